@@ -9,6 +9,8 @@ import (
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/config"
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/manager"
+	ngxcfg "github.com/nginxinc/nginx-kubernetes-gateway/internal/nginx/config"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
 )
 
 const (
@@ -32,24 +34,417 @@ var (
 		"gatewayclass",
 		"",
 		"The name of the GatewayClass resource. Every NGINX Gateway must have a unique corresponding GatewayClass resource")
+
+	eventBatchWindow = flag.Duration(
+		"event-batch-window",
+		0,
+		"The time to wait after a resource change before updating NGINX, so that bursts of changes are handled together. "+
+			"For example, 500ms. By default, changes are handled immediately")
+
+	minReloadInterval = flag.Duration(
+		"min-reload-interval",
+		0,
+		"The minimum interval between two NGINX reloads, for example, 2s. The changes that come within the interval "+
+			"are handled together after it passes. By default, NGINX is reloaded as soon as a change is handled")
+
+	resolveTimeout = flag.Duration(
+		"resolve-timeout",
+		ngxcfg.DefaultResolveTimeout,
+		"The time limit of the resolution of the backends of an HTTPRoute or an NGINX server, for example, 2s. "+
+			"The backends that are not resolved in time are treated as unresolved")
+
+	noBackendsResponseCode = flag.Int(
+		"no-backends-response-code",
+		ngxcfg.DefaultNoBackendsResponseCode,
+		"The status code of the responses to the requests for the rules none of whose backends can be resolved. "+
+			"Supported values: 502, 503")
+
+	noBackendsResponseBody = flag.String(
+		"no-backends-response-body",
+		"",
+		"The plain text body of the responses to the requests for the rules none of whose backends can be resolved, "+
+			"for example, no available backends. By default, the body is empty")
+
+	healthProbeBindAddress = flag.String(
+		"health-probe-bind-address",
+		":8081",
+		"The address the health probe endpoint binds to, in the form HOST:PORT. An empty host means all interfaces")
+
+	metricsBindAddress = flag.String(
+		"metrics-bind-address",
+		":8080",
+		"The address the metrics endpoint binds to, in the form HOST:PORT. An empty host means all interfaces")
+
+	requestIDHeader = flag.String(
+		"request-id-header",
+		ngxcfg.DefaultRequestIDHeader,
+		"The name of the header that carries the ID of a request. If a request doesn't include the header, "+
+			"NGINX generates the ID. The ID is passed to the backends and written to the access log")
+
+	forwardedHeaders = flag.Bool(
+		"forwarded-headers",
+		true,
+		"Pass the X-Forwarded-For, X-Forwarded-Proto and X-Real-IP headers to the backends, so that they know "+
+			"the original client address and scheme")
+
+	trustedProxies = flag.String(
+		"trusted-proxies",
+		"",
+		"A comma-separated list of the CIDRs of the trusted proxies, for example, a cloud load balancer. "+
+			"NGINX takes the client address of the requests from the trusted proxies from the X-Forwarded-For header")
+
+	statusOverrides = flag.String(
+		"status-overrides",
+		"",
+		"A comma-separated list of the translations of the status codes of the backend responses to the status "+
+			"codes of the responses to the clients, in the form backend=client, for example, 418=500. "+
+			"The backend status codes must be between 300 and 599")
+
+	backendCACertificate = flag.String(
+		"backend-ca-certificate",
+		"",
+		"The absolute path of the CA certificate that verifies the certificates of the HTTPS backends. "+
+			"The verification is enabled per HTTPRoute with the gateway.nginx.org/backend-ssl-verify annotation")
+
+	ocspStaplingTrustedCertificate = flag.String(
+		"ocsp-stapling-trusted-certificate",
+		"",
+		"The absolute path of the CA certificate that verifies the OCSP responses about the certificates of "+
+			"the HTTPS listeners. If set, OCSP stapling is enabled. By default, it is disabled")
+
+	proxyConnectTimeout = flag.Duration(
+		"proxy-connect-timeout",
+		0,
+		"The default timeout for establishing a connection with the backends, for example, 5s. "+
+			"An HTTPRoute can override it with the gateway.nginx.org/proxy-connect-timeout annotation. "+
+			"By default, the NGINX default is used")
+
+	proxyReadTimeout = flag.Duration(
+		"proxy-read-timeout",
+		0,
+		"The default timeout between two successive reads of a response from the backends, for example, 60s. "+
+			"An HTTPRoute can override it with the gateway.nginx.org/proxy-read-timeout annotation. "+
+			"By default, the NGINX default is used")
+
+	proxySendTimeout = flag.Duration(
+		"proxy-send-timeout",
+		0,
+		"The default timeout between two successive writes of a request to the backends, for example, 60s. "+
+			"An HTTPRoute can override it with the gateway.nginx.org/proxy-send-timeout annotation. "+
+			"By default, the NGINX default is used")
+
+	securityHeaders = flag.Bool(
+		"security-headers",
+		false,
+		"Add the preset of the security headers to all responses: X-Content-Type-Options, X-Frame-Options, "+
+			"Referrer-Policy and, for HTTPS, Strict-Transport-Security")
+
+	hsts = flag.Bool(
+		"hsts",
+		false,
+		"Add the Strict-Transport-Security header to all responses of the HTTPS servers, so that the browsers only "+
+			"access their hostnames over HTTPS. The plain HTTP servers never add the header")
+
+	hstsMaxAge = flag.Int(
+		"hsts-max-age",
+		ngxcfg.DefaultHSTSMaxAge,
+		"The time, in seconds, the browsers only access a hostname over HTTPS after they receive "+
+			"the Strict-Transport-Security header. Requires hsts")
+
+	hstsIncludeSubDomains = flag.Bool(
+		"hsts-include-subdomains",
+		false,
+		"Extend the Strict-Transport-Security policy to the subdomains of the hostnames. Requires hsts")
+
+	hstsPreload = flag.Bool(
+		"hsts-preload",
+		false,
+		"Allow the hostnames to be included in the HSTS preload lists of the browsers. Requires hsts, "+
+			"hsts-include-subdomains and an hsts-max-age of at least one year")
+
+	serverTokens = flag.Bool(
+		"server-tokens",
+		false,
+		"Advertise the version of NGINX in the Server header of the responses and on the error pages")
+
+	sourceComments = flag.Bool(
+		"source-comments",
+		false,
+		"Add the comments that link the servers and locations of the NGINX configuration to the HTTPRoutes, "+
+			"rules and matches that produced them, for debugging")
+
+	allowSnippets = flag.Bool(
+		"allow-snippets",
+		false,
+		"Allow the HTTPRoutes to add NGINX directives to their locations and servers with the "+
+			"gateway.nginx.org/location-snippets and gateway.nginx.org/server-snippets annotations. The snippets "+
+			"bypass the validation of the HTTPRoutes, so only enable them if the authors of the HTTPRoutes are trusted")
+
+	clientMaxBodySize = flag.String(
+		"client-max-body-size",
+		"",
+		"The maximum size of the request bodies, for example, 10m. NGINX responds with 413 to the requests with "+
+			"larger bodies. 0 disables the check. By default, the NGINX default of 1m is used")
+
+	requestTooLargeBody = flag.String(
+		"request-too-large-body",
+		"",
+		"The body of the 413 responses to the requests whose bodies exceed the client-max-body-size, for example, "+
+			`{"error": "request too large"}. By default, NGINX responds with its default HTML page`)
+
+	requestTooLargeContentType = flag.String(
+		"request-too-large-content-type",
+		"text/plain",
+		"The content type of the request-too-large-body, for example, application/json")
+
+	sslCertificateMap = flag.Bool(
+		"ssl-certificate-map",
+		false,
+		"Select the certificates of the HTTPS listeners by the SNI of a connection during the handshake, rather than "+
+			"loading all certificates when NGINX reloads. This speeds up the reloads for the Gateways with many "+
+			"certificates at the cost of loading a certificate during every handshake. Requires NGINX 1.15.9 or later")
+
+	httpPort = flag.Int(
+		"http-port",
+		ngxcfg.DefaultHTTPPort,
+		"The port NGINX listens on for HTTP, for example, 8080 when NGINX runs as a non-root user or its ports "+
+			"are remapped. The ports of the Gateway listeners don't change")
+
+	httpsPort = flag.Int(
+		"https-port",
+		ngxcfg.DefaultHTTPSPort,
+		"The port NGINX listens on for HTTPS, for example, 8443 when NGINX runs as a non-root user or its ports "+
+			"are remapped. Must differ from http-port. The ports of the Gateway listeners don't change")
+
+	ipFamily = flag.String(
+		"ip-family",
+		"ipv4",
+		"The IP family of the connections NGINX accepts. Supported values: ipv4, ipv6, "+
+			"dual (both IPv4 and IPv6, for dual-stack clusters)")
+
+	gatewayAddresses = flag.String(
+		"gateway-addresses",
+		"",
+		"A comma-separated list of the IP addresses the Gateway can request in its addresses, for NGINX to listen on. "+
+			"The addresses that are not in the list or not of the IP family are rejected. By default, the addresses "+
+			"of the network interfaces of the pod")
+
+	defaultType = flag.String(
+		"default-type",
+		"",
+		"The default content type of the responses without a content type, for example, text/plain. "+
+			"The proxied responses keep the content type of the backends. By default, the NGINX default is used")
+
+	templateOverrides = flag.String(
+		"template-overrides",
+		"",
+		"The absolute path of a file with Go templates that override the server, location and upstream templates "+
+			"of the NGINX configuration, for example, a mounted ConfigMap. The file redefines the templates with "+
+			"{{ define }} actions. By default, the built-in templates are used")
+
+	serviceImportBackends = flag.Bool(
+		"service-import-backends",
+		false,
+		"Support the ServiceImports of the Multi-Cluster Services API (multicluster.x-k8s.io/v1alpha1) as the "+
+			"backends of the routes. Requires the ServiceImport CRD to be installed in the cluster")
+
+	serviceStore = flag.String(
+		"service-store",
+		state.ServiceStoreClusterIP,
+		"The resolver of the Services that the routes reference. Supported values: cluster-ip (the cluster IPs of "+
+			"the Services), static (the addresses of the static-service-addresses flag, for example, for the "+
+			"local development)")
+
+	staticServiceAddresses = flag.String(
+		"static-service-addresses",
+		"",
+		"A comma-separated list of the addresses of the ports of the Services in the form "+
+			"namespace/name:port=address, where the address is an IP address or a hostname. "+
+			"Used by the static service-store")
+
+	defaultBackend = flag.String(
+		"default-backend",
+		"",
+		"The port of the Service that receives the requests that don't match the hostname of any HTTP listener, "+
+			"in the form namespace/name:port, for example, default/fallback:80. By default, NGINX responds to "+
+			"those requests with 404")
+
+	workerProcesses = flag.String(
+		"worker-processes",
+		ngxcfg.DefaultWorkerProcesses,
+		"The number of the NGINX worker processes, for example, 2. The value auto means the number of the CPU cores")
+
+	workerConnections = flag.Int(
+		"worker-connections",
+		ngxcfg.DefaultWorkerConnections,
+		"The maximum number of the simultaneous connections of an NGINX worker process, including the connections "+
+			"with the backends")
+
+	upstreamKeepalive = flag.Int(
+		"upstream-keepalive",
+		ngxcfg.DefaultUpstreamKeepalive,
+		"The maximum number of the idle keepalive connections to the backends of a rule that each NGINX worker "+
+			"process keeps open. 0 disables the keepalive connections")
+
+	upstreamZoneSize = flag.String(
+		"upstream-zone-size",
+		ngxcfg.DefaultUpstreamZoneSize,
+		"The size of the shared memory zone of every upstream, for example, 128k. The zone shares the state of "+
+			"the upstream among the NGINX worker processes, which some load balancing methods require. "+
+			"Must be at least 32k. Empty disables the zones")
+
+	resolver = flag.String(
+		"resolver",
+		"",
+		"A comma-separated list of the addresses of the DNS servers, in the form IP[:PORT], that NGINX uses to "+
+			"resolve the hostnames of the ExternalName Services. By default, the nameservers of the pod")
+
+	normalizePaths = flag.Bool(
+		"normalize-paths",
+		false,
+		"Normalize the paths of the HTTPRoute matches and merge the duplicate slashes of the request URIs, so that, "+
+			"for example, the PathPrefix /foo matches the requests for //foo")
+
+	defaultCertificate = flag.String(
+		"default-certificate",
+		"",
+		"The absolute path of the certificate, for example, self-signed, of the default HTTPS server, which responds "+
+			"with 404 to the requests for the hostnames without an HTTPS listener. Requires default-certificate-key. "+
+			"By default, NGINX rejects the TLS handshakes for those hostnames")
+
+	defaultCertificateKey = flag.String(
+		"default-certificate-key",
+		"",
+		"The absolute path of the key of the default-certificate")
+
+	nginxHealthPort = flag.Int(
+		"nginx-health-port",
+		0,
+		"The port of the health check endpoint of NGINX, for example, for the readiness probe of the NGINX container. "+
+			"The port must differ from the ports of the listeners of the Gateway and the ports of the controller. "+
+			"By default, the endpoint is disabled")
+
+	nginxHealthPath = flag.String(
+		"nginx-health-path",
+		ngxcfg.DefaultHealthCheckPath,
+		"The path of the health check endpoint of NGINX, which responds with 200 without proxying the requests")
+
+	nginxHealthAccessLog = flag.Bool(
+		"nginx-health-access-log",
+		false,
+		"Write the requests of the health check endpoint of NGINX to the access log")
+
+	logLevel = flag.String(
+		"log-level",
+		"info",
+		"The level of the logs. Supported values: debug, info, warn, error")
 )
 
 func main() {
 	flag.Parse()
 
-	logger := zap.New()
-	conf := config.Config{
-		GatewayCtlrName:  *gatewayCtlrName,
-		Logger:           logger,
-		GatewayClassName: *gatewayClassName,
-	}
-
 	MustValidateArguments(
 		flag.CommandLine,
 		GatewayControllerParam(domain, "nginx-gateway" /* FIXME(f5yacobucci) dynamically set */),
 		GatewayClassParam(),
+		EventBatchWindowParam(),
+		MinReloadIntervalParam(),
+		ResolveTimeoutParam(),
+		NoBackendsResponseCodeParam(),
+		HealthProbeBindAddressParam(),
+		MetricsBindAddressParam(),
+		RequestIDHeaderParam(),
+		TrustedProxiesParam(),
+		StatusOverridesParam(),
+		BackendCACertificateParam(),
+		OCSPStaplingTrustedCertificateParam(),
+		ProxyConnectTimeoutParam(),
+		ProxyReadTimeoutParam(),
+		ProxySendTimeoutParam(),
+		HTTPPortParam(),
+		HTTPSPortParam(),
+		IPFamilyParam(),
+		GatewayAddressesParam(),
+		DefaultTypeParam(),
+		TemplateOverridesParam(),
+		ServiceStoreParam(),
+		StaticServiceAddressesParam(),
+		DefaultBackendParam(),
+		WorkerProcessesParam(),
+		WorkerConnectionsParam(),
+		UpstreamKeepaliveParam(),
+		UpstreamZoneSizeParam(),
+		ResolverParam(),
+		DefaultCertificateParam(),
+		DefaultCertificateKeyParam(),
+		NginxHealthPortParam(),
+		NginxHealthPathParam(),
+		HSTSMaxAgeParam(),
+		ClientMaxBodySizeParam(),
+		RequestTooLargeContentTypeParam(),
+		HSTSPreloadParam(),
+		LogLevelParam(),
 	)
 
+	// the overrides are validated above
+	overrides, _ := parseStatusOverrides(*statusOverrides)
+
+	logger := zap.New(zap.Level(logLevels[*logLevel]))
+	conf := config.Config{
+		GatewayCtlrName:                *gatewayCtlrName,
+		Logger:                         logger,
+		GatewayClassName:               *gatewayClassName,
+		EventBatchWindow:               *eventBatchWindow,
+		MinReloadInterval:              *minReloadInterval,
+		ResolveTimeout:                 *resolveTimeout,
+		NoBackendsResponseCode:         *noBackendsResponseCode,
+		NoBackendsResponseBody:         *noBackendsResponseBody,
+		HealthProbeBindAddress:         *healthProbeBindAddress,
+		MetricsBindAddress:             *metricsBindAddress,
+		RequestIDHeader:                *requestIDHeader,
+		ForwardedHeaders:               *forwardedHeaders,
+		TrustedProxies:                 parseList(*trustedProxies),
+		StatusOverrides:                overrides,
+		BackendCACertificate:           *backendCACertificate,
+		OCSPStaplingTrustedCertificate: *ocspStaplingTrustedCertificate,
+		ProxyConnectTimeout:            *proxyConnectTimeout,
+		ProxyReadTimeout:               *proxyReadTimeout,
+		ProxySendTimeout:               *proxySendTimeout,
+		SecurityHeaders:                *securityHeaders,
+		ServerTokens:                   *serverTokens,
+		SourceComments:                 *sourceComments,
+		AllowSnippets:                  *allowSnippets,
+		ClientMaxBodySize:              *clientMaxBodySize,
+		RequestTooLargeBody:            *requestTooLargeBody,
+		RequestTooLargeContentType:     *requestTooLargeContentType,
+		HSTS:                           *hsts,
+		HSTSMaxAge:                     *hstsMaxAge,
+		HSTSIncludeSubDomains:          *hstsIncludeSubDomains,
+		HSTSPreload:                    *hstsPreload,
+		SSLCertificateMap:              *sslCertificateMap,
+		HTTPPort:                       *httpPort,
+		HTTPSPort:                      *httpsPort,
+		IPFamily:                       *ipFamily,
+		GatewayAddresses:               parseList(*gatewayAddresses),
+		DefaultType:                    *defaultType,
+		TemplateOverrides:              *templateOverrides,
+		ServiceImportBackends:          *serviceImportBackends,
+		ServiceStore:                   *serviceStore,
+		StaticServiceAddresses:         parseList(*staticServiceAddresses),
+		DefaultBackend:                 *defaultBackend,
+		WorkerProcesses:                *workerProcesses,
+		WorkerConnections:              *workerConnections,
+		UpstreamKeepalive:              *upstreamKeepalive,
+		UpstreamZoneSize:               *upstreamZoneSize,
+		Resolver:                       parseList(*resolver),
+		NormalizePaths:                 *normalizePaths,
+		DefaultCertificate:             *defaultCertificate,
+		DefaultCertificateKey:          *defaultCertificateKey,
+		NginxHealthPort:                *nginxHealthPort,
+		NginxHealthPath:                *nginxHealthPath,
+		NginxHealthAccessLog:           *nginxHealthAccessLog,
+	}
+
 	logger.Info("Starting NGINX Kubernetes Gateway",
 		"version", version,
 		"commit", commit,
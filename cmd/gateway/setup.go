@@ -3,17 +3,58 @@ package main
 import (
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	flag "github.com/spf13/pflag"
+	"go.uber.org/zap/zapcore"
 	"k8s.io/apimachinery/pkg/util/validation"
+
+	ngxcfg "github.com/nginxinc/nginx-kubernetes-gateway/internal/nginx/config"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
 )
 
 const (
 	errTmpl = "failed validation - flag: '--%s' reason: '%s'\n"
+
+	// controllerNameMaxLength is the maximum length of GatewayClass.ControllerName.
+	controllerNameMaxLength = 253
+
+	// minUpstreamZoneSize is the minimum size of the shared memory zone of an upstream: NGINX requires at least
+	// 8 memory pages of 4k.
+	minUpstreamZoneSize = 32 * 1024
+)
+
+// logLevels maps the supported values of the log-level flag to the levels of the logger.
+var logLevels = map[string]zapcore.Level{
+	"debug": zapcore.DebugLevel,
+	"info":  zapcore.InfoLevel,
+	"warn":  zapcore.WarnLevel,
+	"error": zapcore.ErrorLevel,
+}
+
+// controllerNameRegexp matches the format of GatewayClass.ControllerName:
+// a domain followed by a path.
+var controllerNameRegexp = regexp.MustCompile(
+	`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*\/[A-Za-z0-9\/\-._~%!$&'()*+,;=:]+$`,
 )
 
+// mimeTypeRegexp matches a MIME type without parameters, for example, text/plain.
+var mimeTypeRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]*/[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]*$`)
+
+// sizeRegexp matches an NGINX size, for example, 10m.
+var sizeRegexp = regexp.MustCompile(`^[0-9]+[kKmMgG]?$`)
+
+// healthCheckPathRegexp matches a path of the NGINX health check endpoint, which can be used in a location without
+// quoting or escaping, for example, /nginx-health.
+var healthCheckPathRegexp = regexp.MustCompile(`^/[A-Za-z0-9._~/-]*$`)
+
 type Validator func(*flag.FlagSet) error
 type ValidatorContext struct {
 	Key string
@@ -25,8 +66,6 @@ func GatewayControllerParam(domain string, namespace string) ValidatorContext {
 	return ValidatorContext{
 		name,
 		func(flagset *flag.FlagSet) error {
-			// FIXME(f5yacobucci) this does not provide the same regex validation as
-			// GatewayClass.ControllerName. provide equal and then specific validation
 			param, err := flagset.GetString(name)
 			if err != nil {
 				return err
@@ -36,6 +75,14 @@ func GatewayControllerParam(domain string, namespace string) ValidatorContext {
 				return errors.New("flag must be set")
 			}
 
+			// the same validation as for GatewayClass.ControllerName
+			if len(param) > controllerNameMaxLength {
+				return fmt.Errorf("must be no more than %d characters", controllerNameMaxLength)
+			}
+			if !controllerNameRegexp.MatchString(param) {
+				return errors.New("invalid format, must be a domain followed by a path")
+			}
+
 			fields := strings.Split(param, "/")
 			l := len(fields)
 			if l != 3 {
@@ -58,6 +105,12 @@ func GatewayControllerParam(domain string, namespace string) ValidatorContext {
 					if fields[0] == "" {
 						return errors.New("must provide a name")
 					}
+
+					messages := validation.IsDNS1123Label(fields[0])
+					if len(messages) > 0 {
+						msg := strings.Join(messages, "; ")
+						return fmt.Errorf("invalid name format: %s", msg)
+					}
 				}
 			}
 
@@ -92,6 +145,820 @@ func GatewayClassParam() ValidatorContext {
 	}
 }
 
+func EventBatchWindowParam() ValidatorContext {
+	name := "event-batch-window"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetDuration(name)
+			if err != nil {
+				return err
+			}
+
+			if param < 0 {
+				return errors.New("must not be negative")
+			}
+
+			return nil
+		},
+	}
+}
+
+func MinReloadIntervalParam() ValidatorContext {
+	name := "min-reload-interval"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetDuration(name)
+			if err != nil {
+				return err
+			}
+
+			if param < 0 {
+				return errors.New("must not be negative")
+			}
+
+			return nil
+		},
+	}
+}
+
+func RequestIDHeaderParam() ValidatorContext {
+	name := "request-id-header"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			if len(param) == 0 {
+				return errors.New("flag must be set")
+			}
+
+			messages := validation.IsHTTPHeaderName(param)
+			if len(messages) > 0 {
+				msg := strings.Join(messages, "; ")
+				return fmt.Errorf("invalid format: %s", msg)
+			}
+
+			return nil
+		},
+	}
+}
+
+func TrustedProxiesParam() ValidatorContext {
+	name := "trusted-proxies"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			// the flag is optional
+			for _, cidr := range parseList(param) {
+				if _, _, err := net.ParseCIDR(cidr); err != nil {
+					return fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+func BackendCACertificateParam() ValidatorContext {
+	return absolutePathParam("backend-ca-certificate")
+}
+
+func OCSPStaplingTrustedCertificateParam() ValidatorContext {
+	return absolutePathParam("ocsp-stapling-trusted-certificate")
+}
+
+func DefaultCertificateParam() ValidatorContext {
+	return absolutePathParam("default-certificate")
+}
+
+func DefaultCertificateKeyParam() ValidatorContext {
+	return absolutePathParam("default-certificate-key")
+}
+
+func TemplateOverridesParam() ValidatorContext {
+	return absolutePathParam("template-overrides")
+}
+
+// absolutePathParam validates an optional flag with the absolute path of a file.
+func absolutePathParam(name string) ValidatorContext {
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			// the flag is optional
+			if param == "" {
+				return nil
+			}
+
+			if !filepath.IsAbs(param) {
+				return fmt.Errorf("%q must be an absolute path", param)
+			}
+
+			return nil
+		},
+	}
+}
+
+// parseList parses the comma-separated list of a flag, like trusted-proxies.
+func parseList(param string) []string {
+	if param == "" {
+		return nil
+	}
+
+	cidrs := strings.Split(param, ",")
+	for i := range cidrs {
+		cidrs[i] = strings.TrimSpace(cidrs[i])
+	}
+
+	return cidrs
+}
+
+func StatusOverridesParam() ValidatorContext {
+	name := "status-overrides"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			// the flag is optional
+			_, err = parseStatusOverrides(param)
+			return err
+		},
+	}
+}
+
+// parseStatusOverrides parses the comma-separated list of the status overrides in the form backend=client,
+// for example, 418=500. The backend status codes must be between 300 and 599, because NGINX only intercepts
+// those, and the client status codes between 200 and 599.
+func parseStatusOverrides(param string) (map[int]int, error) {
+	entries := parseList(param)
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	overrides := make(map[int]int, len(entries))
+
+	for _, entry := range entries {
+		fromStr, toStr, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid status override %q, must be backend=client, for example, 418=500", entry)
+		}
+
+		from, err := strconv.Atoi(fromStr)
+		if err != nil || from < 300 || from > 599 {
+			return nil, fmt.Errorf("invalid backend status code %q in the status override %q, must be between "+
+				"300 and 599", fromStr, entry)
+		}
+
+		to, err := strconv.Atoi(toStr)
+		if err != nil || to < 200 || to > 599 {
+			return nil, fmt.Errorf("invalid client status code %q in the status override %q, must be between "+
+				"200 and 599", toStr, entry)
+		}
+
+		if _, exist := overrides[from]; exist {
+			return nil, fmt.Errorf("duplicate status override for %d", from)
+		}
+
+		overrides[from] = to
+	}
+
+	return overrides, nil
+}
+
+func ProxyConnectTimeoutParam() ValidatorContext {
+	return proxyTimeoutParam("proxy-connect-timeout")
+}
+
+func ResolveTimeoutParam() ValidatorContext {
+	name := "resolve-timeout"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetDuration(name)
+			if err != nil {
+				return err
+			}
+
+			if param <= 0 {
+				return errors.New("must be positive")
+			}
+
+			return nil
+		},
+	}
+}
+
+func NoBackendsResponseCodeParam() ValidatorContext {
+	name := "no-backends-response-code"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetInt(name)
+			if err != nil {
+				return err
+			}
+
+			if param != 502 && param != 503 {
+				return fmt.Errorf("unsupported value %d, must be 502 or 503", param)
+			}
+
+			return nil
+		},
+	}
+}
+
+func ProxyReadTimeoutParam() ValidatorContext {
+	return proxyTimeoutParam("proxy-read-timeout")
+}
+
+func ProxySendTimeoutParam() ValidatorContext {
+	return proxyTimeoutParam("proxy-send-timeout")
+}
+
+func proxyTimeoutParam(name string) ValidatorContext {
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetDuration(name)
+			if err != nil {
+				return err
+			}
+
+			// the flag is optional
+			if param == 0 {
+				return nil
+			}
+
+			// NGINX doesn't support the timeouts with a precision higher than milliseconds
+			if param < time.Millisecond {
+				return errors.New("must be at least 1ms")
+			}
+
+			return nil
+		},
+	}
+}
+
+func HTTPPortParam() ValidatorContext {
+	name := "http-port"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetInt(name)
+			if err != nil {
+				return err
+			}
+
+			return validatePort(param)
+		},
+	}
+}
+
+func HTTPSPortParam() ValidatorContext {
+	name := "https-port"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetInt(name)
+			if err != nil {
+				return err
+			}
+
+			if err := validatePort(param); err != nil {
+				return err
+			}
+
+			httpParam, err := flagset.GetInt("http-port")
+			if err != nil {
+				return err
+			}
+
+			if param == httpParam {
+				return fmt.Errorf("must differ from http-port: %d", param)
+			}
+
+			return nil
+		},
+	}
+}
+
+func validatePort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535: %d", port)
+	}
+
+	return nil
+}
+
+func IPFamilyParam() ValidatorContext {
+	name := "ip-family"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			switch param {
+			case ngxcfg.IPFamilyIPv4, ngxcfg.IPFamilyIPv6, ngxcfg.IPFamilyDual:
+				return nil
+			default:
+				return fmt.Errorf("unsupported value %q, must be one of: %s, %s, %s",
+					param, ngxcfg.IPFamilyIPv4, ngxcfg.IPFamilyIPv6, ngxcfg.IPFamilyDual)
+			}
+		},
+	}
+}
+
+func GatewayAddressesParam() ValidatorContext {
+	name := "gateway-addresses"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			// the flag is optional
+			for _, address := range parseList(param) {
+				if net.ParseIP(address) == nil {
+					return fmt.Errorf("invalid IP address %q", address)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+func WorkerProcessesParam() ValidatorContext {
+	name := "worker-processes"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			if param == ngxcfg.DefaultWorkerProcesses {
+				return nil
+			}
+
+			n, err := strconv.Atoi(param)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid value %q, must be a positive number or %s", param, ngxcfg.DefaultWorkerProcesses)
+			}
+
+			return nil
+		},
+	}
+}
+
+func WorkerConnectionsParam() ValidatorContext {
+	name := "worker-connections"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetInt(name)
+			if err != nil {
+				return err
+			}
+
+			if param <= 0 {
+				return errors.New("must be positive")
+			}
+
+			return nil
+		},
+	}
+}
+
+func UpstreamKeepaliveParam() ValidatorContext {
+	name := "upstream-keepalive"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetInt(name)
+			if err != nil {
+				return err
+			}
+
+			if param < 0 {
+				return errors.New("must be zero or positive")
+			}
+
+			return nil
+		},
+	}
+}
+
+func UpstreamZoneSizeParam() ValidatorContext {
+	name := "upstream-zone-size"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			// empty disables the zones
+			if param == "" {
+				return nil
+			}
+
+			if !sizeRegexp.MatchString(param) {
+				return fmt.Errorf("invalid format %q, must be a size with an optional unit k, m or g, for example, 64k",
+					param)
+			}
+
+			size, err := parseSize(param)
+			if err != nil {
+				return err
+			}
+
+			if size < minUpstreamZoneSize {
+				return errors.New("must be at least 32k")
+			}
+
+			return nil
+		},
+	}
+}
+
+// parseSize parses an NGINX size that matches sizeRegexp into the number of bytes.
+func parseSize(size string) (int64, error) {
+	multiplier := int64(1)
+	switch strings.ToLower(size[len(size)-1:]) {
+	case "k":
+		multiplier = 1 << 10
+	case "m":
+		multiplier = 1 << 20
+	case "g":
+		multiplier = 1 << 30
+	}
+
+	digits := size
+	if multiplier != 1 {
+		digits = size[:len(size)-1]
+	}
+
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("size %s is too large", size)
+	}
+
+	return n * multiplier, nil
+}
+
+func ResolverParam() ValidatorContext {
+	name := "resolver"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			// the flag is optional
+			for _, address := range parseList(param) {
+				if err := validateResolverAddress(address); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// validateResolverAddress validates the address of a DNS server in the form IP[:PORT].
+func validateResolverAddress(address string) error {
+	if net.ParseIP(address) != nil {
+		return nil
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) == nil {
+		return fmt.Errorf("invalid address %q, must be an IP address with an optional port", address)
+	}
+
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid port of address %q", address)
+	}
+
+	return nil
+}
+
+func DefaultTypeParam() ValidatorContext {
+	name := "default-type"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			// empty means the NGINX default
+			if param == "" {
+				return nil
+			}
+
+			if !mimeTypeRegexp.MatchString(param) {
+				return fmt.Errorf("invalid format %q, must be a MIME type, for example, text/plain", param)
+			}
+
+			return nil
+		},
+	}
+}
+
+func ServiceStoreParam() ValidatorContext {
+	name := "service-store"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			for _, n := range state.ServiceStoreNames() {
+				if param == n {
+					return nil
+				}
+			}
+
+			return fmt.Errorf("unsupported value %q, must be one of: %s", param,
+				strings.Join(state.ServiceStoreNames(), ", "))
+		},
+	}
+}
+
+func StaticServiceAddressesParam() ValidatorContext {
+	name := "static-service-addresses"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			// the flag is optional
+			_, err = state.ParseStaticServiceAddresses(parseList(param))
+			return err
+		},
+	}
+}
+
+func DefaultBackendParam() ValidatorContext {
+	name := "default-backend"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			// the flag is optional
+			if param == "" {
+				return nil
+			}
+
+			_, err = state.ParseServicePort(param)
+			return err
+		},
+	}
+}
+
+func ClientMaxBodySizeParam() ValidatorContext {
+	name := "client-max-body-size"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			// empty means the NGINX default
+			if param == "" {
+				return nil
+			}
+
+			if !sizeRegexp.MatchString(param) {
+				return fmt.Errorf("invalid format %q, must be a size with an optional unit k, m or g, for example, 10m",
+					param)
+			}
+
+			return nil
+		},
+	}
+}
+
+func RequestTooLargeContentTypeParam() ValidatorContext {
+	name := "request-too-large-content-type"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			if !mimeTypeRegexp.MatchString(param) {
+				return fmt.Errorf("invalid format %q, must be a MIME type, for example, application/json", param)
+			}
+
+			return nil
+		},
+	}
+}
+
+func HSTSMaxAgeParam() ValidatorContext {
+	name := "hsts-max-age"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetInt(name)
+			if err != nil {
+				return err
+			}
+
+			// 0 makes the browsers forget the policy
+			if param < 0 {
+				return errors.New("must be non-negative")
+			}
+
+			return nil
+		},
+	}
+}
+
+// HSTSPreloadParam validates the requirements of the HSTS preload lists, which only accept the policies that cover
+// the subdomains for at least a year.
+func HSTSPreloadParam() ValidatorContext {
+	name := "hsts-preload"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			preload, err := flagset.GetBool(name)
+			if err != nil {
+				return err
+			}
+
+			if !preload {
+				return nil
+			}
+
+			includeSubDomains, err := flagset.GetBool("hsts-include-subdomains")
+			if err != nil {
+				return err
+			}
+
+			if !includeSubDomains {
+				return errors.New("requires hsts-include-subdomains")
+			}
+
+			maxAge, err := flagset.GetInt("hsts-max-age")
+			if err != nil {
+				return err
+			}
+
+			if maxAge < ngxcfg.DefaultHSTSMaxAge {
+				return fmt.Errorf("requires an hsts-max-age of at least %d", ngxcfg.DefaultHSTSMaxAge)
+			}
+
+			return nil
+		},
+	}
+}
+
+func NginxHealthPortParam() ValidatorContext {
+	name := "nginx-health-port"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetInt(name)
+			if err != nil {
+				return err
+			}
+
+			// 0 disables the health check endpoint
+			if param < 0 || param > 65535 {
+				return fmt.Errorf("port must be between 1 and 65535 or 0: %d", param)
+			}
+
+			return nil
+		},
+	}
+}
+
+func NginxHealthPathParam() ValidatorContext {
+	name := "nginx-health-path"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			if !healthCheckPathRegexp.MatchString(param) {
+				return fmt.Errorf("invalid format %q, must be a path, for example, /nginx-health", param)
+			}
+
+			return nil
+		},
+	}
+}
+
+func LogLevelParam() ValidatorContext {
+	name := "log-level"
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			if _, exists := logLevels[param]; !exists {
+				return fmt.Errorf("unsupported level %q, must be one of: debug, info, warn, error", param)
+			}
+
+			return nil
+		},
+	}
+}
+
+func HealthProbeBindAddressParam() ValidatorContext {
+	return bindAddressParam("health-probe-bind-address")
+}
+
+func MetricsBindAddressParam() ValidatorContext {
+	return bindAddressParam("metrics-bind-address")
+}
+
+func bindAddressParam(name string) ValidatorContext {
+	return ValidatorContext{
+		name,
+		func(flagset *flag.FlagSet) error {
+			param, err := flagset.GetString(name)
+			if err != nil {
+				return err
+			}
+
+			if len(param) == 0 {
+				return errors.New("flag must be set")
+			}
+
+			// the host can be empty, in which case all interfaces are used
+			_, port, err := net.SplitHostPort(param)
+			if err != nil {
+				return fmt.Errorf("invalid format, must be HOST:PORT: %w", err)
+			}
+
+			p, err := strconv.Atoi(port)
+			if err != nil {
+				return fmt.Errorf("invalid port: %s", port)
+			}
+
+			if p < 1 || p > 65535 {
+				return fmt.Errorf("port must be between 1 and 65535: %d", p)
+			}
+
+			return nil
+		},
+	}
+}
+
 func ValidateArguments(flagset *flag.FlagSet, validators ...ValidatorContext) []string {
 	var msgs []string
 	for _, v := range validators {
@@ -2,6 +2,7 @@ package main_test
 
 import (
 	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -231,6 +232,52 @@ var _ = Describe("Main", func() {
 
 				runner(table)
 			}) // should verify constraints
+
+			It("should verify the format of the gateway-ctlr-name", func() {
+				table := []testCase{
+					prepareTestCase(
+						"k8s-gateway.nginx.org/nginx-gateway/my-gateway-1",
+						expectSuccess,
+					),
+					prepareTestCase(
+						// leading slash
+						"/k8s-gateway.nginx.org/nginx-gateway/my-gateway",
+						expectError,
+					),
+					prepareTestCase(
+						// trailing slash
+						"k8s-gateway.nginx.org/nginx-gateway/my-gateway/",
+						expectError,
+					),
+					prepareTestCase(
+						// invalid domain label
+						"-k8s-gateway.nginx.org/nginx-gateway/my-gateway",
+						expectError,
+					),
+					prepareTestCase(
+						// invalid domain label
+						"k8s-gateway..nginx.org/nginx-gateway/my-gateway",
+						expectError,
+					),
+					prepareTestCase(
+						// uppercase domain
+						"K8S-gateway.nginx.org/nginx-gateway/my-gateway",
+						expectError,
+					),
+					prepareTestCase(
+						// uppercase name
+						"k8s-gateway.nginx.org/nginx-gateway/My-Gateway",
+						expectError,
+					),
+					prepareTestCase(
+						// invalid name label
+						"k8s-gateway.nginx.org/nginx-gateway/my_gateway",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should verify the format of the gateway-ctlr-name
 		}) // gateway-ctlr-name validation
 
 		Describe("gatewayclass validation", func() {
@@ -268,5 +315,1829 @@ var _ = Describe("Main", func() {
 				tester(t)
 			}) // should fail with invalid name"
 		}) // gatewayclass validation
+
+		Describe("event-batch-window validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "event-batch-window",
+					Value:            value,
+					ValidatorContext: EventBatchWindowParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.Duration("event-batch-window", 0, "mock event-batch-window")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid window", func() {
+				table := []testCase{
+					prepareTestCase(
+						"0s",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"500ms",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid window
+
+			It("should fail with negative window", func() {
+				t := prepareTestCase(
+					"-1s",
+					expectError)
+				tester(t)
+			}) // should fail with negative window
+		}) // event-batch-window validation
+
+		Describe("min-reload-interval validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "min-reload-interval",
+					Value:            value,
+					ValidatorContext: MinReloadIntervalParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.Duration("min-reload-interval", 0, "mock min-reload-interval")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid interval", func() {
+				table := []testCase{
+					prepareTestCase(
+						"0s",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"2s",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid interval
+
+			It("should fail with negative interval", func() {
+				t := prepareTestCase(
+					"-1s",
+					expectError)
+				tester(t)
+			}) // should fail with negative interval
+		}) // min-reload-interval validation
+
+		Describe("resolve-timeout validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "resolve-timeout",
+					Value:            value,
+					ValidatorContext: ResolveTimeoutParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.Duration("resolve-timeout", 5*time.Second, "mock resolve-timeout")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on positive timeout", func() {
+				t := prepareTestCase(
+					"500ms",
+					expectSuccess)
+				tester(t)
+			}) // should succeed on positive timeout
+
+			It("should fail with non-positive timeout", func() {
+				table := []testCase{
+					prepareTestCase(
+						"0s",
+						expectError,
+					),
+					prepareTestCase(
+						"-1s",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with non-positive timeout
+		}) // resolve-timeout validation
+
+		Describe("no-backends-response-code validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "no-backends-response-code",
+					Value:            value,
+					ValidatorContext: NoBackendsResponseCodeParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.Int("no-backends-response-code", 502, "mock no-backends-response-code")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on supported codes", func() {
+				table := []testCase{
+					prepareTestCase(
+						"502",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"503",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on supported codes
+
+			It("should fail with unsupported codes", func() {
+				table := []testCase{
+					prepareTestCase(
+						"500",
+						expectError,
+					),
+					prepareTestCase(
+						"0",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with unsupported codes
+		}) // no-backends-response-code validation
+
+		Describe("request-id-header validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "request-id-header",
+					Value:            value,
+					ValidatorContext: RequestIDHeaderParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("request-id-header", "X-Request-ID", "mock request-id-header")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid header name", func() {
+				table := []testCase{
+					prepareTestCase(
+						"X-Request-ID",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"X-Correlation-ID",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"traceparent",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid header name
+
+			It("should fail with invalid header name", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectError,
+					),
+					prepareTestCase(
+						"X Request ID",
+						expectError,
+					),
+					prepareTestCase(
+						"X_Request_ID",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid header name
+		}) // request-id-header validation
+
+		Describe("trusted-proxies validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "trusted-proxies",
+					Value:            value,
+					ValidatorContext: TrustedProxiesParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("trusted-proxies", "", "mock trusted-proxies")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid CIDRs", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"10.0.0.0/8",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"10.0.0.0/8, 192.168.1.0/24,2001:db8::/32",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid CIDRs
+
+			It("should fail with invalid CIDRs", func() {
+				table := []testCase{
+					prepareTestCase(
+						"10.0.0.1",
+						expectError,
+					),
+					prepareTestCase(
+						"10.0.0.0/8,",
+						expectError,
+					),
+					prepareTestCase(
+						"10.0.0.0/33",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid CIDRs
+		}) // trusted-proxies validation
+
+		Describe("status-overrides validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "status-overrides",
+					Value:            value,
+					ValidatorContext: StatusOverridesParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("status-overrides", "", "mock status-overrides")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid overrides", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"418=500",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"418=500, 404=503",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid overrides
+
+			It("should fail with invalid overrides", func() {
+				table := []testCase{
+					prepareTestCase(
+						"418",
+						expectError,
+					),
+					prepareTestCase(
+						"200=500",
+						expectError,
+					),
+					prepareTestCase(
+						"418=100",
+						expectError,
+					),
+					prepareTestCase(
+						"418=teapot",
+						expectError,
+					),
+					prepareTestCase(
+						"418=500,418=503",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid overrides
+		}) // status-overrides validation
+
+		Describe("backend-ca-certificate validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "backend-ca-certificate",
+					Value:            value,
+					ValidatorContext: BackendCACertificateParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("backend-ca-certificate", "", "mock backend-ca-certificate")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid paths", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"/etc/nginx/secrets/backend-ca.crt",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid paths
+
+			It("should fail with relative paths", func() {
+				table := []testCase{
+					prepareTestCase(
+						"backend-ca.crt",
+						expectError,
+					),
+					prepareTestCase(
+						"./secrets/backend-ca.crt",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with relative paths
+		}) // backend-ca-certificate validation
+
+		Describe("default-certificate validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "default-certificate",
+					Value:            value,
+					ValidatorContext: DefaultCertificateParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("default-certificate", "", "mock default-certificate")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid paths", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"/etc/nginx/default/tls.crt",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid paths
+
+			It("should fail with relative paths", func() {
+				table := []testCase{
+					prepareTestCase(
+						"tls.crt",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with relative paths
+		}) // default-certificate validation
+
+		Describe("template-overrides validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "template-overrides",
+					Value:            value,
+					ValidatorContext: TemplateOverridesParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("template-overrides", "", "mock template-overrides")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid paths", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"/etc/nginx/templates/overrides.tmpl",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid paths
+
+			It("should fail with relative paths", func() {
+				table := []testCase{
+					prepareTestCase(
+						"overrides.tmpl",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with relative paths
+		}) // template-overrides validation
+
+		Describe("log-level validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "log-level",
+					Value:            value,
+					ValidatorContext: LogLevelParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("log-level", "info", "mock log-level")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on supported levels", func() {
+				table := []testCase{
+					prepareTestCase(
+						"debug",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"info",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"warn",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"error",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on supported levels
+
+			It("should fail with unsupported level", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectError,
+					),
+					prepareTestCase(
+						"verbose",
+						expectError,
+					),
+					prepareTestCase(
+						"DEBUG",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with unsupported level
+		}) // log-level validation
+
+		Describe("http-port validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "http-port",
+					Value:            value,
+					ValidatorContext: HTTPPortParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.Int("http-port", 80, "mock http-port")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid ports", func() {
+				table := []testCase{
+					prepareTestCase(
+						"80",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"8080",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid ports
+
+			It("should fail with invalid ports", func() {
+				table := []testCase{
+					prepareTestCase(
+						"0",
+						expectError,
+					),
+					prepareTestCase(
+						"65536",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid ports
+		}) // http-port validation
+
+		Describe("https-port validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "https-port",
+					Value:            value,
+					ValidatorContext: HTTPSPortParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.Int("https-port", 443, "mock https-port")
+				_ = mockFlags.Int("http-port", 8080, "mock http-port")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid ports", func() {
+				table := []testCase{
+					prepareTestCase(
+						"443",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"8443",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid ports
+
+			It("should fail with invalid ports", func() {
+				table := []testCase{
+					prepareTestCase(
+						"0",
+						expectError,
+					),
+					prepareTestCase(
+						"65536",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid ports
+
+			It("should fail with the http-port", func() {
+				t := prepareTestCase(
+					"8080",
+					expectError)
+				tester(t)
+			}) // should fail with the http-port
+		}) // https-port validation
+
+		Describe("ip-family validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "ip-family",
+					Value:            value,
+					ValidatorContext: IPFamilyParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("ip-family", "ipv4", "mock ip-family")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on supported values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"ipv4",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"ipv6",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"dual",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on supported values
+
+			It("should fail with unsupported values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectError,
+					),
+					prepareTestCase(
+						"IPv6",
+						expectError,
+					),
+					prepareTestCase(
+						"ipv4,ipv6",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with unsupported values
+		}) // ip-family validation
+
+		Describe("gateway-addresses validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "gateway-addresses",
+					Value:            value,
+					ValidatorContext: GatewayAddressesParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("gateway-addresses", "", "mock gateway-addresses")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid IP addresses", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"10.0.0.1",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"10.0.0.1, 2001:db8::1",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid IP addresses
+
+			It("should fail with invalid IP addresses", func() {
+				table := []testCase{
+					prepareTestCase(
+						"10.0.0.0/8",
+						expectError,
+					),
+					prepareTestCase(
+						"10.0.0.1,",
+						expectError,
+					),
+					prepareTestCase(
+						"gateway.example.com",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid IP addresses
+		}) // gateway-addresses validation
+
+		Describe("worker-processes validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "worker-processes",
+					Value:            value,
+					ValidatorContext: WorkerProcessesParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("worker-processes", "auto", "mock worker-processes")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"auto",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"4",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid values
+
+			It("should fail with invalid values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectError,
+					),
+					prepareTestCase(
+						"0",
+						expectError,
+					),
+					prepareTestCase(
+						"-1",
+						expectError,
+					),
+					prepareTestCase(
+						"many",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid values
+		}) // worker-processes validation
+
+		Describe("worker-connections validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "worker-connections",
+					Value:            value,
+					ValidatorContext: WorkerConnectionsParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.Int("worker-connections", 1024, "mock worker-connections")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on positive values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"1024",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"1",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on positive values
+
+			It("should fail with non-positive values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"0",
+						expectError,
+					),
+					prepareTestCase(
+						"-1",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with non-positive values
+		}) // worker-connections validation
+
+		Describe("upstream-keepalive validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "upstream-keepalive",
+					Value:            value,
+					ValidatorContext: UpstreamKeepaliveParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.Int("upstream-keepalive", 16, "mock upstream-keepalive")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on non-negative values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"16",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"0",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on non-negative values
+
+			It("should fail with negative values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"-1",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with negative values
+		}) // upstream-keepalive validation
+
+		Describe("upstream-zone-size validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "upstream-zone-size",
+					Value:            value,
+					ValidatorContext: UpstreamZoneSizeParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("upstream-zone-size", "64k", "mock upstream-zone-size")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid sizes", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"64k",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"32K",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"32768",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"1m",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid sizes
+
+			It("should fail with invalid sizes", func() {
+				table := []testCase{
+					prepareTestCase(
+						"0",
+						expectError,
+					),
+					prepareTestCase(
+						"16k",
+						expectError,
+					),
+					prepareTestCase(
+						"64kb",
+						expectError,
+					),
+					prepareTestCase(
+						"-64k",
+						expectError,
+					),
+					prepareTestCase(
+						"99999999999999999999g",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid sizes
+		}) // upstream-zone-size validation
+
+		Describe("resolver validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "resolver",
+					Value:            value,
+					ValidatorContext: ResolverParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("resolver", "", "mock resolver")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid addresses", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"10.96.0.10",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"10.96.0.10:53, fd00::10, [fd00::10]:5353",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid addresses
+
+			It("should fail with invalid addresses", func() {
+				table := []testCase{
+					prepareTestCase(
+						"kube-dns.kube-system",
+						expectError,
+					),
+					prepareTestCase(
+						"10.96.0.10:dns",
+						expectError,
+					),
+					prepareTestCase(
+						"10.96.0.10:0",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid addresses
+		}) // resolver validation
+
+		Describe("service-store validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "service-store",
+					Value:            value,
+					ValidatorContext: ServiceStoreParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("service-store", "cluster-ip", "mock service-store")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on supported values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"cluster-ip",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"static",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on supported values
+
+			It("should fail with unsupported values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectError,
+					),
+					prepareTestCase(
+						"endpoints",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with unsupported values
+		}) // service-store validation
+
+		Describe("static-service-addresses validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "static-service-addresses",
+					Value:            value,
+					ValidatorContext: StaticServiceAddressesParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("static-service-addresses", "", "mock static-service-addresses")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid addresses", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"default/coffee:80=127.0.0.1",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"default/coffee:80=127.0.0.1,default/tea:8080=tea.local",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid addresses
+
+			It("should fail with invalid addresses", func() {
+				table := []testCase{
+					prepareTestCase(
+						"default/coffee:80",
+						expectError,
+					),
+					prepareTestCase(
+						"coffee:80=127.0.0.1",
+						expectError,
+					),
+					prepareTestCase(
+						"default/coffee=127.0.0.1",
+						expectError,
+					),
+					prepareTestCase(
+						"default/coffee:0=127.0.0.1",
+						expectError,
+					),
+					prepareTestCase(
+						"default/coffee:80=not_an_address",
+						expectError,
+					),
+					prepareTestCase(
+						"default/coffee:80=127.0.0.1,default/coffee:80=127.0.0.2",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid addresses
+		}) // static-service-addresses validation
+
+		Describe("default-backend validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "default-backend",
+					Value:            value,
+					ValidatorContext: DefaultBackendParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("default-backend", "", "mock default-backend")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid service ports", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"default/fallback:80",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid service ports
+
+			It("should fail with invalid service ports", func() {
+				table := []testCase{
+					prepareTestCase(
+						"default/fallback",
+						expectError,
+					),
+					prepareTestCase(
+						"fallback:80",
+						expectError,
+					),
+					prepareTestCase(
+						"default/fallback:0",
+						expectError,
+					),
+					prepareTestCase(
+						"default/Fallback:80",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid service ports
+		}) // default-backend validation
+
+		Describe("client-max-body-size validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "client-max-body-size",
+					Value:            value,
+					ValidatorContext: ClientMaxBodySizeParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("client-max-body-size", "", "mock client-max-body-size")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid sizes", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"0",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"1024",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"10m",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"1G",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid sizes
+
+			It("should fail with invalid sizes", func() {
+				table := []testCase{
+					prepareTestCase(
+						"10mb",
+						expectError,
+					),
+					prepareTestCase(
+						"-1",
+						expectError,
+					),
+					prepareTestCase(
+						"m",
+						expectError,
+					),
+					prepareTestCase(
+						"1.5m",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid sizes
+		}) // client-max-body-size validation
+
+		Describe("request-too-large-content-type validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "request-too-large-content-type",
+					Value:            value,
+					ValidatorContext: RequestTooLargeContentTypeParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("request-too-large-content-type", "text/plain", "mock request-too-large-content-type")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid content types", func() {
+				table := []testCase{
+					prepareTestCase(
+						"text/plain",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"application/json",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"application/problem+json",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid content types
+
+			It("should fail with invalid content types", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectError,
+					),
+					prepareTestCase(
+						"json",
+						expectError,
+					),
+					prepareTestCase(
+						"application/json; charset=utf-8",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid content types
+		}) // request-too-large-content-type validation
+
+		Describe("default-type validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "default-type",
+					Value:            value,
+					ValidatorContext: DefaultTypeParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("default-type", "", "mock default-type")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"text/plain",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"application/vnd.api+json",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid values
+
+			It("should fail with invalid values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"text",
+						expectError,
+					),
+					prepareTestCase(
+						"text/plain; charset=utf-8",
+						expectError,
+					),
+					prepareTestCase(
+						"text/plain;",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid values
+		}) // default-type validation
+
+		Describe("hsts-max-age validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "hsts-max-age",
+					Value:            value,
+					ValidatorContext: HSTSMaxAgeParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.Int("hsts-max-age", 31536000, "mock hsts-max-age")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on non-negative values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"0",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"63072000",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on non-negative values
+
+			It("should fail with negative values", func() {
+				table := []testCase{
+					prepareTestCase(
+						"-1",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with negative values
+		}) // hsts-max-age validation
+
+		Describe("hsts-preload validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "hsts-preload",
+					Value:            value,
+					ValidatorContext: HSTSPreloadParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.Bool("hsts-preload", false, "mock hsts-preload")
+				_ = mockFlags.Bool("hsts-include-subdomains", true, "mock hsts-include-subdomains")
+				_ = mockFlags.Int("hsts-max-age", 31536000, "mock hsts-max-age")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed when the requirements of the preload lists are met", func() {
+				table := []testCase{
+					prepareTestCase(
+						"false",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"true",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed when the requirements of the preload lists are met
+
+			It("should fail without hsts-include-subdomains", func() {
+				Expect(mockFlags.Set("hsts-include-subdomains", "false")).To(Succeed())
+
+				runner([]testCase{prepareTestCase("true", expectError)})
+			}) // should fail without hsts-include-subdomains
+
+			It("should fail with an hsts-max-age of less than a year", func() {
+				Expect(mockFlags.Set("hsts-max-age", "86400")).To(Succeed())
+
+				runner([]testCase{prepareTestCase("true", expectError)})
+			}) // should fail with an hsts-max-age of less than a year
+		}) // hsts-preload validation
+
+		Describe("nginx-health-port validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "nginx-health-port",
+					Value:            value,
+					ValidatorContext: NginxHealthPortParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.Int("nginx-health-port", 0, "mock nginx-health-port")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid ports", func() {
+				table := []testCase{
+					prepareTestCase(
+						"0",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"8082",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid ports
+
+			It("should fail with invalid ports", func() {
+				table := []testCase{
+					prepareTestCase(
+						"-1",
+						expectError,
+					),
+					prepareTestCase(
+						"65536",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid ports
+		}) // nginx-health-port validation
+
+		Describe("nginx-health-path validation", func() {
+			prepareTestCase := func(value string, expError bool) testCase {
+				return testCase{
+					Flag:             "nginx-health-path",
+					Value:            value,
+					ValidatorContext: NginxHealthPathParam(),
+					ExpError:         expError,
+				}
+			}
+
+			BeforeEach(func() {
+				mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+				_ = mockFlags.String("nginx-health-path", "/nginx-health", "mock nginx-health-path")
+				err := mockFlags.Parse([]string{})
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				mockFlags = nil
+			})
+
+			It("should succeed on valid paths", func() {
+				table := []testCase{
+					prepareTestCase(
+						"/nginx-health",
+						expectSuccess,
+					),
+					prepareTestCase(
+						"/health/ready",
+						expectSuccess,
+					),
+				}
+
+				runner(table)
+			}) // should succeed on valid paths
+
+			It("should fail with invalid paths", func() {
+				table := []testCase{
+					prepareTestCase(
+						"",
+						expectError,
+					),
+					prepareTestCase(
+						"nginx-health",
+						expectError,
+					),
+					prepareTestCase(
+						"/health; return 500",
+						expectError,
+					),
+				}
+
+				runner(table)
+			}) // should fail with invalid paths
+		}) // nginx-health-path validation
+
+		Describe("bind address validation", func() {
+			for _, v := range []ValidatorContext{HealthProbeBindAddressParam(), MetricsBindAddressParam()} {
+				v := v
+				name := v.Key
+
+				Describe(name, func() {
+					prepareTestCase := func(value string, expError bool) testCase {
+						return testCase{
+							Flag:             name,
+							Value:            value,
+							ValidatorContext: v,
+							ExpError:         expError,
+						}
+					}
+
+					BeforeEach(func() {
+						mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+						_ = mockFlags.String(name, ":8080", "mock "+name)
+						err := mockFlags.Parse([]string{})
+						Expect(err).ToNot(HaveOccurred())
+					})
+					AfterEach(func() {
+						mockFlags = nil
+					})
+
+					It("should succeed on valid address", func() {
+						table := []testCase{
+							prepareTestCase(
+								":8080",
+								expectSuccess,
+							),
+							prepareTestCase(
+								"127.0.0.1:9113",
+								expectSuccess,
+							),
+							prepareTestCase(
+								"localhost:65535",
+								expectSuccess,
+							),
+							prepareTestCase(
+								"[::1]:8081",
+								expectSuccess,
+							),
+						}
+
+						runner(table)
+					}) // should succeed on valid address
+
+					It("should fail with out-of-range port", func() {
+						table := []testCase{
+							prepareTestCase(
+								":0",
+								expectError,
+							),
+							prepareTestCase(
+								":65536",
+								expectError,
+							),
+						}
+
+						runner(table)
+					}) // should fail with out-of-range port
+
+					It("should fail with malformed address", func() {
+						table := []testCase{
+							prepareTestCase(
+								"",
+								expectError,
+							),
+							prepareTestCase(
+								"8080",
+								expectError,
+							),
+							prepareTestCase(
+								"localhost:",
+								expectError,
+							),
+							prepareTestCase(
+								"localhost:http",
+								expectError,
+							),
+							prepareTestCase(
+								"::1:8080",
+								expectError,
+							),
+						}
+
+						runner(table)
+					}) // should fail with malformed address
+				})
+			}
+		}) // bind address validation
+
+		Describe("proxy timeout validation", func() {
+			for _, v := range []ValidatorContext{
+				ProxyConnectTimeoutParam(),
+				ProxyReadTimeoutParam(),
+				ProxySendTimeoutParam(),
+			} {
+				v := v
+				name := v.Key
+
+				Describe(name, func() {
+					prepareTestCase := func(value string, expError bool) testCase {
+						return testCase{
+							Flag:             name,
+							Value:            value,
+							ValidatorContext: v,
+							ExpError:         expError,
+						}
+					}
+
+					BeforeEach(func() {
+						mockFlags = flag.NewFlagSet("mock", flag.PanicOnError)
+						_ = mockFlags.Duration(name, 0, "mock "+name)
+						err := mockFlags.Parse([]string{})
+						Expect(err).ToNot(HaveOccurred())
+					})
+					AfterEach(func() {
+						mockFlags = nil
+					})
+
+					It("should succeed on valid timeout", func() {
+						table := []testCase{
+							prepareTestCase(
+								"0s",
+								expectSuccess,
+							),
+							prepareTestCase(
+								"1ms",
+								expectSuccess,
+							),
+							prepareTestCase(
+								"1m30s",
+								expectSuccess,
+							),
+						}
+
+						runner(table)
+					}) // should succeed on valid timeout
+
+					It("should fail with invalid timeout", func() {
+						table := []testCase{
+							prepareTestCase(
+								"-5s",
+								expectError,
+							),
+							prepareTestCase(
+								"500us",
+								expectError,
+							),
+						}
+
+						runner(table)
+					}) // should fail with invalid timeout
+				})
+			}
+		}) // proxy timeout validation
 	}) // CLI argument validation
 }) // end Main
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -13,4 +15,116 @@ type Config struct {
 	GatewayNsName types.NamespacedName
 	// GatewayClassName is the name of the GatewayClass resource that the Gateway will use.
 	GatewayClassName string
+	// EventBatchWindow is the time the Gateway waits after the first event of a batch before handling the batch.
+	// Events that come within the window are handled together.
+	EventBatchWindow time.Duration
+	// MinReloadInterval is the minimum interval between two NGINX reloads.
+	// The changes that come within the interval are handled together.
+	MinReloadInterval time.Duration
+	// ResolveTimeout is the time limit of the resolution of the backends of an HTTPRoute or an NGINX server.
+	ResolveTimeout time.Duration
+	// NoBackendsResponseCode and NoBackendsResponseBody are the status code and body of the responses to
+	// the requests for the rules none of whose backends can be resolved.
+	NoBackendsResponseCode int
+	NoBackendsResponseBody string
+	// HealthProbeBindAddress is the address (HOST:PORT) the health probe endpoint binds to.
+	HealthProbeBindAddress string
+	// MetricsBindAddress is the address (HOST:PORT) the metrics endpoint binds to.
+	MetricsBindAddress string
+	// RequestIDHeader is the name of the header that carries the ID of a request.
+	RequestIDHeader string
+	// ForwardedHeaders enables the X-Forwarded-For, X-Forwarded-Proto and X-Real-IP headers in the requests to
+	// the backends.
+	ForwardedHeaders bool
+	// TrustedProxies are the CIDRs of the proxies in front of NGINX that set the X-Forwarded-For header.
+	TrustedProxies []string
+	// StatusOverrides translates the status codes of the backend responses to the status codes of the responses to
+	// the clients, for example, {418: 500}.
+	StatusOverrides map[int]int
+	// BackendCACertificate is the path of the CA certificate that verifies the certificates of the HTTPS backends.
+	BackendCACertificate string
+	// OCSPStaplingTrustedCertificate is the path of the CA certificate that verifies the OCSP responses of
+	// the certificates of the HTTPS listeners. Empty means OCSP stapling is disabled.
+	OCSPStaplingTrustedCertificate string
+	// ProxyConnectTimeout is the default timeout for establishing a connection with the backends.
+	// Zero means the NGINX default.
+	ProxyConnectTimeout time.Duration
+	// ProxyReadTimeout is the default timeout between two successive reads of a response from the backends.
+	// Zero means the NGINX default.
+	ProxyReadTimeout time.Duration
+	// ProxySendTimeout is the default timeout between two successive writes of a request to the backends.
+	// Zero means the NGINX default.
+	ProxySendTimeout time.Duration
+	// SecurityHeaders enables the preset of the security headers in the responses.
+	SecurityHeaders bool
+	// HSTS enables the Strict-Transport-Security header in the responses of the HTTPS servers.
+	HSTS bool
+	// HSTSMaxAge, HSTSIncludeSubDomains and HSTSPreload are the directives of the Strict-Transport-Security header.
+	HSTSMaxAge            int
+	HSTSIncludeSubDomains bool
+	HSTSPreload           bool
+	// ServerTokens enables the version of NGINX in the Server header and the error pages.
+	ServerTokens bool
+	// SourceComments enables the comments that link the NGINX configuration to the HTTPRoutes.
+	SourceComments bool
+	// AllowSnippets allows the HTTPRoutes to add NGINX directives to their locations and servers.
+	AllowSnippets bool
+	// ClientMaxBodySize is the maximum size of the request bodies. Empty means the NGINX default.
+	ClientMaxBodySize string
+	// RequestTooLargeBody is the body of the responses to the requests whose bodies exceed the maximum size.
+	// Empty means the NGINX default page.
+	RequestTooLargeBody string
+	// RequestTooLargeContentType is the content type of RequestTooLargeBody.
+	RequestTooLargeContentType string
+	// SSLCertificateMap enables the selection of the certificates of the SSL servers by the SNI using a map.
+	SSLCertificateMap bool
+	// HTTPPort and HTTPSPort are the ports NGINX listens on for HTTP and HTTPS.
+	HTTPPort  int
+	HTTPSPort int
+	// IPFamily is the IP family of the connections NGINX accepts: ipv4, ipv6 or dual.
+	IPFamily string
+	// GatewayAddresses are the IP addresses the Gateway can request in its addresses.
+	// Empty means the addresses of the network interfaces of the pod.
+	GatewayAddresses []string
+	// DefaultType is the default content type of the responses. Empty means the NGINX default.
+	DefaultType string
+	// TemplateOverrides is the path of the file that overrides the server, location and upstream templates of
+	// the NGINX configuration. Empty means the default templates.
+	TemplateOverrides string
+	// ServiceImportBackends enables the ServiceImports of the Multi-Cluster Services API as the backends of
+	// the routes.
+	ServiceImportBackends bool
+	// ServiceStore is the name of the ServiceStore that resolves the Services, for example, cluster-ip.
+	ServiceStore string
+	// StaticServiceAddresses are the addresses of the static ServiceStore in the form namespace/name:port=address.
+	StaticServiceAddresses []string
+	// DefaultBackend is the port of the Service that receives the requests that don't match the hostname of any
+	// server, in the form namespace/name:port. Empty means NGINX responds to such requests with 404.
+	DefaultBackend string
+	// WorkerProcesses is the number of the NGINX worker processes, or auto for the number of the CPU cores.
+	WorkerProcesses string
+	// WorkerConnections is the maximum number of the simultaneous connections of an NGINX worker process.
+	WorkerConnections int
+	// UpstreamKeepalive is the maximum number of the idle keepalive connections to the backends of a rule that each
+	// NGINX worker keeps open. Zero disables the keepalive connections.
+	UpstreamKeepalive int
+	// UpstreamZoneSize is the size of the shared memory zone of every upstream, for example, 64k. Empty disables
+	// the zones.
+	UpstreamZoneSize string
+	// Resolver is the addresses of the DNS servers NGINX uses to resolve the hostnames of the backends, for example,
+	// of the ExternalName Services. Empty means the nameservers of the pod.
+	Resolver []string
+	// NormalizePaths enables the normalization of the paths of the matches and the request URIs, so that the
+	// duplicate slashes don't prevent the matching.
+	NormalizePaths bool
+	// DefaultCertificate and DefaultCertificateKey are the paths of the certificate and key of the default SSL
+	// server. Empty means the default SSL server rejects the handshakes.
+	DefaultCertificate    string
+	DefaultCertificateKey string
+	// NginxHealthPort is the port of the health check endpoint of NGINX. 0 means the endpoint is disabled.
+	NginxHealthPort int
+	// NginxHealthPath is the path of the health check endpoint of NGINX.
+	NginxHealthPath string
+	// NginxHealthAccessLog enables writing the requests of the health check endpoint to the access log.
+	NginxHealthAccessLog bool
 }
@@ -1,6 +1,9 @@
 package events
 
 import (
+	"fmt"
+	"time"
+
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -13,6 +16,8 @@ type EventBatch []interface{}
 type UpsertEvent struct {
 	// Resource is the resource that is being upserted.
 	Resource client.Object
+	// ReceivedAt is the time when the event was received from the controller. The zero value means unknown.
+	ReceivedAt time.Time
 }
 
 // DeleteEvent representing deleting a resource.
@@ -21,4 +26,68 @@ type DeleteEvent struct {
 	NamespacedName types.NamespacedName
 	// Type is the resource type. For example, if the event is for *v1beta1.HTTPRoute, pass &v1beta1.HTTPRoute{} as Type.
 	Type client.Object
+	// ReceivedAt is the time when the event was received from the controller. The zero value means unknown.
+	ReceivedAt time.Time
+}
+
+// eventKey identifies the resource an event is for.
+type eventKey struct {
+	// kind is the Go type of the resource. For example, *v1beta1.HTTPRoute.
+	kind   string
+	nsname types.NamespacedName
+}
+
+func getEventKey(e interface{}) (eventKey, bool) {
+	switch typedEvent := e.(type) {
+	case *UpsertEvent:
+		return eventKey{
+			kind: fmt.Sprintf("%T", typedEvent.Resource),
+			nsname: types.NamespacedName{
+				Namespace: typedEvent.Resource.GetNamespace(),
+				Name:      typedEvent.Resource.GetName(),
+			},
+		}, true
+	case *DeleteEvent:
+		return eventKey{
+			kind:   fmt.Sprintf("%T", typedEvent.Type),
+			nsname: typedEvent.NamespacedName,
+		}, true
+	default:
+		return eventKey{}, false
+	}
+}
+
+func getReceivedAt(e interface{}) time.Time {
+	switch typedEvent := e.(type) {
+	case *UpsertEvent:
+		return typedEvent.ReceivedAt
+	case *DeleteEvent:
+		return typedEvent.ReceivedAt
+	default:
+		return time.Time{}
+	}
+}
+
+// coalesceEvents removes the events that are superseded by later events for the same resource in the batch.
+// For example, if a resource is deleted and then upserted, only the UpsertEvent will remain.
+// The relative order of the remaining events is preserved. Events of unknown types are always kept.
+func coalesceEvents(batch EventBatch) EventBatch {
+	lastIdx := make(map[eventKey]int, len(batch))
+
+	for i, e := range batch {
+		if key, ok := getEventKey(e); ok {
+			lastIdx[key] = i
+		}
+	}
+
+	result := make(EventBatch, 0, len(batch))
+
+	for i, e := range batch {
+		if key, ok := getEventKey(e); ok && lastIdx[key] != i {
+			continue
+		}
+		result = append(result, e)
+	}
+
+	return result
 }
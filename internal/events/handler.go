@@ -1,11 +1,15 @@
 package events
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 
 	"github.com/go-logr/logr"
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/nginx/config"
@@ -30,6 +34,8 @@ type EventHandlerConfig struct {
 	Processor state.ChangeProcessor
 	// ServiceStore is the state ServiceStore.
 	ServiceStore state.ServiceStore
+	// ServiceImportStore is the state ServiceImportStore. It must be set if the ServiceImports are watched.
+	ServiceImportStore state.ServiceImportStore
 	// SecretStore is the state SecretStore.
 	SecretStore state.SecretStore
 	// SecretMemoryManager is the state SecretMemoryManager.
@@ -52,6 +58,12 @@ type EventHandlerConfig struct {
 // (2) Keeping the statuses of the Gateway API resources updated.
 type EventHandlerImpl struct {
 	cfg EventHandlerConfig
+	// httpCfg, streamCfg and mainCfg are the last NGINX configuration that was successfully applied.
+	httpCfg   []byte
+	streamCfg []byte
+	mainCfg   []byte
+	// secretsHash is the hash of the secrets of the last NGINX configuration that was successfully applied.
+	secretsHash string
 }
 
 // NewEventHandlerImpl creates a new EventHandlerImpl.
@@ -74,44 +86,103 @@ func (h *EventHandlerImpl) HandleEventBatch(ctx context.Context, batch EventBatc
 		}
 	}
 
-	changed, conf, statuses := h.cfg.Processor.Process()
+	changed, conf, statuses := h.cfg.Processor.Process(ctx)
 	if !changed {
 		h.cfg.Logger.Info("Handling events didn't result into NGINX configuration changes")
 		return
 	}
 
-	err := h.updateNginx(ctx, conf)
+	warnings, err := h.updateNginx(ctx, conf)
 	if err != nil {
 		h.cfg.Logger.Error(err, "Failed to update NGINX configuration")
 	} else {
 		h.cfg.Logger.Info("NGINX configuration was successfully updated")
 	}
 
+	if statuses.GatewayStatus != nil {
+		setListenersProgrammed(statuses.GatewayStatus.ListenerStatuses, err == nil)
+	}
+	addWarningsToStatuses(statuses, warnings)
+
 	h.cfg.StatusUpdater.Update(ctx, statuses)
 }
 
-func (h *EventHandlerImpl) updateNginx(ctx context.Context, conf state.Configuration) error {
+// updateNginx updates NGINX configuration. It returns the warnings produced while generating the configuration.
+func (h *EventHandlerImpl) updateNginx(ctx context.Context, conf state.Configuration) (config.Warnings, error) {
 	// Write all secrets (nuke and pave).
 	// This will remove all secrets in the secrets directory before writing the requested secrets.
 	// FIXME(kate-osborn): We may want to rethink this approach in the future and write and remove secrets individually.
-	err := h.cfg.SecretMemoryManager.WriteAllRequestedSecrets()
+	secretsHash, err := h.cfg.SecretMemoryManager.WriteAllRequestedSecrets()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, changed, warnings, err := h.cfg.Generator.GenerateAndCompare(ctx, h.httpCfg, conf)
+	if err != nil {
+		return warnings, fmt.Errorf("failed to generate http configuration: %w", err)
+	}
+
+	streamCfg, streamWarnings, err := h.cfg.Generator.GenerateStream(ctx, conf)
+	warnings.Add(streamWarnings)
+	if err != nil {
+		return warnings, fmt.Errorf("failed to generate stream configuration: %w", err)
+	}
+
+	streamChanged := !bytes.Equal(h.streamCfg, streamCfg)
+
+	mainCfg, err := h.cfg.Generator.GenerateMain()
 	if err != nil {
-		return err
+		return warnings, fmt.Errorf("failed to generate main configuration: %w", err)
 	}
 
-	cfg, warnings := h.cfg.Generator.Generate(conf)
+	mainChanged := !bytes.Equal(h.mainCfg, mainCfg)
+
+	h.logWarnings(warnings)
+
+	// NGINX reads the files of the secrets only when it reloads, so a rotated secret requires a reload
+	// even if the configuration is the same.
+	secretsChanged := h.secretsHash != secretsHash
+
+	if !changed && !streamChanged && !mainChanged && !secretsChanged {
+		h.cfg.Logger.Info("NGINX configuration is unchanged, skipping reload")
+		return warnings, nil
+	}
 
 	// For now, we keep all http servers in one config
 	// We might rethink that. For example, we can write each server to its file
 	// or group servers in some way.
 	err = h.cfg.NginxFileMgr.WriteHTTPServersConfig("http-servers", cfg)
 	if err != nil {
-		return err
+		return warnings, err
+	}
+
+	err = h.cfg.NginxFileMgr.WriteStreamServersConfig("stream-servers", streamCfg)
+	if err != nil {
+		return warnings, err
+	}
+
+	err = h.cfg.NginxFileMgr.WriteMainConfig("main", mainCfg)
+	if err != nil {
+		return warnings, err
 	}
 
+	err = h.cfg.NginxRuntimeMgr.Reload(ctx)
+	if err != nil {
+		return warnings, err
+	}
+
+	h.httpCfg = cfg
+	h.streamCfg = streamCfg
+	h.mainCfg = mainCfg
+	h.secretsHash = secretsHash
+
+	return warnings, nil
+}
+
+func (h *EventHandlerImpl) logWarnings(warnings config.Warnings) {
 	for obj, objWarnings := range warnings {
 		for _, w := range objWarnings {
-			// FIXME(pleshakov): report warnings via Object status
+			// FIXME(pleshakov): report warnings via the status of the objects other than HTTPRoutes
 			h.cfg.Logger.Info("Got warning while generating config",
 				"kind", obj.GetObjectKind().GroupVersionKind().Kind,
 				"namespace", obj.GetNamespace(),
@@ -119,8 +190,6 @@ func (h *EventHandlerImpl) updateNginx(ctx context.Context, conf state.Configura
 				"warning", w)
 		}
 	}
-
-	return h.cfg.NginxRuntimeMgr.Reload(ctx)
 }
 
 func (h *EventHandlerImpl) propagateUpsert(e *UpsertEvent) {
@@ -131,32 +200,84 @@ func (h *EventHandlerImpl) propagateUpsert(e *UpsertEvent) {
 		h.cfg.Processor.CaptureUpsertChange(r)
 	case *v1beta1.HTTPRoute:
 		h.cfg.Processor.CaptureUpsertChange(r)
+	case *v1alpha2.TCPRoute:
+		h.cfg.Processor.CaptureUpsertChange(r)
 	case *apiv1.Service:
 		// FIXME(pleshakov): make sure the affected hosts are updated
 		h.cfg.ServiceStore.Upsert(r)
+	case *unstructured.Unstructured:
+		if !state.IsServiceImport(r) {
+			panic(fmt.Errorf("unknown resource kind %s", r.GroupVersionKind().String()))
+		}
+		// FIXME(pleshakov): make sure the affected hosts are updated
+		h.cfg.ServiceImportStore.Upsert(r)
 	case *apiv1.Secret:
-		// FIXME(kate-osborn): need to handle certificate rotation
 		h.cfg.SecretStore.Upsert(r)
+		// the processor rebuilds the configuration if the Secret is referenced by a Gateway
+		h.cfg.Processor.CaptureUpsertChange(r)
 	default:
 		panic(fmt.Errorf("unknown resource type %T", e.Resource))
 	}
 }
 
 func (h *EventHandlerImpl) propagateDelete(e *DeleteEvent) {
-	switch e.Type.(type) {
+	switch t := e.Type.(type) {
 	case *v1beta1.GatewayClass:
 		h.cfg.Processor.CaptureDeleteChange(e.Type, e.NamespacedName)
 	case *v1beta1.Gateway:
 		h.cfg.Processor.CaptureDeleteChange(e.Type, e.NamespacedName)
 	case *v1beta1.HTTPRoute:
 		h.cfg.Processor.CaptureDeleteChange(e.Type, e.NamespacedName)
+	case *v1alpha2.TCPRoute:
+		h.cfg.Processor.CaptureDeleteChange(e.Type, e.NamespacedName)
 	case *apiv1.Service:
 		// FIXME(pleshakov): make sure the affected hosts are updated
 		h.cfg.ServiceStore.Delete(e.NamespacedName)
+	case *unstructured.Unstructured:
+		if !state.IsServiceImport(t) {
+			panic(fmt.Errorf("unknown resource kind %s", t.GroupVersionKind().String()))
+		}
+		// FIXME(pleshakov): make sure the affected hosts are updated
+		h.cfg.ServiceImportStore.Delete(e.NamespacedName)
 	case *apiv1.Secret:
-		// FIXME(kate-osborn): make sure that affected servers are updated
 		h.cfg.SecretStore.Delete(e.NamespacedName)
+		h.cfg.Processor.CaptureDeleteChange(e.Type, e.NamespacedName)
 	default:
 		panic(fmt.Errorf("unknown resource type %T", e.Type))
 	}
 }
+
+// setListenersProgrammed marks the valid listeners as programmed if NGINX was updated with their configuration.
+func setListenersProgrammed(statuses state.ListenerStatuses, nginxUpdated bool) {
+	for name, s := range statuses {
+		s.Programmed = s.Valid && nginxUpdated
+		statuses[name] = s
+	}
+}
+
+// addWarningsToStatuses adds the warnings of the HTTPRoutes and TCPRoutes to their statuses.
+// Duplicate warnings are removed.
+func addWarningsToStatuses(statuses state.Statuses, warnings config.Warnings) {
+	for obj, objWarnings := range warnings.Dedup() {
+		nsname := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+
+		switch obj.(type) {
+		case *v1beta1.HTTPRoute:
+			status, exists := statuses.HTTPRouteStatuses[nsname]
+			if !exists {
+				continue
+			}
+
+			status.Warnings = objWarnings
+			statuses.HTTPRouteStatuses[nsname] = status
+		case *v1alpha2.TCPRoute:
+			status, exists := statuses.TCPRouteStatuses[nsname]
+			if !exists {
+				continue
+			}
+
+			status.Warnings = objWarnings
+			statuses.TCPRouteStatuses[nsname] = status
+		}
+	}
+}
@@ -2,16 +2,19 @@ package events_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/events"
@@ -41,6 +44,7 @@ var _ = Describe("EventHandler", func() {
 		handler                 *events.EventHandlerImpl
 		fakeProcessor           *statefakes.FakeChangeProcessor
 		fakeServiceStore        *statefakes.FakeServiceStore
+		fakeServiceImportStore  *statefakes.FakeServiceImportStore
 		fakeSecretStore         *statefakes.FakeSecretStore
 		fakeSecretMemoryManager *statefakes.FakeSecretDiskMemoryManager
 		fakeGenerator           *configfakes.FakeGenerator
@@ -49,17 +53,32 @@ var _ = Describe("EventHandler", func() {
 		fakeStatusUpdater       *statusfakes.FakeUpdater
 	)
 
-	expectReconfig := func(expectedConf state.Configuration, expectedCfg []byte, expectedStatuses state.Statuses) {
+	expectReconfig := func(
+		expectedConf state.Configuration,
+		expectedCfg []byte,
+		expectedStreamCfg []byte,
+		expectedStatuses state.Statuses,
+	) {
 		Expect(fakeProcessor.ProcessCallCount()).Should(Equal(1))
 
-		Expect(fakeGenerator.GenerateCallCount()).Should(Equal(1))
-		Expect(fakeGenerator.GenerateArgsForCall(0)).Should(Equal(expectedConf))
+		Expect(fakeGenerator.GenerateAndCompareCallCount()).Should(Equal(1))
+		_, _, conf := fakeGenerator.GenerateAndCompareArgsForCall(0)
+		Expect(conf).Should(Equal(expectedConf))
 
 		Expect(fakeNginxFimeMgr.WriteHTTPServersConfigCallCount()).Should(Equal(1))
 		name, cfg := fakeNginxFimeMgr.WriteHTTPServersConfigArgsForCall(0)
 		Expect(name).Should(Equal("http-servers"))
 		Expect(cfg).Should(Equal(expectedCfg))
 
+		Expect(fakeGenerator.GenerateStreamCallCount()).Should(Equal(1))
+		_, streamConf := fakeGenerator.GenerateStreamArgsForCall(0)
+		Expect(streamConf).Should(Equal(expectedConf))
+
+		Expect(fakeNginxFimeMgr.WriteStreamServersConfigCallCount()).Should(Equal(1))
+		name, cfg = fakeNginxFimeMgr.WriteStreamServersConfigArgsForCall(0)
+		Expect(name).Should(Equal("stream-servers"))
+		Expect(cfg).Should(Equal(expectedStreamCfg))
+
 		Expect(fakeNginxRuntimeMgr.ReloadCallCount()).Should(Equal(1))
 
 		Expect(fakeStatusUpdater.UpdateCallCount()).Should(Equal(1))
@@ -70,6 +89,7 @@ var _ = Describe("EventHandler", func() {
 	BeforeEach(func() {
 		fakeProcessor = &statefakes.FakeChangeProcessor{}
 		fakeServiceStore = &statefakes.FakeServiceStore{}
+		fakeServiceImportStore = &statefakes.FakeServiceImportStore{}
 		fakeSecretMemoryManager = &statefakes.FakeSecretDiskMemoryManager{}
 		fakeSecretStore = &statefakes.FakeSecretStore{}
 		fakeGenerator = &configfakes.FakeGenerator{}
@@ -80,6 +100,7 @@ var _ = Describe("EventHandler", func() {
 		handler = events.NewEventHandlerImpl(events.EventHandlerConfig{
 			Processor:           fakeProcessor,
 			ServiceStore:        fakeServiceStore,
+			ServiceImportStore:  fakeServiceImportStore,
 			SecretStore:         fakeSecretStore,
 			SecretMemoryManager: fakeSecretMemoryManager,
 			Generator:           fakeGenerator,
@@ -99,7 +120,10 @@ var _ = Describe("EventHandler", func() {
 				fakeProcessor.ProcessReturns(changed, fakeConf, fakeStatuses)
 
 				fakeCfg := []byte("fake")
-				fakeGenerator.GenerateReturns(fakeCfg, config.Warnings{})
+				fakeGenerator.GenerateAndCompareReturns(fakeCfg, true, config.Warnings{}, nil)
+
+				fakeStreamCfg := []byte("fake-stream")
+				fakeGenerator.GenerateStreamReturns(fakeStreamCfg, config.Warnings{}, nil)
 
 				batch := []interface{}{e}
 
@@ -120,23 +144,363 @@ var _ = Describe("EventHandler", func() {
 				}
 
 				// Check that a reconfig happened
-				expectReconfig(fakeConf, fakeCfg, fakeStatuses)
+				expectReconfig(fakeConf, fakeCfg, fakeStreamCfg, fakeStatuses)
 
 			},
 			Entry("HTTPRoute upsert", &events.UpsertEvent{Resource: &v1beta1.HTTPRoute{}}),
 			Entry("Gateway upsert", &events.UpsertEvent{Resource: &v1beta1.Gateway{}}),
 			Entry("GatewayClass upsert", &events.UpsertEvent{Resource: &v1beta1.GatewayClass{}}),
+			Entry("TCPRoute upsert", &events.UpsertEvent{Resource: &v1alpha2.TCPRoute{}}),
 			Entry("HTTPRoute delete", &events.DeleteEvent{Type: &v1beta1.HTTPRoute{}, NamespacedName: types.NamespacedName{Namespace: "test", Name: "route"}}),
 			Entry("Gateway delete", &events.DeleteEvent{Type: &v1beta1.Gateway{}, NamespacedName: types.NamespacedName{Namespace: "test", Name: "gateway"}}),
+			Entry("TCPRoute delete", &events.DeleteEvent{Type: &v1alpha2.TCPRoute{}, NamespacedName: types.NamespacedName{Namespace: "test", Name: "route"}}),
 			Entry("GatewayClass delete", &events.DeleteEvent{Type: &v1beta1.GatewayClass{}, NamespacedName: types.NamespacedName{Name: "class"}}),
 		)
+
+		It("should not reload NGINX when the configuration is unchanged", func() {
+			fakeStatuses := state.Statuses{}
+			fakeProcessor.ProcessReturns(true, state.Configuration{}, fakeStatuses)
+
+			fakeCfg := []byte("fake")
+			fakeStreamCfg := []byte("fake-stream")
+			fakeGenerator.GenerateAndCompareReturns(fakeCfg, true, config.Warnings{}, nil)
+			fakeGenerator.GenerateStreamReturns(fakeStreamCfg, config.Warnings{}, nil)
+
+			e := &events.UpsertEvent{Resource: &v1beta1.HTTPRoute{}}
+
+			handler.HandleEventBatch(context.TODO(), []interface{}{e})
+
+			Expect(fakeNginxRuntimeMgr.ReloadCallCount()).Should(Equal(1))
+
+			// the same configuration is generated for the next batch
+			fakeGenerator.GenerateAndCompareReturns(fakeCfg, false, config.Warnings{}, nil)
+
+			handler.HandleEventBatch(context.TODO(), []interface{}{e})
+
+			Expect(fakeGenerator.GenerateAndCompareCallCount()).Should(Equal(2))
+			_, prev, _ := fakeGenerator.GenerateAndCompareArgsForCall(1)
+			Expect(prev).Should(Equal(fakeCfg))
+
+			Expect(fakeNginxFimeMgr.WriteHTTPServersConfigCallCount()).Should(Equal(1))
+			Expect(fakeNginxFimeMgr.WriteStreamServersConfigCallCount()).Should(Equal(1))
+			Expect(fakeNginxRuntimeMgr.ReloadCallCount()).Should(Equal(1))
+
+			// statuses are still updated
+			Expect(fakeStatusUpdater.UpdateCallCount()).Should(Equal(2))
+		})
+
+		It("should reload NGINX when only the stream configuration changes", func() {
+			fakeProcessor.ProcessReturns(true, state.Configuration{}, state.Statuses{})
+
+			fakeCfg := []byte("fake")
+			fakeGenerator.GenerateAndCompareReturns(fakeCfg, true, config.Warnings{}, nil)
+			fakeGenerator.GenerateStreamReturns([]byte("fake-stream"), config.Warnings{}, nil)
+
+			e := &events.UpsertEvent{Resource: &v1alpha2.TCPRoute{}}
+
+			handler.HandleEventBatch(context.TODO(), []interface{}{e})
+
+			fakeGenerator.GenerateAndCompareReturns(fakeCfg, false, config.Warnings{}, nil)
+			fakeGenerator.GenerateStreamReturns([]byte("fake-stream-changed"), config.Warnings{}, nil)
+
+			handler.HandleEventBatch(context.TODO(), []interface{}{e})
+
+			Expect(fakeNginxFimeMgr.WriteStreamServersConfigCallCount()).Should(Equal(2))
+			Expect(fakeNginxRuntimeMgr.ReloadCallCount()).Should(Equal(2))
+		})
+
+		It("should write the main configuration and reload NGINX when it changes", func() {
+			fakeProcessor.ProcessReturns(true, state.Configuration{}, state.Statuses{})
+
+			fakeCfg := []byte("fake")
+			fakeMainCfg := []byte("fake-main")
+			fakeGenerator.GenerateAndCompareReturns(fakeCfg, true, config.Warnings{}, nil)
+			fakeGenerator.GenerateStreamReturns([]byte("fake-stream"), config.Warnings{}, nil)
+			fakeGenerator.GenerateMainReturns(fakeMainCfg, nil)
+
+			e := &events.UpsertEvent{Resource: &v1beta1.HTTPRoute{}}
+
+			handler.HandleEventBatch(context.TODO(), []interface{}{e})
+
+			Expect(fakeNginxFimeMgr.WriteMainConfigCallCount()).Should(Equal(1))
+			name, cfg := fakeNginxFimeMgr.WriteMainConfigArgsForCall(0)
+			Expect(name).Should(Equal("main"))
+			Expect(cfg).Should(Equal(fakeMainCfg))
+			Expect(fakeNginxRuntimeMgr.ReloadCallCount()).Should(Equal(1))
+
+			// the main configuration only changes
+			fakeGenerator.GenerateAndCompareReturns(fakeCfg, false, config.Warnings{}, nil)
+			fakeGenerator.GenerateMainReturns([]byte("fake-main-changed"), nil)
+
+			handler.HandleEventBatch(context.TODO(), []interface{}{e})
+
+			Expect(fakeNginxFimeMgr.WriteMainConfigCallCount()).Should(Equal(2))
+			Expect(fakeNginxRuntimeMgr.ReloadCallCount()).Should(Equal(2))
+		})
+
+		It("should reload NGINX when only the contents of the secrets change", func() {
+			fakeProcessor.ProcessReturns(true, state.Configuration{}, state.Statuses{})
+
+			fakeCfg := []byte("fake")
+			fakeStreamCfg := []byte("fake-stream")
+			fakeGenerator.GenerateAndCompareReturns(fakeCfg, true, config.Warnings{}, nil)
+			fakeGenerator.GenerateStreamReturns(fakeStreamCfg, config.Warnings{}, nil)
+			fakeSecretMemoryManager.WriteAllRequestedSecretsReturns("hash", nil)
+
+			e := &events.UpsertEvent{Resource: &apiv1.Secret{}}
+
+			handler.HandleEventBatch(context.TODO(), []interface{}{e})
+
+			// the certificate of the secret is rotated, but the configuration is the same
+			fakeGenerator.GenerateAndCompareReturns(fakeCfg, false, config.Warnings{}, nil)
+			fakeSecretMemoryManager.WriteAllRequestedSecretsReturns("rotated-hash", nil)
+
+			handler.HandleEventBatch(context.TODO(), []interface{}{e})
+
+			Expect(fakeNginxRuntimeMgr.ReloadCallCount()).Should(Equal(2))
+
+			// neither the configuration nor the secrets change
+			handler.HandleEventBatch(context.TODO(), []interface{}{e})
+
+			Expect(fakeNginxRuntimeMgr.ReloadCallCount()).Should(Equal(2))
+		})
+
+		DescribeTable("should not write the configuration or reload NGINX when the generation fails",
+			func(prepare func()) {
+				fakeProcessor.ProcessReturns(true, state.Configuration{}, state.Statuses{})
+				fakeGenerator.GenerateAndCompareReturns([]byte("fake"), true, config.Warnings{}, nil)
+				fakeGenerator.GenerateStreamReturns([]byte("fake-stream"), config.Warnings{}, nil)
+
+				prepare()
+
+				handler.HandleEventBatch(context.TODO(), []interface{}{&events.UpsertEvent{Resource: &v1beta1.HTTPRoute{}}})
+
+				Expect(fakeNginxFimeMgr.WriteHTTPServersConfigCallCount()).Should(Equal(0))
+				Expect(fakeNginxFimeMgr.WriteStreamServersConfigCallCount()).Should(Equal(0))
+				Expect(fakeNginxRuntimeMgr.ReloadCallCount()).Should(Equal(0))
+
+				// statuses are still updated
+				Expect(fakeStatusUpdater.UpdateCallCount()).Should(Equal(1))
+			},
+			Entry("http configuration", func() {
+				fakeGenerator.GenerateAndCompareReturns(nil, false, config.Warnings{}, errors.New("template error"))
+			}),
+			Entry("stream configuration", func() {
+				fakeGenerator.GenerateStreamReturns(nil, config.Warnings{}, errors.New("template error"))
+			}),
+			Entry("main configuration", func() {
+				fakeGenerator.GenerateMainReturns(nil, errors.New("template error"))
+			}),
+		)
+
+		DescribeTable("should mark the valid listeners as programmed only when NGINX is updated",
+			func(reloadErr error, programmed bool) {
+				gwNsName := types.NamespacedName{Namespace: "test", Name: "gateway"}
+
+				fakeStatuses := state.Statuses{
+					GatewayStatus: &state.GatewayStatus{
+						NsName: gwNsName,
+						ListenerStatuses: state.ListenerStatuses{
+							"valid":   {Valid: true},
+							"invalid": {Valid: false},
+						},
+					},
+				}
+				fakeProcessor.ProcessReturns(true, state.Configuration{}, fakeStatuses)
+				fakeGenerator.GenerateAndCompareReturns([]byte("fake"), true, config.Warnings{}, nil)
+				fakeGenerator.GenerateStreamReturns([]byte("fake-stream"), config.Warnings{}, nil)
+				fakeNginxRuntimeMgr.ReloadReturns(reloadErr)
+
+				handler.HandleEventBatch(context.TODO(), []interface{}{&events.UpsertEvent{Resource: &v1beta1.Gateway{}}})
+
+				expectedStatuses := state.Statuses{
+					GatewayStatus: &state.GatewayStatus{
+						NsName: gwNsName,
+						ListenerStatuses: state.ListenerStatuses{
+							"valid":   {Valid: true, Programmed: programmed},
+							"invalid": {Valid: false, Programmed: false},
+						},
+					},
+				}
+
+				Expect(fakeStatusUpdater.UpdateCallCount()).Should(Equal(1))
+				_, statuses := fakeStatusUpdater.UpdateArgsForCall(0)
+				Expect(statuses).Should(Equal(expectedStatuses))
+			},
+			Entry("NGINX is reloaded", nil, true),
+			Entry("NGINX fails to reload", errors.New("reload error"), false),
+		)
+
+		It("should report the deduplicated warnings of HTTPRoutes in their statuses", func() {
+			hr := &v1beta1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "route",
+				},
+			}
+			hrNsName := types.NamespacedName{Namespace: "test", Name: "route"}
+
+			fakeStatuses := state.Statuses{
+				HTTPRouteStatuses: state.HTTPRouteStatuses{
+					hrNsName: {
+						ParentStatuses: state.ParentStatuses{
+							"http": {Attached: true},
+						},
+					},
+				},
+			}
+			fakeProcessor.ProcessReturns(true, state.Configuration{}, fakeStatuses)
+
+			fakeGenerator.GenerateAndCompareReturns([]byte("fake"), true, config.Warnings{
+				hr: []string{"empty backend refs", "empty backend refs", "port is nil"},
+			}, nil)
+			fakeGenerator.GenerateStreamReturns([]byte("fake-stream"), config.Warnings{}, nil)
+
+			handler.HandleEventBatch(context.TODO(), []interface{}{&events.UpsertEvent{Resource: hr}})
+
+			expectedStatuses := state.Statuses{
+				HTTPRouteStatuses: state.HTTPRouteStatuses{
+					hrNsName: {
+						ParentStatuses: state.ParentStatuses{
+							"http": {Attached: true},
+						},
+						Warnings: []string{"empty backend refs", "port is nil"},
+					},
+				},
+			}
+
+			Expect(fakeStatusUpdater.UpdateCallCount()).Should(Equal(1))
+			_, statuses := fakeStatusUpdater.UpdateArgsForCall(0)
+			Expect(statuses).Should(Equal(expectedStatuses))
+		})
+
+		It("should report the warnings of TCPRoutes in their statuses", func() {
+			tr := &v1alpha2.TCPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "tcp-route",
+				},
+			}
+			trNsName := types.NamespacedName{Namespace: "test", Name: "tcp-route"}
+
+			fakeStatuses := state.Statuses{
+				TCPRouteStatuses: state.TCPRouteStatuses{
+					trNsName: {
+						ParentStatuses: state.ParentStatuses{
+							"tcp": {Attached: true},
+						},
+					},
+				},
+			}
+			fakeProcessor.ProcessReturns(true, state.Configuration{}, fakeStatuses)
+
+			fakeGenerator.GenerateAndCompareReturns([]byte("fake"), true, config.Warnings{}, nil)
+			fakeGenerator.GenerateStreamReturns([]byte("fake-stream"), config.Warnings{
+				tr: []string{"only the first backendRef of the first rule is supported; backendRef 1 of rule 0 is ignored"},
+			}, nil)
+
+			handler.HandleEventBatch(context.TODO(), []interface{}{&events.UpsertEvent{Resource: tr}})
+
+			expectedStatuses := state.Statuses{
+				TCPRouteStatuses: state.TCPRouteStatuses{
+					trNsName: {
+						ParentStatuses: state.ParentStatuses{
+							"tcp": {Attached: true},
+						},
+						Warnings: []string{
+							"only the first backendRef of the first rule is supported; backendRef 1 of rule 0 is ignored",
+						},
+					},
+				},
+			}
+
+			Expect(fakeStatusUpdater.UpdateCallCount()).Should(Equal(1))
+			_, statuses := fakeStatusUpdater.UpdateArgsForCall(0)
+			Expect(statuses).Should(Equal(expectedStatuses))
+		})
+	})
+
+	Describe("Report the warnings of the generated configuration", func() {
+		It("should report the empty backend refs of an HTTPRoute in its status", func() {
+			handler = events.NewEventHandlerImpl(events.EventHandlerConfig{
+				Processor:           fakeProcessor,
+				ServiceStore:        fakeServiceStore,
+				SecretStore:         fakeSecretStore,
+				SecretMemoryManager: fakeSecretMemoryManager,
+				Generator:           config.NewGeneratorImpl(fakeServiceStore),
+				Logger:              zap.New(),
+				NginxFileMgr:        fakeNginxFimeMgr,
+				NginxRuntimeMgr:     fakeNginxRuntimeMgr,
+				StatusUpdater:       fakeStatusUpdater,
+			})
+
+			path := "/"
+			hr := &v1beta1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "route",
+				},
+				Spec: v1beta1.HTTPRouteSpec{
+					Rules: []v1beta1.HTTPRouteRule{
+						{
+							Matches: []v1beta1.HTTPRouteMatch{
+								{
+									Path: &v1beta1.HTTPPathMatch{
+										Value: &path,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			hrNsName := types.NamespacedName{Namespace: "test", Name: "route"}
+
+			conf := state.Configuration{
+				HTTPServers: []state.VirtualServer{
+					{
+						Hostname: "example.com",
+						PathRules: []state.PathRule{
+							{
+								Path: path,
+								MatchRules: []state.MatchRule{
+									{
+										MatchIdx: 0,
+										RuleIdx:  0,
+										Source:   hr,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			statuses := state.Statuses{
+				HTTPRouteStatuses: state.HTTPRouteStatuses{
+					hrNsName: {
+						ParentStatuses: state.ParentStatuses{
+							"http": {Attached: true},
+						},
+					},
+				},
+			}
+			fakeProcessor.ProcessReturns(true, conf, statuses)
+
+			handler.HandleEventBatch(context.TODO(), []interface{}{&events.UpsertEvent{Resource: hr}})
+
+			Expect(fakeStatusUpdater.UpdateCallCount()).Should(Equal(1))
+			_, reported := fakeStatusUpdater.UpdateArgsForCall(0)
+			Expect(reported.HTTPRouteStatuses[hrNsName].Warnings).Should(Equal([]string{"empty backend refs"}))
+		})
 	})
 
 	Describe("Process Kubernetes resources events", func() {
 		expectNoReconfig := func() {
 			Expect(fakeProcessor.ProcessCallCount()).Should(Equal(1))
-			Expect(fakeGenerator.GenerateCallCount()).Should(Equal(0))
+			Expect(fakeGenerator.GenerateAndCompareCallCount()).Should(Equal(0))
 			Expect(fakeNginxFimeMgr.WriteHTTPServersConfigCallCount()).Should(Equal(0))
+			Expect(fakeNginxFimeMgr.WriteStreamServersConfigCallCount()).Should(Equal(0))
 			Expect(fakeNginxRuntimeMgr.ReloadCallCount()).Should(Equal(0))
 			Expect(fakeStatusUpdater.UpdateCallCount()).Should(Equal(0))
 		}
@@ -174,6 +538,57 @@ var _ = Describe("EventHandler", func() {
 			})
 		})
 
+		Describe("Process ServiceImport events", func() {
+			createServiceImport := func() *unstructured.Unstructured {
+				si := &unstructured.Unstructured{}
+				si.SetGroupVersionKind(state.ServiceImportGVK)
+				return si
+			}
+
+			It("should process upsert event", func() {
+				si := createServiceImport()
+
+				batch := []interface{}{&events.UpsertEvent{
+					Resource: si,
+				}}
+
+				handler.HandleEventBatch(context.TODO(), batch)
+
+				Expect(fakeServiceImportStore.UpsertCallCount()).Should(Equal(1))
+				Expect(fakeServiceImportStore.UpsertArgsForCall(0)).Should(Equal(si))
+
+				expectNoReconfig()
+			})
+
+			It("should process delete event", func() {
+				nsname := types.NamespacedName{Namespace: "test", Name: "serviceimport"}
+
+				batch := []interface{}{&events.DeleteEvent{
+					NamespacedName: nsname,
+					Type:           createServiceImport(),
+				}}
+
+				handler.HandleEventBatch(context.TODO(), batch)
+
+				Expect(fakeServiceImportStore.DeleteCallCount()).Should(Equal(1))
+				Expect(fakeServiceImportStore.DeleteArgsForCall(0)).Should(Equal(nsname))
+
+				expectNoReconfig()
+			})
+
+			It("should panic for an unstructured resource of another kind", func() {
+				batch := []interface{}{&events.UpsertEvent{
+					Resource: &unstructured.Unstructured{},
+				}}
+
+				handle := func() {
+					handler.HandleEventBatch(context.TODO(), batch)
+				}
+
+				Expect(handle).Should(Panic())
+			})
+		})
+
 		Describe("Process Secret events", func() {
 			It("should process upsert event", func() {
 				secret := &apiv1.Secret{}
@@ -187,6 +602,9 @@ var _ = Describe("EventHandler", func() {
 				Expect(fakeSecretStore.UpsertCallCount()).Should(Equal(1))
 				Expect(fakeSecretStore.UpsertArgsForCall(0)).Should(Equal(secret))
 
+				Expect(fakeProcessor.CaptureUpsertChangeCallCount()).Should(Equal(1))
+				Expect(fakeProcessor.CaptureUpsertChangeArgsForCall(0)).Should(Equal(secret))
+
 				expectNoReconfig()
 			})
 
@@ -203,6 +621,11 @@ var _ = Describe("EventHandler", func() {
 				Expect(fakeSecretStore.DeleteCallCount()).Should(Equal(1))
 				Expect(fakeSecretStore.DeleteArgsForCall(0)).Should(Equal(nsname))
 
+				Expect(fakeProcessor.CaptureDeleteChangeCallCount()).Should(Equal(1))
+				passedObj, passedNsName := fakeProcessor.CaptureDeleteChangeArgsForCall(0)
+				Expect(passedObj).Should(Equal(&apiv1.Secret{}))
+				Expect(passedNsName).Should(Equal(nsname))
+
 				expectNoReconfig()
 			})
 		})
@@ -239,22 +662,25 @@ var _ = Describe("EventHandler", func() {
 		fakeProcessor.ProcessReturns(changed, fakeConf, fakeStatuses)
 
 		fakeCfg := []byte("fake")
-		fakeGenerator.GenerateReturns(fakeCfg, config.Warnings{})
+		fakeGenerator.GenerateAndCompareReturns(fakeCfg, true, config.Warnings{}, nil)
+
+		fakeStreamCfg := []byte("fake-stream")
+		fakeGenerator.GenerateStreamReturns(fakeStreamCfg, config.Warnings{}, nil)
 
 		handler.HandleEventBatch(context.TODO(), batch)
 
 		// Check that the events for Gateway API resources were captured
 
-		// 3, not 5, because the last 2 do not result into CaptureUpsertChange() call
-		Expect(fakeProcessor.CaptureUpsertChangeCallCount()).Should(Equal(3))
-		for i := 0; i < 3; i++ {
-			Expect(fakeProcessor.CaptureUpsertChangeArgsForCall(i)).Should(Equal(upserts[i].(*events.UpsertEvent).Resource))
+		// 4, not 5, because the Service does not result into CaptureUpsertChange() call
+		Expect(fakeProcessor.CaptureUpsertChangeCallCount()).Should(Equal(4))
+		for i, j := range []int{0, 1, 2, 4} {
+			Expect(fakeProcessor.CaptureUpsertChangeArgsForCall(i)).Should(Equal(upserts[j].(*events.UpsertEvent).Resource))
 		}
-		Expect(fakeProcessor.CaptureDeleteChangeCallCount()).Should(Equal(3))
+		Expect(fakeProcessor.CaptureDeleteChangeCallCount()).Should(Equal(4))
 
-		// 3, not 5, because the last 2 do not result into CaptureDeleteChange() call
-		for i := 0; i < 3; i++ {
-			d := deletes[i].(*events.DeleteEvent)
+		// 4, not 5, because the Service does not result into CaptureDeleteChange() call
+		for i, j := range []int{0, 1, 2, 4} {
+			d := deletes[j].(*events.DeleteEvent)
 			passedObj, passedNsName := fakeProcessor.CaptureDeleteChangeArgsForCall(i)
 			Expect(passedObj).Should(Equal(d.Type))
 			Expect(passedNsName).Should(Equal(d.NamespacedName))
@@ -275,7 +701,7 @@ var _ = Describe("EventHandler", func() {
 		Expect(fakeSecretStore.DeleteArgsForCall(0)).Should(Equal(secretNsName))
 
 		// Check that a reconfig happened
-		expectReconfig(fakeConf, fakeCfg, fakeStatuses)
+		expectReconfig(fakeConf, fakeCfg, fakeStreamCfg, fakeStatuses)
 	})
 
 	Describe("Edge cases", func() {
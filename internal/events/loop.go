@@ -3,8 +3,11 @@ package events
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
+
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/metrics"
 )
 
 // EventLoop is the main event loop of the Gateway. It handles events coming through the event channel.
@@ -21,12 +24,56 @@ import (
 // (2) A reload can have side-effects for the data plane traffic.
 // FIXME(pleshakov): better document the side effects and how to prevent and mitigate them.
 // So when the EventLoop have 100 saved events, it is better to process them at once rather than one by one.
+//
+// Additionally, the EventLoop can be configured with a batch window (see WithBatchWindow). In that case, a batch is not
+// handled until the window has passed since its first event, so that bursts of events (for example, during a rolling
+// update of a Deployment) are coalesced into a single batch.
+//
+// The EventLoop can also be configured with a minimum reload interval (see WithMinReloadInterval). In that case,
+// a batch is not handled until the interval has passed since the handling of the previous batch started, so that
+// the changes that come within the interval (for example, from flapping endpoints) are coalesced into a single
+// batch and NGINX is reloaded at most once per interval with the final state.
+//
+// Before a batch is handled, the events superseded by later events for the same resource are removed from it.
 type EventLoop struct {
 	eventCh <-chan interface{}
 	logger  logr.Logger
 	handler EventHandler
 
 	preparer FirstEventBatchPreparer
+
+	batchWindow       time.Duration
+	minReloadInterval time.Duration
+
+	metrics *metrics.ControllerMetrics
+}
+
+// EventLoopOption is a function that modifies the configuration of the EventLoop.
+type EventLoopOption func(*EventLoop)
+
+// WithBatchWindow sets the batch window of the EventLoop. The zero window, which is the default, means a batch is
+// handled as soon as possible.
+func WithBatchWindow(window time.Duration) EventLoopOption {
+	return func(el *EventLoop) {
+		el.batchWindow = window
+	}
+}
+
+// WithMinReloadInterval sets the minimum interval between the starts of the handling of two batches. Because handling
+// a batch can reload NGINX, the interval limits the rate of the reloads. The zero interval, which is the default,
+// means a batch is handled as soon as the previous batch is handled.
+func WithMinReloadInterval(interval time.Duration) EventLoopOption {
+	return func(el *EventLoop) {
+		el.minReloadInterval = interval
+	}
+}
+
+// WithMetrics sets the metrics that the EventLoop records: the number of events waiting to be handled and
+// the lag between the receipt of an event and the end of the handling of its batch.
+func WithMetrics(m *metrics.ControllerMetrics) EventLoopOption {
+	return func(el *EventLoop) {
+		el.metrics = m
+	}
 }
 
 // NewEventLoop creates a new EventLoop.
@@ -35,13 +82,21 @@ func NewEventLoop(
 	logger logr.Logger,
 	handler EventHandler,
 	preparer FirstEventBatchPreparer,
+	options ...EventLoopOption,
 ) *EventLoop {
-	return &EventLoop{
+	el := &EventLoop{
 		eventCh:  eventCh,
 		logger:   logger,
 		handler:  handler,
 		preparer: preparer,
+		metrics:  metrics.NewControllerMetrics(),
+	}
+
+	for _, o := range options {
+		o(el)
 	}
+
+	return el
 }
 
 // Start starts the EventLoop.
@@ -53,13 +108,27 @@ func (el *EventLoop) Start(ctx context.Context) error {
 	var handling bool
 	// handlingDone is used to signal the completion of handling a batch.
 	handlingDone := make(chan struct{})
+	// windowDone signals that the batch window of the current batch has passed. It is nil if there is no window
+	// in progress.
+	var windowDone <-chan time.Time
+	// intervalDone signals that the minimum reload interval has passed since the handling of the previous batch
+	// started. It is nil if there is no interval in progress.
+	var intervalDone <-chan time.Time
+	// handlingStarted is the time the handling of the current or the previous batch started.
+	var handlingStarted time.Time
 
 	handleAndResetBatch := func() {
+		handlingStarted = time.Now()
+
 		go func(batch EventBatch) {
+			batch = coalesceEvents(batch)
+
 			el.logger.Info("Handling events from the batch", "total", len(batch))
 
 			el.handler.HandleEventBatch(ctx, batch)
 
+			el.observeEventLag(batch)
+
 			el.logger.Info("Finished handling the batch")
 			handlingDone <- struct{}{}
 		}(batch)
@@ -68,6 +137,16 @@ func (el *EventLoop) Start(ctx context.Context) error {
 		// Use a double-buffer approach - create two buffers and exchange them between the producer and consumer
 		// routines. NOTE: pass-by-reference, and reset buffer to length 0, but retain capacity.
 		batch = make([]interface{}, 0)
+		el.metrics.EventQueueDepth.Set(0)
+	}
+
+	// handleBatchIfReady handles the current batch if it has at least one event, no batch is being handled,
+	// the batch window has passed and the minimum reload interval has passed.
+	handleBatchIfReady := func() {
+		if len(batch) > 0 && !handling && windowDone == nil && intervalDone == nil {
+			handleAndResetBatch()
+			handling = true
+		}
 	}
 
 	// Prepare the fist event batch, which includes the UpsertEvents for all relevant cluster resources.
@@ -105,6 +184,7 @@ func (el *EventLoop) Start(ctx context.Context) error {
 		case e := <-el.eventCh:
 			// Add the event to the current batch.
 			batch = append(batch, e)
+			el.metrics.EventQueueDepth.Set(float64(len(batch)))
 
 			// FIXME(pleshakov): Log more details about the event like resource GVK and ns/name.
 			el.logger.Info(
@@ -113,19 +193,42 @@ func (el *EventLoop) Start(ctx context.Context) error {
 				"total", len(batch),
 			)
 
-			// Handle the current batch if no batch is being handled.
-			if !handling {
-				handleAndResetBatch()
-				handling = true
+			// Start the batch window with the first event of the batch.
+			if el.batchWindow > 0 && len(batch) == 1 {
+				windowDone = time.After(el.batchWindow)
 			}
+
+			handleBatchIfReady()
+		case <-windowDone:
+			windowDone = nil
+
+			handleBatchIfReady()
+		case <-intervalDone:
+			intervalDone = nil
+
+			handleBatchIfReady()
 		case <-handlingDone:
 			handling = false
 
-			// Handle the current batch if it has at least one event.
-			if len(batch) > 0 {
-				handleAndResetBatch()
-				handling = true
+			// Start the minimum reload interval, unless the handling took longer than the interval.
+			if remaining := el.minReloadInterval - time.Since(handlingStarted); remaining > 0 {
+				intervalDone = time.After(remaining)
 			}
+
+			handleBatchIfReady()
+		}
+	}
+}
+
+// observeEventLag records the lag of the events of the handled batch. The events with unknown receipt time are
+// skipped.
+func (el *EventLoop) observeEventLag(batch EventBatch) {
+	for _, e := range batch {
+		receivedAt := getReceivedAt(e)
+		if receivedAt.IsZero() {
+			continue
 		}
+
+		el.metrics.EventLag.Observe(time.Since(receivedAt).Seconds())
 	}
 }
@@ -3,13 +3,20 @@ package events_test
 import (
 	"context"
 	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/events"
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/events/eventsfakes"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/metrics"
 )
 
 var _ = Describe("EventLoop", func() {
@@ -121,6 +128,256 @@ var _ = Describe("EventLoop", func() {
 		})
 	})
 
+	Describe("Metrics", func() {
+		var controllerMetrics *metrics.ControllerMetrics
+
+		BeforeEach(func() {
+			controllerMetrics = metrics.NewControllerMetrics()
+
+			eventLoop = events.NewEventLoop(eventCh, zap.New(), fakeHandler, fakePreparer, events.WithMetrics(controllerMetrics))
+
+			fakePreparer.PrepareReturns(events.EventBatch{}, nil)
+
+			go func() {
+				errorCh <- eventLoop.Start(ctx)
+			}()
+
+			// Ensure the first batch is handled
+			Eventually(fakeHandler.HandleEventBatchCallCount).Should(Equal(1))
+		})
+
+		AfterEach(func() {
+			cancel()
+
+			var err error
+			Eventually(errorCh).Should(Receive(&err))
+			Expect(err).To(BeNil())
+		})
+
+		getLagSampleCount := func() uint64 {
+			var m dto.Metric
+			Expect(controllerMetrics.EventLag.Write(&m)).To(Succeed())
+			return m.GetHistogram().GetSampleCount()
+		}
+
+		It("should record the lag of a processed event", func() {
+			e := &events.UpsertEvent{
+				Resource:   &v1beta1.HTTPRoute{},
+				ReceivedAt: time.Now().Add(-time.Second),
+			}
+
+			eventCh <- e
+
+			Eventually(getLagSampleCount).Should(BeEquivalentTo(1))
+
+			var m dto.Metric
+			Expect(controllerMetrics.EventLag.Write(&m)).To(Succeed())
+			Expect(m.GetHistogram().GetSampleSum()).To(BeNumerically(">=", 1))
+		})
+
+		It("should not record the lag of an event with unknown receipt time", func() {
+			eventCh <- &events.UpsertEvent{Resource: &v1beta1.HTTPRoute{}}
+
+			Eventually(fakeHandler.HandleEventBatchCallCount).Should(Equal(2))
+			Consistently(getLagSampleCount).Should(BeZero())
+		})
+
+		It("should record the number of events waiting to be handled", func() {
+			firstHandleEventBatchCallInProgress := make(chan struct{})
+			sentSecondAndThirdEvents := make(chan struct{})
+
+			fakeHandler.HandleEventBatchCalls(func(ctx context.Context, batch events.EventBatch) {
+				close(firstHandleEventBatchCallInProgress)
+				<-sentSecondAndThirdEvents
+			})
+
+			eventCh <- "event1"
+
+			<-firstHandleEventBatchCallInProgress
+
+			eventCh <- "event2"
+			eventCh <- "event3"
+
+			Eventually(func() float64 {
+				return testutil.ToFloat64(controllerMetrics.EventQueueDepth)
+			}).Should(BeEquivalentTo(2))
+
+			fakeHandler.HandleEventBatchCalls(nil)
+			close(sentSecondAndThirdEvents)
+
+			Eventually(fakeHandler.HandleEventBatchCallCount).Should(Equal(3))
+			Eventually(func() float64 {
+				return testutil.ToFloat64(controllerMetrics.EventQueueDepth)
+			}).Should(BeZero())
+		})
+	})
+
+	Describe("Processing with a batch window", func() {
+		const batchWindow = 200 * time.Millisecond
+
+		BeforeEach(func() {
+			eventLoop = events.NewEventLoop(eventCh, zap.New(), fakeHandler, fakePreparer, events.WithBatchWindow(batchWindow))
+
+			fakePreparer.PrepareReturns(events.EventBatch{}, nil)
+
+			go func() {
+				errorCh <- eventLoop.Start(ctx)
+			}()
+
+			// Ensure the first batch is handled
+			Eventually(fakeHandler.HandleEventBatchCallCount).Should(Equal(1))
+		})
+
+		AfterEach(func() {
+			cancel()
+
+			var err error
+			Eventually(errorCh).Should(Receive(&err))
+			Expect(err).To(BeNil())
+		})
+
+		It("should batch events that come within the window", func() {
+			e1 := "event1"
+			e2 := "event2"
+
+			eventCh <- e1
+			eventCh <- e2
+
+			Consistently(fakeHandler.HandleEventBatchCallCount, batchWindow/2).Should(Equal(1))
+			Eventually(fakeHandler.HandleEventBatchCallCount).Should(Equal(2))
+
+			_, batch := fakeHandler.HandleEventBatchArgsForCall(1)
+
+			var expectedBatch events.EventBatch = []interface{}{e1, e2}
+			Expect(batch).Should(Equal(expectedBatch))
+		})
+
+		It("should resolve a delete followed by an upsert of the same resource to the upsert", func() {
+			nsname := types.NamespacedName{Namespace: "test", Name: "route"}
+
+			deleteEvent := &events.DeleteEvent{
+				Type:           &v1beta1.HTTPRoute{},
+				NamespacedName: nsname,
+			}
+			upsertEvent := &events.UpsertEvent{
+				Resource: &v1beta1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: nsname.Namespace,
+						Name:      nsname.Name,
+					},
+				},
+			}
+			otherUpsertEvent := &events.UpsertEvent{
+				Resource: &v1beta1.Gateway{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: nsname.Namespace,
+						Name:      nsname.Name,
+					},
+				},
+			}
+
+			eventCh <- deleteEvent
+			eventCh <- otherUpsertEvent
+			eventCh <- upsertEvent
+
+			Eventually(fakeHandler.HandleEventBatchCallCount).Should(Equal(2))
+
+			_, batch := fakeHandler.HandleEventBatchArgsForCall(1)
+
+			var expectedBatch events.EventBatch = []interface{}{otherUpsertEvent, upsertEvent}
+			Expect(batch).Should(Equal(expectedBatch))
+		})
+	})
+
+	Describe("Processing with a minimum reload interval", func() {
+		const minReloadInterval = 300 * time.Millisecond
+
+		BeforeEach(func() {
+			eventLoop = events.NewEventLoop(
+				eventCh,
+				zap.New(),
+				fakeHandler,
+				fakePreparer,
+				events.WithMinReloadInterval(minReloadInterval),
+			)
+
+			fakePreparer.PrepareReturns(events.EventBatch{}, nil)
+
+			go func() {
+				errorCh <- eventLoop.Start(ctx)
+			}()
+
+			// Ensure the first batch is handled
+			Eventually(fakeHandler.HandleEventBatchCallCount).Should(Equal(1))
+		})
+
+		AfterEach(func() {
+			cancel()
+
+			var err error
+			Eventually(errorCh).Should(Receive(&err))
+			Expect(err).To(BeNil())
+		})
+
+		It("should coalesce the events that come within the interval and handle the final state", func() {
+			nsname := types.NamespacedName{Namespace: "test", Name: "service"}
+
+			upsertEvent := &events.UpsertEvent{
+				Resource: &v1beta1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:  nsname.Namespace,
+						Name:       nsname.Name,
+						Generation: 1,
+					},
+				},
+			}
+			finalUpsertEvent := &events.UpsertEvent{
+				Resource: &v1beta1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:  nsname.Namespace,
+						Name:       nsname.Name,
+						Generation: 2,
+					},
+				},
+			}
+			deleteEvent := &events.DeleteEvent{
+				Type:           &v1beta1.HTTPRoute{},
+				NamespacedName: nsname,
+			}
+
+			eventCh <- upsertEvent
+			eventCh <- deleteEvent
+			eventCh <- finalUpsertEvent
+
+			Consistently(fakeHandler.HandleEventBatchCallCount, minReloadInterval/3).Should(Equal(1))
+			Eventually(fakeHandler.HandleEventBatchCallCount).Should(Equal(2))
+
+			_, batch := fakeHandler.HandleEventBatchArgsForCall(1)
+
+			var expectedBatch events.EventBatch = []interface{}{finalUpsertEvent}
+			Expect(batch).Should(Equal(expectedBatch))
+		})
+
+		It("should wait for the interval after every handled batch", func() {
+			e1 := "event1"
+			e2 := "event2"
+
+			eventCh <- e1
+
+			Eventually(fakeHandler.HandleEventBatchCallCount).Should(Equal(2))
+
+			eventCh <- e2
+
+			Consistently(fakeHandler.HandleEventBatchCallCount, minReloadInterval/3).Should(Equal(2))
+			Eventually(fakeHandler.HandleEventBatchCallCount).Should(Equal(3))
+
+			_, batch := fakeHandler.HandleEventBatchArgsForCall(2)
+
+			var expectedBatch events.EventBatch = []interface{}{e2}
+			Expect(batch).Should(Equal(expectedBatch))
+		})
+	})
+
 	Describe("Edge cases", func() {
 		It("should return error when preparer returns error without blocking", func() {
 			preparerError := errors.New("test")
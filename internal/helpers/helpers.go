@@ -27,6 +27,16 @@ func GetInt32Pointer(i int32) *int32 {
 	return &i
 }
 
+// GetIntPointer takes an int and returns a pointer to it. Useful in unit tests when initializing structs.
+func GetIntPointer(i int) *int {
+	return &i
+}
+
+// GetBoolPointer takes a bool and returns a pointer to it. Useful in unit tests when initializing structs.
+func GetBoolPointer(b bool) *bool {
+	return &b
+}
+
 // GetHTTPMethodPointer takes an HTTPMethod and returns a pointer to it. Useful in unit tests when initializing structs.
 func GetHTTPMethodPointer(m v1beta1.HTTPMethod) *v1beta1.HTTPMethod {
 	return &m
@@ -42,6 +52,11 @@ func GetQueryParamMatchTypePointer(t v1beta1.QueryParamMatchType) *v1beta1.Query
 	return &t
 }
 
+// GetPathMatchTypePointer takes a PathMatchType and returns a pointer to it. Useful in unit tests when initializing structs.
+func GetPathMatchTypePointer(t v1beta1.PathMatchType) *v1beta1.PathMatchType {
+	return &t
+}
+
 // GetTLSModePointer takes a TLSModeType and returns a pointer to it. Useful in unit tests when initializing structs.
 func GetTLSModePointer(t v1beta1.TLSModeType) *v1beta1.TLSModeType {
 	return &t
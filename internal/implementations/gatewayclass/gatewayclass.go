@@ -14,6 +14,7 @@ import (
 
 type gatewayClassImplementation struct {
 	logger           logr.Logger
+	gatewayCtlrName  string
 	gatewayClassName string
 	eventCh          chan<- interface{}
 }
@@ -21,6 +22,7 @@ type gatewayClassImplementation struct {
 func NewGatewayClassImplementation(conf config.Config, eventCh chan<- interface{}) sdk.GatewayClassImpl {
 	return &gatewayClassImplementation{
 		logger:           conf.Logger,
+		gatewayCtlrName:  conf.GatewayCtlrName,
 		gatewayClassName: conf.GatewayClassName,
 		eventCh:          eventCh,
 	}
@@ -28,6 +30,20 @@ func NewGatewayClassImplementation(conf config.Config, eventCh chan<- interface{
 
 func (impl *gatewayClassImplementation) Upsert(gc *v1beta1.GatewayClass) {
 	if gc.Name != impl.gatewayClassName {
+		// Multiple GatewayClasses can reference this controller, but the controller only reconciles the configured one,
+		// so that the choice of the GatewayClass is deterministic. The other ones are reported as conflicting.
+		if string(gc.Spec.ControllerName) == impl.gatewayCtlrName {
+			impl.eventCh <- &events.UpsertEvent{
+				Resource: gc,
+			}
+
+			msg := fmt.Sprintf("GatewayClass references this controller but was ignored because this controller only supports the GatewayClass %s", impl.gatewayClassName)
+			impl.logger.Info(msg,
+				"name", gc.Name,
+			)
+			return
+		}
+
 		msg := fmt.Sprintf("GatewayClass was upserted but ignored because this controller only supports the GatewayClass %s", impl.gatewayClassName)
 		impl.logger.Info(msg,
 			"name", gc.Name,
@@ -47,16 +63,17 @@ func (impl *gatewayClassImplementation) Remove(nsname types.NamespacedName) {
 	// GatewayClass is a cluster scoped resource - no namespace.
 
 	if nsname.Name != impl.gatewayClassName {
+		// The removed GatewayClass might have referenced this controller, so it might have been reported as
+		// conflicting. The ChangeProcessor ignores the removal of the GatewayClasses it doesn't know about.
 		msg := fmt.Sprintf("GatewayClass was removed but ignored because this controller only supports the GatewayClass %s", impl.gatewayClassName)
 		impl.logger.Info(msg,
 			"name", nsname.Name,
 		)
-		return
+	} else {
+		impl.logger.Info("GatewayClass was removed",
+			"name", nsname.Name)
 	}
 
-	impl.logger.Info("GatewayClass was removed",
-		"name", nsname.Name)
-
 	impl.eventCh <- &events.DeleteEvent{
 		NamespacedName: nsname,
 		Type:           &v1beta1.GatewayClass{},
@@ -21,6 +21,7 @@ var _ = Describe("GatewayClassImplementation", func() {
 	)
 
 	const (
+		ctlrName           = "my.controller/nginx-gateway"
 		className          = "my-class"
 		unrelatedClassName = "not-my-class"
 	)
@@ -30,6 +31,7 @@ var _ = Describe("GatewayClassImplementation", func() {
 
 		impl = implementation.NewGatewayClassImplementation(config.Config{
 			Logger:           zap.New(),
+			GatewayCtlrName:  ctlrName,
 			GatewayClassName: className,
 		}, eventCh)
 	})
@@ -77,12 +79,38 @@ var _ = Describe("GatewayClassImplementation", func() {
 			Expect(eventCh).ShouldNot(Receive())
 		})
 
-		It("should ignore remove", func() {
+		It("should pass remove to the ChangeProcessor", func() {
 			nsname := types.NamespacedName{Name: unrelatedClassName}
 
-			impl.Remove(nsname)
+			go func() {
+				impl.Remove(nsname)
+			}()
 
-			Expect(eventCh).ShouldNot(Receive())
+			// the ChangeProcessor ignores the removal of an unrelated GatewayClass
+			Eventually(eventCh).Should(Receive(Equal(
+				&events.DeleteEvent{
+					NamespacedName: nsname,
+					Type:           &v1beta1.GatewayClass{},
+				})))
+		})
+	})
+
+	Describe("Implementation processes conflicting GatewayClass", func() {
+		It("should process upsert of a GatewayClass that references the controller", func() {
+			gc := &v1beta1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: unrelatedClassName,
+				},
+				Spec: v1beta1.GatewayClassSpec{
+					ControllerName: ctlrName,
+				},
+			}
+
+			go func() {
+				impl.Upsert(gc)
+			}()
+
+			Eventually(eventCh).Should(Receive(Equal(&events.UpsertEvent{Resource: gc})))
 		})
 	})
 })
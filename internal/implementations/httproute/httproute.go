@@ -1,6 +1,8 @@
 package implementation
 
 import (
+	"time"
+
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
@@ -37,7 +39,8 @@ func (impl *httpRouteImplementation) Upsert(hr *v1beta1.HTTPRoute) {
 	)
 
 	impl.eventCh <- &events.UpsertEvent{
-		Resource: hr,
+		Resource:   hr,
+		ReceivedAt: time.Now(),
 	}
 }
 
@@ -49,5 +52,6 @@ func (impl *httpRouteImplementation) Remove(nsname types.NamespacedName) {
 	impl.eventCh <- &events.DeleteEvent{
 		NamespacedName: nsname,
 		Type:           &v1beta1.HTTPRoute{},
+		ReceivedAt:     time.Now(),
 	}
 }
@@ -0,0 +1,53 @@
+package implementation
+
+import (
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/config"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/events"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
+	"github.com/nginxinc/nginx-kubernetes-gateway/pkg/sdk"
+)
+
+type serviceImportImplementation struct {
+	conf    config.Config
+	eventCh chan<- interface{}
+}
+
+// NewServiceImportImplementation creates a new ServiceImportImplementation.
+func NewServiceImportImplementation(cfg config.Config, eventCh chan<- interface{}) sdk.ServiceImportImpl {
+	return &serviceImportImplementation{
+		conf:    cfg,
+		eventCh: eventCh,
+	}
+}
+
+func (impl *serviceImportImplementation) Logger() logr.Logger {
+	return impl.conf.Logger
+}
+
+func (impl *serviceImportImplementation) Upsert(si *unstructured.Unstructured) {
+	impl.Logger().Info("ServiceImport was upserted",
+		"namespace", si.GetNamespace(), "name", si.GetName(),
+	)
+
+	impl.eventCh <- &events.UpsertEvent{
+		Resource: si,
+	}
+}
+
+func (impl *serviceImportImplementation) Remove(nsname types.NamespacedName) {
+	impl.Logger().Info("ServiceImport resource was removed",
+		"namespace", nsname.Namespace, "name", nsname.Name,
+	)
+
+	si := &unstructured.Unstructured{}
+	si.SetGroupVersionKind(state.ServiceImportGVK)
+
+	impl.eventCh <- &events.DeleteEvent{
+		NamespacedName: nsname,
+		Type:           si,
+	}
+}
@@ -1,15 +1,23 @@
 package manager
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"os"
+	"strings"
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctlr "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctlrmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/config"
@@ -19,6 +27,9 @@ import (
 	hr "github.com/nginxinc/nginx-kubernetes-gateway/internal/implementations/httproute"
 	secret "github.com/nginxinc/nginx-kubernetes-gateway/internal/implementations/secret"
 	svc "github.com/nginxinc/nginx-kubernetes-gateway/internal/implementations/service"
+	si "github.com/nginxinc/nginx-kubernetes-gateway/internal/implementations/serviceimport"
+	tr "github.com/nginxinc/nginx-kubernetes-gateway/internal/implementations/tcproute"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/metrics"
 	ngxcfg "github.com/nginxinc/nginx-kubernetes-gateway/internal/nginx/config"
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/nginx/file"
 	ngxruntime "github.com/nginxinc/nginx-kubernetes-gateway/internal/nginx/runtime"
@@ -33,6 +44,8 @@ const (
 	// secretsFolder is the folder that holds all the secrets for NGINX servers.
 	// nolint:gosec
 	secretsFolder = "/etc/nginx/secrets"
+	// resolvConfPath is the path of the file with the nameservers of the pod.
+	resolvConfPath = "/etc/resolv.conf"
 )
 
 var scheme = runtime.NewScheme()
@@ -40,6 +53,7 @@ var scheme = runtime.NewScheme()
 func init() {
 	// FIXME(pleshakov): handle errors returned by the calls bellow
 	_ = gatewayv1beta1.AddToScheme(scheme)
+	_ = gatewayv1alpha2.AddToScheme(scheme)
 	_ = apiv1.AddToScheme(scheme)
 }
 
@@ -47,7 +61,9 @@ func Start(cfg config.Config) error {
 	logger := cfg.Logger
 
 	options := manager.Options{
-		Scheme: scheme,
+		Scheme:                 scheme,
+		HealthProbeBindAddress: cfg.HealthProbeBindAddress,
+		MetricsBindAddress:     cfg.MetricsBindAddress,
 	}
 
 	eventCh := make(chan interface{})
@@ -60,6 +76,11 @@ func Start(cfg config.Config) error {
 		return fmt.Errorf("cannot build runtime manager: %w", err)
 	}
 
+	err = mgr.AddHealthzCheck("healthz", healthz.Ping)
+	if err != nil {
+		return fmt.Errorf("cannot add health check: %w", err)
+	}
+
 	err = sdk.RegisterGatewayClassController(mgr, gc.NewGatewayClassImplementation(cfg, eventCh))
 	if err != nil {
 		return fmt.Errorf("cannot register gatewayclass implementation: %w", err)
@@ -72,6 +93,10 @@ func Start(cfg config.Config) error {
 	if err != nil {
 		return fmt.Errorf("cannot register httproute implementation: %w", err)
 	}
+	err = sdk.RegisterTCPRouteController(mgr, tr.NewTCPRouteImplementation(cfg, eventCh))
+	if err != nil {
+		return fmt.Errorf("cannot register tcproute implementation: %w", err)
+	}
 	err = sdk.RegisterServiceController(mgr, svc.NewServiceImplementation(cfg, eventCh))
 	if err != nil {
 		return fmt.Errorf("cannot register service implementation: %w", err)
@@ -80,24 +105,162 @@ func Start(cfg config.Config) error {
 	if err != nil {
 		return fmt.Errorf("cannot register secret implementation: %w", err)
 	}
+	if cfg.ServiceImportBackends {
+		err = sdk.RegisterServiceImportController(
+			mgr,
+			state.ServiceImportGVK,
+			si.NewServiceImportImplementation(cfg, eventCh),
+		)
+		if err != nil {
+			return fmt.Errorf("cannot register serviceimport implementation: %w", err)
+		}
+	}
+
+	// the metrics are exported by the Manager on cfg.MetricsBindAddress
+	controllerMetrics := metrics.NewControllerMetrics()
+	err = controllerMetrics.Register(ctlrmetrics.Registry)
+	if err != nil {
+		return fmt.Errorf("cannot register metrics: %w", err)
+	}
 
 	secretStore := state.NewSecretStore()
 	secretMemoryMgr := state.NewSecretDiskMemoryManager(secretsFolder, secretStore)
 
+	serviceStore, err := state.NewServiceStoreByName(cfg.ServiceStore, state.ServiceStoreConfig{
+		StaticAddresses: cfg.StaticServiceAddresses,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create the service store: %w", err)
+	}
+
+	// the resolvers of the backend kinds other than Service are registered here, both for the validation of
+	// the backendRefs by the processor and for the resolution of the backends by the generator
+	backendResolvers := make(map[state.BackendKind]state.BackendResolver)
+
+	var serviceImportStore state.ServiceImportStore
+	if cfg.ServiceImportBackends {
+		serviceImportStore = state.NewServiceImportStore()
+		backendResolvers[state.ServiceImportBackendKind] = serviceImportStore
+	}
+
+	allowedAddresses := cfg.GatewayAddresses
+	if len(allowedAddresses) == 0 {
+		allowedAddresses, err = getInterfaceAddresses()
+		if err != nil {
+			return fmt.Errorf("cannot get the addresses of the network interfaces: %w", err)
+		}
+	}
+
 	processor := state.NewChangeProcessorImpl(state.ChangeProcessorConfig{
 		GatewayCtlrName:     cfg.GatewayCtlrName,
 		GatewayClassName:    cfg.GatewayClassName,
 		SecretMemoryManager: secretMemoryMgr,
+		ServiceStore:        serviceStore,
+		BackendResolvers:    backendResolvers,
+		ResolveTimeout:      cfg.ResolveTimeout,
+		AllowedAddresses:    ngxcfg.FilterAddressesByIPFamily(allowedAddresses, cfg.IPFamily),
 	})
 
-	serviceStore := state.NewServiceStore()
-	configGenerator := ngxcfg.NewGeneratorImpl(serviceStore)
+	njsAvailable, err := ngxruntime.IsNJSAvailable()
+	if err != nil {
+		return fmt.Errorf("cannot check if the njs module is available: %w", err)
+	}
+	if !njsAvailable {
+		logger.Info("The njs module is not loaded by NGINX. HTTPRoute matches that require the module will be ignored")
+	}
+
+	if (cfg.DefaultCertificate == "") != (cfg.DefaultCertificateKey == "") {
+		return errors.New("the default certificate and its key must be configured together")
+	}
+	if cfg.DefaultCertificate == "" {
+		logger.Info("No default certificate is configured. NGINX rejects the TLS handshakes for the hostnames " +
+			"without an HTTPS listener")
+	}
+
+	resolver := cfg.Resolver
+	if len(resolver) == 0 {
+		// NGINX runs in the same pod, so it shares the nameservers
+		resolver, err = getNameservers(resolvConfPath)
+		if err != nil {
+			logger.Error(err, "Cannot get the nameservers of the pod. The ExternalName Services will not be resolved")
+		}
+	}
+
+	generatorOptions := []ngxcfg.GeneratorOption{
+		ngxcfg.WithRequestIDHeader(cfg.RequestIDHeader),
+		ngxcfg.WithMetrics(controllerMetrics),
+		ngxcfg.WithNJSAvailable(njsAvailable),
+		ngxcfg.WithForwardedHeaders(cfg.ForwardedHeaders),
+		ngxcfg.WithTrustedProxies(cfg.TrustedProxies),
+		ngxcfg.WithBackendCACertificate(cfg.BackendCACertificate),
+		ngxcfg.WithOCSPStapling(cfg.OCSPStaplingTrustedCertificate),
+		ngxcfg.WithProxyTimeouts(cfg.ProxyConnectTimeout, cfg.ProxyReadTimeout, cfg.ProxySendTimeout),
+		ngxcfg.WithSecurityHeaders(cfg.SecurityHeaders),
+		ngxcfg.WithServerTokens(cfg.ServerTokens),
+		ngxcfg.WithSourceComments(cfg.SourceComments),
+		ngxcfg.WithSnippets(cfg.AllowSnippets),
+		ngxcfg.WithSSLCertificateMap(cfg.SSLCertificateMap),
+		ngxcfg.WithListenPorts(int32(cfg.HTTPPort), int32(cfg.HTTPSPort)),
+		ngxcfg.WithIPFamily(cfg.IPFamily),
+		ngxcfg.WithDefaultType(cfg.DefaultType),
+		ngxcfg.WithBackendResolvers(backendResolvers),
+		ngxcfg.WithResolveTimeout(cfg.ResolveTimeout),
+		ngxcfg.WithWorkerProcesses(cfg.WorkerProcesses),
+		ngxcfg.WithWorkerConnections(cfg.WorkerConnections),
+		ngxcfg.WithUpstreamKeepalive(cfg.UpstreamKeepalive),
+		ngxcfg.WithUpstreamZoneSize(cfg.UpstreamZoneSize),
+		ngxcfg.WithResolver(resolver),
+		ngxcfg.WithPathNormalization(cfg.NormalizePaths),
+		ngxcfg.WithDefaultCertificate(cfg.DefaultCertificate, cfg.DefaultCertificateKey),
+	}
+	if cfg.DefaultBackend != "" {
+		backend, err := state.ParseServicePort(cfg.DefaultBackend)
+		if err != nil {
+			return fmt.Errorf("invalid default backend: %w", err)
+		}
+		generatorOptions = append(generatorOptions, ngxcfg.WithDefaultBackend(backend.NsName, backend.Port))
+	}
+	if cfg.NoBackendsResponseCode != ngxcfg.DefaultNoBackendsResponseCode || cfg.NoBackendsResponseBody != "" {
+		generatorOptions = append(generatorOptions,
+			ngxcfg.WithNoBackendsResponse(cfg.NoBackendsResponseCode, cfg.NoBackendsResponseBody))
+	}
+	if len(cfg.StatusOverrides) > 0 {
+		generatorOptions = append(generatorOptions, ngxcfg.WithStatusOverrides(cfg.StatusOverrides))
+	}
+	if cfg.ClientMaxBodySize != "" {
+		generatorOptions = append(generatorOptions, ngxcfg.WithClientMaxBodySize(cfg.ClientMaxBodySize))
+	}
+	if cfg.RequestTooLargeBody != "" {
+		generatorOptions = append(generatorOptions,
+			ngxcfg.WithRequestTooLargeResponse(cfg.RequestTooLargeContentType, cfg.RequestTooLargeBody))
+	}
+	if cfg.HSTS {
+		generatorOptions = append(generatorOptions,
+			ngxcfg.WithHSTS(cfg.HSTSMaxAge, cfg.HSTSIncludeSubDomains, cfg.HSTSPreload))
+	}
+	if cfg.NginxHealthPort != 0 {
+		generatorOptions = append(generatorOptions,
+			ngxcfg.WithHealthCheck(int32(cfg.NginxHealthPort), cfg.NginxHealthPath, cfg.NginxHealthAccessLog))
+	}
+	if cfg.TemplateOverrides != "" {
+		overrides, err := os.ReadFile(cfg.TemplateOverrides)
+		if err != nil {
+			return fmt.Errorf("cannot read template overrides: %w", err)
+		}
+		if err := ngxcfg.ValidateTemplateOverrides(string(overrides)); err != nil {
+			return fmt.Errorf("invalid template overrides %s: %w", cfg.TemplateOverrides, err)
+		}
+		generatorOptions = append(generatorOptions, ngxcfg.WithTemplateOverrides(string(overrides)))
+	}
+
+	configGenerator := ngxcfg.NewGeneratorImpl(serviceStore, generatorOptions...)
 	nginxFileMgr := file.NewManagerImpl()
 	nginxRuntimeMgr := ngxruntime.NewManagerImpl()
 	statusUpdater := status.NewUpdater(status.UpdaterConfig{
 		GatewayCtlrName:  cfg.GatewayCtlrName,
 		GatewayClassName: cfg.GatewayClassName,
 		Client:           mgr.GetClient(),
+		Metrics:          controllerMetrics,
 		// FIXME(pleshakov) Make sure each component:
 		// (1) Has a dedicated named logger.
 		// (2) Get it from the Manager (the WithName is done here for all components).
@@ -108,6 +271,7 @@ func Start(cfg config.Config) error {
 	eventHandler := events.NewEventHandlerImpl(events.EventHandlerConfig{
 		Processor:           processor,
 		ServiceStore:        serviceStore,
+		ServiceImportStore:  serviceImportStore,
 		SecretStore:         secretStore,
 		SecretMemoryManager: secretMemoryMgr,
 		Generator:           configGenerator,
@@ -117,24 +281,35 @@ func Start(cfg config.Config) error {
 		StatusUpdater:       statusUpdater,
 	})
 
+	objectLists := []client.ObjectList{
+		&apiv1.ServiceList{},
+		&apiv1.SecretList{},
+		&gatewayv1beta1.GatewayList{},
+		&gatewayv1beta1.HTTPRouteList{},
+		&gatewayv1alpha2.TCPRouteList{},
+	}
+	if cfg.ServiceImportBackends {
+		serviceImportList := &unstructured.UnstructuredList{}
+		serviceImportList.SetGroupVersionKind(state.ServiceImportGVK.GroupVersion().WithKind("ServiceImportList"))
+		objectLists = append(objectLists, serviceImportList)
+	}
+
 	firstBatchPreparer := events.NewFirstEventBatchPreparerImpl(
 		mgr.GetCache(),
 		[]client.Object{
 			&gatewayv1beta1.GatewayClass{ObjectMeta: metav1.ObjectMeta{Name: cfg.GatewayClassName}},
 		},
-		[]client.ObjectList{
-			&apiv1.ServiceList{},
-			&apiv1.SecretList{},
-			&gatewayv1beta1.GatewayList{},
-			&gatewayv1beta1.HTTPRouteList{},
-		},
+		objectLists,
 	)
 
 	eventLoop := events.NewEventLoop(
 		eventCh,
 		cfg.Logger.WithName("eventLoop"),
 		eventHandler,
-		firstBatchPreparer)
+		firstBatchPreparer,
+		events.WithBatchWindow(cfg.EventBatchWindow),
+		events.WithMinReloadInterval(cfg.MinReloadInterval),
+		events.WithMetrics(controllerMetrics))
 
 	err = mgr.Add(eventLoop)
 	if err != nil {
@@ -146,3 +321,38 @@ func Start(cfg config.Config) error {
 	logger.Info("Starting manager")
 	return mgr.Start(ctx)
 }
+
+// getNameservers returns the addresses of the nameservers of the resolv.conf file.
+func getNameservers(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var nameservers []string
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			nameservers = append(nameservers, fields[1])
+		}
+	}
+
+	return nameservers, nil
+}
+
+// getInterfaceAddresses returns the IP addresses of the network interfaces of the pod, which NGINX can listen on.
+func getInterfaceAddresses() ([]string, error) {
+	interfaceAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(interfaceAddrs))
+	for _, a := range interfaceAddrs {
+		if ipNet, ok := a.(*net.IPNet); ok {
+			addresses = append(addresses, ipNet.IP.String())
+		}
+	}
+
+	return addresses, nil
+}
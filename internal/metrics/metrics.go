@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// namespace is the namespace of all metrics of the Gateway.
+	namespace = "nginx_kubernetes_gateway"
+
+	// StatusUpdateSuccess is the value of the result label of the StatusUpdates metric for successful updates.
+	StatusUpdateSuccess = "success"
+	// StatusUpdateFailure is the value of the result label of the StatusUpdates metric for failed updates.
+	StatusUpdateFailure = "failure"
+)
+
+// ControllerMetrics holds the metrics of the control plane of the Gateway.
+type ControllerMetrics struct {
+	// GeneratedServers is the number of server blocks in the last generated NGINX configuration.
+	GeneratedServers prometheus.Gauge
+	// GenerationDuration is the duration of the generation of the NGINX configuration.
+	GenerationDuration prometheus.Histogram
+	// StatusUpdates counts the status updates of the resources by their result.
+	StatusUpdates *prometheus.CounterVec
+	// ResolutionFailures counts the failed resolutions of the Services referenced by the routes.
+	ResolutionFailures prometheus.Counter
+	// EventQueueDepth is the number of events waiting to be handled by the event loop.
+	EventQueueDepth prometheus.Gauge
+	// EventLag is the time between the receipt of an event and the end of the handling of its batch, which
+	// includes applying the NGINX configuration.
+	EventLag prometheus.Histogram
+}
+
+// NewControllerMetrics creates new ControllerMetrics.
+// The metrics are not exported until they are registered using Register.
+func NewControllerMetrics() *ControllerMetrics {
+	return &ControllerMetrics{
+		GeneratedServers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "generated_servers",
+			Help:      "Number of server blocks in the last generated NGINX configuration",
+		}),
+		GenerationDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "config_generation_duration_seconds",
+			Help:      "Duration of the generation of the NGINX configuration",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 8),
+		}),
+		StatusUpdates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "status_updates_total",
+			Help:      "Number of status updates of the resources by result",
+		}, []string{"result"}),
+		ResolutionFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "service_resolution_failures_total",
+			Help:      "Number of failed resolutions of the Services referenced by the routes",
+		}),
+		EventQueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "event_queue_depth",
+			Help:      "Number of events waiting to be handled by the event loop",
+		}),
+		EventLag: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "event_lag_seconds",
+			Help:      "Time between the receipt of an event and the application of the resulting configuration",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+		}),
+	}
+}
+
+// Register registers the metrics in the registerer.
+func (m *ControllerMetrics) Register(registerer prometheus.Registerer) error {
+	collectors := []prometheus.Collector{
+		m.GeneratedServers,
+		m.GenerationDuration,
+		m.StatusUpdates,
+		m.ResolutionFailures,
+		m.EventQueueDepth,
+		m.EventLag,
+	}
+
+	for _, c := range collectors {
+		if err := registerer.Register(c); err != nil {
+			return fmt.Errorf("failed to register metric: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRegister(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	m := NewControllerMetrics()
+
+	err := m.Register(registry)
+	if err != nil {
+		t.Fatalf("Register() returned unexpected error %v", err)
+	}
+
+	m.StatusUpdates.WithLabelValues(StatusUpdateSuccess).Inc()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() returned unexpected error %v", err)
+	}
+
+	// StatusUpdates is only exported once it has a labeled counter.
+	if len(families) != 6 {
+		t.Errorf("Register() registered %d metrics but expected 6", len(families))
+	}
+
+	err = NewControllerMetrics().Register(registry)
+	if err == nil {
+		t.Error("Register() didn't return an error when registering the same metrics twice")
+	}
+}
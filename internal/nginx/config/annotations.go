@@ -0,0 +1,745 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// The annotations below configure NGINX-specific options for all routing rules of an HTTPRoute.
+const (
+	// streamingAnnotation enables chunked transfer encoding and disables buffering of responses from the backends,
+	// so that the responses are streamed to clients as soon as they are received. The value is a boolean.
+	streamingAnnotation = "gateway.nginx.org/streaming"
+	// forceRangesAnnotation enables byte-range support for responses from the backends regardless of their
+	// Accept-Ranges header. The value is a boolean.
+	forceRangesAnnotation = "gateway.nginx.org/proxy-force-ranges"
+	// maxRangesAnnotation limits the maximum allowed number of ranges in byte-range requests. 0 disables
+	// byte-range support. The value is a non-negative integer.
+	maxRangesAnnotation = "gateway.nginx.org/max-ranges"
+	// proxyHTTPVersionAnnotation sets the version of HTTP used to proxy requests to the backends. The value is 1.0 or
+	// 1.1. HTTP/1.0 is meant for legacy backends: it disables keepalive connections to the backends and
+	// doesn't support WebSocket upgrades.
+	proxyHTTPVersionAnnotation = "gateway.nginx.org/proxy-http-version"
+	// backendProtocolAnnotation sets the protocol used to proxy requests to the backends. The value is http or https.
+	backendProtocolAnnotation = "gateway.nginx.org/backend-protocol"
+	// backendSSLVerifyAnnotation enables the verification of the certificates of the HTTPS backends with the CA
+	// certificate configured for the Gateway. The value is a boolean. It only applies to the HTTPS backends.
+	backendSSLVerifyAnnotation = "gateway.nginx.org/backend-ssl-verify"
+	// backendSSLNameAnnotation sets the name that is sent to the HTTPS backends in the SNI and that their
+	// certificates are verified against. It only applies to the HTTPS backends.
+	backendSSLNameAnnotation = "gateway.nginx.org/backend-ssl-name"
+	// caseInsensitiveHeadersAnnotation makes the comparison of the header values of the matches case-insensitive.
+	// By default, the header values are compared in a case-sensitive manner. The value is a boolean.
+	caseInsensitiveHeadersAnnotation = "gateway.nginx.org/case-insensitive-header-values"
+	// webSocketAnnotation enables WebSocket connections to the backends by passing the Upgrade and Connection
+	// headers of the requests to the backends. The value is a boolean. WebSocket requires HTTP/1.1 for proxying.
+	webSocketAnnotation = "gateway.nginx.org/websocket"
+	// proxyCacheBypassAnnotation sets the conditions under which the responses are not taken from the cache.
+	// The value is a space-separated list of NGINX variables, for example, "$cookie_session $http_authorization".
+	// The cache is bypassed if any of the variables is not empty and not "0". Only the variables of the request
+	// headers, cookies and arguments and the variables of supportedCacheVariables are supported.
+	// The Gateway doesn't configure proxy_cache, so the annotation only takes effect if a cache is enabled
+	// for the locations, for example, with the template overrides. Otherwise, NGINX ignores the conditions.
+	proxyCacheBypassAnnotation = "gateway.nginx.org/proxy-cache-bypass"
+	// proxyNoCacheAnnotation sets the conditions under which the responses are not saved to the cache.
+	// The value has the same format as the value of proxyCacheBypassAnnotation.
+	proxyNoCacheAnnotation = "gateway.nginx.org/proxy-no-cache"
+	// proxyConnectTimeoutAnnotation sets the timeout for establishing a connection with the backends.
+	// The value is a positive duration, for example, 5s. It overrides the default timeout of the Gateway.
+	proxyConnectTimeoutAnnotation = "gateway.nginx.org/proxy-connect-timeout"
+	// proxyReadTimeoutAnnotation sets the timeout between two successive reads of a response from the backends.
+	// The value is a positive duration, for example, 60s. It overrides the default timeout of the Gateway.
+	proxyReadTimeoutAnnotation = "gateway.nginx.org/proxy-read-timeout"
+	// proxySendTimeoutAnnotation sets the timeout between two successive writes of a request to the backends.
+	// The value is a positive duration, for example, 60s. It overrides the default timeout of the Gateway.
+	proxySendTimeoutAnnotation = "gateway.nginx.org/proxy-send-timeout"
+	// proxyBufferingAnnotation enables or disables the buffering of the responses from the backends. The value is
+	// a boolean. Without the annotation, the NGINX default (enabled) is used.
+	proxyBufferingAnnotation = "gateway.nginx.org/proxy-buffering"
+	// proxyBuffersAnnotation sets the number and size of the buffers of a response from the backends, for example,
+	// "16 8k".
+	proxyBuffersAnnotation = "gateway.nginx.org/proxy-buffers"
+	// proxyBufferSizeAnnotation sets the size of the buffer of the first part of a response from the backends,
+	// which includes the response header, for example, 16k.
+	proxyBufferSizeAnnotation = "gateway.nginx.org/proxy-buffer-size"
+	// proxyNextUpstreamAnnotation sets the conditions under which a request is passed to the next backend of
+	// the rule, for example, "error timeout http_502". The value is a space-separated list of the conditions of
+	// the NGINX proxy_next_upstream directive or off. Without the annotation, the NGINX default (error timeout) is
+	// used.
+	proxyNextUpstreamAnnotation = "gateway.nginx.org/proxy-next-upstream"
+	// proxyNextUpstreamTriesAnnotation limits the number of the tries to pass a request to the next backend.
+	// The value is a positive integer. Without the annotation, the number of the tries is not limited.
+	proxyNextUpstreamTriesAnnotation = "gateway.nginx.org/proxy-next-upstream-tries"
+	// proxyNextUpstreamTimeoutAnnotation limits the time during which a request can be passed to the next backend.
+	// The value is a positive duration, for example, 10s. Without the annotation, the time is not limited.
+	proxyNextUpstreamTimeoutAnnotation = "gateway.nginx.org/proxy-next-upstream-timeout"
+	// locationSnippetsAnnotation adds NGINX directives to the end of the locations of the HTTPRoute, for example,
+	// "proxy_set_header X-Env prod;". The value can span multiple lines. The snippets must be allowed for the Gateway.
+	locationSnippetsAnnotation = "gateway.nginx.org/location-snippets"
+	// serverSnippetsAnnotation adds NGINX directives to the servers of the hostnames of the HTTPRoute, before
+	// the locations. The snippets of multiple HTTPRoutes of a server are ordered by the namespaces and names of
+	// the HTTPRoutes. The snippets must be allowed for the Gateway.
+	serverSnippetsAnnotation = "gateway.nginx.org/server-snippets"
+	// corsAllowOriginAnnotation enables CORS for the HTTPRoute: NGINX responds to the preflight requests itself and
+	// adds the CORS headers to the responses from the backends. The value is the origin that is allowed to access
+	// the resources, for example, https://example.com, or * to allow any origin.
+	corsAllowOriginAnnotation = "gateway.nginx.org/cors-allow-origin"
+	// corsAllowMethodsAnnotation sets the methods that are allowed in the cross-origin requests. The value is
+	// a comma-separated list of methods, for example, "GET, POST". It only applies if CORS is enabled.
+	corsAllowMethodsAnnotation = "gateway.nginx.org/cors-allow-methods"
+	// corsAllowHeadersAnnotation sets the request headers that are allowed in the cross-origin requests. The value is
+	// a comma-separated list of headers, for example, "Authorization, Content-Type". It only applies if CORS is
+	// enabled.
+	corsAllowHeadersAnnotation = "gateway.nginx.org/cors-allow-headers"
+	// corsAllowCredentialsAnnotation allows the cross-origin requests to include credentials, like cookies.
+	// The value is a boolean. It only applies if CORS is enabled for a specific origin.
+	corsAllowCredentialsAnnotation = "gateway.nginx.org/cors-allow-credentials"
+	// corsMaxAgeAnnotation sets how long the clients can cache the results of a preflight request. The value is
+	// a non-negative number of seconds. It only applies if CORS is enabled.
+	corsMaxAgeAnnotation = "gateway.nginx.org/cors-max-age"
+)
+
+// The defaults of the CORS headers, which are used unless the annotations set them.
+const (
+	defaultCORSAllowMethods = "GET, PUT, POST, DELETE, PATCH, OPTIONS"
+	defaultCORSAllowHeaders = "Authorization, Content-Type"
+	defaultCORSMaxAge       = 86400
+)
+
+const (
+	httpVersion10 = "1.0"
+	httpVersion11 = "1.1"
+)
+
+const (
+	backendProtocolHTTP  = "http"
+	backendProtocolHTTPS = "https"
+)
+
+// routeOptions holds the NGINX-specific options of an HTTPRoute.
+type routeOptions struct {
+	// Streaming enables chunked transfer encoding and disables response buffering.
+	Streaming bool
+	// ForceRanges enables byte-range support regardless of the Accept-Ranges header of the backend responses.
+	ForceRanges bool
+	// MaxRanges is the maximum allowed number of ranges in byte-range requests. nil means no limit.
+	MaxRanges *int
+	// ProxyHTTPVersion is the version of HTTP used to proxy requests to the backends. Empty means the NGINX default.
+	ProxyHTTPVersion string
+	// BackendTLS enables HTTPS for proxying requests to the backends.
+	BackendTLS bool
+	// BackendSSLVerify enables the verification of the certificates of the HTTPS backends.
+	BackendSSLVerify bool
+	// BackendSSLName is the name used in the SNI and the verification of the certificates of the HTTPS backends.
+	// Empty means the address of the backend.
+	BackendSSLName string
+	// CaseInsensitiveHeaders makes the comparison of the header values of the matches case-insensitive.
+	CaseInsensitiveHeaders bool
+	// WebSocket enables WebSocket connections to the backends.
+	WebSocket bool
+	// CacheBypass are the NGINX variables that make NGINX bypass the cache.
+	CacheBypass []string
+	// NoCache are the NGINX variables that make NGINX not save the responses to the cache.
+	NoCache []string
+	// Timeouts are the proxy timeouts of the HTTPRoute. They override the default timeouts of the Gateway.
+	Timeouts proxyTimeouts
+	// ProxyBuffering enables or disables the buffering of the responses. nil means the NGINX default.
+	ProxyBuffering *bool
+	// ProxyBuffers and ProxyBufferSize are the sizes of the response buffers in the NGINX format.
+	// Empty means the NGINX default.
+	ProxyBuffers    string
+	ProxyBufferSize string
+	// CORS holds the CORS headers of the HTTPRoute. nil means CORS is not enabled.
+	CORS *cors
+	// LocationSnippets and ServerSnippets are the lines of the NGINX directives of the snippets of the HTTPRoute.
+	// Unlike the other options, they are set by getSnippets, because they depend on whether the snippets are allowed.
+	LocationSnippets []string
+	ServerSnippets   []string
+	// NextUpstream are the conditions under which a request is passed to the next backend.
+	// Empty means the NGINX default.
+	NextUpstream []string
+	// NextUpstreamTries limits the number of the tries to pass a request to the next backend. Zero means no limit.
+	NextUpstreamTries int
+	// NextUpstreamTimeout limits the time during which a request can be passed to the next backend.
+	// Zero means no limit.
+	NextUpstreamTimeout time.Duration
+}
+
+// proxyTimeouts holds the timeouts of proxying requests to the backends. Zero means the timeout is not set.
+type proxyTimeouts struct {
+	Connect time.Duration
+	Read    time.Duration
+	Send    time.Duration
+}
+
+// merge returns the timeouts with the unset timeouts taken from the defaults.
+func (t proxyTimeouts) merge(defaults proxyTimeouts) proxyTimeouts {
+	if t.Connect == 0 {
+		t.Connect = defaults.Connect
+	}
+	if t.Read == 0 {
+		t.Read = defaults.Read
+	}
+	if t.Send == 0 {
+		t.Send = defaults.Send
+	}
+
+	return t
+}
+
+// getRouteOptions gets the options of the HTTPRoute from its annotations.
+// Invalid annotations are ignored and reported as warnings.
+func getRouteOptions(hr *v1beta1.HTTPRoute) (routeOptions, Warnings) {
+	warnings := newWarnings()
+
+	var opts routeOptions
+
+	if value, exists := hr.Annotations[streamingAnnotation]; exists {
+		streaming, err := strconv.ParseBool(value)
+		if err != nil {
+			warnings.AddWarning(hr, invalidAnnotationMsg(streamingAnnotation, value, "must be a boolean"))
+		} else {
+			opts.Streaming = streaming
+		}
+	}
+
+	if value, exists := hr.Annotations[forceRangesAnnotation]; exists {
+		forceRanges, err := strconv.ParseBool(value)
+		if err != nil {
+			warnings.AddWarning(hr, invalidAnnotationMsg(forceRangesAnnotation, value, "must be a boolean"))
+		} else {
+			opts.ForceRanges = forceRanges
+		}
+	}
+
+	if value, exists := hr.Annotations[maxRangesAnnotation]; exists {
+		maxRanges, err := strconv.Atoi(value)
+		if err != nil || maxRanges < 0 {
+			warnings.AddWarning(hr, invalidAnnotationMsg(maxRangesAnnotation, value, "must be a non-negative integer"))
+		} else {
+			opts.MaxRanges = &maxRanges
+		}
+	}
+
+	if value, exists := hr.Annotations[proxyHTTPVersionAnnotation]; exists {
+		if value != httpVersion10 && value != httpVersion11 {
+			warnings.AddWarning(hr, invalidAnnotationMsg(proxyHTTPVersionAnnotation, value, "must be 1.0 or 1.1"))
+		} else {
+			opts.ProxyHTTPVersion = value
+		}
+	}
+
+	if value, exists := hr.Annotations[backendProtocolAnnotation]; exists {
+		if value != backendProtocolHTTP && value != backendProtocolHTTPS {
+			warnings.AddWarning(hr, invalidAnnotationMsg(backendProtocolAnnotation, value, "must be http or https"))
+		} else {
+			opts.BackendTLS = value == backendProtocolHTTPS
+		}
+	}
+
+	if value, exists := hr.Annotations[backendSSLVerifyAnnotation]; exists {
+		verify, err := strconv.ParseBool(value)
+		if err != nil {
+			warnings.AddWarning(hr, invalidAnnotationMsg(backendSSLVerifyAnnotation, value, "must be a boolean"))
+		} else {
+			opts.BackendSSLVerify = verify
+		}
+	}
+
+	if value, exists := hr.Annotations[backendSSLNameAnnotation]; exists {
+		if errs := validation.IsDNS1123Subdomain(value); len(errs) > 0 {
+			warnings.AddWarning(hr, invalidAnnotationMsg(backendSSLNameAnnotation, value, "must be a hostname"))
+		} else {
+			opts.BackendSSLName = value
+		}
+	}
+
+	if value, exists := hr.Annotations[caseInsensitiveHeadersAnnotation]; exists {
+		caseInsensitive, err := strconv.ParseBool(value)
+		if err != nil {
+			warnings.AddWarning(hr, invalidAnnotationMsg(caseInsensitiveHeadersAnnotation, value, "must be a boolean"))
+		} else {
+			opts.CaseInsensitiveHeaders = caseInsensitive
+		}
+	}
+
+	if value, exists := hr.Annotations[webSocketAnnotation]; exists {
+		webSocket, err := strconv.ParseBool(value)
+		switch {
+		case err != nil:
+			warnings.AddWarning(hr, invalidAnnotationMsg(webSocketAnnotation, value, "must be a boolean"))
+		case webSocket && opts.ProxyHTTPVersion == httpVersion10:
+			warnings.AddWarning(hr, invalidAnnotationMsg(webSocketAnnotation, value,
+				fmt.Sprintf("WebSocket is not supported with %s %s", proxyHTTPVersionAnnotation, httpVersion10)))
+		default:
+			opts.WebSocket = webSocket
+		}
+	}
+
+	cacheConditions := []struct {
+		annotation string
+		variables  *[]string
+	}{
+		{annotation: proxyCacheBypassAnnotation, variables: &opts.CacheBypass},
+		{annotation: proxyNoCacheAnnotation, variables: &opts.NoCache},
+	}
+
+	for _, c := range cacheConditions {
+		if value, exists := hr.Annotations[c.annotation]; exists {
+			variables, err := parseCacheVariables(value)
+			if err != nil {
+				warnings.AddWarning(hr, invalidAnnotationMsg(c.annotation, value, err.Error()))
+			} else {
+				*c.variables = variables
+			}
+		}
+	}
+
+	timeouts := []struct {
+		annotation string
+		timeout    *time.Duration
+	}{
+		{annotation: proxyConnectTimeoutAnnotation, timeout: &opts.Timeouts.Connect},
+		{annotation: proxyReadTimeoutAnnotation, timeout: &opts.Timeouts.Read},
+		{annotation: proxySendTimeoutAnnotation, timeout: &opts.Timeouts.Send},
+	}
+
+	for _, t := range timeouts {
+		if value, exists := hr.Annotations[t.annotation]; exists {
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout < time.Millisecond {
+				warnings.AddWarning(hr, invalidAnnotationMsg(t.annotation, value, "must be a duration of at least 1ms"))
+			} else {
+				*t.timeout = timeout
+			}
+		}
+	}
+
+	if value, exists := hr.Annotations[proxyBufferingAnnotation]; exists {
+		buffering, err := strconv.ParseBool(value)
+		switch {
+		case err != nil:
+			warnings.AddWarning(hr, invalidAnnotationMsg(proxyBufferingAnnotation, value, "must be a boolean"))
+		case buffering && opts.Streaming:
+			warnings.AddWarning(hr, invalidAnnotationMsg(proxyBufferingAnnotation, value,
+				fmt.Sprintf("buffering is not supported with %s", streamingAnnotation)))
+		default:
+			opts.ProxyBuffering = &buffering
+		}
+	}
+
+	if value, exists := hr.Annotations[proxyBuffersAnnotation]; exists {
+		if _, _, err := parseProxyBuffers(value); err != nil {
+			warnings.AddWarning(hr, invalidAnnotationMsg(proxyBuffersAnnotation, value, err.Error()))
+		} else {
+			opts.ProxyBuffers = strings.Join(strings.Fields(value), " ")
+		}
+	}
+
+	if value, exists := hr.Annotations[proxyBufferSizeAnnotation]; exists {
+		if _, err := parseSize(value); err != nil {
+			warnings.AddWarning(hr, invalidAnnotationMsg(proxyBufferSizeAnnotation, value, err.Error()))
+		} else {
+			opts.ProxyBufferSize = value
+		}
+	}
+
+	if opts.ProxyBuffers != "" || opts.ProxyBufferSize != "" {
+		if err := validateProxyBuffers(opts.ProxyBuffers, opts.ProxyBufferSize); err != nil {
+			warnings.AddWarningf(hr, "annotations %s and %s are ignored: %v",
+				proxyBuffersAnnotation, proxyBufferSizeAnnotation, err)
+			opts.ProxyBuffers = ""
+			opts.ProxyBufferSize = ""
+		}
+	}
+
+	if value, exists := hr.Annotations[proxyNextUpstreamAnnotation]; exists {
+		conditions, err := parseNextUpstream(value)
+		if err != nil {
+			warnings.AddWarning(hr, invalidAnnotationMsg(proxyNextUpstreamAnnotation, value, err.Error()))
+		} else {
+			opts.NextUpstream = conditions
+		}
+	}
+
+	if value, exists := hr.Annotations[proxyNextUpstreamTriesAnnotation]; exists {
+		tries, err := strconv.Atoi(value)
+		if err != nil || tries <= 0 {
+			warnings.AddWarning(hr, invalidAnnotationMsg(proxyNextUpstreamTriesAnnotation, value,
+				"must be a positive integer"))
+		} else {
+			opts.NextUpstreamTries = tries
+		}
+	}
+
+	if value, exists := hr.Annotations[proxyNextUpstreamTimeoutAnnotation]; exists {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < time.Millisecond {
+			warnings.AddWarning(hr, invalidAnnotationMsg(proxyNextUpstreamTimeoutAnnotation, value,
+				"must be a duration of at least 1ms"))
+		} else {
+			opts.NextUpstreamTimeout = timeout
+		}
+	}
+
+	opts.CORS = getCORS(hr, warnings)
+
+	return opts, warnings
+}
+
+// getSnippets gets the location and server snippets of the HTTPRoute from its annotations as the lines of
+// the directives. If the snippets are not allowed, the annotations are ignored.
+// Invalid and ignored annotations are reported as warnings.
+func getSnippets(hr *v1beta1.HTTPRoute, allowed bool, warnings Warnings) (location, server []string) {
+	snippets := []struct {
+		annotation string
+		lines      *[]string
+	}{
+		{annotation: locationSnippetsAnnotation, lines: &location},
+		{annotation: serverSnippetsAnnotation, lines: &server},
+	}
+
+	for _, s := range snippets {
+		value, exists := hr.Annotations[s.annotation]
+		if !exists {
+			continue
+		}
+
+		if !allowed {
+			warnings.AddWarningf(hr, "annotation %s is ignored: the snippets are not allowed", s.annotation)
+			continue
+		}
+
+		lines, err := parseSnippet(value)
+		if err != nil {
+			warnings.AddWarning(hr, invalidAnnotationMsg(s.annotation, value, err.Error()))
+		} else {
+			*s.lines = lines
+		}
+	}
+
+	return location, server
+}
+
+// parseSnippet parses the NGINX directives of a snippet into lines without the surrounding whitespace, so that
+// the lines can be indented in the generated configuration. The directives must be complete: terminated, with
+// the closed blocks and quotes, so that the snippet cannot break the block it is added to.
+func parseSnippet(value string) ([]string, error) {
+	if err := NewStructuralValidator().Validate([]byte(value)); err != nil {
+		return nil, fmt.Errorf("must be complete NGINX directives: %w", err)
+	}
+
+	var lines []string
+	for _, l := range strings.Split(value, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil, errors.New("must not be empty")
+	}
+
+	return lines, nil
+}
+
+// getCORS gets the CORS headers of the HTTPRoute from its annotations. It returns nil if CORS is not enabled.
+// Invalid annotations are ignored and reported as warnings.
+func getCORS(hr *v1beta1.HTTPRoute, warnings Warnings) *cors {
+	origin, enabled := hr.Annotations[corsAllowOriginAnnotation]
+	if !enabled {
+		for _, name := range []string{
+			corsAllowMethodsAnnotation,
+			corsAllowHeadersAnnotation,
+			corsAllowCredentialsAnnotation,
+			corsMaxAgeAnnotation,
+		} {
+			if _, exists := hr.Annotations[name]; exists {
+				warnings.AddWarningf(hr, "annotation %s is ignored: CORS is not enabled with %s",
+					name, corsAllowOriginAnnotation)
+			}
+		}
+
+		return nil
+	}
+
+	if err := validateCORSOrigin(origin); err != nil {
+		warnings.AddWarning(hr, invalidAnnotationMsg(corsAllowOriginAnnotation, origin, err.Error()))
+		return nil
+	}
+
+	c := &cors{
+		AllowOrigin:  origin,
+		AllowMethods: defaultCORSAllowMethods,
+		AllowHeaders: defaultCORSAllowHeaders,
+		MaxAge:       defaultCORSMaxAge,
+	}
+
+	if value, exists := hr.Annotations[corsAllowMethodsAnnotation]; exists {
+		methods, err := parseCORSList(value, methodRegexp)
+		if err != nil {
+			warnings.AddWarning(hr, invalidAnnotationMsg(corsAllowMethodsAnnotation, value,
+				"must be a comma-separated list of methods"))
+		} else {
+			c.AllowMethods = methods
+		}
+	}
+
+	if value, exists := hr.Annotations[corsAllowHeadersAnnotation]; exists {
+		headers, err := parseCORSList(value, headerNameRegexp)
+		if err != nil {
+			warnings.AddWarning(hr, invalidAnnotationMsg(corsAllowHeadersAnnotation, value,
+				"must be a comma-separated list of headers"))
+		} else {
+			c.AllowHeaders = headers
+		}
+	}
+
+	if value, exists := hr.Annotations[corsAllowCredentialsAnnotation]; exists {
+		credentials, err := strconv.ParseBool(value)
+		switch {
+		case err != nil:
+			warnings.AddWarning(hr, invalidAnnotationMsg(corsAllowCredentialsAnnotation, value, "must be a boolean"))
+		case credentials && origin == "*":
+			// the browsers reject the credentialed responses that allow any origin
+			warnings.AddWarning(hr, invalidAnnotationMsg(corsAllowCredentialsAnnotation, value,
+				fmt.Sprintf("credentials are not supported with any origin in %s", corsAllowOriginAnnotation)))
+		default:
+			c.AllowCredentials = credentials
+		}
+	}
+
+	if value, exists := hr.Annotations[corsMaxAgeAnnotation]; exists {
+		maxAge, err := strconv.Atoi(value)
+		if err != nil || maxAge < 0 {
+			warnings.AddWarning(hr, invalidAnnotationMsg(corsMaxAgeAnnotation, value, "must be a non-negative integer"))
+		} else {
+			c.MaxAge = maxAge
+		}
+	}
+
+	return c
+}
+
+// validateCORSOrigin ensures the origin is * or the scheme, host and optional port of a URL, for example,
+// https://example.com:8443.
+func validateCORSOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return errors.New("must be * or an origin, for example, https://example.com")
+	}
+
+	return nil
+}
+
+var (
+	// methodRegexp matches an HTTP method, for example, GET.
+	methodRegexp = regexp.MustCompile(`^[A-Z]+$`)
+	// headerNameRegexp matches the name of an HTTP header, for example, Content-Type.
+	headerNameRegexp = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+)
+
+// parseCORSList parses a comma-separated list whose items match the regexp and returns it in the format of
+// the CORS headers, for example, "GET, POST".
+func parseCORSList(value string, itemRegexp *regexp.Regexp) (string, error) {
+	items := strings.Split(value, ",")
+
+	for i, item := range items {
+		item = strings.TrimSpace(item)
+		if !itemRegexp.MatchString(item) {
+			return "", fmt.Errorf("invalid item %q", item)
+		}
+
+		items[i] = item
+	}
+
+	return strings.Join(items, ", "), nil
+}
+
+// sizeRegexp matches a size in the NGINX format, for example, 8k.
+var sizeRegexp = regexp.MustCompile(`^([0-9]+)([kKmM]?)$`)
+
+// parseSize parses a positive size in the NGINX format and returns it in bytes.
+func parseSize(value string) (int64, error) {
+	m := sizeRegexp.FindStringSubmatch(value)
+	if m == nil {
+		return 0, errors.New("must be a size, for example, 8k")
+	}
+
+	size, err := strconv.ParseInt(m[1], 10, 32)
+	if err != nil || size == 0 {
+		return 0, errors.New("must be a positive size")
+	}
+
+	switch strings.ToLower(m[2]) {
+	case "k":
+		size *= 1024
+	case "m":
+		size *= 1024 * 1024
+	}
+
+	return size, nil
+}
+
+// parseProxyBuffers parses the number and size of the buffers, for example, "16 8k".
+func parseProxyBuffers(value string) (number int, size int64, err error) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return 0, 0, errors.New("must be the number and size of the buffers, for example, 16 8k")
+	}
+
+	number, err = strconv.Atoi(fields[0])
+	if err != nil || number < 2 {
+		return 0, 0, errors.New("the number of the buffers must be at least 2")
+	}
+
+	size, err = parseSize(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return number, size, nil
+}
+
+// validateProxyBuffers ensures NGINX accepts the buffers: the busy buffers, which are twice the larger of a buffer
+// and the buffer of the response header by default, must be smaller than all buffers minus one.
+// The defaults depend on the memory page size, so the smallest default buffers (8 4k) and the largest default buffer
+// of the response header (8k) are assumed for the unset values.
+func validateProxyBuffers(buffers, bufferSize string) error {
+	number, size := 8, int64(4*1024)
+	headerSize := int64(8 * 1024)
+
+	if buffers != "" {
+		number, size, _ = parseProxyBuffers(buffers)
+	}
+	if bufferSize != "" {
+		headerSize, _ = parseSize(bufferSize)
+	}
+
+	busy := 2 * size
+	if headerSize > size {
+		busy = 2 * headerSize
+	}
+
+	if busy >= int64(number-1)*size {
+		return errors.New("the size of all buffers except one must exceed twice the larger of a buffer and " +
+			"the buffer size")
+	}
+
+	return nil
+}
+
+// nextUpstreamOff disables passing the requests to the next backend. It cannot be combined with the conditions.
+const nextUpstreamOff = "off"
+
+// nextUpstreamConditions are the conditions of the NGINX proxy_next_upstream directive.
+var nextUpstreamConditions = map[string]struct{}{
+	"error":          {},
+	"timeout":        {},
+	"denied":         {},
+	"invalid_header": {},
+	"http_500":       {},
+	"http_502":       {},
+	"http_503":       {},
+	"http_504":       {},
+	"http_403":       {},
+	"http_404":       {},
+	"http_429":       {},
+	"non_idempotent": {},
+}
+
+// parseNextUpstream parses a space-separated list of the conditions of the NGINX proxy_next_upstream directive
+// or off.
+func parseNextUpstream(value string) ([]string, error) {
+	conditions := strings.Fields(value)
+	if len(conditions) == 0 {
+		return nil, errors.New("must be a space-separated list of conditions or off")
+	}
+
+	if len(conditions) == 1 && conditions[0] == nextUpstreamOff {
+		return conditions, nil
+	}
+
+	for _, c := range conditions {
+		if c == nextUpstreamOff {
+			return nil, errors.New("off cannot be combined with the conditions")
+		}
+
+		if _, exists := nextUpstreamConditions[c]; !exists {
+			return nil, fmt.Errorf("unknown condition %q", c)
+		}
+	}
+
+	return conditions, nil
+}
+
+// variableRegexp matches an NGINX variable, for example, $http_authorization.
+var variableRegexp = regexp.MustCompile(`^\$[A-Za-z_][A-Za-z0-9_]*$`)
+
+// supportedCacheVariablePrefixes are the prefixes of the variables of the request headers, cookies and arguments,
+// which are supported in the cache conditions.
+var supportedCacheVariablePrefixes = []string{"$http_", "$cookie_", "$arg_"}
+
+// supportedCacheVariables are the other variables of a request that are supported in the cache conditions.
+// The variables that are not related to the request, like $upstream_status, are not supported, because
+// NGINX evaluates the conditions before the request is proxied.
+var supportedCacheVariables = map[string]struct{}{
+	"$args":           {},
+	"$remote_user":    {},
+	"$request_method": {},
+	"$scheme":         {},
+}
+
+// parseCacheVariables parses a space-separated list of the NGINX variables of the cache conditions.
+// It returns an error if the list is empty, includes anything other than variables or includes unsupported variables.
+func parseCacheVariables(value string) ([]string, error) {
+	variables := strings.Fields(value)
+	if len(variables) == 0 {
+		return nil, errors.New("must be a space-separated list of NGINX variables")
+	}
+
+	for _, v := range variables {
+		if !variableRegexp.MatchString(v) {
+			return nil, errors.New("must be a space-separated list of NGINX variables")
+		}
+
+		if !isSupportedCacheVariable(v) {
+			return nil, fmt.Errorf("variable %s is not supported, must be a $http_, $cookie_ or $arg_ variable "+
+				"or one of $args, $remote_user, $request_method, $scheme", v)
+		}
+	}
+
+	return variables, nil
+}
+
+func isSupportedCacheVariable(v string) bool {
+	if _, exists := supportedCacheVariables[v]; exists {
+		return true
+	}
+
+	for _, prefix := range supportedCacheVariablePrefixes {
+		// the prefix alone is not a variable
+		if strings.HasPrefix(v, prefix) && len(v) > len(prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func invalidAnnotationMsg(name, value, reason string) string {
+	return fmt.Sprintf("annotation %s has invalid value %q: %s", name, value, reason)
+}
@@ -0,0 +1,670 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/helpers"
+)
+
+func TestGetRouteOptions(t *testing.T) {
+	createRoute := func(annotations map[string]string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        "route",
+				Annotations: annotations,
+			},
+		}
+	}
+
+	noAnnotations := createRoute(nil)
+	streaming := createRoute(map[string]string{streamingAnnotation: "true"})
+	notStreaming := createRoute(map[string]string{streamingAnnotation: "false"})
+	invalidStreaming := createRoute(map[string]string{streamingAnnotation: "yes please"})
+	ranges := createRoute(map[string]string{forceRangesAnnotation: "true", maxRangesAnnotation: "2"})
+	noRanges := createRoute(map[string]string{maxRangesAnnotation: "0"})
+	invalidRanges := createRoute(map[string]string{forceRangesAnnotation: "on", maxRangesAnnotation: "-1"})
+	invalidMaxRanges := createRoute(map[string]string{maxRangesAnnotation: "many"})
+	http10 := createRoute(map[string]string{proxyHTTPVersionAnnotation: "1.0"})
+	http11 := createRoute(map[string]string{proxyHTTPVersionAnnotation: "1.1"})
+	invalidHTTPVersion := createRoute(map[string]string{proxyHTTPVersionAnnotation: "2"})
+	backendTLS := createRoute(map[string]string{
+		backendProtocolAnnotation:  "https",
+		backendSSLVerifyAnnotation: "true",
+		backendSSLNameAnnotation:   "backend.test.svc",
+	})
+	caseInsensitiveHeaders := createRoute(map[string]string{caseInsensitiveHeadersAnnotation: "true"})
+	invalidCaseInsensitiveHeaders := createRoute(map[string]string{caseInsensitiveHeadersAnnotation: "loose"})
+	webSocket := createRoute(map[string]string{webSocketAnnotation: "true"})
+	invalidWebSocket := createRoute(map[string]string{webSocketAnnotation: "upgrade"})
+	http10WebSocket := createRoute(map[string]string{proxyHTTPVersionAnnotation: "1.0", webSocketAnnotation: "true"})
+	cacheConditions := createRoute(map[string]string{
+		proxyCacheBypassAnnotation: "$cookie_session  $http_authorization",
+		proxyNoCacheAnnotation:     "$http_authorization",
+	})
+	invalidCacheConditions := createRoute(map[string]string{
+		proxyCacheBypassAnnotation: "$cookie_session; return 200",
+		proxyNoCacheAnnotation:     " ",
+	})
+	unsupportedCacheConditions := createRoute(map[string]string{
+		proxyCacheBypassAnnotation: "$arg_nocache $upstream_status",
+		proxyNoCacheAnnotation:     "$http_",
+	})
+	otherCacheConditions := createRoute(map[string]string{
+		proxyCacheBypassAnnotation: "$arg_nocache $request_method",
+	})
+	backendHTTP := createRoute(map[string]string{backendProtocolAnnotation: "http"})
+	timeouts := createRoute(map[string]string{
+		proxyConnectTimeoutAnnotation: "5s",
+		proxyReadTimeoutAnnotation:    "1m30s",
+		proxySendTimeoutAnnotation:    "1500ms",
+	})
+	invalidTimeouts := createRoute(map[string]string{
+		proxyConnectTimeoutAnnotation: "5",
+		proxyReadTimeoutAnnotation:    "-1s",
+		proxySendTimeoutAnnotation:    "0s",
+	})
+	invalidBackendTLS := createRoute(map[string]string{
+		backendProtocolAnnotation:  "grpc",
+		backendSSLVerifyAnnotation: "always",
+		backendSSLNameAnnotation:   "backend;",
+	})
+
+	buffering := createRoute(map[string]string{
+		proxyBufferingAnnotation:  "false",
+		proxyBuffersAnnotation:    "16  8k",
+		proxyBufferSizeAnnotation: "16k",
+	})
+	invalidBuffering := createRoute(map[string]string{
+		proxyBufferingAnnotation:  "maybe",
+		proxyBuffersAnnotation:    "1 8k",
+		proxyBufferSizeAnnotation: "16kb",
+	})
+	streamingBuffering := createRoute(map[string]string{streamingAnnotation: "true", proxyBufferingAnnotation: "true"})
+	tooSmallBuffers := createRoute(map[string]string{
+		proxyBuffersAnnotation:    "4 4k",
+		proxyBufferSizeAnnotation: "16k",
+	})
+
+	nextUpstream := createRoute(map[string]string{
+		proxyNextUpstreamAnnotation:        "error  timeout http_502",
+		proxyNextUpstreamTriesAnnotation:   "3",
+		proxyNextUpstreamTimeoutAnnotation: "10s",
+	})
+	nextUpstreamOff := createRoute(map[string]string{proxyNextUpstreamAnnotation: "off"})
+	invalidNextUpstream := createRoute(map[string]string{
+		proxyNextUpstreamAnnotation:        "error off",
+		proxyNextUpstreamTriesAnnotation:   "0",
+		proxyNextUpstreamTimeoutAnnotation: "10",
+	})
+	unknownNextUpstream := createRoute(map[string]string{proxyNextUpstreamAnnotation: "error http_418"})
+
+	corsDefaults := createRoute(map[string]string{corsAllowOriginAnnotation: "*"})
+	corsCustom := createRoute(map[string]string{
+		corsAllowOriginAnnotation:      "https://example.com",
+		corsAllowMethodsAnnotation:     "GET,POST",
+		corsAllowHeadersAnnotation:     "X-Custom , Content-Type",
+		corsAllowCredentialsAnnotation: "true",
+		corsMaxAgeAnnotation:           "600",
+	})
+	invalidCORS := createRoute(map[string]string{
+		corsAllowOriginAnnotation:      "*",
+		corsAllowMethodsAnnotation:     "GET; return 200",
+		corsAllowHeadersAnnotation:     "X-Custom,",
+		corsAllowCredentialsAnnotation: "true",
+		corsMaxAgeAnnotation:           "-1",
+	})
+	invalidCORSOrigin := createRoute(map[string]string{corsAllowOriginAnnotation: "https://example.com/path"})
+	corsNotEnabled := createRoute(map[string]string{corsMaxAgeAnnotation: "600"})
+
+	tests := []struct {
+		hr          *v1beta1.HTTPRoute
+		expected    routeOptions
+		expWarnings Warnings
+		msg         string
+	}{
+		{
+			hr:          noAnnotations,
+			expected:    routeOptions{},
+			expWarnings: Warnings{},
+			msg:         "no annotations",
+		},
+		{
+			hr:          streaming,
+			expected:    routeOptions{Streaming: true},
+			expWarnings: Warnings{},
+			msg:         "streaming enabled",
+		},
+		{
+			hr:          notStreaming,
+			expected:    routeOptions{},
+			expWarnings: Warnings{},
+			msg:         "streaming disabled",
+		},
+		{
+			hr:       invalidStreaming,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidStreaming: []string{
+					`annotation gateway.nginx.org/streaming has invalid value "yes please": must be a boolean`,
+				},
+			},
+			msg: "invalid streaming",
+		},
+		{
+			hr:          ranges,
+			expected:    routeOptions{ForceRanges: true, MaxRanges: helpers.GetIntPointer(2)},
+			expWarnings: Warnings{},
+			msg:         "range options",
+		},
+		{
+			hr:          noRanges,
+			expected:    routeOptions{MaxRanges: helpers.GetIntPointer(0)},
+			expWarnings: Warnings{},
+			msg:         "ranges disabled",
+		},
+		{
+			hr:       invalidRanges,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidRanges: []string{
+					`annotation gateway.nginx.org/proxy-force-ranges has invalid value "on": must be a boolean`,
+					`annotation gateway.nginx.org/max-ranges has invalid value "-1": must be a non-negative integer`,
+				},
+			},
+			msg: "invalid range options",
+		},
+		{
+			hr:       invalidMaxRanges,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidMaxRanges: []string{
+					`annotation gateway.nginx.org/max-ranges has invalid value "many": must be a non-negative integer`,
+				},
+			},
+			msg: "invalid max ranges",
+		},
+		{
+			hr:          http10,
+			expected:    routeOptions{ProxyHTTPVersion: "1.0"},
+			expWarnings: Warnings{},
+			msg:         "http 1.0",
+		},
+		{
+			hr:          http11,
+			expected:    routeOptions{ProxyHTTPVersion: "1.1"},
+			expWarnings: Warnings{},
+			msg:         "http 1.1",
+		},
+		{
+			hr:       invalidHTTPVersion,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidHTTPVersion: []string{
+					`annotation gateway.nginx.org/proxy-http-version has invalid value "2": must be 1.0 or 1.1`,
+				},
+			},
+			msg: "invalid http version",
+		},
+		{
+			hr: backendTLS,
+			expected: routeOptions{
+				BackendTLS:       true,
+				BackendSSLVerify: true,
+				BackendSSLName:   "backend.test.svc",
+			},
+			expWarnings: Warnings{},
+			msg:         "https backends",
+		},
+		{
+			hr:          backendHTTP,
+			expected:    routeOptions{},
+			expWarnings: Warnings{},
+			msg:         "http backends",
+		},
+		{
+			hr:       invalidBackendTLS,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidBackendTLS: []string{
+					`annotation gateway.nginx.org/backend-protocol has invalid value "grpc": must be http or https`,
+					`annotation gateway.nginx.org/backend-ssl-verify has invalid value "always": must be a boolean`,
+					`annotation gateway.nginx.org/backend-ssl-name has invalid value "backend;": must be a hostname`,
+				},
+			},
+			msg: "invalid backend tls options",
+		},
+		{
+			hr:          caseInsensitiveHeaders,
+			expected:    routeOptions{CaseInsensitiveHeaders: true},
+			expWarnings: Warnings{},
+			msg:         "case-insensitive header values",
+		},
+		{
+			hr:       invalidCaseInsensitiveHeaders,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidCaseInsensitiveHeaders: []string{
+					`annotation gateway.nginx.org/case-insensitive-header-values has invalid value "loose": ` +
+						`must be a boolean`,
+				},
+			},
+			msg: "invalid case-insensitive header values",
+		},
+		{
+			hr:          webSocket,
+			expected:    routeOptions{WebSocket: true},
+			expWarnings: Warnings{},
+			msg:         "websocket",
+		},
+		{
+			hr:       invalidWebSocket,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidWebSocket: []string{
+					`annotation gateway.nginx.org/websocket has invalid value "upgrade": must be a boolean`,
+				},
+			},
+			msg: "invalid websocket",
+		},
+		{
+			hr:       http10WebSocket,
+			expected: routeOptions{ProxyHTTPVersion: "1.0"},
+			expWarnings: Warnings{
+				http10WebSocket: []string{
+					`annotation gateway.nginx.org/websocket has invalid value "true": ` +
+						`WebSocket is not supported with gateway.nginx.org/proxy-http-version 1.0`,
+				},
+			},
+			msg: "websocket with http 1.0",
+		},
+		{
+			hr: cacheConditions,
+			expected: routeOptions{
+				CacheBypass: []string{"$cookie_session", "$http_authorization"},
+				NoCache:     []string{"$http_authorization"},
+			},
+			expWarnings: Warnings{},
+			msg:         "cache conditions",
+		},
+		{
+			hr:       invalidCacheConditions,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidCacheConditions: []string{
+					`annotation gateway.nginx.org/proxy-cache-bypass has invalid value "$cookie_session; return 200": ` +
+						`must be a space-separated list of NGINX variables`,
+					`annotation gateway.nginx.org/proxy-no-cache has invalid value " ": ` +
+						`must be a space-separated list of NGINX variables`,
+				},
+			},
+			msg: "invalid cache conditions",
+		},
+		{
+			hr:       unsupportedCacheConditions,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				unsupportedCacheConditions: []string{
+					`annotation gateway.nginx.org/proxy-cache-bypass has invalid value "$arg_nocache $upstream_status": ` +
+						`variable $upstream_status is not supported, must be a $http_, $cookie_ or $arg_ variable ` +
+						`or one of $args, $remote_user, $request_method, $scheme`,
+					`annotation gateway.nginx.org/proxy-no-cache has invalid value "$http_": ` +
+						`variable $http_ is not supported, must be a $http_, $cookie_ or $arg_ variable ` +
+						`or one of $args, $remote_user, $request_method, $scheme`,
+				},
+			},
+			msg: "unsupported cache conditions",
+		},
+		{
+			hr: otherCacheConditions,
+			expected: routeOptions{
+				CacheBypass: []string{"$arg_nocache", "$request_method"},
+			},
+			expWarnings: Warnings{},
+			msg:         "cache conditions with other supported variables",
+		},
+		{
+			hr: timeouts,
+			expected: routeOptions{
+				Timeouts: proxyTimeouts{
+					Connect: 5 * time.Second,
+					Read:    90 * time.Second,
+					Send:    1500 * time.Millisecond,
+				},
+			},
+			expWarnings: Warnings{},
+			msg:         "proxy timeouts",
+		},
+		{
+			hr:       invalidTimeouts,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidTimeouts: []string{
+					`annotation gateway.nginx.org/proxy-connect-timeout has invalid value "5": ` +
+						`must be a duration of at least 1ms`,
+					`annotation gateway.nginx.org/proxy-read-timeout has invalid value "-1s": ` +
+						`must be a duration of at least 1ms`,
+					`annotation gateway.nginx.org/proxy-send-timeout has invalid value "0s": ` +
+						`must be a duration of at least 1ms`,
+				},
+			},
+			msg: "invalid proxy timeouts",
+		},
+		{
+			hr: nextUpstream,
+			expected: routeOptions{
+				NextUpstream:        []string{"error", "timeout", "http_502"},
+				NextUpstreamTries:   3,
+				NextUpstreamTimeout: 10 * time.Second,
+			},
+			expWarnings: Warnings{},
+			msg:         "next upstream",
+		},
+		{
+			hr: nextUpstreamOff,
+			expected: routeOptions{
+				NextUpstream: []string{"off"},
+			},
+			expWarnings: Warnings{},
+			msg:         "next upstream off",
+		},
+		{
+			hr:       invalidNextUpstream,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidNextUpstream: []string{
+					`annotation gateway.nginx.org/proxy-next-upstream has invalid value "error off": ` +
+						`off cannot be combined with the conditions`,
+					`annotation gateway.nginx.org/proxy-next-upstream-tries has invalid value "0": ` +
+						`must be a positive integer`,
+					`annotation gateway.nginx.org/proxy-next-upstream-timeout has invalid value "10": ` +
+						`must be a duration of at least 1ms`,
+				},
+			},
+			msg: "invalid next upstream",
+		},
+		{
+			hr:       unknownNextUpstream,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				unknownNextUpstream: []string{
+					`annotation gateway.nginx.org/proxy-next-upstream has invalid value "error http_418": ` +
+						`unknown condition "http_418"`,
+				},
+			},
+			msg: "unknown next upstream condition",
+		},
+		{
+			hr: buffering,
+			expected: routeOptions{
+				ProxyBuffering:  helpers.GetBoolPointer(false),
+				ProxyBuffers:    "16 8k",
+				ProxyBufferSize: "16k",
+			},
+			expWarnings: Warnings{},
+			msg:         "proxy buffering",
+		},
+		{
+			hr:       invalidBuffering,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidBuffering: []string{
+					`annotation gateway.nginx.org/proxy-buffering has invalid value "maybe": must be a boolean`,
+					`annotation gateway.nginx.org/proxy-buffers has invalid value "1 8k": ` +
+						`the number of the buffers must be at least 2`,
+					`annotation gateway.nginx.org/proxy-buffer-size has invalid value "16kb": ` +
+						`must be a size, for example, 8k`,
+				},
+			},
+			msg: "invalid proxy buffering",
+		},
+		{
+			hr:       streamingBuffering,
+			expected: routeOptions{Streaming: true},
+			expWarnings: Warnings{
+				streamingBuffering: []string{
+					`annotation gateway.nginx.org/proxy-buffering has invalid value "true": ` +
+						`buffering is not supported with gateway.nginx.org/streaming`,
+				},
+			},
+			msg: "proxy buffering with streaming",
+		},
+		{
+			hr:       tooSmallBuffers,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				tooSmallBuffers: []string{
+					`annotations gateway.nginx.org/proxy-buffers and gateway.nginx.org/proxy-buffer-size are ignored: ` +
+						`the size of all buffers except one must exceed twice the larger of a buffer and the buffer size`,
+				},
+			},
+			msg: "proxy buffers too small for the buffer size",
+		},
+		{
+			hr: corsDefaults,
+			expected: routeOptions{
+				CORS: &cors{
+					AllowOrigin:  "*",
+					AllowMethods: "GET, PUT, POST, DELETE, PATCH, OPTIONS",
+					AllowHeaders: "Authorization, Content-Type",
+					MaxAge:       86400,
+				},
+			},
+			expWarnings: Warnings{},
+			msg:         "cors with defaults",
+		},
+		{
+			hr: corsCustom,
+			expected: routeOptions{
+				CORS: &cors{
+					AllowOrigin:      "https://example.com",
+					AllowMethods:     "GET, POST",
+					AllowHeaders:     "X-Custom, Content-Type",
+					AllowCredentials: true,
+					MaxAge:           600,
+				},
+			},
+			expWarnings: Warnings{},
+			msg:         "cors with custom headers",
+		},
+		{
+			hr: invalidCORS,
+			expected: routeOptions{
+				CORS: &cors{
+					AllowOrigin:  "*",
+					AllowMethods: "GET, PUT, POST, DELETE, PATCH, OPTIONS",
+					AllowHeaders: "Authorization, Content-Type",
+					MaxAge:       86400,
+				},
+			},
+			expWarnings: Warnings{
+				invalidCORS: []string{
+					`annotation gateway.nginx.org/cors-allow-methods has invalid value "GET; return 200": ` +
+						`must be a comma-separated list of methods`,
+					`annotation gateway.nginx.org/cors-allow-headers has invalid value "X-Custom,": ` +
+						`must be a comma-separated list of headers`,
+					`annotation gateway.nginx.org/cors-allow-credentials has invalid value "true": ` +
+						`credentials are not supported with any origin in gateway.nginx.org/cors-allow-origin`,
+					`annotation gateway.nginx.org/cors-max-age has invalid value "-1": must be a non-negative integer`,
+				},
+			},
+			msg: "invalid cors headers",
+		},
+		{
+			hr:       invalidCORSOrigin,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				invalidCORSOrigin: []string{
+					`annotation gateway.nginx.org/cors-allow-origin has invalid value "https://example.com/path": ` +
+						`must be * or an origin, for example, https://example.com`,
+				},
+			},
+			msg: "invalid cors origin",
+		},
+		{
+			hr:       corsNotEnabled,
+			expected: routeOptions{},
+			expWarnings: Warnings{
+				corsNotEnabled: []string{
+					"annotation gateway.nginx.org/cors-max-age is ignored: " +
+						"CORS is not enabled with gateway.nginx.org/cors-allow-origin",
+				},
+			},
+			msg: "cors headers without origin",
+		},
+	}
+
+	for _, test := range tests {
+		result, warnings := getRouteOptions(test.hr)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("getRouteOptions() %q mismatch (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expWarnings, warnings); diff != "" {
+			t.Errorf("getRouteOptions() %q mismatch on warnings (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestGetSnippets(t *testing.T) {
+	createRoute := func(annotations map[string]string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        "route",
+				Annotations: annotations,
+			},
+		}
+	}
+
+	snippets := createRoute(map[string]string{
+		locationSnippetsAnnotation: "proxy_set_header X-Env prod;\n\n  if ($http_x_debug) {\n    return 204;\n  }\n",
+		serverSnippetsAnnotation:   "  add_header X-Server gateway;  ",
+	})
+	invalidSnippets := createRoute(map[string]string{
+		locationSnippetsAnnotation: "}\nlocation /admin {",
+		serverSnippetsAnnotation:   " \n ",
+	})
+
+	tests := []struct {
+		hr               *v1beta1.HTTPRoute
+		expectedLocation []string
+		expectedServer   []string
+		expWarnings      Warnings
+		allowed          bool
+		msg              string
+	}{
+		{
+			hr:          createRoute(nil),
+			allowed:     true,
+			expWarnings: Warnings{},
+			msg:         "no snippets",
+		},
+		{
+			hr:      snippets,
+			allowed: true,
+			expectedLocation: []string{
+				"proxy_set_header X-Env prod;",
+				"if ($http_x_debug) {",
+				"return 204;",
+				"}",
+			},
+			expectedServer: []string{"add_header X-Server gateway;"},
+			expWarnings:    Warnings{},
+			msg:            "valid snippets",
+		},
+		{
+			hr:      invalidSnippets,
+			allowed: true,
+			expWarnings: Warnings{
+				invalidSnippets: []string{
+					`annotation gateway.nginx.org/location-snippets has invalid value "}\nlocation /admin {": ` +
+						`must be complete NGINX directives: line 1: unexpected "}"`,
+					`annotation gateway.nginx.org/server-snippets has invalid value " \n ": must not be empty`,
+				},
+			},
+			msg: "invalid snippets",
+		},
+		{
+			hr:      snippets,
+			allowed: false,
+			expWarnings: Warnings{
+				snippets: []string{
+					"annotation gateway.nginx.org/location-snippets is ignored: the snippets are not allowed",
+					"annotation gateway.nginx.org/server-snippets is ignored: the snippets are not allowed",
+				},
+			},
+			msg: "snippets not allowed",
+		},
+	}
+
+	for _, test := range tests {
+		warnings := newWarnings()
+
+		location, server := getSnippets(test.hr, test.allowed, warnings)
+		if diff := cmp.Diff(test.expectedLocation, location); diff != "" {
+			t.Errorf("getSnippets() %q mismatch on location snippets (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedServer, server); diff != "" {
+			t.Errorf("getSnippets() %q mismatch on server snippets (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expWarnings, warnings); diff != "" {
+			t.Errorf("getSnippets() %q mismatch on warnings (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestMergeProxyTimeouts(t *testing.T) {
+	defaults := proxyTimeouts{
+		Connect: 5 * time.Second,
+		Read:    60 * time.Second,
+		Send:    60 * time.Second,
+	}
+
+	tests := []struct {
+		timeouts proxyTimeouts
+		defaults proxyTimeouts
+		expected proxyTimeouts
+		msg      string
+	}{
+		{
+			timeouts: proxyTimeouts{},
+			defaults: proxyTimeouts{},
+			expected: proxyTimeouts{},
+			msg:      "no timeouts",
+		},
+		{
+			timeouts: proxyTimeouts{},
+			defaults: defaults,
+			expected: defaults,
+			msg:      "inherited defaults",
+		},
+		{
+			timeouts: proxyTimeouts{Read: 5 * time.Minute},
+			defaults: defaults,
+			expected: proxyTimeouts{
+				Connect: 5 * time.Second,
+				Read:    5 * time.Minute,
+				Send:    60 * time.Second,
+			},
+			msg: "one overridden timeout",
+		},
+		{
+			timeouts: proxyTimeouts{Connect: time.Second},
+			defaults: proxyTimeouts{},
+			expected: proxyTimeouts{Connect: time.Second},
+			msg:      "timeout without defaults",
+		},
+	}
+
+	for _, test := range tests {
+		result := test.timeouts.merge(test.defaults)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("merge() %q mismatch (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
@@ -2,6 +2,7 @@
 package configfakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/nginx/config"
@@ -9,40 +10,107 @@ import (
 )
 
 type FakeGenerator struct {
-	GenerateStub        func(state.Configuration) ([]byte, config.Warnings)
+	GenerateStub        func(context.Context, state.Configuration) ([]byte, config.Warnings, error)
 	generateMutex       sync.RWMutex
 	generateArgsForCall []struct {
-		arg1 state.Configuration
+		arg1 context.Context
+		arg2 state.Configuration
 	}
 	generateReturns struct {
 		result1 []byte
 		result2 config.Warnings
+		result3 error
 	}
 	generateReturnsOnCall map[int]struct {
 		result1 []byte
 		result2 config.Warnings
+		result3 error
+	}
+	GenerateAndCompareStub        func(context.Context, []byte, state.Configuration) ([]byte, bool, config.Warnings, error)
+	generateAndCompareMutex       sync.RWMutex
+	generateAndCompareArgsForCall []struct {
+		arg1 context.Context
+		arg2 []byte
+		arg3 state.Configuration
+	}
+	generateAndCompareReturns struct {
+		result1 []byte
+		result2 bool
+		result3 config.Warnings
+		result4 error
+	}
+	generateAndCompareReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 bool
+		result3 config.Warnings
+		result4 error
+	}
+	GenerateFilesStub        func(context.Context, state.Configuration) (map[string][]byte, config.Warnings, error)
+	generateFilesMutex       sync.RWMutex
+	generateFilesArgsForCall []struct {
+		arg1 context.Context
+		arg2 state.Configuration
+	}
+	generateFilesReturns struct {
+		result1 map[string][]byte
+		result2 config.Warnings
+		result3 error
+	}
+	generateFilesReturnsOnCall map[int]struct {
+		result1 map[string][]byte
+		result2 config.Warnings
+		result3 error
+	}
+	GenerateMainStub        func() ([]byte, error)
+	generateMainMutex       sync.RWMutex
+	generateMainArgsForCall []struct {
+	}
+	generateMainReturns struct {
+		result1 []byte
+		result2 error
+	}
+	generateMainReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	GenerateStreamStub        func(context.Context, state.Configuration) ([]byte, config.Warnings, error)
+	generateStreamMutex       sync.RWMutex
+	generateStreamArgsForCall []struct {
+		arg1 context.Context
+		arg2 state.Configuration
+	}
+	generateStreamReturns struct {
+		result1 []byte
+		result2 config.Warnings
+		result3 error
+	}
+	generateStreamReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 config.Warnings
+		result3 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeGenerator) Generate(arg1 state.Configuration) ([]byte, config.Warnings) {
+func (fake *FakeGenerator) Generate(arg1 context.Context, arg2 state.Configuration) ([]byte, config.Warnings, error) {
 	fake.generateMutex.Lock()
 	ret, specificReturn := fake.generateReturnsOnCall[len(fake.generateArgsForCall)]
 	fake.generateArgsForCall = append(fake.generateArgsForCall, struct {
-		arg1 state.Configuration
-	}{arg1})
+		arg1 context.Context
+		arg2 state.Configuration
+	}{arg1, arg2})
 	stub := fake.GenerateStub
 	fakeReturns := fake.generateReturns
-	fake.recordInvocation("Generate", []interface{}{arg1})
+	fake.recordInvocation("Generate", []interface{}{arg1, arg2})
 	fake.generateMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1, ret.result2, ret.result3
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
 }
 
 func (fake *FakeGenerator) GenerateCallCount() int {
@@ -51,30 +119,31 @@ func (fake *FakeGenerator) GenerateCallCount() int {
 	return len(fake.generateArgsForCall)
 }
 
-func (fake *FakeGenerator) GenerateCalls(stub func(state.Configuration) ([]byte, config.Warnings)) {
+func (fake *FakeGenerator) GenerateCalls(stub func(context.Context, state.Configuration) ([]byte, config.Warnings, error)) {
 	fake.generateMutex.Lock()
 	defer fake.generateMutex.Unlock()
 	fake.GenerateStub = stub
 }
 
-func (fake *FakeGenerator) GenerateArgsForCall(i int) state.Configuration {
+func (fake *FakeGenerator) GenerateArgsForCall(i int) (context.Context, state.Configuration) {
 	fake.generateMutex.RLock()
 	defer fake.generateMutex.RUnlock()
 	argsForCall := fake.generateArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeGenerator) GenerateReturns(result1 []byte, result2 config.Warnings) {
+func (fake *FakeGenerator) GenerateReturns(result1 []byte, result2 config.Warnings, result3 error) {
 	fake.generateMutex.Lock()
 	defer fake.generateMutex.Unlock()
 	fake.GenerateStub = nil
 	fake.generateReturns = struct {
 		result1 []byte
 		result2 config.Warnings
-	}{result1, result2}
+		result3 error
+	}{result1, result2, result3}
 }
 
-func (fake *FakeGenerator) GenerateReturnsOnCall(i int, result1 []byte, result2 config.Warnings) {
+func (fake *FakeGenerator) GenerateReturnsOnCall(i int, result1 []byte, result2 config.Warnings, result3 error) {
 	fake.generateMutex.Lock()
 	defer fake.generateMutex.Unlock()
 	fake.GenerateStub = nil
@@ -82,19 +151,298 @@ func (fake *FakeGenerator) GenerateReturnsOnCall(i int, result1 []byte, result2
 		fake.generateReturnsOnCall = make(map[int]struct {
 			result1 []byte
 			result2 config.Warnings
+			result3 error
 		})
 	}
 	fake.generateReturnsOnCall[i] = struct {
 		result1 []byte
 		result2 config.Warnings
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeGenerator) GenerateAndCompare(arg1 context.Context, arg2 []byte, arg3 state.Configuration) ([]byte, bool, config.Warnings, error) {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.generateAndCompareMutex.Lock()
+	ret, specificReturn := fake.generateAndCompareReturnsOnCall[len(fake.generateAndCompareArgsForCall)]
+	fake.generateAndCompareArgsForCall = append(fake.generateAndCompareArgsForCall, struct {
+		arg1 context.Context
+		arg2 []byte
+		arg3 state.Configuration
+	}{arg1, arg2Copy, arg3})
+	stub := fake.GenerateAndCompareStub
+	fakeReturns := fake.generateAndCompareReturns
+	fake.recordInvocation("GenerateAndCompare", []interface{}{arg1, arg2Copy, arg3})
+	fake.generateAndCompareMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3, ret.result4
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3, fakeReturns.result4
+}
+
+func (fake *FakeGenerator) GenerateAndCompareCallCount() int {
+	fake.generateAndCompareMutex.RLock()
+	defer fake.generateAndCompareMutex.RUnlock()
+	return len(fake.generateAndCompareArgsForCall)
+}
+
+func (fake *FakeGenerator) GenerateAndCompareCalls(stub func(context.Context, []byte, state.Configuration) ([]byte, bool, config.Warnings, error)) {
+	fake.generateAndCompareMutex.Lock()
+	defer fake.generateAndCompareMutex.Unlock()
+	fake.GenerateAndCompareStub = stub
+}
+
+func (fake *FakeGenerator) GenerateAndCompareArgsForCall(i int) (context.Context, []byte, state.Configuration) {
+	fake.generateAndCompareMutex.RLock()
+	defer fake.generateAndCompareMutex.RUnlock()
+	argsForCall := fake.generateAndCompareArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGenerator) GenerateAndCompareReturns(result1 []byte, result2 bool, result3 config.Warnings, result4 error) {
+	fake.generateAndCompareMutex.Lock()
+	defer fake.generateAndCompareMutex.Unlock()
+	fake.GenerateAndCompareStub = nil
+	fake.generateAndCompareReturns = struct {
+		result1 []byte
+		result2 bool
+		result3 config.Warnings
+		result4 error
+	}{result1, result2, result3, result4}
+}
+
+func (fake *FakeGenerator) GenerateAndCompareReturnsOnCall(i int, result1 []byte, result2 bool, result3 config.Warnings, result4 error) {
+	fake.generateAndCompareMutex.Lock()
+	defer fake.generateAndCompareMutex.Unlock()
+	fake.GenerateAndCompareStub = nil
+	if fake.generateAndCompareReturnsOnCall == nil {
+		fake.generateAndCompareReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 bool
+			result3 config.Warnings
+			result4 error
+		})
+	}
+	fake.generateAndCompareReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 bool
+		result3 config.Warnings
+		result4 error
+	}{result1, result2, result3, result4}
+}
+
+func (fake *FakeGenerator) GenerateFiles(arg1 context.Context, arg2 state.Configuration) (map[string][]byte, config.Warnings, error) {
+	fake.generateFilesMutex.Lock()
+	ret, specificReturn := fake.generateFilesReturnsOnCall[len(fake.generateFilesArgsForCall)]
+	fake.generateFilesArgsForCall = append(fake.generateFilesArgsForCall, struct {
+		arg1 context.Context
+		arg2 state.Configuration
+	}{arg1, arg2})
+	stub := fake.GenerateFilesStub
+	fakeReturns := fake.generateFilesReturns
+	fake.recordInvocation("GenerateFiles", []interface{}{arg1, arg2})
+	fake.generateFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeGenerator) GenerateFilesCallCount() int {
+	fake.generateFilesMutex.RLock()
+	defer fake.generateFilesMutex.RUnlock()
+	return len(fake.generateFilesArgsForCall)
+}
+
+func (fake *FakeGenerator) GenerateFilesCalls(stub func(context.Context, state.Configuration) (map[string][]byte, config.Warnings, error)) {
+	fake.generateFilesMutex.Lock()
+	defer fake.generateFilesMutex.Unlock()
+	fake.GenerateFilesStub = stub
+}
+
+func (fake *FakeGenerator) GenerateFilesArgsForCall(i int) (context.Context, state.Configuration) {
+	fake.generateFilesMutex.RLock()
+	defer fake.generateFilesMutex.RUnlock()
+	argsForCall := fake.generateFilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGenerator) GenerateFilesReturns(result1 map[string][]byte, result2 config.Warnings, result3 error) {
+	fake.generateFilesMutex.Lock()
+	defer fake.generateFilesMutex.Unlock()
+	fake.GenerateFilesStub = nil
+	fake.generateFilesReturns = struct {
+		result1 map[string][]byte
+		result2 config.Warnings
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeGenerator) GenerateFilesReturnsOnCall(i int, result1 map[string][]byte, result2 config.Warnings, result3 error) {
+	fake.generateFilesMutex.Lock()
+	defer fake.generateFilesMutex.Unlock()
+	fake.GenerateFilesStub = nil
+	if fake.generateFilesReturnsOnCall == nil {
+		fake.generateFilesReturnsOnCall = make(map[int]struct {
+			result1 map[string][]byte
+			result2 config.Warnings
+			result3 error
+		})
+	}
+	fake.generateFilesReturnsOnCall[i] = struct {
+		result1 map[string][]byte
+		result2 config.Warnings
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeGenerator) GenerateMain() ([]byte, error) {
+	fake.generateMainMutex.Lock()
+	ret, specificReturn := fake.generateMainReturnsOnCall[len(fake.generateMainArgsForCall)]
+	fake.generateMainArgsForCall = append(fake.generateMainArgsForCall, struct {
+	}{})
+	stub := fake.GenerateMainStub
+	fakeReturns := fake.generateMainReturns
+	fake.recordInvocation("GenerateMain", []interface{}{})
+	fake.generateMainMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGenerator) GenerateMainCallCount() int {
+	fake.generateMainMutex.RLock()
+	defer fake.generateMainMutex.RUnlock()
+	return len(fake.generateMainArgsForCall)
+}
+
+func (fake *FakeGenerator) GenerateMainCalls(stub func() ([]byte, error)) {
+	fake.generateMainMutex.Lock()
+	defer fake.generateMainMutex.Unlock()
+	fake.GenerateMainStub = stub
+}
+
+func (fake *FakeGenerator) GenerateMainReturns(result1 []byte, result2 error) {
+	fake.generateMainMutex.Lock()
+	defer fake.generateMainMutex.Unlock()
+	fake.GenerateMainStub = nil
+	fake.generateMainReturns = struct {
+		result1 []byte
+		result2 error
 	}{result1, result2}
 }
 
+func (fake *FakeGenerator) GenerateMainReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.generateMainMutex.Lock()
+	defer fake.generateMainMutex.Unlock()
+	fake.GenerateMainStub = nil
+	if fake.generateMainReturnsOnCall == nil {
+		fake.generateMainReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.generateMainReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGenerator) GenerateStream(arg1 context.Context, arg2 state.Configuration) ([]byte, config.Warnings, error) {
+	fake.generateStreamMutex.Lock()
+	ret, specificReturn := fake.generateStreamReturnsOnCall[len(fake.generateStreamArgsForCall)]
+	fake.generateStreamArgsForCall = append(fake.generateStreamArgsForCall, struct {
+		arg1 context.Context
+		arg2 state.Configuration
+	}{arg1, arg2})
+	stub := fake.GenerateStreamStub
+	fakeReturns := fake.generateStreamReturns
+	fake.recordInvocation("GenerateStream", []interface{}{arg1, arg2})
+	fake.generateStreamMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeGenerator) GenerateStreamCallCount() int {
+	fake.generateStreamMutex.RLock()
+	defer fake.generateStreamMutex.RUnlock()
+	return len(fake.generateStreamArgsForCall)
+}
+
+func (fake *FakeGenerator) GenerateStreamCalls(stub func(context.Context, state.Configuration) ([]byte, config.Warnings, error)) {
+	fake.generateStreamMutex.Lock()
+	defer fake.generateStreamMutex.Unlock()
+	fake.GenerateStreamStub = stub
+}
+
+func (fake *FakeGenerator) GenerateStreamArgsForCall(i int) (context.Context, state.Configuration) {
+	fake.generateStreamMutex.RLock()
+	defer fake.generateStreamMutex.RUnlock()
+	argsForCall := fake.generateStreamArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGenerator) GenerateStreamReturns(result1 []byte, result2 config.Warnings, result3 error) {
+	fake.generateStreamMutex.Lock()
+	defer fake.generateStreamMutex.Unlock()
+	fake.GenerateStreamStub = nil
+	fake.generateStreamReturns = struct {
+		result1 []byte
+		result2 config.Warnings
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeGenerator) GenerateStreamReturnsOnCall(i int, result1 []byte, result2 config.Warnings, result3 error) {
+	fake.generateStreamMutex.Lock()
+	defer fake.generateStreamMutex.Unlock()
+	fake.GenerateStreamStub = nil
+	if fake.generateStreamReturnsOnCall == nil {
+		fake.generateStreamReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 config.Warnings
+			result3 error
+		})
+	}
+	fake.generateStreamReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 config.Warnings
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeGenerator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.generateMutex.RLock()
 	defer fake.generateMutex.RUnlock()
+	fake.generateAndCompareMutex.RLock()
+	defer fake.generateAndCompareMutex.RUnlock()
+	fake.generateFilesMutex.RLock()
+	defer fake.generateFilesMutex.RUnlock()
+	fake.generateMainMutex.RLock()
+	defer fake.generateMainMutex.RUnlock()
+	fake.generateStreamMutex.RLock()
+	defer fake.generateStreamMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
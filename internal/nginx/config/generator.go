@@ -1,169 +1,1817 @@
 package config
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/metrics"
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
 )
 
-// nginx502Server is used as a backend for services that cannot be resolved (have no IP address).
-const nginx502Server = "unix:/var/lib/nginx/nginx-502-server.sock"
+const (
+	// nginx502Server is the address of the internal server that responds with 502 to all requests.
+	nginx502Server = "unix:/var/lib/nginx/nginx-502-server.sock"
+	// fallbackUpstreamName is the name of the upstream that proxies to nginx502Server.
+	// It is used as a backend for services that cannot be resolved (have no IP address).
+	fallbackUpstreamName = "nginx-502-fallback"
+	// unixSocketPrefix is the prefix of the address of a backend that is reachable through a Unix domain socket.
+	// A resolver resolves such a backend to the path of the socket with the prefix, like unix:/var/run/app.sock.
+	unixSocketPrefix = "unix:"
+	// HTTPServersMainFile is the name of the main file of the http configuration generated by GenerateFiles.
+	HTTPServersMainFile = "http-servers.conf"
+	// httpConfigFolder is the folder of the http configuration files.
+	httpConfigFolder = "/etc/nginx/conf.d"
+	// httpServerFilesFolder is the folder, relative to httpConfigFolder, of the server files generated by GenerateFiles.
+	// The files are not in httpConfigFolder itself, so that NGINX only includes them through the main file.
+	httpServerFilesFolder = "servers"
+	// sslCertificateVar is the NGINX variable with the path of the certificate selected by the SNI of an SSL
+	// connection.
+	sslCertificateVar = "$gateway_ssl_certificate"
+	// sslCertificateKeyVar is the NGINX variable with the path of the key of the certificate selected by the SNI
+	// of an SSL connection.
+	sslCertificateKeyVar = "$gateway_ssl_certificate_key"
+	// DefaultRequestIDHeader is the default name of the header that carries the ID of a request.
+	DefaultRequestIDHeader = "X-Request-ID"
+	// DefaultHTTPPort is the default port NGINX listens on for HTTP.
+	DefaultHTTPPort = 80
+	// DefaultHTTPSPort is the default port NGINX listens on for HTTPS.
+	DefaultHTTPSPort = 443
+	// IPFamilyIPv4 makes NGINX accept only IPv4 connections.
+	IPFamilyIPv4 = "ipv4"
+	// IPFamilyIPv6 makes NGINX accept only IPv6 connections.
+	IPFamilyIPv6 = "ipv6"
+	// IPFamilyDual makes NGINX accept both IPv4 and IPv6 connections.
+	IPFamilyDual = "dual"
+	// DefaultNoBackendsResponseCode is the default status code of the response NGINX returns when none of
+	// the backends of a rule can be resolved.
+	DefaultNoBackendsResponseCode = 502
+	// DefaultResolveTimeout is the default time limit of the resolution of the backends of a server.
+	DefaultResolveTimeout = state.DefaultResolveTimeout
+	// DefaultWorkerProcesses is the default number of the NGINX worker processes: one per CPU core.
+	DefaultWorkerProcesses = "auto"
+	// DefaultWorkerConnections is the default maximum number of the connections of an NGINX worker process,
+	// including the connections to the backends.
+	DefaultWorkerConnections = 1024
+	// DefaultHSTSMaxAge is the default time, in seconds, the browsers only access a host over HTTPS after they receive
+	// the Strict-Transport-Security header: one year.
+	DefaultHSTSMaxAge = 31536000
+	// DefaultHealthCheckPath is the default path of the health check endpoint of NGINX.
+	DefaultHealthCheckPath = "/nginx-health"
+	// DefaultUpstreamKeepalive is the default maximum number of the idle keepalive connections to the backends of
+	// a rule that each NGINX worker keeps open.
+	DefaultUpstreamKeepalive = 16
+	// DefaultUpstreamZoneSize is the default size of the shared memory zone of an upstream.
+	DefaultUpstreamZoneSize = "64k"
+)
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Generator
 
 // Generator generates NGINX configuration.
+// The Warnings report the issues of the individual resources, which don't prevent the generation of the rest of
+// the configuration. The error reports a failure that prevents the generation of the whole configuration, in which
+// case the configuration must not be used.
+// ctx bounds the resolution of the backends: the backends that are not resolved before ctx is done are treated as
+// unresolved.
 type Generator interface {
 	// Generate generates NGINX configuration from internal representation.
-	Generate(configuration state.Configuration) ([]byte, Warnings)
+	Generate(ctx context.Context, configuration state.Configuration) ([]byte, Warnings, error)
+	// GenerateFiles generates the same NGINX configuration as Generate, but split into a main file and a file per
+	// server, which the main file includes. The keys of the returned map are the file names relative to
+	// the http configuration folder. The main file is HTTPServersMainFile.
+	GenerateFiles(ctx context.Context, configuration state.Configuration) (map[string][]byte, Warnings, error)
+	// GenerateAndCompare generates NGINX configuration like Generate and compares it with prev, the previously
+	// generated configuration. changed is false if the configurations are identical.
+	GenerateAndCompare(
+		ctx context.Context,
+		prev []byte,
+		configuration state.Configuration,
+	) (cfg []byte, changed bool, warnings Warnings, err error)
+	// GenerateStream generates NGINX stream configuration from internal representation.
+	// Unlike the configuration produced by Generate, it must be included in the stream context.
+	GenerateStream(ctx context.Context, configuration state.Configuration) ([]byte, Warnings, error)
+	// GenerateMain generates the NGINX configuration of the main context, including the events block.
+	// Unlike the configuration produced by Generate, it must be included in the main context.
+	GenerateMain() ([]byte, error)
 }
 
 // GeneratorImpl is an implementation of Generator
 type GeneratorImpl struct {
-	executor     *templateExecutor
-	serviceStore state.ServiceStore
+	executor        *templateExecutor
+	serviceStore    state.ServiceStore
+	requestIDHeader string
+	metrics         *metrics.ControllerMetrics
+	njsAvailable    bool
+	defaultBackend  *defaultBackend
+	// noBackendsResponse is returned when none of the backends of a rule can be resolved.
+	// If nil, the requests are proxied to the fallback upstream, which responds with 502.
+	noBackendsResponse *returnVal
+	forwardedHeaders   bool
+	trustedProxies     []string
+	statusOverrides    statusOverrides
+	sslCertificateMap  bool
+	// backendCACertificate is the path of the CA certificate that verifies the certificates of the HTTPS backends.
+	backendCACertificate string
+	// proxyTimeouts are the default proxy timeouts, which the HTTPRoutes can override.
+	proxyTimeouts proxyTimeouts
+	// backendResolvers are the resolvers of the supported backend kinds other than Service.
+	backendResolvers map[state.BackendKind]state.BackendResolver
+	// httpPort and httpsPort are the ports NGINX listens on for HTTP and HTTPS.
+	httpPort  int32
+	httpsPort int32
+	// securityHeaders enables the preset of the security headers in the responses.
+	securityHeaders bool
+	// ipFamily is the IP family of the connections NGINX accepts: IPFamilyIPv4, IPFamilyIPv6 or IPFamilyDual.
+	ipFamily string
+	// defaultType is the default content type of the responses. Empty means the NGINX default.
+	defaultType string
+	// resolveTimeout bounds the time of the resolution of the backends of a server. The backends that are not
+	// resolved in time are treated as unresolved.
+	resolveTimeout time.Duration
+	// workerProcesses is the number of the NGINX worker processes or auto.
+	workerProcesses string
+	// workerConnections is the maximum number of the connections of an NGINX worker process.
+	workerConnections int
+	// upstreamKeepalive is the maximum number of the idle keepalive connections to the backends of a rule that each
+	// NGINX worker keeps open. Zero means the connections are not kept alive.
+	upstreamKeepalive int
+	// upstreamZoneSize is the size of the shared memory zone of an upstream. Empty means the upstreams don't have
+	// zones.
+	upstreamZoneSize string
+	// resolver is the addresses of the DNS servers that resolve the hostnames of the backends at runtime.
+	resolver []string
+	// normalizePaths enables the normalization of the paths of the matches and the merging of the duplicate slashes
+	// of the request URIs.
+	normalizePaths bool
+	// defaultCertificate is the certificate of the default SSL server. If nil, the default SSL server rejects
+	// the handshakes.
+	defaultCertificate *ssl
+	// healthCheck is the health check endpoint of NGINX. If nil, NGINX doesn't serve the endpoint.
+	healthCheck *healthCheck
+	// ocspTrustedCertificate is the path of the CA certificate that verifies the OCSP responses of the certificates
+	// of the SSL servers. Empty means OCSP stapling is disabled.
+	ocspTrustedCertificate string
+	// hsts is the value of the Strict-Transport-Security header of the SSL servers. Empty means the header is only
+	// added as a part of the security headers.
+	hsts string
+	// serverTokens enables the version of NGINX in the Server header and the error pages.
+	serverTokens bool
+	// clientMaxBodySize is the maximum size of the request bodies. Empty means the NGINX default.
+	clientMaxBodySize string
+	// requestTooLargeResponse is returned to the requests whose bodies exceed the maximum size.
+	// If nil, NGINX responds with its default page.
+	requestTooLargeResponse *returnVal
+	// sourceComments enables the comments that link the servers and locations to the HTTPRoutes.
+	sourceComments bool
+	// allowSnippets enables the snippets of NGINX directives in the annotations of the HTTPRoutes.
+	allowSnippets bool
+}
+
+// healthCheck is the health check endpoint of NGINX.
+type healthCheck struct {
+	port      int32
+	path      string
+	accessLog bool
+}
+
+// defaultBackend is the Service that receives the requests that don't match any server.
+type defaultBackend struct {
+	nsname types.NamespacedName
+	port   int32
+}
+
+// GeneratorOption is a function that modifies the configuration of the GeneratorImpl.
+type GeneratorOption func(*GeneratorImpl)
+
+// WithRequestIDHeader sets the name of the header that carries the ID of a request.
+// If a client request doesn't include the header, NGINX generates the ID.
+// The ID is passed to the backends in the header and written to the access log.
+func WithRequestIDHeader(header string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.requestIDHeader = header
+	}
+}
+
+// WithMetrics sets the metrics that the GeneratorImpl records.
+func WithMetrics(m *metrics.ControllerMetrics) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.metrics = m
+	}
+}
+
+// WithNJSAvailable sets whether the njs module, which evaluates the HTTPRoute matches, is available in NGINX.
+// If it is not available, the GeneratorImpl doesn't generate the configuration that depends on the module and
+// evaluates the matches it can with the directives of the rewrite module instead.
+func WithNJSAvailable(available bool) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.njsAvailable = available
+	}
+}
+
+// WithDefaultBackend sets the Service that receives the requests that don't match the hostname of any server.
+// Without a default backend, NGINX responds to such requests with 404.
+func WithDefaultBackend(nsname types.NamespacedName, port int32) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.defaultBackend = &defaultBackend{
+			nsname: nsname,
+			port:   port,
+		}
+	}
+}
+
+// WithNoBackendsResponse sets the response that NGINX returns when none of the backends of a rule can be resolved.
+// The code is expected to be 502 or 503. Without the option, NGINX responds with DefaultNoBackendsResponseCode and
+// an empty body.
+// Like in the NGINX return directive, the NGINX variables in the message are evaluated.
+func WithNoBackendsResponse(code int, message string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.noBackendsResponse = &returnVal{
+			Code:        statusCode(code),
+			Body:        message,
+			ContentType: contentTypeText,
+		}
+	}
+}
+
+// WithForwardedHeaders sets whether NGINX passes the X-Forwarded-For, X-Forwarded-Proto and X-Real-IP headers
+// to the backends, so that they know the original client address and scheme. The headers are passed by default.
+func WithForwardedHeaders(enabled bool) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.forwardedHeaders = enabled
+	}
+}
+
+// WithTrustedProxies sets the CIDRs of the trusted proxies, for example, a cloud load balancer in front of NGINX.
+// For the requests from the trusted proxies, NGINX takes the client address from the X-Forwarded-For header.
+func WithTrustedProxies(cidrs []string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.trustedProxies = cidrs
+	}
+}
+
+// WithStatusOverrides sets the translation of the status codes of the backend responses to the status codes of
+// the responses to the clients. For example, {418: 500} makes NGINX respond with 500 when a backend responds with 418.
+// The backend status codes must be 300 or greater.
+func WithStatusOverrides(overrides map[int]int) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.statusOverrides = generateStatusOverrides(overrides)
+	}
+}
+
+// WithSSLCertificateMap sets whether NGINX selects the certificates of the SSL servers by the SNI of a connection
+// using a map, rather than loading the certificate of every server when the configuration is loaded.
+// This speeds up the reloads for the Gateways with many certificates at the cost of loading a certificate
+// during every SSL handshake. The map requires NGINX 1.15.9 or later.
+func WithSSLCertificateMap(enabled bool) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.sslCertificateMap = enabled
+	}
+}
+
+// WithBackendCACertificate sets the path of the CA certificate that verifies the certificates of the HTTPS backends
+// of the HTTPRoutes that enable the verification.
+func WithBackendCACertificate(path string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.backendCACertificate = path
+	}
 }
 
-// NewGeneratorImpl creates a new GeneratorImpl.
-func NewGeneratorImpl(serviceStore state.ServiceStore) *GeneratorImpl {
-	return &GeneratorImpl{
-		executor:     newTemplateExecutor(),
-		serviceStore: serviceStore,
+// WithProxyTimeouts sets the default timeouts of proxying requests to the backends: the timeout for establishing
+// a connection, the timeout between two successive reads of a response and the timeout between two successive
+// writes of a request. Zero means the NGINX default. An HTTPRoute can override every timeout with an annotation.
+func WithProxyTimeouts(connect, read, send time.Duration) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.proxyTimeouts = proxyTimeouts{
+			Connect: connect,
+			Read:    read,
+			Send:    send,
+		}
+	}
+}
+
+// WithListenPorts sets the ports NGINX listens on for HTTP and HTTPS, for example, 8080 and 8443, when NGINX runs
+// as a non-root user or its ports are remapped. The ports of the Gateway listeners are not changed.
+// Without the option, NGINX listens on DefaultHTTPPort and DefaultHTTPSPort.
+func WithListenPorts(httpPort, httpsPort int32) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.httpPort = httpPort
+		g.httpsPort = httpsPort
+	}
+}
+
+// WithSecurityHeaders enables the preset of the security headers, which NGINX adds to all responses of the servers
+// for the HTTPRoutes: X-Content-Type-Options, X-Frame-Options and Referrer-Policy. The servers with SSL additionally
+// add Strict-Transport-Security (HSTS), because browsers ignore it in the responses over HTTP. The HSTS header
+// has a max-age of DefaultHSTSMaxAge unless WithHSTS configures it.
+func WithSecurityHeaders(enable bool) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.securityHeaders = enable
+	}
+}
+
+// WithIPFamily sets the IP family of the connections NGINX accepts: IPFamilyIPv4, IPFamilyIPv6 or IPFamilyDual.
+// For IPFamilyDual, every server listens on both the IPv4 and IPv6 addresses.
+// Without the option, NGINX accepts only IPv4 connections.
+func WithIPFamily(family string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.ipFamily = family
+	}
+}
+
+// FilterAddressesByIPFamily returns the IP addresses of the IP family, which NGINX can listen on.
+// The addresses that are not valid IP addresses are dropped.
+func FilterAddressesByIPFamily(addresses []string, family string) []string {
+	var filtered []string
+
+	for _, a := range addresses {
+		ip := net.ParseIP(a)
+		if ip == nil {
+			continue
+		}
+
+		isIPv4 := ip.To4() != nil
+
+		var ofFamily bool
+		switch family {
+		case IPFamilyIPv6:
+			ofFamily = !isIPv4
+		case IPFamilyDual:
+			ofFamily = true
+		default:
+			ofFamily = isIPv4
+		}
+
+		if ofFamily {
+			filtered = append(filtered, a)
+		}
+	}
+
+	return filtered
+}
+
+// WithDefaultType sets the default content type (default_type) of the responses in the http context. The proxied
+// responses keep the content type of the backends, and the fixed responses, like the 404 responses, always set
+// their own content type, so the option only affects the responses without a content type.
+func WithDefaultType(contentType string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.defaultType = contentType
+	}
+}
+
+// WithClientMaxBodySize sets the maximum size of the request bodies, for example, 10m. NGINX responds with 413 to
+// the requests with larger bodies. 0 disables the check. Without the option, the NGINX default of 1m is used.
+func WithClientMaxBodySize(size string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.clientMaxBodySize = size
+	}
+}
+
+// WithRequestTooLargeResponse sets the response that NGINX returns with 413 to the requests whose bodies exceed
+// the maximum size, for example, a JSON body with the application/json content type.
+// Without the option, NGINX responds with its default HTML page.
+func WithRequestTooLargeResponse(contentType string, body string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.requestTooLargeResponse = &returnVal{
+			Code:        statusRequestEntityTooLarge,
+			Body:        body,
+			ContentType: contentType,
+		}
+	}
+}
+
+// WithDefaultCertificate sets the certificate and key, for example, self-signed, of the default SSL server, so that
+// the HTTPS requests for the hostnames without a server complete the handshake and get a 404 response.
+// Without the option, the default SSL server rejects their handshakes.
+func WithDefaultCertificate(certPath, keyPath string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		if certPath == "" || keyPath == "" {
+			return
+		}
+
+		g.defaultCertificate = &ssl{
+			Certificate:    certPath,
+			CertificateKey: keyPath,
+		}
+	}
+}
+
+// WithPathNormalization enables the normalization of the paths, so that, for example, a PathPrefix /foo matches
+// the requests for //foo. NGINX merges the duplicate slashes of the request URIs (merge_slashes) and decodes their
+// percent-encoded characters before it matches the locations, so the paths of the matches are normalized the same
+// way.
+func WithPathNormalization(enabled bool) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.normalizePaths = enabled
+	}
+}
+
+// WithHealthCheck makes NGINX serve a health check endpoint, for example, for the readiness probe of its container.
+// NGINX responds with 200 to the requests for the path on the port without proxying them to any backend.
+// The endpoint is served by a dedicated server, so it never collides with the routes. The port must differ from
+// the ports of the listeners of the Gateway. Unless accessLog is true, the requests are not written to the access log.
+func WithHealthCheck(port int32, path string, accessLog bool) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.healthCheck = &healthCheck{
+			port:      port,
+			path:      path,
+			accessLog: accessLog,
+		}
+	}
+}
+
+// WithOCSPStapling enables OCSP stapling for the SSL servers: NGINX attaches the OCSP responses about the revocation
+// status of their certificates to the handshakes, so that the clients don't query the OCSP responders themselves.
+// The responses are verified with the CA certificate of the path. NGINX resolves the OCSP responders with
+// the resolver (see WithResolver). Stapling doesn't apply to the certificates selected with the map
+// (see WithSSLCertificateMap). An empty path disables stapling.
+func WithOCSPStapling(trustedCertificatePath string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.ocspTrustedCertificate = trustedCertificatePath
+	}
+}
+
+// WithHSTS makes the SSL servers for the HTTPRoutes add the Strict-Transport-Security (HSTS) header to all responses,
+// so that the browsers only access the host over HTTPS for maxAge seconds. includeSubDomains extends the policy to
+// the subdomains of the host, and preload allows the host to be included in the HSTS preload lists of the browsers.
+// The plain HTTP servers never add the header, because browsers ignore it in the responses over HTTP.
+func WithHSTS(maxAge int, includeSubDomains, preload bool) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.hsts = generateHSTS(maxAge, includeSubDomains, preload)
+	}
+}
+
+// generateHSTS generates the value of the Strict-Transport-Security header.
+func generateHSTS(maxAge int, includeSubDomains, preload bool) string {
+	hsts := fmt.Sprintf("max-age=%d", maxAge)
+	if includeSubDomains {
+		hsts += "; includeSubDomains"
+	}
+	if preload {
+		hsts += "; preload"
+	}
+
+	return hsts
+}
+
+// WithServerTokens sets whether NGINX advertises its version in the Server header of the responses and on the error
+// pages (server_tokens). The version is hidden by default.
+func WithServerTokens(enabled bool) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.serverTokens = enabled
+	}
+}
+
+// WithSourceComments sets whether the servers and locations of the HTTPRoutes carry the comments that link them to
+// the HTTPRoutes, and the locations to the rules and matches, that produced them, for example,
+// "# source: HTTPRoute test/route1 rule 0 match 2". The comments are disabled by default.
+func WithSourceComments(enabled bool) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.sourceComments = enabled
+	}
+}
+
+// WithSnippets sets whether the HTTPRoutes can add NGINX directives to their locations and servers with
+// the snippets annotations. The snippets bypass the validation of the HTTPRoutes, so they are disabled by default:
+// the annotations are ignored and reported as warnings.
+func WithSnippets(allowed bool) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.allowSnippets = allowed
+	}
+}
+
+// WithResolveTimeout sets the time limit of the resolution of the backends of a server, so that a slow ServiceStore
+// or BackendResolver doesn't stall the generation of the configuration. The backends that are not resolved in time
+// are treated as unresolved: the requests are proxied to the fallback upstream, which responds with 502.
+// Without the option, DefaultResolveTimeout is used.
+func WithResolveTimeout(timeout time.Duration) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.resolveTimeout = timeout
+	}
+}
+
+// WithWorkerProcesses sets the number of the NGINX worker processes (worker_processes): a positive number or auto,
+// which starts one worker process per CPU core.
+// Without the option, DefaultWorkerProcesses is used.
+func WithWorkerProcesses(workerProcesses string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.workerProcesses = workerProcesses
+	}
+}
+
+// WithWorkerConnections sets the maximum number of the connections of an NGINX worker process (worker_connections),
+// which includes both the client connections and the connections to the backends.
+// Without the option, DefaultWorkerConnections is used.
+func WithWorkerConnections(workerConnections int) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.workerConnections = workerConnections
+	}
+}
+
+// WithUpstreamKeepalive sets the maximum number of the idle keepalive connections to the backends of a rule
+// (keepalive) that each NGINX worker keeps open in the upstream of the rule. Zero disables the keepalive connections.
+// Without the option, DefaultUpstreamKeepalive is used.
+func WithUpstreamKeepalive(keepalive int) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.upstreamKeepalive = keepalive
+	}
+}
+
+// WithUpstreamZoneSize sets the size of the shared memory zone (zone) of every upstream, for example, 128k.
+// The zone keeps the state of the upstream shared among the NGINX workers, which some load balancing methods
+// require. The name of the zone is the name of the upstream. Empty disables the zones.
+// Without the option, DefaultUpstreamZoneSize is used.
+func WithUpstreamZoneSize(size string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.upstreamZoneSize = size
+	}
+}
+
+// WithTemplateOverrides redefines the server, location and upstream templates of the http servers configuration
+// with the {{ define }} actions of the overrides, so that advanced users can customize the generated configuration.
+// The data of the server template is a serverData, the data of the location template is a locationData and the data
+// of the upstream template is an upstream. The templates that the overrides don't define keep their defaults.
+// The overrides must be validated with ValidateTemplateOverrides: the option panics if they are invalid.
+func WithTemplateOverrides(overrides string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		e, err := newTemplateExecutorWithOverrides(overrides)
+		if err != nil {
+			panic(fmt.Errorf("invalid template overrides: %w", err))
+		}
+		g.executor = e
+	}
+}
+
+// WithBackendResolvers adds the support of the backends of the kinds of the resolvers, for example, the ServiceImports
+// of multi-cluster Services, to the backendRefs of the HTTPRoutes. The resolvers resolve the addresses of the backends.
+// The Services of the core group are always supported and resolved by the ServiceStore.
+// The resolvers must be the same as the BackendResolvers of the ChangeProcessor, so that the backendRefs the
+// ChangeProcessor accepts are the ones the Generator resolves.
+func WithBackendResolvers(resolvers map[state.BackendKind]state.BackendResolver) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.backendResolvers = resolvers
+	}
+}
+
+// WithResolver sets the addresses of the DNS servers NGINX uses to resolve the hostnames of the backends at runtime,
+// for example, of the ExternalName Services. An address is an IP address with an optional port.
+// Without the option, the backends with hostnames cannot be resolved.
+func WithResolver(addresses []string) GeneratorOption {
+	return func(g *GeneratorImpl) {
+		g.resolver = make([]string, 0, len(addresses))
+		for _, a := range addresses {
+			g.resolver = append(g.resolver, generateResolverAddress(a))
+		}
+	}
+}
+
+// generateResolverAddress encloses an IPv6 address in square brackets, as NGINX requires.
+func generateResolverAddress(address string) string {
+	if ip := net.ParseIP(address); ip != nil && ip.To4() == nil {
+		return "[" + address + "]"
+	}
+
+	return address
+}
+
+// NewGeneratorImpl creates a new GeneratorImpl.
+func NewGeneratorImpl(serviceStore state.ServiceStore, options ...GeneratorOption) *GeneratorImpl {
+	g := &GeneratorImpl{
+		executor:          newTemplateExecutor(),
+		serviceStore:      serviceStore,
+		requestIDHeader:   DefaultRequestIDHeader,
+		metrics:           metrics.NewControllerMetrics(),
+		njsAvailable:      true,
+		forwardedHeaders:  true,
+		httpPort:          DefaultHTTPPort,
+		httpsPort:         DefaultHTTPSPort,
+		ipFamily:          IPFamilyIPv4,
+		resolveTimeout:    DefaultResolveTimeout,
+		workerProcesses:   DefaultWorkerProcesses,
+		workerConnections: DefaultWorkerConnections,
+		upstreamKeepalive: DefaultUpstreamKeepalive,
+		upstreamZoneSize:  DefaultUpstreamZoneSize,
+	}
+
+	for _, o := range options {
+		o(g)
+	}
+
+	g.serviceStore = &instrumentedServiceStore{
+		ServiceStore:       g.serviceStore,
+		resolutionFailures: g.metrics.ResolutionFailures,
+	}
+
+	return g
+}
+
+// instrumentedServiceStore is a ServiceStore that counts failed resolutions.
+type instrumentedServiceStore struct {
+	state.ServiceStore
+	resolutionFailures prometheus.Counter
+}
+
+func (s *instrumentedServiceStore) Resolve(
+	ctx context.Context,
+	nsname types.NamespacedName,
+	port int32,
+) (string, error) {
+	address, err := s.ServiceStore.Resolve(ctx, nsname, port)
+	if err != nil {
+		s.resolutionFailures.Inc()
+	}
+
+	return address, err
+}
+
+func (g *GeneratorImpl) Generate(ctx context.Context, conf state.Configuration) ([]byte, Warnings, error) {
+	defer g.observeGenerationDuration(time.Now())
+
+	servers, warnings := g.generateHTTPServers(ctx, conf)
+
+	cfg, err := g.executor.ExecuteForHTTPServers(servers)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	return cfg, warnings, nil
+}
+
+func (g *GeneratorImpl) GenerateAndCompare(
+	ctx context.Context,
+	prev []byte,
+	conf state.Configuration,
+) ([]byte, bool, Warnings, error) {
+	cfg, warnings, err := g.Generate(ctx, conf)
+	if err != nil {
+		return nil, false, warnings, err
+	}
+
+	// Generate is deterministic: the same Configuration results in the same bytes.
+	return cfg, !bytes.Equal(prev, cfg), warnings, nil
+}
+
+func (g *GeneratorImpl) GenerateFiles(
+	ctx context.Context,
+	conf state.Configuration,
+) (map[string][]byte, Warnings, error) {
+	defer g.observeGenerationDuration(time.Now())
+
+	servers, warnings := g.generateHTTPServers(ctx, conf)
+
+	// main file + a file per server
+	files := make(map[string][]byte, len(servers.Servers)+1)
+
+	common, err := g.executor.ExecuteForHTTPCommon(servers)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	var main bytes.Buffer
+	main.Write(common)
+
+	for idx, s := range servers.Servers {
+		name := getServerFileName(idx, s)
+
+		file, err := g.executor.ExecuteForHTTPServerBlocks(httpServers{
+			RequestID:        servers.RequestID,
+			ForwardedHeaders: servers.ForwardedHeaders,
+			StatusOverrides:  servers.StatusOverrides,
+			HTTPListen:       servers.HTTPListen,
+			HTTPSListen:      servers.HTTPSListen,
+			SecurityHeaders:  servers.SecurityHeaders,
+			HSTS:             servers.HSTS,
+			OCSPStapling:     servers.OCSPStapling,
+			RequestTooLarge:  servers.RequestTooLarge,
+			Servers:          []server{s},
+		})
+		if err != nil {
+			return nil, warnings, err
+		}
+
+		files[name] = file
+
+		fmt.Fprintf(&main, "include %s/%s;\n", httpConfigFolder, name)
+	}
+
+	files[HTTPServersMainFile] = main.Bytes()
+
+	return files, warnings, nil
+}
+
+func (g *GeneratorImpl) observeGenerationDuration(start time.Time) {
+	g.metrics.GenerationDuration.Observe(time.Since(start).Seconds())
+}
+
+func (g *GeneratorImpl) generateHTTPServers(ctx context.Context, conf state.Configuration) (httpServers, Warnings) {
+	warnings := newWarnings()
+
+	confServers := append(conf.HTTPServers, conf.SSLServers...)
+
+	servers := httpServers{
+		RequestID:         generateRequestID(g.requestIDHeader),
+		ForwardedHeaders:  g.forwardedHeaders,
+		TrustedProxies:    g.trustedProxies,
+		StatusOverrides:   g.statusOverrides,
+		DefaultType:       g.defaultType,
+		HTTPListen:        generateListenAddresses(g.httpPort, g.ipFamily, conf.ListenAddresses),
+		HTTPSListen:       generateListenAddresses(g.httpsPort, g.ipFamily, conf.ListenAddresses),
+		SecurityHeaders:   g.securityHeaders,
+		HSTS:              g.hsts,
+		OCSPStapling:      generateOCSPStapling(g.ocspTrustedCertificate),
+		MergeSlashes:      g.normalizePaths,
+		ServerTokens:      g.serverTokens,
+		ClientMaxBodySize: g.clientMaxBodySize,
+		RequestTooLarge:   g.requestTooLargeResponse,
+		Upstreams:         []upstream{generateFallbackUpstream()},
+		// capacity is all the conf servers + redirect servers + fallback, health check, default ssl & http servers
+		Servers: make([]server, 0, len(confServers)+len(conf.HTTPRedirects)+len(conf.SSLRedirects)+4),
+	}
+
+	if servers.HSTS == "" && g.securityHeaders {
+		servers.HSTS = generateHSTS(DefaultHSTSMaxAge, false, false)
+	}
+
+	servers.Servers = append(servers.Servers, generateFallbackServer())
+
+	if g.healthCheck != nil {
+		servers.Servers = append(servers.Servers, g.generateHealthCheckServer())
+	}
+
+	if len(conf.HTTPServers) > 0 {
+		defaultHTTPServer := g.generateDefaultHTTPServer(ctx)
+
+		servers.Servers = append(servers.Servers, defaultHTTPServer)
+	}
+
+	if len(conf.SSLServers) > 0 {
+		defaultSSLServer := g.generateDefaultSSLServer()
+
+		servers.Servers = append(servers.Servers, defaultSSLServer)
+	}
+
+	if g.sslCertificateMap {
+		sslServers := make([]state.VirtualServer, 0, len(conf.SSLServers)+len(conf.SSLRedirects))
+		sslServers = append(sslServers, conf.SSLServers...)
+		for _, r := range conf.SSLRedirects {
+			sslServers = append(sslServers, state.VirtualServer{Hostname: r.Hostname, SSL: r.SSL})
+		}
+
+		servers.SSLCertificates = generateSSLCertificates(sslServers)
+	}
+
+	// the same rule can be used by multiple servers, so its upstream is only added once.
+	upstreamNames := make(map[string]struct{})
+	// the same backend can be referenced by multiple rules, so it is only resolved once. The backends resolved
+	// while the configuration was built are not resolved again.
+	memo := state.NewBackendResolutions(conf.BackendAddresses)
+
+	for _, s := range confServers {
+		cfg, upstreams, warns := g.generate(ctx, s, memo)
+
+		servers.Servers = append(servers.Servers, cfg)
+		warnings.Add(warns)
+
+		for _, u := range upstreams {
+			if _, exist := upstreamNames[u.Name]; exist {
+				continue
+			}
+			upstreamNames[u.Name] = struct{}{}
+
+			servers.Upstreams = append(servers.Upstreams, u)
+		}
+	}
+
+	servers.BackendSplits = generateBackendSplits(servers.Servers)
+
+	for _, r := range append(conf.HTTPRedirects, conf.SSLRedirects...) {
+		servers.Servers = append(servers.Servers, g.generateRedirectServer(r))
+	}
+
+	// the OCSP responders are resolved at runtime too
+	if usesHostnames(servers.Servers) || (servers.OCSPStapling != nil && len(conf.SSLServers) > 0) {
+		servers.Resolver = g.resolver
+	}
+
+	g.metrics.GeneratedServers.Set(float64(len(servers.Servers)))
+
+	return servers, warnings
+}
+
+// generateListenAddresses generates the addresses for the port of the IP family.
+// An address without a host makes NGINX accept only IPv4 connections, while [::] accepts only IPv6 connections,
+// because NGINX enables ipv6only by default.
+// If the Gateway requests specific IP addresses, NGINX only listens on those addresses. The ChangeProcessor only
+// accepts the addresses of the IP family (see FilterAddressesByIPFamily).
+func generateListenAddresses(port int32, family string, ips []string) []string {
+	if len(ips) > 0 {
+		addresses := make([]string, 0, len(ips))
+		for _, ip := range ips {
+			addresses = append(addresses, net.JoinHostPort(ip, strconv.Itoa(int(port))))
+		}
+
+		return addresses
+	}
+
+	ipv4 := strconv.Itoa(int(port))
+	ipv6 := "[::]:" + ipv4
+
+	switch family {
+	case IPFamilyIPv6:
+		return []string{ipv6}
+	case IPFamilyDual:
+		return []string{ipv4, ipv6}
+	default:
+		return []string{ipv4}
+	}
+}
+
+func (g *GeneratorImpl) generateSSL(s *state.SSL) *ssl {
+	if s == nil {
+		return nil
+	}
+
+	if g.sslCertificateMap {
+		return &ssl{
+			Certificate:    sslCertificateVar,
+			CertificateKey: sslCertificateKeyVar,
+		}
+	}
+
+	return &ssl{
+		Certificate:    s.CertificatePath,
+		CertificateKey: s.KeyPath,
+	}
+}
+
+// generateRedirectServer generates the server that redirects the requests for the hostname of the www
+// normalization redirect to its target.
+func (g *GeneratorImpl) generateRedirectServer(r state.WWWRedirect) server {
+	return server{
+		ServerName: r.Hostname,
+		SSL:        g.generateSSL(r.SSL),
+		Locations: []location{
+			{
+				Path: "/",
+				Return: &returnVal{
+					Code:        statusMovedPermanently,
+					Body:        fmt.Sprintf("$scheme://%s$request_uri", r.Target),
+					ContentType: contentTypeHTML,
+				},
+			},
+		},
+	}
+}
+
+// generateHTTPSRedirectLocation generates the location that redirects the requests to the same URI over HTTPS.
+// The port is omitted if NGINX listens for HTTPS on the default port.
+func (g *GeneratorImpl) generateHTTPSRedirectLocation() location {
+	host := "$host"
+	if g.httpsPort != DefaultHTTPSPort {
+		host = fmt.Sprintf("$host:%d", g.httpsPort)
+	}
+
+	return location{
+		Path: "/",
+		Return: &returnVal{
+			Code:        statusMovedPermanently,
+			Body:        fmt.Sprintf("https://%s$request_uri", host),
+			ContentType: contentTypeHTML,
+		},
+	}
+}
+
+// generateSSLCertificates generates the maps of the hostnames of the SSL servers to their certificates and keys.
+func generateSSLCertificates(sslServers []state.VirtualServer) []sslCertificate {
+	certs := make([]sslCertificate, 0, len(sslServers))
+	hostnames := make(map[string]struct{}, len(sslServers))
+
+	for _, s := range sslServers {
+		if s.SSL == nil {
+			continue
+		}
+
+		// a hostname can only be used once in the map
+		if _, exist := hostnames[s.Hostname]; exist {
+			continue
+		}
+		hostnames[s.Hostname] = struct{}{}
+
+		certs = append(certs, sslCertificate{
+			Hostname: s.Hostname,
+			Path:     s.SSL.CertificatePath,
+			KeyPath:  s.SSL.KeyPath,
+		})
+	}
+
+	return certs
+}
+
+// getServerFileName returns the name of the file of the server generated by GenerateFiles.
+// The name starts with the index of the server, so that the names are unique and keep the order of the servers.
+func getServerFileName(idx int, s server) string {
+	var name string
+
+	switch {
+	case s.IsFallback:
+		name = "fallback"
+	case s.IsDefaultHTTP:
+		name = "default-http"
+	case s.IsDefaultSSL:
+		name = "default-ssl"
+	case s.HealthCheck != nil:
+		name = "health-check"
+	default:
+		name = strings.ReplaceAll(s.ServerName, "*", "_")
+		if s.SSL != nil {
+			name += "-ssl"
+		}
+	}
+
+	return fmt.Sprintf("%s/%03d-%s.conf", httpServerFilesFolder, idx, name)
+}
+
+func generateRequestID(header string) requestID {
+	return requestID{
+		Header:    header,
+		HeaderVar: "$http_" + strings.ToLower(strings.ReplaceAll(header, "-", "_")),
+	}
+}
+
+func (g *GeneratorImpl) GenerateStream(ctx context.Context, conf state.Configuration) ([]byte, Warnings, error) {
+	warnings := newWarnings()
+
+	servers := streamServers{
+		Servers: make([]streamServer, 0, len(conf.TCPServers)),
+	}
+
+	memo := state.NewBackendResolutions(conf.BackendAddresses)
+
+	for _, s := range conf.TCPServers {
+		warnings.Add(warnIgnoredStreamBackendRefs(s.Source))
+
+		cfg, err := g.generateStreamServer(ctx, s, memo)
+		if err != nil {
+			// unlike http, there is no way to respond with an error to a TCP client, so we skip the server.
+			warnings.AddWarning(s.Source, err.Error())
+			continue
+		}
+
+		servers.Servers = append(servers.Servers, cfg)
+	}
+
+	cfg, err := g.executor.ExecuteForStreamServers(servers)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	return cfg, warnings, nil
+}
+
+func (g *GeneratorImpl) GenerateMain() ([]byte, error) {
+	return g.executor.ExecuteForMain(mainConfig{
+		WorkerProcesses:   g.workerProcesses,
+		WorkerConnections: g.workerConnections,
+	})
+}
+
+func (g *GeneratorImpl) generateStreamServer(
+	ctx context.Context,
+	tcpServer state.TCPServer,
+	memo state.BackendResolutions,
+) (streamServer, error) {
+	// FIXME(pleshakov): for now, we only support a single rule with a single backend reference
+	var refs []v1beta1.BackendRef
+	if len(tcpServer.Source.Spec.Rules) > 0 {
+		refs = tcpServer.Source.Spec.Rules[0].BackendRefs
+	}
+
+	if len(refs) == 0 {
+		return streamServer{}, errors.New("empty backend refs")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.resolveTimeout)
+	defer cancel()
+
+	// FIXME(pleshakov): for now, we only support Services as the backends of TCPRoutes
+	address, err := resolveBackendRef(ctx, memo, refs[0], tcpServer.Source.Namespace, g.serviceStore, nil)
+	if err != nil {
+		return streamServer{}, err
+	}
+
+	// NGINX resolves the hostname of a stream server only when it loads the configuration, so a hostname that
+	// cannot be resolved would fail the reload
+	if isHostnameAddress(address) {
+		return streamServer{}, fmt.Errorf("the backend %s has a hostname, which is not supported for TCPRoutes", address)
 	}
+
+	return streamServer{
+		Listen:    strconv.Itoa(int(tcpServer.Port)),
+		ProxyPass: address,
+	}, nil
 }
 
-func (g *GeneratorImpl) Generate(conf state.Configuration) ([]byte, Warnings) {
+// warnIgnoredStreamBackendRefs returns a warning for every backendRef of the TCPRoute except the first backendRef
+// of the first rule, because generateStreamServer ignores them.
+func warnIgnoredStreamBackendRefs(tr *v1alpha2.TCPRoute) Warnings {
 	warnings := newWarnings()
 
-	confServers := append(conf.HTTPServers, conf.SSLServers...)
+	for ruleIdx, rule := range tr.Spec.Rules {
+		for refIdx := range rule.BackendRefs {
+			if ruleIdx == 0 && refIdx == 0 {
+				continue
+			}
 
-	servers := httpServers{
-		// capacity is all the conf servers + default ssl & http servers
-		Servers: make([]server, 0, len(confServers)+2),
+			warnings.AddWarningf(
+				tr,
+				"only the first backendRef of the first rule is supported; backendRef %d of rule %d is ignored",
+				refIdx,
+				ruleIdx,
+			)
+		}
 	}
 
-	if len(conf.HTTPServers) > 0 {
-		defaultHTTPServer := generateDefaultHTTPServer()
+	return warnings
+}
 
-		servers.Servers = append(servers.Servers, defaultHTTPServer)
-	}
+// generateStatusOverrides generates the status overrides sorted by the backend status code, so that
+// the configuration is the same for the same overrides.
+func generateStatusOverrides(overrides map[int]int) statusOverrides {
+	var result statusOverrides
 
-	if len(conf.SSLServers) > 0 {
-		defaultSSLServer := generateDefaultSSLServer()
+	codes := make(map[statusCode]struct{})
 
-		servers.Servers = append(servers.Servers, defaultSSLServer)
+	for from, to := range overrides {
+		result.Overrides = append(result.Overrides, statusOverride{
+			From: statusCode(from),
+			To:   statusCode(to),
+		})
+
+		if _, exist := codes[statusCode(to)]; !exist {
+			codes[statusCode(to)] = struct{}{}
+			result.Codes = append(result.Codes, statusCode(to))
+		}
 	}
 
-	for _, s := range confServers {
-		cfg, warns := generate(s, g.serviceStore)
+	sort.Slice(result.Overrides, func(i, j int) bool {
+		return result.Overrides[i].From < result.Overrides[j].From
+	})
+	sort.Slice(result.Codes, func(i, j int) bool {
+		return result.Codes[i] < result.Codes[j]
+	})
 
-		servers.Servers = append(servers.Servers, cfg)
-		warnings.Add(warns)
+	return result
+}
+
+func generateFallbackUpstream() upstream {
+	return upstream{
+		Name:    fallbackUpstreamName,
+		Servers: []upstreamServer{{Address: nginx502Server}},
 	}
+}
 
-	return g.executor.ExecuteForHTTPServers(servers), warnings
+func generateFallbackServer() server {
+	return server{IsFallback: true, Listen: nginx502Server}
 }
 
-func generateDefaultSSLServer() server {
-	return server{IsDefaultSSL: true}
+// generateHealthCheckServer generates the server of the health check endpoint. The server listens on all addresses,
+// even if the Gateway requests specific addresses, so that the endpoint is reachable on the address of the pod.
+func (g *GeneratorImpl) generateHealthCheckServer() server {
+	return server{
+		HealthCheck: &healthCheckServer{
+			Listen:    generateListenAddresses(g.healthCheck.port, g.ipFamily, nil),
+			Path:      g.healthCheck.path,
+			AccessLog: g.healthCheck.accessLog,
+		},
+	}
+}
+
+func (g *GeneratorImpl) generateDefaultSSLServer() server {
+	return server{IsDefaultSSL: true, SSL: g.defaultCertificate}
 }
 
-func generateDefaultHTTPServer() server {
-	return server{IsDefaultHTTP: true}
+func (g *GeneratorImpl) generateDefaultHTTPServer(ctx context.Context) server {
+	s := server{IsDefaultHTTP: true}
+
+	if g.defaultBackend == nil {
+		return s
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.resolveTimeout)
+	defer cancel()
+
+	// if the default backend cannot be resolved, the requests are proxied to the fallback upstream
+	address, _ := state.ResolveBackend(ctx, g.serviceStore, g.defaultBackend.nsname, g.defaultBackend.port)
+	if address != "" {
+		address = formatBackendAddress(address, g.defaultBackend.port)
+	}
+	if isHostnameAddress(address) && g.checkResolver(address) != nil {
+		address = ""
+	}
+
+	s.Locations = []location{
+		{
+			Path:      "/",
+			ProxyPass: generateProxyPass(address),
+		},
+	}
+
+	return s
 }
 
-func generate(virtualServer state.VirtualServer, serviceStore state.ServiceStore) (server, Warnings) {
+// generate generates the server for the virtual server and the upstreams of the rules with multiple backends.
+// The backends are resolved through the memo, which is shared by all servers of a single generation.
+// If the njs module is not available, the matches that require the module (all matches except a single path-only
+// or method-only match) are evaluated with the directives of the rewrite module. The matches the rewrite module
+// cannot evaluate (see validateRewriteMatch) are ignored and reported as warnings.
+func (g *GeneratorImpl) generate(
+	ctx context.Context,
+	virtualServer state.VirtualServer,
+	memo state.BackendResolutions,
+) (server, []upstream, Warnings) {
 	warnings := newWarnings()
+	var upstreams []upstream
 
 	s := server{ServerName: virtualServer.Hostname}
 
-	if virtualServer.SSL != nil {
-		s.SSL = &ssl{
-			Certificate:    virtualServer.SSL.CertificatePath,
-			CertificateKey: virtualServer.SSL.CertificatePath,
-		}
+	s.SSL = g.generateSSL(virtualServer.SSL)
+
+	if virtualServer.HTTPSRedirect {
+		s.Locations = []location{g.generateHTTPSRedirectLocation()}
+		return s, nil, warnings
 	}
 
 	if len(virtualServer.PathRules) == 0 {
 		// generate default "/" 404 location
-		s.Locations = []location{{Path: "/", Return: &returnVal{Code: statusNotFound}}}
-		return s, warnings
+		s.Locations = []location{{Path: "/", Return: generateNotFoundReturn()}}
+		return s, nil, warnings
+	}
+
+	// the resolution of all backends of the server is bounded, so that a slow resolver doesn't stall the generation
+	ctx, cancel := context.WithTimeout(ctx, g.resolveTimeout)
+	defer cancel()
+
+	// routeOpts holds the options of the HTTPRoutes of the server, so that the options of an HTTPRoute are only
+	// parsed (and their warnings reported) once.
+	routeOpts := make(map[*v1beta1.HTTPRoute]routeOptions)
+	// mirrors holds the paths of the mirror locations of the rules of the HTTPRoutes, so that a mirror location is
+	// only generated once per rule. Empty path means the rule doesn't mirror requests.
+	mirrors := make(map[ruleKey]string)
+
+	pathRules := virtualServer.PathRules
+	if g.normalizePaths {
+		pathRules = normalizePathRules(pathRules)
+	}
+
+	if g.sourceComments {
+		s.Sources = generateServerSources(pathRules)
 	}
 
-	locs := make([]location, 0, len(virtualServer.PathRules)) // FIXME(pleshakov): expand with rule.Routes
-	for _, rule := range virtualServer.PathRules {
+	locs := make([]location, 0, len(pathRules)) // FIXME(pleshakov): expand with rule.Routes
+	for _, rule := range pathRules {
 		matches := make([]httpMatch, 0, len(rule.MatchRules))
+		pathLocGenerated := false
+		// pathCORS is the CORS of the location of the path with the matches, which responds to the preflight
+		// requests before they are matched. The CORS of the match with the highest precedence is used.
+		var pathCORS *cors
 
 		for ruleIdx, r := range rule.MatchRules {
+			m := r.GetMatch()
+
+			// a single method-only match doesn't need the njs module: the method is checked in the standard location.
+			standardLoc := len(rule.MatchRules) == 1 && (isPathOnlyMatch(m) || isMethodOnlyMatch(m))
+
+			if !standardLoc && !g.njsAvailable {
+				if err := validateRewriteMatch(m); err != nil {
+					warnings.AddWarningf(r.Source,
+						"match %d of rule %d for the path %s requires the njs module, which is not available: %s; "+
+							"the match is ignored",
+						r.MatchIdx, r.RuleIdx, rule.Path, err.Error(),
+					)
+					continue
+				}
+			}
 
-			address, err := getBackendAddress(r.Source.Spec.Rules[r.RuleIdx].BackendRefs, r.Source.Namespace, serviceStore)
-			if err != nil {
+			b, errs := g.getBackend(ctx, memo, r.Source, r.RuleIdx)
+			for _, err := range errs {
 				warnings.AddWarning(r.Source, err.Error())
 			}
 
-			m := r.GetMatch()
+			upstreams = append(upstreams, b.upstreams...)
+
+			address := b.address
+
+			opts, exist := routeOpts[r.Source]
+			if !exist {
+				var warns Warnings
+				opts, warns = getRouteOptions(r.Source)
+				opts.LocationSnippets, opts.ServerSnippets = getSnippets(r.Source, g.allowSnippets, warns)
 
-			// handle case where the only route is a path-only match
+				if opts.BackendTLS && opts.BackendSSLVerify && g.backendCACertificate == "" {
+					warnings.AddWarning(r.Source, "the verification of the HTTPS backends requires a CA certificate, "+
+						"which is not configured; the certificates of the backends are not verified")
+				}
+
+				for _, err := range ValidateHTTPRoute(r.Source) {
+					warnings.AddWarningf(r.Source, "%s; the feature is ignored", err.Error())
+				}
+
+				routeOpts[r.Source] = opts
+				warnings.Add(warns)
+			}
+
+			mk := ruleKey{route: r.Source, ruleIdx: r.RuleIdx}
+			mirror, exist := mirrors[mk]
+			if !exist {
+				mirrorLoc, err := g.getMirrorLocation(ctx, memo, r.Source, r.RuleIdx)
+				if err != nil {
+					warnings.AddWarning(r.Source, err.Error())
+				}
+
+				if mirrorLoc != nil {
+					if g.sourceComments {
+						mirrorLoc.Sources = []string{generateRuleSource(r)}
+					}
+					mirror = mirrorLoc.Path
+					locs = append(locs, *mirrorLoc)
+				}
+
+				mirrors[mk] = mirror
+			}
+
+			var loc location
+
+			// handle case where the only route is a path-only or method-only match
 			// generate a standard location block without http_matches.
-			if len(rule.MatchRules) == 1 && isPathOnlyMatch(m) {
-				locs = append(locs, location{
+			if standardLoc {
+				loc = location{
 					Path:      rule.Path,
 					ProxyPass: generateProxyPass(address),
-				})
+				}
+				if m.Method != nil {
+					loc.Method = string(*m.Method)
+				}
+				pathLocGenerated = true
 			} else {
 				path := createPathForMatch(rule.Path, ruleIdx)
-				locs = append(locs, generateMatchLocation(path, address))
-				matches = append(matches, createHTTPMatch(m, path))
+				loc = generateMatchLocation(path, address)
+
+				hm, duplicates := createHTTPMatch(m, path)
+				for _, d := range duplicates {
+					warnings.AddWarningf(r.Source, "match %d of rule %d for the path %s: %s",
+						r.MatchIdx, r.RuleIdx, rule.Path, d)
+				}
+				hm.CaseInsensitiveHeaders = opts.CaseInsensitiveHeaders && len(hm.Headers) > 0
+				matches = append(matches, hm)
+			}
+
+			// the fallback upstream is always proxied over HTTP
+			if opts.BackendTLS && address != "" {
+				loc.ProxyPass = generateSecureProxyPass(address)
+				loc.ProxySSL = g.generateProxySSL(opts)
+			}
+
+			if address == "" && g.noBackendsResponse != nil {
+				loc.ProxyPass = ""
+				loc.Return = g.noBackendsResponse
+			}
+
+			loc.Streaming = opts.Streaming
+			// streaming already disables the buffering
+			if opts.ProxyBuffering != nil && !opts.Streaming {
+				loc.ProxyBuffering = formatSwitch(*opts.ProxyBuffering)
+			}
+			loc.ProxyBuffers = opts.ProxyBuffers
+			loc.ProxyBufferSize = opts.ProxyBufferSize
+			loc.ForceRanges = opts.ForceRanges
+			loc.MaxRanges = opts.MaxRanges
+			loc.ProxyHTTPVersion = opts.ProxyHTTPVersion
+			loc.WebSocket = opts.WebSocket
+			loc.CacheBypass = opts.CacheBypass
+			loc.NoCache = opts.NoCache
+			loc.Mirror = mirror
+			loc.CORS = opts.CORS
+			if pathCORS == nil && !standardLoc {
+				pathCORS = opts.CORS
 			}
+
+			timeouts := opts.Timeouts.merge(g.proxyTimeouts)
+			loc.ProxyConnectTimeout = formatDuration(timeouts.Connect)
+			loc.ProxyReadTimeout = formatDuration(timeouts.Read)
+			loc.ProxySendTimeout = formatDuration(timeouts.Send)
+			loc.ProxyNextUpstream = opts.NextUpstream
+			loc.ProxyNextUpstreamTries = opts.NextUpstreamTries
+			loc.ProxyNextUpstreamTimeout = formatDuration(opts.NextUpstreamTimeout)
+
+			if g.sourceComments {
+				loc.Sources = []string{generateMatchSource(r)}
+			}
+
+			loc.Snippets = opts.LocationSnippets
+			loc.BackendSplit = b.split
+
+			locs = append(locs, loc)
 		}
 
 		if len(matches) > 0 {
-			b, err := json.Marshal(matches)
-			if err != nil {
-				// panic is safe here because we should never fail to marshal the match unless we constructed it incorrectly.
-				panic(fmt.Errorf("could not marshal http match: %w", err))
+			pathLoc := location{
+				Path: rule.Path,
+				CORS: pathCORS,
 			}
 
-			pathLoc := location{
-				Path:         rule.Path,
-				HTTPMatchVar: string(b),
+			if g.njsAvailable {
+				b, err := json.Marshal(matches)
+				if err != nil {
+					// panic is safe here because we should never fail to marshal the match unless we constructed it
+					// incorrectly.
+					panic(fmt.Errorf("could not marshal http match: %w", err))
+				}
+				pathLoc.HTTPMatchVar = string(b)
+			} else {
+				pathLoc.RewriteMatches = make([]rewriteMatch, 0, len(matches))
+				for _, hm := range matches {
+					pathLoc.RewriteMatches = append(pathLoc.RewriteMatches, createRewriteMatch(hm))
+				}
+			}
+
+			// the location of the path redirects the requests to the locations of the matches
+			if g.sourceComments {
+				for _, r := range rule.MatchRules {
+					pathLoc.Sources = append(pathLoc.Sources, generateMatchSource(r))
+				}
 			}
 
 			locs = append(locs, pathLoc)
+		} else if !pathLocGenerated {
+			// all matches for the path were ignored
+			locs = append(locs, location{Path: rule.Path, Return: generateNotFoundReturn()})
 		}
 	}
 
 	s.Locations = locs
+	s.Snippets = generateServerSnippets(routeOpts)
+
+	return s, upstreams, warnings
+}
+
+// generateServerSnippets generates the server snippets of the HTTPRoutes of the server, ordered by the namespaces
+// and names of the HTTPRoutes.
+func generateServerSnippets(routeOpts map[*v1beta1.HTTPRoute]routeOptions) []string {
+	routes := make([]*v1beta1.HTTPRoute, 0, len(routeOpts))
+	for hr, opts := range routeOpts {
+		if len(opts.ServerSnippets) > 0 {
+			routes = append(routes, hr)
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Namespace != routes[j].Namespace {
+			return routes[i].Namespace < routes[j].Namespace
+		}
+		return routes[i].Name < routes[j].Name
+	})
+
+	var snippets []string
+	for _, hr := range routes {
+		snippets = append(snippets, routeOpts[hr].ServerSnippets...)
+	}
+
+	return snippets
+}
+
+// generateServerSources generates the sorted unique sources of the server: the HTTPRoutes of the rules.
+func generateServerSources(rules []state.PathRule) []string {
+	unique := make(map[string]struct{})
+	for _, rule := range rules {
+		for _, r := range rule.MatchRules {
+			unique[fmt.Sprintf("HTTPRoute %s/%s", r.Source.Namespace, r.Source.Name)] = struct{}{}
+		}
+	}
+
+	sources := make([]string, 0, len(unique))
+	for src := range unique {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+
+	return sources
+}
+
+// generateRuleSource generates the source of a location of the rule of the match rule, like a mirror location.
+func generateRuleSource(r state.MatchRule) string {
+	return fmt.Sprintf("HTTPRoute %s/%s rule %d", r.Source.Namespace, r.Source.Name, r.RuleIdx)
+}
+
+// generateMatchSource generates the source of the location of the match rule.
+func generateMatchSource(r state.MatchRule) string {
+	return fmt.Sprintf("%s match %d", generateRuleSource(r), r.MatchIdx)
+}
+
+// normalizePathRules normalizes the paths of the rules. The rules whose paths become the same are merged, so that
+// a single location is generated per path, and the match rules of the merged rules are sorted by their precedence.
+func normalizePathRules(rules []state.PathRule) []state.PathRule {
+	normalized := make([]state.PathRule, 0, len(rules))
+	indexes := make(map[string]int, len(rules))
+
+	for _, r := range rules {
+		path := normalizePath(r.Path)
+
+		idx, exist := indexes[path]
+		if !exist {
+			indexes[path] = len(normalized)
+			normalized = append(normalized, state.PathRule{Path: path, MatchRules: r.MatchRules})
+			continue
+		}
+
+		// the match rules are copied, so that the rules of the Configuration are not modified
+		merged := make([]state.MatchRule, 0, len(normalized[idx].MatchRules)+len(r.MatchRules))
+		merged = append(merged, normalized[idx].MatchRules...)
+		merged = append(merged, r.MatchRules...)
+		state.SortMatchRules(merged)
+
+		normalized[idx].MatchRules = merged
+	}
+
+	return normalized
+}
+
+// normalizePath normalizes the path the way NGINX normalizes the URI of a request: the duplicate slashes are merged
+// and the percent-encoded unreserved characters are decoded. The other percent-encoded characters are kept, so that
+// the path never includes the characters that are not allowed in the location directive.
+func normalizePath(path string) string {
+	var b strings.Builder
+	b.Grow(len(path))
+
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+
+		if c == '%' && i+2 < len(path) {
+			if decoded, err := strconv.ParseUint(path[i+1:i+3], 16, 8); err == nil && isUnreserved(byte(decoded)) {
+				c = byte(decoded)
+				i += 2
+			}
+		}
+
+		if c == '/' && strings.HasSuffix(b.String(), "/") {
+			continue
+		}
+
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// isUnreserved reports whether the character is an unreserved character of a URI (RFC 3986).
+func isUnreserved(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// backend is the destination of the requests of a rule.
+type backend struct {
+	// address is the name of the upstream of the rule, the variable of its split or the address of a backend with
+	// a hostname. It is empty if none of the backends can be resolved.
+	address string
+	// upstreams are the upstream of the rule or, if the requests are split, the upstreams of its backends.
+	// They are empty for a backend with a hostname.
+	upstreams []upstream
+	// split is the split of the requests among the backends whose backendRefs modify the request headers.
+	split *backendSplit
+}
+
+// getBackend resolves the backends of the rule of the HTTPRoute.
+// The requests of a rule are proxied through an upstream, so that the connections to the backends are kept
+// alive, except for a single backend with a hostname, for example, an ExternalName Service, which NGINX resolves at
+// runtime. When the rule has multiple backends, the requests are split among the resolved backends according to their
+// weights. The backends that cannot be resolved are excluded from the upstream. If none of them can be resolved,
+// no upstream is generated.
+// If the backendRefs of the rule modify the request headers, every backend has its own upstream and the requests are
+// split among the upstreams, so that the requests to a backend get its headers (see newSplitBackend).
+// getBackend returns the errors of the backends that cannot be resolved.
+func (g *GeneratorImpl) getBackend(
+	ctx context.Context,
+	memo state.BackendResolutions,
+	hr *v1beta1.HTTPRoute,
+	ruleIdx int,
+) (backend, []error) {
+	refs := hr.Spec.Rules[ruleIdx].BackendRefs
+
+	if len(refs) <= 1 {
+		address, err := getBackendAddress(ctx, memo, refs, hr.Namespace, g.serviceStore, g.backendResolvers)
+		if err != nil {
+			return backend{}, []error{err}
+		}
+
+		// NGINX resolves the hostnames of the upstream servers only when it loads the configuration, so the requests
+		// are proxied to the hostname directly, which NGINX resolves at runtime by the resolver.
+		if isHostnameAddress(address) {
+			if err := g.checkResolver(address); err != nil {
+				return backend{}, []error{err}
+			}
+
+			var errs []error
+			if hasHeaderFilters(refs) {
+				errs = append(errs, fmt.Errorf("the filters of the backend %s with a hostname are not supported; "+
+					"the filters are ignored", address))
+			}
+
+			return backend{address: address}, errs
+		}
+
+		if hasHeaderFilters(refs) {
+			return g.newSplitBackend(hr, ruleIdx, []splitTarget{{refIdx: 0, ref: refs[0], address: address}})
+		}
+
+		return g.newUpstreamBackend(hr, ruleIdx, []upstreamServer{{Address: address}}), nil
+	}
+
+	var errs []error
+	servers := make([]upstreamServer, 0, len(refs))
+	targets := make([]splitTarget, 0, len(refs))
+	zeroWeightRefs := 0
+
+	for i, ref := range refs {
+		// the backends with weight 0 don't receive any traffic, so they are excluded from the upstream
+		if ref.Weight != nil && *ref.Weight == 0 {
+			zeroWeightRefs++
+			continue
+		}
+
+		address, err := resolveBackendRef(ctx, memo, ref.BackendRef, hr.Namespace, g.serviceStore, g.backendResolvers)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if isHostnameAddress(address) {
+			errs = append(errs, fmt.Errorf(
+				"the backend %s has a hostname, which is only supported for the rules with a single backend", address))
+			continue
+		}
+
+		servers = append(servers, upstreamServer{
+			Address: address,
+			Weight:  getWeight(ref.Weight),
+		})
+		targets = append(targets, splitTarget{refIdx: i, ref: ref, address: address})
+	}
+
+	if zeroWeightRefs == len(refs) {
+		errs = append(errs, fmt.Errorf("all %d backends of rule %d have weight 0 and receive no traffic", len(refs), ruleIdx))
+		return backend{}, errs
+	}
+
+	if len(servers) == 0 {
+		errs = append(errs, fmt.Errorf("none of the %d backends of rule %d can be resolved", len(refs), ruleIdx))
+		return backend{}, errs
+	}
+
+	if hasHeaderFilters(refs) {
+		b, splitErrs := g.newSplitBackend(hr, ruleIdx, targets)
+		return b, append(errs, splitErrs...)
+	}
+
+	return g.newUpstreamBackend(hr, ruleIdx, servers), errs
+}
+
+// isHostnameAddress reports whether the address of a backend, in the form HOST:PORT, has a hostname rather than
+// an IP, for example, the address of an ExternalName Service.
+func isHostnameAddress(address string) bool {
+	if isUnixSocketAddress(address) {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	return err == nil && net.ParseIP(host) == nil
+}
+
+// checkResolver returns an error if NGINX cannot resolve the backend with the hostname at runtime, because no
+// resolver is configured.
+func (g *GeneratorImpl) checkResolver(address string) error {
+	if len(g.resolver) == 0 {
+		return fmt.Errorf("the backend %s has a hostname, but no resolver is configured", address)
+	}
+
+	return nil
+}
+
+func generateOCSPStapling(trustedCertificate string) *ocspStapling {
+	if trustedCertificate == "" {
+		return nil
+	}
+
+	return &ocspStapling{TrustedCertificate: trustedCertificate}
+}
+
+// usesHostnames reports whether any location proxies the requests to a backend with a hostname.
+func usesHostnames(servers []server) bool {
+	for _, s := range servers {
+		for _, l := range s.Locations {
+			address := strings.TrimPrefix(strings.TrimPrefix(l.ProxyPass, "http://"), "https://")
+			if isHostnameAddress(address) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// newUpstreamBackend creates the backend that proxies the requests of the rule of the HTTPRoute to the servers
+// through the upstream of the rule.
+func (g *GeneratorImpl) newUpstreamBackend(hr *v1beta1.HTTPRoute, ruleIdx int, servers []upstreamServer) backend {
+	name := getUpstreamName(hr, ruleIdx)
+
+	return backend{
+		address: name,
+		upstreams: []upstream{
+			{
+				Name:      name,
+				Servers:   servers,
+				Keepalive: g.upstreamKeepalive,
+				ZoneSize:  g.upstreamZoneSize,
+			},
+		},
+	}
+}
+
+// ruleKey identifies a rule of an HTTPRoute.
+type ruleKey struct {
+	route   *v1beta1.HTTPRoute
+	ruleIdx int
+}
+
+// getMirrorLocation generates the internal location that proxies the mirrored requests of a rule to the backend of
+// its RequestMirror filter. NGINX ignores the responses to the mirrored requests, so the mirror backend doesn't
+// affect the responses to the clients. It returns nil if the rule doesn't have a RequestMirror filter.
+func (g *GeneratorImpl) getMirrorLocation(
+	ctx context.Context,
+	memo state.BackendResolutions,
+	hr *v1beta1.HTTPRoute,
+	ruleIdx int,
+) (*location, error) {
+	for _, f := range hr.Spec.Rules[ruleIdx].Filters {
+		if f.Type != v1beta1.HTTPRouteFilterRequestMirror || f.RequestMirror == nil {
+			continue
+		}
+
+		ref := v1beta1.BackendRef{BackendObjectReference: f.RequestMirror.BackendRef}
+
+		address, err := resolveBackendRef(ctx, memo, ref, hr.Namespace, g.serviceStore, g.backendResolvers)
+		if err != nil {
+			return nil, fmt.Errorf("the mirror backend of rule %d cannot be resolved: %w", ruleIdx, err)
+		}
+
+		if isHostnameAddress(address) {
+			if err := g.checkResolver(address); err != nil {
+				return nil, fmt.Errorf("the mirror backend of rule %d cannot be resolved: %w", ruleIdx, err)
+			}
+		}
+
+		return &location{
+			Path:      getMirrorPath(hr, ruleIdx),
+			ProxyPass: generateProxyPass(address),
+			Internal:  true,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func getMirrorPath(hr *v1beta1.HTTPRoute, ruleIdx int) string {
+	return fmt.Sprintf("/_mirror_%s_%s_rule%d", hr.Namespace, hr.Name, ruleIdx)
+}
+
+// getUpstreamName returns the name of the upstream of the rule of the HTTPRoute.
+func getUpstreamName(hr *v1beta1.HTTPRoute, ruleIdx int) string {
+	return fmt.Sprintf("%s_%s_rule%d", hr.Namespace, hr.Name, ruleIdx)
+}
+
+// getWeight returns the weight of a backendRef. The default weight is 1.
+func getWeight(weight *int32) int32 {
+	if weight == nil {
+		return 1
+	}
+	return *weight
+}
 
-	return s, warnings
+// formatSwitch formats the value of an NGINX directive that is on or off.
+func formatSwitch(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
+
+func generateNotFoundReturn() *returnVal {
+	return &returnVal{
+		Code:        statusNotFound,
+		ContentType: contentTypeHTML,
+	}
 }
 
 func generateProxyPass(address string) string {
 	if address == "" {
-		return "http://" + nginx502Server
+		return "http://" + fallbackUpstreamName
 	}
-	return "http://" + address
+	return "http://" + generateProxyPassAddress(address)
+}
+
+func generateSecureProxyPass(address string) string {
+	return "https://" + generateProxyPassAddress(address)
+}
+
+// generateProxyPassAddress generates the address of the proxy_pass directive. The path of a Unix domain socket is
+// terminated by a colon, which separates it from the URI the template appends.
+func generateProxyPassAddress(address string) string {
+	if isUnixSocketAddress(address) {
+		return address + ":"
+	}
+	return address
+}
+
+// isUnixSocketAddress reports whether the backend of the address is reachable through a Unix domain socket,
+// for example, a sidecar, rather than TCP.
+func isUnixSocketAddress(address string) bool {
+	return strings.HasPrefix(address, unixSocketPrefix)
+}
+
+// formatBackendAddress formats the resolved address of a backend with its port, in the form HOST:PORT.
+// The address of a Unix domain socket doesn't have a port, so it is returned as is.
+func formatBackendAddress(address string, port int32) string {
+	if isUnixSocketAddress(address) {
+		return address
+	}
+	return fmt.Sprintf("%s:%d", address, port)
+}
+
+func (g *GeneratorImpl) generateProxySSL(opts routeOptions) *proxySSL {
+	ps := &proxySSL{Name: opts.BackendSSLName}
+
+	if opts.BackendSSLVerify {
+		ps.TrustedCertificate = g.backendCACertificate
+	}
+
+	return ps
 }
 
 func getBackendAddress(
+	ctx context.Context,
+	memo state.BackendResolutions,
 	refs []v1beta1.HTTPBackendRef,
 	parentNS string,
 	serviceStore state.ServiceStore,
+	resolvers map[state.BackendKind]state.BackendResolver,
 ) (string, error) {
 	if len(refs) == 0 {
 		return "", errors.New("empty backend refs")
 	}
 
-	// FIXME(pleshakov): for now, we only support a single backend reference
-	ref := refs[0].BackendRef
+	// a backend with weight 0 must not receive any traffic, so the rule doesn't have an available backend
+	if refs[0].Weight != nil && *refs[0].Weight == 0 {
+		return "", fmt.Errorf("the backend %s has weight 0 and receives no traffic", refs[0].Name)
+	}
+
+	// the rules with multiple backends are handled by getBackend
+	return resolveBackendRef(ctx, memo, refs[0].BackendRef, parentNS, serviceStore, resolvers)
+}
+
+// resolveBackendRef resolves the address of the backend the ref references. The Services are resolved by
+// the serviceStore and the other kinds by their resolvers. If the ref doesn't specify the namespace, parentNS is used.
+// If ctx is done before the backend is resolved, the backend cannot be resolved.
+// The resolutions are memoized in the memo, so that every backend is resolved once per generation.
+func resolveBackendRef(
+	ctx context.Context,
+	memo state.BackendResolutions,
+	ref v1beta1.BackendRef,
+	parentNS string,
+	serviceStore state.ServiceStore,
+	resolvers map[state.BackendKind]state.BackendResolver,
+) (string, error) {
+	kind := state.GetBackendKind(ref.BackendObjectReference)
 
-	if ref.Kind != nil && *ref.Kind != "Service" {
-		return "", fmt.Errorf("unsupported kind %s", *ref.Kind)
+	var resolver state.BackendResolver = serviceStore
+	if kind != state.ServiceBackendKind {
+		r, exists := resolvers[kind]
+		if !exists {
+			if kind.Kind == state.ServiceBackendKind.Kind {
+				return "", fmt.Errorf("unsupported group %s, must be the core group", kind.Group)
+			}
+			return "", fmt.Errorf("unsupported kind %s", kind.Kind)
+		}
+		resolver = r
 	}
 
 	ns := parentNS
@@ -171,16 +1819,22 @@ func getBackendAddress(
 		ns = string(*ref.Namespace)
 	}
 
-	address, err := serviceStore.Resolve(types.NamespacedName{Namespace: ns, Name: string(ref.Name)})
-	if err != nil {
-		return "", fmt.Errorf("service %s/%s cannot be resolved: %w", ns, ref.Name, err)
-	}
-
 	if ref.Port == nil {
 		return "", errors.New("port is nil")
 	}
 
-	return fmt.Sprintf("%s:%d", address, *ref.Port), nil
+	key := state.BackendKey{
+		Kind:   kind,
+		NsName: types.NamespacedName{Namespace: ns, Name: string(ref.Name)},
+		Port:   int32(*ref.Port),
+	}
+
+	address, err := memo.Resolve(ctx, resolver, key)
+	if err != nil {
+		return "", fmt.Errorf("%s %s/%s cannot be resolved: %w", strings.ToLower(kind.Kind), ns, ref.Name, err)
+	}
+
+	return formatBackendAddress(address, int32(*ref.Port)), nil
 }
 
 func generateMatchLocation(path, address string) location {
@@ -206,62 +1860,93 @@ type httpMatch struct {
 	Method v1beta1.HTTPMethod `json:"method,omitempty"`
 	// Headers is a list of HTTPHeaders name value pairs with the format "{name}:{value}".
 	Headers []string `json:"headers,omitempty"`
+	// CaseInsensitiveHeaders makes the comparison of the values of the Headers case-insensitive.
+	CaseInsensitiveHeaders bool `json:"caseInsensitiveHeaders,omitempty"`
 	// QueryParams is a list of HTTPQueryParams name value pairs with the format "{name}={value}".
 	QueryParams []string `json:"params,omitempty"`
 	// RedirectPath is the path to redirect the request to if the request satisfies the match conditions.
 	RedirectPath string `json:"redirectPath,omitempty"`
 }
 
-func createHTTPMatch(match v1beta1.HTTPRouteMatch, redirectPath string) httpMatch {
+// createHTTPMatch creates the httpMatch for the match. It also returns the messages about the ignored duplicate
+// header and query parameter matches.
+//
+// Duplicate header names (case-insensitive) and duplicate query parameter names (case-sensitive) are not permitted
+// by the spec. If the match includes duplicates, only the first entry for every name is configured.
+func createHTTPMatch(match v1beta1.HTTPRouteMatch, redirectPath string) (httpMatch, []string) {
 	hm := httpMatch{
 		RedirectPath: redirectPath,
 	}
 
 	if isPathOnlyMatch(match) {
 		hm.Any = true
-		return hm
+		return hm, nil
 	}
 
 	if match.Method != nil {
 		hm.Method = *match.Method
 	}
 
+	var duplicates []string
+
 	if match.Headers != nil {
 		headers := make([]string, 0, len(match.Headers))
 		headerNames := make(map[string]struct{})
 
-		// FIXME(kate-osborn): For now we only support type "Exact".
+		// only the type Exact is supported, ValidateHTTPRoute reports the other types
 		for _, h := range match.Headers {
-			if *h.Type == v1beta1.HeaderMatchExact {
-				// duplicate header names are not permitted by the spec
-				// only configure the first entry for every header name (case-insensitive)
-				lowerName := strings.ToLower(string(h.Name))
-				if _, ok := headerNames[lowerName]; !ok {
-					headers = append(headers, createHeaderKeyValString(h))
-					headerNames[lowerName] = struct{}{}
-				}
+			// Exact is the default type
+			if h.Type != nil && *h.Type != v1beta1.HeaderMatchExact {
+				continue
+			}
+
+			lowerName := strings.ToLower(string(h.Name))
+			if _, ok := headerNames[lowerName]; ok {
+				duplicates = append(duplicates, fmt.Sprintf(
+					"the header %s is duplicated; only its first value is used, the value %q is ignored",
+					h.Name, h.Value,
+				))
+				continue
 			}
+
+			headers = append(headers, createHeaderKeyValString(h))
+			headerNames[lowerName] = struct{}{}
 		}
 		hm.Headers = headers
 	}
 
 	if match.QueryParams != nil {
 		params := make([]string, 0, len(match.QueryParams))
+		paramNames := make(map[string]struct{})
 
-		// FIXME(kate-osborn): For now we only support type "Exact".
+		// only the type Exact is supported, ValidateHTTPRoute reports the other types
 		for _, p := range match.QueryParams {
-			if *p.Type == v1beta1.QueryParamMatchExact {
-				params = append(params, createQueryParamKeyValString(p))
+			// Exact is the default type
+			if p.Type != nil && *p.Type != v1beta1.QueryParamMatchExact {
+				continue
+			}
+
+			if _, ok := paramNames[p.Name]; ok {
+				duplicates = append(duplicates, fmt.Sprintf(
+					"the query parameter %s is duplicated; only its first value is used, the value %q is ignored",
+					p.Name, p.Value,
+				))
+				continue
 			}
+
+			params = append(params, createQueryParamKeyValString(p))
+			paramNames[p.Name] = struct{}{}
 		}
 		hm.QueryParams = params
 	}
 
-	return hm
+	return hm, duplicates
 }
 
 // The name and values are delimited by "=". A name and value can always be recovered using strings.SplitN(arg,"=", 2).
 // Query Parameters are case-sensitive so case is preserved.
+// The value can be empty (e.g. "flag="). Such a match requires the parameter to be present in the request with
+// an empty value; the requests without the parameter don't satisfy it.
 func createQueryParamKeyValString(p v1beta1.HTTPQueryParamMatch) string {
 	return p.Name + "=" + p.Value
 }
@@ -276,3 +1961,22 @@ func createHeaderKeyValString(h v1beta1.HTTPHeaderMatch) string {
 func isPathOnlyMatch(match v1beta1.HTTPRouteMatch) bool {
 	return match.Method == nil && match.Headers == nil && match.QueryParams == nil
 }
+
+func isMethodOnlyMatch(match v1beta1.HTTPRouteMatch) bool {
+	return match.Method != nil && match.Headers == nil && match.QueryParams == nil
+}
+
+// formatDuration formats the duration in the NGINX time format with the precision of milliseconds, for example, 5s
+// or 1500ms. Zero is formatted as an empty string.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+
+	ms := d.Milliseconds()
+	if ms%1000 == 0 {
+		return fmt.Sprintf("%ds", ms/1000)
+	}
+
+	return fmt.Sprintf("%dms", ms)
+}
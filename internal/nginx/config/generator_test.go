@@ -1,17 +1,24 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"strings"
 	"testing"
+	"text/template"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/helpers"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/metrics"
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state/statefakes"
 )
@@ -75,7 +82,7 @@ func TestGenerateForHost(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		cfg, warnings := generator.Generate(tc.conf)
+		cfg, warnings, _ := generator.Generate(context.Background(), tc.conf)
 
 		defaultSSLExists := strings.Contains(string(cfg), "listen 443 ssl default_server")
 		defaultHTTPExists := strings.Contains(string(cfg), "listen 80 default_server")
@@ -99,40 +106,6237 @@ func TestGenerateForHost(t *testing.T) {
 		if len(cfg) == 0 {
 			t.Errorf("Generate() generated empty config for test: %q", tc.msg)
 		}
+
+		fallbackUpstreams := strings.Count(string(cfg), "upstream "+fallbackUpstreamName+" {")
+		if fallbackUpstreams != 1 {
+			t.Errorf("Generate() generated %d fallback upstreams but expected 1 for test: %q", fallbackUpstreams, tc.msg)
+		}
+
+		fallbackServers := strings.Count(string(cfg), "listen "+nginx502Server+";")
+		if fallbackServers != 1 {
+			t.Errorf("Generate() generated %d fallback servers but expected 1 for test: %q", fallbackServers, tc.msg)
+		}
+
 		if len(warnings) > 0 {
 			t.Errorf("Generate() returned unexpected warnings: %v for test: %q", warnings, tc.msg)
 		}
 	}
 }
 
-func TestGenerate(t *testing.T) {
+func TestGenerateWithListenPorts(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+		SSLServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				SSL: &state.SSL{
+					CertificatePath: "/etc/nginx/secrets/cafe",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		options  []GeneratorOption
+		expected []string
+		msg      string
+	}{
+		{
+			expected: []string{
+				"listen 80 default_server;",
+				"listen 443 ssl default_server;",
+				"listen 80;",
+				"listen 443 ssl;",
+			},
+			msg: "default ports",
+		},
+		{
+			options: []GeneratorOption{WithListenPorts(8080, 8443)},
+			expected: []string{
+				"listen 8080 default_server;",
+				"listen 8443 ssl default_server;",
+				"listen 8080;",
+				"listen 8443 ssl;",
+			},
+			msg: "custom ports",
+		},
+	}
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, test.options...)
+
+		cfg, _, _ := generator.Generate(context.Background(), conf)
+
+		for _, e := range test.expected {
+			if c := strings.Count(string(cfg), e); c != 1 {
+				t.Errorf("Generate() generated %q %d times but expected once for the case of %q; got:\n%s",
+					e, c, test.msg, string(cfg))
+			}
+		}
+
+		files, _, _ := generator.GenerateFiles(context.Background(), conf)
+
+		var generated strings.Builder
+		for _, f := range files {
+			generated.Write(f)
+		}
+
+		for _, e := range test.expected {
+			if c := strings.Count(generated.String(), e); c != 1 {
+				t.Errorf("GenerateFiles() generated %q %d times but expected once for the case of %q", e, c, test.msg)
+			}
+		}
+	}
+}
+
+func TestGenerateWithGatewayAddresses(t *testing.T) {
+	createConf := func(addresses ...string) state.Configuration {
+		return state.Configuration{
+			HTTPServers: []state.VirtualServer{
+				{
+					Hostname: "cafe.example.com",
+				},
+			},
+			SSLServers: []state.VirtualServer{
+				{
+					Hostname: "cafe.example.com",
+					SSL: &state.SSL{
+						CertificatePath: "/etc/nginx/secrets/cafe.crt",
+						KeyPath:         "/etc/nginx/secrets/cafe.key",
+					},
+				},
+			},
+			ListenAddresses: addresses,
+		}
+	}
+
+	tests := []struct {
+		conf        state.Configuration
+		expected    []string
+		notExpected []string
+		msg         string
+	}{
+		{
+			conf: createConf(),
+			expected: []string{
+				"listen 80 default_server;",
+				"listen 443 ssl default_server;",
+				"listen 80;",
+				"listen 443 ssl;",
+			},
+			notExpected: []string{"10.0.0.1"},
+			msg:         "all interfaces",
+		},
+		{
+			conf: createConf("10.0.0.1"),
+			expected: []string{
+				"listen 10.0.0.1:80 default_server;",
+				"listen 10.0.0.1:443 ssl default_server;",
+				"listen 10.0.0.1:80;",
+				"listen 10.0.0.1:443 ssl;",
+			},
+			notExpected: []string{
+				"listen 80",
+				"listen 443",
+			},
+			msg: "ip address",
+		},
+	}
+
+	for _, test := range tests {
+		cfg, _, _ := NewGeneratorImpl(&statefakes.FakeServiceStore{}).Generate(context.Background(), test.conf)
+
+		for _, e := range test.expected {
+			if !strings.Contains(string(cfg), e) {
+				t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", e, test.msg, string(cfg))
+			}
+		}
+		for _, ne := range test.notExpected {
+			if strings.Contains(string(cfg), ne) {
+				t.Errorf("Generate() generated %q for the case of %q; got:\n%s", ne, test.msg, string(cfg))
+			}
+		}
+	}
+}
+
+func TestGenerateWithIPFamily(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+		SSLServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				SSL: &state.SSL{
+					CertificatePath: "/etc/nginx/secrets/cafe",
+				},
+			},
+		},
+	}
+
+	ipv4Listens := []string{
+		"listen 80 default_server;",
+		"listen 443 ssl default_server;",
+		"listen 80;",
+		"listen 443 ssl;",
+	}
+	ipv6Listens := []string{
+		"listen [::]:80 default_server;",
+		"listen [::]:443 ssl default_server;",
+		"listen [::]:80;",
+		"listen [::]:443 ssl;",
+	}
+
+	tests := []struct {
+		options     []GeneratorOption
+		expected    []string
+		notExpected []string
+		msg         string
+	}{
+		{
+			expected:    ipv4Listens,
+			notExpected: ipv6Listens,
+			msg:         "default",
+		},
+		{
+			options:     []GeneratorOption{WithIPFamily(IPFamilyIPv4)},
+			expected:    ipv4Listens,
+			notExpected: ipv6Listens,
+			msg:         "ipv4",
+		},
+		{
+			options:     []GeneratorOption{WithIPFamily(IPFamilyIPv6)},
+			expected:    ipv6Listens,
+			notExpected: ipv4Listens,
+			msg:         "ipv6",
+		},
+		{
+			options:  []GeneratorOption{WithIPFamily(IPFamilyDual)},
+			expected: append(append([]string{}, ipv4Listens...), ipv6Listens...),
+			msg:      "dual",
+		},
+	}
+
+	for _, test := range tests {
+		cfg, _, _ := NewGeneratorImpl(&statefakes.FakeServiceStore{}, test.options...).Generate(context.Background(), conf)
+
+		// every address has exactly one default server
+		for _, e := range test.expected {
+			if c := strings.Count(string(cfg), e); c != 1 {
+				t.Errorf("Generate() generated %q %d times but expected once for the case of %q; got:\n%s",
+					e, c, test.msg, string(cfg))
+			}
+		}
+		for _, e := range test.notExpected {
+			if strings.Contains(string(cfg), e) {
+				t.Errorf("Generate() generated %q for the case of %q", e, test.msg)
+			}
+		}
+	}
+}
+
+func TestGenerateFilesDualStack(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+			{
+				Hostname: "tea.example.com",
+			},
+		},
+		SSLServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				SSL: &state.SSL{
+					CertificatePath: "/etc/nginx/secrets/cafe",
+				},
+			},
+		},
+	}
+
+	generator := NewGeneratorImpl(
+		&statefakes.FakeServiceStore{},
+		WithIPFamily(IPFamilyDual),
+	)
+
+	files, _, _ := generator.GenerateFiles(context.Background(), conf)
+
+	for name, f := range files {
+		if name == HTTPServersMainFile || strings.HasSuffix(name, "-fallback.conf") {
+			continue
+		}
+
+		var expected []string
+
+		switch {
+		case strings.HasSuffix(name, "-default-http.conf"):
+			expected = []string{"listen 80 default_server;", "listen [::]:80 default_server;"}
+		case strings.HasSuffix(name, "-default-ssl.conf"):
+			expected = []string{"listen 443 ssl default_server;", "listen [::]:443 ssl default_server;"}
+		case strings.HasSuffix(name, "-ssl.conf"):
+			expected = []string{"listen 443 ssl;", "listen [::]:443 ssl;"}
+		default:
+			expected = []string{"listen 80;", "listen [::]:80;"}
+		}
+
+		for _, e := range expected {
+			if !strings.Contains(string(f), e) {
+				t.Errorf("GenerateFiles() didn't generate %q in the file %s; got:\n%s", e, name, string(f))
+			}
+		}
+	}
+}
+
+func TestGenerateWithSecurityHeaders(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+		SSLServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				SSL: &state.SSL{
+					CertificatePath: "/etc/nginx/secrets/cafe",
+				},
+			},
+		},
+	}
+
+	presetHeaders := []string{
+		"add_header X-Content-Type-Options nosniff always;",
+		"add_header X-Frame-Options SAMEORIGIN always;",
+		"add_header Referrer-Policy strict-origin-when-cross-origin always;",
+	}
+	hstsHeader := `add_header Strict-Transport-Security "max-age=31536000" always;`
+
+	// getServerBlock returns the server block that includes the text.
+	getServerBlock := func(cfg string, text string) string {
+		for _, block := range strings.Split(cfg, "server {") {
+			if strings.Contains(block, text) {
+				return block
+			}
+		}
+		return ""
+	}
+
+	generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, WithSecurityHeaders(true))
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+
+	httpBlock := getServerBlock(string(cfg), "listen 80;")
+	sslBlock := getServerBlock(string(cfg), "listen 443 ssl;")
+
+	for _, h := range presetHeaders {
+		if !strings.Contains(httpBlock, h) {
+			t.Errorf("Generate() didn't generate %q in the HTTP server; got:\n%s", h, httpBlock)
+		}
+		if !strings.Contains(sslBlock, h) {
+			t.Errorf("Generate() didn't generate %q in the SSL server; got:\n%s", h, sslBlock)
+		}
+	}
+
+	if strings.Contains(httpBlock, hstsHeader) {
+		t.Errorf("Generate() generated HSTS in the HTTP server; got:\n%s", httpBlock)
+	}
+	if !strings.Contains(sslBlock, hstsHeader) {
+		t.Errorf("Generate() didn't generate HSTS in the SSL server; got:\n%s", sslBlock)
+	}
+
+	cfg, _, _ = NewGeneratorImpl(&statefakes.FakeServiceStore{}).Generate(context.Background(), conf)
+
+	if strings.Contains(string(cfg), "add_header") {
+		t.Errorf("Generate() generated security headers without the preset; got:\n%s", string(cfg))
+	}
+}
+
+func TestGenerateHealthCheck(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "example.com",
+			},
+		},
+	}
+
+	// getServerBlock returns the server block that includes the text.
+	getServerBlock := func(cfg string, text string) string {
+		for _, block := range strings.Split(cfg, "server {") {
+			if strings.Contains(block, text) {
+				return block
+			}
+		}
+		return ""
+	}
+
+	tests := []struct {
+		options     []GeneratorOption
+		expected    []string
+		notExpected []string
+		msg         string
+	}{
+		{
+			options: []GeneratorOption{WithHealthCheck(8082, DefaultHealthCheckPath, false)},
+			expected: []string{
+				"listen 8082;",
+				"access_log off;",
+				"location = /nginx-health {",
+				`return 200 "healthy\n";`,
+			},
+			msg: "health check",
+		},
+		{
+			options: []GeneratorOption{WithHealthCheck(9000, "/healthz", true), WithIPFamily(IPFamilyDual)},
+			expected: []string{
+				"listen 9000;",
+				"listen [::]:9000;",
+				"location = /healthz {",
+				`return 200 "healthy\n";`,
+			},
+			notExpected: []string{"access_log off;"},
+			msg:         "health check with access log",
+		},
+	}
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, test.options...)
+
+		cfg, _, err := generator.Generate(context.Background(), conf)
+		if err != nil {
+			t.Fatalf("Generate() returned unexpected error %v for the case of %q", err, test.msg)
+		}
+
+		block := getServerBlock(string(cfg), "location = ")
+		if block == "" {
+			t.Fatalf("Generate() didn't generate the health check server for the case of %q; got:\n%s",
+				test.msg, string(cfg))
+		}
+
+		for _, e := range test.expected {
+			if !strings.Contains(block, e) {
+				t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", e, test.msg, block)
+			}
+		}
+		for _, e := range test.notExpected {
+			if strings.Contains(block, e) {
+				t.Errorf("Generate() generated %q for the case of %q; got:\n%s", e, test.msg, block)
+			}
+		}
+
+		// the health check endpoint is only served by its server
+		if strings.Count(string(cfg), "location = ") != 1 {
+			t.Errorf("Generate() generated the health check location outside of its server for the case of %q; "+
+				"got:\n%s", test.msg, string(cfg))
+		}
+	}
+
+	cfg, _, _ := NewGeneratorImpl(&statefakes.FakeServiceStore{}).Generate(context.Background(), conf)
+	if strings.Contains(string(cfg), DefaultHealthCheckPath) {
+		t.Errorf("Generate() generated the health check endpoint without the option; got:\n%s", string(cfg))
+	}
+}
+
+func TestGenerateHSTS(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+		SSLServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				SSL: &state.SSL{
+					CertificatePath: "/etc/nginx/secrets/cafe",
+				},
+			},
+		},
+	}
+
+	// getServerBlock returns the server block that includes the text.
+	getServerBlock := func(cfg string, text string) string {
+		for _, block := range strings.Split(cfg, "server {") {
+			if strings.Contains(block, text) {
+				return block
+			}
+		}
+		return ""
+	}
+
+	tests := []struct {
+		options  []GeneratorOption
+		expected string
+		msg      string
+	}{
+		{
+			options:  []GeneratorOption{WithHSTS(63072000, false, false)},
+			expected: `add_header Strict-Transport-Security "max-age=63072000" always;`,
+			msg:      "max-age",
+		},
+		{
+			options:  []GeneratorOption{WithHSTS(31536000, true, true)},
+			expected: `add_header Strict-Transport-Security "max-age=31536000; includeSubDomains; preload" always;`,
+			msg:      "includeSubDomains and preload",
+		},
+		{
+			options:  []GeneratorOption{WithSecurityHeaders(true), WithHSTS(600, true, false)},
+			expected: `add_header Strict-Transport-Security "max-age=600; includeSubDomains" always;`,
+			msg:      "hsts overrides the preset of the security headers",
+		},
+	}
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, test.options...)
+		cfg, _, _ := generator.Generate(context.Background(), conf)
+
+		httpBlock := getServerBlock(string(cfg), "listen 80;")
+		sslBlock := getServerBlock(string(cfg), "listen 443 ssl;")
+
+		if !strings.Contains(sslBlock, test.expected) {
+			t.Errorf("Generate() didn't generate %q in the SSL server for the case of %q; got:\n%s",
+				test.expected, test.msg, sslBlock)
+		}
+		if strings.Count(sslBlock, "Strict-Transport-Security") != 1 {
+			t.Errorf("Generate() didn't generate a single HSTS header in the SSL server for the case of %q; got:\n%s",
+				test.msg, sslBlock)
+		}
+		if strings.Contains(httpBlock, "Strict-Transport-Security") {
+			t.Errorf("Generate() generated HSTS in the HTTP server for the case of %q; got:\n%s", test.msg, httpBlock)
+		}
+
+		// the server files get the header from the same settings
+		files, _, _ := generator.GenerateFiles(context.Background(), conf)
+		for name, file := range files {
+			if strings.Contains(string(file), "listen 443 ssl;") && !strings.Contains(string(file), test.expected) {
+				t.Errorf("GenerateFiles() didn't generate %q in %s for the case of %q; got:\n%s",
+					test.expected, name, test.msg, string(file))
+			}
+		}
+	}
+}
+
+func TestGenerateOCSPStapling(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+		SSLServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				SSL: &state.SSL{
+					CertificatePath: "/etc/nginx/secrets/cafe",
+				},
+			},
+		},
+	}
+
+	stapling := []string{
+		"ssl_stapling on;",
+		"ssl_stapling_verify on;",
+		"ssl_trusted_certificate /etc/nginx/ca/ocsp.crt;",
+	}
+
+	// getServerBlock returns the server block that includes the text.
+	getServerBlock := func(cfg string, text string) string {
+		for _, block := range strings.Split(cfg, "server {") {
+			if strings.Contains(block, text) {
+				return block
+			}
+		}
+		return ""
+	}
+
+	generator := NewGeneratorImpl(
+		&statefakes.FakeServiceStore{},
+		WithOCSPStapling("/etc/nginx/ca/ocsp.crt"),
+		WithResolver([]string{"10.0.0.10"}),
+	)
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+
+	httpBlock := getServerBlock(string(cfg), "listen 80;")
+	sslBlock := getServerBlock(string(cfg), "listen 443 ssl;")
+
+	for _, d := range stapling {
+		if !strings.Contains(sslBlock, d) {
+			t.Errorf("Generate() didn't generate %q in the SSL server; got:\n%s", d, sslBlock)
+		}
+		if strings.Contains(httpBlock, d) {
+			t.Errorf("Generate() generated %q in the HTTP server; got:\n%s", d, httpBlock)
+		}
+	}
+
+	// NGINX resolves the OCSP responders with the resolver
+	if !strings.Contains(string(cfg), "resolver 10.0.0.10;") {
+		t.Errorf("Generate() didn't generate the resolver for OCSP stapling; got:\n%s", string(cfg))
+	}
+
+	cfg, _, _ = NewGeneratorImpl(&statefakes.FakeServiceStore{}).Generate(context.Background(), conf)
+	if strings.Contains(string(cfg), "ssl_stapling") {
+		t.Errorf("Generate() generated OCSP stapling without the option; got:\n%s", string(cfg))
+	}
+}
+
+func TestFilterAddressesByIPFamily(t *testing.T) {
+	addresses := []string{"10.0.0.1", "2001:db8::1", "not-an-ip"}
+
+	tests := []struct {
+		family   string
+		expected []string
+	}{
+		{
+			family:   IPFamilyIPv4,
+			expected: []string{"10.0.0.1"},
+		},
+		{
+			family:   IPFamilyIPv6,
+			expected: []string{"2001:db8::1"},
+		},
+		{
+			family:   IPFamilyDual,
+			expected: []string{"10.0.0.1", "2001:db8::1"},
+		},
+		{
+			family:   "",
+			expected: []string{"10.0.0.1"},
+		},
+	}
+
+	for _, test := range tests {
+		result := FilterAddressesByIPFamily(addresses, test.family)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("FilterAddressesByIPFamily() mismatch for the family %q (-want +got):\n%s", test.family, diff)
+		}
+	}
+}
+
+func TestGenerateListenAddresses(t *testing.T) {
+	tests := []struct {
+		family   string
+		ips      []string
+		expected []string
+		msg      string
+	}{
+		{
+			family:   IPFamilyIPv4,
+			expected: []string{"8080"},
+			msg:      "ipv4",
+		},
+		{
+			family:   IPFamilyIPv6,
+			expected: []string{"[::]:8080"},
+			msg:      "ipv6",
+		},
+		{
+			family:   IPFamilyDual,
+			expected: []string{"8080", "[::]:8080"},
+			msg:      "dual",
+		},
+		{
+			family:   "",
+			expected: []string{"8080"},
+			msg:      "empty family",
+		},
+		{
+			family:   IPFamilyIPv4,
+			ips:      []string{"10.0.0.1"},
+			expected: []string{"10.0.0.1:8080"},
+			msg:      "ipv4 address",
+		},
+		{
+			family:   IPFamilyDual,
+			ips:      []string{"10.0.0.1", "2001:db8::1"},
+			expected: []string{"10.0.0.1:8080", "[2001:db8::1]:8080"},
+			msg:      "ipv4 and ipv6 addresses",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateListenAddresses(8080, test.family, test.ips)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateListenAddresses() mismatch for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+// createUpstream creates the upstream of a rule with a single backend.
+func createUpstream(name, address string) upstream {
+	return upstream{
+		Name:      name,
+		Servers:   []upstreamServer{{Address: address}},
+		Keepalive: DefaultUpstreamKeepalive,
+		ZoneSize:  DefaultUpstreamZoneSize,
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Hostnames: []v1beta1.Hostname{
+				"cafe.example.com",
+			},
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+							Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodPost),
+						},
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+							Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodPatch),
+						},
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"), // should generate an "any" httpmatch since other matches exists for /
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name:      "service1",
+									Namespace: (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+									Port:      (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/test"),
+							},
+							Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodGet),
+							Headers: []v1beta1.HTTPHeaderMatch{
+								{
+									Type:  helpers.GetHeaderMatchTypePointer(v1beta1.HeaderMatchExact),
+									Name:  "Version",
+									Value: "V1",
+								},
+								{
+									Type:  helpers.GetHeaderMatchTypePointer(v1beta1.HeaderMatchExact),
+									Name:  "test",
+									Value: "foo",
+								},
+								{
+									Type:  helpers.GetHeaderMatchTypePointer(v1beta1.HeaderMatchExact),
+									Name:  "my-header",
+									Value: "my-value",
+								},
+							},
+							QueryParams: []v1beta1.HTTPQueryParamMatch{
+								{
+									Type:  helpers.GetQueryParamMatchTypePointer(v1beta1.QueryParamMatchExact),
+									Name:  "GrEat", // query names and values should not be normalized to lowercase
+									Value: "EXAMPLE",
+								},
+								{
+									Type:  helpers.GetQueryParamMatchTypePointer(v1beta1.QueryParamMatchExact),
+									Name:  "test",
+									Value: "foo=bar",
+								},
+							},
+						},
+					},
+					BackendRefs: nil, // no backend refs will cause warnings
+				},
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/path-only"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name:      "service2",
+									Namespace: (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+									Port:      (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	certPath := "/etc/nginx/secrets/cert.crt"
+	keyPath := "/etc/nginx/secrets/cert.key"
+
+	httpHost := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+					{
+						MatchIdx: 1,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+					{
+						MatchIdx: 2,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+				},
+			},
+			{
+				Path: "/test",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  1,
+						Source:   hr,
+					},
+				},
+			},
+			{
+				Path: "/path-only",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  2,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	httpsHost := httpHost
+	httpsHost.SSL = &state.SSL{CertificatePath: certPath, KeyPath: keyPath}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	expectedMatchString := func(m []httpMatch) string {
+		b, err := json.Marshal(m)
+		if err != nil {
+			t.Errorf("error marshaling test match: %v", err)
+		}
+		return string(b)
+	}
+
+	slashMatches := []httpMatch{
+		{Method: v1beta1.HTTPMethodPost, RedirectPath: "/_route0"},
+		{Method: v1beta1.HTTPMethodPatch, RedirectPath: "/_route1"},
+		{Any: true, RedirectPath: "/_route2"},
+	}
+	testMatches := []httpMatch{
+		{
+			Method:       v1beta1.HTTPMethodGet,
+			Headers:      []string{"Version:V1", "test:foo", "my-header:my-value"},
+			QueryParams:  []string{"GrEat=EXAMPLE", "test=foo=bar"},
+			RedirectPath: "/test_route0",
+		},
+	}
+
+	const backendAddr = "http://test_route1_rule0"
+
+	expectedHTTPServer := server{
+		ServerName: "example.com",
+		Locations: []location{
+			{
+				Path:      "/_route0",
+				Internal:  true,
+				ProxyPass: backendAddr,
+			},
+			{
+				Path:      "/_route1",
+				Internal:  true,
+				ProxyPass: backendAddr,
+			},
+			{
+				Path:      "/_route2",
+				Internal:  true,
+				ProxyPass: backendAddr,
+			},
+			{
+				Path:         "/",
+				HTTPMatchVar: expectedMatchString(slashMatches),
+			},
+			{
+				Path:      "/test_route0",
+				Internal:  true,
+				ProxyPass: "http://" + fallbackUpstreamName,
+			},
+			{
+				Path:         "/test",
+				HTTPMatchVar: expectedMatchString(testMatches),
+			},
+			{
+				Path:      "/path-only",
+				ProxyPass: "http://test_route1_rule2",
+			},
+		},
+	}
+
+	// the upstream of a rule is returned for every match of the rule
+	rule0Upstream := createUpstream("test_route1_rule0", "10.0.0.1:80")
+	expectedUpstreams := []upstream{
+		rule0Upstream,
+		rule0Upstream,
+		rule0Upstream,
+		createUpstream("test_route1_rule2", "10.0.0.1:80"),
+	}
+
+	expectedHTTPSServer := expectedHTTPServer
+	expectedHTTPSServer.SSL = &ssl{Certificate: certPath, CertificateKey: keyPath}
+
+	expectedWarnings := Warnings{
+		hr: []string{"empty backend refs"},
+	}
+
+	testcases := []struct {
+		host        state.VirtualServer
+		expWarnings Warnings
+		expResult   server
+		msg         string
+	}{
+		{
+			host:        httpHost,
+			expWarnings: expectedWarnings,
+			expResult:   expectedHTTPServer,
+			msg:         "http server",
+		},
+		{
+			host:        httpsHost,
+			expWarnings: expectedWarnings,
+			expResult:   expectedHTTPSServer,
+			msg:         "https server",
+		},
+	}
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	for _, tc := range testcases {
+		result, upstreams, warnings := generator.generate(context.Background(), tc.host, make(state.BackendResolutions))
+
+		if diff := cmp.Diff(tc.expResult, result); diff != "" {
+			t.Errorf("generate() mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(tc.expWarnings, warnings); diff != "" {
+			t.Errorf("generate() mismatch on warnings (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(expectedUpstreams, upstreams); diff != "" {
+			t.Errorf("generate() mismatch on upstreams (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestGenerateHeaderMatchesForSamePath(t *testing.T) {
+	createRule := func(version string) v1beta1.HTTPRouteRule {
+		return v1beta1.HTTPRouteRule{
+			Matches: []v1beta1.HTTPRouteMatch{
+				{
+					Path: &v1beta1.HTTPPathMatch{
+						Value: helpers.GetStringPointer("/api"),
+					},
+					Headers: []v1beta1.HTTPHeaderMatch{
+						{
+							Name:  "X-Ver",
+							Value: version,
+						},
+					},
+				},
+			},
+			BackendRefs: []v1beta1.HTTPBackendRef{
+				{
+					BackendRef: v1beta1.BackendRef{
+						BackendObjectReference: v1beta1.BackendObjectReference{
+							Name: v1beta1.ObjectName("service-v" + version),
+							Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				createRule("1"),
+				createRule("2"),
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/api",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+					{
+						MatchIdx: 0,
+						RuleIdx:  1,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveCalls(func(_ context.Context, nsname types.NamespacedName, _ int32) (string, error) {
+		if nsname.Name == "service-v1" {
+			return "10.0.0.1", nil
+		}
+		return "10.0.0.2", nil
+	})
+
+	expectedMatches := []httpMatch{
+		{
+			Headers:      []string{"X-Ver:1"},
+			RedirectPath: "/api_route0",
+		},
+		{
+			Headers:      []string{"X-Ver:2"},
+			RedirectPath: "/api_route1",
+		},
+	}
+
+	matchVar, err := json.Marshal(expectedMatches)
+	if err != nil {
+		t.Fatalf("failed to marshal the expected matches: %v", err)
+	}
+
+	expectedLocations := []location{
+		{
+			Path:      "/api_route0",
+			ProxyPass: "http://test_route1_rule0",
+			Internal:  true,
+		},
+		{
+			Path:      "/api_route1",
+			ProxyPass: "http://test_route1_rule1",
+			Internal:  true,
+		},
+		{
+			Path:         "/api",
+			HTTPMatchVar: string(matchVar),
+		},
+	}
+
+	expectedUpstreams := []upstream{
+		createUpstream("test_route1_rule0", "10.0.0.1:80"),
+		createUpstream("test_route1_rule1", "10.0.0.2:80"),
+	}
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+	result, upstreams, warnings := generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+	if diff := cmp.Diff(expectedLocations, result.Locations); diff != "" {
+		t.Errorf("generate() mismatch on locations (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedUpstreams, upstreams); diff != "" {
+		t.Errorf("generate() mismatch on upstreams (-want +got):\n%s", diff)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("generate() returned unexpected warnings: %v", warnings)
+	}
+}
+
+func TestGenerateMethodOnlyMatch(t *testing.T) {
+	backendRefs := []v1beta1.HTTPBackendRef{
+		{
+			BackendRef: v1beta1.BackendRef{
+				BackendObjectReference: v1beta1.BackendObjectReference{
+					Name: "service1",
+					Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+				},
+			},
+		},
+	}
+
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/method"),
+							},
+							Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodPost),
+						},
+					},
+					BackendRefs: backendRefs,
+				},
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/combined"),
+							},
+							Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodPost),
+							Headers: []v1beta1.HTTPHeaderMatch{
+								{
+									Name:  "version",
+									Value: "v2",
+								},
+							},
+						},
+					},
+					BackendRefs: backendRefs,
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/combined",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  1,
+						Source:   hr,
+					},
+				},
+			},
+			{
+				Path: "/method",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	combinedMatches, err := json.Marshal([]httpMatch{
+		{
+			Method:       v1beta1.HTTPMethodPost,
+			Headers:      []string{"version:v2"},
+			RedirectPath: "/combined_route0",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal the expected matches: %v", err)
+	}
+
+	expectedLocations := []location{
+		{
+			Path:      "/combined_route0",
+			ProxyPass: "http://test_route1_rule1",
+			Internal:  true,
+		},
+		{
+			Path:         "/combined",
+			HTTPMatchVar: string(combinedMatches),
+		},
+		{
+			Path:      "/method",
+			ProxyPass: "http://test_route1_rule0",
+			Method:    "POST",
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	result, _, warnings := generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+	if diff := cmp.Diff(expectedLocations, result.Locations); diff != "" {
+		t.Errorf("generate() mismatch on locations (-want +got):\n%s", diff)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("generate() returned unexpected warnings: %v", warnings)
+	}
+
+	cfg, _, _ := generator.Generate(context.Background(), state.Configuration{HTTPServers: []state.VirtualServer{host}})
+
+	expected := "if ($request_method != POST) {\n\t\t\treturn 404;\n\t\t}"
+	if !strings.Contains(string(cfg), expected) {
+		t.Errorf("Generate() didn't generate %q; got:\n%s", expected, string(cfg))
+	}
+	if strings.Contains(string(cfg), "/method_route") {
+		t.Errorf("Generate() generated an internal location for the method-only match; got:\n%s", string(cfg))
+	}
+}
+
+func TestGenerateCaseInsensitiveHeaders(t *testing.T) {
+	createRoute := func(annotations map[string]string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        "route1",
+				Annotations: annotations,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer("/"),
+								},
+								Headers: []v1beta1.HTTPHeaderMatch{
+									{
+										Name:  "X-Token",
+										Value: "Beta",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	createHost := func(hr *v1beta1.HTTPRoute) state.VirtualServer {
+		return state.VirtualServer{
+			Hostname: "example.com",
+			PathRules: []state.PathRule{
+				{
+					Path: "/",
+					MatchRules: []state.MatchRule{
+						{
+							MatchIdx: 0,
+							RuleIdx:  0,
+							Source:   hr,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		hr       *v1beta1.HTTPRoute
+		expected httpMatch
+		msg      string
+	}{
+		{
+			hr: createRoute(nil),
+			expected: httpMatch{
+				Headers:      []string{"X-Token:Beta"},
+				RedirectPath: "/_route0",
+			},
+			msg: "case-sensitive by default",
+		},
+		{
+			hr: createRoute(map[string]string{caseInsensitiveHeadersAnnotation: "true"}),
+			expected: httpMatch{
+				Headers:                []string{"X-Token:Beta"},
+				CaseInsensitiveHeaders: true,
+				RedirectPath:           "/_route0",
+			},
+			msg: "case-insensitive",
+		},
+	}
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(&statefakes.FakeServiceStore{})
+
+		result, _, _ := generator.generate(context.Background(), createHost(test.hr), make(state.BackendResolutions))
+
+		if l := len(result.Locations); l != 2 {
+			t.Fatalf("generate() returned %d locations but expected 2 for the case of %q", l, test.msg)
+		}
+
+		var matches []httpMatch
+		if err := json.Unmarshal([]byte(result.Locations[1].HTTPMatchVar), &matches); err != nil {
+			t.Fatalf("failed to unmarshal the matches for the case of %q: %v", test.msg, err)
+		}
+
+		if diff := cmp.Diff([]httpMatch{test.expected}, matches); diff != "" {
+			t.Errorf("generate() mismatch on matches for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestGenerateDuplicateMatchConditions(t *testing.T) {
+	backendRefs := []v1beta1.HTTPBackendRef{
+		{
+			BackendRef: v1beta1.BackendRef{
+				BackendObjectReference: v1beta1.BackendObjectReference{
+					Name: "service1",
+					Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+				},
+			},
+		},
+	}
+
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+							Headers: []v1beta1.HTTPHeaderMatch{
+								{
+									Name:  "version",
+									Value: "v1",
+								},
+								{
+									Name:  "Version",
+									Value: "v2",
+								},
+							},
+							QueryParams: []v1beta1.HTTPQueryParamMatch{
+								{
+									Name:  "debug",
+									Value: "true",
+								},
+								{
+									Name:  "debug",
+									Value: "false",
+								},
+							},
+						},
+					},
+					BackendRefs: backendRefs,
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	expectedMatches := []httpMatch{
+		{
+			Headers:      []string{"version:v1"},
+			QueryParams:  []string{"debug=true"},
+			RedirectPath: "/_route0",
+		},
+	}
+
+	expectedWarnings := Warnings{
+		hr: {
+			`match 0 of rule 0 for the path /: the header Version is duplicated; only its first value is used, ` +
+				`the value "v2" is ignored`,
+			`match 0 of rule 0 for the path /: the query parameter debug is duplicated; only its first value is used, ` +
+				`the value "false" is ignored`,
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	result, _, warnings := NewGeneratorImpl(fakeServiceStore).generate(
+		context.Background(),
+		host,
+		make(state.BackendResolutions),
+	)
+
+	if l := len(result.Locations); l != 2 {
+		t.Fatalf("generate() returned %d locations but expected 2", l)
+	}
+
+	var matches []httpMatch
+	if err := json.Unmarshal([]byte(result.Locations[1].HTTPMatchVar), &matches); err != nil {
+		t.Fatalf("failed to unmarshal the matches: %v", err)
+	}
+
+	if diff := cmp.Diff(expectedMatches, matches); diff != "" {
+		t.Errorf("generate() mismatch on matches (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+		t.Errorf("generate() mismatch on warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateStreamingRoute(t *testing.T) {
+	createRoute := func(name string, path string, annotations map[string]string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        name,
+				Annotations: annotations,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Hostnames: []v1beta1.Hostname{
+					"cafe.example.com",
+				},
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer(path),
+								},
+							},
+						},
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							{
+								BackendRef: v1beta1.BackendRef{
+									BackendObjectReference: v1beta1.BackendObjectReference{
+										Name: "service1",
+										Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	streamingHR := createRoute("streaming", "/events", map[string]string{streamingAnnotation: "true"})
+	regularHR := createRoute("regular", "/", nil)
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   regularHR,
+							},
+						},
+					},
+					{
+						Path: "/events",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   streamingHR,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	cfg, warnings, _ := generator.Generate(context.Background(), conf)
+
+	if len(warnings) > 0 {
+		t.Errorf("Generate() returned unexpected warnings: %v", warnings)
+	}
+
+	for _, directive := range []string{"chunked_transfer_encoding on;", "proxy_buffering off;"} {
+		// only the location of the streaming route must include the directive
+		if count := strings.Count(string(cfg), directive); count != 1 {
+			t.Errorf("Generate() generated %q %d times but expected 1; got:\n%s", directive, count, string(cfg))
+		}
+	}
+
+	streamingLocIdx := strings.Index(string(cfg), "location /events {")
+	if streamingLocIdx == -1 {
+		t.Fatalf("Generate() didn't generate the location of the streaming route; got:\n%s", string(cfg))
+	}
+
+	if !strings.Contains(string(cfg[streamingLocIdx:]), "chunked_transfer_encoding on;") {
+		t.Errorf("Generate() didn't generate the streaming directives in the location of the streaming route")
+	}
+}
+
+func TestGenerateProxyTimeouts(t *testing.T) {
+	createRoute := func(name string, annotations map[string]string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        name,
+				Annotations: annotations,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer("/" + name),
+								},
+							},
+						},
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							{
+								BackendRef: v1beta1.BackendRef{
+									BackendObjectReference: v1beta1.BackendObjectReference{
+										Name: "service1",
+										Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	regularHR := createRoute("regular", nil)
+	overrideHR := createRoute("override", map[string]string{
+		proxyReadTimeoutAnnotation: "5m",
+		proxySendTimeoutAnnotation: "1500ms",
+	})
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/override",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   overrideHR,
+					},
+				},
+			},
+			{
+				Path: "/regular",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   regularHR,
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		options  []GeneratorOption
+		expected []location
+		msg      string
+	}{
+		{
+			options: nil,
+			expected: []location{
+				{
+					Path:             "/override",
+					ProxyPass:        "http://test_override_rule0",
+					ProxyReadTimeout: "300s",
+					ProxySendTimeout: "1500ms",
+				},
+				{
+					Path:      "/regular",
+					ProxyPass: "http://test_regular_rule0",
+				},
+			},
+			msg: "no defaults",
+		},
+		{
+			options: []GeneratorOption{WithProxyTimeouts(5*time.Second, 60*time.Second, 30*time.Second)},
+			expected: []location{
+				{
+					Path:                "/override",
+					ProxyPass:           "http://test_override_rule0",
+					ProxyConnectTimeout: "5s",
+					ProxyReadTimeout:    "300s",
+					ProxySendTimeout:    "1500ms",
+				},
+				{
+					Path:                "/regular",
+					ProxyPass:           "http://test_regular_rule0",
+					ProxyConnectTimeout: "5s",
+					ProxyReadTimeout:    "60s",
+					ProxySendTimeout:    "30s",
+				},
+			},
+			msg: "defaults",
+		},
+	}
+
+	for _, test := range tests {
+		fakeServiceStore := &statefakes.FakeServiceStore{}
+		fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+		generator := NewGeneratorImpl(fakeServiceStore, test.options...)
+
+		result, _, warnings := generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+		if diff := cmp.Diff(test.expected, result.Locations); diff != "" {
+			t.Errorf("generate() mismatch on locations for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+		if len(warnings) != 0 {
+			t.Errorf("generate() returned unexpected warnings for the case of %q: %v", test.msg, warnings)
+		}
+	}
+
+	generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, WithProxyTimeouts(5*time.Second, 0, 0))
+
+	cfg, _, _ := generator.Generate(context.Background(), state.Configuration{HTTPServers: []state.VirtualServer{host}})
+
+	for _, directive := range []string{
+		"proxy_connect_timeout 5s;",
+		"proxy_read_timeout 300s;",
+		"proxy_send_timeout 1500ms;",
+	} {
+		if !strings.Contains(string(cfg), directive) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", directive, string(cfg))
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{duration: 0, expected: ""},
+		{duration: 5 * time.Second, expected: "5s"},
+		{duration: 2 * time.Minute, expected: "120s"},
+		{duration: 1500 * time.Millisecond, expected: "1500ms"},
+		{duration: time.Millisecond, expected: "1ms"},
+	}
+
+	for _, test := range tests {
+		result := formatDuration(test.duration)
+		if result != test.expected {
+			t.Errorf("formatDuration(%v) returned %q but expected %q", test.duration, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateRangeOptions(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "media",
+			Annotations: map[string]string{
+				forceRangesAnnotation: "true",
+				maxRangesAnnotation:   "1",
+			},
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Hostnames: []v1beta1.Hostname{
+				"cafe.example.com",
+			},
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/videos"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/videos",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	cfg, warnings, _ := generator.Generate(context.Background(), conf)
+
+	if len(warnings) > 0 {
+		t.Errorf("Generate() returned unexpected warnings: %v", warnings)
+	}
+
+	for _, directive := range []string{"proxy_force_ranges on;", "max_ranges 1;"} {
+		if !strings.Contains(string(cfg), directive) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", directive, string(cfg))
+		}
+	}
+}
+
+func TestGenerateProxyBuffering(t *testing.T) {
+	createRoute := func(annotations map[string]string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        "route1",
+				Annotations: annotations,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer("/"),
+								},
+							},
+						},
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							{
+								BackendRef: v1beta1.BackendRef{
+									BackendObjectReference: v1beta1.BackendObjectReference{
+										Name: "service1",
+										Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		annotations map[string]string
+		expected    []string
+		notExpected []string
+		msg         string
+	}{
+		{
+			annotations: map[string]string{proxyBufferingAnnotation: "false"},
+			expected:    []string{"proxy_buffering off;"},
+			notExpected: []string{"proxy_buffers", "proxy_buffer_size"},
+			msg:         "buffering disabled",
+		},
+		{
+			annotations: map[string]string{
+				proxyBuffersAnnotation:    "16 8k",
+				proxyBufferSizeAnnotation: "16k",
+			},
+			expected:    []string{"proxy_buffers 16 8k;", "proxy_buffer_size 16k;"},
+			notExpected: []string{"proxy_buffering"},
+			msg:         "custom buffer sizes",
+		},
+		{
+			annotations: nil,
+			notExpected: []string{"proxy_buffering", "proxy_buffers", "proxy_buffer_size"},
+			msg:         "no annotations",
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	for _, test := range tests {
+		hr := createRoute(test.annotations)
+
+		conf := state.Configuration{
+			HTTPServers: []state.VirtualServer{
+				{
+					Hostname: "example.com",
+					PathRules: []state.PathRule{
+						{
+							Path: "/",
+							MatchRules: []state.MatchRule{
+								{
+									MatchIdx: 0,
+									RuleIdx:  0,
+									Source:   hr,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		cfg, warnings, _ := generator.Generate(context.Background(), conf)
+		if len(warnings) > 0 {
+			t.Errorf("Generate() returned unexpected warnings for the case of %q: %v", test.msg, warnings)
+		}
+
+		for _, e := range test.expected {
+			if !strings.Contains(string(cfg), e) {
+				t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", e, test.msg, string(cfg))
+			}
+		}
+		for _, e := range test.notExpected {
+			if strings.Contains(string(cfg), e) {
+				t.Errorf("Generate() generated %q for the case of %q; got:\n%s", e, test.msg, string(cfg))
+			}
+		}
+	}
+}
+
+func TestGenerateProxyNextUpstream(t *testing.T) {
+	createRoute := func(annotations map[string]string) *v1beta1.HTTPRoute {
+		createBackendRef := func(name string) v1beta1.HTTPBackendRef {
+			return v1beta1.HTTPBackendRef{
+				BackendRef: v1beta1.BackendRef{
+					BackendObjectReference: v1beta1.BackendObjectReference{
+						Name: v1beta1.ObjectName(name),
+						Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+					},
+				},
+			}
+		}
+
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        "route1",
+				Annotations: annotations,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer("/"),
+								},
+							},
+						},
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							createBackendRef("service1"),
+							createBackendRef("service2"),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		annotations map[string]string
+		expected    []string
+		notExpected []string
+		msg         string
+	}{
+		{
+			annotations: map[string]string{
+				proxyNextUpstreamAnnotation:        "error timeout http_502",
+				proxyNextUpstreamTriesAnnotation:   "3",
+				proxyNextUpstreamTimeoutAnnotation: "10s",
+			},
+			expected: []string{
+				"proxy_next_upstream error timeout http_502;",
+				"proxy_next_upstream_tries 3;",
+				"proxy_next_upstream_timeout 10s;",
+				"proxy_pass http://test_route1_rule0$request_uri;",
+			},
+			msg: "retries on error timeout http_502",
+		},
+		{
+			annotations: map[string]string{proxyNextUpstreamAnnotation: "off"},
+			expected:    []string{"proxy_next_upstream off;"},
+			notExpected: []string{"proxy_next_upstream_tries", "proxy_next_upstream_timeout"},
+			msg:         "retries disabled",
+		},
+		{
+			annotations: nil,
+			notExpected: []string{"proxy_next_upstream"},
+			msg:         "no annotations",
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1:80", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	for _, test := range tests {
+		hr := createRoute(test.annotations)
+
+		conf := state.Configuration{
+			HTTPServers: []state.VirtualServer{
+				{
+					Hostname: "example.com",
+					PathRules: []state.PathRule{
+						{
+							Path: "/",
+							MatchRules: []state.MatchRule{
+								{
+									MatchIdx: 0,
+									RuleIdx:  0,
+									Source:   hr,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		cfg, warnings, _ := generator.Generate(context.Background(), conf)
+		if len(warnings) > 0 {
+			t.Errorf("Generate() returned unexpected warnings for the case of %q: %v", test.msg, warnings)
+		}
+
+		for _, e := range test.expected {
+			if !strings.Contains(string(cfg), e) {
+				t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", e, test.msg, string(cfg))
+			}
+		}
+		for _, e := range test.notExpected {
+			if strings.Contains(string(cfg), e) {
+				t.Errorf("Generate() generated %q for the case of %q; got:\n%s", e, test.msg, string(cfg))
+			}
+		}
+	}
+}
+
+func TestGenerateSnippets(t *testing.T) {
+	createRoute := func(name string, annotations map[string]string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        name,
+				Annotations: annotations,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer("/" + name),
+								},
+							},
+						},
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							{
+								BackendRef: v1beta1.BackendRef{
+									BackendObjectReference: v1beta1.BackendObjectReference{
+										Name: "service1",
+										Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// the routes are in the reverse order of their names to ensure the server snippets are sorted
+	hrB := createRoute("b", map[string]string{
+		locationSnippetsAnnotation: "proxy_set_header X-Env prod;\nproxy_set_header X-Team b;",
+		serverSnippetsAnnotation:   "add_header X-Route b;",
+	})
+	hrA := createRoute("a", map[string]string{
+		serverSnippetsAnnotation: "add_header X-Route a;",
+	})
+
+	createPathRule := func(hr *v1beta1.HTTPRoute) state.PathRule {
+		return state.PathRule{
+			Path: "/" + hr.Name,
+			MatchRules: []state.MatchRule{
+				{
+					MatchIdx: 0,
+					RuleIdx:  0,
+					Source:   hr,
+				},
+			},
+		}
+	}
+
+	host := state.VirtualServer{
+		Hostname:  "example.com",
+		PathRules: []state.PathRule{createPathRule(hrB), createPathRule(hrA)},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1:80", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore, WithSnippets(true))
+
+	result, _, warnings := generator.generate(context.Background(), host, make(state.BackendResolutions))
+	if len(warnings) != 0 {
+		t.Errorf("generate() returned unexpected warnings: %v", warnings)
+	}
+
+	if diff := cmp.Diff([]string{"add_header X-Route a;", "add_header X-Route b;"}, result.Snippets); diff != "" {
+		t.Errorf("generate() mismatch on server snippets (-want +got):\n%s", diff)
+	}
+
+	expectedLocationSnippets := [][]string{
+		{"proxy_set_header X-Env prod;", "proxy_set_header X-Team b;"},
+		nil,
+	}
+	for i, loc := range result.Locations {
+		if diff := cmp.Diff(expectedLocationSnippets[i], loc.Snippets); diff != "" {
+			t.Errorf("generate() mismatch on snippets of the location %s (-want +got):\n%s", loc.Path, diff)
+		}
+	}
+
+	cfg, _, _ := generator.Generate(context.Background(), state.Configuration{HTTPServers: []state.VirtualServer{host}})
+
+	// the server snippets precede the locations and the location snippets follow proxy_pass
+	expectedInOrder := []string{
+		"add_header X-Route a;",
+		"add_header X-Route b;",
+		"location /b {",
+		"proxy_pass http://test_b_rule0$request_uri;",
+		"proxy_set_header X-Env prod;",
+		"proxy_set_header X-Team b;",
+		"}",
+		"location /a {",
+	}
+	rest := string(cfg)
+	for _, expected := range expectedInOrder {
+		idx := strings.Index(rest, expected)
+		if idx == -1 {
+			t.Fatalf("Generate() didn't generate %q in the expected order; got:\n%s", expected, string(cfg))
+		}
+		rest = rest[idx+len(expected):]
+	}
+
+	// the snippets are disabled by default
+	generator = NewGeneratorImpl(fakeServiceStore)
+
+	result, _, warnings = generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+	expectedWarnings := Warnings{
+		hrA: []string{
+			"annotation gateway.nginx.org/server-snippets is ignored: the snippets are not allowed",
+		},
+		hrB: []string{
+			"annotation gateway.nginx.org/location-snippets is ignored: the snippets are not allowed",
+			"annotation gateway.nginx.org/server-snippets is ignored: the snippets are not allowed",
+		},
+	}
+	if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+		t.Errorf("generate() mismatch on warnings (-want +got):\n%s", diff)
+	}
+
+	if result.Snippets != nil {
+		t.Errorf("generate() generated server snippets when they are not allowed: %v", result.Snippets)
+	}
+	for _, loc := range result.Locations {
+		if loc.Snippets != nil {
+			t.Errorf("generate() generated snippets of the location %s when they are not allowed: %v",
+				loc.Path, loc.Snippets)
+		}
+	}
+}
+
+func TestGenerateCORS(t *testing.T) {
+	getMethod := v1beta1.HTTPMethodGet
+	backendRefs := []v1beta1.HTTPBackendRef{
+		{
+			BackendRef: v1beta1.BackendRef{
+				BackendObjectReference: v1beta1.BackendObjectReference{
+					Name: "service1",
+					Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+				},
+			},
+		},
+	}
+
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+			Annotations: map[string]string{
+				corsAllowOriginAnnotation:      "https://example.com",
+				corsAllowCredentialsAnnotation: "true",
+			},
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: backendRefs,
+				},
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/api"),
+							},
+							Method: &getMethod,
+						},
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/api"),
+							},
+							Headers: []v1beta1.HTTPHeaderMatch{
+								{
+									Name:  "version",
+									Value: "v2",
+								},
+							},
+						},
+					},
+					BackendRefs: backendRefs,
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+					{
+						Path: "/api",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  1,
+								Source:   hr,
+							},
+							{
+								MatchIdx: 1,
+								RuleIdx:  1,
+								Source:   hr,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	preflight := []string{
+		"if ($request_method = OPTIONS) {",
+		`add_header Access-Control-Allow-Origin "https://example.com";`,
+		`add_header Access-Control-Allow-Methods "GET, PUT, POST, DELETE, PATCH, OPTIONS";`,
+		`add_header Access-Control-Allow-Headers "Authorization, Content-Type";`,
+		"add_header Access-Control-Allow-Credentials true;",
+		"add_header Access-Control-Max-Age 86400;",
+		"return 204;",
+	}
+	responseHeaders := []string{
+		`add_header Access-Control-Allow-Origin "https://example.com" always;`,
+		"add_header Access-Control-Allow-Credentials true always;",
+		"add_header X-Content-Type-Options nosniff always;",
+	}
+
+	// getLocationBlock returns the location block of the path.
+	getLocationBlock := func(cfg string, path string) string {
+		for _, block := range strings.Split(cfg, "location ") {
+			if strings.HasPrefix(block, path+" {") {
+				return block
+			}
+		}
+		return ""
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore, WithSecurityHeaders(true))
+
+	cfg, warnings, err := generator.Generate(context.Background(), conf)
+	if err != nil {
+		t.Fatalf("Generate() returned unexpected error %v", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("Generate() returned unexpected warnings: %v", warnings)
+	}
+
+	tests := []struct {
+		path         string
+		expPreflight bool
+		msg          string
+	}{
+		{
+			path:         "/",
+			expPreflight: true,
+			msg:          "standard location",
+		},
+		{
+			path:         "/api",
+			expPreflight: true,
+			msg:          "location of the path with matches",
+		},
+		{
+			path:         "/api_route0",
+			expPreflight: false,
+			msg:          "internal location of a match",
+		},
+	}
+
+	for _, test := range tests {
+		block := getLocationBlock(string(cfg), test.path)
+		if block == "" {
+			t.Fatalf("Generate() didn't generate the location %s; got:\n%s", test.path, string(cfg))
+		}
+
+		for _, e := range responseHeaders {
+			if !strings.Contains(block, e) {
+				t.Errorf("Generate() didn't generate %q in the %s; got:\n%s", e, test.msg, block)
+			}
+		}
+
+		for _, e := range preflight {
+			if strings.Contains(block, e) != test.expPreflight {
+				t.Errorf("Generate() mismatch on %q in the %s, expected the preflight: %t; got:\n%s",
+					e, test.msg, test.expPreflight, block)
+			}
+		}
+	}
+}
+
+func TestGenerateProxyHTTPVersion(t *testing.T) {
+	createRoute := func(name, path string, annotations map[string]string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        name,
+				Annotations: annotations,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer(path),
+								},
+							},
+						},
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							{
+								BackendRef: v1beta1.BackendRef{
+									BackendObjectReference: v1beta1.BackendObjectReference{
+										Name: "service1",
+										Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	legacyHR := createRoute("legacy", "/legacy", map[string]string{proxyHTTPVersionAnnotation: "1.0"})
+	hr := createRoute("modern", "/", nil)
+
+	createPathRule := func(path string, hr *v1beta1.HTTPRoute) state.PathRule {
+		return state.PathRule{
+			Path: path,
+			MatchRules: []state.MatchRule{
+				{
+					MatchIdx: 0,
+					RuleIdx:  0,
+					Source:   hr,
+				},
+			},
+		}
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
+					createPathRule("/", hr),
+					createPathRule("/legacy", legacyHR),
+				},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	cfg, warnings, _ := generator.Generate(context.Background(), conf)
+
+	if len(warnings) > 0 {
+		t.Errorf("Generate() returned unexpected warnings: %v", warnings)
+	}
+
+	// getLocationBlock returns the location block for the path.
+	getLocationBlock := func(path string) string {
+		for _, block := range strings.Split(string(cfg), "location ") {
+			if strings.HasPrefix(block, path+" {") {
+				return block
+			}
+		}
+		return ""
+	}
+
+	legacyLoc := getLocationBlock("/legacy")
+	if !strings.Contains(legacyLoc, "proxy_http_version 1.0;") {
+		t.Errorf("Generate() didn't generate proxy_http_version 1.0 for the legacy route; got:\n%s", legacyLoc)
+	}
+	// HTTP/1.0 doesn't support keepalive connections to the backends
+	if strings.Contains(legacyLoc, `proxy_set_header Connection "";`) {
+		t.Errorf("Generate() enabled keepalive connections for the legacy route; got:\n%s", legacyLoc)
+	}
+
+	loc := getLocationBlock("/")
+	if loc == "" {
+		t.Fatalf("Generate() didn't generate the location for /; got:\n%s", string(cfg))
+	}
+	// HTTP/1.1 with keepalive connections is the default
+	for _, directive := range []string{"proxy_http_version 1.1;", `proxy_set_header Connection "";`} {
+		if !strings.Contains(loc, directive) {
+			t.Errorf("Generate() didn't generate %q for the route without the annotation; got:\n%s", directive, loc)
+		}
+	}
+}
+
+func TestGenerateWebSocket(t *testing.T) {
+	createRoute := func(name string, annotations map[string]string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        name,
+				Annotations: annotations,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer("/" + name),
+								},
+							},
+						},
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							{
+								BackendRef: v1beta1.BackendRef{
+									BackendObjectReference: v1beta1.BackendObjectReference{
+										Name: "service1",
+										Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	wsHR := createRoute("ws", map[string]string{webSocketAnnotation: "true"})
+	hr := createRoute("http", nil)
+
+	createPathRule := func(hr *v1beta1.HTTPRoute) state.PathRule {
+		return state.PathRule{
+			Path: *hr.Spec.Rules[0].Matches[0].Path.Value,
+			MatchRules: []state.MatchRule{
+				{
+					MatchIdx: 0,
+					RuleIdx:  0,
+					Source:   hr,
+				},
+			},
+		}
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname:  "cafe.example.com",
+				PathRules: []state.PathRule{createPathRule(wsHR), createPathRule(hr)},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	cfg, warnings, _ := NewGeneratorImpl(fakeServiceStore).Generate(context.Background(), conf)
+
+	if len(warnings) > 0 {
+		t.Errorf("Generate() returned unexpected warnings: %v", warnings)
+	}
+
+	// getLocationBlock returns the location block for the path.
+	getLocationBlock := func(path string) string {
+		for _, block := range strings.Split(string(cfg), "location ") {
+			if strings.HasPrefix(block, path+" {") {
+				return block
+			}
+		}
+		return ""
+	}
+
+	upgradeHeaders := []string{
+		"proxy_set_header Upgrade $http_upgrade;",
+		"proxy_set_header Connection $connection_upgrade;",
+	}
+
+	wsLoc := getLocationBlock("/ws")
+	for _, h := range upgradeHeaders {
+		if !strings.Contains(wsLoc, h) {
+			t.Errorf("Generate() didn't generate %q for the WebSocket route; got:\n%s", h, wsLoc)
+		}
+	}
+	if strings.Contains(wsLoc, `proxy_set_header Connection "";`) {
+		t.Errorf("Generate() cleared the Connection header for the WebSocket route; got:\n%s", wsLoc)
+	}
+
+	loc := getLocationBlock("/http")
+	for _, h := range upgradeHeaders {
+		if strings.Contains(loc, h) {
+			t.Errorf("Generate() generated %q for the route without the annotation; got:\n%s", h, loc)
+		}
+	}
+	if !strings.Contains(loc, `proxy_set_header Connection "";`) {
+		t.Errorf("Generate() didn't clear the Connection header for the route without the annotation; got:\n%s", loc)
+	}
+
+	if !strings.Contains(string(cfg), "map $http_upgrade $connection_upgrade {") {
+		t.Errorf("Generate() didn't generate the map of the Connection header; got:\n%s", string(cfg))
+	}
+}
+
+func TestGenerateWebSocketMatchLocations(t *testing.T) {
+	createRoute := func(annotations map[string]string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        "route1",
+				Annotations: annotations,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer("/chat"),
+								},
+								Headers: []v1beta1.HTTPHeaderMatch{
+									{
+										Type:  helpers.GetHeaderMatchTypePointer(v1beta1.HeaderMatchExact),
+										Name:  "Version",
+										Value: "V2",
+									},
+								},
+							},
+						},
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							{
+								BackendRef: v1beta1.BackendRef{
+									BackendObjectReference: v1beta1.BackendObjectReference{
+										Name: "service1",
+										Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		hr        *v1beta1.HTTPRoute
+		webSocket bool
+		msg       string
+	}{
+		{
+			hr:        createRoute(map[string]string{webSocketAnnotation: "true"}),
+			webSocket: true,
+			msg:       "websocket route",
+		},
+		{
+			hr:        createRoute(nil),
+			webSocket: false,
+			msg:       "route without the annotation",
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	for _, test := range tests {
+		host := state.VirtualServer{
+			Hostname: "example.com",
+			PathRules: []state.PathRule{
+				{
+					Path: "/chat",
+					MatchRules: []state.MatchRule{
+						{
+							MatchIdx: 0,
+							RuleIdx:  0,
+							Source:   test.hr,
+						},
+					},
+				},
+			},
+		}
+
+		result, _, _ := generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+		proxied := 0
+		for _, loc := range result.Locations {
+			if loc.ProxyPass == "" {
+				continue
+			}
+			proxied++
+
+			if !loc.Internal {
+				t.Errorf("generate() generated the location %s that is not internal for the case of %q", loc.Path, test.msg)
+			}
+			if loc.WebSocket != test.webSocket {
+				t.Errorf("generate() generated the location %s with WebSocket %t but expected %t for the case of %q",
+					loc.Path, loc.WebSocket, test.webSocket, test.msg)
+			}
+		}
+
+		if proxied != 1 {
+			t.Errorf("generate() generated %d proxied locations but expected 1 for the case of %q", proxied, test.msg)
+		}
+	}
+}
+
+func TestGenerateCacheConditions(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+			Annotations: map[string]string{
+				proxyCacheBypassAnnotation: "$cookie_session $http_authorization",
+				proxyNoCacheAnnotation:     "$http_authorization",
+			},
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	cfg, warnings, _ := NewGeneratorImpl(fakeServiceStore).Generate(context.Background(), conf)
+
+	if len(warnings) > 0 {
+		t.Errorf("Generate() returned unexpected warnings: %v", warnings)
+	}
+
+	for _, directive := range []string{
+		"proxy_cache_bypass $cookie_session $http_authorization;",
+		"proxy_no_cache $http_authorization;",
+	} {
+		if !strings.Contains(string(cfg), directive) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", directive, string(cfg))
+		}
+	}
+
+	hr.Annotations = nil
+
+	cfg, _, _ = NewGeneratorImpl(fakeServiceStore).Generate(context.Background(), conf)
+
+	for _, directive := range []string{"proxy_cache_bypass", "proxy_no_cache"} {
+		if strings.Contains(string(cfg), directive) {
+			t.Errorf("Generate() generated %q for the route without the annotations; got:\n%s", directive, string(cfg))
+		}
+	}
+}
+
+func TestGenerateBackendTLS(t *testing.T) {
+	createRoute := func(annotations map[string]string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        "route1",
+				Annotations: annotations,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer("/"),
+								},
+							},
+						},
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							{
+								BackendRef: v1beta1.BackendRef{
+									BackendObjectReference: v1beta1.BackendObjectReference{
+										Name: "service1",
+										Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(443)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	createHost := func(hr *v1beta1.HTTPRoute) state.VirtualServer {
+		return state.VirtualServer{
+			Hostname: "example.com",
+			PathRules: []state.PathRule{
+				{
+					Path: "/",
+					MatchRules: []state.MatchRule{
+						{
+							MatchIdx: 0,
+							RuleIdx:  0,
+							Source:   hr,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	plain := createRoute(nil)
+	noVerify := createRoute(map[string]string{
+		backendProtocolAnnotation: "https",
+	})
+	verify := createRoute(map[string]string{
+		backendProtocolAnnotation:  "https",
+		backendSSLVerifyAnnotation: "true",
+		backendSSLNameAnnotation:   "service1.test.svc",
+	})
+
+	const caCert = "/etc/nginx/secrets/backend-ca.crt"
+
+	tests := []struct {
+		hr               *v1beta1.HTTPRoute
+		options          []GeneratorOption
+		expectedLocation location
+		expectedWarnings Warnings
+		msg              string
+	}{
+		{
+			hr:      plain,
+			options: []GeneratorOption{WithBackendCACertificate(caCert)},
+			expectedLocation: location{
+				Path:      "/",
+				ProxyPass: "http://test_route1_rule0",
+			},
+			expectedWarnings: Warnings{},
+			msg:              "http backend",
+		},
+		{
+			hr:      noVerify,
+			options: []GeneratorOption{WithBackendCACertificate(caCert)},
+			expectedLocation: location{
+				Path:      "/",
+				ProxyPass: "https://test_route1_rule0",
+				ProxySSL:  &proxySSL{},
+			},
+			expectedWarnings: Warnings{},
+			msg:              "https backend without verification",
+		},
+		{
+			hr:      verify,
+			options: []GeneratorOption{WithBackendCACertificate(caCert)},
+			expectedLocation: location{
+				Path:      "/",
+				ProxyPass: "https://test_route1_rule0",
+				ProxySSL: &proxySSL{
+					Name:               "service1.test.svc",
+					TrustedCertificate: caCert,
+				},
+			},
+			expectedWarnings: Warnings{},
+			msg:              "https backend with verification",
+		},
+		{
+			hr: verify,
+			expectedLocation: location{
+				Path:      "/",
+				ProxyPass: "https://test_route1_rule0",
+				ProxySSL: &proxySSL{
+					Name: "service1.test.svc",
+				},
+			},
+			expectedWarnings: Warnings{
+				verify: []string{
+					"the verification of the HTTPS backends requires a CA certificate, which is not configured; " +
+						"the certificates of the backends are not verified",
+				},
+			},
+			msg: "https backend with verification but no ca certificate",
+		},
+	}
+
+	for _, test := range tests {
+		fakeServiceStore := &statefakes.FakeServiceStore{}
+		fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+		generator := NewGeneratorImpl(fakeServiceStore, test.options...)
+
+		result, _, warnings := generator.generate(context.Background(), createHost(test.hr), make(state.BackendResolutions))
+
+		if diff := cmp.Diff([]location{test.expectedLocation}, result.Locations); diff != "" {
+			t.Errorf("generate() mismatch on locations for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedWarnings, warnings); diff != "" {
+			t.Errorf("generate() mismatch on warnings for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore, WithBackendCACertificate(caCert))
+
+	conf := state.Configuration{HTTPServers: []state.VirtualServer{createHost(verify)}}
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+
+	directives := []string{
+		"proxy_pass https://test_route1_rule0$request_uri;",
+		"proxy_ssl_server_name on;",
+		"proxy_ssl_name service1.test.svc;",
+		"proxy_ssl_verify on;",
+		"proxy_ssl_trusted_certificate " + caCert + ";",
+	}
+	for _, d := range directives {
+		if !strings.Contains(string(cfg), d) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", d, string(cfg))
+		}
+	}
+}
+
+func TestGenerateRequestIDHeader(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Hostnames: []v1beta1.Hostname{
+				"cafe.example.com",
+			},
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		options   []GeneratorOption
+		expConfig []string
+		msg       string
+	}{
+		{
+			options: nil,
+			expConfig: []string{
+				"map $http_x_request_id $request_id_header {",
+				"default $http_x_request_id;",
+				"X-Request-ID=$request_id_header",
+				"proxy_set_header X-Request-ID $request_id_header;",
+			},
+			msg: "default header",
+		},
+		{
+			options: []GeneratorOption{WithRequestIDHeader("X-Correlation-ID")},
+			expConfig: []string{
+				"map $http_x_correlation_id $request_id_header {",
+				"default $http_x_correlation_id;",
+				"X-Correlation-ID=$request_id_header",
+				"proxy_set_header X-Correlation-ID $request_id_header;",
+			},
+			msg: "custom header",
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(fakeServiceStore, test.options...)
+
+		cfg, warnings, _ := generator.Generate(context.Background(), conf)
+
+		if len(warnings) > 0 {
+			t.Errorf("Generate() returned unexpected warnings for the case of %q: %v", test.msg, warnings)
+		}
+
+		for _, expected := range test.expConfig {
+			if !strings.Contains(string(cfg), expected) {
+				t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", expected, test.msg, string(cfg))
+			}
+		}
+	}
+}
+
+func TestGenerateForwardedHeaders(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	forwardedHeaders := []string{
+		"proxy_set_header Host $host;",
+		"proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;",
+		"proxy_set_header X-Forwarded-Proto $scheme;",
+		"proxy_set_header X-Real-IP $remote_addr;",
+	}
+
+	// getServerBlock returns the server block that includes the text.
+	getServerBlock := func(cfg string, text string) string {
+		for _, block := range strings.Split(cfg, "server {") {
+			if strings.Contains(block, text) {
+				return block
+			}
+		}
+		return ""
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	cfg, _, _ := NewGeneratorImpl(fakeServiceStore).Generate(context.Background(), conf)
+
+	serverBlock := getServerBlock(string(cfg), "server_name cafe.example.com;")
+	for _, h := range forwardedHeaders {
+		if !strings.Contains(serverBlock, h) {
+			t.Errorf("Generate() didn't generate %q in the location; got:\n%s", h, serverBlock)
+		}
+	}
+
+	fallbackBlock := getServerBlock(string(cfg), "return 502;")
+	if fallbackBlock == "" {
+		t.Fatalf("Generate() didn't generate the fallback server; got:\n%s", string(cfg))
+	}
+	if strings.Contains(fallbackBlock, "proxy_set_header") {
+		t.Errorf("Generate() generated headers in the fallback server; got:\n%s", fallbackBlock)
+	}
+
+	cfg, _, _ = NewGeneratorImpl(fakeServiceStore, WithForwardedHeaders(false)).Generate(context.Background(), conf)
+
+	for _, h := range forwardedHeaders[1:] {
+		if strings.Contains(string(cfg), h) {
+			t.Errorf("Generate() generated %q when the forwarded headers are disabled", h)
+		}
+	}
+	if !strings.Contains(string(cfg), forwardedHeaders[0]) {
+		t.Errorf("Generate() didn't generate %q when the forwarded headers are disabled", forwardedHeaders[0])
+	}
+}
+
+func TestGenerateTrustedProxies(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+	}
+
+	tests := []struct {
+		trustedProxies []string
+		expected       []string
+		notExpected    []string
+		msg            string
+	}{
+		{
+			trustedProxies: []string{"10.0.0.0/8", "192.168.1.0/24"},
+			expected: []string{
+				"set_real_ip_from 10.0.0.0/8;",
+				"set_real_ip_from 192.168.1.0/24;",
+				"real_ip_header X-Forwarded-For;",
+			},
+			msg: "trusted proxies",
+		},
+		{
+			trustedProxies: nil,
+			notExpected: []string{
+				"set_real_ip_from",
+				"real_ip_header",
+			},
+			msg: "no trusted proxies",
+		},
+	}
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, WithTrustedProxies(test.trustedProxies))
+
+		cfg, _, _ := generator.Generate(context.Background(), conf)
+
+		for _, e := range test.expected {
+			if !strings.Contains(string(cfg), e) {
+				t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", e, test.msg, string(cfg))
+			}
+		}
+		for _, e := range test.notExpected {
+			if strings.Contains(string(cfg), e) {
+				t.Errorf("Generate() generated %q for the case of %q; got:\n%s", e, test.msg, string(cfg))
+			}
+		}
+	}
+}
+
+func TestGenerateMain(t *testing.T) {
+	tests := []struct {
+		options  []GeneratorOption
+		expected []string
+		msg      string
+	}{
+		{
+			options: nil,
+			expected: []string{
+				"worker_processes auto;",
+				"worker_connections 1024;",
+			},
+			msg: "defaults",
+		},
+		{
+			options: []GeneratorOption{
+				WithWorkerProcesses("4"),
+				WithWorkerConnections(4096),
+			},
+			expected: []string{
+				"worker_processes 4;",
+				"worker_connections 4096;",
+			},
+			msg: "custom values",
+		},
+	}
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, test.options...)
+
+		cfg, err := generator.GenerateMain()
+		if err != nil {
+			t.Errorf("GenerateMain() returned unexpected error %v for the case of %q", err, test.msg)
+			continue
+		}
+
+		for _, e := range test.expected {
+			if !strings.Contains(string(cfg), e) {
+				t.Errorf("GenerateMain() didn't generate %q for the case of %q; got:\n%s", e, test.msg, string(cfg))
+			}
+		}
+	}
+}
+
+func TestGenerateWithoutNJS(t *testing.T) {
+	backendRefs := []v1beta1.HTTPBackendRef{
+		{
+			BackendRef: v1beta1.BackendRef{
+				BackendObjectReference: v1beta1.BackendObjectReference{
+					Name: "service1",
+					Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+				},
+			},
+		},
+	}
+
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+							Headers: []v1beta1.HTTPHeaderMatch{
+								{
+									Name:  "version",
+									Value: "v2",
+								},
+							},
+						},
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+							Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodGet),
+							Headers: []v1beta1.HTTPHeaderMatch{
+								{
+									Name:  "x.version",
+									Value: "v3",
+								},
+							},
+						},
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+							Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodPost),
+							QueryParams: []v1beta1.HTTPQueryParamMatch{
+								{
+									Name:  "version",
+									Value: "v4",
+								},
+							},
+						},
+					},
+					BackendRefs: backendRefs,
+				},
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/coffee"),
+							},
+							Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodPost),
+						},
+					},
+					BackendRefs: backendRefs,
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 2,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+					{
+						MatchIdx: 3,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+					{
+						MatchIdx: 1,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+				},
+			},
+			{
+				Path: "/coffee",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  1,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	expected := server{
+		ServerName: "example.com",
+		Locations: []location{
+			{
+				Path:      "/_route1",
+				ProxyPass: "http://test_route1_rule0",
+				Internal:  true,
+			},
+			{
+				Path:      "/_route2",
+				ProxyPass: "http://test_route1_rule0",
+				Internal:  true,
+			},
+			{
+				Path:      "/_route3",
+				ProxyPass: "http://test_route1_rule0",
+				Internal:  true,
+			},
+			{
+				Path: "/",
+				RewriteMatches: []rewriteMatch{
+					{
+						Conditions: []rewriteCondition{
+							{Variable: "$request_method", Value: "POST"},
+							{Variable: "$arg_version", Value: "v4"},
+						},
+						RedirectPath: "/_route1",
+					},
+					{
+						Conditions: []rewriteCondition{
+							{Variable: "$http_version", Value: "v2"},
+						},
+						RedirectPath: "/_route2",
+					},
+					{
+						RedirectPath: "/_route3",
+					},
+				},
+			},
+			{
+				Path:      "/coffee",
+				ProxyPass: "http://test_route1_rule1",
+				Method:    "POST",
+			},
+		},
+	}
+	expectedWarnings := Warnings{
+		hr: []string{
+			`match 2 of rule 0 for the path / requires the njs module, which is not available: the header name ` +
+				`"x.version" must only include letters, digits and -; the match is ignored`,
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore, WithNJSAvailable(false))
+
+	result, _, warnings := generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generate() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+		t.Errorf("generate() mismatch on warnings (-want +got):\n%s", diff)
+	}
+
+	cfg, _, _ := generator.Generate(context.Background(), state.Configuration{HTTPServers: []state.VirtualServer{host}})
+
+	// the matches are evaluated in the order of their precedence
+	expectedRewrites := []string{
+		"set $gateway_match 1;",
+		"if ($request_method != \"POST\") {\n\t\t\tset $gateway_match 0;\n\t\t}",
+		"if ($arg_version != \"v4\") {\n\t\t\tset $gateway_match 0;\n\t\t}",
+		"if ($gateway_match) {\n\t\t\trewrite ^ /_route1 last;\n\t\t}",
+		"if ($http_version != \"v2\") {\n\t\t\tset $gateway_match 0;\n\t\t}",
+		"if ($gateway_match) {\n\t\t\trewrite ^ /_route2 last;\n\t\t}",
+		"rewrite ^ /_route3 last;",
+		"return 404;",
+	}
+	rest := string(cfg)
+	for _, expected := range expectedRewrites {
+		idx := strings.Index(rest, expected)
+		if idx == -1 {
+			t.Errorf("Generate() didn't generate %q in order; got:\n%s", expected, string(cfg))
+			break
+		}
+		rest = rest[idx+len(expected):]
+	}
+	if strings.Contains(string(cfg), "js_content") {
+		t.Errorf("Generate() generated js_content without the njs module; got:\n%s", string(cfg))
+	}
+
+	// with the njs module, all matches are evaluated by the module
+	generator = NewGeneratorImpl(fakeServiceStore, WithNJSAvailable(true))
+
+	result, _, warnings = generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+	if len(warnings) != 0 {
+		t.Errorf("generate() returned unexpected warnings with the njs module: %v", warnings)
+	}
+	for _, loc := range result.Locations {
+		if loc.RewriteMatches != nil {
+			t.Errorf("generate() generated rewrite matches with the njs module for the location %s", loc.Path)
+		}
+	}
+	if pathLoc := result.Locations[4]; pathLoc.Path != "/" || pathLoc.HTTPMatchVar == "" {
+		t.Errorf("generate() didn't generate the HTTPMatchVar with the njs module; got:\n%+v", pathLoc)
+	}
+}
+
+func TestGenerateFiles(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	pathRules := []state.PathRule{
+		{
+			Path: "/",
+			MatchRules: []state.MatchRule{
+				{
+					MatchIdx: 0,
+					RuleIdx:  0,
+					Source:   hr,
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname:  "cafe.example.com",
+				PathRules: pathRules,
+			},
+			{
+				Hostname: "*.example.com",
+			},
+		},
+		SSLServers: []state.VirtualServer{
+			{
+				Hostname:  "cafe.example.com",
+				PathRules: pathRules,
+				SSL:       &state.SSL{CertificatePath: "/etc/nginx/secrets/secret"},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	singleFile, warnings, _ := generator.Generate(context.Background(), conf)
+	if len(warnings) > 0 {
+		t.Errorf("Generate() returned unexpected warnings: %v", warnings)
+	}
+
+	files, warnings, _ := generator.GenerateFiles(context.Background(), conf)
+	if len(warnings) > 0 {
+		t.Errorf("GenerateFiles() returned unexpected warnings: %v", warnings)
+	}
+
+	expectedNames := []string{
+		HTTPServersMainFile,
+		"servers/000-fallback.conf",
+		"servers/001-default-http.conf",
+		"servers/002-default-ssl.conf",
+		"servers/003-cafe.example.com.conf",
+		"servers/004-_.example.com.conf",
+		"servers/005-cafe.example.com-ssl.conf",
+	}
+
+	if len(files) != len(expectedNames) {
+		t.Errorf("GenerateFiles() returned %d files but expected %d", len(files), len(expectedNames))
+	}
+
+	for _, name := range expectedNames {
+		if _, exists := files[name]; !exists {
+			t.Errorf("GenerateFiles() didn't generate file %s", name)
+		}
+	}
+
+	// Replacing the include directives in the main file with the contents of the included files must result
+	// in the same configuration as the single-file one.
+	var combined strings.Builder
+
+	for _, line := range strings.Split(string(files[HTTPServersMainFile]), "\n") {
+		if !strings.HasPrefix(line, "include ") {
+			combined.WriteString(line + "\n")
+			continue
+		}
+
+		path := strings.TrimSuffix(strings.TrimPrefix(line, "include "), ";")
+		name := strings.TrimPrefix(path, httpConfigFolder+"/")
+
+		content, exists := files[name]
+		if !exists {
+			t.Fatalf("GenerateFiles() generated main file that includes a non-existing file %s", path)
+		}
+
+		combined.Write(content)
+	}
+
+	normalize := func(cfg string) string {
+		return strings.Join(strings.Fields(cfg), " ")
+	}
+
+	if normalize(combined.String()) != normalize(string(singleFile)) {
+		t.Errorf("GenerateFiles() generated configuration that is not consistent with Generate():\n%s\n\nvs\n\n%s",
+			combined.String(), string(singleFile))
+	}
+}
+
+func TestGenerateAndCompare(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "example.com",
+			},
+		},
+	}
+	changedConf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "example.com",
+			},
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+	}
+
+	generator := NewGeneratorImpl(&statefakes.FakeServiceStore{})
+
+	prev, _, _ := generator.Generate(context.Background(), conf)
+
+	tests := []struct {
+		prev       []byte
+		conf       state.Configuration
+		expChanged bool
+		msg        string
+	}{
+		{
+			prev:       nil,
+			conf:       conf,
+			expChanged: true,
+			msg:        "no previous configuration",
+		},
+		{
+			prev:       prev,
+			conf:       conf,
+			expChanged: false,
+			msg:        "unchanged",
+		},
+		{
+			prev:       prev,
+			conf:       changedConf,
+			expChanged: true,
+			msg:        "changed",
+		},
+	}
+
+	for _, test := range tests {
+		cfg, changed, _, _ := generator.GenerateAndCompare(context.Background(), test.prev, test.conf)
+
+		if changed != test.expChanged {
+			t.Errorf("GenerateAndCompare() returned changed %v but expected %v for the case of %q",
+				changed, test.expChanged, test.msg)
+		}
+
+		expectedCfg, _, _ := generator.Generate(context.Background(), test.conf)
+		if string(cfg) != string(expectedCfg) {
+			t.Errorf("GenerateAndCompare() returned configuration different from Generate() for the case of %q",
+				test.msg)
+		}
+	}
+}
+
+func TestGenerateTemplateError(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "example.com",
+			},
+		},
+	}
+
+	generator := NewGeneratorImpl(&statefakes.FakeServiceStore{})
+
+	// the templates reference a field that doesn't exist, so their execution fails
+	generator.executor = &templateExecutor{
+		httpServersTemplate: template.Must(template.New("server").Parse(
+			`{{ define "http-common" }}{{ .Missing }}{{ end }}{{ define "http-server-blocks" }}{{ .Missing }}{{ end }}` +
+				`{{ .Missing }}`,
+		)),
+		streamServersTemplate: template.Must(template.New("stream server").Parse(`{{ .Missing }}`)),
+		mainTemplate:          template.Must(template.New("main").Parse(`{{ .Missing }}`)),
+	}
+
+	cfg, _, err := generator.Generate(context.Background(), conf)
+	if err == nil {
+		t.Errorf("Generate() didn't return an error")
+	}
+	if cfg != nil {
+		t.Errorf("Generate() returned configuration %q but expected nil", string(cfg))
+	}
+
+	files, _, err := generator.GenerateFiles(context.Background(), conf)
+	if err == nil {
+		t.Errorf("GenerateFiles() didn't return an error")
+	}
+	if files != nil {
+		t.Errorf("GenerateFiles() returned files %v but expected nil", files)
+	}
+
+	cfg, changed, _, err := generator.GenerateAndCompare(context.Background(), nil, conf)
+	if err == nil {
+		t.Errorf("GenerateAndCompare() didn't return an error")
+	}
+	if cfg != nil || changed {
+		t.Errorf("GenerateAndCompare() returned configuration %q and changed %v but expected nil and false",
+			string(cfg), changed)
+	}
+
+	cfg, _, err = generator.GenerateStream(context.Background(), state.Configuration{})
+	if err == nil {
+		t.Errorf("GenerateStream() didn't return an error")
+	}
+	if cfg != nil {
+		t.Errorf("GenerateStream() returned configuration %q but expected nil", string(cfg))
+	}
+
+	cfg, err = generator.GenerateMain()
+	if err == nil {
+		t.Errorf("GenerateMain() didn't return an error")
+	}
+	if cfg != nil {
+		t.Errorf("GenerateMain() returned configuration %q but expected nil", string(cfg))
+	}
+}
+
+func TestGenerateMetrics(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	registry := prometheus.NewRegistry()
+	controllerMetrics := metrics.NewControllerMetrics()
+	if err := controllerMetrics.Register(registry); err != nil {
+		t.Fatalf("Register() returned unexpected error %v", err)
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("", errors.New("no cluster IP"))
+
+	generator := NewGeneratorImpl(fakeServiceStore, WithMetrics(controllerMetrics))
+
+	_, _, _ = generator.Generate(context.Background(), conf)
+
+	// fallback, default http and cafe.example.com servers
+	if result := testutil.ToFloat64(controllerMetrics.GeneratedServers); result != 3 {
+		t.Errorf("Generate() set the generated servers metric to %v but expected 3", result)
+	}
+
+	if result := testutil.ToFloat64(controllerMetrics.ResolutionFailures); result != 1 {
+		t.Errorf("Generate() set the resolution failures metric to %v but expected 1", result)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() returned unexpected error %v", err)
+	}
+
+	var durationCount uint64
+	for _, f := range families {
+		if f.GetName() == "nginx_kubernetes_gateway_config_generation_duration_seconds" {
+			durationCount = f.GetMetric()[0].GetHistogram().GetSampleCount()
+		}
+	}
+
+	if durationCount != 1 {
+		t.Errorf("Generate() observed the generation duration %d times but expected 1", durationCount)
+	}
+}
+
+func TestGenerateRequestID(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected requestID
+	}{
+		{
+			header: "X-Request-ID",
+			expected: requestID{
+				Header:    "X-Request-ID",
+				HeaderVar: "$http_x_request_id",
+			},
+		},
+		{
+			header: "traceparent",
+			expected: requestID{
+				Header:    "traceparent",
+				HeaderVar: "$http_traceparent",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		result := generateRequestID(test.header)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateRequestID() mismatch for %q (-want +got):\n%s", test.header, diff)
+		}
+	}
+}
+
+func TestGenerateWeightedBackends(t *testing.T) {
+	createRef := func(name string, weight *int32) v1beta1.HTTPBackendRef {
+		return v1beta1.HTTPBackendRef{
+			BackendRef: v1beta1.BackendRef{
+				BackendObjectReference: v1beta1.BackendObjectReference{
+					Name: v1beta1.ObjectName(name),
+					Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+				},
+				Weight: weight,
+			},
+		}
+	}
+
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						createRef("service1", helpers.GetInt32Pointer(80)),
+						createRef("service2", nil),
+					},
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	resolveAll := func(_ context.Context, nsname types.NamespacedName, _ int32) (string, error) {
+		if nsname.Name == "service1" {
+			return "10.0.0.1", nil
+		}
+		return "10.0.0.2", nil
+	}
+	resolveNone := func(_ context.Context, nsname types.NamespacedName, _ int32) (string, error) {
+		return "", errors.New("no cluster IP")
+	}
+
+	unresolvableWarnings := Warnings{
+		hr: []string{
+			"service test/service1 cannot be resolved: no cluster IP",
+			"service test/service2 cannot be resolved: no cluster IP",
+			"none of the 2 backends of rule 0 can be resolved",
+		},
+	}
+
+	tests := []struct {
+		resolve           func(context.Context, types.NamespacedName, int32) (string, error)
+		options           []GeneratorOption
+		expectedLocation  location
+		expectedUpstreams []upstream
+		expectedWarnings  Warnings
+		msg               string
+	}{
+		{
+			resolve: resolveAll,
+			expectedLocation: location{
+				Path:      "/",
+				ProxyPass: "http://test_route1_rule0",
+			},
+			expectedUpstreams: []upstream{
+				{
+					Name: "test_route1_rule0",
+					Servers: []upstreamServer{
+						{Address: "10.0.0.1:80", Weight: 80},
+						{Address: "10.0.0.2:80", Weight: 1},
+					},
+					Keepalive: DefaultUpstreamKeepalive,
+					ZoneSize:  DefaultUpstreamZoneSize,
+				},
+			},
+			expectedWarnings: Warnings{},
+			msg:              "all backends are resolved",
+		},
+		{
+			resolve: resolveNone,
+			expectedLocation: location{
+				Path:      "/",
+				ProxyPass: "http://" + fallbackUpstreamName,
+			},
+			expectedWarnings: unresolvableWarnings,
+			msg:              "all backends are unresolvable",
+		},
+		{
+			resolve: resolveNone,
+			options: []GeneratorOption{WithNoBackendsResponse(503, "no available backends")},
+			expectedLocation: location{
+				Path: "/",
+				Return: &returnVal{
+					Code:        503,
+					Body:        "no available backends",
+					ContentType: "text/plain",
+				},
+			},
+			expectedWarnings: unresolvableWarnings,
+			msg:              "all backends are unresolvable with a configured response",
+		},
+	}
+
+	for _, test := range tests {
+		fakeServiceStore := &statefakes.FakeServiceStore{}
+		fakeServiceStore.ResolveCalls(test.resolve)
+
+		generator := NewGeneratorImpl(fakeServiceStore, test.options...)
+
+		result, upstreams, warnings := generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+		if diff := cmp.Diff([]location{test.expectedLocation}, result.Locations); diff != "" {
+			t.Errorf("generate() mismatch on locations for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedUpstreams, upstreams); diff != "" {
+			t.Errorf("generate() mismatch on upstreams for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedWarnings, warnings); diff != "" {
+			t.Errorf("generate() mismatch on warnings for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveCalls(resolveNone)
+
+	generator := NewGeneratorImpl(fakeServiceStore, WithNoBackendsResponse(503, "no available backends"))
+
+	cfg, _, _ := generator.Generate(context.Background(), state.Configuration{HTTPServers: []state.VirtualServer{host}})
+
+	expected := `return 503 "no available backends";`
+	if !strings.Contains(string(cfg), expected) {
+		t.Errorf("Generate() didn't generate %q; got:\n%s", expected, string(cfg))
+	}
+
+	fakeServiceStore.ResolveCalls(resolveAll)
+
+	cfg, _, _ = generator.Generate(context.Background(), state.Configuration{HTTPServers: []state.VirtualServer{host}})
+
+	for _, expected := range []string{
+		"upstream test_route1_rule0 {",
+		"server 10.0.0.1:80 weight=80;",
+		"server 10.0.0.2:80 weight=1;",
+		"keepalive 16;",
+		"proxy_pass http://test_route1_rule0$request_uri;",
+	} {
+		if !strings.Contains(string(cfg), expected) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", expected, string(cfg))
+		}
+	}
+}
+
+func TestGenerateResolveTimeout(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	// the store blocks and ignores the context, like a locked store
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveCalls(func(context.Context, types.NamespacedName, int32) (string, error) {
+		<-unblock
+		return "10.0.0.1", nil
+	})
+
+	generator := NewGeneratorImpl(fakeServiceStore, WithResolveTimeout(50*time.Millisecond))
+
+	start := time.Now()
+
+	result, upstreams, warnings := generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("generate() took %v but expected to return after the resolve timeout", elapsed)
+	}
+
+	expectedLocations := []location{
+		{
+			Path:      "/",
+			ProxyPass: "http://" + fallbackUpstreamName,
+		},
+	}
+	if diff := cmp.Diff(expectedLocations, result.Locations); diff != "" {
+		t.Errorf("generate() mismatch on locations (-want +got):\n%s", diff)
+	}
+	if len(upstreams) != 0 {
+		t.Errorf("generate() returned upstreams %v but expected none", upstreams)
+	}
+
+	expectedWarnings := Warnings{
+		hr: []string{"service test/service1 cannot be resolved: context deadline exceeded"},
+	}
+	if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+		t.Errorf("generate() mismatch on warnings (-want +got):\n%s", diff)
+	}
+
+	// the resolution is also bounded by the context of the caller
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	generator = NewGeneratorImpl(fakeServiceStore)
+
+	result, _, warnings = generator.generate(ctx, host, make(state.BackendResolutions))
+
+	if diff := cmp.Diff(expectedLocations, result.Locations); diff != "" {
+		t.Errorf("generate() mismatch on locations with done context (-want +got):\n%s", diff)
+	}
+
+	expectedWarnings = Warnings{
+		hr: []string{"service test/service1 cannot be resolved: context canceled"},
+	}
+	if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+		t.Errorf("generate() mismatch on warnings with done context (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateWeightedBackendsWithHeaderMatch(t *testing.T) {
+	createRef := func(name string, weight int32) v1beta1.HTTPBackendRef {
+		return v1beta1.HTTPBackendRef{
+			BackendRef: v1beta1.BackendRef{
+				BackendObjectReference: v1beta1.BackendObjectReference{
+					Name: v1beta1.ObjectName(name),
+					Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+				},
+				Weight: helpers.GetInt32Pointer(weight),
+			},
+		}
+	}
+
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+							Headers: []v1beta1.HTTPHeaderMatch{
+								{
+									Name:  "canary",
+									Value: "always",
+								},
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						createRef("stable", 90),
+						createRef("canary", 10),
+					},
+				},
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						createRef("stable", 1),
+					},
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+					{
+						MatchIdx: 0,
+						RuleIdx:  1,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveCalls(func(_ context.Context, nsname types.NamespacedName, _ int32) (string, error) {
+		if nsname.Name == "stable" {
+			return "10.0.0.1", nil
+		}
+		return "10.0.0.2", nil
+	})
+
+	// the location of the header match proxies the matched requests to the weighted upstream
+	expectedMatchLocations := []location{
+		{
+			Path:      "/_route0",
+			ProxyPass: "http://test_route1_rule0",
+			Internal:  true,
+		},
+		{
+			Path:      "/_route1",
+			ProxyPass: "http://test_route1_rule1",
+			Internal:  true,
+		},
+	}
+
+	expectedUpstreams := []upstream{
+		{
+			Name: "test_route1_rule0",
+			Servers: []upstreamServer{
+				{Address: "10.0.0.1:80", Weight: 90},
+				{Address: "10.0.0.2:80", Weight: 10},
+			},
+			Keepalive: DefaultUpstreamKeepalive,
+			ZoneSize:  DefaultUpstreamZoneSize,
+		},
+		{
+			Name: "test_route1_rule1",
+			Servers: []upstreamServer{
+				{Address: "10.0.0.1:80"},
+			},
+			Keepalive: DefaultUpstreamKeepalive,
+			ZoneSize:  DefaultUpstreamZoneSize,
+		},
+	}
+
+	generator := NewGeneratorImpl(fakeServiceStore, WithNJSAvailable(true))
+
+	result, upstreams, warnings := generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+	if len(result.Locations) != 3 {
+		t.Fatalf("generate() returned %d locations, expected 3: %v", len(result.Locations), result.Locations)
+	}
+	if diff := cmp.Diff(expectedMatchLocations, result.Locations[:2]); diff != "" {
+		t.Errorf("generate() mismatch on the locations of the matches (-want +got):\n%s", diff)
+	}
+	if result.Locations[2].HTTPMatchVar == "" {
+		t.Errorf("generate() didn't generate the matches of the location of the path: %v", result.Locations[2])
+	}
+	if diff := cmp.Diff(expectedUpstreams, upstreams); diff != "" {
+		t.Errorf("generate() mismatch on upstreams (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(Warnings{}, warnings); diff != "" {
+		t.Errorf("generate() mismatch on warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateZeroWeightBackends(t *testing.T) {
+	createRef := func(name string, weight *int32) v1beta1.HTTPBackendRef {
+		return v1beta1.HTTPBackendRef{
+			BackendRef: v1beta1.BackendRef{
+				BackendObjectReference: v1beta1.BackendObjectReference{
+					Name: v1beta1.ObjectName(name),
+					Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+				},
+				Weight: weight,
+			},
+		}
+	}
+
+	createRoute := func(refs ...v1beta1.HTTPBackendRef) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test",
+				Name:      "route1",
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer("/"),
+								},
+							},
+						},
+						BackendRefs: refs,
+					},
+				},
+			},
+		}
+	}
+
+	createHost := func(hr *v1beta1.HTTPRoute) state.VirtualServer {
+		return state.VirtualServer{
+			Hostname: "example.com",
+			PathRules: []state.PathRule{
+				{
+					Path: "/",
+					MatchRules: []state.MatchRule{
+						{
+							MatchIdx: 0,
+							RuleIdx:  0,
+							Source:   hr,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	singleZeroHR := createRoute(createRef("service1", helpers.GetInt32Pointer(0)))
+	mixedHR := createRoute(
+		createRef("service1", helpers.GetInt32Pointer(0)),
+		createRef("service2", helpers.GetInt32Pointer(50)),
+		createRef("service3", nil),
+	)
+	allZeroHR := createRoute(
+		createRef("service1", helpers.GetInt32Pointer(0)),
+		createRef("service2", helpers.GetInt32Pointer(0)),
+	)
+
+	tests := []struct {
+		hr                *v1beta1.HTTPRoute
+		expectedLocation  location
+		expectedUpstreams []upstream
+		expectedWarnings  Warnings
+		msg               string
+	}{
+		{
+			hr: singleZeroHR,
+			expectedLocation: location{
+				Path:      "/",
+				ProxyPass: "http://" + fallbackUpstreamName,
+			},
+			expectedWarnings: Warnings{
+				singleZeroHR: []string{"the backend service1 has weight 0 and receives no traffic"},
+			},
+			msg: "single zero-weight backend",
+		},
+		{
+			hr: mixedHR,
+			expectedLocation: location{
+				Path:      "/",
+				ProxyPass: "http://test_route1_rule0",
+			},
+			expectedUpstreams: []upstream{
+				{
+					Name: "test_route1_rule0",
+					Servers: []upstreamServer{
+						{Address: "10.0.0.2:80", Weight: 50},
+						{Address: "10.0.0.3:80", Weight: 1},
+					},
+					Keepalive: DefaultUpstreamKeepalive,
+					ZoneSize:  DefaultUpstreamZoneSize,
+				},
+			},
+			expectedWarnings: Warnings{},
+			msg:              "zero-weight and non-zero-weight backends",
+		},
+		{
+			hr: allZeroHR,
+			expectedLocation: location{
+				Path:      "/",
+				ProxyPass: "http://" + fallbackUpstreamName,
+			},
+			expectedWarnings: Warnings{
+				allZeroHR: []string{"all 2 backends of rule 0 have weight 0 and receive no traffic"},
+			},
+			msg: "all backends with zero weight",
+		},
+	}
+
+	resolve := func(_ context.Context, nsname types.NamespacedName, _ int32) (string, error) {
+		switch nsname.Name {
+		case "service1":
+			return "10.0.0.1", nil
+		case "service2":
+			return "10.0.0.2", nil
+		default:
+			return "10.0.0.3", nil
+		}
+	}
+
+	for _, test := range tests {
+		fakeServiceStore := &statefakes.FakeServiceStore{}
+		fakeServiceStore.ResolveCalls(resolve)
+
+		generator := NewGeneratorImpl(fakeServiceStore)
+
+		result, upstreams, warnings := generator.generate(
+			context.Background(),
+			createHost(test.hr),
+			make(state.BackendResolutions),
+		)
+
+		if diff := cmp.Diff([]location{test.expectedLocation}, result.Locations); diff != "" {
+			t.Errorf("generate() mismatch on locations for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedUpstreams, upstreams); diff != "" {
+			t.Errorf("generate() mismatch on upstreams for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedWarnings, warnings); diff != "" {
+			t.Errorf("generate() mismatch on warnings for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+		// the zero-weight backends are not resolved
+		for i := 0; i < fakeServiceStore.ResolveCallCount(); i++ {
+			if _, nsname, _ := fakeServiceStore.ResolveArgsForCall(i); nsname.Name == "service1" {
+				t.Errorf("generate() resolved the zero-weight backend for the case of %q", test.msg)
+			}
+		}
+	}
+}
+
+func TestGenerateResolvesBackendsOnce(t *testing.T) {
+	createRule := func(path string, port int32) v1beta1.HTTPRouteRule {
+		return v1beta1.HTTPRouteRule{
+			Matches: []v1beta1.HTTPRouteMatch{
+				{
+					Path: &v1beta1.HTTPPathMatch{
+						Value: helpers.GetStringPointer(path),
+					},
+				},
+			},
+			BackendRefs: []v1beta1.HTTPBackendRef{
+				{
+					BackendRef: v1beta1.BackendRef{
+						BackendObjectReference: v1beta1.BackendObjectReference{
+							Name: "service1",
+							Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(port)),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				createRule("/coffee", 80),
+				createRule("/tea", 80),
+				createRule("/milk", 8080),
+			},
+		},
+	}
+
+	pathRules := []state.PathRule{
+		{
+			Path:       "/coffee",
+			MatchRules: []state.MatchRule{{MatchIdx: 0, RuleIdx: 0, Source: hr}},
+		},
+		{
+			Path:       "/milk",
+			MatchRules: []state.MatchRule{{MatchIdx: 0, RuleIdx: 2, Source: hr}},
+		},
+		{
+			Path:       "/tea",
+			MatchRules: []state.MatchRule{{MatchIdx: 0, RuleIdx: 1, Source: hr}},
+		},
+	}
+
+	// the same rules are used by the HTTP and SSL servers
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname:  "cafe.example.com",
+				PathRules: pathRules,
+			},
+		},
+		SSLServers: []state.VirtualServer{
+			{
+				Hostname:  "cafe.example.com",
+				PathRules: pathRules,
+				SSL:       &state.SSL{CertificatePath: "/etc/nginx/secrets/cafe.crt", KeyPath: "/etc/nginx/secrets/cafe.key"},
+			},
+		},
+	}
+
+	tests := []struct {
+		address string
+		err     error
+		msg     string
+	}{
+		{
+			address: "10.0.0.1",
+			msg:     "resolved backends",
+		},
+		{
+			err: errors.New("no cluster IP"),
+			msg: "unresolvable backends",
+		},
+	}
+
+	for _, test := range tests {
+		fakeServiceStore := &statefakes.FakeServiceStore{}
+		fakeServiceStore.ResolveReturns(test.address, test.err)
+
+		generator := NewGeneratorImpl(fakeServiceStore)
+
+		_, warnings, _ := generator.Generate(context.Background(), conf)
+
+		// service1:80 and service1:8080
+		if c := fakeServiceStore.ResolveCallCount(); c != 2 {
+			t.Errorf("Generate() resolved the backends %d times but expected 2 for the case of %q", c, test.msg)
+		}
+
+		// the memoized failures are still reported
+		if test.err != nil && len(warnings[hr]) == 0 {
+			t.Errorf("Generate() didn't report the unresolvable backends for the case of %q", test.msg)
+		}
+	}
+
+	// every generation resolves the backends again, so that it uses the current state of the ServiceStore
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	_, _, _ = generator.Generate(context.Background(), conf)
+	_, _, _ = generator.Generate(context.Background(), conf)
+
+	if c := fakeServiceStore.ResolveCallCount(); c != 4 {
+		t.Errorf("Generate() resolved the backends %d times in two generations but expected 4", c)
+	}
+
+	// the backends resolved while the configuration was built are not resolved again
+	fakeServiceStore = &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	conf.BackendAddresses = map[state.BackendKey]string{
+		{
+			Kind:   state.ServiceBackendKind,
+			NsName: types.NamespacedName{Namespace: "test", Name: "service1"},
+			Port:   80,
+		}: "10.0.0.2",
+	}
+
+	cfg, _, _ := NewGeneratorImpl(fakeServiceStore).Generate(context.Background(), conf)
+
+	// only service1:8080
+	if c := fakeServiceStore.ResolveCallCount(); c != 1 {
+		t.Errorf("Generate() resolved the backends %d times with the resolved addresses but expected 1", c)
+	}
+	for _, expected := range []string{"server 10.0.0.2:80;", "server 10.0.0.1:8080;"} {
+		if !strings.Contains(string(cfg), expected) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", expected, string(cfg))
+		}
+	}
+}
+
+func TestGenerateServiceImportBackend(t *testing.T) {
+	createRoute := func(group, kind string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test",
+				Name:      "route1",
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer("/"),
+								},
+							},
+						},
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							{
+								BackendRef: v1beta1.BackendRef{
+									BackendObjectReference: v1beta1.BackendObjectReference{
+										Group: (*v1beta1.Group)(helpers.GetStringPointer(group)),
+										Kind:  (*v1beta1.Kind)(helpers.GetStringPointer(kind)),
+										Name:  "backend1",
+										Port:  (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	createHost := func(hr *v1beta1.HTTPRoute) state.VirtualServer {
+		return state.VirtualServer{
+			Hostname: "example.com",
+			PathRules: []state.PathRule{
+				{
+					Path: "/",
+					MatchRules: []state.MatchRule{
+						{
+							MatchIdx: 0,
+							RuleIdx:  0,
+							Source:   hr,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	serviceHR := createRoute("", "Service")
+	serviceImportHR := createRoute("multicluster.x-k8s.io", "ServiceImport")
+
+	tests := []struct {
+		hr                *v1beta1.HTTPRoute
+		registerImports   bool
+		expectedProxyPass string
+		expectedUpstreams []upstream
+		expectedWarnings  Warnings
+		msg               string
+	}{
+		{
+			hr:                serviceHR,
+			registerImports:   true,
+			expectedProxyPass: "http://test_route1_rule0",
+			expectedUpstreams: []upstream{createUpstream("test_route1_rule0", "10.0.0.1:80")},
+			expectedWarnings:  Warnings{},
+			msg:               "service",
+		},
+		{
+			hr:                serviceImportHR,
+			registerImports:   true,
+			expectedProxyPass: "http://test_route1_rule0",
+			expectedUpstreams: []upstream{createUpstream("test_route1_rule0", "10.0.1.1:80")},
+			expectedWarnings:  Warnings{},
+			msg:               "service import",
+		},
+		{
+			hr:                serviceImportHR,
+			registerImports:   false,
+			expectedProxyPass: "http://" + fallbackUpstreamName,
+			expectedWarnings: Warnings{
+				serviceImportHR: []string{"unsupported kind ServiceImport"},
+			},
+			msg: "service import without a registered resolver",
+		},
+	}
+
+	for _, test := range tests {
+		fakeServiceStore := &statefakes.FakeServiceStore{}
+		fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+		fakeImportResolver := &statefakes.FakeBackendResolver{}
+		fakeImportResolver.ResolveReturns("10.0.1.1", nil)
+
+		var options []GeneratorOption
+		if test.registerImports {
+			options = append(options, WithBackendResolvers(map[state.BackendKind]state.BackendResolver{
+				state.ServiceImportBackendKind: fakeImportResolver,
+			}))
+		}
+
+		generator := NewGeneratorImpl(fakeServiceStore, options...)
+
+		result, upstreams, warnings := generator.generate(
+			context.Background(),
+			createHost(test.hr),
+			make(state.BackendResolutions),
+		)
+
+		if diff := cmp.Diff(test.expectedUpstreams, upstreams); diff != "" {
+			t.Errorf("generate() mismatch on upstreams for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+
+		expectedLocations := []location{
+			{
+				Path:      "/",
+				ProxyPass: test.expectedProxyPass,
+			},
+		}
+
+		if diff := cmp.Diff(expectedLocations, result.Locations); diff != "" {
+			t.Errorf("generate() mismatch on locations for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedWarnings, warnings); diff != "" {
+			t.Errorf("generate() mismatch on warnings for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+
+		// every kind is resolved only by its resolver
+		kind := state.GetBackendKind(test.hr.Spec.Rules[0].BackendRefs[0].BackendObjectReference)
+		expectedServiceCalls, expectedImportCalls := 1, 0
+		if kind == state.ServiceImportBackendKind {
+			expectedServiceCalls = 0
+			if test.registerImports {
+				expectedImportCalls = 1
+			}
+		}
+
+		if c := fakeServiceStore.ResolveCallCount(); c != expectedServiceCalls {
+			t.Errorf("generate() resolved %d services but expected %d for the case of %q", c, expectedServiceCalls, test.msg)
+		}
+		if c := fakeImportResolver.ResolveCallCount(); c != expectedImportCalls {
+			t.Errorf("generate() resolved %d service imports but expected %d for the case of %q",
+				c, expectedImportCalls, test.msg)
+		}
+	}
+}
+
+func TestGenerateFallbackReferenced(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: nil, // no backend refs will cause the fallback upstream to be used
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	generator := NewGeneratorImpl(&statefakes.FakeServiceStore{})
+
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+
+	expectedProxyPass := "proxy_pass http://" + fallbackUpstreamName + "$request_uri;"
+	if !strings.Contains(string(cfg), expectedProxyPass) {
+		t.Errorf("Generate() did not generate a location that proxies to the fallback upstream %q", fallbackUpstreamName)
+	}
+
+	expectedUpstreamServer := "server " + nginx502Server + ";"
+	if strings.Count(string(cfg), expectedUpstreamServer) != 1 {
+		t.Errorf("Generate() did not generate the fallback upstream with the server %q exactly once", nginx502Server)
+	}
+}
+
+func TestGenerateRequestMirror(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					Filters: []v1beta1.HTTPRouteFilter{
+						{
+							Type: v1beta1.HTTPRouteFilterRequestMirror,
+							RequestMirror: &v1beta1.HTTPRequestMirrorFilter{
+								BackendRef: v1beta1.BackendObjectReference{
+									Name: "mirror",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(8080)),
+								},
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		resolve           func(context.Context, types.NamespacedName, int32) (string, error)
+		expectedLocations []location
+		expectedWarnings  Warnings
+		msg               string
+	}{
+		{
+			resolve: func(_ context.Context, nsname types.NamespacedName, _ int32) (string, error) {
+				if nsname.Name == "mirror" {
+					return "10.0.0.2", nil
+				}
+				return "10.0.0.1", nil
+			},
+			expectedLocations: []location{
+				{
+					Path:      "/_mirror_test_route1_rule0",
+					ProxyPass: "http://10.0.0.2:8080",
+					Internal:  true,
+				},
+				{
+					Path:      "/",
+					ProxyPass: "http://test_route1_rule0",
+					Mirror:    "/_mirror_test_route1_rule0",
+				},
+			},
+			expectedWarnings: Warnings{},
+			msg:              "mirror backend is resolved",
+		},
+		{
+			resolve: func(_ context.Context, nsname types.NamespacedName, _ int32) (string, error) {
+				if nsname.Name == "mirror" {
+					return "", errors.New("no cluster IP")
+				}
+				return "10.0.0.1", nil
+			},
+			expectedLocations: []location{
+				{
+					Path:      "/",
+					ProxyPass: "http://test_route1_rule0",
+				},
+			},
+			expectedWarnings: Warnings{
+				hr: []string{
+					"the mirror backend of rule 0 cannot be resolved: " +
+						"service test/mirror cannot be resolved: no cluster IP",
+				},
+			},
+			msg: "mirror backend is unresolvable",
+		},
+	}
+
+	for _, test := range tests {
+		fakeServiceStore := &statefakes.FakeServiceStore{}
+		fakeServiceStore.ResolveCalls(test.resolve)
+
+		result, _, warnings := NewGeneratorImpl(fakeServiceStore).generate(
+			context.Background(),
+			host,
+			make(state.BackendResolutions),
+		)
+
+		if diff := cmp.Diff(test.expectedLocations, result.Locations); diff != "" {
+			t.Errorf("generate() mismatch on locations for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedWarnings, warnings); diff != "" {
+			t.Errorf("generate() mismatch on warnings for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestGenerateSSL(t *testing.T) {
+	sslConf := &state.SSL{
+		CertificatePath: "/etc/nginx/secrets/secret.crt",
+		KeyPath:         "/etc/nginx/secrets/secret.key",
+	}
+
+	tests := []struct {
+		ssl            *state.SSL
+		expected       *ssl
+		certificateMap bool
+		msg            string
+	}{
+		{
+			ssl:      nil,
+			expected: nil,
+			msg:      "no ssl",
+		},
+		{
+			ssl: sslConf,
+			expected: &ssl{
+				Certificate:    "/etc/nginx/secrets/secret.crt",
+				CertificateKey: "/etc/nginx/secrets/secret.key",
+			},
+			msg: "separate certificate and key paths",
+		},
+		{
+			ssl: sslConf,
+			expected: &ssl{
+				Certificate:    "$gateway_ssl_certificate",
+				CertificateKey: "$gateway_ssl_certificate_key",
+			},
+			certificateMap: true,
+			msg:            "certificate map",
+		},
+	}
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, WithSSLCertificateMap(test.certificateMap))
+
+		result := generator.generateSSL(test.ssl)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateSSL() mismatch for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestGenerateSSLCertificates(t *testing.T) {
+	sslServers := []state.VirtualServer{
+		{
+			Hostname: "bar.example.com",
+			SSL:      &state.SSL{CertificatePath: "/etc/nginx/secrets/bar.crt", KeyPath: "/etc/nginx/secrets/bar.key"},
+		},
+		{
+			Hostname: "foo.example.com",
+			SSL:      &state.SSL{CertificatePath: "/etc/nginx/secrets/foo.crt", KeyPath: "/etc/nginx/secrets/foo.key"},
+		},
+		{
+			Hostname: "foo.example.com",
+			SSL:      &state.SSL{CertificatePath: "/etc/nginx/secrets/other.crt", KeyPath: "/etc/nginx/secrets/other.key"},
+		},
+		{
+			Hostname: "no-ssl.example.com",
+		},
+	}
+
+	expected := []sslCertificate{
+		{
+			Hostname: "bar.example.com",
+			Path:     "/etc/nginx/secrets/bar.crt",
+			KeyPath:  "/etc/nginx/secrets/bar.key",
+		},
+		{
+			Hostname: "foo.example.com",
+			Path:     "/etc/nginx/secrets/foo.crt",
+			KeyPath:  "/etc/nginx/secrets/foo.key",
+		},
+	}
+
+	result := generateSSLCertificates(sslServers)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateSSLCertificates() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateWithSSLCertificateMap(t *testing.T) {
+	conf := state.Configuration{
+		SSLServers: []state.VirtualServer{
+			{
+				Hostname: "bar.example.com",
+				SSL:      &state.SSL{CertificatePath: "/etc/nginx/secrets/bar.crt", KeyPath: "/etc/nginx/secrets/bar.key"},
+			},
+			{
+				Hostname: "foo.example.com",
+				SSL:      &state.SSL{CertificatePath: "/etc/nginx/secrets/foo.crt", KeyPath: "/etc/nginx/secrets/foo.key"},
+			},
+		},
+	}
+
+	mapDirectives := []string{
+		"map $ssl_server_name $gateway_ssl_certificate {",
+		"default /etc/nginx/secrets/bar.crt;",
+		"bar.example.com /etc/nginx/secrets/bar.crt;",
+		"foo.example.com /etc/nginx/secrets/foo.crt;",
+		"map $ssl_server_name $gateway_ssl_certificate_key {",
+		"default /etc/nginx/secrets/bar.key;",
+		"bar.example.com /etc/nginx/secrets/bar.key;",
+		"foo.example.com /etc/nginx/secrets/foo.key;",
+		"ssl_certificate $gateway_ssl_certificate;",
+		"ssl_certificate_key $gateway_ssl_certificate_key;",
+	}
+	staticDirectives := []string{
+		"ssl_certificate /etc/nginx/secrets/bar.crt;",
+		"ssl_certificate_key /etc/nginx/secrets/bar.key;",
+		"ssl_certificate /etc/nginx/secrets/foo.crt;",
+		"ssl_certificate_key /etc/nginx/secrets/foo.key;",
+	}
+
+	tests := []struct {
+		expected   []string
+		unexpected []string
+		enabled    bool
+		msg        string
+	}{
+		{
+			enabled:    true,
+			expected:   mapDirectives,
+			unexpected: staticDirectives,
+			msg:        "map enabled",
+		},
+		{
+			enabled:    false,
+			expected:   staticDirectives,
+			unexpected: mapDirectives,
+			msg:        "map disabled",
+		},
+	}
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, WithSSLCertificateMap(test.enabled))
+
+		cfg, _, _ := generator.Generate(context.Background(), conf)
+
+		for _, d := range test.expected {
+			if !strings.Contains(string(cfg), d) {
+				t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", d, test.msg, string(cfg))
+			}
+		}
+		for _, d := range test.unexpected {
+			if strings.Contains(string(cfg), d) {
+				t.Errorf("Generate() generated %q for the case of %q; got:\n%s", d, test.msg, string(cfg))
+			}
+		}
+	}
+}
+
+func TestGenerateWithWWWRedirects(t *testing.T) {
+	sslConf := &state.SSL{
+		CertificatePath: "/etc/nginx/secrets/secret.crt",
+		KeyPath:         "/etc/nginx/secrets/secret.key",
+	}
+
+	conf := state.Configuration{
+		HTTPRedirects: []state.WWWRedirect{
+			{
+				Hostname: "www.example.com",
+				Target:   "example.com",
+			},
+		},
+		SSLRedirects: []state.WWWRedirect{
+			{
+				Hostname: "example.org",
+				Target:   "www.example.org",
+				SSL:      sslConf,
+			},
+		},
+	}
+
+	expected := []server{
+		{
+			ServerName: "www.example.com",
+			Locations: []location{
+				{
+					Path: "/",
+					Return: &returnVal{
+						Code:        statusMovedPermanently,
+						Body:        "$scheme://example.com$request_uri",
+						ContentType: contentTypeHTML,
+					},
+				},
+			},
+		},
+		{
+			ServerName: "example.org",
+			SSL: &ssl{
+				Certificate:    "/etc/nginx/secrets/secret.crt",
+				CertificateKey: "/etc/nginx/secrets/secret.key",
+			},
+			Locations: []location{
+				{
+					Path: "/",
+					Return: &returnVal{
+						Code:        statusMovedPermanently,
+						Body:        "$scheme://www.example.org$request_uri",
+						ContentType: contentTypeHTML,
+					},
+				},
+			},
+		},
+	}
+
+	generator := NewGeneratorImpl(&statefakes.FakeServiceStore{})
+
+	servers, _ := generator.generateHTTPServers(context.Background(), conf)
+
+	result := servers.Servers[len(servers.Servers)-len(expected):]
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateHTTPServers() mismatch on the redirect servers (-want +got):\n%s", diff)
+	}
+
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+
+	for _, e := range []string{
+		`return 301 "$scheme://example.com$request_uri";`,
+		`return 301 "$scheme://www.example.org$request_uri";`,
+	} {
+		if !strings.Contains(string(cfg), e) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", e, string(cfg))
+		}
+	}
+}
+
+func TestGenerateStatusOverrides(t *testing.T) {
+	expected := statusOverrides{
+		Overrides: []statusOverride{
+			{From: 404, To: 503},
+			{From: 418, To: 500},
+			{From: 503, To: 500},
+		},
+		Codes: []statusCode{500, 503},
+	}
+
+	result := generateStatusOverrides(map[int]int{418: 500, 503: 500, 404: 503})
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateStatusOverrides() mismatch (-want +got):\n%s", diff)
+	}
+
+	if result := generateStatusOverrides(nil); len(result.Overrides) != 0 || len(result.Codes) != 0 {
+		t.Errorf("generateStatusOverrides() returned %v for no overrides", result)
+	}
+}
+
+func TestGenerateWithStatusOverrides(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	directives := []string{
+		"proxy_intercept_errors on;",
+		"error_page 418 =500 @gateway_status_500;",
+		"location @gateway_status_500 {\n\t\tdefault_type text/html;\n\t\treturn 500;\n\t}",
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore, WithStatusOverrides(map[int]int{418: 500}))
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+
+	for _, d := range directives {
+		if !strings.Contains(string(cfg), d) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", d, string(cfg))
+		}
+	}
+
+	cfg, _, _ = NewGeneratorImpl(fakeServiceStore).Generate(context.Background(), conf)
+
+	for _, d := range []string{"proxy_intercept_errors", "error_page", "@gateway_status"} {
+		if strings.Contains(string(cfg), d) {
+			t.Errorf("Generate() generated %q without status overrides; got:\n%s", d, string(cfg))
+		}
+	}
+}
+
+func TestGenerateWithTemplateOverrides(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	expected, _, _ := NewGeneratorImpl(fakeServiceStore).Generate(context.Background(), conf)
+
+	// the default templates redefined with themselves must generate the same configuration
+	defaults := httpServersTemplate[strings.Index(httpServersTemplate, `{{ define "server" }}`):]
+
+	cfg, _, err := NewGeneratorImpl(fakeServiceStore, WithTemplateOverrides(defaults)).Generate(context.Background(), conf)
+	if err != nil {
+		t.Fatalf("Generate() returned unexpected error %v", err)
+	}
+	if diff := cmp.Diff(string(expected), string(cfg)); diff != "" {
+		t.Errorf("Generate() with the default templates as overrides mismatch (-want +got):\n%s", diff)
+	}
+
+	overrides := `{{ define "location" }}location {{ .Location.Path }} {
+		proxy_set_header X-Server {{ .Server.ServerName }};
+		proxy_pass {{ .Location.ProxyPass }};
+	}{{ end }}`
+
+	cfg, _, err = NewGeneratorImpl(fakeServiceStore, WithTemplateOverrides(overrides)).Generate(context.Background(), conf)
+	if err != nil {
+		t.Fatalf("Generate() returned unexpected error %v", err)
+	}
+
+	for _, d := range []string{
+		"proxy_set_header X-Server cafe.example.com;",
+		"proxy_pass http://test_route1_rule0;",
+		// the templates that are not overridden keep their defaults
+		"server_name cafe.example.com;",
+		"upstream " + fallbackUpstreamName + " {",
+		"upstream test_route1_rule0 {",
+	} {
+		if !strings.Contains(string(cfg), d) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", d, string(cfg))
+		}
+	}
+	if strings.Contains(string(cfg), "$request_uri") {
+		t.Errorf("Generate() generated the default location; got:\n%s", string(cfg))
+	}
+}
+
+func TestGenerateFallbackUpstream(t *testing.T) {
+	expected := upstream{
+		Name:    fallbackUpstreamName,
+		Servers: []upstreamServer{{Address: nginx502Server}},
+	}
+
+	result := generateFallbackUpstream()
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateFallbackUpstream() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateFallbackServer(t *testing.T) {
+	expected := server{
+		IsFallback: true,
+		Listen:     nginx502Server,
+	}
+
+	result := generateFallbackServer()
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateFallbackServer() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateDefaultHTTPServer(t *testing.T) {
+	backendNsName := types.NamespacedName{Namespace: "test", Name: "default-backend"}
+
+	tests := []struct {
+		options      []GeneratorOption
+		storeAddress string
+		storeErr     error
+		expected     server
+		msg          string
+	}{
+		{
+			options: nil,
+			expected: server{
+				IsDefaultHTTP: true,
+			},
+			msg: "default backend is absent",
+		},
+		{
+			options:      []GeneratorOption{WithDefaultBackend(backendNsName, 8080)},
+			storeAddress: "10.0.0.1",
+			expected: server{
+				IsDefaultHTTP: true,
+				Locations: []location{
+					{
+						Path:      "/",
+						ProxyPass: "http://10.0.0.1:8080",
+					},
+				},
+			},
+			msg: "default backend is set",
+		},
+		{
+			options:      []GeneratorOption{WithDefaultBackend(backendNsName, 8080)},
+			storeAddress: "unix:/var/run/default-backend.sock",
+			expected: server{
+				IsDefaultHTTP: true,
+				Locations: []location{
+					{
+						Path:      "/",
+						ProxyPass: "http://unix:/var/run/default-backend.sock:",
+					},
+				},
+			},
+			msg: "default backend is a unix socket",
+		},
+		{
+			options:  []GeneratorOption{WithDefaultBackend(backendNsName, 8080)},
+			storeErr: errors.New("service doesn't exist"),
+			expected: server{
+				IsDefaultHTTP: true,
+				Locations: []location{
+					{
+						Path:      "/",
+						ProxyPass: "http://" + fallbackUpstreamName,
+					},
+				},
+			},
+			msg: "default backend cannot be resolved",
+		},
+	}
+
+	for _, test := range tests {
+		fakeServiceStore := &statefakes.FakeServiceStore{}
+		fakeServiceStore.ResolveReturns(test.storeAddress, test.storeErr)
+
+		generator := NewGeneratorImpl(fakeServiceStore, test.options...)
+
+		result := generator.generateDefaultHTTPServer(context.Background())
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateDefaultHTTPServer() mismatch for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+
+		if len(test.options) == 0 {
+			if fakeServiceStore.ResolveCallCount() != 0 {
+				t.Errorf("generateDefaultHTTPServer() resolved a service for the case of %q", test.msg)
+			}
+			continue
+		}
+
+		_, nsname, port := fakeServiceStore.ResolveArgsForCall(0)
+		if nsname != backendNsName || port != 8080 {
+			t.Errorf(
+				"generateDefaultHTTPServer() resolved %v:%d but expected %v:8080 for the case of %q",
+				nsname,
+				port,
+				backendNsName,
+				test.msg,
+			)
+		}
+	}
+}
+
+func TestGenerateWithDefaultBackend(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(
+		fakeServiceStore,
+		WithDefaultBackend(types.NamespacedName{Namespace: "test", Name: "default-backend"}, 8080),
+	)
+
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+
+	expected := "proxy_pass http://10.0.0.1:8080$request_uri;"
+	if !strings.Contains(string(cfg), expected) {
+		t.Errorf("Generate() didn't proxy the unmatched requests to the default backend; got:\n%s", string(cfg))
+	}
+	// only the location of the server without routes responds with 404, not the default server
+	if n := strings.Count(string(cfg), "return 404;"); n != 1 {
+		t.Errorf("Generate() returned 404 for the unmatched requests; got:\n%s", string(cfg))
+	}
+}
+
+func TestGenerateExternalNameBackend(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "external",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("api.example.com", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore, WithResolver([]string{"10.96.0.10", "fd00::10"}))
+
+	cfg, warnings, _ := generator.Generate(context.Background(), conf)
+	if len(warnings) > 0 {
+		t.Errorf("Generate() returned unexpected warnings: %v", warnings)
+	}
+
+	for _, expected := range []string{
+		"resolver 10.96.0.10 [fd00::10];",
+		"proxy_pass http://api.example.com:80$request_uri;",
+	} {
+		if !strings.Contains(string(cfg), expected) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", expected, string(cfg))
+		}
+	}
+	// NGINX resolves the upstream servers only when it loads the configuration, so the hostname is not in an upstream
+	if strings.Contains(string(cfg), "upstream test_route1_rule0") {
+		t.Errorf("Generate() generated an upstream for the backend with a hostname; got:\n%s", string(cfg))
+	}
+
+	// the backend cannot be resolved at runtime without a resolver
+	cfg, warnings, _ = NewGeneratorImpl(fakeServiceStore).Generate(context.Background(), conf)
+	if len(warnings[hr]) != 1 {
+		t.Errorf("Generate() returned %d warnings but expected 1: %v", len(warnings[hr]), warnings)
+	}
+	if strings.Contains(string(cfg), "api.example.com") {
+		t.Errorf("Generate() proxied the requests to the backend with a hostname without a resolver; got:\n%s",
+			string(cfg))
+	}
+
+	// the resolver is only generated if a backend has a hostname
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	cfg, _, _ = generator.Generate(context.Background(), conf)
+	if strings.Contains(string(cfg), "resolver ") {
+		t.Errorf("Generate() generated the resolver without backends with hostnames; got:\n%s", string(cfg))
+	}
+}
+
+func TestGenerateBackendHeaderFilters(t *testing.T) {
+	createBackendRef := func(name string, weight int32, filter v1beta1.HTTPRequestHeaderFilter) v1beta1.HTTPBackendRef {
+		return v1beta1.HTTPBackendRef{
+			BackendRef: v1beta1.BackendRef{
+				BackendObjectReference: v1beta1.BackendObjectReference{
+					Name: v1beta1.ObjectName(name),
+					Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+				},
+				Weight: helpers.GetInt32Pointer(weight),
+			},
+			Filters: []v1beta1.HTTPRouteFilter{
+				{
+					Type:                  v1beta1.HTTPRouteFilterRequestHeaderModifier,
+					RequestHeaderModifier: &filter,
+				},
+			},
+		}
+	}
+
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						createBackendRef("stable", 80, v1beta1.HTTPRequestHeaderFilter{
+							Set:    []v1beta1.HTTPHeader{{Name: "X-Version", Value: "stable"}},
+							Remove: []string{"X-Debug"},
+						}),
+						createBackendRef("canary", 20, v1beta1.HTTPRequestHeaderFilter{
+							Set: []v1beta1.HTTPHeader{{Name: "X-Version", Value: "canary"}},
+							Add: []v1beta1.HTTPHeader{{Name: "X-Tags", Value: "canary"}},
+						}),
+					},
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveCalls(func(_ context.Context, nsname types.NamespacedName, _ int32) (string, error) {
+		if nsname.Name == "stable" {
+			return "10.0.0.1", nil
+		}
+		return "10.0.0.2", nil
+	})
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	result, upstreams, warnings := generator.generate(context.Background(), host, make(state.BackendResolutions))
+	if len(warnings) != 0 {
+		t.Errorf("generate() returned unexpected warnings: %v", warnings)
+	}
+
+	// every backend has its own upstream, so that its requests get its headers
+	expectedUpstreams := []upstream{
+		createUpstream("test_route1_rule0_backend0", "10.0.0.1:80"),
+		createUpstream("test_route1_rule0_backend1", "10.0.0.2:80"),
+	}
+	if diff := cmp.Diff(expectedUpstreams, upstreams); diff != "" {
+		t.Errorf("generate() mismatch on upstreams (-want +got):\n%s", diff)
+	}
+
+	const variable = "$gateway_backend_test__route1__rule0"
+
+	expectedLocations := []location{
+		{
+			Path:      "/",
+			ProxyPass: "http://" + variable,
+			BackendSplit: &backendSplit{
+				Variable: variable,
+				Backends: []splitBackend{
+					{Percentage: "80.00%", Upstream: "test_route1_rule0_backend0"},
+					{Percentage: "*", Upstream: "test_route1_rule0_backend1"},
+				},
+				Headers: []splitHeader{
+					{
+						Name:           "X-Version",
+						Variable:       variable + "__header0",
+						ClientVariable: "$http_x_version",
+						Values: []splitHeaderValue{
+							{Upstream: "test_route1_rule0_backend0", Value: "stable"},
+							{Upstream: "test_route1_rule0_backend1", Value: "canary"},
+						},
+					},
+					{
+						Name:           "X-Debug",
+						Variable:       variable + "__header1",
+						ClientVariable: "$http_x_debug",
+						Values: []splitHeaderValue{
+							{Upstream: "test_route1_rule0_backend0", Value: ""},
+						},
+					},
+					{
+						Name:           "X-Tags",
+						Variable:       variable + "__header2",
+						ClientVariable: "$http_x_tags",
+						AppendVariable: variable + "__header2__append",
+						Values: []splitHeaderValue{
+							{
+								Upstream: "test_route1_rule0_backend1",
+								Value:    "${gateway_backend_test__route1__rule0__header2__append}canary",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedLocations, result.Locations); diff != "" {
+		t.Errorf("generate() mismatch on locations (-want +got):\n%s", diff)
+	}
+
+	cfg, _, _ := generator.Generate(context.Background(), state.Configuration{HTTPServers: []state.VirtualServer{host}})
+
+	for _, expected := range []string{
+		"split_clients $request_id " + variable + " {",
+		"80.00% test_route1_rule0_backend0;",
+		"* test_route1_rule0_backend1;",
+		"map " + variable + " " + variable + "__header0 {",
+		"default $http_x_version;",
+		`test_route1_rule0_backend0 "stable";`,
+		`test_route1_rule0_backend1 "canary";`,
+		"map $http_x_tags " + variable + "__header2__append {",
+		"proxy_set_header X-Version " + variable + "__header0;",
+		"proxy_set_header X-Debug " + variable + "__header1;",
+		"proxy_set_header X-Tags " + variable + "__header2;",
+		"proxy_pass http://" + variable + "$request_uri;",
+	} {
+		if !strings.Contains(string(cfg), expected) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", expected, string(cfg))
+		}
+	}
+
+	// the rules without backendRef filters keep a single upstream
+	for i := range hr.Spec.Rules[0].BackendRefs {
+		hr.Spec.Rules[0].BackendRefs[i].Filters = nil
+	}
+
+	result, upstreams, _ = generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+	if len(upstreams) != 1 || upstreams[0].Name != "test_route1_rule0" {
+		t.Errorf("generate() generated unexpected upstreams without the filters: %v", upstreams)
+	}
+	if result.Locations[0].BackendSplit != nil {
+		t.Errorf("generate() generated a split without the filters: %v", result.Locations[0].BackendSplit)
+	}
+}
+
+func TestGenerateUnixSocketBackend(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "sidecar",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		storeAddress     string
+		expectedUpstream upstream
+		expectedServer   string
+		msg              string
+	}{
+		{
+			storeAddress:     "unix:/var/run/app.sock",
+			expectedUpstream: createUpstream("test_route1_rule0", "unix:/var/run/app.sock"),
+			expectedServer:   "server unix:/var/run/app.sock;",
+			msg:              "unix socket",
+		},
+		{
+			storeAddress:     "10.0.0.1",
+			expectedUpstream: createUpstream("test_route1_rule0", "10.0.0.1:80"),
+			expectedServer:   "server 10.0.0.1:80;",
+			msg:              "tcp",
+		},
+	}
+
+	for _, test := range tests {
+		fakeServiceStore := &statefakes.FakeServiceStore{}
+		fakeServiceStore.ResolveReturns(test.storeAddress, nil)
+
+		generator := NewGeneratorImpl(fakeServiceStore)
+
+		result, upstreams, warnings := generator.generate(context.Background(), host, make(state.BackendResolutions))
+		if len(warnings) != 0 {
+			t.Errorf("generate() returned unexpected warnings for the case of %q: %v", test.msg, warnings)
+		}
+
+		if diff := cmp.Diff([]upstream{test.expectedUpstream}, upstreams); diff != "" {
+			t.Errorf("generate() mismatch on upstreams for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+
+		expectedLocations := []location{
+			{
+				Path:      "/",
+				ProxyPass: "http://test_route1_rule0",
+			},
+		}
+		if diff := cmp.Diff(expectedLocations, result.Locations); diff != "" {
+			t.Errorf("generate() mismatch on locations for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+
+		cfg, _, _ := generator.Generate(context.Background(), state.Configuration{HTTPServers: []state.VirtualServer{host}})
+		if !strings.Contains(string(cfg), test.expectedServer) {
+			t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", test.expectedServer, test.msg, string(cfg))
+		}
+		// a unix socket is not a hostname, so NGINX doesn't need a resolver for it
+		if strings.Contains(string(cfg), "resolver ") {
+			t.Errorf("Generate() generated the resolver for the case of %q; got:\n%s", test.msg, string(cfg))
+		}
+	}
+}
+
+func TestGenerateHTTPSRedirect(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	pathRules := []state.PathRule{
+		{
+			Path: "/",
+			MatchRules: []state.MatchRule{
+				{
+					MatchIdx: 0,
+					RuleIdx:  0,
+					Source:   hr,
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname:      "cafe.example.com",
+				PathRules:     pathRules,
+				HTTPSRedirect: true,
+			},
+		},
+	}
+
+	tests := []struct {
+		options  []GeneratorOption
+		expected string
+		msg      string
+	}{
+		{
+			options:  nil,
+			expected: `return 301 "https://$host$request_uri";`,
+			msg:      "default https port",
+		},
+		{
+			options:  []GeneratorOption{WithListenPorts(8080, 8443)},
+			expected: `return 301 "https://$host:8443$request_uri";`,
+			msg:      "custom https port",
+		},
+	}
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, test.options...)
+
+		cfg, _, _ := generator.Generate(context.Background(), conf)
+
+		if !strings.Contains(string(cfg), test.expected) {
+			t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", test.expected, test.msg, string(cfg))
+		}
+		// the requests are redirected rather than proxied to the backends
+		if strings.Contains(string(cfg), "proxy_pass http://test_route1_rule0") {
+			t.Errorf("Generate() proxied the redirected requests for the case of %q; got:\n%s", test.msg, string(cfg))
+		}
+	}
+
+	// the server without the redirect proxies the requests
+	conf.HTTPServers[0].HTTPSRedirect = false
+
+	cfg, _, _ := NewGeneratorImpl(&statefakes.FakeServiceStore{}).Generate(context.Background(), conf)
+	if strings.Contains(string(cfg), "https://$host") {
+		t.Errorf("Generate() redirected the requests of the HTTP only server; got:\n%s", string(cfg))
+	}
+}
+
+func TestGenerateDefaultSSLServer(t *testing.T) {
+	conf := state.Configuration{
+		SSLServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				SSL: &state.SSL{
+					CertificatePath: "/etc/nginx/secrets/cafe.crt",
+					KeyPath:         "/etc/nginx/secrets/cafe.key",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		options     []GeneratorOption
+		expected    []string
+		notExpected []string
+		msg         string
+	}{
+		{
+			options: []GeneratorOption{
+				WithDefaultCertificate("/etc/nginx/default/tls.crt", "/etc/nginx/default/tls.key"),
+			},
+			expected: []string{
+				"ssl_certificate /etc/nginx/default/tls.crt;",
+				"ssl_certificate_key /etc/nginx/default/tls.key;",
+				"return 404;",
+			},
+			notExpected: []string{
+				"ssl_reject_handshake on;",
+			},
+			msg: "default certificate",
+		},
+		{
+			options: nil,
+			expected: []string{
+				"ssl_reject_handshake on;",
+			},
+			notExpected: []string{
+				"/etc/nginx/default/tls.crt",
+			},
+			msg: "no default certificate",
+		},
+		{
+			options: []GeneratorOption{
+				WithDefaultCertificate("/etc/nginx/default/tls.crt", ""),
+			},
+			expected: []string{
+				"ssl_reject_handshake on;",
+			},
+			notExpected: []string{
+				"/etc/nginx/default/tls.crt",
+			},
+			msg: "default certificate without key",
+		},
+	}
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, test.options...)
+
+		cfg, _, _ := generator.Generate(context.Background(), conf)
+
+		for _, e := range test.expected {
+			if !strings.Contains(string(cfg), e) {
+				t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", e, test.msg, string(cfg))
+			}
+		}
+		for _, e := range test.notExpected {
+			if strings.Contains(string(cfg), e) {
+				t.Errorf("Generate() generated %q for the case of %q; got:\n%s", e, test.msg, string(cfg))
+			}
+		}
+	}
+}
+
+func TestGenerateWithDefaultType(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+	}
+
+	expected := "default_type application/octet-stream;"
+
+	generator := NewGeneratorImpl(&statefakes.FakeServiceStore{}, WithDefaultType("application/octet-stream"))
+
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+	if !strings.Contains(string(cfg), expected) {
+		t.Errorf("Generate() didn't generate %q; got:\n%s", expected, string(cfg))
+	}
+
+	// the default type is set in the http context, which the main file includes
+	files, _, _ := generator.GenerateFiles(context.Background(), conf)
+	if main := string(files[HTTPServersMainFile]); !strings.Contains(main, expected) {
+		t.Errorf("GenerateFiles() didn't generate %q in the main file; got:\n%s", expected, main)
+	}
+
+	cfg, _, _ = NewGeneratorImpl(&statefakes.FakeServiceStore{}).Generate(context.Background(), conf)
+	if strings.Contains(string(cfg), expected) {
+		t.Errorf("Generate() generated %q without the option; got:\n%s", expected, string(cfg))
+	}
+}
+
+func TestGenerateServerTokens(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+	}
+
+	expected := "server_tokens off;"
+
+	generator := NewGeneratorImpl(&statefakes.FakeServiceStore{})
+
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+	if !strings.Contains(string(cfg), expected) {
+		t.Errorf("Generate() didn't generate %q by default; got:\n%s", expected, string(cfg))
+	}
+
+	// server_tokens is set in the http context, which the main file includes
+	files, _, _ := generator.GenerateFiles(context.Background(), conf)
+	if main := string(files[HTTPServersMainFile]); !strings.Contains(main, expected) {
+		t.Errorf("GenerateFiles() didn't generate %q in the main file; got:\n%s", expected, main)
+	}
+
+	generator = NewGeneratorImpl(&statefakes.FakeServiceStore{}, WithServerTokens(true))
+
+	cfg, _, _ = generator.Generate(context.Background(), conf)
+	if strings.Contains(string(cfg), "server_tokens") {
+		t.Errorf("Generate() generated server_tokens with the option enabled; got:\n%s", string(cfg))
+	}
+}
+
+func TestGenerateUnsupportedFeatures(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+					Filters: []v1beta1.HTTPRouteFilter{
+						{
+							Type: v1beta1.HTTPRouteFilterURLRewrite,
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	expectedWarnings := Warnings{
+		hr: []string{
+			`spec.rules[0].filters[0].type: Unsupported value: "URLRewrite": supported values: "RequestMirror"; ` +
+				"the feature is ignored",
+		},
+	}
+
+	_, _, warnings := NewGeneratorImpl(fakeServiceStore).generate(
+		context.Background(),
+		host,
+		make(state.BackendResolutions),
+	)
+
+	if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+		t.Errorf("generate() mismatch on warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateSourceComments(t *testing.T) {
+	createHeaderMatch := func(value string) v1beta1.HTTPRouteMatch {
+		return v1beta1.HTTPRouteMatch{
+			Path: &v1beta1.HTTPPathMatch{
+				Value: helpers.GetStringPointer("/tea"),
+			},
+			Headers: []v1beta1.HTTPHeaderMatch{
+				{
+					Name:  "version",
+					Value: value,
+				},
+			},
+		}
+	}
+
+	backendRefs := []v1beta1.HTTPBackendRef{
+		{
+			BackendRef: v1beta1.BackendRef{
+				BackendObjectReference: v1beta1.BackendObjectReference{
+					Name: "service1",
+					Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+				},
+			},
+		},
+	}
+
 	hr := &v1beta1.HTTPRoute{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "test",
 			Name:      "route1",
 		},
 		Spec: v1beta1.HTTPRouteSpec{
-			Hostnames: []v1beta1.Hostname{
-				"cafe.example.com",
-			},
 			Rules: []v1beta1.HTTPRouteRule{
 				{
 					Matches: []v1beta1.HTTPRouteMatch{
 						{
 							Path: &v1beta1.HTTPPathMatch{
-								Value: helpers.GetStringPointer("/"),
+								Value: helpers.GetStringPointer("/coffee"),
 							},
-							Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodPost),
 						},
+					},
+					Filters: []v1beta1.HTTPRouteFilter{
 						{
-							Path: &v1beta1.HTTPPathMatch{
-								Value: helpers.GetStringPointer("/"),
+							Type: v1beta1.HTTPRouteFilterRequestMirror,
+							RequestMirror: &v1beta1.HTTPRequestMirrorFilter{
+								BackendRef: v1beta1.BackendObjectReference{
+									Name: "mirror",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(8080)),
+								},
 							},
-							Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodPatch),
 						},
+					},
+					BackendRefs: backendRefs,
+				},
+				{
+					Matches:     []v1beta1.HTTPRouteMatch{createHeaderMatch("v1"), createHeaderMatch("v2")},
+					BackendRefs: backendRefs,
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "cafe.example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/coffee",
+				MatchRules: []state.MatchRule{
+					{MatchIdx: 0, RuleIdx: 0, Source: hr},
+				},
+			},
+			{
+				Path: "/tea",
+				MatchRules: []state.MatchRule{
+					{MatchIdx: 0, RuleIdx: 1, Source: hr},
+					{MatchIdx: 1, RuleIdx: 1, Source: hr},
+				},
+			},
+		},
+	}
+
+	expectedServerSources := []string{"HTTPRoute test/route1"}
+	expectedLocationSources := [][]string{
+		{"HTTPRoute test/route1 rule 0"},
+		{"HTTPRoute test/route1 rule 0 match 0"},
+		{"HTTPRoute test/route1 rule 1 match 0"},
+		{"HTTPRoute test/route1 rule 1 match 1"},
+		{"HTTPRoute test/route1 rule 1 match 0", "HTTPRoute test/route1 rule 1 match 1"},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore, WithNJSAvailable(true), WithSourceComments(true))
+
+	result, _, _ := generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+	if diff := cmp.Diff(expectedServerSources, result.Sources); diff != "" {
+		t.Errorf("generate() mismatch on the sources of the server (-want +got):\n%s", diff)
+	}
+
+	locationSources := make([][]string, 0, len(result.Locations))
+	for _, l := range result.Locations {
+		locationSources = append(locationSources, l.Sources)
+	}
+	if diff := cmp.Diff(expectedLocationSources, locationSources); diff != "" {
+		t.Errorf("generate() mismatch on the sources of the locations (-want +got):\n%s", diff)
+	}
+
+	cfg, _, _ := generator.Generate(context.Background(), state.Configuration{
+		HTTPServers: []state.VirtualServer{host},
+	})
+	for _, expected := range []string{
+		"# source: HTTPRoute test/route1\n",
+		"# source: HTTPRoute test/route1 rule 0 match 0",
+		"# source: HTTPRoute test/route1 rule 1 match 1",
+	} {
+		if !strings.Contains(string(cfg), expected) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", expected, string(cfg))
+		}
+	}
+
+	generator = NewGeneratorImpl(fakeServiceStore, WithNJSAvailable(true))
+
+	cfg, _, _ = generator.Generate(context.Background(), state.Configuration{
+		HTTPServers: []state.VirtualServer{host},
+	})
+	if strings.Contains(string(cfg), "# source:") {
+		t.Errorf("Generate() generated the source comments without the option; got:\n%s", string(cfg))
+	}
+}
+
+func TestGenerateRequestTooLarge(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+	}
+
+	generator := NewGeneratorImpl(&statefakes.FakeServiceStore{})
+
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+	for _, unexpected := range []string{"client_max_body_size", "error_page 413", "@gateway_request_too_large"} {
+		if strings.Contains(string(cfg), unexpected) {
+			t.Errorf("Generate() generated %q without the options; got:\n%s", unexpected, string(cfg))
+		}
+	}
+
+	generator = NewGeneratorImpl(
+		&statefakes.FakeServiceStore{},
+		WithClientMaxBodySize("10m"),
+		WithRequestTooLargeResponse("application/json", `{"error": "request too large"}`),
+	)
+
+	expectedCommon := "client_max_body_size 10m;"
+	expectedServer := []string{
+		"error_page 413 @gateway_request_too_large;",
+		`location @gateway_request_too_large {
+		default_type application/json;
+		return 413 "{\"error\": \"request too large\"}";
+	}`,
+	}
+
+	cfg, _, _ = generator.Generate(context.Background(), conf)
+	for _, expected := range append([]string{expectedCommon}, expectedServer...) {
+		if !strings.Contains(string(cfg), expected) {
+			t.Errorf("Generate() didn't generate %q; got:\n%s", expected, string(cfg))
+		}
+	}
+
+	files, _, _ := generator.GenerateFiles(context.Background(), conf)
+	if main := string(files[HTTPServersMainFile]); !strings.Contains(main, expectedCommon) {
+		t.Errorf("GenerateFiles() didn't generate %q in the main file; got:\n%s", expectedCommon, main)
+	}
+	var serverFile string
+	for name, file := range files {
+		if strings.HasSuffix(name, "-cafe.example.com.conf") {
+			serverFile = string(file)
+		}
+	}
+	for _, expected := range expectedServer {
+		if !strings.Contains(serverFile, expected) {
+			t.Errorf("GenerateFiles() didn't generate %q in the server file; got:\n%s", expected, serverFile)
+		}
+	}
+}
+
+func TestGenerateUpstreamKeepalive(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
 						{
 							Path: &v1beta1.HTTPPathMatch{
-								Value: helpers.GetStringPointer("/"), // should generate an "any" httpmatch since other matches exists for /
+								Value: helpers.GetStringPointer("/"),
 							},
 						},
 					},
@@ -140,59 +6344,278 @@ func TestGenerate(t *testing.T) {
 						{
 							BackendRef: v1beta1.BackendRef{
 								BackendObjectReference: v1beta1.BackendObjectReference{
-									Name:      "service1",
-									Namespace: (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
-									Port:      (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
 								},
 							},
 						},
 					},
 				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	locationDirectives := []string{
+		"proxy_http_version 1.1;",
+		`proxy_set_header Connection "";`,
+		"proxy_pass http://test_route1_rule0$request_uri;",
+	}
+
+	tests := []struct {
+		options           []GeneratorOption
+		expectedKeepalive string
+		msg               string
+	}{
+		{
+			expectedKeepalive: "keepalive 16;",
+			msg:               "default pool size",
+		},
+		{
+			options:           []GeneratorOption{WithUpstreamKeepalive(64)},
+			expectedKeepalive: "keepalive 64;",
+			msg:               "custom pool size",
+		},
+		{
+			options: []GeneratorOption{WithUpstreamKeepalive(0)},
+			msg:     "disabled keepalive connections",
+		},
+	}
+
+	for _, test := range tests {
+		fakeServiceStore := &statefakes.FakeServiceStore{}
+		fakeServiceStore.ResolveReturns("10.0.0.1:80", nil)
+
+		generator := NewGeneratorImpl(fakeServiceStore, test.options...)
+
+		cfg, _, _ := generator.Generate(context.Background(), conf)
+
+		upstreamStart := strings.Index(string(cfg), "upstream test_route1_rule0 {")
+		if upstreamStart == -1 {
+			t.Errorf("Generate() didn't generate the upstream for the case of %q; got:\n%s", test.msg, string(cfg))
+			continue
+		}
+		upstreamBlock := string(cfg)[upstreamStart:]
+		upstreamBlock = upstreamBlock[:strings.Index(upstreamBlock, "}")]
+
+		if test.expectedKeepalive == "" {
+			if strings.Contains(upstreamBlock, "keepalive") {
+				t.Errorf("Generate() generated keepalive for the case of %q; got:\n%s", test.msg, upstreamBlock)
+			}
+		} else if !strings.Contains(upstreamBlock, test.expectedKeepalive) {
+			t.Errorf("Generate() didn't generate %q in the upstream for the case of %q; got:\n%s",
+				test.expectedKeepalive, test.msg, upstreamBlock)
+		}
+
+		for _, expected := range locationDirectives {
+			if !strings.Contains(string(cfg), expected) {
+				t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", expected, test.msg, string(cfg))
+			}
+		}
+	}
+}
+
+func TestGenerateUpstreamZone(t *testing.T) {
+	createBackendRef := func(name string, filters ...v1beta1.HTTPRouteFilter) v1beta1.HTTPBackendRef {
+		return v1beta1.HTTPBackendRef{
+			BackendRef: v1beta1.BackendRef{
+				BackendObjectReference: v1beta1.BackendObjectReference{
+					Name: v1beta1.ObjectName(name),
+					Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+				},
+			},
+			Filters: filters,
+		}
+	}
+
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/coffee"),
+							},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{createBackendRef("coffee")},
+				},
 				{
 					Matches: []v1beta1.HTTPRouteMatch{
 						{
 							Path: &v1beta1.HTTPPathMatch{
-								Value: helpers.GetStringPointer("/test"),
+								Value: helpers.GetStringPointer("/tea"),
 							},
-							Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodGet),
-							Headers: []v1beta1.HTTPHeaderMatch{
-								{
-									Type:  helpers.GetHeaderMatchTypePointer(v1beta1.HeaderMatchExact),
-									Name:  "Version",
-									Value: "V1",
-								},
-								{
-									Type:  helpers.GetHeaderMatchTypePointer(v1beta1.HeaderMatchExact),
-									Name:  "test",
-									Value: "foo",
-								},
-								{
-									Type:  helpers.GetHeaderMatchTypePointer(v1beta1.HeaderMatchExact),
-									Name:  "my-header",
-									Value: "my-value",
-								},
+						},
+					},
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						createBackendRef("tea"),
+						createBackendRef("tea-canary", v1beta1.HTTPRouteFilter{
+							Type: v1beta1.HTTPRouteFilterRequestHeaderModifier,
+							RequestHeaderModifier: &v1beta1.HTTPRequestHeaderFilter{
+								Set: []v1beta1.HTTPHeader{{Name: "X-Version", Value: "canary"}},
 							},
-							QueryParams: []v1beta1.HTTPQueryParamMatch{
-								{
-									Type:  helpers.GetQueryParamMatchTypePointer(v1beta1.QueryParamMatchExact),
-									Name:  "GrEat", // query names and values should not be normalized to lowercase
-									Value: "EXAMPLE",
-								},
-								{
-									Type:  helpers.GetQueryParamMatchTypePointer(v1beta1.QueryParamMatchExact),
-									Name:  "test",
-									Value: "foo=bar",
-								},
+						}),
+					},
+				},
+			},
+		},
+	}
+
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "/coffee",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
+					},
+					{
+						Path: "/tea",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  1,
+								Source:   hr,
 							},
 						},
 					},
-					BackendRefs: nil, // no backend refs will cause warnings
 				},
+			},
+		},
+	}
+
+	// the rule with the header filters has an upstream for every backend
+	upstreamNames := []string{
+		"test_route1_rule0",
+		"test_route1_rule1_backend0",
+		"test_route1_rule1_backend1",
+	}
+
+	tests := []struct {
+		options      []GeneratorOption
+		expectedSize string
+		msg          string
+	}{
+		{
+			expectedSize: "64k",
+			msg:          "default size",
+		},
+		{
+			options:      []GeneratorOption{WithUpstreamZoneSize("1m")},
+			expectedSize: "1m",
+			msg:          "custom size",
+		},
+		{
+			options: []GeneratorOption{WithUpstreamZoneSize("")},
+			msg:     "disabled zones",
+		},
+	}
+
+	for _, test := range tests {
+		fakeServiceStore := &statefakes.FakeServiceStore{}
+		fakeServiceStore.ResolveReturns("10.0.0.1:80", nil)
+
+		generator := NewGeneratorImpl(fakeServiceStore, test.options...)
+
+		cfg, warnings, _ := generator.Generate(context.Background(), conf)
+		if len(warnings) != 0 {
+			t.Errorf("Generate() returned unexpected warnings for the case of %q: %v", test.msg, warnings)
+		}
+
+		for _, name := range upstreamNames {
+			upstreamStart := strings.Index(string(cfg), "upstream "+name+" {")
+			if upstreamStart == -1 {
+				t.Errorf("Generate() didn't generate the upstream %s for the case of %q; got:\n%s",
+					name, test.msg, string(cfg))
+				continue
+			}
+			upstreamBlock := string(cfg)[upstreamStart:]
+			upstreamBlock = upstreamBlock[:strings.Index(upstreamBlock, "}")]
+
+			if test.expectedSize == "" {
+				if strings.Contains(upstreamBlock, "zone") {
+					t.Errorf("Generate() generated a zone for the case of %q; got:\n%s", test.msg, upstreamBlock)
+				}
+				continue
+			}
+
+			expected := "zone " + name + " " + test.expectedSize + ";"
+			if !strings.Contains(upstreamBlock, expected) {
+				t.Errorf("Generate() didn't generate %q in the upstream for the case of %q; got:\n%s",
+					expected, test.msg, upstreamBlock)
+			}
+		}
+
+		// the zones of the upstreams must have unique names
+		zones := make(map[string]int)
+		for _, line := range strings.Split(string(cfg), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 3 && fields[0] == "zone" {
+				zones[fields[1]]++
+			}
+		}
+
+		expectedZones := len(upstreamNames)
+		if test.expectedSize == "" {
+			expectedZones = 0
+		}
+		if len(zones) != expectedZones {
+			t.Errorf("Generate() generated %d zones for the case of %q but expected %d; got:\n%s",
+				len(zones), test.msg, expectedZones, string(cfg))
+		}
+		for name, count := range zones {
+			if count != 1 {
+				t.Errorf("Generate() generated the zone %s %d times for the case of %q", name, count, test.msg)
+			}
+		}
+	}
+}
+
+func TestGenerateFixedResponsesContentType(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
 				{
 					Matches: []v1beta1.HTTPRouteMatch{
 						{
 							Path: &v1beta1.HTTPPathMatch{
-								Value: helpers.GetStringPointer("/path-only"),
+								Value: helpers.GetStringPointer("/coffee"),
 							},
 						},
 					},
@@ -200,9 +6623,8 @@ func TestGenerate(t *testing.T) {
 						{
 							BackendRef: v1beta1.BackendRef{
 								BackendObjectReference: v1beta1.BackendObjectReference{
-									Name:      "service2",
-									Namespace: (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
-									Port:      (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
 								},
 							},
 						},
@@ -212,174 +6634,469 @@ func TestGenerate(t *testing.T) {
 		},
 	}
 
-	certPath := "/etc/nginx/secrets/cert"
-
-	httpHost := state.VirtualServer{
-		Hostname: "example.com",
-		PathRules: []state.PathRule{
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
 			{
-				Path: "/",
-				MatchRules: []state.MatchRule{
-					{
-						MatchIdx: 0,
-						RuleIdx:  0,
-						Source:   hr,
-					},
-					{
-						MatchIdx: 1,
-						RuleIdx:  0,
-						Source:   hr,
-					},
+				Hostname: "cafe.example.com",
+				PathRules: []state.PathRule{
 					{
-						MatchIdx: 2,
-						RuleIdx:  0,
-						Source:   hr,
+						Path: "/coffee",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr,
+							},
+						},
 					},
 				},
 			},
 			{
-				Path: "/test",
-				MatchRules: []state.MatchRule{
-					{
-						MatchIdx: 0,
-						RuleIdx:  1,
-						Source:   hr,
-					},
-				},
+				Hostname: "no-routes.example.com",
 			},
+		},
+		HTTPRedirects: []state.WWWRedirect{
 			{
-				Path: "/path-only",
-				MatchRules: []state.MatchRule{
-					{
-						MatchIdx: 0,
-						RuleIdx:  2,
-						Source:   hr,
-					},
-				},
+				Hostname: "www.cafe.example.com",
+				Target:   "cafe.example.com",
 			},
 		},
 	}
 
-	httpsHost := httpHost
-	httpsHost.SSL = &state.SSL{CertificatePath: certPath}
-
 	fakeServiceStore := &statefakes.FakeServiceStore{}
-	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+	fakeServiceStore.ResolveReturns("", errors.New("no cluster IP"))
 
-	expectedMatchString := func(m []httpMatch) string {
-		b, err := json.Marshal(m)
-		if err != nil {
-			t.Errorf("error marshaling test match: %v", err)
+	generator := NewGeneratorImpl(
+		fakeServiceStore,
+		WithNoBackendsResponse(503, "no available backends"),
+		WithStatusOverrides(map[int]int{500: 504}),
+		WithDefaultType("application/octet-stream"),
+	)
+
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+
+	// getLocationBlock returns the location block that includes the text.
+	getLocationBlock := func(text string) string {
+		for _, block := range strings.Split(string(cfg), "location ") {
+			if strings.Contains(block, text) {
+				return block
+			}
 		}
-		return string(b)
+		return ""
 	}
 
-	slashMatches := []httpMatch{
-		{Method: v1beta1.HTTPMethodPost, RedirectPath: "/_route0"},
-		{Method: v1beta1.HTTPMethodPatch, RedirectPath: "/_route1"},
-		{Any: true, RedirectPath: "/_route2"},
-	}
-	testMatches := []httpMatch{
+	tests := []struct {
+		returnDirective string
+		expected        string
+		msg             string
+	}{
 		{
-			Method:       v1beta1.HTTPMethodGet,
-			Headers:      []string{"Version:V1", "test:foo", "my-header:my-value"},
-			QueryParams:  []string{"GrEat=EXAMPLE", "test=foo=bar"},
-			RedirectPath: "/test_route0",
+			returnDirective: `return 503 "no available backends";`,
+			expected:        "default_type text/plain;",
+			msg:             "no backends response",
+		},
+		{
+			returnDirective: "return 404;",
+			expected:        "default_type text/html;",
+			msg:             "server without routes",
+		},
+		{
+			returnDirective: `return 301 "$scheme://cafe.example.com$request_uri";`,
+			expected:        "default_type text/html;",
+			msg:             "www redirect",
+		},
+		{
+			returnDirective: "return 504;",
+			expected:        "default_type text/html;",
+			msg:             "status override",
 		},
 	}
 
-	const backendAddr = "http://10.0.0.1:80"
+	for _, test := range tests {
+		block := getLocationBlock(test.returnDirective)
+		if block == "" {
+			t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", test.returnDirective, test.msg, string(cfg))
+			continue
+		}
+		if !strings.Contains(block, test.expected) {
+			t.Errorf("Generate() didn't generate %q for the case of %q; got:\n%s", test.expected, test.msg, block)
+		}
+	}
+}
 
-	expectedHTTPServer := server{
-		ServerName: "example.com",
-		Locations: []location{
-			{
-				Path:      "/_route0",
-				Internal:  true,
-				ProxyPass: backendAddr,
+func createTCPRoute(refs []v1alpha2.BackendRef) *v1alpha2.TCPRoute {
+	return &v1alpha2.TCPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "tr",
+		},
+		Spec: v1alpha2.TCPRouteSpec{
+			Rules: []v1alpha2.TCPRouteRule{
+				{
+					BackendRefs: refs,
+				},
 			},
-			{
-				Path:      "/_route1",
-				Internal:  true,
-				ProxyPass: backendAddr,
+		},
+	}
+}
+
+func TestGenerateStream(t *testing.T) {
+	tr := createTCPRoute([]v1alpha2.BackendRef{
+		{
+			BackendObjectReference: v1alpha2.BackendObjectReference{
+				Name: "service1",
+				Port: (*v1alpha2.PortNumber)(helpers.GetInt32Pointer(5432)),
 			},
+		},
+	})
+
+	conf := state.Configuration{
+		TCPServers: []state.TCPServer{
 			{
-				Path:      "/_route2",
-				Internal:  true,
-				ProxyPass: backendAddr,
+				Port:   5432,
+				Source: tr,
 			},
-			{
-				Path:         "/",
-				HTTPMatchVar: expectedMatchString(slashMatches),
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	cfg, warnings, _ := generator.GenerateStream(context.Background(), conf)
+
+	expectedServer := "server {\n\tlisten 5432;\n\n\tproxy_pass 10.0.0.1:5432;\n}"
+	if !strings.Contains(string(cfg), expectedServer) {
+		t.Errorf("GenerateStream() did not generate the stream server %q; got:\n%s", expectedServer, string(cfg))
+	}
+
+	if len(warnings) > 0 {
+		t.Errorf("GenerateStream() returned unexpected warnings: %v", warnings)
+	}
+
+	expectedNsName := types.NamespacedName{Namespace: "test", Name: "service1"}
+	_, nsname, port := fakeServiceStore.ResolveArgsForCall(0)
+	if nsname != expectedNsName {
+		t.Errorf("GenerateStream() resolved %v but expected %v", nsname, expectedNsName)
+	}
+	if port != 5432 {
+		t.Errorf("GenerateStream() resolved port %d but expected 5432", port)
+	}
+}
+
+func TestGenerateStreamUnresolvedBackend(t *testing.T) {
+	tr := createTCPRoute([]v1alpha2.BackendRef{
+		{
+			BackendObjectReference: v1alpha2.BackendObjectReference{
+				Name: "service1",
+				Port: (*v1alpha2.PortNumber)(helpers.GetInt32Pointer(5432)),
 			},
+		},
+	})
+
+	conf := state.Configuration{
+		TCPServers: []state.TCPServer{
 			{
-				Path:      "/test_route0",
-				Internal:  true,
-				ProxyPass: "http://" + nginx502Server,
+				Port:   5432,
+				Source: tr,
 			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("", errors.New(""))
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	cfg, warnings, _ := generator.GenerateStream(context.Background(), conf)
+
+	if strings.Contains(string(cfg), "listen 5432;") {
+		t.Errorf("GenerateStream() generated a stream server for an unresolved backend; got:\n%s", string(cfg))
+	}
+
+	expectedWarnings := Warnings{
+		tr: []string{"service test/service1 cannot be resolved: "},
+	}
+	if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+		t.Errorf("GenerateStream() mismatch on warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateStreamIgnoredBackendRefs(t *testing.T) {
+	ref := v1alpha2.BackendRef{
+		BackendObjectReference: v1alpha2.BackendObjectReference{
+			Name: "service1",
+			Port: (*v1alpha2.PortNumber)(helpers.GetInt32Pointer(5432)),
+		},
+	}
+
+	tr := createTCPRoute([]v1alpha2.BackendRef{ref, ref})
+	tr.Spec.Rules = append(tr.Spec.Rules, v1alpha2.TCPRouteRule{
+		BackendRefs: []v1alpha2.BackendRef{ref},
+	})
+
+	conf := state.Configuration{
+		TCPServers: []state.TCPServer{
 			{
-				Path:         "/test",
-				HTTPMatchVar: expectedMatchString(testMatches),
+				Port:   5432,
+				Source: tr,
+			},
+		},
+	}
+
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	generator := NewGeneratorImpl(fakeServiceStore)
+
+	cfg, warnings, _ := generator.GenerateStream(context.Background(), conf)
+
+	if !strings.Contains(string(cfg), "listen 5432;") {
+		t.Errorf("GenerateStream() did not generate the stream server; got:\n%s", string(cfg))
+	}
+
+	expectedWarnings := Warnings{
+		tr: []string{
+			"only the first backendRef of the first rule is supported; backendRef 1 of rule 0 is ignored",
+			"only the first backendRef of the first rule is supported; backendRef 0 of rule 1 is ignored",
+		},
+	}
+	if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+		t.Errorf("GenerateStream() mismatch on warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateStreamServer(t *testing.T) {
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	tests := []struct {
+		tcpServer   state.TCPServer
+		expected    streamServer
+		expectedErr bool
+		msg         string
+	}{
+		{
+			tcpServer: state.TCPServer{
+				Port: 8080,
+				Source: createTCPRoute([]v1alpha2.BackendRef{
+					{
+						BackendObjectReference: v1alpha2.BackendObjectReference{
+							Name: "service1",
+							Port: (*v1alpha2.PortNumber)(helpers.GetInt32Pointer(80)),
+						},
+					},
+				}),
+			},
+			expected: streamServer{
+				Listen:    "8080",
+				ProxyPass: "10.0.0.1:80",
+			},
+			msg: "normal case",
+		},
+		{
+			tcpServer: state.TCPServer{
+				Port:   8080,
+				Source: createTCPRoute(nil),
+			},
+			expected:    streamServer{},
+			expectedErr: true,
+			msg:         "empty backend refs",
+		},
+	}
+
+	for _, test := range tests {
+		generator := NewGeneratorImpl(fakeServiceStore)
+
+		result, err := generator.generateStreamServer(context.Background(), test.tcpServer, make(state.BackendResolutions))
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateStreamServer() %q mismatch (-want +got):\n%s", test.msg, diff)
+		}
+		if test.expectedErr && err == nil {
+			t.Errorf("generateStreamServer() didn't return any error for case %q", test.msg)
+		}
+		if !test.expectedErr && err != nil {
+			t.Errorf("generateStreamServer() returned unexpected error %v for case %q", err, test.msg)
+		}
+	}
+}
+
+func TestGenerateProxyPass(t *testing.T) {
+	expected := "http://10.0.0.1:80"
+
+	result := generateProxyPass("10.0.0.1:80")
+	if result != expected {
+		t.Errorf("generateProxyPass() returned %s but expected %s", result, expected)
+	}
+
+	expected = "http://" + fallbackUpstreamName
+
+	result = generateProxyPass("")
+	if result != expected {
+		t.Errorf("generateProxyPass() returned %s but expected %s", result, expected)
+	}
+
+	expected = "http://unix:/var/run/app.sock:"
+
+	result = generateProxyPass("unix:/var/run/app.sock")
+	if result != expected {
+		t.Errorf("generateProxyPass() returned %s but expected %s", result, expected)
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/foo", expected: "/foo"},
+		{path: "//foo", expected: "/foo"},
+		{path: "/foo///bar/", expected: "/foo/bar/"},
+		{path: "/%66oo", expected: "/foo"},
+		{path: "/foo%2Fbar", expected: "/foo%2Fbar"},
+		{path: "/foo%20bar", expected: "/foo%20bar"},
+		{path: "/foo%2", expected: "/foo%2"},
+		{path: "/%2F%2F", expected: "/%2F%2F"},
+	}
+
+	for _, test := range tests {
+		if result := normalizePath(test.path); result != test.expected {
+			t.Errorf("normalizePath(%q) returned %q but expected %q", test.path, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateWithPathNormalization(t *testing.T) {
+	createRoute := func(name string, path string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test",
+				Name:      name,
 			},
-			{
-				Path:      "/path-only",
-				ProxyPass: backendAddr,
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{
+							{
+								Path: &v1beta1.HTTPPathMatch{
+									Value: helpers.GetStringPointer(path),
+								},
+							},
+						},
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							{
+								BackendRef: v1beta1.BackendRef{
+									BackendObjectReference: v1beta1.BackendObjectReference{
+										Name: "service1",
+										Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									},
+								},
+							},
+						},
+					},
+				},
 			},
-		},
+		}
 	}
 
-	expectedHTTPSServer := expectedHTTPServer
-	expectedHTTPSServer.SSL = &ssl{Certificate: certPath, CertificateKey: certPath}
+	hr1 := createRoute("route1", "//foo")
+	hr2 := createRoute("route2", "/foo")
 
-	expectedWarnings := Warnings{
-		hr: []string{"empty backend refs"},
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "example.com",
+				PathRules: []state.PathRule{
+					{
+						Path: "//foo",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr1,
+							},
+						},
+					},
+					{
+						Path: "/foo",
+						MatchRules: []state.MatchRule{
+							{
+								MatchIdx: 0,
+								RuleIdx:  0,
+								Source:   hr2,
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
-	testcases := []struct {
-		host        state.VirtualServer
-		expWarnings Warnings
-		expResult   server
-		msg         string
+	fakeServiceStore := &statefakes.FakeServiceStore{}
+	fakeServiceStore.ResolveReturns("10.0.0.1", nil)
+
+	tests := []struct {
+		expectedCounts map[string]int
+		msg            string
+		enabled        bool
 	}{
 		{
-			host:        httpHost,
-			expWarnings: expectedWarnings,
-			expResult:   expectedHTTPServer,
-			msg:         "http server",
+			enabled: true,
+			expectedCounts: map[string]int{
+				"merge_slashes on;": 1,
+				"location /foo {":   1,
+				"location //foo {":  0,
+			},
+			msg: "normalization enabled",
 		},
 		{
-			host:        httpsHost,
-			expWarnings: expectedWarnings,
-			expResult:   expectedHTTPSServer,
-			msg:         "https server",
+			enabled: false,
+			expectedCounts: map[string]int{
+				"merge_slashes on;": 0,
+				"location /foo {":   1,
+				"location //foo {":  1,
+			},
+			msg: "normalization disabled",
 		},
 	}
 
-	for _, tc := range testcases {
-		result, warnings := generate(tc.host, fakeServiceStore)
+	for _, test := range tests {
+		generator := NewGeneratorImpl(fakeServiceStore, WithPathNormalization(test.enabled))
 
-		if diff := cmp.Diff(tc.expResult, result); diff != "" {
-			t.Errorf("generate() mismatch (-want +got):\n%s", diff)
-		}
-		if diff := cmp.Diff(tc.expWarnings, warnings); diff != "" {
-			t.Errorf("generate() mismatch on warnings (-want +got):\n%s", diff)
+		cfg, _, _ := generator.Generate(context.Background(), conf)
+
+		for directive, expected := range test.expectedCounts {
+			if count := strings.Count(string(cfg), directive); count != expected {
+				t.Errorf("Generate() generated %q %d times but expected %d for the case of %q; got:\n%s",
+					directive, count, expected, test.msg, string(cfg))
+			}
 		}
 	}
-}
-
-func TestGenerateProxyPass(t *testing.T) {
-	expected := "http://10.0.0.1:80"
 
-	result := generateProxyPass("10.0.0.1:80")
-	if result != expected {
-		t.Errorf("generateProxyPass() returned %s but expected %s", result, expected)
+	// the rules of the Configuration are not modified by the merging of the rules
+	if len(conf.HTTPServers[0].PathRules[0].MatchRules) != 1 {
+		t.Errorf("Generate() modified the match rules of the Configuration")
 	}
+}
 
-	expected = "http://" + nginx502Server
+func TestIsHostnameAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected bool
+	}{
+		{address: "api.example.com:80", expected: true},
+		{address: "10.0.0.1:80", expected: false},
+		{address: "[fd00::1]:80", expected: false},
+		{address: "test_route1_rule0", expected: false},
+		{address: "unix:/var/run/app.sock", expected: false},
+		{address: "", expected: false},
+	}
 
-	result = generateProxyPass("")
-	if result != expected {
-		t.Errorf("generateProxyPass() returned %s but expected %s", result, expected)
+	for _, test := range tests {
+		if result := isHostnameAddress(test.address); result != test.expected {
+			t.Errorf("isHostnameAddress(%q) returned %v but expected %v", test.address, result, test.expected)
+		}
 	}
 }
 
@@ -389,7 +7106,7 @@ func TestGetBackendAddress(t *testing.T) {
 			{
 				BackendRef: v1beta1.BackendRef{
 					BackendObjectReference: v1beta1.BackendObjectReference{
-						Group:     (*v1beta1.Group)(helpers.GetStringPointer("networking.k8s.io")),
+						Group:     (*v1beta1.Group)(helpers.GetStringPointer("")),
 						Kind:      (*v1beta1.Kind)(helpers.GetStringPointer("Service")),
 						Name:      "service1",
 						Namespace: (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
@@ -424,7 +7141,7 @@ func TestGetBackendAddress(t *testing.T) {
 			expectedNsName:            types.NamespacedName{Namespace: "test", Name: "service1"},
 			expectedAddress:           "10.0.0.1:80",
 			expectErr:                 false,
-			msg:                       "normal case",
+			msg:                       "normal case with the core group",
 		},
 		{
 			refs: getModifiedRefs(
@@ -492,6 +7209,54 @@ func TestGetBackendAddress(t *testing.T) {
 			expectErr:                 true,
 			msg:                       "not a service Kind",
 		},
+		{
+			refs: getModifiedRefs(
+				func(refs []v1beta1.HTTPBackendRef) []v1beta1.HTTPBackendRef {
+					refs[0].BackendRef.Group = nil
+					return refs
+				},
+			),
+			parentNS:                  "test",
+			storeAddress:              "10.0.0.1",
+			storeErr:                  nil,
+			expectedResolverCallCount: 1,
+			expectedNsName:            types.NamespacedName{Namespace: "test", Name: "service1"},
+			expectedAddress:           "10.0.0.1:80",
+			expectErr:                 false,
+			msg:                       "nil group",
+		},
+		{
+			refs: getModifiedRefs(
+				func(refs []v1beta1.HTTPBackendRef) []v1beta1.HTTPBackendRef {
+					refs[0].BackendRef.Group = (*v1beta1.Group)(helpers.GetStringPointer("networking.k8s.io"))
+					return refs
+				},
+			),
+			parentNS:                  "test",
+			storeAddress:              "10.0.0.1",
+			storeErr:                  nil,
+			expectedResolverCallCount: 0,
+			expectedNsName:            types.NamespacedName{},
+			expectedAddress:           "",
+			expectErr:                 true,
+			msg:                       "unexpected group",
+		},
+		{
+			refs: getModifiedRefs(
+				func(refs []v1beta1.HTTPBackendRef) []v1beta1.HTTPBackendRef {
+					refs[0].Weight = helpers.GetInt32Pointer(0)
+					return refs
+				},
+			),
+			parentNS:                  "test",
+			storeAddress:              "10.0.0.1",
+			storeErr:                  nil,
+			expectedResolverCallCount: 0,
+			expectedNsName:            types.NamespacedName{},
+			expectedAddress:           "",
+			expectErr:                 true,
+			msg:                       "zero weight",
+		},
 		{
 			refs:                      nil,
 			parentNS:                  "test",
@@ -513,8 +7278,8 @@ func TestGetBackendAddress(t *testing.T) {
 			parentNS:                  "test",
 			storeAddress:              "10.0.0.1",
 			storeErr:                  nil,
-			expectedResolverCallCount: 1,
-			expectedNsName:            types.NamespacedName{Namespace: "test", Name: "service1"},
+			expectedResolverCallCount: 0,
+			expectedNsName:            types.NamespacedName{},
 			expectedAddress:           "",
 			expectErr:                 true,
 			msg:                       "no port",
@@ -536,7 +7301,14 @@ func TestGetBackendAddress(t *testing.T) {
 		fakeServiceStore := &statefakes.FakeServiceStore{}
 		fakeServiceStore.ResolveReturns(test.storeAddress, test.storeErr)
 
-		result, err := getBackendAddress(test.refs, test.parentNS, fakeServiceStore)
+		result, err := getBackendAddress(
+			context.Background(),
+			make(state.BackendResolutions),
+			test.refs,
+			test.parentNS,
+			fakeServiceStore,
+			nil,
+		)
 		if result != test.expectedAddress {
 			t.Errorf(
 				"getBackendAddress() returned %s but expected %s for case %q",
@@ -570,7 +7342,7 @@ func TestGetBackendAddress(t *testing.T) {
 			continue
 		}
 
-		nsname := fakeServiceStore.ResolveArgsForCall(0)
+		_, nsname, port := fakeServiceStore.ResolveArgsForCall(0)
 		if nsname != test.expectedNsName {
 			t.Errorf(
 				"getBackendAddress() called fakeServiceStore.Resolve with %v but expected %v for case %q",
@@ -579,6 +7351,13 @@ func TestGetBackendAddress(t *testing.T) {
 				test.msg,
 			)
 		}
+		if port != 80 {
+			t.Errorf(
+				"getBackendAddress() called fakeServiceStore.Resolve with port %d but expected 80 for case %q",
+				port,
+				test.msg,
+			)
+		}
 	}
 }
 
@@ -590,8 +7369,8 @@ func TestGenerateMatchLocation(t *testing.T) {
 	}
 
 	result := generateMatchLocation("/path", "10.0.0.1:80")
-	if result != expected {
-		t.Errorf("generateMatchLocation() returned %v but expected %v", result, expected)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateMatchLocation() mismatch (-want +got):\n%s", diff)
 	}
 }
 
@@ -711,6 +7490,70 @@ func TestMatchLocationNeeded(t *testing.T) {
 	}
 }
 
+func TestIsMethodOnlyMatch(t *testing.T) {
+	path := &v1beta1.HTTPPathMatch{
+		Value: helpers.GetStringPointer("/path"),
+	}
+
+	tests := []struct {
+		match    v1beta1.HTTPRouteMatch
+		expected bool
+		msg      string
+	}{
+		{
+			match: v1beta1.HTTPRouteMatch{
+				Path: path,
+			},
+			expected: false,
+			msg:      "path only match",
+		},
+		{
+			match: v1beta1.HTTPRouteMatch{
+				Path:   path,
+				Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodGet),
+			},
+			expected: true,
+			msg:      "method only match",
+		},
+		{
+			match: v1beta1.HTTPRouteMatch{
+				Path:   path,
+				Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodGet),
+				Headers: []v1beta1.HTTPHeaderMatch{
+					{
+						Name:  "header",
+						Value: "val",
+					},
+				},
+			},
+			expected: false,
+			msg:      "method and headers defined in match",
+		},
+		{
+			match: v1beta1.HTTPRouteMatch{
+				Path:   path,
+				Method: helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodGet),
+				QueryParams: []v1beta1.HTTPQueryParamMatch{
+					{
+						Name:  "arg",
+						Value: "val",
+					},
+				},
+			},
+			expected: false,
+			msg:      "method and query params defined in match",
+		},
+	}
+
+	for _, tc := range tests {
+		result := isMethodOnlyMatch(tc.match)
+
+		if result != tc.expected {
+			t.Errorf("isMethodOnlyMatch() returned %t but expected %t for test case %q", result, tc.expected, tc.msg)
+		}
+	}
+}
+
 func TestCreateHTTPMatch(t *testing.T) {
 	testPath := "/internal_loc"
 
@@ -773,13 +7616,29 @@ func TestCreateHTTPMatch(t *testing.T) {
 		},
 	}
 
+	testDuplicateQueryParams := make([]v1beta1.HTTPQueryParamMatch, 0, 6)
+	testDuplicateQueryParams = append(testDuplicateQueryParams, testQueryParamMatches...)
+	testDuplicateQueryParams = append(testDuplicateQueryParams,
+		v1beta1.HTTPQueryParamMatch{
+			Type:  helpers.GetQueryParamMatchTypePointer(v1beta1.QueryParamMatchExact),
+			Name:  "arg2",
+			Value: "other-val",
+		},
+		v1beta1.HTTPQueryParamMatch{
+			Type:  helpers.GetQueryParamMatchTypePointer(v1beta1.QueryParamMatchExact),
+			Name:  "ARG1", // query param names are case-sensitive
+			Value: "val1",
+		},
+	)
+
 	expectedHeaders := []string{"header-1:val-1", "header-2:val-2", "header-3:val-3"}
 	expectedArgs := []string{"arg1=val1", "arg2=val2=another-val", "arg3===val3"}
 
 	tests := []struct {
-		match    v1beta1.HTTPRouteMatch
-		expected httpMatch
-		msg      string
+		match              v1beta1.HTTPRouteMatch
+		expected           httpMatch
+		expectedDuplicates []string
+		msg                string
 	}{
 		{
 			match: v1beta1.HTTPRouteMatch{
@@ -880,13 +7739,118 @@ func TestCreateHTTPMatch(t *testing.T) {
 				Headers:      expectedHeaders,
 				RedirectPath: testPath,
 			},
+			expectedDuplicates: []string{
+				`the header HEADER-2 is duplicated; only its first value is used, the value "val-2" is ignored`,
+			},
 			msg: "duplicate header names",
 		},
+		{
+			match: v1beta1.HTTPRouteMatch{
+				QueryParams: testDuplicateQueryParams,
+			},
+			expected: httpMatch{
+				QueryParams:  append(expectedArgs, "ARG1=val1"),
+				RedirectPath: testPath,
+			},
+			expectedDuplicates: []string{
+				`the query parameter arg2 is duplicated; only its first value is used, the value "other-val" is ignored`,
+			},
+			msg: "duplicate query param names",
+		},
+		{
+			match: v1beta1.HTTPRouteMatch{
+				Headers: []v1beta1.HTTPHeaderMatch{
+					{
+						Name:  "header-1",
+						Value: "val-1",
+					},
+				},
+				QueryParams: []v1beta1.HTTPQueryParamMatch{
+					{
+						Name:  "arg1",
+						Value: "val1",
+					},
+				},
+			},
+			expected: httpMatch{
+				Headers:      []string{"header-1:val-1"},
+				QueryParams:  []string{"arg1=val1"},
+				RedirectPath: testPath,
+			},
+			msg: "headers and query params without type",
+		},
+		{
+			match: v1beta1.HTTPRouteMatch{
+				QueryParams: []v1beta1.HTTPQueryParamMatch{
+					{
+						Type:  helpers.GetQueryParamMatchTypePointer(v1beta1.QueryParamMatchExact),
+						Name:  "flag",
+						Value: "",
+					},
+					{
+						Type:  helpers.GetQueryParamMatchTypePointer(v1beta1.QueryParamMatchExact),
+						Name:  "arg1",
+						Value: "val1",
+					},
+				},
+			},
+			expected: httpMatch{
+				QueryParams:  []string{"flag=", "arg1=val1"},
+				RedirectPath: testPath,
+			},
+			msg: "query param with an empty value",
+		},
 	}
 	for _, tc := range tests {
-		result := createHTTPMatch(tc.match, testPath)
+		result, duplicates := createHTTPMatch(tc.match, testPath)
 		if diff := helpers.Diff(result, tc.expected); diff != "" {
 			t.Errorf("createHTTPMatch() returned incorrect httpMatch for test case: %q, diff: %+v", tc.msg, diff)
 		}
+		if diff := helpers.Diff(duplicates, tc.expectedDuplicates); diff != "" {
+			t.Errorf("createHTTPMatch() returned incorrect duplicates for test case: %q, diff: %+v", tc.msg, diff)
+		}
+	}
+}
+
+func TestCreateHTTPMatchEmptyQueryParamValue(t *testing.T) {
+	match := v1beta1.HTTPRouteMatch{
+		QueryParams: []v1beta1.HTTPQueryParamMatch{
+			{
+				Type:  helpers.GetQueryParamMatchTypePointer(v1beta1.QueryParamMatchExact),
+				Name:  "flag",
+				Value: "",
+			},
+		},
+	}
+
+	hm, _ := createHTTPMatch(match, "/internal_loc")
+
+	tests := []struct {
+		query    map[string]string
+		expected bool
+		msg      string
+	}{
+		{
+			query:    map[string]string{"flag": ""},
+			expected: true,
+			msg:      "present with an empty value",
+		},
+		{
+			query:    nil,
+			expected: false,
+			msg:      "absent",
+		},
+		{
+			query:    map[string]string{"flag": "on"},
+			expected: false,
+			msg:      "present with a non-empty value",
+		},
+	}
+
+	for _, test := range tests {
+		_, ok := matchRequest([]httpMatch{hm}, "GET", nil, test.query)
+		if ok != test.expected {
+			t.Errorf("matchRequest() returned %t but expected %t for the case of %q", ok, test.expected, test.msg)
+		}
 	}
 }
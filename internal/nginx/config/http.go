@@ -1,15 +1,113 @@
 package config
 
 type httpServers struct {
-	Servers []server
+	RequestID requestID
+	// ForwardedHeaders enables passing the X-Forwarded-For, X-Forwarded-Proto and X-Real-IP headers to the backends.
+	ForwardedHeaders bool
+	// TrustedProxies are the CIDRs of the proxies whose X-Forwarded-For header sets the client address.
+	TrustedProxies []string
+	// StatusOverrides translates the status codes of the backend responses to different status codes.
+	StatusOverrides statusOverrides
+	// SSLCertificates maps the hostnames of the SSL servers to their certificates. If not empty, NGINX selects
+	// the certificate of an SSL connection by its SNI during the handshake. The first certificate is the default
+	// for the SNIs that don't match any hostname, so that NGINX never loads an empty certificate path.
+	SSLCertificates []sslCertificate
+	// DefaultType is the default content type of the responses. Empty means the NGINX default.
+	DefaultType string
+	// HTTPListen and HTTPSListen are the addresses the servers listen on for HTTP and HTTPS.
+	// There is an address per IP family.
+	HTTPListen  []string
+	HTTPSListen []string
+	// SecurityHeaders enables the preset of the security headers in the responses of the servers for the HTTPRoutes.
+	SecurityHeaders bool
+	// OCSPStapling configures OCSP stapling for the SSL servers. nil means stapling is disabled.
+	OCSPStapling *ocspStapling
+	// HSTS is the value of the Strict-Transport-Security header in the responses of the SSL servers for
+	// the HTTPRoutes. Empty means the header is not added.
+	HSTS string
+	// MergeSlashes enables the merging of the duplicate slashes of the request URIs.
+	MergeSlashes bool
+	// ServerTokens enables the version of NGINX in the Server header and the error pages.
+	ServerTokens bool
+	// ClientMaxBodySize is the maximum size of the request bodies. Empty means the NGINX default.
+	ClientMaxBodySize string
+	// RequestTooLarge is the response to the requests whose bodies exceed the maximum size.
+	// nil means the NGINX default page.
+	RequestTooLarge *returnVal
+	// Resolver is the addresses of the DNS servers that resolve the hostnames of the backends at runtime.
+	// It is only set if a location proxies the requests to a backend with a hostname.
+	Resolver  []string
+	Upstreams []upstream
+	// BackendSplits are the splits of the requests of the rules whose backendRefs modify the request headers.
+	BackendSplits []backendSplit
+	Servers       []server
+}
+
+// ocspStapling configures OCSP stapling.
+type ocspStapling struct {
+	// TrustedCertificate is the path of the CA certificate that verifies the OCSP responses.
+	TrustedCertificate string
+}
+
+// sslCertificate is the certificate of the SSL server with the hostname.
+type sslCertificate struct {
+	Hostname string
+	// Path is the path of the file with the certificate.
+	Path string
+	// KeyPath is the path of the file with the key of the certificate.
+	KeyPath string
+}
+
+// statusOverrides translates the status codes of the backend responses to different status codes.
+type statusOverrides struct {
+	// Overrides are sorted by the backend status code.
+	Overrides []statusOverride
+	// Codes are the unique client-facing status codes of the Overrides. Every server includes a named location
+	// per code that responds with the code.
+	Codes []statusCode
+}
+
+type statusOverride struct {
+	// From is the status code of the backend response.
+	From statusCode
+	// To is the status code of the response to the client.
+	To statusCode
+}
+
+// requestID configures the propagation of the ID of a request.
+type requestID struct {
+	// Header is the name of the header that carries the ID.
+	Header string
+	// HeaderVar is the NGINX variable of the request header. For example, $http_x_request_id.
+	HeaderVar string
 }
 
 type server struct {
-	SSL           *ssl
-	ServerName    string
+	SSL        *ssl
+	ServerName string
+	// Listen is the address the server listens on. It is only set for the fallback server.
+	Listen        string
 	Locations     []location
 	IsDefaultHTTP bool
 	IsDefaultSSL  bool
+	IsFallback    bool
+	// HealthCheck is set for the server of the health check endpoint.
+	HealthCheck *healthCheckServer
+	// Sources are the HTTPRoutes that produced the server, rendered as comments.
+	// Empty means the comments are disabled.
+	Sources []string
+	// Snippets are the lines of the NGINX directives of the server snippets of the HTTPRoutes.
+	Snippets []string
+}
+
+// healthCheckServer is the server that responds with 200 to the requests for the health check path.
+type healthCheckServer struct {
+	// Listen are the addresses the server listens on. There is an address per IP family.
+	Listen []string
+	// Path is the path of the health check endpoint.
+	Path string
+	// AccessLog enables writing the requests to the access log.
+	AccessLog bool
 }
 
 type location struct {
@@ -17,11 +115,114 @@ type location struct {
 	Path         string
 	ProxyPass    string
 	HTTPMatchVar string
-	Internal     bool
+	// RewriteMatches are the matches that redirect the requests to the locations of the matches when the njs module,
+	// which evaluates the HTTPMatchVar, is not available. NGINX responds with 404 to the requests that don't satisfy
+	// any match.
+	RewriteMatches []rewriteMatch
+	Internal       bool
+	// Method is the only method the location accepts. NGINX responds with 404 to the requests with other methods.
+	// Empty means all methods are accepted.
+	Method string
+	// Streaming enables chunked transfer encoding and disables response buffering.
+	Streaming bool
+	// ProxyBuffering is on or off to enable or disable response buffering. Empty means the NGINX default.
+	ProxyBuffering string
+	// ProxyBuffers and ProxyBufferSize are the sizes of the response buffers. Empty means the NGINX default.
+	ProxyBuffers    string
+	ProxyBufferSize string
+	// ForceRanges enables byte-range support regardless of the Accept-Ranges header of the backend responses.
+	ForceRanges bool
+	// MaxRanges is the maximum allowed number of ranges in byte-range requests. nil means no limit.
+	MaxRanges *int
+	// ProxyHTTPVersion is the version of HTTP used to proxy requests to the backends. Empty means 1.1.
+	// With HTTP/1.1, the Connection header of the requests is cleared, so that the connections to the backends can be
+	// kept alive.
+	ProxyHTTPVersion string
+	// WebSocket enables WebSocket connections to the backends by passing the Upgrade and Connection headers.
+	WebSocket bool
+	// CacheBypass and NoCache are the NGINX variables that make NGINX bypass the cache and not save the responses
+	// to the cache. Empty means the NGINX defaults.
+	CacheBypass []string
+	NoCache     []string
+	// Mirror is the path of the internal location the requests are mirrored to. Empty means no mirroring.
+	Mirror string
+	// ProxySSL configures HTTPS for proxying requests to the backends. nil means the backends are proxied over HTTP.
+	ProxySSL *proxySSL
+	// ProxyConnectTimeout, ProxyReadTimeout and ProxySendTimeout are the timeouts of proxying requests to
+	// the backends in the NGINX time format. Empty means the NGINX default.
+	ProxyConnectTimeout string
+	ProxyReadTimeout    string
+	ProxySendTimeout    string
+	// ProxyNextUpstream are the conditions under which a request is passed to the next server of the upstream.
+	// Empty means the NGINX default.
+	ProxyNextUpstream []string
+	// ProxyNextUpstreamTries limits the number of the tries to pass a request to the next server. Zero means
+	// the NGINX default.
+	ProxyNextUpstreamTries int
+	// ProxyNextUpstreamTimeout limits the time during which a request can be passed to the next server in the NGINX
+	// time format. Empty means the NGINX default.
+	ProxyNextUpstreamTimeout string
+	// CORS holds the CORS headers of the responses. If the location is not internal, NGINX also responds to
+	// the preflight requests. nil means CORS is not enabled.
+	// The headers of a location disable the inheritance of the headers of the server, so the location repeats
+	// the security headers of the server.
+	CORS *cors
+	// Sources are the matches of the HTTPRoutes that produced the location, rendered as comments.
+	// Empty means the comments are disabled.
+	Sources []string
+	// Snippets are the lines of the NGINX directives of the location snippets of the HTTPRoute, which are added to
+	// the end of the location.
+	Snippets []string
+	// BackendSplit is the split of the requests among the backends of the rule, which sets the request headers of
+	// the selected backend. nil means the backendRefs of the rule don't modify the request headers.
+	BackendSplit *backendSplit
+}
+
+// rewriteMatch is a match that NGINX evaluates with the directives of the rewrite module.
+type rewriteMatch struct {
+	// Conditions are the conditions the request must satisfy. Empty means the match is satisfied by all requests.
+	Conditions []rewriteCondition
+	// RedirectPath is the path of the location the request is redirected to if it satisfies the match.
+	RedirectPath string
+}
+
+// rewriteCondition compares an NGINX variable with a value.
+type rewriteCondition struct {
+	Variable string
+	// Value is the value the variable must be equal to. If CaseInsensitive, it is a regular expression the variable
+	// must match case-insensitively.
+	Value           string
+	CaseInsensitive bool
+}
+
+// cors holds the CORS headers.
+type cors struct {
+	// AllowOrigin is the allowed origin or * for any origin.
+	AllowOrigin string
+	// AllowMethods and AllowHeaders are comma-separated lists of the allowed methods and request headers.
+	AllowMethods string
+	AllowHeaders string
+	// AllowCredentials allows the requests to include credentials.
+	AllowCredentials bool
+	// MaxAge is the number of seconds the clients can cache the results of a preflight request.
+	MaxAge int
+}
+
+type proxySSL struct {
+	// Name is the name used in the SNI and the verification of the certificates of the backends.
+	// Empty means the address of the backend, in which case the SNI is not sent.
+	Name string
+	// TrustedCertificate is the path of the CA certificate that verifies the certificates of the backends.
+	// Empty means the certificates are not verified.
+	TrustedCertificate string
 }
 
 type returnVal struct {
 	Code statusCode
+	// Body is the text of the response. It is optional.
+	Body string
+	// ContentType is the content type of the response.
+	ContentType string
 }
 
 type ssl struct {
@@ -29,6 +230,74 @@ type ssl struct {
 	CertificateKey string
 }
 
+type upstream struct {
+	Name    string
+	Servers []upstreamServer
+	// Keepalive is the maximum number of the idle keepalive connections to the servers that each NGINX worker
+	// keeps open. Zero means the connections are not kept alive.
+	Keepalive int
+	// ZoneSize is the size of the shared memory zone of the upstream, which is named after the upstream.
+	// Empty means the upstream doesn't have a zone.
+	ZoneSize string
+}
+
+// backendSplit splits the requests of a rule among its backends according to their weights, so that the requests
+// to every backend get the request headers of the filters of its backendRef.
+type backendSplit struct {
+	// Variable is the variable with the name of the upstream of the backend selected for a request.
+	Variable string
+	Backends []splitBackend
+	// Headers are the request headers whose values depend on the selected backend.
+	Headers []splitHeader
+}
+
+type splitBackend struct {
+	// Percentage is the percentage of the requests the backend receives, like 33.33%. The last backend receives
+	// the rest of the requests, so its percentage is *.
+	Percentage string
+	// Upstream is the name of the upstream of the backend.
+	Upstream string
+}
+
+// splitHeader is a request header whose value depends on the backend selected by the split.
+type splitHeader struct {
+	Name string
+	// Variable is the variable with the value of the header for the selected backend.
+	Variable string
+	// ClientVariable is the variable with the value of the header in the client request. The backends whose filters
+	// don't modify the header receive it as is.
+	ClientVariable string
+	// AppendVariable is the variable with the value of the header in the client request followed by a comma, or
+	// empty if the client request doesn't have the header. It is only set if a filter adds a value to the header.
+	AppendVariable string
+	// Values are the values of the header for the backends whose filters modify the header.
+	Values []splitHeaderValue
+}
+
+type splitHeaderValue struct {
+	// Upstream is the name of the upstream of the backend.
+	Upstream string
+	// Value is the value of the header. Empty means the header is removed.
+	Value string
+}
+
+type upstreamServer struct {
+	Address string
+	// Weight is the weight of the server. Zero means the default weight.
+	Weight int32
+}
+
 type statusCode int
 
-const statusNotFound statusCode = 404
+const (
+	statusMovedPermanently      statusCode = 301
+	statusNotFound              statusCode = 404
+	statusRequestEntityTooLarge statusCode = 413
+)
+
+const (
+	// contentTypeHTML is the content type of the responses with the NGINX pages, like the 404 and redirect pages.
+	contentTypeHTML = "text/html"
+	// contentTypeText is the content type of the responses with a text body.
+	contentTypeText = "text/plain"
+)
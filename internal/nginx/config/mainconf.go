@@ -0,0 +1,7 @@
+package config
+
+// mainConfig is the configuration of the main context of NGINX, including the events block.
+type mainConfig struct {
+	WorkerProcesses   string
+	WorkerConnections int
+}
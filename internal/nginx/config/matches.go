@@ -0,0 +1,200 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// matchRequest evaluates the matches of a path against a request the same way the njs module (see
+// internal/nginx/modules/src/httpmatches.js) evaluates the HTTPMatchVar of a location. It returns the redirect path of
+// the first match the request satisfies. The order of the matches is their precedence.
+//
+// A match is satisfied if it is Any, or if the request satisfies all its conditions:
+// - The method is equal to the Method of the match.
+// - For every header of the match, one of the comma-separated values of the request header is equal to the value of
+// the match. The header names are case-insensitive. The header values are case-sensitive, unless
+// CaseInsensitiveHeaders is set.
+// - For every query parameter of the match, the value of the request query parameter is equal to the value of
+// the match. Both the names and values are case-sensitive. A match with an empty value requires the request query
+// parameter to be present with an empty value.
+//
+// Unlike the njs module, which responds with 500 to the requests if a match is malformed, matchRequest treats
+// a malformed match as not satisfied.
+func matchRequest(matches []httpMatch, method string, headers, query map[string]string) (redirectPath string, ok bool) {
+	for _, m := range matches {
+		if testMatch(m, method, headers, query) {
+			return m.RedirectPath, true
+		}
+	}
+
+	return "", false
+}
+
+func testMatch(m httpMatch, method string, headers, query map[string]string) bool {
+	if m.Any {
+		return true
+	}
+
+	if m.Method != "" && string(m.Method) != method {
+		return false
+	}
+
+	for _, h := range m.Headers {
+		if !headerMatches(h, headers, m.CaseInsensitiveHeaders) {
+			return false
+		}
+	}
+
+	for _, p := range m.QueryParams {
+		if !queryParamMatches(p, query) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// headerMatches checks the header match in the format "{name}:{value}" against the request headers.
+func headerMatches(h string, headers map[string]string, caseInsensitive bool) bool {
+	kv := strings.Split(h, ":")
+	if len(kv) != 2 {
+		return false
+	}
+
+	name, expected := kv[0], kv[1]
+
+	var val string
+	for n, v := range headers {
+		if strings.EqualFold(n, name) {
+			val = v
+			break
+		}
+	}
+
+	if val == "" {
+		return false
+	}
+
+	// NGINX uses commas to delimit multiple header values
+	for _, v := range strings.Split(val, ",") {
+		if v == expected || (caseInsensitive && strings.EqualFold(v, expected)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// queryParamMatches checks the query parameter match in the format "{name}={value}" against the request query
+// parameters. The value can include "=" and can be empty.
+func queryParamMatches(p string, query map[string]string) bool {
+	idx := strings.Index(p, "=")
+	if idx <= 0 {
+		return false
+	}
+
+	val, exists := query[p[:idx]]
+
+	return exists && val == p[idx+1:]
+}
+
+// rewriteQueryParamNameRegexp matches the query parameter names that NGINX exposes as $arg_ variables.
+var rewriteQueryParamNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// validateRewriteMatch checks that the match can be evaluated with the directives of the rewrite module of NGINX,
+// which replace the njs module when it is not available. The rewrite module reads the headers and the query
+// parameters through the NGINX variables, so:
+// - The names must be valid in the names of the variables. NGINX ignores the headers with underscores by default.
+// - The values must not be empty, because an empty variable also means the header or the query parameter is absent.
+// - The values must not include "$", because NGINX treats it as the start of a variable.
+//
+// The header and query parameter matches of the types other than Exact are ignored, like in createHTTPMatch.
+func validateRewriteMatch(match v1beta1.HTTPRouteMatch) error {
+	for _, h := range match.Headers {
+		if h.Type != nil && *h.Type != v1beta1.HeaderMatchExact {
+			continue
+		}
+
+		if !headerNameRegexp.MatchString(string(h.Name)) {
+			return fmt.Errorf("the header name %q must only include letters, digits and -", h.Name)
+		}
+		if err := validateRewriteValue(h.Value); err != nil {
+			return fmt.Errorf("the value of the header %s %w", h.Name, err)
+		}
+	}
+
+	for _, p := range match.QueryParams {
+		if p.Type != nil && *p.Type != v1beta1.QueryParamMatchExact {
+			continue
+		}
+
+		if !rewriteQueryParamNameRegexp.MatchString(p.Name) {
+			return fmt.Errorf("the query parameter name %q must only include letters, digits and _", p.Name)
+		}
+		if err := validateRewriteValue(p.Value); err != nil {
+			return fmt.Errorf("the value of the query parameter %s %w", p.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateRewriteValue(value string) error {
+	if value == "" {
+		return errors.New("must not be empty")
+	}
+	if strings.Contains(value, "$") {
+		return errors.New(`must not include "$"`)
+	}
+
+	return nil
+}
+
+// createRewriteMatch creates the rewriteMatch that evaluates the httpMatch with the directives of the rewrite module.
+// The match must be checked by validateRewriteMatch.
+//
+// Unlike the njs module, the rewrite module compares the whole value of a header, so a request with multiple values
+// of a header doesn't satisfy a match of one of them. The names of the query parameters are case-insensitive.
+func createRewriteMatch(hm httpMatch) rewriteMatch {
+	rm := rewriteMatch{RedirectPath: hm.RedirectPath}
+
+	if hm.Any {
+		return rm
+	}
+
+	if hm.Method != "" {
+		rm.Conditions = append(rm.Conditions, rewriteCondition{
+			Variable: "$request_method",
+			Value:    string(hm.Method),
+		})
+	}
+
+	for _, h := range hm.Headers {
+		name, value, _ := strings.Cut(h, ":")
+		c := rewriteCondition{
+			Variable: getHeaderVariable(name),
+			Value:    value,
+		}
+
+		if hm.CaseInsensitiveHeaders {
+			c.Value = "^" + regexp.QuoteMeta(value) + "$"
+			c.CaseInsensitive = true
+		}
+
+		rm.Conditions = append(rm.Conditions, c)
+	}
+
+	for _, p := range hm.QueryParams {
+		name, value, _ := strings.Cut(p, "=")
+		rm.Conditions = append(rm.Conditions, rewriteCondition{
+			Variable: "$arg_" + name,
+			Value:    value,
+		})
+	}
+
+	return rm
+}
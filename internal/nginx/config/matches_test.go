@@ -0,0 +1,397 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/helpers"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state/statefakes"
+)
+
+func TestMatchRequest(t *testing.T) {
+	matches := []httpMatch{
+		{
+			Method:       v1beta1.HTTPMethodPost,
+			Headers:      []string{"version:v2"},
+			QueryParams:  []string{"debug=true"},
+			RedirectPath: "/_route0",
+		},
+		{
+			Method:       v1beta1.HTTPMethodPost,
+			Headers:      []string{"version:v2"},
+			RedirectPath: "/_route1",
+		},
+		{
+			Headers:                []string{"token:Beta"},
+			CaseInsensitiveHeaders: true,
+			RedirectPath:           "/_route2",
+		},
+		{
+			Method:       v1beta1.HTTPMethodPost,
+			RedirectPath: "/_route3",
+		},
+		{
+			QueryParams:  []string{"filter=a=b"},
+			RedirectPath: "/_route4",
+		},
+		{
+			QueryParams:  []string{"flag="},
+			RedirectPath: "/_route5",
+		},
+		{
+			Any:          true,
+			RedirectPath: "/_route6",
+		},
+	}
+
+	tests := []struct {
+		headers      map[string]string
+		query        map[string]string
+		method       string
+		expectedPath string
+		msg          string
+	}{
+		{
+			method:       "POST",
+			headers:      map[string]string{"version": "v2"},
+			query:        map[string]string{"debug": "true"},
+			expectedPath: "/_route0",
+			msg:          "all conditions of the first match",
+		},
+		{
+			method:       "POST",
+			headers:      map[string]string{"Version": "v2"},
+			expectedPath: "/_route1",
+			msg:          "the first match fails on the query parameter; header names are case-insensitive",
+		},
+		{
+			method:       "POST",
+			headers:      map[string]string{"version": "v1,v2,v3"},
+			expectedPath: "/_route1",
+			msg:          "one of multiple header values",
+		},
+		{
+			method:       "POST",
+			headers:      map[string]string{"version": "V2"},
+			expectedPath: "/_route3",
+			msg:          "header values are case-sensitive by default",
+		},
+		{
+			method:       "GET",
+			headers:      map[string]string{"token": "BETA"},
+			expectedPath: "/_route2",
+			msg:          "case-insensitive header values",
+		},
+		{
+			method:       "GET",
+			headers:      map[string]string{"version": "v2"},
+			expectedPath: "/_route6",
+			msg:          "method doesn't match, any fallback",
+		},
+		{
+			method:       "GET",
+			query:        map[string]string{"filter": "a=b"},
+			expectedPath: "/_route4",
+			msg:          "query parameter value with the delimiter",
+		},
+		{
+			method:       "GET",
+			query:        map[string]string{"Filter": "a=b"},
+			expectedPath: "/_route6",
+			msg:          "query parameter names are case-sensitive, any fallback",
+		},
+		{
+			method:       "GET",
+			query:        map[string]string{"flag": ""},
+			expectedPath: "/_route5",
+			msg:          "query parameter with an empty value",
+		},
+		{
+			method:       "GET",
+			query:        map[string]string{"flag": "on"},
+			expectedPath: "/_route6",
+			msg:          "query parameter with a non-empty value doesn't match the empty value, any fallback",
+		},
+		{
+			method:       "GET",
+			expectedPath: "/_route6",
+			msg:          "no conditions, any fallback",
+		},
+	}
+
+	for _, test := range tests {
+		path, ok := matchRequest(matches, test.method, test.headers, test.query)
+		if !ok {
+			t.Errorf("matchRequest() didn't match for the case of %q", test.msg)
+		}
+		if path != test.expectedPath {
+			t.Errorf("matchRequest() returned %q but expected %q for the case of %q", path, test.expectedPath, test.msg)
+		}
+	}
+}
+
+func TestMatchRequestNoMatch(t *testing.T) {
+	tests := []struct {
+		matches []httpMatch
+		msg     string
+	}{
+		{
+			matches: nil,
+			msg:     "no matches",
+		},
+		{
+			matches: []httpMatch{
+				{
+					Method:       v1beta1.HTTPMethodPost,
+					RedirectPath: "/_route0",
+				},
+			},
+			msg: "method doesn't match",
+		},
+		{
+			matches: []httpMatch{
+				{
+					Headers:      []string{"too:many:colons"},
+					RedirectPath: "/_route0",
+				},
+			},
+			msg: "malformed header",
+		},
+		{
+			matches: []httpMatch{
+				{
+					QueryParams:  []string{"=nokey"},
+					RedirectPath: "/_route0",
+				},
+			},
+			msg: "malformed query parameter",
+		},
+		{
+			matches: []httpMatch{
+				{
+					QueryParams:  []string{"flag="},
+					RedirectPath: "/_route0",
+				},
+			},
+			msg: "query parameter with an empty value is absent",
+		},
+	}
+
+	for _, test := range tests {
+		headers := map[string]string{"too": "many:colons"}
+		query := map[string]string{"novalue": ""}
+
+		path, ok := matchRequest(test.matches, "GET", headers, query)
+		if ok {
+			t.Errorf("matchRequest() matched %q for the case of %q", path, test.msg)
+		}
+	}
+}
+
+func TestGenerateMatchesEvaluation(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+							Headers: []v1beta1.HTTPHeaderMatch{
+								{
+									Name:  "version",
+									Value: "v2",
+								},
+							},
+						},
+						{
+							Path: &v1beta1.HTTPPathMatch{
+								Value: helpers.GetStringPointer("/"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	host := state.VirtualServer{
+		Hostname: "example.com",
+		PathRules: []state.PathRule{
+			{
+				Path: "/",
+				MatchRules: []state.MatchRule{
+					{
+						MatchIdx: 0,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+					{
+						MatchIdx: 1,
+						RuleIdx:  0,
+						Source:   hr,
+					},
+				},
+			},
+		},
+	}
+
+	generator := NewGeneratorImpl(&statefakes.FakeServiceStore{})
+	result, _, _ := generator.generate(context.Background(), host, make(state.BackendResolutions))
+
+	var matches []httpMatch
+	for _, l := range result.Locations {
+		if l.HTTPMatchVar == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(l.HTTPMatchVar), &matches); err != nil {
+			t.Fatalf("failed to unmarshal the matches: %v", err)
+		}
+	}
+
+	path, _ := matchRequest(matches, "GET", map[string]string{"version": "v2"}, nil)
+	if path != "/_route0" {
+		t.Errorf("matchRequest() returned %q for the request with the header but expected /_route0", path)
+	}
+
+	path, _ = matchRequest(matches, "GET", nil, nil)
+	if path != "/_route1" {
+		t.Errorf("matchRequest() returned %q for the request without the header but expected /_route1", path)
+	}
+}
+
+func TestValidateRewriteMatch(t *testing.T) {
+	tests := []struct {
+		match       v1beta1.HTTPRouteMatch
+		expectedErr string
+		msg         string
+	}{
+		{
+			match: v1beta1.HTTPRouteMatch{
+				Method:      helpers.GetHTTPMethodPointer(v1beta1.HTTPMethodGet),
+				Headers:     []v1beta1.HTTPHeaderMatch{{Name: "X-Version", Value: "v2"}},
+				QueryParams: []v1beta1.HTTPQueryParamMatch{{Name: "api_version", Value: "2"}},
+			},
+			msg: "valid match",
+		},
+		{
+			match: v1beta1.HTTPRouteMatch{
+				Headers: []v1beta1.HTTPHeaderMatch{
+					{
+						Type:  helpers.GetHeaderMatchTypePointer(v1beta1.HeaderMatchRegularExpression),
+						Name:  "x.version",
+						Value: "",
+					},
+				},
+			},
+			msg: "ignored header match type",
+		},
+		{
+			match: v1beta1.HTTPRouteMatch{
+				Headers: []v1beta1.HTTPHeaderMatch{{Name: "x_version", Value: "v2"}},
+			},
+			expectedErr: `the header name "x_version" must only include letters, digits and -`,
+			msg:         "header name with underscore",
+		},
+		{
+			match: v1beta1.HTTPRouteMatch{
+				Headers: []v1beta1.HTTPHeaderMatch{{Name: "version", Value: "$v2"}},
+			},
+			expectedErr: `the value of the header version must not include "$"`,
+			msg:         "header value with variable",
+		},
+		{
+			match: v1beta1.HTTPRouteMatch{
+				QueryParams: []v1beta1.HTTPQueryParamMatch{{Name: "api-version", Value: "2"}},
+			},
+			expectedErr: `the query parameter name "api-version" must only include letters, digits and _`,
+			msg:         "query parameter name with dash",
+		},
+		{
+			match: v1beta1.HTTPRouteMatch{
+				QueryParams: []v1beta1.HTTPQueryParamMatch{{Name: "flag", Value: ""}},
+			},
+			expectedErr: "the value of the query parameter flag must not be empty",
+			msg:         "query parameter with empty value",
+		},
+	}
+
+	for _, test := range tests {
+		err := validateRewriteMatch(test.match)
+
+		if test.expectedErr == "" {
+			if err != nil {
+				t.Errorf("validateRewriteMatch() returned unexpected error %v for the case of %q", err, test.msg)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("validateRewriteMatch() returned error %v but expected %q for the case of %q",
+				err, test.expectedErr, test.msg)
+		}
+	}
+}
+
+func TestCreateRewriteMatch(t *testing.T) {
+	tests := []struct {
+		hm       httpMatch
+		expected rewriteMatch
+		msg      string
+	}{
+		{
+			hm: httpMatch{
+				Any:          true,
+				RedirectPath: "/_route0",
+			},
+			expected: rewriteMatch{
+				RedirectPath: "/_route0",
+			},
+			msg: "any",
+		},
+		{
+			hm: httpMatch{
+				Method:       v1beta1.HTTPMethodPost,
+				Headers:      []string{"X-Version:v2:beta"},
+				QueryParams:  []string{"filter=a=b"},
+				RedirectPath: "/_route1",
+			},
+			expected: rewriteMatch{
+				Conditions: []rewriteCondition{
+					{Variable: "$request_method", Value: "POST"},
+					{Variable: "$http_x_version", Value: "v2:beta"},
+					{Variable: "$arg_filter", Value: "a=b"},
+				},
+				RedirectPath: "/_route1",
+			},
+			msg: "method, header and query parameter",
+		},
+		{
+			hm: httpMatch{
+				Headers:                []string{"token:v1.0"},
+				CaseInsensitiveHeaders: true,
+				RedirectPath:           "/_route2",
+			},
+			expected: rewriteMatch{
+				Conditions: []rewriteCondition{
+					{Variable: "$http_token", Value: `^v1\.0$`, CaseInsensitive: true},
+				},
+				RedirectPath: "/_route2",
+			},
+			msg: "case-insensitive header",
+		},
+	}
+
+	for _, test := range tests {
+		result := createRewriteMatch(test.hm)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("createRewriteMatch() mismatch for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// splitVariablePrefix is the prefix of the variables of the backend splits.
+const splitVariablePrefix = "$gateway_backend_"
+
+// splitVariableNameEncoder encodes the names of the namespaces and the HTTPRoutes in the names of the variables,
+// which can only include letters, digits and underscores.
+var splitVariableNameEncoder = strings.NewReplacer("-", "_h", ".", "_p")
+
+// splitTarget is a resolved backend of a rule whose requests are split among its backends.
+type splitTarget struct {
+	// refIdx is the index of the backendRef of the backend in the rule.
+	refIdx  int
+	ref     v1beta1.HTTPBackendRef
+	address string
+}
+
+// hasHeaderFilters reports whether any of the backendRefs modifies the request headers.
+func hasHeaderFilters(refs []v1beta1.HTTPBackendRef) bool {
+	for _, ref := range refs {
+		for _, f := range ref.Filters {
+			if f.Type == v1beta1.HTTPRouteFilterRequestHeaderModifier && f.RequestHeaderModifier != nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// newSplitBackend creates the backend that splits the requests of the rule of the HTTPRoute among the targets
+// according to their weights. Every target has its own upstream, so that the requests to the target get the
+// request headers of the RequestHeaderModifier filters of its backendRef. The modifications of the headers that
+// NGINX cannot apply are ignored and returned as errors.
+func (g *GeneratorImpl) newSplitBackend(hr *v1beta1.HTTPRoute, ruleIdx int, targets []splitTarget) (backend, []error) {
+	split := &backendSplit{
+		Variable: generateSplitVariable(hr, ruleIdx),
+		Backends: make([]splitBackend, 0, len(targets)),
+	}
+	upstreams := make([]upstream, 0, len(targets))
+
+	var total int64
+	for _, t := range targets {
+		total += int64(getWeight(t.ref.Weight))
+	}
+
+	// headers holds the indexes of the Headers of the split, where the key is the lowercase name of a header.
+	headers := make(map[string]int)
+	var errs []error
+
+	for i, t := range targets {
+		name := fmt.Sprintf("%s_backend%d", getUpstreamName(hr, ruleIdx), t.refIdx)
+
+		upstreams = append(upstreams, upstream{
+			Name:      name,
+			Servers:   []upstreamServer{{Address: t.address}},
+			Keepalive: g.upstreamKeepalive,
+			ZoneSize:  g.upstreamZoneSize,
+		})
+
+		percentage := "*"
+		if i < len(targets)-1 {
+			percentage = formatSplitPercentage(int64(getWeight(t.ref.Weight)), total)
+		}
+		split.Backends = append(split.Backends, splitBackend{Percentage: percentage, Upstream: name})
+
+		values, modErrs := g.getHeaderValues(t.ref)
+		for _, err := range modErrs {
+			errs = append(errs, fmt.Errorf("the filters of the backend %s of rule %d: %w; the modification is ignored",
+				t.ref.Name, ruleIdx, err))
+		}
+
+		for _, v := range values {
+			key := strings.ToLower(v.name)
+
+			idx, exist := headers[key]
+			if !exist {
+				idx = len(split.Headers)
+				headers[key] = idx
+				split.Headers = append(split.Headers, splitHeader{
+					Name:           http.CanonicalHeaderKey(v.name),
+					Variable:       fmt.Sprintf("%s__header%d", split.Variable, idx),
+					ClientVariable: getHeaderVariable(v.name),
+				})
+			}
+
+			h := &split.Headers[idx]
+
+			value := v.value
+			if v.appended {
+				h.AppendVariable = h.Variable + "__append"
+				value = "${" + strings.TrimPrefix(h.AppendVariable, "$") + "}" + value
+			}
+
+			h.Values = append(h.Values, splitHeaderValue{Upstream: name, Value: value})
+		}
+	}
+
+	return backend{
+		address:   split.Variable,
+		upstreams: upstreams,
+		split:     split,
+	}, errs
+}
+
+// headerValue is the value of a request header that the filters of a backendRef modify.
+type headerValue struct {
+	name string
+	// value is empty if the header is removed.
+	value string
+	// appended shows whether the value is appended to the value of the header in the client request.
+	appended bool
+}
+
+// getHeaderValues gets the values of the request headers that the RequestHeaderModifier filters of the backendRef
+// set, add or remove. The filters are applied in order, and every filter sets, adds and then removes its headers.
+// It returns an error for every modification that NGINX cannot apply.
+func (g *GeneratorImpl) getHeaderValues(ref v1beta1.HTTPBackendRef) ([]headerValue, []error) {
+	var values []headerValue
+	var errs []error
+
+	// indexes holds the indexes of the values, where the key is the lowercase name of a header.
+	indexes := make(map[string]int)
+
+	setValue := func(v headerValue) {
+		key := strings.ToLower(v.name)
+		if idx, exist := indexes[key]; exist {
+			values[idx] = v
+			return
+		}
+
+		indexes[key] = len(values)
+		values = append(values, v)
+	}
+
+	for _, f := range ref.Filters {
+		if f.Type != v1beta1.HTTPRouteFilterRequestHeaderModifier || f.RequestHeaderModifier == nil {
+			continue
+		}
+
+		for _, h := range f.RequestHeaderModifier.Set {
+			if err := g.validateHeaderModification(string(h.Name), h.Value); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			setValue(headerValue{name: string(h.Name), value: h.Value})
+		}
+
+		for _, h := range f.RequestHeaderModifier.Add {
+			if err := g.validateHeaderModification(string(h.Name), h.Value); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			// a value added to a header that the filters set or add is appended to that value, while a value added
+			// to a removed header replaces it
+			if idx, exist := indexes[strings.ToLower(string(h.Name))]; exist {
+				if values[idx].value == "" {
+					values[idx] = headerValue{name: string(h.Name), value: h.Value}
+				} else {
+					values[idx].value += "," + h.Value
+				}
+				continue
+			}
+
+			setValue(headerValue{name: string(h.Name), value: h.Value, appended: true})
+		}
+
+		for _, name := range f.RequestHeaderModifier.Remove {
+			if err := g.validateHeaderModification(name, ""); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			setValue(headerValue{name: name})
+		}
+	}
+
+	return values, errs
+}
+
+// validateHeaderModification checks that NGINX can modify the request header:
+// - The name must be valid in the names of the variables, like in validateRewriteMatch.
+// - The header must not be one of the headers NGINX sets for every request, like Host.
+// - The value must not include "$", because NGINX treats it as the start of a variable.
+func (g *GeneratorImpl) validateHeaderModification(name, value string) error {
+	if !headerNameRegexp.MatchString(name) {
+		return fmt.Errorf("the header name %q must only include letters, digits and -", name)
+	}
+
+	for _, reserved := range []string{
+		"Host",
+		"Connection",
+		"Upgrade",
+		"X-Forwarded-For",
+		"X-Forwarded-Proto",
+		"X-Real-IP",
+		g.requestIDHeader,
+	} {
+		if strings.EqualFold(name, reserved) {
+			return fmt.Errorf("the header %s is set by NGINX and cannot be modified", name)
+		}
+	}
+
+	if strings.Contains(value, "$") {
+		return fmt.Errorf(`the value of the header %s must not include "$"`, name)
+	}
+
+	return nil
+}
+
+// generateSplitVariable generates the variable of the split of the rule of the HTTPRoute. The - and . of the names
+// are encoded as _h and _p, and the parts of the variable are separated by __, so that the variables of different
+// rules are different.
+func generateSplitVariable(hr *v1beta1.HTTPRoute, ruleIdx int) string {
+	return fmt.Sprintf(
+		"%s%s__%s__rule%d",
+		splitVariablePrefix,
+		splitVariableNameEncoder.Replace(hr.Namespace),
+		splitVariableNameEncoder.Replace(hr.Name),
+		ruleIdx,
+	)
+}
+
+// formatSplitPercentage formats the percentage of the requests of the weight, rounded down to the two decimal
+// places that the split_clients directive supports, so that the percentages of the backends never exceed 100%.
+func formatSplitPercentage(weight, total int64) string {
+	basisPoints := weight * 10000 / total
+	return fmt.Sprintf("%d.%02d%%", basisPoints/100, basisPoints%100)
+}
+
+// getHeaderVariable returns the NGINX variable with the value of the request header.
+func getHeaderVariable(name string) string {
+	return "$http_" + strings.ReplaceAll(strings.ToLower(name), "-", "_")
+}
+
+// generateBackendSplits generates the unique splits of the locations of the servers. The same rule can be used by
+// multiple servers, so its split is only added once.
+func generateBackendSplits(servers []server) []backendSplit {
+	var splits []backendSplit
+	variables := make(map[string]struct{})
+
+	for _, s := range servers {
+		for _, l := range s.Locations {
+			if l.BackendSplit == nil {
+				continue
+			}
+
+			if _, exist := variables[l.BackendSplit.Variable]; exist {
+				continue
+			}
+			variables[l.BackendSplit.Variable] = struct{}{}
+
+			splits = append(splits, *l.BackendSplit)
+		}
+	}
+
+	return splits
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state/statefakes"
+)
+
+func TestGetHeaderValues(t *testing.T) {
+	createRef := func(filters ...v1beta1.HTTPRequestHeaderFilter) v1beta1.HTTPBackendRef {
+		ref := v1beta1.HTTPBackendRef{}
+		for i := range filters {
+			ref.Filters = append(ref.Filters, v1beta1.HTTPRouteFilter{
+				Type:                  v1beta1.HTTPRouteFilterRequestHeaderModifier,
+				RequestHeaderModifier: &filters[i],
+			})
+		}
+		return ref
+	}
+
+	tests := []struct {
+		ref            v1beta1.HTTPBackendRef
+		expectedValues []headerValue
+		expectedErrs   []string
+		msg            string
+	}{
+		{
+			ref:            v1beta1.HTTPBackendRef{},
+			expectedValues: nil,
+			msg:            "no filters",
+		},
+		{
+			ref: v1beta1.HTTPBackendRef{
+				Filters: []v1beta1.HTTPRouteFilter{
+					{
+						Type: v1beta1.HTTPRouteFilterRequestMirror,
+					},
+				},
+			},
+			expectedValues: nil,
+			msg:            "other filter",
+		},
+		{
+			ref: createRef(v1beta1.HTTPRequestHeaderFilter{
+				Set:    []v1beta1.HTTPHeader{{Name: "X-Version", Value: "canary"}},
+				Add:    []v1beta1.HTTPHeader{{Name: "X-Tags", Value: "beta"}},
+				Remove: []string{"X-Debug"},
+			}),
+			expectedValues: []headerValue{
+				{name: "X-Version", value: "canary"},
+				{name: "X-Tags", value: "beta", appended: true},
+				{name: "X-Debug"},
+			},
+			msg: "set, add and remove",
+		},
+		{
+			ref: createRef(
+				v1beta1.HTTPRequestHeaderFilter{
+					Set:    []v1beta1.HTTPHeader{{Name: "X-Version", Value: "canary"}},
+					Add:    []v1beta1.HTTPHeader{{Name: "x-version", Value: "beta"}},
+					Remove: []string{"X-Debug"},
+				},
+				v1beta1.HTTPRequestHeaderFilter{
+					Add: []v1beta1.HTTPHeader{{Name: "X-Debug", Value: "off"}},
+				},
+			),
+			expectedValues: []headerValue{
+				{name: "X-Version", value: "canary,beta"},
+				{name: "X-Debug", value: "off"},
+			},
+			msg: "add to a set header and to a removed header",
+		},
+		{
+			ref: createRef(v1beta1.HTTPRequestHeaderFilter{
+				Set: []v1beta1.HTTPHeader{
+					{Name: "X_Version", Value: "canary"},
+					{Name: "host", Value: "example.com"},
+					{Name: "X-Request-ID", Value: "1"},
+				},
+				Add: []v1beta1.HTTPHeader{
+					{Name: "X-Tags", Value: "$remote_addr"},
+					{Name: "X-Env", Value: "prod"},
+				},
+			}),
+			expectedValues: []headerValue{
+				{name: "X-Env", value: "prod", appended: true},
+			},
+			expectedErrs: []string{
+				`the header name "X_Version" must only include letters, digits and -`,
+				"the header host is set by NGINX and cannot be modified",
+				"the header X-Request-ID is set by NGINX and cannot be modified",
+				`the value of the header X-Tags must not include "$"`,
+			},
+			msg: "invalid modifications",
+		},
+	}
+
+	generator := NewGeneratorImpl(&statefakes.FakeServiceStore{})
+
+	for _, test := range tests {
+		values, errs := generator.getHeaderValues(test.ref)
+
+		if diff := cmp.Diff(test.expectedValues, values, cmp.AllowUnexported(headerValue{})); diff != "" {
+			t.Errorf("getHeaderValues() %q mismatch on values (-want +got):\n%s", test.msg, diff)
+		}
+
+		var errMsgs []string
+		for _, err := range errs {
+			errMsgs = append(errMsgs, err.Error())
+		}
+		if diff := cmp.Diff(test.expectedErrs, errMsgs); diff != "" {
+			t.Errorf("getHeaderValues() %q mismatch on errors (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestFormatSplitPercentage(t *testing.T) {
+	tests := []struct {
+		weight   int64
+		total    int64
+		expected string
+	}{
+		{weight: 80, total: 100, expected: "80.00%"},
+		{weight: 1, total: 3, expected: "33.33%"},
+		{weight: 2, total: 3, expected: "66.66%"},
+		{weight: 1, total: 1000000, expected: "0.00%"},
+	}
+
+	for _, test := range tests {
+		if result := formatSplitPercentage(test.weight, test.total); result != test.expected {
+			t.Errorf("formatSplitPercentage(%d, %d) returned %q but expected %q",
+				test.weight, test.total, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateSplitVariable(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-ns",
+			Name:      "route.v1",
+		},
+	}
+
+	expected := "$gateway_backend_test_hns__route_pv1__rule2"
+
+	if result := generateSplitVariable(hr, 2); result != expected {
+		t.Errorf("generateSplitVariable() returned %q but expected %q", result, expected)
+	}
+}
+
+func TestGenerateBackendSplits(t *testing.T) {
+	split1 := &backendSplit{Variable: "$gateway_backend_test__route1__rule0"}
+	split2 := &backendSplit{Variable: "$gateway_backend_test__route2__rule0"}
+
+	servers := []server{
+		{
+			Locations: []location{
+				{Path: "/", BackendSplit: split1},
+				{Path: "/coffee"},
+			},
+		},
+		{
+			Locations: []location{
+				{Path: "/", BackendSplit: split1},
+				{Path: "/tea", BackendSplit: split2},
+			},
+		},
+	}
+
+	expected := []backendSplit{*split1, *split2}
+
+	result := generateBackendSplits(servers)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateBackendSplits() mismatch (-want +got):\n%s", diff)
+	}
+}
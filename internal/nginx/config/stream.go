@@ -0,0 +1,10 @@
+package config
+
+type streamServers struct {
+	Servers []streamServer
+}
+
+type streamServer struct {
+	Listen    string
+	ProxyPass string
+}
@@ -3,87 +3,621 @@ package config
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"text/template"
 )
 
-var httpServersTemplate = `{{ range $s := .Servers }}
-	{{ if $s.IsDefaultSSL }}
+var httpServersTemplate = `{{ template "http-common" . }}
+{{ template "http-server-blocks" . }}
+
+{{ define "http-common" }}
+map {{ .RequestID.HeaderVar }} $request_id_header {
+	default {{ .RequestID.HeaderVar }};
+	"" $request_id;
+}
+
+log_format gateway_main '$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent '
+	'"$http_referer" "$http_user_agent" {{ .RequestID.Header }}=$request_id_header';
+access_log /var/log/nginx/access.log gateway_main;
+
+{{ range $cidr := .TrustedProxies }}
+set_real_ip_from {{ $cidr }};
+{{ end }}
+{{ if .TrustedProxies }}
+real_ip_header X-Forwarded-For;
+{{ end }}
+
+map $http_upgrade $connection_upgrade {
+	default upgrade;
+	"" "";
+}
+
+{{ if not .ServerTokens }}
+server_tokens off;
+{{ end }}
+
+{{ if .DefaultType }}
+default_type {{ .DefaultType }};
+{{ end }}
+
+{{ if .MergeSlashes }}
+merge_slashes on;
+{{ end }}
+
+{{ if .ClientMaxBodySize }}
+client_max_body_size {{ .ClientMaxBodySize }};
+{{ end }}
+
+{{ if .Resolver }}
+resolver{{ range $a := .Resolver }} {{ $a }}{{ end }};
+{{ end }}
+
+{{ if .SSLCertificates }}
+map $ssl_server_name $gateway_ssl_certificate {
+	hostnames;
+	default {{ (index .SSLCertificates 0).Path }};
+
+	{{ range $c := .SSLCertificates }}
+	{{ $c.Hostname }} {{ $c.Path }};
+	{{ end }}
+}
+
+map $ssl_server_name $gateway_ssl_certificate_key {
+	hostnames;
+	default {{ (index .SSLCertificates 0).KeyPath }};
+
+	{{ range $c := .SSLCertificates }}
+	{{ $c.Hostname }} {{ $c.KeyPath }};
+	{{ end }}
+}
+{{ end }}
+
+{{ range $u := .Upstreams }}
+{{ template "upstream" $u }}
+{{ end }}
+
+{{ range $s := .BackendSplits }}
+split_clients $request_id {{ $s.Variable }} {
+	{{ range $b := $s.Backends }}
+	{{ $b.Percentage }} {{ $b.Upstream }};
+	{{ end }}
+}
+	{{ range $hdr := $s.Headers }}
+		{{ if $hdr.AppendVariable }}
+map {{ $hdr.ClientVariable }} {{ $hdr.AppendVariable }} {
+	"" "";
+	default "{{ $hdr.ClientVariable }},";
+}
+		{{ end }}
+map {{ $s.Variable }} {{ $hdr.Variable }} {
+	default {{ $hdr.ClientVariable }};
+		{{ range $v := $hdr.Values }}
+	{{ $v.Upstream }} {{ $v.Value | nginxString }};
+		{{ end }}
+}
+	{{ end }}
+{{ end }}
+{{ end }}
+
+{{ define "upstream" }}
+upstream {{ .Name }} {
+	{{ if .ZoneSize }}
+	zone {{ .Name }} {{ .ZoneSize }};
+	{{ end }}
+	{{ range $server := .Servers }}
+	server {{ $server.Address }}{{ if $server.Weight }} weight={{ $server.Weight }}{{ end }};
+	{{ end }}
+	{{ if .Keepalive }}
+	keepalive {{ .Keepalive }};
+	{{ end }}
+}
+{{ end }}
+
+{{ define "http-server-blocks" }}
+{{ range $s := .Servers }}
+{{ template "server" (serverData $s $) }}
+{{ end }}
+{{ end }}
+
+{{ define "server" }}{{ $s := .Server }}{{ $h := .HTTP }}
+	{{ if $s.IsFallback }}
+server {
+	listen {{ $s.Listen }};
+
+	access_log off;
+	return 502;
+}
+	{{ else if $s.HealthCheck }}
 server {
-	listen 443 ssl default_server;
+		{{ range $addr := $s.HealthCheck.Listen }}
+	listen {{ $addr }};
+		{{ end }}
+		{{ if not $s.HealthCheck.AccessLog }}
+
+	access_log off;
+		{{ end }}
+
+	location = {{ $s.HealthCheck.Path }} {
+		default_type text/plain;
+		return 200 "healthy\n";
+	}
+
+	location / {
+		default_type text/html;
+		return 404;
+	}
+}
+	{{ else if $s.IsDefaultSSL }}
+server {
+		{{ range $addr := $h.HTTPSListen }}
+	listen {{ $addr }} ssl default_server;
+		{{ end }}
+		{{ if $s.SSL }}
+
+	ssl_certificate {{ $s.SSL.Certificate }};
+	ssl_certificate_key {{ $s.SSL.CertificateKey }};
+
+	default_type text/html;
+	return 404;
+		{{ else }}
 
 	ssl_reject_handshake on;
+		{{ end }}
 }
 	{{ else if $s.IsDefaultHTTP }}
 server {
-	listen 80 default_server;
-	
+		{{ range $addr := $h.HTTPListen }}
+	listen {{ $addr }} default_server;
+		{{ end }}
+		{{ range $l := $s.Locations }}
+
+	location {{ $l.Path }} {
+		proxy_http_version 1.1;
+		proxy_set_header Connection "";
+		proxy_set_header Host $host;
+			{{ if $h.ForwardedHeaders }}
+		proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
+		proxy_set_header X-Forwarded-Proto $scheme;
+		proxy_set_header X-Real-IP $remote_addr;
+			{{ end }}
+		proxy_set_header {{ $h.RequestID.Header }} $request_id_header;
+		proxy_pass {{ $l.ProxyPass }}$request_uri;
+	}
+		{{ else }}
+
 	default_type text/html;
 	return 404;
+		{{ end }}
 }
 	{{ else }}
 server {
 		{{ if $s.SSL }}
-	listen 443 ssl;
+			{{ range $addr := $h.HTTPSListen }}
+	listen {{ $addr }} ssl;
+			{{ end }}
 	ssl_certificate {{ $s.SSL.Certificate }};
 	ssl_certificate_key {{ $s.SSL.CertificateKey }};
+			{{ if $h.OCSPStapling }}
+	ssl_stapling on;
+	ssl_stapling_verify on;
+	ssl_trusted_certificate {{ $h.OCSPStapling.TrustedCertificate }};
+			{{ end }}
 
 	if ($ssl_server_name != $host) {
 		return 421;
 	}
+		{{ else }}
+			{{ range $addr := $h.HTTPListen }}
+	listen {{ $addr }};
+			{{ end }}
 		{{ end }}
 
 	server_name {{ $s.ServerName }};
+		{{ range $src := $s.Sources }}
+	# source: {{ $src }}
+		{{ end }}
+
+	{{ template "security-headers" (serverData $s $h) }}
+
+		{{ if $h.RequestTooLarge }}
+	error_page 413 @gateway_request_too_large;
+		{{ end }}
+		{{ range $line := $s.Snippets }}
+	{{ $line }}
+		{{ end }}
 
 		{{ range $l := $s.Locations }}
-	location {{ $l.Path }} {
+	{{ template "location" (locationData $l $s $h) }}
+		{{ end }}
+
+		{{ range $code := $h.StatusOverrides.Codes }}
+	location @gateway_status_{{ $code }} {
+		default_type text/html;
+		return {{ $code }};
+	}
+		{{ end }}
+
+		{{ with $h.RequestTooLarge }}
+	location @gateway_request_too_large {
+		default_type {{ .ContentType }};
+		return {{ .Code }}{{ if .Body }} {{ .Body | nginxString }}{{ end }};
+	}
+		{{ end }}
+}
+	{{ end }}
+{{ end }}
+
+{{ define "security-headers" }}
+	{{ if .HTTP.SecurityHeaders }}
+	add_header X-Content-Type-Options nosniff always;
+	add_header X-Frame-Options SAMEORIGIN always;
+	add_header Referrer-Policy strict-origin-when-cross-origin always;
+	{{ end }}
+	{{ if and .Server.SSL .HTTP.HSTS }}
+	add_header Strict-Transport-Security {{ .HTTP.HSTS | nginxString }} always;
+	{{ end }}
+{{ end }}
+
+{{ define "location" }}{{ $l := .Location }}{{ $h := .HTTP }}location {{ $l.Path }} {
+		{{ range $src := $l.Sources }}
+		# source: {{ $src }}
+		{{ end }}
 		{{ if $l.Internal }}
 		internal;
 		{{ end }}
 
+		{{ if $l.CORS }}
+			{{ if not $l.Internal }}
+		if ($request_method = OPTIONS) {
+			add_header Access-Control-Allow-Origin {{ $l.CORS.AllowOrigin | nginxString }};
+			add_header Access-Control-Allow-Methods {{ $l.CORS.AllowMethods | nginxString }};
+			add_header Access-Control-Allow-Headers {{ $l.CORS.AllowHeaders | nginxString }};
+				{{ if $l.CORS.AllowCredentials }}
+			add_header Access-Control-Allow-Credentials true;
+				{{ end }}
+			add_header Access-Control-Max-Age {{ $l.CORS.MaxAge }};
+			return 204;
+		}
+			{{ end }}
+
+		add_header Access-Control-Allow-Origin {{ $l.CORS.AllowOrigin | nginxString }} always;
+			{{ if $l.CORS.AllowCredentials }}
+		add_header Access-Control-Allow-Credentials true always;
+			{{ end }}
+		{{ template "security-headers" (serverData .Server $h) }}
+		{{ end }}
+
+		{{ if $l.Method }}
+		if ($request_method != {{ $l.Method }}) {
+			return 404;
+		}
+		{{ end }}
+
 		{{ if $l.Return }}
-		return {{ $l.Return.Code }};
+			{{ if $l.Return.ContentType }}
+		default_type {{ $l.Return.ContentType }};
+			{{ end }}
+		return {{ $l.Return.Code }}{{ if $l.Return.Body }} {{ $l.Return.Body | nginxString }}{{ end }};
 		{{ end }}
 
 		{{ if $l.HTTPMatchVar }}
-		set $http_matches {{ $l.HTTPMatchVar | printf "%q" }};
+		set $http_matches {{ $l.HTTPMatchVar | nginxString }};
 		js_content httpmatches.redirect;
 		{{ end }}
 
+		{{ if $l.RewriteMatches }}
+			{{ range $m := $l.RewriteMatches }}
+				{{ if $m.Conditions }}
+		set $gateway_match 1;
+					{{ range $c := $m.Conditions }}
+		if ({{ $c.Variable }} {{ if $c.CaseInsensitive }}!~*{{ else }}!={{ end }} {{ $c.Value | nginxString }}) {
+			set $gateway_match 0;
+		}
+					{{ end }}
+		if ($gateway_match) {
+			rewrite ^ {{ $m.RedirectPath }} last;
+		}
+				{{ else }}
+		rewrite ^ {{ $m.RedirectPath }} last;
+				{{ end }}
+			{{ end }}
+		return 404;
+		{{ end }}
+
 		{{ if $l.ProxyPass }}
+			{{ if $l.Streaming }}
+		chunked_transfer_encoding on;
+		proxy_buffering off;
+			{{ end }}
+			{{ if $l.ProxyBuffering }}
+		proxy_buffering {{ $l.ProxyBuffering }};
+			{{ end }}
+			{{ if $l.ProxyBuffers }}
+		proxy_buffers {{ $l.ProxyBuffers }};
+			{{ end }}
+			{{ if $l.ProxyBufferSize }}
+		proxy_buffer_size {{ $l.ProxyBufferSize }};
+			{{ end }}
+			{{ if $l.ForceRanges }}
+		proxy_force_ranges on;
+			{{ end }}
+			{{ if $l.MaxRanges }}
+		max_ranges {{ $l.MaxRanges }};
+			{{ end }}
+		proxy_http_version {{ or $l.ProxyHTTPVersion "1.1" }};
+			{{ if $l.WebSocket }}
+		proxy_set_header Upgrade $http_upgrade;
+		proxy_set_header Connection $connection_upgrade;
+			{{ else if ne $l.ProxyHTTPVersion "1.0" }}
+		proxy_set_header Connection "";
+			{{ end }}
+			{{ if $l.CacheBypass }}
+		proxy_cache_bypass{{ range $v := $l.CacheBypass }} {{ $v }}{{ end }};
+			{{ end }}
+			{{ if $l.NoCache }}
+		proxy_no_cache{{ range $v := $l.NoCache }} {{ $v }}{{ end }};
+			{{ end }}
+			{{ if $l.ProxyConnectTimeout }}
+		proxy_connect_timeout {{ $l.ProxyConnectTimeout }};
+			{{ end }}
+			{{ if $l.ProxyReadTimeout }}
+		proxy_read_timeout {{ $l.ProxyReadTimeout }};
+			{{ end }}
+			{{ if $l.ProxySendTimeout }}
+		proxy_send_timeout {{ $l.ProxySendTimeout }};
+			{{ end }}
+			{{ if $l.ProxyNextUpstream }}
+		proxy_next_upstream{{ range $c := $l.ProxyNextUpstream }} {{ $c }}{{ end }};
+			{{ end }}
+			{{ if $l.ProxyNextUpstreamTries }}
+		proxy_next_upstream_tries {{ $l.ProxyNextUpstreamTries }};
+			{{ end }}
+			{{ if $l.ProxyNextUpstreamTimeout }}
+		proxy_next_upstream_timeout {{ $l.ProxyNextUpstreamTimeout }};
+			{{ end }}
+			{{ if $l.ProxySSL }}
+				{{ if $l.ProxySSL.Name }}
+		proxy_ssl_server_name on;
+		proxy_ssl_name {{ $l.ProxySSL.Name }};
+				{{ end }}
+				{{ if $l.ProxySSL.TrustedCertificate }}
+		proxy_ssl_verify on;
+		proxy_ssl_trusted_certificate {{ $l.ProxySSL.TrustedCertificate }};
+				{{ end }}
+			{{ end }}
+			{{ if $l.Mirror }}
+		mirror {{ $l.Mirror }};
+			{{ end }}
+			{{ if $h.StatusOverrides.Overrides }}
+		proxy_intercept_errors on;
+				{{ range $o := $h.StatusOverrides.Overrides }}
+		error_page {{ $o.From }} ={{ $o.To }} @gateway_status_{{ $o.To }};
+				{{ end }}
+				{{ if $h.RequestTooLarge }}
+		error_page 413 @gateway_request_too_large;
+				{{ end }}
+			{{ end }}
 		proxy_set_header Host $host;
+			{{ if $h.ForwardedHeaders }}
+		proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
+		proxy_set_header X-Forwarded-Proto $scheme;
+		proxy_set_header X-Real-IP $remote_addr;
+			{{ end }}
+		proxy_set_header {{ $h.RequestID.Header }} $request_id_header;
+			{{ if $l.BackendSplit }}
+				{{ range $hdr := $l.BackendSplit.Headers }}
+		proxy_set_header {{ $hdr.Name }} {{ $hdr.Variable }};
+				{{ end }}
+			{{ end }}
 		proxy_pass {{ $l.ProxyPass }}$request_uri;
 		{{ end }}
-	}
+		{{ range $line := $l.Snippets }}
+		{{ $line }}
 		{{ end }}
+	}{{ end }}
+`
+
+var mainTemplate = `worker_processes {{ .WorkerProcesses }};
+
+events {
+	worker_connections {{ .WorkerConnections }};
+}
+`
+
+var streamServersTemplate = `{{ range $s := .Servers }}
+server {
+	listen {{ $s.Listen }};
+
+	proxy_pass {{ $s.ProxyPass }};
 }
-	{{ end }}
 {{ end }}
 `
 
+// overridableTemplates are the named templates of the http servers template that the template overrides can
+// redefine.
+var overridableTemplates = map[string]struct{}{
+	"server":   {},
+	"location": {},
+	"upstream": {},
+}
+
+var templateFuncs = template.FuncMap{
+	"serverData":   newServerData,
+	"locationData": newLocationData,
+	"nginxString":  quoteNginxString,
+}
+
+// nginxStringEscaper escapes the characters that NGINX treats specially in a double-quoted string.
+// Unlike the Go escapes of printf "%q", it leaves the non-ASCII characters as they are, because NGINX doesn't support
+// the \u escapes. The NGINX variables in the string are not escaped, so that NGINX can evaluate them.
+var nginxStringEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+// quoteNginxString returns the string as a double-quoted NGINX string.
+func quoteNginxString(s string) string {
+	return `"` + nginxStringEscaper.Replace(s) + `"`
+}
+
+// serverData is the data of the server template.
+type serverData struct {
+	Server server
+	// HTTP holds the settings shared by all servers, like the listen addresses.
+	HTTP httpServers
+}
+
+func newServerData(s server, h httpServers) serverData {
+	return serverData{Server: s, HTTP: h}
+}
+
+// locationData is the data of the location template.
+type locationData struct {
+	Location location
+	Server   server
+	// HTTP holds the settings shared by all servers, like the request ID header.
+	HTTP httpServers
+}
+
+func newLocationData(l location, s server, h httpServers) locationData {
+	return locationData{Location: l, Server: s, HTTP: h}
+}
+
 // templateExecutor generates NGINX configuration using a template.
-// Template parsing or executing errors can only occur if there is a bug in the template, so they are handled with panics.
-// For now, we only generate configuration with NGINX http servers, but in the future we will also need to generate
-// the main NGINX configuration file, upstreams, stream servers.
+// Template parsing errors can only occur if there is a bug in the template, so they are handled with panics.
+// Executing errors are returned, so that a broken configuration is never written.
+// It generates the configuration of the NGINX http and stream servers and of the main context.
 type templateExecutor struct {
-	httpServersTemplate *template.Template
+	httpServersTemplate   *template.Template
+	streamServersTemplate *template.Template
+	mainTemplate          *template.Template
 }
 
 func newTemplateExecutor() *templateExecutor {
-	t, err := template.New("server").Parse(httpServersTemplate)
+	e, err := newTemplateExecutorWithOverrides("")
+	if err != nil {
+		panic(err)
+	}
+
+	return e
+}
+
+// newTemplateExecutorWithOverrides creates a templateExecutor whose server, location and upstream templates are
+// redefined by the {{ define }} actions of the overrides. The templates that the overrides don't define keep their
+// default definitions. An error is only returned for invalid overrides.
+func newTemplateExecutorWithOverrides(overrides string) (*templateExecutor, error) {
+	t, err := template.New("http servers").Funcs(templateFuncs).Parse(httpServersTemplate)
 	if err != nil {
 		panic(fmt.Errorf("failed to parse http servers template: %w", err))
 	}
 
-	return &templateExecutor{httpServersTemplate: t}
+	st, err := template.New("stream servers").Parse(streamServersTemplate)
+	if err != nil {
+		panic(fmt.Errorf("failed to parse stream servers template: %w", err))
+	}
+
+	mt, err := template.New("main").Parse(mainTemplate)
+	if err != nil {
+		panic(fmt.Errorf("failed to parse main template: %w", err))
+	}
+
+	if overrides != "" {
+		if err := validateOverrides(overrides); err != nil {
+			return nil, err
+		}
+
+		// The text outside of the {{ define }} actions goes to the overrides template, which is never executed.
+		if _, err := t.New("overrides").Parse(overrides); err != nil {
+			return nil, fmt.Errorf("failed to parse template overrides: %w", err)
+		}
+	}
+
+	return &templateExecutor{
+		httpServersTemplate:   t,
+		streamServersTemplate: st,
+		mainTemplate:          mt,
+	}, nil
 }
 
-func (e *templateExecutor) ExecuteForHTTPServers(servers httpServers) []byte {
+// validateOverrides ensures the overrides only define the overridable templates.
+func validateOverrides(overrides string) error {
+	ot, err := template.New("overrides").Funcs(templateFuncs).Parse(overrides)
+	if err != nil {
+		return fmt.Errorf("failed to parse template overrides: %w", err)
+	}
+
+	for _, d := range ot.Templates() {
+		if d.Name() == ot.Name() {
+			continue
+		}
+		if _, ok := overridableTemplates[d.Name()]; !ok {
+			return fmt.Errorf("template %q cannot be overridden; only server, location and upstream can", d.Name())
+		}
+	}
+
+	return nil
+}
+
+// ValidateTemplateOverrides validates the overrides of the templates of the http servers configuration.
+func ValidateTemplateOverrides(overrides string) error {
+	_, err := newTemplateExecutorWithOverrides(overrides)
+	return err
+}
+
+func (e *templateExecutor) ExecuteForHTTPServers(servers httpServers) ([]byte, error) {
 	var buf bytes.Buffer
 
 	err := e.httpServersTemplate.Execute(&buf, servers)
 	if err != nil {
-		panic(fmt.Errorf("failed to execute http servers template: %w", err))
+		return nil, fmt.Errorf("failed to execute http servers template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ExecuteForHTTPCommon generates the part of the http servers configuration that is shared by all servers,
+// like the upstreams. The servers are not generated.
+func (e *templateExecutor) ExecuteForHTTPCommon(servers httpServers) ([]byte, error) {
+	return e.executeHTTPServersTemplate("http-common", servers)
+}
+
+// ExecuteForHTTPServerBlocks generates only the server blocks of the http servers configuration.
+func (e *templateExecutor) ExecuteForHTTPServerBlocks(servers httpServers) ([]byte, error) {
+	return e.executeHTTPServersTemplate("http-server-blocks", servers)
+}
+
+func (e *templateExecutor) executeHTTPServersTemplate(name string, servers httpServers) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := e.httpServersTemplate.ExecuteTemplate(&buf, name, servers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute %s template: %w", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (e *templateExecutor) ExecuteForStreamServers(servers streamServers) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := e.streamServersTemplate.Execute(&buf, servers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute stream servers template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (e *templateExecutor) ExecuteForMain(main mainConfig) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := e.mainTemplate.Execute(&buf, main)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute main template: %w", err)
 	}
 
-	return buf.Bytes()
+	return buf.Bytes(), nil
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 	"text/template"
 )
@@ -22,7 +23,10 @@ func TestExecuteForServer(t *testing.T) {
 		},
 	}
 
-	cfg := executor.ExecuteForHTTPServers(servers)
+	cfg, err := executor.ExecuteForHTTPServers(servers)
+	if err != nil {
+		t.Fatalf("ExecuteForServer() returned unexpected error %v", err)
+	}
 	// we only do a sanity check here.
 	// the config generation logic is tested in the Generator tests.
 	if len(cfg) == 0 {
@@ -30,8 +34,192 @@ func TestExecuteForServer(t *testing.T) {
 	}
 }
 
+func TestExecuteForHTTPCommonAndServerBlocks(t *testing.T) {
+	executor := newTemplateExecutor()
+
+	servers := httpServers{
+		RequestID: generateRequestID(DefaultRequestIDHeader),
+		Upstreams: []upstream{generateFallbackUpstream()},
+		Servers: []server{
+			{
+				ServerName: "example.com",
+				Locations: []location{
+					{
+						Path:      "/",
+						ProxyPass: "http://10.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	commonCfg, err := executor.ExecuteForHTTPCommon(servers)
+	if err != nil {
+		t.Fatalf("ExecuteForHTTPCommon() returned unexpected error %v", err)
+	}
+
+	common := string(commonCfg)
+	if !strings.Contains(common, "upstream "+fallbackUpstreamName) {
+		t.Errorf("ExecuteForHTTPCommon() didn't generate the upstreams; got:\n%s", common)
+	}
+	if strings.Contains(common, "server_name") {
+		t.Errorf("ExecuteForHTTPCommon() generated servers; got:\n%s", common)
+	}
+
+	blocksCfg, err := executor.ExecuteForHTTPServerBlocks(servers)
+	if err != nil {
+		t.Fatalf("ExecuteForHTTPServerBlocks() returned unexpected error %v", err)
+	}
+
+	blocks := string(blocksCfg)
+	if !strings.Contains(blocks, "server_name example.com;") {
+		t.Errorf("ExecuteForHTTPServerBlocks() didn't generate the servers; got:\n%s", blocks)
+	}
+	if strings.Contains(blocks, "upstream") {
+		t.Errorf("ExecuteForHTTPServerBlocks() generated upstreams; got:\n%s", blocks)
+	}
+}
+
+func TestExecuteForStreamServers(t *testing.T) {
+	executor := newTemplateExecutor()
+
+	servers := streamServers{
+		Servers: []streamServer{
+			{
+				Listen:    "8080",
+				ProxyPass: "10.0.0.1:80",
+			},
+		},
+	}
+
+	cfg, err := executor.ExecuteForStreamServers(servers)
+	if err != nil {
+		t.Fatalf("ExecuteForStreamServers() returned unexpected error %v", err)
+	}
+	// we only do a sanity check here.
+	// the config generation logic is tested in the Generator tests.
+	if len(cfg) == 0 {
+		t.Error("ExecuteForStreamServers() returned 0-length config")
+	}
+}
+
+func TestExecuteForMain(t *testing.T) {
+	executor := newTemplateExecutor()
+
+	cfg, err := executor.ExecuteForMain(mainConfig{WorkerProcesses: "auto", WorkerConnections: 1024})
+	if err != nil {
+		t.Fatalf("ExecuteForMain() returned unexpected error %v", err)
+	}
+	// we only do a sanity check here.
+	// the config generation logic is tested in the Generator tests.
+	if len(cfg) == 0 {
+		t.Error("ExecuteForMain() returned 0-length config")
+	}
+}
+
+func TestNewTemplateExecutorWithOverrides(t *testing.T) {
+	servers := httpServers{
+		Upstreams: []upstream{generateFallbackUpstream()},
+		Servers: []server{
+			{
+				ServerName: "example.com",
+				Locations: []location{
+					{
+						Path:      "/",
+						ProxyPass: "http://10.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		overrides string
+		expected  []string
+		expErr    bool
+		msg       string
+	}{
+		{
+			overrides: "",
+			expected: []string{
+				"upstream " + fallbackUpstreamName + " {",
+				"server_name example.com;",
+				"proxy_pass http://10.0.0.1$request_uri;",
+			},
+			msg: "no overrides",
+		},
+		{
+			overrides: `{{ define "location" }}location {{ .Location.Path }} { return 204; }{{ end }}`,
+			expected: []string{
+				"upstream " + fallbackUpstreamName + " {",
+				"server_name example.com;",
+				"location / { return 204; }",
+			},
+			msg: "location override",
+		},
+		{
+			overrides: `{{ define "server" }}server { server_name {{ .Server.ServerName }}; }{{ end }}`,
+			expected: []string{
+				"server { server_name example.com; }",
+			},
+			msg: "server override",
+		},
+		{
+			overrides: `{{ define "upstream" }}upstream {{ .Name }} { server unix:/var/run/nginx.sock; }{{ end }}`,
+			expected: []string{
+				"upstream " + fallbackUpstreamName + " { server unix:/var/run/nginx.sock; }",
+			},
+			msg: "upstream override",
+		},
+		{
+			overrides: `{{ define "location" }}{{ end`,
+			expErr:    true,
+			msg:       "invalid template",
+		},
+		{
+			overrides: `{{ define "http-common" }}{{ end }}`,
+			expErr:    true,
+			msg:       "not overridable template",
+		},
+	}
+
+	for _, test := range tests {
+		executor, err := newTemplateExecutorWithOverrides(test.overrides)
+
+		if test.expErr {
+			if err == nil {
+				t.Errorf("newTemplateExecutorWithOverrides() didn't return an error for the case of %q", test.msg)
+			}
+			if err := ValidateTemplateOverrides(test.overrides); err == nil {
+				t.Errorf("ValidateTemplateOverrides() didn't return an error for the case of %q", test.msg)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("newTemplateExecutorWithOverrides() returned unexpected error %v for the case of %q", err, test.msg)
+			continue
+		}
+
+		cfg, err := executor.ExecuteForHTTPServers(servers)
+		if err != nil {
+			t.Errorf("ExecuteForHTTPServers() returned unexpected error %v for the case of %q", err, test.msg)
+			continue
+		}
+
+		for _, e := range test.expected {
+			if !strings.Contains(string(cfg), e) {
+				t.Errorf("ExecuteForHTTPServers() didn't generate %q for the case of %q; got:\n%s", e, test.msg, cfg)
+			}
+		}
+	}
+}
+
 func TestNewTemplateExecutorPanics(t *testing.T) {
+	original := httpServersTemplate
 	defer func() {
+		httpServersTemplate = original
+
 		r := recover()
 		if r == nil {
 			t.Error("newTemplateExecutor() didn't panic")
@@ -42,20 +230,62 @@ func TestNewTemplateExecutorPanics(t *testing.T) {
 	newTemplateExecutor()
 }
 
-func TestExecuteForServerPanics(t *testing.T) {
-	defer func() {
-		r := recover()
-		if r == nil {
-			t.Error("ExecuteForServer() didn't panic")
-		}
-	}()
-
+func TestExecuteForServerError(t *testing.T) {
 	tmpl, err := template.New("test").Parse("{{ .NonExistingField }}")
 	if err != nil {
 		t.Fatalf("Failed to parse template: %v", err)
 	}
 
-	executor := &templateExecutor{httpServersTemplate: tmpl}
+	executor := &templateExecutor{httpServersTemplate: tmpl, streamServersTemplate: tmpl}
 
-	_ = executor.ExecuteForHTTPServers(httpServers{})
+	if _, err := executor.ExecuteForHTTPServers(httpServers{}); err == nil {
+		t.Error("ExecuteForServer() didn't return an error")
+	}
+	if _, err := executor.ExecuteForHTTPCommon(httpServers{}); err == nil {
+		t.Error("ExecuteForHTTPCommon() didn't return an error")
+	}
+	if _, err := executor.ExecuteForStreamServers(streamServers{}); err == nil {
+		t.Error("ExecuteForStreamServers() didn't return an error")
+	}
+}
+
+func TestQuoteNginxString(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected string
+		msg      string
+	}{
+		{
+			s:        "no available backends",
+			expected: `"no available backends"`,
+			msg:      "plain text",
+		},
+		{
+			s:        `say "hi" \ bye`,
+			expected: `"say \"hi\" \\ bye"`,
+			msg:      "quotes and backslashes",
+		},
+		{
+			s:        "line1\nline2\ttab",
+			expected: `"line1\nline2\ttab"`,
+			msg:      "control characters",
+		},
+		{
+			s:        "café",
+			expected: `"café"`,
+			msg:      "non-ASCII characters",
+		},
+		{
+			s:        "$scheme://example.com$request_uri",
+			expected: `"$scheme://example.com$request_uri"`,
+			msg:      "variables",
+		},
+	}
+
+	for _, test := range tests {
+		result := quoteNginxString(test.s)
+		if result != test.expected {
+			t.Errorf("quoteNginxString() returned %s but expected %s for the case of %q", result, test.expected, test.msg)
+		}
+	}
 }
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+var (
+	supportedPathMatchTypes       = []string{string(v1beta1.PathMatchPathPrefix)}
+	supportedHeaderMatchTypes     = []string{string(v1beta1.HeaderMatchExact)}
+	supportedQueryParamMatchTypes = []string{string(v1beta1.QueryParamMatchExact)}
+	supportedFilterTypes          = []string{string(v1beta1.HTTPRouteFilterRequestMirror)}
+	supportedBackendFilterTypes   = []string{string(v1beta1.HTTPRouteFilterRequestHeaderModifier)}
+)
+
+// ValidateHTTPRoute validates the HTTPRoute against the features the Generator supports, so that, for example,
+// an admission webhook can reject the HTTPRoutes with the unsupported features. The Generator ignores the unsupported
+// features and warns about them with the messages of the returned errors.
+func ValidateHTTPRoute(hr *v1beta1.HTTPRoute) field.ErrorList {
+	var allErrs field.ErrorList
+
+	rulesPath := field.NewPath("spec").Child("rules")
+
+	for i, rule := range hr.Spec.Rules {
+		rulePath := rulesPath.Index(i)
+
+		for j, m := range rule.Matches {
+			allErrs = append(allErrs, validateMatch(m, rulePath.Child("matches").Index(j))...)
+		}
+
+		for j, f := range rule.Filters {
+			if f.Type != v1beta1.HTTPRouteFilterRequestMirror {
+				typePath := rulePath.Child("filters").Index(j).Child("type")
+				allErrs = append(allErrs, field.NotSupported(typePath, string(f.Type), supportedFilterTypes))
+			}
+		}
+
+		for j, ref := range rule.BackendRefs {
+			for k, f := range ref.Filters {
+				if f.Type != v1beta1.HTTPRouteFilterRequestHeaderModifier {
+					typePath := rulePath.Child("backendRefs").Index(j).Child("filters").Index(k).Child("type")
+					allErrs = append(allErrs, field.NotSupported(typePath, string(f.Type), supportedBackendFilterTypes))
+				}
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateMatch(m v1beta1.HTTPRouteMatch, matchPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// PathPrefix is the default type
+	if m.Path != nil && m.Path.Type != nil && *m.Path.Type != v1beta1.PathMatchPathPrefix {
+		typePath := matchPath.Child("path").Child("type")
+		allErrs = append(allErrs, field.NotSupported(typePath, string(*m.Path.Type), supportedPathMatchTypes))
+	}
+
+	// Exact is the default type of the header and query param matches
+	for i, h := range m.Headers {
+		if h.Type != nil && *h.Type != v1beta1.HeaderMatchExact {
+			typePath := matchPath.Child("headers").Index(i).Child("type")
+			allErrs = append(allErrs, field.NotSupported(typePath, string(*h.Type), supportedHeaderMatchTypes))
+		}
+	}
+
+	for i, p := range m.QueryParams {
+		if p.Type != nil && *p.Type != v1beta1.QueryParamMatchExact {
+			typePath := matchPath.Child("queryParams").Index(i).Child("type")
+			allErrs = append(allErrs, field.NotSupported(typePath, string(*p.Type), supportedQueryParamMatchTypes))
+		}
+	}
+
+	return allErrs
+}
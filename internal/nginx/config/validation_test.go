@@ -0,0 +1,184 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/helpers"
+)
+
+func TestValidateHTTPRoute(t *testing.T) {
+	createRoute := func(match v1beta1.HTTPRouteMatch, filters ...v1beta1.HTTPRouteFilter) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test",
+				Name:      "route1",
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						Matches: []v1beta1.HTTPRouteMatch{match},
+						Filters: filters,
+					},
+				},
+			},
+		}
+	}
+
+	pathMatch := v1beta1.HTTPRouteMatch{
+		Path: &v1beta1.HTTPPathMatch{
+			Type:  helpers.GetPathMatchTypePointer(v1beta1.PathMatchPathPrefix),
+			Value: helpers.GetStringPointer("/"),
+		},
+	}
+
+	tests := []struct {
+		hr       *v1beta1.HTTPRoute
+		expected []string
+		msg      string
+	}{
+		{
+			hr: createRoute(
+				v1beta1.HTTPRouteMatch{
+					Path: pathMatch.Path,
+					Headers: []v1beta1.HTTPHeaderMatch{
+						{
+							Type:  helpers.GetHeaderMatchTypePointer(v1beta1.HeaderMatchExact),
+							Name:  "version",
+							Value: "v1",
+						},
+						{
+							Name:  "env",
+							Value: "canary",
+						},
+					},
+					QueryParams: []v1beta1.HTTPQueryParamMatch{
+						{
+							Type:  helpers.GetQueryParamMatchTypePointer(v1beta1.QueryParamMatchExact),
+							Name:  "debug",
+							Value: "true",
+						},
+					},
+				},
+				v1beta1.HTTPRouteFilter{
+					Type: v1beta1.HTTPRouteFilterRequestMirror,
+				},
+			),
+			expected: nil,
+			msg:      "supported features",
+		},
+		{
+			hr: createRoute(v1beta1.HTTPRouteMatch{
+				Path: &v1beta1.HTTPPathMatch{
+					Type:  helpers.GetPathMatchTypePointer(v1beta1.PathMatchExact),
+					Value: helpers.GetStringPointer("/coffee"),
+				},
+			}),
+			expected: []string{
+				`spec.rules[0].matches[0].path.type: Unsupported value: "Exact": supported values: "PathPrefix"`,
+			},
+			msg: "exact path match",
+		},
+		{
+			hr: createRoute(v1beta1.HTTPRouteMatch{
+				Path: &v1beta1.HTTPPathMatch{
+					Type:  helpers.GetPathMatchTypePointer(v1beta1.PathMatchRegularExpression),
+					Value: helpers.GetStringPointer("/coffee/.*"),
+				},
+			}),
+			expected: []string{
+				`spec.rules[0].matches[0].path.type: Unsupported value: "RegularExpression": ` +
+					`supported values: "PathPrefix"`,
+			},
+			msg: "regex path match",
+		},
+		{
+			hr: createRoute(v1beta1.HTTPRouteMatch{
+				Headers: []v1beta1.HTTPHeaderMatch{
+					{
+						Name:  "version",
+						Value: "v1",
+					},
+					{
+						Type:  helpers.GetHeaderMatchTypePointer(v1beta1.HeaderMatchRegularExpression),
+						Name:  "env",
+						Value: "canary-.*",
+					},
+				},
+			}),
+			expected: []string{
+				`spec.rules[0].matches[0].headers[1].type: Unsupported value: "RegularExpression": ` +
+					`supported values: "Exact"`,
+			},
+			msg: "regex header match",
+		},
+		{
+			hr: createRoute(v1beta1.HTTPRouteMatch{
+				QueryParams: []v1beta1.HTTPQueryParamMatch{
+					{
+						Type:  helpers.GetQueryParamMatchTypePointer(v1beta1.QueryParamMatchRegularExpression),
+						Name:  "debug",
+						Value: "true|yes",
+					},
+				},
+			}),
+			expected: []string{
+				`spec.rules[0].matches[0].queryParams[0].type: Unsupported value: "RegularExpression": ` +
+					`supported values: "Exact"`,
+			},
+			msg: "regex query param match",
+		},
+		{
+			hr: createRoute(
+				pathMatch,
+				v1beta1.HTTPRouteFilter{Type: v1beta1.HTTPRouteFilterRequestHeaderModifier},
+				v1beta1.HTTPRouteFilter{Type: v1beta1.HTTPRouteFilterRequestMirror},
+				v1beta1.HTTPRouteFilter{Type: v1beta1.HTTPRouteFilterRequestRedirect},
+				v1beta1.HTTPRouteFilter{Type: v1beta1.HTTPRouteFilterURLRewrite},
+				v1beta1.HTTPRouteFilter{Type: v1beta1.HTTPRouteFilterExtensionRef},
+			),
+			expected: []string{
+				`spec.rules[0].filters[0].type: Unsupported value: "RequestHeaderModifier": ` +
+					`supported values: "RequestMirror"`,
+				`spec.rules[0].filters[2].type: Unsupported value: "RequestRedirect": ` +
+					`supported values: "RequestMirror"`,
+				`spec.rules[0].filters[3].type: Unsupported value: "URLRewrite": supported values: "RequestMirror"`,
+				`spec.rules[0].filters[4].type: Unsupported value: "ExtensionRef": supported values: "RequestMirror"`,
+			},
+			msg: "unsupported filters",
+		},
+		{
+			hr: func() *v1beta1.HTTPRoute {
+				hr := createRoute(pathMatch)
+				hr.Spec.Rules[0].BackendRefs = []v1beta1.HTTPBackendRef{
+					{
+						Filters: []v1beta1.HTTPRouteFilter{
+							{Type: v1beta1.HTTPRouteFilterRequestHeaderModifier},
+							{Type: v1beta1.HTTPRouteFilterRequestMirror},
+						},
+					},
+				}
+				return hr
+			}(),
+			expected: []string{
+				`spec.rules[0].backendRefs[0].filters[1].type: Unsupported value: "RequestMirror": ` +
+					`supported values: "RequestHeaderModifier"`,
+			},
+			msg: "unsupported backendRef filters",
+		},
+	}
+
+	for _, test := range tests {
+		var result []string
+		for _, err := range ValidateHTTPRoute(test.hr) {
+			result = append(result, err.Error())
+		}
+
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("ValidateHTTPRoute() %q mismatch (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Validator
+
+// Validator validates the generated NGINX configuration.
+type Validator interface {
+	// Validate returns an error if the configuration is invalid.
+	Validate(cfg []byte) error
+}
+
+// DryRun generates the NGINX configuration of the main, http and stream contexts like GenerateMain, Generate and
+// GenerateStream and validates it with the validator, without writing it. It is meant for the checks before
+// the configuration is applied, for example, in CI.
+// It returns the warnings of the generation and the first error of the generation or the validation.
+func DryRun(
+	ctx context.Context,
+	generator Generator,
+	validator Validator,
+	conf state.Configuration,
+) (Warnings, error) {
+	main, err := generator.GenerateMain()
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate the main configuration: %w", err)
+	}
+	if err := validator.Validate(main); err != nil {
+		return nil, fmt.Errorf("invalid main configuration: %w", err)
+	}
+
+	warnings := newWarnings()
+
+	http, httpWarnings, err := generator.Generate(ctx, conf)
+	warnings.Add(httpWarnings)
+	if err != nil {
+		return warnings, fmt.Errorf("cannot generate the http configuration: %w", err)
+	}
+	if err := validator.Validate(http); err != nil {
+		return warnings, fmt.Errorf("invalid http configuration: %w", err)
+	}
+
+	stream, streamWarnings, err := generator.GenerateStream(ctx, conf)
+	warnings.Add(streamWarnings)
+	if err != nil {
+		return warnings, fmt.Errorf("cannot generate the stream configuration: %w", err)
+	}
+	if err := validator.Validate(stream); err != nil {
+		return warnings, fmt.Errorf("invalid stream configuration: %w", err)
+	}
+
+	return warnings, nil
+}
+
+// StructuralValidator validates the structure of the NGINX configuration without NGINX: every directive is
+// terminated, the blocks are named and balanced and the quotes are closed. It doesn't validate the names and
+// the arguments of the directives, which only NGINX knows.
+type StructuralValidator struct{}
+
+// NewStructuralValidator creates a new StructuralValidator.
+func NewStructuralValidator() *StructuralValidator {
+	return &StructuralValidator{}
+}
+
+func (v *StructuralValidator) Validate(cfg []byte) error {
+	var (
+		// depth is the number of the open blocks.
+		depth int
+		// words is the number of the words of the current directive.
+		words  int
+		inWord bool
+		quote  byte
+		line   = 1
+		// quoteLine is the line of the opening quote.
+		quoteLine int
+	)
+
+	for i := 0; i < len(cfg); i++ {
+		c := cfg[i]
+
+		if c == '\n' {
+			line++
+		}
+
+		if quote != 0 {
+			switch c {
+			case quote:
+				quote = 0
+			case '\\':
+				i++
+				if i < len(cfg) && cfg[i] == '\n' {
+					line++
+				}
+			}
+			continue
+		}
+
+		switch c {
+		case '#':
+			for i+1 < len(cfg) && cfg[i+1] != '\n' {
+				i++
+			}
+		case '"', '\'':
+			if !inWord {
+				words++
+			}
+			quote = c
+			quoteLine = line
+			inWord = true
+		case ' ', '\t', '\r', '\n':
+			inWord = false
+		case ';':
+			if words == 0 {
+				return fmt.Errorf("line %d: unexpected \";\"", line)
+			}
+			words, inWord = 0, false
+		case '{':
+			if words == 0 {
+				return fmt.Errorf("line %d: block without a directive name", line)
+			}
+			depth++
+			words, inWord = 0, false
+		case '}':
+			if words > 0 {
+				return fmt.Errorf("line %d: unexpected \"}\", the directive is not terminated by \";\"", line)
+			}
+			if depth == 0 {
+				return fmt.Errorf("line %d: unexpected \"}\"", line)
+			}
+			depth--
+			inWord = false
+		default:
+			if !inWord {
+				words++
+			}
+			inWord = true
+		}
+	}
+
+	switch {
+	case quote != 0:
+		return fmt.Errorf("line %d: unterminated quote", quoteLine)
+	case words > 0:
+		return errors.New("unexpected end of file, the directive is not terminated by \";\"")
+	case depth > 0:
+		return errors.New("unexpected end of file, expecting \"}\"")
+	}
+
+	return nil
+}
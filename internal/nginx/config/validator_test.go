@@ -0,0 +1,227 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/nginx/config"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/nginx/config/configfakes"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state/statefakes"
+)
+
+func TestDryRun(t *testing.T) {
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "route1",
+		},
+	}
+
+	createGenerator := func() *configfakes.FakeGenerator {
+		generator := &configfakes.FakeGenerator{}
+		generator.GenerateMainReturns([]byte("main"), nil)
+		generator.GenerateReturns([]byte("http"), config.Warnings{hr: []string{"warning"}}, nil)
+		generator.GenerateStreamReturns([]byte("stream"), nil, nil)
+		return generator
+	}
+
+	failOn := func(invalid string) func([]byte) error {
+		return func(cfg []byte) error {
+			if string(cfg) == invalid {
+				return errors.New("invalid")
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		generator        *configfakes.FakeGenerator
+		validate         func([]byte) error
+		expectedWarnings config.Warnings
+		expectedErr      string
+		expectedValidate []string
+		msg              string
+	}{
+		{
+			generator:        createGenerator(),
+			validate:         failOn(""),
+			expectedWarnings: config.Warnings{hr: []string{"warning"}},
+			expectedValidate: []string{"main", "http", "stream"},
+			msg:              "valid configuration",
+		},
+		{
+			generator:        createGenerator(),
+			validate:         failOn("main"),
+			expectedErr:      "invalid main configuration: invalid",
+			expectedValidate: []string{"main"},
+			msg:              "invalid main configuration",
+		},
+		{
+			generator:        createGenerator(),
+			validate:         failOn("http"),
+			expectedWarnings: config.Warnings{hr: []string{"warning"}},
+			expectedErr:      "invalid http configuration: invalid",
+			expectedValidate: []string{"main", "http"},
+			msg:              "invalid http configuration",
+		},
+		{
+			generator:        createGenerator(),
+			validate:         failOn("stream"),
+			expectedWarnings: config.Warnings{hr: []string{"warning"}},
+			expectedErr:      "invalid stream configuration: invalid",
+			expectedValidate: []string{"main", "http", "stream"},
+			msg:              "invalid stream configuration",
+		},
+		{
+			generator: func() *configfakes.FakeGenerator {
+				generator := createGenerator()
+				generator.GenerateReturns(nil, nil, errors.New("template error"))
+				return generator
+			}(),
+			validate:         failOn(""),
+			expectedWarnings: config.Warnings{},
+			expectedErr:      "cannot generate the http configuration: template error",
+			expectedValidate: []string{"main"},
+			msg:              "generation error",
+		},
+	}
+
+	for _, test := range tests {
+		validator := &configfakes.FakeValidator{}
+		validator.ValidateCalls(test.validate)
+
+		warnings, err := config.DryRun(context.Background(), test.generator, validator, state.Configuration{})
+
+		if test.expectedErr == "" {
+			if err != nil {
+				t.Errorf("DryRun() returned unexpected error %v for the case of %q", err, test.msg)
+			}
+		} else if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("DryRun() returned error %v but expected %q for the case of %q", err, test.expectedErr, test.msg)
+		}
+
+		if diff := cmp.Diff(test.expectedWarnings, warnings); diff != "" {
+			t.Errorf("DryRun() mismatch on warnings for the case of %q (-want +got):\n%s", test.msg, diff)
+		}
+
+		validated := make([]string, 0, validator.ValidateCallCount())
+		for i := 0; i < validator.ValidateCallCount(); i++ {
+			validated = append(validated, string(validator.ValidateArgsForCall(i)))
+		}
+		if diff := cmp.Diff(test.expectedValidate, validated); diff != "" {
+			t.Errorf("DryRun() mismatch on the validated configurations for the case of %q (-want +got):\n%s",
+				test.msg, diff)
+		}
+	}
+}
+
+func TestStructuralValidator(t *testing.T) {
+	tests := []struct {
+		cfg         string
+		expectedErr string
+		msg         string
+	}{
+		{
+			cfg: `
+server {
+	listen 80;
+	# a comment with { and ;
+	location / {
+		return 200 "a quoted ; { } and \" value";
+	}
+}
+`,
+			msg: "valid configuration",
+		},
+		{
+			cfg: "",
+			msg: "empty configuration",
+		},
+		{
+			cfg:         "server {\n\tlisten 80\n}\n",
+			expectedErr: `line 3: unexpected "}", the directive is not terminated by ";"`,
+			msg:         "directive without semicolon",
+		},
+		{
+			cfg:         "server {\n\tlisten 80;\n",
+			expectedErr: `unexpected end of file, expecting "}"`,
+			msg:         "unclosed block",
+		},
+		{
+			cfg:         "server {\n}\n}\n",
+			expectedErr: `line 3: unexpected "}"`,
+			msg:         "unexpected closing brace",
+		},
+		{
+			cfg:         "{\n}\n",
+			expectedErr: "line 1: block without a directive name",
+			msg:         "block without name",
+		},
+		{
+			cfg:         "listen 80;;\n",
+			expectedErr: `line 1: unexpected ";"`,
+			msg:         "empty directive",
+		},
+		{
+			cfg:         "return 200 \"value;\n",
+			expectedErr: "line 1: unterminated quote",
+			msg:         "unterminated quote",
+		},
+		{
+			cfg:         "listen 80",
+			expectedErr: `unexpected end of file, the directive is not terminated by ";"`,
+			msg:         "unterminated directive at the end",
+		},
+	}
+
+	validator := config.NewStructuralValidator()
+
+	for _, test := range tests {
+		err := validator.Validate([]byte(test.cfg))
+
+		if test.expectedErr == "" {
+			if err != nil {
+				t.Errorf("Validate() returned unexpected error %v for the case of %q", err, test.msg)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("Validate() returned error %v but expected %q for the case of %q", err, test.expectedErr, test.msg)
+		}
+	}
+}
+
+func TestStructuralValidatorGeneratedConfiguration(t *testing.T) {
+	conf := state.Configuration{
+		HTTPServers: []state.VirtualServer{
+			{
+				Hostname: "cafe.example.com",
+			},
+		},
+	}
+
+	generator := config.NewGeneratorImpl(&statefakes.FakeServiceStore{}, config.WithSecurityHeaders(true))
+
+	warnings, err := config.DryRun(context.Background(), generator, config.NewStructuralValidator(), conf)
+	if err != nil {
+		t.Errorf("DryRun() returned unexpected error %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("DryRun() returned unexpected warnings %v", warnings)
+	}
+
+	// the validator catches a broken configuration
+	cfg, _, _ := generator.Generate(context.Background(), conf)
+	broken := strings.Replace(string(cfg), "server_name cafe.example.com;", "server_name cafe.example.com", 1)
+	if err := config.NewStructuralValidator().Validate([]byte(broken)); err == nil {
+		t.Errorf("Validate() didn't return an error for the broken configuration:\n%s", broken)
+	}
+}
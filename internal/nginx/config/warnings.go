@@ -30,3 +30,26 @@ func (w Warnings) Add(warnings Warnings) {
 		w[k] = append(w[k], v...)
 	}
 }
+
+// Dedup returns new Warnings without the duplicate warnings of each object.
+// The order of the remaining warnings is preserved.
+func (w Warnings) Dedup() Warnings {
+	result := make(Warnings, len(w))
+
+	for obj, msgs := range w {
+		seen := make(map[string]struct{}, len(msgs))
+		unique := make([]string, 0, len(msgs))
+
+		for _, msg := range msgs {
+			if _, exists := seen[msg]; exists {
+				continue
+			}
+			seen[msg] = struct{}{}
+			unique = append(unique, msg)
+		}
+
+		result[obj] = unique
+	}
+
+	return result
+}
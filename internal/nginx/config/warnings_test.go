@@ -130,3 +130,37 @@ func TestAdd(t *testing.T) {
 		}
 	}
 }
+
+func TestDedup(t *testing.T) {
+	obj1 := &v1beta1.HTTPRoute{}
+	obj2 := &v1beta1.HTTPRoute{}
+
+	warnings := Warnings{
+		obj1: []string{
+			"empty backend refs",
+			"first",
+			"empty backend refs",
+			"second",
+			"first",
+		},
+		obj2: []string{
+			"first",
+		},
+	}
+
+	expected := Warnings{
+		obj1: []string{
+			"empty backend refs",
+			"first",
+			"second",
+		},
+		obj2: []string{
+			"first",
+		},
+	}
+
+	result := warnings.Dedup()
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Dedup mismatch (-want +got):\n%s", diff)
+	}
+}
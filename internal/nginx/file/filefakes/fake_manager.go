@@ -20,6 +20,30 @@ type FakeManager struct {
 	writeHTTPServersConfigReturnsOnCall map[int]struct {
 		result1 error
 	}
+	WriteMainConfigStub        func(string, []byte) error
+	writeMainConfigMutex       sync.RWMutex
+	writeMainConfigArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+	}
+	writeMainConfigReturns struct {
+		result1 error
+	}
+	writeMainConfigReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WriteStreamServersConfigStub        func(string, []byte) error
+	writeStreamServersConfigMutex       sync.RWMutex
+	writeStreamServersConfigArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+	}
+	writeStreamServersConfigReturns struct {
+		result1 error
+	}
+	writeStreamServersConfigReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -91,11 +115,149 @@ func (fake *FakeManager) WriteHTTPServersConfigReturnsOnCall(i int, result1 erro
 	}{result1}
 }
 
+func (fake *FakeManager) WriteMainConfig(arg1 string, arg2 []byte) error {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.writeMainConfigMutex.Lock()
+	ret, specificReturn := fake.writeMainConfigReturnsOnCall[len(fake.writeMainConfigArgsForCall)]
+	fake.writeMainConfigArgsForCall = append(fake.writeMainConfigArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+	}{arg1, arg2Copy})
+	stub := fake.WriteMainConfigStub
+	fakeReturns := fake.writeMainConfigReturns
+	fake.recordInvocation("WriteMainConfig", []interface{}{arg1, arg2Copy})
+	fake.writeMainConfigMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeManager) WriteMainConfigCallCount() int {
+	fake.writeMainConfigMutex.RLock()
+	defer fake.writeMainConfigMutex.RUnlock()
+	return len(fake.writeMainConfigArgsForCall)
+}
+
+func (fake *FakeManager) WriteMainConfigCalls(stub func(string, []byte) error) {
+	fake.writeMainConfigMutex.Lock()
+	defer fake.writeMainConfigMutex.Unlock()
+	fake.WriteMainConfigStub = stub
+}
+
+func (fake *FakeManager) WriteMainConfigArgsForCall(i int) (string, []byte) {
+	fake.writeMainConfigMutex.RLock()
+	defer fake.writeMainConfigMutex.RUnlock()
+	argsForCall := fake.writeMainConfigArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeManager) WriteMainConfigReturns(result1 error) {
+	fake.writeMainConfigMutex.Lock()
+	defer fake.writeMainConfigMutex.Unlock()
+	fake.WriteMainConfigStub = nil
+	fake.writeMainConfigReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeManager) WriteMainConfigReturnsOnCall(i int, result1 error) {
+	fake.writeMainConfigMutex.Lock()
+	defer fake.writeMainConfigMutex.Unlock()
+	fake.WriteMainConfigStub = nil
+	if fake.writeMainConfigReturnsOnCall == nil {
+		fake.writeMainConfigReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.writeMainConfigReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeManager) WriteStreamServersConfig(arg1 string, arg2 []byte) error {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.writeStreamServersConfigMutex.Lock()
+	ret, specificReturn := fake.writeStreamServersConfigReturnsOnCall[len(fake.writeStreamServersConfigArgsForCall)]
+	fake.writeStreamServersConfigArgsForCall = append(fake.writeStreamServersConfigArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+	}{arg1, arg2Copy})
+	stub := fake.WriteStreamServersConfigStub
+	fakeReturns := fake.writeStreamServersConfigReturns
+	fake.recordInvocation("WriteStreamServersConfig", []interface{}{arg1, arg2Copy})
+	fake.writeStreamServersConfigMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeManager) WriteStreamServersConfigCallCount() int {
+	fake.writeStreamServersConfigMutex.RLock()
+	defer fake.writeStreamServersConfigMutex.RUnlock()
+	return len(fake.writeStreamServersConfigArgsForCall)
+}
+
+func (fake *FakeManager) WriteStreamServersConfigCalls(stub func(string, []byte) error) {
+	fake.writeStreamServersConfigMutex.Lock()
+	defer fake.writeStreamServersConfigMutex.Unlock()
+	fake.WriteStreamServersConfigStub = stub
+}
+
+func (fake *FakeManager) WriteStreamServersConfigArgsForCall(i int) (string, []byte) {
+	fake.writeStreamServersConfigMutex.RLock()
+	defer fake.writeStreamServersConfigMutex.RUnlock()
+	argsForCall := fake.writeStreamServersConfigArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeManager) WriteStreamServersConfigReturns(result1 error) {
+	fake.writeStreamServersConfigMutex.Lock()
+	defer fake.writeStreamServersConfigMutex.Unlock()
+	fake.WriteStreamServersConfigStub = nil
+	fake.writeStreamServersConfigReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeManager) WriteStreamServersConfigReturnsOnCall(i int, result1 error) {
+	fake.writeStreamServersConfigMutex.Lock()
+	defer fake.writeStreamServersConfigMutex.Unlock()
+	fake.WriteStreamServersConfigStub = nil
+	if fake.writeStreamServersConfigReturnsOnCall == nil {
+		fake.writeStreamServersConfigReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.writeStreamServersConfigReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeManager) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.writeHTTPServersConfigMutex.RLock()
 	defer fake.writeHTTPServersConfigMutex.RUnlock()
+	fake.writeMainConfigMutex.RLock()
+	defer fake.writeMainConfigMutex.RUnlock()
+	fake.writeStreamServersConfigMutex.RLock()
+	defer fake.writeStreamServersConfigMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
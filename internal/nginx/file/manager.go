@@ -6,7 +6,11 @@ import (
 	"path/filepath"
 )
 
-const confdFolder = "/etc/nginx/conf.d"
+const (
+	confdFolder       = "/etc/nginx/conf.d"
+	streamConfdFolder = "/etc/nginx/stream-conf.d"
+	mainConfdFolder   = "/etc/nginx/main-conf.d"
+)
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Manager
 
@@ -16,6 +20,12 @@ type Manager interface {
 	// The name distinguishes this config among all other configs. For that, it must be unique.
 	// Note that name is not the name of the corresponding configuration file.
 	WriteHTTPServersConfig(name string, cfg []byte) error
+	// WriteStreamServersConfig writes the stream servers config on the file system.
+	// The name has the same meaning as in WriteHTTPServersConfig.
+	WriteStreamServersConfig(name string, cfg []byte) error
+	// WriteMainConfig writes the config of the main context on the file system.
+	// The name has the same meaning as in WriteHTTPServersConfig.
+	WriteMainConfig(name string, cfg []byte) error
 }
 
 // ManagerImpl is an implementation of Manager.
@@ -27,8 +37,18 @@ func NewManagerImpl() *ManagerImpl {
 }
 
 func (m *ManagerImpl) WriteHTTPServersConfig(name string, cfg []byte) error {
-	path := getPathForServerConfig(name)
+	return writeConfig(getPathForServerConfig(name), cfg)
+}
+
+func (m *ManagerImpl) WriteStreamServersConfig(name string, cfg []byte) error {
+	return writeConfig(getPathForStreamServerConfig(name), cfg)
+}
+
+func (m *ManagerImpl) WriteMainConfig(name string, cfg []byte) error {
+	return writeConfig(getPathForMainConfig(name), cfg)
+}
 
+func writeConfig(path string, cfg []byte) error {
 	file, err := os.Create(path)
 	if err != nil {
 		return fmt.Errorf("failed to create server config %s: %w", path, err)
@@ -47,3 +67,11 @@ func (m *ManagerImpl) WriteHTTPServersConfig(name string, cfg []byte) error {
 func getPathForServerConfig(name string) string {
 	return filepath.Join(confdFolder, name+".conf")
 }
+
+func getPathForStreamServerConfig(name string) string {
+	return filepath.Join(streamConfdFolder, name+".conf")
+}
+
+func getPathForMainConfig(name string) string {
+	return filepath.Join(mainConfdFolder, name+".conf")
+}
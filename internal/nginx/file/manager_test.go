@@ -10,3 +10,21 @@ func TestGetPathForServerConfig(t *testing.T) {
 		t.Errorf("getPathForServerConfig() returned %q but expected %q", result, expected)
 	}
 }
+
+func TestGetPathForStreamServerConfig(t *testing.T) {
+	expected := "/etc/nginx/stream-conf.d/tcp.conf"
+
+	result := getPathForStreamServerConfig("tcp")
+	if result != expected {
+		t.Errorf("getPathForStreamServerConfig() returned %q but expected %q", result, expected)
+	}
+}
+
+func TestGetPathForMainConfig(t *testing.T) {
+	expected := "/etc/nginx/main-conf.d/main.conf"
+
+	result := getPathForMainConfig("main")
+	if result != expected {
+		t.Errorf("getPathForMainConfig() returned %q but expected %q", result, expected)
+	}
+}
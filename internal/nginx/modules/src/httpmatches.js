@@ -104,7 +104,7 @@ function testMatch(r, match) {
   // check headers
   if (match.headers) {
     try {
-      let found = headersMatch(r.headersIn, match.headers);
+      let found = headersMatch(r.headersIn, match.headers, match.caseInsensitiveHeaders);
       if (!found) {
         return false;
       }
@@ -129,7 +129,7 @@ function testMatch(r, match) {
   return true;
 }
 
-function headersMatch(requestHeaders, headers) {
+function headersMatch(requestHeaders, headers, caseInsensitive) {
   for (let i = 0; i < headers.length; i++) {
     const h = headers[i];
     const kv = h.split(':');
@@ -147,8 +147,16 @@ function headersMatch(requestHeaders, headers) {
     }
 
     // split on comma because nginx uses commas to delimit multiple header values
-    const values = val.split(',');
-    if (!values.includes(kv[1])) {
+    let values = val.split(',');
+    let expected = kv[1];
+
+    // Header values are compared in a case-sensitive manner unless the match opts in to case-insensitive comparison.
+    if (caseInsensitive) {
+      values = values.map((v) => v.toLowerCase());
+      expected = expected.toLowerCase();
+    }
+
+    if (!values.includes(expected)) {
       return false;
     }
   }
@@ -162,13 +170,12 @@ function paramsMatch(requestParams, params) {
     // We store query parameter matches as strings with the format "key=value"; however, there may be more than one instance of "=" in the string.
     // To recover the key and value, we need to find the first occurrence of "=" in the string.
     const idx = params[i].indexOf('=');
-    // Check for an improperly constructed query parameter match. There are three possible error cases:
+    // Check for an improperly constructed query parameter match. There are two possible error cases:
     // (1) if the index is -1, then there are no "=" in the string (e.g. "keyvalue")
-    // (2) if the index is 0, then there is no value in the string (e.g. "key=").
-    // NOTE: While query parameter values are permitted to be empty, the Gateway API Spec forces the value to be a non-empty string.
-    // https://github.com/kubernetes-sigs/gateway-api/blob/50e61865db9659111582080daa5ca1a91bbe265d/apis/v1alpha2/httproute_types.go#L375
-    // (3) if the index is equal to length -1, then there is no key in the string (e.g. "=value").
-    if (idx === -1 || (idx === 0) | (idx === p.length - 1)) {
+    // (2) if the index is 0, then there is no key in the string (e.g. "=value").
+    // NOTE: An empty value (e.g. "key=") is valid. It matches the requests that include the parameter with an empty
+    // value (e.g. "?key=") but not the requests that don't include the parameter.
+    if (idx === -1 || idx === 0) {
       throw Error(`invalid query parameter: ${p}`);
     }
 
@@ -177,7 +184,8 @@ function paramsMatch(requestParams, params) {
 
     const val = requestParams[kv[0]];
 
-    if (!val || val !== kv[1]) {
+    // A missing parameter is undefined, while a parameter with an empty value is an empty string.
+    if (val === undefined || val !== kv[1]) {
       return false;
     }
   }
@@ -116,6 +116,18 @@ describe('testMatch', () => {
       }),
       expected: true,
     },
+    {
+      name: 'returns true if headers match with case-insensitive comparison',
+      match: { headers: ['header:VALUE'], caseInsensitiveHeaders: true },
+      request: createRequest({ headers: { header: 'value' } }),
+      expected: true,
+    },
+    {
+      name: 'returns false if headers case does not match with case-sensitive comparison',
+      match: { headers: ['header:VALUE'] },
+      request: createRequest({ headers: { header: 'value' } }),
+      expected: false,
+    },
     {
       name: 'returns false if method does not match',
       match: { method: 'POST' },
@@ -168,6 +180,7 @@ describe('findWinningMatch', () => {
   const methodMatch = { method: 'POST' };
   const anyMatch = { any: true };
   const malformedMatch = { headers: ['malformed'] };
+  const getMatch = { method: 'GET' };
 
   const tests = [
     {
@@ -176,6 +189,24 @@ describe('findWinningMatch', () => {
       request: createRequest({ method: 'POST', params: { key: 'value' } }),
       expected: queryParamMatch,
     },
+    {
+      name: 'returns the GET match before the any match at the same path',
+      matches: [getMatch, methodMatch, anyMatch],
+      request: createRequest({ method: 'GET' }),
+      expected: getMatch,
+    },
+    {
+      name: 'returns the POST match before the any match at the same path',
+      matches: [getMatch, methodMatch, anyMatch],
+      request: createRequest({ method: 'POST' }),
+      expected: methodMatch,
+    },
+    {
+      name: 'returns the any match for the other methods',
+      matches: [getMatch, methodMatch, anyMatch],
+      request: createRequest({ method: 'DELETE' }),
+      expected: anyMatch,
+    },
     {
       name: 'returns null when no match exists',
       matches: [headerMatch, queryParamMatch, methodMatch],
@@ -260,6 +291,28 @@ describe('headersMatch', () => {
       },
       expected: true,
     },
+    {
+      name: 'returns true if the header values case does not match with case-insensitive comparison',
+      headers: multipleHeaders,
+      requestHeaders: {
+        header1: 'value1', // this value is not the correct case
+        header2: 'VALUE2', // this value is not the correct case
+        header3: 'value3',
+      },
+      caseInsensitive: true,
+      expected: true,
+    },
+    {
+      name: 'returns false if one of the header values does not match with case-insensitive comparison',
+      headers: multipleHeaders,
+      requestHeaders: {
+        header1: 'value1',
+        header2: 'value2',
+        header3: 'wrong-value', // this value does not match
+      },
+      caseInsensitive: true,
+      expected: false,
+    },
   ];
 
   tests.forEach((test) => {
@@ -269,7 +322,9 @@ describe('headersMatch', () => {
           'invalid header match',
         );
       } else {
-        expect(hm.headersMatch(test.requestHeaders, test.headers)).to.equal(test.expected);
+        expect(hm.headersMatch(test.requestHeaders, test.headers, test.caseInsensitive)).to.equal(
+          test.expected,
+        );
       }
     });
   });
@@ -284,11 +339,6 @@ describe('paramsMatch', () => {
       params: ['=nokey'],
       expectThrow: true,
     },
-    {
-      name: 'throws an error if a param has no value',
-      params: ['novalue='],
-      expectThrow: true,
-    },
     {
       name: 'throws an error a param has no equal sign delimiter',
       params: ['keyval'],
@@ -318,6 +368,30 @@ describe('paramsMatch', () => {
       requestParams: params,
       expected: false,
     },
+    {
+      name: 'returns true if a param with an empty value matches',
+      params: ['flag='],
+      requestParams: { flag: '' },
+      expected: true,
+    },
+    {
+      name: 'returns false if a param with an empty value is missing from request',
+      params: ['flag='],
+      requestParams: {},
+      expected: false,
+    },
+    {
+      name: 'returns false if a param with an empty value has a non-empty value in request',
+      params: ['flag='],
+      requestParams: { flag: 'on' },
+      expected: false,
+    },
+    {
+      name: 'returns false if a param has an empty value in request',
+      params: ['flag=on'],
+      requestParams: { flag: '' },
+      expected: false,
+    },
   ];
 
   tests.forEach((test) => {
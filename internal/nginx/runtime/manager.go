@@ -4,13 +4,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 )
 
-const pidFile = "/etc/nginx/nginx.pid"
+const (
+	pidFile        = "/etc/nginx/nginx.pid"
+	mainConfigFile = "/etc/nginx/nginx.conf"
+	// njsModuleFile is the file of the njs module, which NGINX uses to evaluate HTTPRoute matches.
+	njsModuleFile = "ngx_http_js_module.so"
+)
 
 type readFileFunc func(string) ([]byte, error)
 
@@ -77,3 +83,91 @@ func findMainProcess(readFile readFileFunc) (int, error) {
 
 	return pid, nil
 }
+
+// IsNJSAvailable checks if the main NGINX configuration file loads the njs module with a load_module directive.
+func IsNJSAvailable() (bool, error) {
+	return isNJSAvailable(os.ReadFile)
+}
+
+func isNJSAvailable(readFile readFileFunc) (bool, error) {
+	content, err := readFile(mainConfigFile)
+	if err != nil {
+		return false, err
+	}
+
+	for _, args := range parseDirectives(content) {
+		if len(args) == 2 && args[0] == "load_module" && path.Base(args[1]) == njsModuleFile {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// parseDirectives splits the NGINX configuration into the directives and returns the words of every directive.
+// The comments are skipped, and the quotes around the words are removed.
+// The blocks are not tracked: the opening and closing braces only terminate the directives, like semicolons.
+func parseDirectives(content []byte) [][]string {
+	var (
+		directives [][]string
+		words      []string
+		word       strings.Builder
+		inWord     bool
+		quote      byte
+	)
+
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	endDirective := func() {
+		endWord()
+		if len(words) > 0 {
+			directives = append(directives, words)
+			words = nil
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		if quote != 0 {
+			switch c {
+			case quote:
+				quote = 0
+			case '\\':
+				if i+1 < len(content) {
+					i++
+					word.WriteByte(content[i])
+				}
+			default:
+				word.WriteByte(c)
+			}
+			continue
+		}
+
+		switch c {
+		case '#':
+			endWord()
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case '"', '\'':
+			quote = c
+			inWord = true
+		case ' ', '\t', '\r', '\n':
+			endWord()
+		case ';', '{', '}':
+			endDirective()
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	return directives
+}
@@ -3,6 +3,8 @@ package runtime
 import (
 	"errors"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestFindMainProcess(t *testing.T) {
@@ -68,3 +70,110 @@ func TestFindMainProcess(t *testing.T) {
 		}
 	}
 }
+
+func TestParseDirectives(t *testing.T) {
+	content := []byte(`
+load_module modules/ngx_http_js_module.so; # the njs module
+events {}
+http {
+    # include /etc/nginx/disabled.conf;
+    log_format main '$remote_addr "quoted; value"';
+}
+`)
+
+	expected := [][]string{
+		{"load_module", "modules/ngx_http_js_module.so"},
+		{"events"},
+		{"http"},
+		{"log_format", "main", `$remote_addr "quoted; value"`},
+	}
+
+	result := parseDirectives(content)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("parseDirectives() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestIsNJSAvailable(t *testing.T) {
+	readFileFuncGen := func(content []byte) readFileFunc {
+		return func(name string) ([]byte, error) {
+			if name != mainConfigFile {
+				return nil, errors.New("error")
+			}
+			return content, nil
+		}
+	}
+	readFileError := func(string) ([]byte, error) {
+		return nil, errors.New("error")
+	}
+
+	tests := []struct {
+		readFile    readFileFunc
+		expected    bool
+		expectError bool
+		msg         string
+	}{
+		{
+			readFile: readFileFuncGen(
+				[]byte("load_module /usr/lib/nginx/modules/ngx_http_js_module.so; events {} http {}"),
+			),
+			expected:    true,
+			expectError: false,
+			msg:         "njs module loaded",
+		},
+		{
+			readFile:    readFileFuncGen([]byte("events {} http {}")),
+			expected:    false,
+			expectError: false,
+			msg:         "njs module not loaded",
+		},
+		{
+			readFile: readFileFuncGen(
+				[]byte("# load_module /usr/lib/nginx/modules/ngx_http_js_module.so;\nevents {} http {}"),
+			),
+			expected:    false,
+			expectError: false,
+			msg:         "njs module load commented out",
+		},
+		{
+			readFile: readFileFuncGen(
+				[]byte("events {} http { log_format main 'ngx_http_js_module.so'; }"),
+			),
+			expected:    false,
+			expectError: false,
+			msg:         "njs module file mentioned outside of load_module",
+		},
+		{
+			readFile: readFileFuncGen(
+				[]byte("load_module \"modules/ngx_http_js_module.so\"; # njs\nevents {} http {}"),
+			),
+			expected:    true,
+			expectError: false,
+			msg:         "njs module loaded with a quoted path",
+		},
+		{
+			readFile:    readFileError,
+			expected:    false,
+			expectError: true,
+			msg:         "cannot read file",
+		},
+	}
+
+	for _, test := range tests {
+		result, err := isNJSAvailable(test.readFile)
+
+		if result != test.expected {
+			t.Errorf("isNJSAvailable() returned %v but expected %v for case %q", result, test.expected, test.msg)
+		}
+
+		if test.expectError {
+			if err == nil {
+				t.Errorf("isNJSAvailable() didn't return error for case %q", test.msg)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("isNJSAvailable() returned unexpected error %v for case %q", err, test.msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package state
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . BackendResolver
+
+// BackendResolver resolves the address of a backend resource, specified by its namespace and name, for the port.
+// For example, a resolver of ServiceImports for multi-cluster Services returns the IP of a ServiceImport.
+// A backend that is reachable through a Unix domain socket, for example, a sidecar, is resolved to the path of
+// the socket with the prefix unix:, like unix:/var/run/app.sock. The port is ignored for such a backend.
+type BackendResolver interface {
+	// Resolve returns the address of the backend. If the backend cannot be resolved or ctx is done before
+	// the backend is resolved, it returns an error.
+	Resolve(ctx context.Context, nsname types.NamespacedName, port int32) (string, error)
+}
+
+// BackendKind is the group and kind of the resources that the backendRefs of the HTTPRoutes reference.
+type BackendKind struct {
+	// Group is the API group of the resource. Empty means the core group.
+	Group string
+	// Kind is the kind of the resource.
+	Kind string
+}
+
+// ServiceBackendKind is the kind of the Services of the core group, which the backendRefs reference by default.
+// The Services are always supported and resolved by the ServiceStore.
+var ServiceBackendKind = BackendKind{Kind: "Service"}
+
+// GetBackendKind returns the kind of the resource that the backendRef references, with the defaults of the group
+// and kind applied.
+func GetBackendKind(ref v1beta1.BackendObjectReference) BackendKind {
+	kind := ServiceBackendKind
+
+	if ref.Group != nil {
+		kind.Group = string(*ref.Group)
+	}
+	if ref.Kind != nil {
+		kind.Kind = string(*ref.Kind)
+	}
+
+	return kind
+}
+
+// DefaultResolveTimeout is the default time limit of the resolution of the backends of an HTTPRoute or a server.
+const DefaultResolveTimeout = 5 * time.Second
+
+// BackendKey identifies a backend by its kind, namespace, name and port.
+type BackendKey struct {
+	Kind   BackendKind
+	NsName types.NamespacedName
+	Port   int32
+}
+
+// BackendResolution is the result of the resolution of a backend.
+type BackendResolution struct {
+	Address string
+	Err     error
+}
+
+// BackendResolutions memoizes the resolutions of the backends, so that every backend is resolved once while
+// the graph is built and the configuration is generated.
+type BackendResolutions map[BackendKey]BackendResolution
+
+// NewBackendResolutions creates BackendResolutions with the addresses of the resolved backends.
+func NewBackendResolutions(addresses map[BackendKey]string) BackendResolutions {
+	r := make(BackendResolutions, len(addresses))
+	for key, address := range addresses {
+		r[key] = BackendResolution{Address: address}
+	}
+
+	return r
+}
+
+// Resolve returns the address of the backend. A backend that is not memoized yet is resolved by the resolver with
+// ResolveBackend, and its resolution is memoized, including the failed one.
+func (r BackendResolutions) Resolve(ctx context.Context, resolver BackendResolver, key BackendKey) (string, error) {
+	res, resolved := r[key]
+	if !resolved {
+		res.Address, res.Err = ResolveBackend(ctx, resolver, key.NsName, key.Port)
+		r[key] = res
+	}
+
+	return res.Address, res.Err
+}
+
+// Addresses returns the addresses of the resolved backends. The failed resolutions are not included, so that they
+// are retried. It returns nil if no backend is resolved.
+func (r BackendResolutions) Addresses() map[BackendKey]string {
+	var addresses map[BackendKey]string
+
+	for key, res := range r {
+		if res.Err != nil {
+			continue
+		}
+
+		if addresses == nil {
+			addresses = make(map[BackendKey]string)
+		}
+		addresses[key] = res.Address
+	}
+
+	return addresses
+}
+
+// ResolveBackend resolves the address of the backend by the resolver. If ctx is done before the resolver returns,
+// ResolveBackend returns the error of ctx, even if the resolver blocks and doesn't respect ctx.
+func ResolveBackend(
+	ctx context.Context,
+	resolver BackendResolver,
+	nsname types.NamespacedName,
+	port int32,
+) (string, error) {
+	type result struct {
+		address string
+		err     error
+	}
+
+	// the channel is buffered, so that the goroutine can exit after ctx is done
+	results := make(chan result, 1)
+
+	go func() {
+		address, err := resolver.Resolve(ctx, nsname, port)
+		results <- result{address: address, err: err}
+	}()
+
+	select {
+	case r := <-results:
+		return r.address, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
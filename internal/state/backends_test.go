@@ -0,0 +1,137 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// backendResolverFunc is a BackendResolver that resolves the backends with the function.
+type backendResolverFunc func(ctx context.Context, nsname types.NamespacedName, port int32) (string, error)
+
+func (f backendResolverFunc) Resolve(ctx context.Context, nsname types.NamespacedName, port int32) (string, error) {
+	return f(ctx, nsname, port)
+}
+
+func TestResolveBackend(t *testing.T) {
+	nsname := types.NamespacedName{Namespace: "test", Name: "service"}
+
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	tests := []struct {
+		resolver        backendResolverFunc
+		timeout         time.Duration
+		expectedAddress string
+		expectedErr     error
+		msg             string
+	}{
+		{
+			resolver: func(context.Context, types.NamespacedName, int32) (string, error) {
+				return "10.0.0.1", nil
+			},
+			timeout:         time.Second,
+			expectedAddress: "10.0.0.1",
+			msg:             "resolved in time",
+		},
+		{
+			resolver: func(ctx context.Context, _ types.NamespacedName, _ int32) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+			timeout:     10 * time.Millisecond,
+			expectedErr: context.DeadlineExceeded,
+			msg:         "resolver respects the deadline",
+		},
+		{
+			resolver: func(context.Context, types.NamespacedName, int32) (string, error) {
+				<-unblock
+				return "10.0.0.1", nil
+			},
+			timeout:     10 * time.Millisecond,
+			expectedErr: context.DeadlineExceeded,
+			msg:         "resolver ignores the deadline",
+		},
+	}
+
+	for _, test := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+
+		address, err := ResolveBackend(ctx, test.resolver, nsname, 80)
+
+		cancel()
+
+		if address != test.expectedAddress {
+			t.Errorf("ResolveBackend() returned %q but expected %q for the case of %q",
+				address, test.expectedAddress, test.msg)
+		}
+		if !errors.Is(err, test.expectedErr) {
+			t.Errorf("ResolveBackend() returned error %v but expected %v for the case of %q",
+				err, test.expectedErr, test.msg)
+		}
+	}
+}
+
+func TestBackendResolutions(t *testing.T) {
+	resolvedKey := BackendKey{
+		Kind:   ServiceBackendKind,
+		NsName: types.NamespacedName{Namespace: "test", Name: "service1"},
+		Port:   80,
+	}
+	failedKey := BackendKey{
+		Kind:   ServiceBackendKind,
+		NsName: types.NamespacedName{Namespace: "test", Name: "service2"},
+		Port:   80,
+	}
+
+	calls := 0
+	var resolver backendResolverFunc = func(_ context.Context, nsname types.NamespacedName, _ int32) (string, error) {
+		calls++
+		if nsname == failedKey.NsName {
+			return "", errors.New("service test/service2 doesn't exist")
+		}
+		return "10.0.0.1", nil
+	}
+
+	resolutions := make(BackendResolutions)
+
+	for i := 0; i < 2; i++ {
+		if address, err := resolutions.Resolve(context.Background(), resolver, resolvedKey); address != "10.0.0.1" ||
+			err != nil {
+			t.Errorf("Resolve() returned %q, %v but expected 10.0.0.1", address, err)
+		}
+		if _, err := resolutions.Resolve(context.Background(), resolver, failedKey); err == nil {
+			t.Errorf("Resolve() didn't return an error for the backend that doesn't exist")
+		}
+	}
+
+	// every backend is only resolved once, including the failed ones
+	if calls != 2 {
+		t.Errorf("Resolve() called the resolver %d times but expected 2", calls)
+	}
+
+	// the failed resolutions are not included in the addresses, so that they are retried
+	expectedAddresses := map[BackendKey]string{resolvedKey: "10.0.0.1"}
+	if diff := cmp.Diff(expectedAddresses, resolutions.Addresses()); diff != "" {
+		t.Errorf("Addresses() mismatch (-want +got):\n%s", diff)
+	}
+
+	if addresses := make(BackendResolutions).Addresses(); addresses != nil {
+		t.Errorf("Addresses() returned %v but expected nil without resolved backends", addresses)
+	}
+
+	// the backends with the addresses are not resolved again
+	calls = 0
+	resolutions = NewBackendResolutions(expectedAddresses)
+	if address, err := resolutions.Resolve(context.Background(), resolver, resolvedKey); address != "10.0.0.1" ||
+		err != nil {
+		t.Errorf("Resolve() returned %q, %v but expected 10.0.0.1", address, err)
+	}
+	if calls != 0 {
+		t.Errorf("Resolve() called the resolver %d times for a backend with an address", calls)
+	}
+}
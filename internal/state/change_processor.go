@@ -1,32 +1,36 @@
 package state
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
 
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . ChangeProcessor
 
 // ChangeProcessor processes the changes to resources producing the internal representation of the Gateway configuration.
-// ChangeProcessor only supports one GatewayClass resource.
+// ChangeProcessor only supports one GatewayClass resource. The other GatewayClass resources that reference the Gateway
+// controller are ignored and reported as conflicting.
 type ChangeProcessor interface {
 	// CaptureUpsertChange captures an upsert change to a resource.
-	// It panics if the resource is of unsupported type or if the passed Gateway is different from the one this ChangeProcessor
-	// was created for.
+	// It panics if the resource is of unsupported type.
 	CaptureUpsertChange(obj client.Object)
 	// CaptureDeleteChange captures a delete change to a resource.
-	// The method panics if the resource is of unsupported type or if the passed Gateway is different from the one this ChangeProcessor
-	// was created for.
+	// The method panics if the resource is of unsupported type.
 	CaptureDeleteChange(resourceType client.Object, nsname types.NamespacedName)
 	// Process processes any captured changes and produces an internal representation of the Gateway configuration and
 	// the status information about the processed resources.
 	// If no changes were captured, the changed return argument will be false and both the configuration and statuses
 	// will be empty.
-	Process() (changed bool, conf Configuration, statuses Statuses)
+	// ctx bounds the resolution of the backends of the routes.
+	Process(ctx context.Context) (changed bool, conf Configuration, statuses Statuses)
 }
 
 // ChangeProcessorConfig holds configuration parameters for ChangeProcessorImpl.
@@ -37,6 +41,18 @@ type ChangeProcessorConfig struct {
 	GatewayClassName string
 	// SecretMemoryManager is the secret memory manager.
 	SecretMemoryManager SecretDiskMemoryManager
+	// ServiceStore is the service store used to validate the backendRefs of the routes.
+	ServiceStore ServiceStore
+	// BackendResolvers are the resolvers of the supported backend kinds other than Service, used to validate
+	// the backendRefs of the routes. The backendRefs of other kinds are invalid.
+	// The resolvers must be the same as the ones of the Generator (see config.WithBackendResolvers).
+	BackendResolvers map[BackendKind]BackendResolver
+	// ResolveTimeout is the time limit of the resolution of the backendRefs of an HTTPRoute. The backends that are
+	// not resolved in time are unresolved. Zero means DefaultResolveTimeout.
+	ResolveTimeout time.Duration
+	// AllowedAddresses are the IP addresses NGINX can listen on, of the IP family NGINX accepts.
+	// The addresses of the Gateway that are not among them are rejected and reported in the status of the Gateway.
+	AllowedAddresses []string
 }
 
 // ChangeProcessorImpl is an implementation of ChangeProcessor.
@@ -55,6 +71,10 @@ type ChangeProcessorImpl struct {
 
 // NewChangeProcessorImpl creates a new ChangeProcessorImpl for the Gateway resource with the configured namespace name.
 func NewChangeProcessorImpl(cfg ChangeProcessorConfig) *ChangeProcessorImpl {
+	if cfg.ResolveTimeout == 0 {
+		cfg.ResolveTimeout = DefaultResolveTimeout
+	}
+
 	return &ChangeProcessorImpl{
 		store: newStore(),
 		cfg:   cfg,
@@ -76,7 +96,13 @@ func (c *ChangeProcessorImpl) CaptureUpsertChange(obj client.Object) {
 	switch o := obj.(type) {
 	case *v1beta1.GatewayClass:
 		if o.Name != c.cfg.GatewayClassName {
-			panic(fmt.Errorf("gatewayclass resource must be %s, got %s", c.cfg.GatewayClassName, o.Name))
+			// if the resource spec hasn't changed (its generation is the same), ignore the upsert
+			prev, exist := c.store.ignoredGatewayClasses[o.Name]
+			if exist && o.Generation == prev.Generation {
+				resourceChanged = false
+			}
+			c.store.ignoredGatewayClasses[o.Name] = o
+			break
 		}
 		// if the resource spec hasn't changed (its generation is the same), ignore the upsert
 		if c.store.gc != nil && c.store.gc.Generation == o.Generation {
@@ -97,6 +123,17 @@ func (c *ChangeProcessorImpl) CaptureUpsertChange(obj client.Object) {
 			resourceChanged = false
 		}
 		c.store.httpRoutes[getNamespacedName(obj)] = o
+	case *v1alpha2.TCPRoute:
+		// if the resource spec hasn't changed (its generation is the same), ignore the upsert
+		prev, exist := c.store.tcpRoutes[getNamespacedName(obj)]
+		if exist && o.Generation == prev.Generation {
+			resourceChanged = false
+		}
+		c.store.tcpRoutes[getNamespacedName(obj)] = o
+	case *apiv1.Secret:
+		// the Secret is stored in the SecretStore, but its contents are validated when the listeners are built,
+		// and NGINX has to reload to read the rotated certificate and key.
+		resourceChanged = c.store.referencesSecret(getNamespacedName(obj))
 	default:
 		panic(fmt.Errorf("ChangeProcessor doesn't support %T", obj))
 	}
@@ -108,24 +145,33 @@ func (c *ChangeProcessorImpl) CaptureDeleteChange(resourceType client.Object, ns
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	c.storeChanged = true
+	resourceChanged := true
 
 	switch resourceType.(type) {
 	case *v1beta1.GatewayClass:
 		if nsname.Name != c.cfg.GatewayClassName {
-			panic(fmt.Errorf("gatewayclass resource must be %s, got %s", c.cfg.GatewayClassName, nsname.Name))
+			// the deleted resource might not reference the Gateway controller, so it might not be in the store
+			_, resourceChanged = c.store.ignoredGatewayClasses[nsname.Name]
+			delete(c.store.ignoredGatewayClasses, nsname.Name)
+			break
 		}
 		c.store.gc = nil
 	case *v1beta1.Gateway:
 		delete(c.store.gateways, nsname)
 	case *v1beta1.HTTPRoute:
 		delete(c.store.httpRoutes, nsname)
+	case *v1alpha2.TCPRoute:
+		delete(c.store.tcpRoutes, nsname)
+	case *apiv1.Secret:
+		resourceChanged = c.store.referencesSecret(nsname)
 	default:
 		panic(fmt.Errorf("ChangeProcessor doesn't support %T", resourceType))
 	}
+
+	c.storeChanged = c.storeChanged || resourceChanged
 }
 
-func (c *ChangeProcessorImpl) Process() (changed bool, conf Configuration, statuses Statuses) {
+func (c *ChangeProcessorImpl) Process(ctx context.Context) (changed bool, conf Configuration, statuses Statuses) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
@@ -136,10 +182,15 @@ func (c *ChangeProcessorImpl) Process() (changed bool, conf Configuration, statu
 	c.storeChanged = false
 
 	graph := buildGraph(
+		ctx,
 		c.store,
 		c.cfg.GatewayCtlrName,
 		c.cfg.GatewayClassName,
 		c.cfg.SecretMemoryManager,
+		c.cfg.ServiceStore,
+		c.cfg.BackendResolvers,
+		c.cfg.ResolveTimeout,
+		c.cfg.AllowedAddresses,
 	)
 
 	conf = buildConfiguration(graph)
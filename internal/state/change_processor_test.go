@@ -1,8 +1,11 @@
 package state_test
 
 import (
+	"context"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -21,6 +24,7 @@ var _ = Describe("ChangeProcessor", func() {
 			controllerName  = "my.controller"
 			gcName          = "test-class"
 			certificatePath = "path/to/cert"
+			keyPath         = "path/to/key"
 		)
 
 		var (
@@ -141,15 +145,16 @@ var _ = Describe("ChangeProcessor", func() {
 				GatewayCtlrName:     controllerName,
 				GatewayClassName:    gcName,
 				SecretMemoryManager: fakeSecretMemoryMgr,
+				ServiceStore:        &statefakes.FakeServiceStore{},
 			})
 
-			fakeSecretMemoryMgr.RequestReturns(certificatePath, nil)
+			fakeSecretMemoryMgr.RequestReturns(state.SecretPaths{Certificate: certificatePath, Key: keyPath}, nil)
 		})
 
 		Describe("Process resources", Ordered, func() {
 			When("no upsert has occurred", func() {
 				It("should return empty configuration and statuses", func() {
-					changed, conf, statuses := processor.Process()
+					changed, conf, statuses := processor.Process(context.Background())
 					Expect(changed).To(BeFalse())
 					Expect(conf).To(BeZero())
 					Expect(statuses).To(BeZero())
@@ -162,11 +167,13 @@ var _ = Describe("ChangeProcessor", func() {
 
 						expectedConf := state.Configuration{}
 						expectedStatuses := state.Statuses{
-							IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{},
-							HTTPRouteStatuses:      map[types.NamespacedName]state.HTTPRouteStatus{},
+							IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
+							IgnoredGatewayStatuses:      map[types.NamespacedName]state.IgnoredGatewayStatus{},
+							TCPRouteStatuses:            map[types.NamespacedName]state.TCPRouteStatus{},
+							HTTPRouteStatuses:           map[types.NamespacedName]state.HTTPRouteStatus{},
 						}
 
-						changed, conf, statuses := processor.Process()
+						changed, conf, statuses := processor.Process(context.Background())
 						Expect(changed).To(BeTrue())
 						Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 						Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
@@ -183,15 +190,17 @@ var _ = Describe("ChangeProcessor", func() {
 							ListenerStatuses: map[string]state.ListenerStatus{
 								"listener-80-1": {
 									Valid:          false,
-									AttachedRoutes: 1,
+									AttachedRoutes: 0,
 								},
 								"listener-443-1": {
 									Valid:          false,
-									AttachedRoutes: 1,
+									AttachedRoutes: 0,
 								},
 							},
 						},
-						IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{},
+						IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
+						IgnoredGatewayStatuses:      map[types.NamespacedName]state.IgnoredGatewayStatus{},
+						TCPRouteStatuses:            map[types.NamespacedName]state.TCPRouteStatus{},
 						HTTPRouteStatuses: map[types.NamespacedName]state.HTTPRouteStatus{
 							{Namespace: "test", Name: "hr-1"}: {
 								ParentStatuses: map[string]state.ParentStatus{
@@ -202,7 +211,7 @@ var _ = Describe("ChangeProcessor", func() {
 						},
 					}
 
-					changed, conf, statuses := processor.Process()
+					changed, conf, statuses := processor.Process(context.Background())
 					Expect(changed).To(BeTrue())
 					Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 					Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
@@ -233,7 +242,7 @@ var _ = Describe("ChangeProcessor", func() {
 					SSLServers: []state.VirtualServer{
 						{
 							Hostname: "foo.example.com",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 							PathRules: []state.PathRule{
 								{
 									Path: "/",
@@ -249,7 +258,7 @@ var _ = Describe("ChangeProcessor", func() {
 						},
 						{
 							Hostname: "~^",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 						},
 					},
 				}
@@ -272,7 +281,9 @@ var _ = Describe("ChangeProcessor", func() {
 							},
 						},
 					},
-					IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
+					IgnoredGatewayStatuses:      map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					TCPRouteStatuses:            map[types.NamespacedName]state.TCPRouteStatus{},
 					HTTPRouteStatuses: map[types.NamespacedName]state.HTTPRouteStatus{
 						{Namespace: "test", Name: "hr-1"}: {
 							ParentStatuses: map[string]state.ParentStatus{
@@ -283,7 +294,7 @@ var _ = Describe("ChangeProcessor", func() {
 					},
 				}
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 				Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 				Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
@@ -294,7 +305,7 @@ var _ = Describe("ChangeProcessor", func() {
 				// hr1UpdatedSameGen.Generation has not been changed
 				processor.CaptureUpsertChange(hr1UpdatedSameGen)
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeFalse())
 				Expect(conf).To(BeZero())
 				Expect(statuses).To(BeZero())
@@ -324,7 +335,7 @@ var _ = Describe("ChangeProcessor", func() {
 					SSLServers: []state.VirtualServer{
 						{
 							Hostname: "foo.example.com",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 							PathRules: []state.PathRule{
 								{
 									Path: "/",
@@ -340,7 +351,7 @@ var _ = Describe("ChangeProcessor", func() {
 						},
 						{
 							Hostname: "~^",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 						},
 					},
 				}
@@ -362,7 +373,9 @@ var _ = Describe("ChangeProcessor", func() {
 							},
 						},
 					},
-					IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
+					IgnoredGatewayStatuses:      map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					TCPRouteStatuses:            map[types.NamespacedName]state.TCPRouteStatus{},
 					HTTPRouteStatuses: map[types.NamespacedName]state.HTTPRouteStatus{
 						{Namespace: "test", Name: "hr-1"}: {
 							ParentStatuses: map[string]state.ParentStatus{
@@ -373,7 +386,7 @@ var _ = Describe("ChangeProcessor", func() {
 					},
 				}
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 				Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 				Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
@@ -384,7 +397,7 @@ var _ = Describe("ChangeProcessor", func() {
 				// gwUpdatedSameGen.Generation has not been changed
 				processor.CaptureUpsertChange(gwUpdatedSameGen)
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeFalse())
 				Expect(conf).To(BeZero())
 				Expect(statuses).To(BeZero())
@@ -414,7 +427,7 @@ var _ = Describe("ChangeProcessor", func() {
 					SSLServers: []state.VirtualServer{
 						{
 							Hostname: "foo.example.com",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 							PathRules: []state.PathRule{
 								{
 									Path: "/",
@@ -430,7 +443,7 @@ var _ = Describe("ChangeProcessor", func() {
 						},
 						{
 							Hostname: "~^",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 						},
 					},
 				}
@@ -452,7 +465,9 @@ var _ = Describe("ChangeProcessor", func() {
 							},
 						},
 					},
-					IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
+					IgnoredGatewayStatuses:      map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					TCPRouteStatuses:            map[types.NamespacedName]state.TCPRouteStatus{},
 					HTTPRouteStatuses: map[types.NamespacedName]state.HTTPRouteStatus{
 						{Namespace: "test", Name: "hr-1"}: {
 							ParentStatuses: map[string]state.ParentStatus{
@@ -463,7 +478,7 @@ var _ = Describe("ChangeProcessor", func() {
 					},
 				}
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 				Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 				Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
@@ -474,7 +489,7 @@ var _ = Describe("ChangeProcessor", func() {
 				// gcUpdatedSameGen.Generation has not been changed
 				processor.CaptureUpsertChange(gcUpdatedSameGen)
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeFalse())
 				Expect(conf).To(BeZero())
 				Expect(statuses).To(BeZero())
@@ -504,7 +519,7 @@ var _ = Describe("ChangeProcessor", func() {
 					SSLServers: []state.VirtualServer{
 						{
 							Hostname: "foo.example.com",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 							PathRules: []state.PathRule{
 								{
 									Path: "/",
@@ -520,7 +535,7 @@ var _ = Describe("ChangeProcessor", func() {
 						},
 						{
 							Hostname: "~^",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 						},
 					},
 				}
@@ -542,7 +557,9 @@ var _ = Describe("ChangeProcessor", func() {
 							},
 						},
 					},
-					IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
+					IgnoredGatewayStatuses:      map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					TCPRouteStatuses:            map[types.NamespacedName]state.TCPRouteStatus{},
 					HTTPRouteStatuses: map[types.NamespacedName]state.HTTPRouteStatus{
 						{Namespace: "test", Name: "hr-1"}: {
 							ParentStatuses: map[string]state.ParentStatus{
@@ -553,14 +570,14 @@ var _ = Describe("ChangeProcessor", func() {
 					},
 				}
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 				Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 				Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
 			})
 
 			It("should return empty configuration and statuses after processing without capturing any changes", func() {
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 
 				Expect(changed).To(BeFalse())
 				Expect(conf).To(BeZero())
@@ -605,11 +622,12 @@ var _ = Describe("ChangeProcessor", func() {
 							},
 							SSL: &state.SSL{
 								CertificatePath: certificatePath,
+								KeyPath:         keyPath,
 							},
 						},
 						{
 							Hostname: "~^",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 						},
 					},
 				}
@@ -631,11 +649,13 @@ var _ = Describe("ChangeProcessor", func() {
 							},
 						},
 					},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
 					IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{
 						{Namespace: "test", Name: "gateway-2"}: {
 							ObservedGeneration: gw2.Generation,
 						},
 					},
+					TCPRouteStatuses: map[types.NamespacedName]state.TCPRouteStatus{},
 					HTTPRouteStatuses: map[types.NamespacedName]state.HTTPRouteStatus{
 						{Namespace: "test", Name: "hr-1"}: {
 							ParentStatuses: map[string]state.ParentStatus{
@@ -646,7 +666,7 @@ var _ = Describe("ChangeProcessor", func() {
 					},
 				}
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 				Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 				Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
@@ -676,7 +696,7 @@ var _ = Describe("ChangeProcessor", func() {
 					SSLServers: []state.VirtualServer{
 						{
 							Hostname: "foo.example.com",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 							PathRules: []state.PathRule{
 								{
 									Path: "/",
@@ -692,7 +712,7 @@ var _ = Describe("ChangeProcessor", func() {
 						},
 						{
 							Hostname: "~^",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 						},
 					},
 				}
@@ -714,11 +734,13 @@ var _ = Describe("ChangeProcessor", func() {
 							},
 						},
 					},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
 					IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{
 						{Namespace: "test", Name: "gateway-2"}: {
 							ObservedGeneration: gw2.Generation,
 						},
 					},
+					TCPRouteStatuses: map[types.NamespacedName]state.TCPRouteStatus{},
 					HTTPRouteStatuses: map[types.NamespacedName]state.HTTPRouteStatus{
 						{Namespace: "test", Name: "hr-1"}: {
 							ParentStatuses: map[string]state.ParentStatus{
@@ -728,14 +750,14 @@ var _ = Describe("ChangeProcessor", func() {
 						},
 						{Namespace: "test", Name: "hr-2"}: {
 							ParentStatuses: map[string]state.ParentStatus{
-								"listener-80-1":  {Attached: false},
-								"listener-443-1": {Attached: false},
+								"listener-80-1":  {Attached: false, Reason: state.ParentRefReasonGatewayConflict},
+								"listener-443-1": {Attached: false, Reason: state.ParentRefReasonGatewayConflict},
 							},
 						},
 					},
 				}
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 				Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 				Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
@@ -765,7 +787,7 @@ var _ = Describe("ChangeProcessor", func() {
 					SSLServers: []state.VirtualServer{
 						{
 							Hostname: "bar.example.com",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 							PathRules: []state.PathRule{
 								{
 									Path: "/",
@@ -781,7 +803,7 @@ var _ = Describe("ChangeProcessor", func() {
 						},
 						{
 							Hostname: "~^",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 						},
 					},
 				}
@@ -803,7 +825,9 @@ var _ = Describe("ChangeProcessor", func() {
 							},
 						},
 					},
-					IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
+					IgnoredGatewayStatuses:      map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					TCPRouteStatuses:            map[types.NamespacedName]state.TCPRouteStatus{},
 					HTTPRouteStatuses: map[types.NamespacedName]state.HTTPRouteStatus{
 						{Namespace: "test", Name: "hr-2"}: {
 							ParentStatuses: map[string]state.ParentStatus{
@@ -814,7 +838,7 @@ var _ = Describe("ChangeProcessor", func() {
 					},
 				}
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 				Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 				Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
@@ -828,7 +852,7 @@ var _ = Describe("ChangeProcessor", func() {
 					SSLServers: []state.VirtualServer{
 						{
 							Hostname: "~^",
-							SSL:      &state.SSL{CertificatePath: certificatePath},
+							SSL:      &state.SSL{CertificatePath: certificatePath, KeyPath: keyPath},
 						},
 					},
 				}
@@ -850,11 +874,13 @@ var _ = Describe("ChangeProcessor", func() {
 							},
 						},
 					},
-					IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{},
-					HTTPRouteStatuses:      map[types.NamespacedName]state.HTTPRouteStatus{},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
+					IgnoredGatewayStatuses:      map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					TCPRouteStatuses:            map[types.NamespacedName]state.TCPRouteStatus{},
+					HTTPRouteStatuses:           map[types.NamespacedName]state.HTTPRouteStatus{},
 				}
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 				Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 				Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
@@ -878,11 +904,13 @@ var _ = Describe("ChangeProcessor", func() {
 							},
 						},
 					},
-					IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{},
-					HTTPRouteStatuses:      map[types.NamespacedName]state.HTTPRouteStatus{},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
+					IgnoredGatewayStatuses:      map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					TCPRouteStatuses:            map[types.NamespacedName]state.TCPRouteStatus{},
+					HTTPRouteStatuses:           map[types.NamespacedName]state.HTTPRouteStatus{},
 				}
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 				Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 				Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
@@ -893,11 +921,13 @@ var _ = Describe("ChangeProcessor", func() {
 
 				expectedConf := state.Configuration{}
 				expectedStatuses := state.Statuses{
-					IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{},
-					HTTPRouteStatuses:      map[types.NamespacedName]state.HTTPRouteStatus{},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
+					IgnoredGatewayStatuses:      map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					TCPRouteStatuses:            map[types.NamespacedName]state.TCPRouteStatus{},
+					HTTPRouteStatuses:           map[types.NamespacedName]state.HTTPRouteStatus{},
 				}
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 				Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 				Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
@@ -908,16 +938,67 @@ var _ = Describe("ChangeProcessor", func() {
 
 				expectedConf := state.Configuration{}
 				expectedStatuses := state.Statuses{
-					IgnoredGatewayStatuses: map[types.NamespacedName]state.IgnoredGatewayStatus{},
-					HTTPRouteStatuses:      map[types.NamespacedName]state.HTTPRouteStatus{},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{},
+					IgnoredGatewayStatuses:      map[types.NamespacedName]state.IgnoredGatewayStatus{},
+					TCPRouteStatuses:            map[types.NamespacedName]state.TCPRouteStatus{},
+					HTTPRouteStatuses:           map[types.NamespacedName]state.HTTPRouteStatus{},
 				}
 
-				changed, conf, statuses := processor.Process()
+				changed, conf, statuses := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 				Expect(helpers.Diff(expectedConf, conf)).To(BeEmpty())
 				Expect(helpers.Diff(expectedStatuses, statuses)).To(BeEmpty())
 			})
 		})
+
+		Describe("Deleting one of the HTTPRoutes attached to the same listeners", Ordered, func() {
+			hr3NsName := types.NamespacedName{Namespace: "test", Name: "hr-3"}
+
+			It("should count both HTTPRoutes after they are upserted", func() {
+				// hr-3 is attached to the listeners of gateway-1 along with hr-1
+				hr3 := hr2.DeepCopy()
+				hr3.Name = hr3NsName.Name
+				for i := range hr3.Spec.ParentRefs {
+					hr3.Spec.ParentRefs[i].Name = "gateway-1"
+				}
+
+				processor.CaptureUpsertChange(gc)
+				processor.CaptureUpsertChange(gw1)
+				processor.CaptureUpsertChange(hr1)
+				processor.CaptureUpsertChange(hr3)
+
+				changed, conf, statuses := processor.Process(context.Background())
+				Expect(changed).To(BeTrue())
+
+				Expect(conf.HTTPServers).To(HaveLen(2))
+
+				Expect(statuses.GatewayStatus).ToNot(BeNil())
+				for _, name := range []string{"listener-80-1", "listener-443-1"} {
+					Expect(statuses.GatewayStatus.ListenerStatuses[name].AttachedRoutes).To(Equal(int32(2)))
+				}
+
+				Expect(statuses.HTTPRouteStatuses).To(HaveKey(hr3NsName))
+				Expect(statuses.HTTPRouteStatuses).To(HaveKey(types.NamespacedName{Namespace: "test", Name: "hr-1"}))
+			})
+
+			It("should decrement the count and remove the status of the HTTPRoute after it is deleted", func() {
+				processor.CaptureDeleteChange(&v1beta1.HTTPRoute{}, hr3NsName)
+
+				changed, conf, statuses := processor.Process(context.Background())
+				Expect(changed).To(BeTrue())
+
+				Expect(conf.HTTPServers).To(HaveLen(1))
+				Expect(conf.HTTPServers[0].Hostname).To(Equal("foo.example.com"))
+
+				Expect(statuses.GatewayStatus).ToNot(BeNil())
+				for _, name := range []string{"listener-80-1", "listener-443-1"} {
+					Expect(statuses.GatewayStatus.ListenerStatuses[name].AttachedRoutes).To(Equal(int32(1)))
+				}
+
+				Expect(statuses.HTTPRouteStatuses).ToNot(HaveKey(hr3NsName))
+				Expect(statuses.HTTPRouteStatuses).To(HaveKey(types.NamespacedName{Namespace: "test", Name: "hr-1"}))
+			})
+		})
 	})
 
 	Describe("Multiple captured changes", func() {
@@ -935,6 +1016,7 @@ var _ = Describe("ChangeProcessor", func() {
 				GatewayCtlrName:     "test.controller",
 				GatewayClassName:    "my-class",
 				SecretMemoryManager: fakeSecretMemoryMgr,
+				ServiceStore:        &statefakes.FakeServiceStore{},
 			})
 
 			gcNsName = types.NamespacedName{Name: "my-class"}
@@ -983,7 +1065,7 @@ var _ = Describe("ChangeProcessor", func() {
 		})
 
 		Describe("Ensuring non-changing changes don't override previously changing changes", Ordered, func() {
-			// Changing change - a change that makes processor.Process() report changed
+			// Changing change - a change that makes processor.Process(context.Background()) report changed
 			// Non-changing change - a change that doesn't do that
 
 			// Note: in this test, we deliberately don't fully inspect the returned configuration and statuses
@@ -994,7 +1076,7 @@ var _ = Describe("ChangeProcessor", func() {
 				processor.CaptureUpsertChange(gw1)
 				processor.CaptureUpsertChange(hr1)
 
-				changed, _, _ := processor.Process()
+				changed, _, _ := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 			})
 
@@ -1003,7 +1085,7 @@ var _ = Describe("ChangeProcessor", func() {
 				processor.CaptureUpsertChange(gw1)
 				processor.CaptureUpsertChange(hr1)
 
-				changed, _, _ := processor.Process()
+				changed, _, _ := processor.Process(context.Background())
 				Expect(changed).To(BeFalse())
 			})
 
@@ -1018,7 +1100,7 @@ var _ = Describe("ChangeProcessor", func() {
 				processor.CaptureUpsertChange(gw1Updated)
 				processor.CaptureUpsertChange(hr1Updated)
 
-				changed, _, _ := processor.Process()
+				changed, _, _ := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 			})
 
@@ -1027,7 +1109,7 @@ var _ = Describe("ChangeProcessor", func() {
 				processor.CaptureUpsertChange(gw2)
 				processor.CaptureUpsertChange(hr2)
 
-				changed, _, _ := processor.Process()
+				changed, _, _ := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 			})
 
@@ -1041,10 +1123,148 @@ var _ = Describe("ChangeProcessor", func() {
 				processor.CaptureUpsertChange(gw2)
 				processor.CaptureUpsertChange(hr2)
 
-				changed, _, _ := processor.Process()
+				changed, _, _ := processor.Process(context.Background())
 				Expect(changed).To(BeTrue())
 			})
 		})
+
+		Describe("Processing the conflicting GatewayClasses", Ordered, func() {
+			var conflictingGc, unrelatedGc *v1beta1.GatewayClass
+
+			BeforeAll(func() {
+				conflictingGc = &v1beta1.GatewayClass{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "conflicting-class",
+						Generation: 2,
+					},
+					Spec: v1beta1.GatewayClassSpec{
+						ControllerName: "test.controller",
+					},
+				}
+
+				unrelatedGc = &v1beta1.GatewayClass{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "unrelated-class",
+					},
+					Spec: v1beta1.GatewayClassSpec{
+						ControllerName: "other.controller",
+					},
+				}
+			})
+
+			It("should report the conflicting GatewayClass after it is upserted", func() {
+				processor.CaptureUpsertChange(gc)
+				processor.CaptureUpsertChange(conflictingGc)
+				processor.CaptureUpsertChange(unrelatedGc)
+
+				changed, _, statuses := processor.Process(context.Background())
+				Expect(changed).To(BeTrue())
+				Expect(statuses.IgnoredGatewayClassStatuses).To(Equal(state.IgnoredGatewayClassStatuses{
+					"conflicting-class": {ObservedGeneration: 2},
+				}))
+			})
+
+			It("should report not changed after upserting the conflicting GatewayClass with same generation", func() {
+				processor.CaptureUpsertChange(conflictingGc)
+
+				changed, _, _ := processor.Process(context.Background())
+				Expect(changed).To(BeFalse())
+			})
+
+			It("should report not changed after deleting a GatewayClass that is not in the store", func() {
+				processor.CaptureDeleteChange(&v1beta1.GatewayClass{}, types.NamespacedName{Name: "unknown-class"})
+
+				changed, _, _ := processor.Process(context.Background())
+				Expect(changed).To(BeFalse())
+			})
+
+			It("should not report the conflicting GatewayClass after it is deleted", func() {
+				processor.CaptureDeleteChange(&v1beta1.GatewayClass{}, types.NamespacedName{Name: "conflicting-class"})
+
+				changed, _, statuses := processor.Process(context.Background())
+				Expect(changed).To(BeTrue())
+				Expect(statuses.IgnoredGatewayClassStatuses).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Processing the Secrets", Ordered, func() {
+		var (
+			processor *state.ChangeProcessorImpl
+			gw        *v1beta1.Gateway
+			secret    *apiv1.Secret
+		)
+
+		BeforeAll(func() {
+			processor = state.NewChangeProcessorImpl(state.ChangeProcessorConfig{
+				GatewayCtlrName:     "test.controller",
+				GatewayClassName:    "my-class",
+				SecretMemoryManager: &statefakes.FakeSecretDiskMemoryManager{},
+				ServiceStore:        &statefakes.FakeServiceStore{},
+			})
+
+			gw = &v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "gateway",
+				},
+				Spec: v1beta1.GatewaySpec{
+					GatewayClassName: "my-class",
+					Listeners: []v1beta1.Listener{
+						{
+							Name:     "listener-443",
+							Port:     443,
+							Protocol: v1beta1.HTTPSProtocolType,
+							TLS: &v1beta1.GatewayTLSConfig{
+								CertificateRefs: []v1beta1.SecretObjectReference{
+									{
+										Name: "secret",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			secret = &apiv1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "secret",
+				},
+			}
+		})
+
+		It("should report changed after upserting the Gateway", func() {
+			processor.CaptureUpsertChange(gw)
+
+			changed, _, _ := processor.Process(context.Background())
+			Expect(changed).To(BeTrue())
+		})
+
+		It("should report not changed after upserting a Secret that is not referenced by the Gateway", func() {
+			unrelated := secret.DeepCopy()
+			unrelated.Namespace = "other"
+
+			processor.CaptureUpsertChange(unrelated)
+
+			changed, _, _ := processor.Process(context.Background())
+			Expect(changed).To(BeFalse())
+		})
+
+		It("should report changed after upserting the Secret referenced by the Gateway", func() {
+			processor.CaptureUpsertChange(secret)
+
+			changed, _, _ := processor.Process(context.Background())
+			Expect(changed).To(BeTrue())
+		})
+
+		It("should report changed after deleting the Secret referenced by the Gateway", func() {
+			processor.CaptureDeleteChange(&apiv1.Secret{}, types.NamespacedName{Namespace: "test", Name: "secret"})
+
+			changed, _, _ := processor.Process(context.Background())
+			Expect(changed).To(BeTrue())
+		})
 	})
 
 	Describe("Edge cases with panic", func() {
@@ -1058,6 +1278,7 @@ var _ = Describe("ChangeProcessor", func() {
 				GatewayCtlrName:     "test.controller",
 				GatewayClassName:    "my-class",
 				SecretMemoryManager: fakeSecretMemoryMgr,
+				ServiceStore:        &statefakes.FakeServiceStore{},
 			})
 		})
 
@@ -1068,8 +1289,7 @@ var _ = Describe("ChangeProcessor", func() {
 				}
 				Expect(process).Should(Panic())
 			},
-			Entry("an unsupported resource", &v1alpha2.TCPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "tcp"}}),
-			Entry("a wrong gatewayclass", &v1beta1.GatewayClass{ObjectMeta: metav1.ObjectMeta{Name: "wrong-class"}}))
+			Entry("an unsupported resource", &v1alpha2.UDPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "udp"}}))
 
 		DescribeTable("CaptureDeleteChange must panic",
 			func(resourceType client.Object, nsname types.NamespacedName) {
@@ -1078,7 +1298,6 @@ var _ = Describe("ChangeProcessor", func() {
 				}
 				Expect(process).Should(Panic())
 			},
-			Entry("an unsupported resource", &v1alpha2.TCPRoute{}, types.NamespacedName{Namespace: "test", Name: "tcp"}),
-			Entry("a wrong gatewayclass", &v1beta1.GatewayClass{}, types.NamespacedName{Name: "wrong-class"}))
+			Entry("an unsupported resource", &v1alpha2.UDPRoute{}, types.NamespacedName{Namespace: "test", Name: "udp"}))
 	})
 })
@@ -3,12 +3,31 @@ package state
 import (
 	"fmt"
 	"sort"
+	"strings"
 
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 const wildcardHostname = "~^"
 
+const (
+	// wwwRedirectAnnotation enables the normalization of the www hostnames of the servers of a Gateway using 301
+	// redirects. The value is wwwRedirectStrip or wwwRedirectAdd. The other values are ignored.
+	wwwRedirectAnnotation = "gateway.nginx.org/www-redirect"
+	// wwwRedirectStrip redirects the requests for www.{hostname} to {hostname}, for every server with a non-www
+	// hostname.
+	wwwRedirectStrip = "strip"
+	// wwwRedirectAdd redirects the requests for {hostname} to www.{hostname}, for every server with a www hostname.
+	wwwRedirectAdd = "add"
+	// wwwPrefix is the prefix of the www hostnames.
+	wwwPrefix = "www."
+	// httpsRedirectAnnotation enables the 301 redirects of the requests of the HTTP servers of a Gateway to HTTPS,
+	// for every HTTP server with the same hostname as an SSL server. The value is "true". The other values are
+	// ignored.
+	httpsRedirectAnnotation = "gateway.nginx.org/https-redirect"
+)
+
 // Configuration is an internal representation of Gateway configuration.
 // We can think of Configuration as an intermediate state between the Gateway API resources and the data plane (NGINX)
 // configuration.
@@ -19,6 +38,27 @@ type Configuration struct {
 	// SSLServers holds all SSLServers.
 	// FIXME(kate-osborn) We assume that all SSL servers listen on port 443.
 	SSLServers []VirtualServer
+	// TCPServers holds all TCPServers.
+	TCPServers []TCPServer
+	// HTTPRedirects holds the www normalization redirects of the HTTPServers.
+	HTTPRedirects []WWWRedirect
+	// SSLRedirects holds the www normalization redirects of the SSLServers.
+	SSLRedirects []WWWRedirect
+	// ListenAddresses are the valid IP addresses requested in the addresses of the Gateway, which the HTTP and SSL
+	// servers listen on. If empty, the servers listen on all interfaces.
+	ListenAddresses []string
+	// BackendAddresses holds the addresses of the backends of the HTTPRoutes resolved while the configuration was
+	// built, so that they are not resolved again when NGINX configuration is generated. The backends that failed
+	// to resolve are not included.
+	BackendAddresses map[BackendKey]string
+}
+
+// TCPServer is a stream server that proxies a TCP port to a backend.
+type TCPServer struct {
+	// Port is the port the server listens on.
+	Port int32
+	// Source is the TCPRoute attached to the listener of the server.
+	Source *v1alpha2.TCPRoute
 }
 
 // VirtualServer is a virtual server.
@@ -29,11 +69,26 @@ type VirtualServer struct {
 	PathRules []PathRule
 	// SSL holds the SSL configuration options fo the server.
 	SSL *SSL
+	// HTTPSRedirect redirects the requests to the SSL server with the same hostname. It is only set for
+	// the HTTPServers.
+	HTTPSRedirect bool
+}
+
+// WWWRedirect is a redirect of the requests for a hostname to its www or non-www counterpart.
+type WWWRedirect struct {
+	// Hostname is the hostname of the requests that are redirected.
+	Hostname string
+	// Target is the hostname the requests are redirected to.
+	Target string
+	// SSL holds the SSL configuration options of the redirect. It is nil for the HTTPRedirects.
+	SSL *SSL
 }
 
 type SSL struct {
 	// CertificatePath is the path to the certificate file.
 	CertificatePath string
+	// KeyPath is the path to the file with the key of the certificate.
+	KeyPath string
 }
 
 // PathRule represents routing rules that share a common path.
@@ -81,18 +136,131 @@ func buildConfiguration(graph *graph) Configuration {
 		}
 	}
 
-	return configBuilder.build()
+	conf := configBuilder.build()
+	conf.ListenAddresses = graph.Gateway.ListenAddresses
+	conf.BackendAddresses = graph.BackendAddresses
+
+	if graph.Gateway.Source.Annotations[httpsRedirectAnnotation] == "true" {
+		setHTTPSRedirects(conf.HTTPServers, conf.SSLServers)
+	}
+
+	mode := graph.Gateway.Source.Annotations[wwwRedirectAnnotation]
+	conf.HTTPRedirects = buildWWWRedirects(conf.HTTPServers, graph.Gateway.Listeners, v1beta1.HTTPProtocolType, mode)
+	conf.SSLRedirects = buildWWWRedirects(conf.SSLServers, graph.Gateway.Listeners, v1beta1.HTTPSProtocolType, mode)
+
+	return conf
+}
+
+// setHTTPSRedirects enables the HTTPS redirect of every HTTP server with the same hostname as an SSL server, so that
+// the HTTP requests are only redirected when an HTTPS listener accepts the hostname.
+func setHTTPSRedirects(httpServers []VirtualServer, sslServers []VirtualServer) {
+	sslHostnames := make(map[string]struct{}, len(sslServers))
+	for _, s := range sslServers {
+		sslHostnames[s.Hostname] = struct{}{}
+	}
+
+	for i := range httpServers {
+		if _, exist := sslHostnames[httpServers[i].Hostname]; exist {
+			httpServers[i].HTTPSRedirect = true
+		}
+	}
+}
+
+// buildWWWRedirects builds the www normalization redirects for the servers of the protocol.
+// The servers with wildcard hostnames and the servers without routes are skipped. A redirect is only built if
+// a valid listener of the protocol accepts the redirected hostname and, for HTTPS, if the certificate of that listener
+// is valid for the redirected hostname. The explicitly configured servers take precedence over the redirects.
+func buildWWWRedirects(
+	servers []VirtualServer,
+	listeners map[string]*listener,
+	protocol v1beta1.ProtocolType,
+	mode string,
+) []WWWRedirect {
+	if mode != wwwRedirectStrip && mode != wwwRedirectAdd {
+		return nil
+	}
+
+	// the listeners are sorted for the predictable choice of the listener that accepts a hostname
+	names := make([]string, 0, len(listeners))
+	for name, l := range listeners {
+		if l.Valid && l.Source.Protocol == protocol {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	hostnames := make(map[string]struct{}, len(servers))
+	for _, s := range servers {
+		hostnames[s.Hostname] = struct{}{}
+	}
+
+	var redirects []WWWRedirect
+
+	for _, s := range servers {
+		if len(s.PathRules) == 0 || strings.HasPrefix(s.Hostname, "*") || strings.HasPrefix(s.Hostname, "~") {
+			continue
+		}
+
+		isWWW := strings.HasPrefix(s.Hostname, wwwPrefix)
+
+		var from string
+
+		switch {
+		case mode == wwwRedirectStrip && !isWWW:
+			from = wwwPrefix + s.Hostname
+		case mode == wwwRedirectAdd && isWWW:
+			from = strings.TrimPrefix(s.Hostname, wwwPrefix)
+		default:
+			continue
+		}
+
+		if _, exist := hostnames[from]; exist {
+			continue
+		}
+
+		for _, name := range names {
+			l := listeners[name]
+
+			if _, ok := intersectHostnames(getHostname(l.Source.Hostname), from); !ok {
+				continue
+			}
+
+			r := WWWRedirect{
+				Hostname: from,
+				Target:   s.Hostname,
+			}
+
+			if protocol == v1beta1.HTTPSProtocolType {
+				if l.Certificate == nil || l.Certificate.VerifyHostname(from) != nil {
+					continue
+				}
+				r.SSL = newSSL(l.SecretPaths)
+			}
+
+			redirects = append(redirects, r)
+
+			break
+		}
+	}
+
+	sort.Slice(redirects, func(i, j int) bool {
+		return redirects[i].Hostname < redirects[j].Hostname
+	})
+
+	return redirects
 }
 
 type configBuilder struct {
 	http *virtualServerBuilder
 	ssl  *virtualServerBuilder
+	tcp  *tcpServerBuilder
 }
 
 func newConfigBuilder() *configBuilder {
 	return &configBuilder{
 		http: newVirtualServerBuilder(v1beta1.HTTPProtocolType),
 		ssl:  newVirtualServerBuilder(v1beta1.HTTPSProtocolType),
+		tcp:  newTCPServerBuilder(),
 	}
 }
 
@@ -102,6 +270,8 @@ func (b *configBuilder) upsertListener(l *listener) {
 		b.http.upsertListener(l)
 	case v1beta1.HTTPSProtocolType:
 		b.ssl.upsertListener(l)
+	case v1beta1.TCPProtocolType:
+		b.tcp.upsertListener(l)
 	default:
 		panic(fmt.Sprintf("listener protocol %s not supported", l.Source.Protocol))
 	}
@@ -111,9 +281,42 @@ func (b *configBuilder) build() Configuration {
 	return Configuration{
 		HTTPServers: b.http.build(),
 		SSLServers:  b.ssl.build(),
+		TCPServers:  b.tcp.build(),
 	}
 }
 
+type tcpServerBuilder struct {
+	servers []TCPServer
+}
+
+func newTCPServerBuilder() *tcpServerBuilder {
+	return &tcpServerBuilder{}
+}
+
+func (b *tcpServerBuilder) upsertListener(l *listener) {
+	if len(l.TCPRoutes) == 0 {
+		return
+	}
+
+	// A stream server can only proxy to one destination, so only one TCPRoute is attached to the listener.
+	// The other TCPRoutes are detached by resolveTCPRouteConflicts.
+	for _, r := range l.TCPRoutes {
+		b.servers = append(b.servers, TCPServer{
+			Port:   int32(l.Source.Port),
+			Source: r.Source,
+		})
+	}
+}
+
+func (b *tcpServerBuilder) build() []TCPServer {
+	// sort servers for predictable order
+	sort.Slice(b.servers, func(i, j int) bool {
+		return b.servers[i].Port < b.servers[j].Port
+	})
+
+	return b.servers
+}
+
 type virtualServerBuilder struct {
 	protocolType     v1beta1.ProtocolType
 	rulesPerHost     map[string]map[string]PathRule
@@ -136,13 +339,9 @@ func (b *virtualServerBuilder) upsertListener(l *listener) {
 	}
 
 	for _, r := range l.Routes {
-		var hostnames []string
-
-		for _, h := range r.Source.Spec.Hostnames {
-			if _, exist := l.AcceptedHostnames[string(h)]; exist {
-				hostnames = append(hostnames, string(h))
-			}
-		}
+		// l.AcceptedHostnames includes the hostnames of all routes attached to the listener, so the intersection is
+		// computed for every route to avoid serving a route on the hostnames of another route.
+		hostnames := findAcceptedHostnames(l.Source.Hostname, r.Source.Spec.Hostnames)
 
 		for _, h := range hostnames {
 			b.listenersForHost[h] = l
@@ -189,12 +388,12 @@ func (b *virtualServerBuilder) build() []VirtualServer {
 			panic(fmt.Sprintf("no listener found for hostname: %s", h))
 		}
 
-		if l.SecretPath != "" {
-			s.SSL = &SSL{CertificatePath: l.SecretPath}
+		if l.SecretPaths.Certificate != "" {
+			s.SSL = newSSL(l.SecretPaths)
 		}
 
 		for _, r := range rules {
-			sortMatchRules(r.MatchRules)
+			SortMatchRules(r.MatchRules)
 
 			s.PathRules = append(s.PathRules, r)
 		}
@@ -214,7 +413,7 @@ func (b *virtualServerBuilder) build() []VirtualServer {
 		if len(l.Routes) == 0 || hostname == wildcardHostname {
 			servers = append(servers, VirtualServer{
 				Hostname: hostname,
-				SSL:      &SSL{CertificatePath: l.SecretPath},
+				SSL:      newSSL(l.SecretPaths),
 			})
 		}
 	}
@@ -241,3 +440,10 @@ func getPath(path *v1beta1.HTTPPathMatch) string {
 	}
 	return *path.Value
 }
+
+func newSSL(paths SecretPaths) *SSL {
+	return &SSL{
+		CertificatePath: paths.Certificate,
+		KeyPath:         paths.Key,
+	}
+}
@@ -1,11 +1,13 @@
 package state
 
 import (
+	"crypto/x509"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/helpers"
@@ -55,7 +57,7 @@ func TestBuildConfiguration(t *testing.T) {
 		ValidSectionNameRefs: map[string]struct{}{
 			"listener-80-1": {},
 		},
-		InvalidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
 	}
 
 	hr2 := createRoute("hr-2", "bar.example.com", "listener-80-1", "/")
@@ -65,7 +67,7 @@ func TestBuildConfiguration(t *testing.T) {
 		ValidSectionNameRefs: map[string]struct{}{
 			"listener-80-1": {},
 		},
-		InvalidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
 	}
 
 	httpsHR1 := createRoute("https-hr-1", "foo.example.com", "listener-443-1", "/")
@@ -75,7 +77,7 @@ func TestBuildConfiguration(t *testing.T) {
 		ValidSectionNameRefs: map[string]struct{}{
 			"listener-443-1": {},
 		},
-		InvalidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
 	}
 
 	httpsHR2 := createRoute("https-hr-2", "bar.example.com", "listener-443-1", "/")
@@ -85,7 +87,7 @@ func TestBuildConfiguration(t *testing.T) {
 		ValidSectionNameRefs: map[string]struct{}{
 			"listener-443-1": {},
 		},
-		InvalidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
 	}
 
 	hr3 := createRoute("hr-3", "foo.example.com", "listener-80-1", "/", "/third")
@@ -95,7 +97,7 @@ func TestBuildConfiguration(t *testing.T) {
 		ValidSectionNameRefs: map[string]struct{}{
 			"listener-80-1": {},
 		},
-		InvalidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
 	}
 
 	httpsHR3 := createRoute("https-hr-3", "foo.example.com", "listener-443-1", "/", "/third")
@@ -105,7 +107,7 @@ func TestBuildConfiguration(t *testing.T) {
 		ValidSectionNameRefs: map[string]struct{}{
 			"listener-443-1": {},
 		},
-		InvalidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
 	}
 
 	hr4 := createRoute("hr-4", "foo.example.com", "listener-80-1", "/fourth", "/")
@@ -115,7 +117,7 @@ func TestBuildConfiguration(t *testing.T) {
 		ValidSectionNameRefs: map[string]struct{}{
 			"listener-80-1": {},
 		},
-		InvalidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
 	}
 
 	httpsHR4 := createRoute("https-hr-4", "foo.example.com", "listener-443-1", "/fourth", "/")
@@ -125,7 +127,7 @@ func TestBuildConfiguration(t *testing.T) {
 		ValidSectionNameRefs: map[string]struct{}{
 			"listener-443-1": {},
 		},
-		InvalidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
 	}
 
 	httpsHR5 := createRoute("https-hr-5", "example.com", "listener-443-with-hostname", "/")
@@ -135,7 +137,74 @@ func TestBuildConfiguration(t *testing.T) {
 		ValidSectionNameRefs: map[string]struct{}{
 			"listener-443-with-hostname": {},
 		},
-		InvalidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
+	}
+
+	hrMultipleHostnames := createRoute("hr-multiple-hostnames", "foo.example.com", "listener-80-wildcard", "/")
+	hrMultipleHostnames.Spec.Hostnames = []v1beta1.Hostname{"foo.example.com", "bar.example.com", "foo.example.org"}
+
+	routeHRMultipleHostnames := &route{
+		Source: hrMultipleHostnames,
+		ValidSectionNameRefs: map[string]struct{}{
+			"listener-80-wildcard": {},
+		},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
+	}
+
+	hrHTTPAndHTTPS := createRoute("hr-http-and-https", "foo.example.com", "listener-80-1", "/")
+	hrHTTPAndHTTPS.Spec.ParentRefs = append(hrHTTPAndHTTPS.Spec.ParentRefs, v1beta1.ParentReference{
+		Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+		Name:        "gateway",
+		SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("listener-443-1")),
+	})
+
+	routeHRHTTPAndHTTPS := &route{
+		Source: hrHTTPAndHTTPS,
+		ValidSectionNameRefs: map[string]struct{}{
+			"listener-80-1":  {},
+			"listener-443-1": {},
+		},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
+	}
+
+	hrHeaders := createRoute("hr-headers", "foo.example.com", "listener-80-1", "/api", "/api")
+	for i, version := range []string{"1", "2"} {
+		hrHeaders.Spec.Rules[i].Matches[0].Headers = []v1beta1.HTTPHeaderMatch{
+			{
+				Name:  "X-Ver",
+				Value: version,
+			},
+		}
+	}
+
+	hrNoHostnames := createRoute("hr-no-hostnames", "", "listener-80-app", "/")
+	hrNoHostnames.Spec.Hostnames = nil
+
+	routeHRNoHostnames := &route{
+		Source: hrNoHostnames,
+		ValidSectionNameRefs: map[string]struct{}{
+			"listener-80-app": {},
+		},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
+	}
+
+	httpsHRBroadHostnames := createRoute("https-hr-broad-hostnames", "", "listener-443-app", "/")
+	httpsHRBroadHostnames.Spec.Hostnames = []v1beta1.Hostname{"*.example.com", "foo.example.org"}
+
+	httpsRouteHRBroadHostnames := &route{
+		Source: httpsHRBroadHostnames,
+		ValidSectionNameRefs: map[string]struct{}{
+			"listener-443-app": {},
+		},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
+	}
+
+	routeHRHeaders := &route{
+		Source: hrHeaders,
+		ValidSectionNameRefs: map[string]struct{}{
+			"listener-80-1": {},
+		},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
 	}
 
 	listener80 := v1beta1.Listener{
@@ -180,6 +249,41 @@ func TestBuildConfiguration(t *testing.T) {
 		},
 	}
 
+	listenerWildcardHostname := v1beta1.Hostname("*.example.com")
+
+	listener80Wildcard := v1beta1.Listener{
+		Name:     "listener-80-wildcard",
+		Hostname: &listenerWildcardHostname,
+		Port:     80,
+		Protocol: v1beta1.HTTPProtocolType,
+	}
+
+	appHostname := v1beta1.Hostname("app.example.com")
+
+	listener80App := v1beta1.Listener{
+		Name:     "listener-80-app",
+		Hostname: &appHostname,
+		Port:     80,
+		Protocol: v1beta1.HTTPProtocolType,
+	}
+
+	listener443App := v1beta1.Listener{
+		Name:     "listener-443-app",
+		Hostname: &appHostname,
+		Port:     443,
+		Protocol: v1beta1.HTTPSProtocolType,
+		TLS: &v1beta1.GatewayTLSConfig{
+			Mode: helpers.GetTLSModePointer(v1beta1.TLSModeTerminate),
+			CertificateRefs: []v1beta1.SecretObjectReference{
+				{
+					Kind:      (*v1beta1.Kind)(helpers.GetStringPointer("Secret")),
+					Name:      "secret",
+					Namespace: (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+				},
+			},
+		},
+	}
+
 	invalidListener := v1beta1.Listener{
 		Name:     "invalid-listener",
 		Hostname: nil,
@@ -188,8 +292,46 @@ func TestBuildConfiguration(t *testing.T) {
 		TLS:      nil, // missing TLS config
 	}
 
-	// nolint:gosec
-	secretPath := "/etc/nginx/secrets/secret"
+	secretPaths := SecretPaths{
+		Certificate: "/etc/nginx/secrets/secret.crt",
+		Key:         "/etc/nginx/secrets/secret.key",
+	}
+	expectedSSL := &SSL{
+		CertificatePath: "/etc/nginx/secrets/secret.crt",
+		KeyPath:         "/etc/nginx/secrets/secret.key",
+	}
+
+	listener8080 := v1beta1.Listener{
+		Name:     "listener-8080",
+		Port:     8080,
+		Protocol: v1beta1.TCPProtocolType,
+	}
+
+	tr1 := &v1alpha2.TCPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "tr-1",
+		},
+		Spec: v1alpha2.TCPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{
+				ParentRefs: []v1alpha2.ParentReference{
+					{
+						Namespace:   (*v1alpha2.Namespace)(helpers.GetStringPointer("test")),
+						Name:        "gateway",
+						SectionName: (*v1alpha2.SectionName)(helpers.GetStringPointer("listener-8080")),
+					},
+				},
+			},
+		},
+	}
+
+	routeTR1 := &tcpRoute{
+		Source: tr1,
+		ValidSectionNameRefs: map[string]struct{}{
+			"listener-8080": {},
+		},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
+	}
 
 	tests := []struct {
 		graph    *graph
@@ -214,6 +356,56 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 			msg: "no listeners and routes",
 		},
+		{
+			graph: &graph{
+				GatewayClass: &gatewayClass{
+					Source: &v1beta1.GatewayClass{},
+					Valid:  true,
+				},
+				Gateway: &gateway{
+					Source:    &v1beta1.Gateway{},
+					Listeners: map[string]*listener{},
+				},
+				Routes: map[types.NamespacedName]*route{},
+				BackendAddresses: map[BackendKey]string{
+					{Kind: ServiceBackendKind, NsName: types.NamespacedName{Namespace: "test", Name: "foo"}, Port: 80}: "10.0.0.1",
+				},
+			},
+			expected: Configuration{
+				HTTPServers: []VirtualServer{},
+				SSLServers:  []VirtualServer{},
+				BackendAddresses: map[BackendKey]string{
+					{Kind: ServiceBackendKind, NsName: types.NamespacedName{Namespace: "test", Name: "foo"}, Port: 80}: "10.0.0.1",
+				},
+			},
+			msg: "resolved backends",
+		},
+		{
+			graph: &graph{
+				GatewayClass: &gatewayClass{
+					Source: &v1beta1.GatewayClass{},
+					Valid:  true,
+				},
+				Gateway: &gateway{
+					Source:    &v1beta1.Gateway{},
+					Listeners: map[string]*listener{},
+				},
+				Routes: map[types.NamespacedName]*route{
+					{Namespace: "test", Name: "hr-1"}: {
+						Source:               hr1,
+						ValidSectionNameRefs: map[string]struct{}{},
+						InvalidSectionNameRefs: map[string]ParentRefReason{
+							"listener-80-1": ParentRefReasonNoMatchingParent,
+						},
+					},
+				},
+			},
+			expected: Configuration{
+				HTTPServers: []VirtualServer{},
+				SSLServers:  []VirtualServer{},
+			},
+			msg: "no listeners with a route",
+		},
 		{
 			graph: &graph{
 				GatewayClass: &gatewayClass{
@@ -253,14 +445,14 @@ func TestBuildConfiguration(t *testing.T) {
 							Valid:             true,
 							Routes:            map[types.NamespacedName]*route{},
 							AcceptedHostnames: map[string]struct{}{},
-							SecretPath:        secretPath,
+							SecretPaths:       secretPaths,
 						},
 						"listener-443-with-hostname": {
 							Source:            listener443WithHostname, // non-nil hostname
 							Valid:             true,
 							Routes:            map[types.NamespacedName]*route{},
 							AcceptedHostnames: map[string]struct{}{},
-							SecretPath:        secretPath,
+							SecretPaths:       secretPaths,
 						},
 					},
 				},
@@ -271,11 +463,11 @@ func TestBuildConfiguration(t *testing.T) {
 				SSLServers: []VirtualServer{
 					{
 						Hostname: string(hostname),
-						SSL:      &SSL{CertificatePath: secretPath},
+						SSL:      expectedSSL,
 					},
 					{
 						Hostname: wildcardHostname,
-						SSL:      &SSL{CertificatePath: secretPath},
+						SSL:      expectedSSL,
 					},
 				},
 			},
@@ -301,7 +493,7 @@ func TestBuildConfiguration(t *testing.T) {
 								"foo.example.com": {},
 								"bar.example.com": {},
 							},
-							SecretPath: "",
+							SecretPaths: SecretPaths{},
 						},
 					},
 				},
@@ -316,6 +508,47 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 			msg: "invalid listener",
 		},
+		{
+			graph: &graph{
+				GatewayClass: &gatewayClass{
+					Source: &v1beta1.GatewayClass{},
+					Valid:  true,
+				},
+				Gateway: &gateway{
+					Source: &v1beta1.Gateway{},
+					Listeners: map[string]*listener{
+						"listener-53": {
+							Source: v1beta1.Listener{
+								Name:     "listener-53",
+								Port:     53,
+								Protocol: v1beta1.UDPProtocolType,
+							},
+							Valid:               false,
+							UnsupportedProtocol: true,
+							Routes:              map[types.NamespacedName]*route{},
+							AcceptedHostnames:   map[string]struct{}{},
+						},
+						"listener-443-tls": {
+							Source: v1beta1.Listener{
+								Name:     "listener-443-tls",
+								Port:     443,
+								Protocol: v1beta1.TLSProtocolType,
+							},
+							Valid:               false,
+							UnsupportedProtocol: true,
+							Routes:              map[types.NamespacedName]*route{},
+							AcceptedHostnames:   map[string]struct{}{},
+						},
+					},
+				},
+				Routes: map[types.NamespacedName]*route{},
+			},
+			expected: Configuration{
+				HTTPServers: []VirtualServer{},
+				SSLServers:  []VirtualServer{},
+			},
+			msg: "listeners with unsupported protocols",
+		},
 		{
 			graph: &graph{
 				GatewayClass: &gatewayClass{
@@ -381,6 +614,247 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 			msg: "one http listener with two routes for different hostnames",
 		},
+		{
+			graph: &graph{
+				GatewayClass: &gatewayClass{
+					Source: &v1beta1.GatewayClass{},
+					Valid:  true,
+				},
+				Gateway: &gateway{
+					Source: &v1beta1.Gateway{},
+					Listeners: map[string]*listener{
+						"listener-80-wildcard": {
+							Source: listener80Wildcard,
+							Valid:  true,
+							Routes: map[types.NamespacedName]*route{
+								{Namespace: "test", Name: "hr-multiple-hostnames"}: routeHRMultipleHostnames,
+							},
+							AcceptedHostnames: map[string]struct{}{
+								"foo.example.com": {},
+								"bar.example.com": {},
+							},
+						},
+					},
+				},
+				Routes: map[types.NamespacedName]*route{
+					{Namespace: "test", Name: "hr-multiple-hostnames"}: routeHRMultipleHostnames,
+				},
+			},
+			expected: Configuration{
+				HTTPServers: []VirtualServer{
+					{
+						Hostname: "bar.example.com",
+						PathRules: []PathRule{
+							{
+								Path: "/",
+								MatchRules: []MatchRule{
+									{
+										MatchIdx: 0,
+										RuleIdx:  0,
+										Source:   hrMultipleHostnames,
+									},
+								},
+							},
+						},
+					},
+					{
+						Hostname: "foo.example.com",
+						PathRules: []PathRule{
+							{
+								Path: "/",
+								MatchRules: []MatchRule{
+									{
+										MatchIdx: 0,
+										RuleIdx:  0,
+										Source:   hrMultipleHostnames,
+									},
+								},
+							},
+						},
+					},
+				},
+				SSLServers: []VirtualServer{},
+			},
+			msg: "http listener with a wildcard hostname and a route with three hostnames",
+		},
+		{
+			graph: &graph{
+				GatewayClass: &gatewayClass{
+					Source: &v1beta1.GatewayClass{},
+					Valid:  true,
+				},
+				Gateway: &gateway{
+					Source: &v1beta1.Gateway{},
+					Listeners: map[string]*listener{
+						"listener-80-1": {
+							Source: listener80,
+							Valid:  true,
+							Routes: map[types.NamespacedName]*route{
+								{Namespace: "test", Name: "hr-headers"}: routeHRHeaders,
+							},
+							AcceptedHostnames: map[string]struct{}{
+								"foo.example.com": {},
+							},
+						},
+					},
+				},
+				Routes: map[types.NamespacedName]*route{
+					{Namespace: "test", Name: "hr-headers"}: routeHRHeaders,
+				},
+			},
+			expected: Configuration{
+				HTTPServers: []VirtualServer{
+					{
+						Hostname: "foo.example.com",
+						PathRules: []PathRule{
+							{
+								Path: "/api",
+								MatchRules: []MatchRule{
+									{
+										MatchIdx: 0,
+										RuleIdx:  0,
+										Source:   hrHeaders,
+									},
+									{
+										MatchIdx: 0,
+										RuleIdx:  1,
+										Source:   hrHeaders,
+									},
+								},
+							},
+						},
+					},
+				},
+				SSLServers: []VirtualServer{},
+			},
+			msg: "http listener with a route with two rules for the same path with different header matches",
+		},
+		{
+			graph: &graph{
+				GatewayClass: &gatewayClass{
+					Source: &v1beta1.GatewayClass{},
+					Valid:  true,
+				},
+				Gateway: &gateway{
+					Source: &v1beta1.Gateway{},
+					Listeners: map[string]*listener{
+						"listener-80-app": {
+							Source: listener80App,
+							Valid:  true,
+							Routes: map[types.NamespacedName]*route{
+								{Namespace: "test", Name: "hr-no-hostnames"}: routeHRNoHostnames,
+							},
+							AcceptedHostnames: map[string]struct{}{
+								"app.example.com": {},
+							},
+						},
+					},
+				},
+				Routes: map[types.NamespacedName]*route{
+					{Namespace: "test", Name: "hr-no-hostnames"}: routeHRNoHostnames,
+				},
+			},
+			expected: Configuration{
+				HTTPServers: []VirtualServer{
+					{
+						Hostname: "app.example.com",
+						PathRules: []PathRule{
+							{
+								Path: "/",
+								MatchRules: []MatchRule{
+									{
+										MatchIdx: 0,
+										RuleIdx:  0,
+										Source:   hrNoHostnames,
+									},
+								},
+							},
+						},
+					},
+				},
+				SSLServers: []VirtualServer{},
+			},
+			msg: "http listener with a hostname and a route without hostnames",
+		},
+		{
+			graph: &graph{
+				GatewayClass: &gatewayClass{
+					Source: &v1beta1.GatewayClass{},
+					Valid:  true,
+				},
+				Gateway: &gateway{
+					Source: &v1beta1.Gateway{},
+					Listeners: map[string]*listener{
+						"listener-80-1": {
+							Source: listener80,
+							Valid:  true,
+							Routes: map[types.NamespacedName]*route{
+								{Namespace: "test", Name: "hr-http-and-https"}: routeHRHTTPAndHTTPS,
+							},
+							AcceptedHostnames: map[string]struct{}{
+								"foo.example.com": {},
+							},
+						},
+						"listener-443-1": {
+							Source:      listener443,
+							Valid:       true,
+							SecretPaths: secretPaths,
+							Routes: map[types.NamespacedName]*route{
+								{Namespace: "test", Name: "hr-http-and-https"}: routeHRHTTPAndHTTPS,
+							},
+							AcceptedHostnames: map[string]struct{}{
+								"foo.example.com": {},
+							},
+						},
+					},
+				},
+				Routes: map[types.NamespacedName]*route{
+					{Namespace: "test", Name: "hr-http-and-https"}: routeHRHTTPAndHTTPS,
+				},
+			},
+			expected: Configuration{
+				HTTPServers: []VirtualServer{
+					{
+						Hostname: "foo.example.com",
+						PathRules: []PathRule{
+							{
+								Path: "/",
+								MatchRules: []MatchRule{
+									{
+										MatchIdx: 0,
+										RuleIdx:  0,
+										Source:   hrHTTPAndHTTPS,
+									},
+								},
+							},
+						},
+					},
+				},
+				SSLServers: []VirtualServer{
+					{
+						Hostname: "foo.example.com",
+						PathRules: []PathRule{
+							{
+								Path: "/",
+								MatchRules: []MatchRule{
+									{
+										MatchIdx: 0,
+										RuleIdx:  0,
+										Source:   hrHTTPAndHTTPS,
+									},
+								},
+							},
+						},
+						SSL: expectedSSL,
+					},
+					{
+						Hostname: wildcardHostname,
+						SSL:      expectedSSL,
+					},
+				},
+			},
+			msg: "one http and one https listener with a route attached to both",
+		},
 		{
 			graph: &graph{
 				GatewayClass: &gatewayClass{
@@ -391,9 +865,9 @@ func TestBuildConfiguration(t *testing.T) {
 					Source: &v1beta1.Gateway{},
 					Listeners: map[string]*listener{
 						"listener-443-1": {
-							Source:     listener443,
-							Valid:      true,
-							SecretPath: secretPath,
+							Source:      listener443,
+							Valid:       true,
+							SecretPaths: secretPaths,
 							Routes: map[types.NamespacedName]*route{
 								{Namespace: "test", Name: "https-hr-1"}: httpsRouteHR1,
 								{Namespace: "test", Name: "https-hr-2"}: httpsRouteHR2,
@@ -404,9 +878,9 @@ func TestBuildConfiguration(t *testing.T) {
 							},
 						},
 						"listener-443-with-hostname": {
-							Source:     listener443WithHostname,
-							Valid:      true,
-							SecretPath: secretPath,
+							Source:      listener443WithHostname,
+							Valid:       true,
+							SecretPaths: secretPaths,
 							Routes: map[types.NamespacedName]*route{
 								{Namespace: "test", Name: "https-hr-5"}: httpsRouteHR5,
 							},
@@ -439,9 +913,7 @@ func TestBuildConfiguration(t *testing.T) {
 								},
 							},
 						},
-						SSL: &SSL{
-							CertificatePath: secretPath,
-						},
+						SSL: expectedSSL,
 					},
 					{
 						Hostname: "example.com",
@@ -457,9 +929,7 @@ func TestBuildConfiguration(t *testing.T) {
 								},
 							},
 						},
-						SSL: &SSL{
-							CertificatePath: secretPath,
-						},
+						SSL: expectedSSL,
 					},
 					{
 						Hostname: "foo.example.com",
@@ -475,18 +945,65 @@ func TestBuildConfiguration(t *testing.T) {
 								},
 							},
 						},
-						SSL: &SSL{
-							CertificatePath: secretPath,
-						},
+						SSL: expectedSSL,
 					},
 					{
 						Hostname: wildcardHostname,
-						SSL:      &SSL{CertificatePath: secretPath},
+						SSL:      expectedSSL,
 					},
 				},
 			},
 			msg: "two https listeners each with routes for different hostnames",
 		},
+		{
+			graph: &graph{
+				GatewayClass: &gatewayClass{
+					Source: &v1beta1.GatewayClass{},
+					Valid:  true,
+				},
+				Gateway: &gateway{
+					Source: &v1beta1.Gateway{},
+					Listeners: map[string]*listener{
+						"listener-443-app": {
+							Source:      listener443App,
+							Valid:       true,
+							SecretPaths: secretPaths,
+							Routes: map[types.NamespacedName]*route{
+								{Namespace: "test", Name: "https-hr-broad-hostnames"}: httpsRouteHRBroadHostnames,
+							},
+							AcceptedHostnames: map[string]struct{}{
+								"app.example.com": {},
+							},
+						},
+					},
+				},
+				Routes: map[types.NamespacedName]*route{
+					{Namespace: "test", Name: "https-hr-broad-hostnames"}: httpsRouteHRBroadHostnames,
+				},
+			},
+			expected: Configuration{
+				HTTPServers: []VirtualServer{},
+				SSLServers: []VirtualServer{
+					{
+						Hostname: "app.example.com",
+						PathRules: []PathRule{
+							{
+								Path: "/",
+								MatchRules: []MatchRule{
+									{
+										MatchIdx: 0,
+										RuleIdx:  0,
+										Source:   httpsHRBroadHostnames,
+									},
+								},
+							},
+						},
+						SSL: expectedSSL,
+					},
+				},
+			},
+			msg: "https listener with a hostname and a route with broader hostnames",
+		},
 		{
 			graph: &graph{
 				GatewayClass: &gatewayClass{
@@ -508,9 +1025,9 @@ func TestBuildConfiguration(t *testing.T) {
 							},
 						},
 						"listener-443-1": {
-							Source:     listener443,
-							Valid:      true,
-							SecretPath: secretPath,
+							Source:      listener443,
+							Valid:       true,
+							SecretPaths: secretPaths,
 							Routes: map[types.NamespacedName]*route{
 								{Namespace: "test", Name: "https-hr-3"}: httpsRouteHR3,
 								{Namespace: "test", Name: "https-hr-4"}: httpsRouteHR4,
@@ -574,9 +1091,7 @@ func TestBuildConfiguration(t *testing.T) {
 				SSLServers: []VirtualServer{
 					{
 						Hostname: "foo.example.com",
-						SSL: &SSL{
-							CertificatePath: secretPath,
-						},
+						SSL:      expectedSSL,
 						PathRules: []PathRule{
 							{
 								Path: "/",
@@ -617,12 +1132,49 @@ func TestBuildConfiguration(t *testing.T) {
 					},
 					{
 						Hostname: wildcardHostname,
-						SSL:      &SSL{CertificatePath: secretPath},
+						SSL:      expectedSSL,
 					},
 				},
 			},
 			msg: "one http and one https listener with two routes with the same hostname with and without collisions",
 		},
+		{
+			graph: &graph{
+				GatewayClass: &gatewayClass{
+					Source: &v1beta1.GatewayClass{},
+					Valid:  true,
+				},
+				Gateway: &gateway{
+					Source: &v1beta1.Gateway{},
+					Listeners: map[string]*listener{
+						"listener-8080": {
+							Source:            listener8080,
+							Valid:             true,
+							Routes:            map[types.NamespacedName]*route{},
+							AcceptedHostnames: map[string]struct{}{},
+							TCPRoutes: map[types.NamespacedName]*tcpRoute{
+								{Namespace: "test", Name: "tr-1"}: routeTR1,
+							},
+						},
+					},
+				},
+				Routes: map[types.NamespacedName]*route{},
+				TCPRoutes: map[types.NamespacedName]*tcpRoute{
+					{Namespace: "test", Name: "tr-1"}: routeTR1,
+				},
+			},
+			expected: Configuration{
+				HTTPServers: []VirtualServer{},
+				SSLServers:  []VirtualServer{},
+				TCPServers: []TCPServer{
+					{
+						Port:   8080,
+						Source: tr1,
+					},
+				},
+			},
+			msg: "tcp listener with a route",
+		},
 		{
 			graph: &graph{
 				GatewayClass: &gatewayClass{
@@ -689,6 +1241,27 @@ func TestBuildConfiguration(t *testing.T) {
 			expected: Configuration{},
 			msg:      "missing gateway",
 		},
+		{
+			graph: &graph{
+				GatewayClass: &gatewayClass{
+					Source: &v1beta1.GatewayClass{},
+					Valid:  true,
+				},
+				Gateway: &gateway{
+					Source:            &v1beta1.Gateway{},
+					Listeners:         map[string]*listener{},
+					ListenAddresses:   []string{"10.0.0.1"},
+					RejectedAddresses: []string{"10.0.0.2"},
+				},
+				Routes: map[types.NamespacedName]*route{},
+			},
+			expected: Configuration{
+				HTTPServers:     []VirtualServer{},
+				SSLServers:      []VirtualServer{},
+				ListenAddresses: []string{"10.0.0.1"},
+			},
+			msg: "gateway with addresses",
+		},
 	}
 
 	for _, test := range tests {
@@ -699,6 +1272,190 @@ func TestBuildConfiguration(t *testing.T) {
 	}
 }
 
+func TestBuildWWWRedirects(t *testing.T) {
+	pathRules := []PathRule{{Path: "/"}}
+	secretPaths := SecretPaths{
+		Certificate: "/etc/nginx/secrets/secret.crt",
+		Key:         "/etc/nginx/secrets/secret.key",
+	}
+
+	servers := []VirtualServer{
+		{Hostname: wildcardHostname},
+		{Hostname: "*.example.org", PathRules: pathRules},
+		{Hostname: "bar.example.com", PathRules: pathRules},
+		{Hostname: "example.com", PathRules: pathRules},
+		{Hostname: "no-routes.example.com"},
+		{Hostname: "www.bar.example.com", PathRules: pathRules},
+		{Hostname: "www.example.org", PathRules: pathRules},
+	}
+
+	httpListeners := map[string]*listener{
+		"listener-80": {
+			Source: v1beta1.Listener{Protocol: v1beta1.HTTPProtocolType},
+			Valid:  true,
+		},
+	}
+
+	tests := []struct {
+		listeners map[string]*listener
+		mode      string
+		expected  []WWWRedirect
+		msg       string
+	}{
+		{
+			listeners: httpListeners,
+			mode:      wwwRedirectStrip,
+			expected: []WWWRedirect{
+				{Hostname: "www.example.com", Target: "example.com"},
+			},
+			msg: "strip www",
+		},
+		{
+			listeners: httpListeners,
+			mode:      wwwRedirectAdd,
+			expected: []WWWRedirect{
+				{Hostname: "example.org", Target: "www.example.org"},
+			},
+			msg: "add www",
+		},
+		{
+			listeners: httpListeners,
+			mode:      "",
+			expected:  nil,
+			msg:       "no annotation",
+		},
+		{
+			listeners: httpListeners,
+			mode:      "remove",
+			expected:  nil,
+			msg:       "invalid annotation",
+		},
+		{
+			listeners: map[string]*listener{
+				"listener-80": {
+					Source: v1beta1.Listener{
+						Protocol: v1beta1.HTTPProtocolType,
+						Hostname: (*v1beta1.Hostname)(helpers.GetStringPointer("example.com")),
+					},
+					Valid: true,
+				},
+			},
+			mode:     wwwRedirectStrip,
+			expected: nil,
+			msg:      "no listener accepts the redirected hostname",
+		},
+		{
+			listeners: map[string]*listener{
+				"listener-80": {
+					Source: v1beta1.Listener{
+						Protocol: v1beta1.HTTPProtocolType,
+						Hostname: (*v1beta1.Hostname)(helpers.GetStringPointer("*.example.com")),
+					},
+					Valid: true,
+				},
+			},
+			mode: wwwRedirectStrip,
+			expected: []WWWRedirect{
+				{Hostname: "www.example.com", Target: "example.com"},
+			},
+			msg: "wildcard listener accepts the redirected hostname",
+		},
+		{
+			listeners: map[string]*listener{
+				"listener-80": {
+					Source: v1beta1.Listener{Protocol: v1beta1.HTTPProtocolType},
+					Valid:  false,
+				},
+			},
+			mode:     wwwRedirectStrip,
+			expected: nil,
+			msg:      "invalid listener",
+		},
+	}
+
+	for _, test := range tests {
+		result := buildWWWRedirects(servers, test.listeners, v1beta1.HTTPProtocolType, test.mode)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("buildWWWRedirects() %q mismatch (-want +got):\n%s", test.msg, diff)
+		}
+	}
+
+	sslServers := []VirtualServer{
+		{Hostname: "example.com", PathRules: pathRules, SSL: newSSL(secretPaths)},
+	}
+
+	sslTests := []struct {
+		certificate *x509.Certificate
+		expected    []WWWRedirect
+		msg         string
+	}{
+		{
+			certificate: &x509.Certificate{DNSNames: []string{"example.com", "www.example.com"}},
+			expected: []WWWRedirect{
+				{Hostname: "www.example.com", Target: "example.com", SSL: newSSL(secretPaths)},
+			},
+			msg: "certificate is valid for the redirected hostname",
+		},
+		{
+			certificate: &x509.Certificate{DNSNames: []string{"*.example.com"}},
+			expected: []WWWRedirect{
+				{Hostname: "www.example.com", Target: "example.com", SSL: newSSL(secretPaths)},
+			},
+			msg: "wildcard certificate is valid for the redirected hostname",
+		},
+		{
+			certificate: &x509.Certificate{DNSNames: []string{"example.com"}},
+			expected:    nil,
+			msg:         "certificate is not valid for the redirected hostname",
+		},
+		{
+			certificate: nil,
+			expected:    nil,
+			msg:         "no certificate",
+		},
+	}
+
+	for _, test := range sslTests {
+		listeners := map[string]*listener{
+			"listener-443": {
+				Source:      v1beta1.Listener{Protocol: v1beta1.HTTPSProtocolType},
+				Valid:       true,
+				SecretPaths: secretPaths,
+				Certificate: test.certificate,
+			},
+		}
+
+		result := buildWWWRedirects(sslServers, listeners, v1beta1.HTTPSProtocolType, wwwRedirectStrip)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("buildWWWRedirects() %q mismatch (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestSetHTTPSRedirects(t *testing.T) {
+	pathRules := []PathRule{{Path: "/"}}
+
+	httpServers := []VirtualServer{
+		{Hostname: "both.example.com", PathRules: pathRules},
+		{Hostname: "http-only.example.com", PathRules: pathRules},
+	}
+	sslServers := []VirtualServer{
+		{Hostname: "both.example.com", PathRules: pathRules},
+		{Hostname: "https-only.example.com", PathRules: pathRules},
+	}
+
+	expected := []VirtualServer{
+		{Hostname: "both.example.com", PathRules: pathRules, HTTPSRedirect: true},
+		{Hostname: "http-only.example.com", PathRules: pathRules},
+	}
+
+	setHTTPSRedirects(httpServers, sslServers)
+
+	if diff := cmp.Diff(expected, httpServers); diff != "" {
+		t.Errorf("setHTTPSRedirects() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestGetPath(t *testing.T) {
 	tests := []struct {
 		path     *v1beta1.HTTPPathMatch
@@ -1,10 +1,15 @@
 package state
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"sort"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
@@ -14,20 +19,75 @@ type gateway struct {
 	Source *v1beta1.Gateway
 	// Listeners include the listeners of the Gateway.
 	Listeners map[string]*listener
+	// ListenAddresses are the IP addresses from the addresses of the Gateway that NGINX can listen on.
+	ListenAddresses []string
+	// RejectedAddresses are the values of the addresses of the Gateway that NGINX cannot listen on.
+	RejectedAddresses []string
 }
 
+// ParentRefReason is the reason why a parentRef of an HTTPRoute is invalid. The values match the reasons of
+// the Accepted condition of the Gateway API routes.
+type ParentRefReason string
+
+const (
+	// ParentRefReasonNoMatchingParent means the Gateway doesn't have the listener the parentRef references.
+	ParentRefReasonNoMatchingParent ParentRefReason = "NoMatchingParent"
+	// ParentRefReasonNoMatchingListenerHostname means none of the hostnames of the route match the hostname of
+	// the listener.
+	ParentRefReasonNoMatchingListenerHostname ParentRefReason = "NoMatchingListenerHostname"
+	// ParentRefReasonNotAllowedByListeners means the listener doesn't allow the route: the protocol of the listener
+	// doesn't support the kind of the route or its allowedRoutes don't include the route.
+	ParentRefReasonNotAllowedByListeners ParentRefReason = "NotAllowedByListeners"
+	// ParentRefReasonGatewayConflict means the parentRef references a Gateway that is ignored due to a conflicting
+	// Gateway resource.
+	ParentRefReasonGatewayConflict ParentRefReason = "GatewayConflict"
+	// ParentRefReasonRouteConflict means the TCP listener the parentRef references is used by an older TCPRoute.
+	// A TCP listener proxies all connections to a single destination, so only one TCPRoute can attach to it.
+	ParentRefReasonRouteConflict ParentRefReason = "RouteConflict"
+)
+
 // route represents an HTTPRoute.
 type route struct {
 	// Source is the source resource of the route.
 	// FIXME(pleshakov)
-	// For now, we assume that the source is only HTTPRoute. Later we can support more types - TLSRoute, TCPRoute and UDPRoute.
+	// For now, we assume that the source is only HTTPRoute. TCPRoutes are represented by tcpRoute. Later we can support
+	// more types - TLSRoute and UDPRoute.
 	Source *v1beta1.HTTPRoute
 
 	// ValidSectionNameRefs includes the sectionNames from the parentRefs of the HTTPRoute that are valid -- i.e.
 	// the Gateway resource has a corresponding valid listener.
 	ValidSectionNameRefs map[string]struct{}
-	// ValidSectionNameRefs includes the sectionNames from the parentRefs of the HTTPRoute that are invalid.
-	InvalidSectionNameRefs map[string]struct{}
+	// InvalidSectionNameRefs includes the sectionNames from the parentRefs of the HTTPRoute that are invalid along with
+	// the reasons why they are invalid.
+	InvalidSectionNameRefs map[string]ParentRefReason
+	// MissingGatewayRefs includes the sectionNames from the parentRefs of the HTTPRoute that reference Gateway
+	// resources that don't exist, where the key is the namespaced name of a Gateway. It is nil if there are no
+	// such parentRefs.
+	MissingGatewayRefs map[types.NamespacedName][]string
+	// UnresolvedRefs includes the messages explaining why the Service backendRefs of the HTTPRoute cannot be resolved.
+	// For example, a Service doesn't exist or doesn't expose the port.
+	UnresolvedRefs []string
+	// InvalidKindRefs includes the messages explaining why the backendRefs of the HTTPRoute reference unsupported
+	// resources. Only the Services of the core group are supported.
+	InvalidKindRefs []string
+	// BackendRefCount is the number of the backendRefs of the HTTPRoute, including the unresolved and invalid ones.
+	BackendRefCount int
+	// UnresolvableRules includes the indexes of the rules of the HTTPRoute whose backendRefs are all either unresolved
+	// or invalid. NGINX cannot proxy the requests of such rules.
+	UnresolvableRules []int
+}
+
+// tcpRoute represents a TCPRoute.
+type tcpRoute struct {
+	// Source is the source resource of the route.
+	Source *v1alpha2.TCPRoute
+
+	// ValidSectionNameRefs includes the sectionNames from the parentRefs of the TCPRoute that are valid -- i.e.
+	// the Gateway resource has a corresponding valid TCP listener.
+	ValidSectionNameRefs map[string]struct{}
+	// InvalidSectionNameRefs includes the sectionNames from the parentRefs of the TCPRoute that are invalid along with
+	// the reasons why they are invalid.
+	InvalidSectionNameRefs map[string]ParentRefReason
 }
 
 // gatewayClass represents the GatewayClass resource.
@@ -44,6 +104,9 @@ type gatewayClass struct {
 type graph struct {
 	// GatewayClass holds the GatewayClass resource.
 	GatewayClass *gatewayClass
+	// IgnoredGatewayClasses holds the ignored GatewayClass resources, which reference the Gateway controller (based on
+	// the ControllerName field of the resource) but are not the GatewayClass of the NGINX Gateway.
+	IgnoredGatewayClasses map[string]*v1beta1.GatewayClass
 	// Gateway holds the winning Gateway resource.
 	Gateway *gateway
 	// IgnoredGateways holds the ignored Gateway resources, which belong to the NGINX Gateway (based on the
@@ -52,33 +115,73 @@ type graph struct {
 	IgnoredGateways map[types.NamespacedName]*v1beta1.Gateway
 	// Routes holds route resources.
 	Routes map[types.NamespacedName]*route
+	// TCPRoutes holds TCPRoute resources.
+	TCPRoutes map[types.NamespacedName]*tcpRoute
+	// BackendAddresses holds the addresses of the backends of the HTTPRoutes resolved while the graph was built.
+	// It is nil if no backend is resolved.
+	BackendAddresses map[BackendKey]string
 }
 
 // buildGraph builds a graph from a store assuming that the Gateway resource has the gwNsName namespace and name.
+// If ctx is done, the backendRefs that are not resolved yet are reported as unresolved. The resolution of the
+// backendRefs of every HTTPRoute is also bounded by resolveTimeout, so that a slow resolver doesn't stall the build.
+// Every backend is resolved once.
+// allowedAddresses are the IP addresses the addresses of the Gateway are validated against.
 func buildGraph(
+	ctx context.Context,
 	store *store,
 	controllerName string,
 	gcName string,
 	secretMemoryMgr SecretDiskMemoryManager,
+	serviceStore ServiceStore,
+	backendResolvers map[BackendKind]BackendResolver,
+	resolveTimeout time.Duration,
+	allowedAddresses []string,
 ) *graph {
 	gc := buildGatewayClass(store.gc, controllerName)
 
+	ignoredGcs := processIgnoredGatewayClasses(store.ignoredGatewayClasses, controllerName)
+
 	gw, ignoredGws := processGateways(store.gateways, gcName)
 
 	listeners := buildListeners(gw, gcName, secretMemoryMgr)
 
+	// the same backend can be referenced by multiple HTTPRoutes, so it is only resolved once.
+	resolutions := make(BackendResolutions)
+
 	routes := make(map[types.NamespacedName]*route)
 	for _, ghr := range store.httpRoutes {
-		ignored, r := bindHTTPRouteToListeners(ghr, gw, ignoredGws, listeners)
+		ignored, r := bindHTTPRouteToListeners(ghr, gw, ignoredGws, store.gateways, listeners)
 		if !ignored {
+			r.BackendRefCount, r.UnresolvedRefs, r.InvalidKindRefs, r.UnresolvableRules = resolveBackendRefs(
+				ctx,
+				ghr,
+				resolutions,
+				resolveTimeout,
+				serviceStore,
+				backendResolvers,
+			)
 			routes[getNamespacedName(ghr)] = r
 		}
 	}
 
+	tcpRoutes := make(map[types.NamespacedName]*tcpRoute)
+	for _, tr := range store.tcpRoutes {
+		ignored, r := bindTCPRouteToListeners(tr, gw, ignoredGws, listeners)
+		if !ignored {
+			tcpRoutes[getNamespacedName(tr)] = r
+		}
+	}
+
+	resolveTCPRouteConflicts(listeners)
+
 	g := &graph{
-		GatewayClass:    gc,
-		Routes:          routes,
-		IgnoredGateways: ignoredGws,
+		GatewayClass:          gc,
+		IgnoredGatewayClasses: ignoredGcs,
+		Routes:                routes,
+		TCPRoutes:             tcpRoutes,
+		IgnoredGateways:       ignoredGws,
+		BackendAddresses:      resolutions.Addresses(),
 	}
 
 	if gw != nil {
@@ -86,6 +189,7 @@ func buildGraph(
 			Source:    gw,
 			Listeners: listeners,
 		}
+		g.Gateway.ListenAddresses, g.Gateway.RejectedAddresses = validateGatewayAddresses(gw, allowedAddresses)
 	}
 
 	return g
@@ -122,6 +226,25 @@ func processGateways(gws map[types.NamespacedName]*v1beta1.Gateway, gcName strin
 	return referencedGws[0], ignoredGws
 }
 
+// processIgnoredGatewayClasses returns the GatewayClass resources that conflict with the GatewayClass of the
+// NGINX Gateway - the ones that reference the same Gateway controller.
+func processIgnoredGatewayClasses(gcs map[string]*v1beta1.GatewayClass, controllerName string) map[string]*v1beta1.GatewayClass {
+	var ignoredGcs map[string]*v1beta1.GatewayClass
+
+	for name, gc := range gcs {
+		if string(gc.Spec.ControllerName) != controllerName {
+			continue
+		}
+
+		if ignoredGcs == nil {
+			ignoredGcs = make(map[string]*v1beta1.GatewayClass)
+		}
+		ignoredGcs[name] = gc
+	}
+
+	return ignoredGcs
+}
+
 func buildGatewayClass(gc *v1beta1.GatewayClass, controllerName string) *gatewayClass {
 	if gc == nil {
 		return nil
@@ -152,21 +275,139 @@ func buildListeners(gw *v1beta1.Gateway, gcName string, secretMemoryMgr SecretDi
 
 	for _, gl := range gw.Spec.Listeners {
 		configurator := listenerFactory.getConfiguratorForListener(gl)
-		listeners[string(gl.Name)] = configurator.configure(gl)
+
+		l := configurator.configure(gl)
+		l.UnsupportedSelector = usesNamespaceSelector(gl)
+
+		listeners[string(gl.Name)] = l
 	}
 
+	markProtocolConflicts(listeners)
+
 	return listeners
 }
 
+// markProtocolConflicts invalidates the listeners that bind the same port as a listener with a different protocol.
+// Such listeners cannot coexist, so all of them become conflicted.
+// The listeners with unsupported protocols are ignored, because NGINX doesn't bind their ports.
+func markProtocolConflicts(listeners map[string]*listener) {
+	protocols := make(map[v1beta1.PortNumber]map[v1beta1.ProtocolType]struct{})
+
+	for _, l := range listeners {
+		if l.UnsupportedProtocol {
+			continue
+		}
+
+		port := l.Source.Port
+		if protocols[port] == nil {
+			protocols[port] = make(map[v1beta1.ProtocolType]struct{})
+		}
+		protocols[port][l.Source.Protocol] = struct{}{}
+	}
+
+	for _, l := range listeners {
+		if !l.UnsupportedProtocol && len(protocols[l.Source.Port]) > 1 {
+			l.Valid = false
+			l.Conflict = ListenerConflictReasonProtocol
+		}
+	}
+}
+
+// resolveBackendRefs checks that the backends referenced by the backendRefs of the HTTPRoute exist and expose the
+// requested ports. The Services are resolved by the serviceStore and the other kinds by their backendResolvers.
+// It returns the number of the checked backendRefs, a message for every backendRef that cannot be resolved,
+// a message for every backendRef that references an unsupported kind and the indexes of the rules none of whose
+// checked backendRefs can be resolved.
+// backendRefs without a port are not checked.
+// The backends are resolved through the resolutions, and their resolution is bounded by resolveTimeout. The backends
+// that are not resolved in time are unresolved.
+func resolveBackendRefs(
+	ctx context.Context,
+	hr *v1beta1.HTTPRoute,
+	resolutions BackendResolutions,
+	resolveTimeout time.Duration,
+	serviceStore ServiceStore,
+	backendResolvers map[BackendKind]BackendResolver,
+) (count int, unresolved []string, invalidKind []string, unresolvableRules []int) {
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+
+	for ruleIdx, rule := range hr.Spec.Rules {
+		ruleCount, ruleFailed := 0, 0
+
+		for _, ref := range rule.BackendRefs {
+			kind := GetBackendKind(ref.BackendObjectReference)
+
+			var resolver BackendResolver = serviceStore
+			if kind != ServiceBackendKind {
+				r, exists := backendResolvers[kind]
+				if !exists {
+					// the kind is checked first, because the port is optional for the kinds other than Service
+					count++
+					ruleCount++
+					invalidKind = append(invalidKind, unsupportedBackendKindMsg(string(ref.Name), kind))
+					ruleFailed++
+					continue
+				}
+				resolver = r
+			}
+
+			if ref.Port == nil {
+				continue
+			}
+
+			count++
+			ruleCount++
+
+			ns := hr.Namespace
+			if ref.Namespace != nil {
+				ns = string(*ref.Namespace)
+			}
+
+			key := BackendKey{
+				Kind:   kind,
+				NsName: types.NamespacedName{Namespace: ns, Name: string(ref.Name)},
+				Port:   int32(*ref.Port),
+			}
+
+			_, err := resolutions.Resolve(ctx, resolver, key)
+			if err != nil {
+				unresolved = append(unresolved, err.Error())
+				ruleFailed++
+			}
+		}
+
+		if ruleCount > 0 && ruleFailed == ruleCount {
+			unresolvableRules = append(unresolvableRules, ruleIdx)
+		}
+	}
+
+	return count, unresolved, invalidKind, unresolvableRules
+}
+
+func unsupportedBackendKindMsg(name string, kind BackendKind) string {
+	if kind.Kind == ServiceBackendKind.Kind {
+		return fmt.Sprintf("backendRef %s has unsupported group %s, must be the core group", name, kind.Group)
+	}
+	if kind.Group == "" {
+		return fmt.Sprintf("backendRef %s has unsupported kind %s", name, kind.Kind)
+	}
+	return fmt.Sprintf("backendRef %s has unsupported kind %s of group %s", name, kind.Kind, kind.Group)
+}
+
 // bindHTTPRouteToListeners tries to bind an HTTPRoute to listener.
 // There are three possibilities:
 // (1) HTTPRoute will be ignored.
 // (2) HTTPRoute will be processed but not bound.
 // (3) HTTPRoute will be processed and bound to a listener.
+// gateways are all Gateway resources, which tell whether a parentRef references a Gateway that doesn't exist.
+// Such parentRefs are only reported for the processed HTTPRoutes, because the HTTPRoutes that don't reference
+// the Gateways of the NGINX Gateway might belong to other Gateway implementations.
 func bindHTTPRouteToListeners(
 	ghr *v1beta1.HTTPRoute,
 	gw *v1beta1.Gateway,
 	ignoredGws map[types.NamespacedName]*v1beta1.Gateway,
+	gateways map[types.NamespacedName]*v1beta1.Gateway,
 	listeners map[string]*listener,
 ) (ignored bool, r *route) {
 	if len(ghr.Spec.ParentRefs) == 0 {
@@ -177,7 +418,7 @@ func bindHTTPRouteToListeners(
 	r = &route{
 		Source:                 ghr,
 		ValidSectionNameRefs:   make(map[string]struct{}),
-		InvalidSectionNameRefs: make(map[string]struct{}),
+		InvalidSectionNameRefs: make(map[string]ParentRefReason),
 	}
 
 	// FIXME (pleshakov) Handle the case when parent refs are duplicated
@@ -221,7 +462,15 @@ func bindHTTPRouteToListeners(
 
 			l, exists := listeners[name]
 			if !exists {
-				r.InvalidSectionNameRefs[name] = struct{}{}
+				r.InvalidSectionNameRefs[name] = ParentRefReasonNoMatchingParent
+				continue
+			}
+			if l.Source.Protocol == v1beta1.TCPProtocolType {
+				r.InvalidSectionNameRefs[name] = ParentRefReasonNotAllowedByListeners
+				continue
+			}
+			if !allowsRoute(l.Source, gw.Namespace, ghr.Namespace, "HTTPRoute") {
+				r.InvalidSectionNameRefs[name] = ParentRefReasonNotAllowedByListeners
 				continue
 			}
 
@@ -234,7 +483,7 @@ func bindHTTPRouteToListeners(
 				r.ValidSectionNameRefs[name] = struct{}{}
 				l.Routes[getNamespacedName(ghr)] = r
 			} else {
-				r.InvalidSectionNameRefs[name] = struct{}{}
+				r.InvalidSectionNameRefs[name] = ParentRefReasonNoMatchingListenerHostname
 			}
 
 			continue
@@ -245,7 +494,107 @@ func bindHTTPRouteToListeners(
 		key := types.NamespacedName{Namespace: ns, Name: string(p.Name)}
 
 		if _, exist := ignoredGws[key]; exist {
-			r.InvalidSectionNameRefs[name] = struct{}{}
+			r.InvalidSectionNameRefs[name] = ParentRefReasonGatewayConflict
+
+			processed = true
+			continue
+		}
+
+		// Case 3: the parentRef references a Gateway resource that doesn't exist.
+
+		if _, exist := gateways[key]; !exist && isGatewayParentRef(p) {
+			if r.MissingGatewayRefs == nil {
+				r.MissingGatewayRefs = make(map[types.NamespacedName][]string)
+			}
+			r.MissingGatewayRefs[key] = append(r.MissingGatewayRefs[key], name)
+
+			continue
+		}
+
+		// Case 4: the parentRef references some unrelated to this NGINX Gateway Gateway or other resource.
+
+		// Do nothing
+	}
+
+	if !processed {
+		return true, nil
+	}
+
+	return false, r
+}
+
+// isGatewayParentRef returns true if the parentRef references a Gateway resource, which is the default kind.
+func isGatewayParentRef(p v1beta1.ParentReference) bool {
+	return (p.Group == nil || *p.Group == v1beta1.GroupName) && (p.Kind == nil || *p.Kind == "Gateway")
+}
+
+// bindTCPRouteToListeners tries to bind a TCPRoute to a TCP listener.
+// The possibilities are the same as for bindHTTPRouteToListeners. Unlike an HTTPRoute, a TCPRoute doesn't have
+// hostnames, so it binds to any TCP listener its parentRefs reference.
+func bindTCPRouteToListeners(
+	tr *v1alpha2.TCPRoute,
+	gw *v1beta1.Gateway,
+	ignoredGws map[types.NamespacedName]*v1beta1.Gateway,
+	listeners map[string]*listener,
+) (ignored bool, r *tcpRoute) {
+	if len(tr.Spec.ParentRefs) == 0 {
+		// ignore TCPRoute without refs
+		return true, nil
+	}
+
+	r = &tcpRoute{
+		Source:                 tr,
+		ValidSectionNameRefs:   make(map[string]struct{}),
+		InvalidSectionNameRefs: make(map[string]ParentRefReason),
+	}
+
+	processed := false
+
+	for _, p := range tr.Spec.ParentRefs {
+		// FIXME(pleshakov) Support empty section name
+		if p.SectionName == nil || *p.SectionName == "" {
+			continue
+		}
+
+		// if the namespace is missing, assume the namespace of the TCPRoute
+		ns := tr.Namespace
+		if p.Namespace != nil {
+			ns = string(*p.Namespace)
+		}
+
+		name := string(*p.SectionName)
+
+		// Case 1: the parentRef references the winning Gateway.
+
+		if gw != nil && gw.Namespace == ns && gw.Name == string(p.Name) {
+			processed = true
+
+			l, exists := listeners[name]
+			if !exists {
+				r.InvalidSectionNameRefs[name] = ParentRefReasonNoMatchingParent
+				continue
+			}
+			if l.Source.Protocol != v1beta1.TCPProtocolType {
+				r.InvalidSectionNameRefs[name] = ParentRefReasonNotAllowedByListeners
+				continue
+			}
+			if !allowsRoute(l.Source, gw.Namespace, tr.Namespace, "TCPRoute") {
+				r.InvalidSectionNameRefs[name] = ParentRefReasonNotAllowedByListeners
+				continue
+			}
+
+			r.ValidSectionNameRefs[name] = struct{}{}
+			l.TCPRoutes[getNamespacedName(tr)] = r
+
+			continue
+		}
+
+		// Case 2: the parentRef references an ignored Gateway resource.
+
+		key := types.NamespacedName{Namespace: ns, Name: string(p.Name)}
+
+		if _, exist := ignoredGws[key]; exist {
+			r.InvalidSectionNameRefs[name] = ParentRefReasonGatewayConflict
 
 			processed = true
 			continue
@@ -263,27 +612,97 @@ func bindHTTPRouteToListeners(
 	return false, r
 }
 
+// resolveTCPRouteConflicts detaches all TCPRoutes but the oldest one from every TCP listener, because a stream server
+// can only proxy to one destination. The detached TCPRoutes are rejected by the listener with
+// ParentRefReasonRouteConflict.
+func resolveTCPRouteConflicts(listeners map[string]*listener) {
+	for name, l := range listeners {
+		if len(l.TCPRoutes) <= 1 {
+			continue
+		}
+
+		var winner *tcpRoute
+		for _, r := range l.TCPRoutes {
+			if winner == nil || lessObjectMeta(&r.Source.ObjectMeta, &winner.Source.ObjectMeta) {
+				winner = r
+			}
+		}
+
+		for nsname, r := range l.TCPRoutes {
+			if r == winner {
+				continue
+			}
+
+			delete(l.TCPRoutes, nsname)
+			delete(r.ValidSectionNameRefs, name)
+			r.InvalidSectionNameRefs[name] = ParentRefReasonRouteConflict
+		}
+	}
+}
+
+// findAcceptedHostnames returns the intersection of the listener hostname and the route hostnames.
+// A wildcard hostname (for example, *.example.com) on either side matches the hostnames that share its suffix;
+// the more specific hostname of the two is accepted.
+// A route without hostnames inherits the listener hostname, for both HTTP and HTTPS listeners.
 func findAcceptedHostnames(listenerHostname *v1beta1.Hostname, routeHostnames []v1beta1.Hostname) []string {
 	hostname := getHostname(listenerHostname)
 
-	match := func(h v1beta1.Hostname) bool {
+	if len(routeHostnames) == 0 {
+		// FIXME(pleshakov): A route without hostnames attached to a listener without a hostname must match
+		// all hostnames. For now, such a route is not accepted.
 		if hostname == "" {
-			return true
+			return nil
 		}
-		return string(h) == hostname
+		return []string{hostname}
 	}
 
 	var result []string
+	seen := make(map[string]struct{})
 
 	for _, h := range routeHostnames {
-		if match(h) {
-			result = append(result, string(h))
+		accepted, ok := intersectHostnames(hostname, string(h))
+		if !ok {
+			continue
 		}
+
+		if _, exist := seen[accepted]; exist {
+			continue
+		}
+		seen[accepted] = struct{}{}
+
+		result = append(result, accepted)
 	}
 
 	return result
 }
 
+// intersectHostnames returns the hostname that matches both the listener and the route hostnames.
+// An empty listener hostname matches any route hostname.
+func intersectHostnames(listenerHostname, routeHostname string) (string, bool) {
+	switch {
+	case listenerHostname == "", listenerHostname == routeHostname:
+		return routeHostname, true
+	case matchesWildcardHostname(routeHostname, listenerHostname):
+		return routeHostname, true
+	case matchesWildcardHostname(listenerHostname, routeHostname):
+		return listenerHostname, true
+	}
+
+	return "", false
+}
+
+// matchesWildcardHostname checks if the hostname matches the wildcard hostname.
+// For example, foo.example.com and *.foo.example.com match *.example.com, but example.com doesn't.
+func matchesWildcardHostname(hostname, wildcard string) bool {
+	if !strings.HasPrefix(wildcard, "*.") {
+		return false
+	}
+
+	suffix := wildcard[1:]
+
+	return len(hostname) > len(suffix) && strings.HasSuffix(hostname, suffix)
+}
+
 func getHostname(h *v1beta1.Hostname) string {
 	if h == nil {
 		return ""
@@ -298,3 +717,38 @@ func validateGatewayClass(gc *v1beta1.GatewayClass, controllerName string) error
 
 	return nil
 }
+
+// validateGatewayAddresses splits the addresses of the Gateway into the IP addresses NGINX can listen on and
+// the rejected ones. NGINX can only listen on the allowedAddresses, so the addresses of the other types, like
+// Hostname, the invalid IP addresses and the IP addresses that are not among the allowedAddresses are rejected.
+func validateGatewayAddresses(gw *v1beta1.Gateway, allowedAddresses []string) (valid []string, rejected []string) {
+	for _, a := range gw.Spec.Addresses {
+		// the type defaults to IPAddress
+		if a.Type != nil && *a.Type != v1beta1.IPAddressType {
+			rejected = append(rejected, a.Value)
+			continue
+		}
+
+		ip := net.ParseIP(a.Value)
+		if ip == nil || !containsIP(allowedAddresses, ip) {
+			rejected = append(rejected, a.Value)
+			continue
+		}
+
+		valid = append(valid, a.Value)
+	}
+
+	return valid, rejected
+}
+
+// containsIP returns true if the addresses include the IP. The addresses are compared as IPs, so that the different
+// notations of the same IPv6 address match.
+func containsIP(addresses []string, ip net.IP) bool {
+	for _, a := range addresses {
+		if ip.Equal(net.ParseIP(a)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,12 +2,16 @@
 package state
 
 import (
+	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/helpers"
@@ -71,7 +75,10 @@ cpLlHMAqbLJ8WYGJCkhiWxyal6hYTyWY4cVkC0xtTl/hUE9IeNKo
 }
 
 var (
-	secretPath       = "/etc/nginx/secrets/test_secret"
+	secretPaths = SecretPaths{
+		Certificate: "/etc/nginx/secrets/test_secret.crt",
+		Key:         "/etc/nginx/secrets/test_secret.key",
+	}
 	secretsDirectory = "/etc/nginx/secrets"
 )
 
@@ -119,6 +126,29 @@ func TestBuildGraph(t *testing.T) {
 	hr2 := createRoute("hr-2", "wrong-gateway", "listener-80-1")
 	hr3 := createRoute("hr-3", "gateway-1", "listener-443-1") // https listener; should not conflict with hr1
 
+	tr1 := &v1alpha2.TCPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "tr-1",
+		},
+		Spec: v1alpha2.TCPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{
+				ParentRefs: []v1alpha2.ParentReference{
+					{
+						Namespace:   (*v1alpha2.Namespace)(helpers.GetStringPointer("test")),
+						Name:        "gateway-1",
+						SectionName: (*v1alpha2.SectionName)(helpers.GetStringPointer("listener-8080-1")),
+					},
+					{
+						Namespace:   (*v1alpha2.Namespace)(helpers.GetStringPointer("test")),
+						Name:        "gateway-1",
+						SectionName: (*v1alpha2.SectionName)(helpers.GetStringPointer("listener-80-1")), // not a tcp listener
+					},
+				},
+			},
+		},
+	}
+
 	createGateway := func(name string) *v1beta1.Gateway {
 		return &v1beta1.Gateway{
 			ObjectMeta: metav1.ObjectMeta{
@@ -151,6 +181,12 @@ func TestBuildGraph(t *testing.T) {
 						},
 						Protocol: v1beta1.HTTPSProtocolType,
 					},
+
+					{
+						Name:     "listener-8080-1",
+						Port:     8080,
+						Protocol: v1beta1.TCPProtocolType,
+					},
 				},
 			},
 		}
@@ -174,6 +210,9 @@ func TestBuildGraph(t *testing.T) {
 			{Namespace: "test", Name: "hr-2"}: hr2,
 			{Namespace: "test", Name: "hr-3"}: hr3,
 		},
+		tcpRoutes: map[types.NamespacedName]*v1alpha2.TCPRoute{
+			{Namespace: "test", Name: "tr-1"}: tr1,
+		},
 	}
 
 	routeHR1 := &route{
@@ -181,7 +220,7 @@ func TestBuildGraph(t *testing.T) {
 		ValidSectionNameRefs: map[string]struct{}{
 			"listener-80-1": {},
 		},
-		InvalidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
 	}
 
 	routeHR3 := &route{
@@ -189,7 +228,17 @@ func TestBuildGraph(t *testing.T) {
 		ValidSectionNameRefs: map[string]struct{}{
 			"listener-443-1": {},
 		},
-		InvalidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{},
+	}
+
+	routeTR1 := &tcpRoute{
+		Source: tr1,
+		ValidSectionNameRefs: map[string]struct{}{
+			"listener-8080-1": {},
+		},
+		InvalidSectionNameRefs: map[string]ParentRefReason{
+			"listener-80-1": ParentRefReasonNotAllowedByListeners,
+		},
 	}
 
 	expected := &graph{
@@ -219,7 +268,16 @@ func TestBuildGraph(t *testing.T) {
 					AcceptedHostnames: map[string]struct{}{
 						"foo.example.com": {},
 					},
-					SecretPath: secretPath,
+					SecretPaths: secretPaths,
+				},
+				"listener-8080-1": {
+					Source:            gw1.Spec.Listeners[2],
+					Valid:             true,
+					Routes:            map[types.NamespacedName]*route{},
+					AcceptedHostnames: map[string]struct{}{},
+					TCPRoutes: map[types.NamespacedName]*tcpRoute{
+						{Namespace: "test", Name: "tr-1"}: routeTR1,
+					},
 				},
 			},
 		},
@@ -230,6 +288,9 @@ func TestBuildGraph(t *testing.T) {
 			{Namespace: "test", Name: "hr-1"}: routeHR1,
 			{Namespace: "test", Name: "hr-3"}: routeHR3,
 		},
+		TCPRoutes: map[types.NamespacedName]*tcpRoute{
+			{Namespace: "test", Name: "tr-1"}: routeTR1,
+		},
 	}
 
 	// add test secret to store
@@ -238,7 +299,17 @@ func TestBuildGraph(t *testing.T) {
 
 	secretMemoryMgr := NewSecretDiskMemoryManager(secretsDirectory, secretStore)
 
-	result := buildGraph(store, controllerName, gcName, secretMemoryMgr)
+	result := buildGraph(
+		context.Background(),
+		store,
+		controllerName,
+		gcName,
+		secretMemoryMgr,
+		NewServiceStore(),
+		nil,
+		DefaultResolveTimeout,
+		nil,
+	)
 	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("buildGraph() mismatch (-want +got):\n%s", diff)
 	}
@@ -321,6 +392,55 @@ func TestProcessGateways(t *testing.T) {
 	}
 }
 
+func TestProcessIgnoredGatewayClasses(t *testing.T) {
+	const controllerName = "my.controller"
+
+	conflicting := &v1beta1.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "conflicting"},
+		Spec:       v1beta1.GatewayClassSpec{ControllerName: controllerName},
+	}
+	unrelated := &v1beta1.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "unrelated"},
+		Spec:       v1beta1.GatewayClassSpec{ControllerName: "other.controller"},
+	}
+
+	tests := []struct {
+		gcs      map[string]*v1beta1.GatewayClass
+		expected map[string]*v1beta1.GatewayClass
+		msg      string
+	}{
+		{
+			gcs:      nil,
+			expected: nil,
+			msg:      "no gatewayclasses",
+		},
+		{
+			gcs: map[string]*v1beta1.GatewayClass{
+				"unrelated": unrelated,
+			},
+			expected: nil,
+			msg:      "unrelated gatewayclass",
+		},
+		{
+			gcs: map[string]*v1beta1.GatewayClass{
+				"conflicting": conflicting,
+				"unrelated":   unrelated,
+			},
+			expected: map[string]*v1beta1.GatewayClass{
+				"conflicting": conflicting,
+			},
+			msg: "conflicting gatewayclass",
+		},
+	}
+
+	for _, test := range tests {
+		result := processIgnoredGatewayClasses(test.gcs, controllerName)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("processIgnoredGatewayClasses() '%s' mismatch (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
 func TestBuildGatewayClass(t *testing.T) {
 	const controllerName = "my.controller"
 
@@ -386,7 +506,7 @@ func TestBuildListeners(t *testing.T) {
 		Name:     "listener-80-2",
 		Hostname: (*v1beta1.Hostname)(helpers.GetStringPointer("bar.example.com")),
 		Port:     80,
-		Protocol: v1beta1.TCPProtocolType, // invalid protocol
+		Protocol: v1beta1.UDPProtocolType, // invalid protocol
 	}
 	listener803 := v1beta1.Listener{
 		Name:     "listener-80-3",
@@ -401,6 +521,34 @@ func TestBuildListeners(t *testing.T) {
 		Protocol: v1beta1.HTTPProtocolType,
 	}
 
+	// tcp listeners
+	listener80801 := v1beta1.Listener{
+		Name:     "listener-8080-1",
+		Port:     8080,
+		Protocol: v1beta1.TCPProtocolType,
+	}
+	listener80802 := v1beta1.Listener{
+		Name:     "listener-8080-2",
+		Port:     8080,
+		Protocol: v1beta1.TCPProtocolType,
+	}
+	listener805 := v1beta1.Listener{
+		Name:     "listener-80-5",
+		Port:     80,
+		Protocol: v1beta1.TCPProtocolType, // invalid tcp listener; port 80 is reserved for http
+	}
+	fromSelector := v1beta1.NamespacesFromSelector
+	listener806 := v1beta1.Listener{
+		Name:     "listener-80-6",
+		Port:     80,
+		Protocol: v1beta1.HTTPProtocolType,
+		AllowedRoutes: &v1beta1.AllowedRoutes{
+			Namespaces: &v1beta1.RouteNamespaces{
+				From: &fromSelector, // unsupported
+			},
+		},
+	}
+
 	gatewayTLSConfig := &v1beta1.GatewayTLSConfig{
 		Mode: helpers.GetTLSModePointer(v1beta1.TLSModeTerminate),
 		CertificateRefs: []v1beta1.SecretObjectReference{
@@ -422,6 +570,16 @@ func TestBuildListeners(t *testing.T) {
 			},
 		},
 	}
+	tlsConfigSecretInMissingNamespace := &v1beta1.GatewayTLSConfig{
+		Mode: helpers.GetTLSModePointer(v1beta1.TLSModeTerminate),
+		CertificateRefs: []v1beta1.SecretObjectReference{
+			{
+				Kind:      (*v1beta1.Kind)(helpers.GetStringPointer("Secret")),
+				Name:      "secret",
+				Namespace: (*v1beta1.Namespace)(helpers.GetStringPointer("missing")),
+			},
+		},
+	}
 	// https listeners
 	listener4431 := v1beta1.Listener{
 		Name:     "listener-443-1",
@@ -458,6 +616,26 @@ func TestBuildListeners(t *testing.T) {
 		TLS:      tlsConfigInvalidSecret, // invalid https listener; secret does not exist
 		Protocol: v1beta1.HTTPSProtocolType,
 	}
+	listener807 := v1beta1.Listener{
+		Name:     "listener-80-7",
+		Hostname: (*v1beta1.Hostname)(helpers.GetStringPointer("foo.example.com")),
+		Port:     80,
+		TLS:      gatewayTLSConfig,
+		Protocol: v1beta1.HTTPSProtocolType, // conflicts with the http listeners on the same port
+	}
+	listener4436 := v1beta1.Listener{
+		Name:     "listener-443-6",
+		Hostname: (*v1beta1.Hostname)(helpers.GetStringPointer("foo.example.com")),
+		Port:     443,
+		Protocol: v1beta1.TLSProtocolType, // unsupported protocol
+	}
+	listener4437 := v1beta1.Listener{
+		Name:     "listener-443-7",
+		Hostname: (*v1beta1.Hostname)(helpers.GetStringPointer("bar.example.com")),
+		Port:     443,
+		TLS:      tlsConfigSecretInMissingNamespace, // invalid https listener; secret in a missing namespace
+		Protocol: v1beta1.HTTPSProtocolType,
+	}
 	tests := []struct {
 		gateway  *v1beta1.Gateway
 		expected map[string]*listener
@@ -486,6 +664,29 @@ func TestBuildListeners(t *testing.T) {
 			},
 			msg: "valid http listener",
 		},
+		{
+			gateway: &v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+				},
+				Spec: v1beta1.GatewaySpec{
+					GatewayClassName: gcName,
+					Listeners: []v1beta1.Listener{
+						listener806,
+					},
+				},
+			},
+			expected: map[string]*listener{
+				"listener-80-6": {
+					Source:              listener806,
+					Valid:               true,
+					Routes:              map[types.NamespacedName]*route{},
+					AcceptedHostnames:   map[string]struct{}{},
+					UnsupportedSelector: true,
+				},
+			},
+			msg: "http listener with selector namespaces",
+		},
 		{
 			gateway: &v1beta1.Gateway{
 				ObjectMeta: metav1.ObjectMeta{
@@ -504,7 +705,7 @@ func TestBuildListeners(t *testing.T) {
 					Valid:             true,
 					Routes:            map[types.NamespacedName]*route{},
 					AcceptedHostnames: map[string]struct{}{},
-					SecretPath:        secretPath,
+					SecretPaths:       secretPaths,
 				},
 			},
 			msg: "valid https listener",
@@ -523,13 +724,136 @@ func TestBuildListeners(t *testing.T) {
 			},
 			expected: map[string]*listener{
 				"listener-80-2": {
-					Source:            listener802,
+					Source:              listener802,
+					Valid:               false,
+					UnsupportedProtocol: true,
+					Routes:              map[types.NamespacedName]*route{},
+					AcceptedHostnames:   map[string]struct{}{},
+				},
+			},
+			msg: "invalid listener protocol",
+		},
+		{
+			gateway: &v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+				},
+				Spec: v1beta1.GatewaySpec{
+					GatewayClassName: gcName,
+					Listeners: []v1beta1.Listener{
+						listener801, listener802, listener4431, listener4436,
+					},
+				},
+			},
+			expected: map[string]*listener{
+				"listener-80-1": {
+					Source:            listener801,
+					Valid:             true,
+					Routes:            map[types.NamespacedName]*route{},
+					AcceptedHostnames: map[string]struct{}{},
+				},
+				"listener-80-2": {
+					Source:              listener802,
+					Valid:               false,
+					UnsupportedProtocol: true,
+					Routes:              map[types.NamespacedName]*route{},
+					AcceptedHostnames:   map[string]struct{}{},
+				},
+				"listener-443-1": {
+					Source:            listener4431,
+					Valid:             true,
+					Routes:            map[types.NamespacedName]*route{},
+					AcceptedHostnames: map[string]struct{}{},
+					SecretPaths:       secretPaths,
+				},
+				"listener-443-6": {
+					Source:              listener4436,
+					Valid:               false,
+					UnsupportedProtocol: true,
+					Routes:              map[types.NamespacedName]*route{},
+					AcceptedHostnames:   map[string]struct{}{},
+				},
+			},
+			// the listeners with unsupported protocols don't conflict with the listeners on the same ports
+			msg: "unsupported listener protocols on the ports of the supported listeners",
+		},
+		{
+			gateway: &v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+				},
+				Spec: v1beta1.GatewaySpec{
+					GatewayClassName: gcName,
+					Listeners: []v1beta1.Listener{
+						listener80801,
+					},
+				},
+			},
+			expected: map[string]*listener{
+				"listener-8080-1": {
+					Source:            listener80801,
+					Valid:             true,
+					Routes:            map[types.NamespacedName]*route{},
+					AcceptedHostnames: map[string]struct{}{},
+					TCPRoutes:         map[types.NamespacedName]*tcpRoute{},
+				},
+			},
+			msg: "valid tcp listener",
+		},
+		{
+			gateway: &v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+				},
+				Spec: v1beta1.GatewaySpec{
+					GatewayClassName: gcName,
+					Listeners: []v1beta1.Listener{
+						listener805,
+					},
+				},
+			},
+			expected: map[string]*listener{
+				"listener-80-5": {
+					Source:            listener805,
 					Valid:             false,
 					Routes:            map[types.NamespacedName]*route{},
 					AcceptedHostnames: map[string]struct{}{},
+					TCPRoutes:         map[types.NamespacedName]*tcpRoute{},
 				},
 			},
-			msg: "invalid listener protocol",
+			msg: "invalid tcp listener (reserved port)",
+		},
+		{
+			gateway: &v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+				},
+				Spec: v1beta1.GatewaySpec{
+					GatewayClassName: gcName,
+					Listeners: []v1beta1.Listener{
+						listener80801, listener80802,
+					},
+				},
+			},
+			expected: map[string]*listener{
+				"listener-8080-1": {
+					Source:            listener80801,
+					Valid:             false,
+					Conflict:          ListenerConflictReasonHostname,
+					Routes:            map[types.NamespacedName]*route{},
+					AcceptedHostnames: map[string]struct{}{},
+					TCPRoutes:         map[types.NamespacedName]*tcpRoute{},
+				},
+				"listener-8080-2": {
+					Source:            listener80802,
+					Valid:             false,
+					Conflict:          ListenerConflictReasonHostname,
+					Routes:            map[types.NamespacedName]*route{},
+					AcceptedHostnames: map[string]struct{}{},
+					TCPRoutes:         map[types.NamespacedName]*tcpRoute{},
+				},
+			},
+			msg: "tcp port collisions",
 		},
 		{
 			gateway: &v1beta1.Gateway{
@@ -569,12 +893,41 @@ func TestBuildListeners(t *testing.T) {
 				"listener-443-5": {
 					Source:            listener4435,
 					Valid:             false,
+					UnresolvedRefs:    true,
 					Routes:            map[types.NamespacedName]*route{},
 					AcceptedHostnames: map[string]struct{}{},
 				},
 			},
 			msg: "invalid https listener (secret does not exist)",
 		},
+		{
+			gateway: &v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+				},
+				Spec: v1beta1.GatewaySpec{
+					GatewayClassName: gcName,
+					Listeners: []v1beta1.Listener{
+						listener801, listener4437,
+					},
+				},
+			},
+			expected: map[string]*listener{
+				"listener-80-1": {
+					Source:            listener801,
+					Valid:             true,
+					Routes:            map[types.NamespacedName]*route{},
+					AcceptedHostnames: map[string]struct{}{},
+				},
+				"listener-443-7": {
+					Source:            listener4437,
+					Valid:             false,
+					Routes:            map[types.NamespacedName]*route{},
+					AcceptedHostnames: map[string]struct{}{},
+				},
+			},
+			msg: "invalid https listener (secret in a missing namespace) and valid http listener",
+		},
 		{
 			gateway: &v1beta1.Gateway{
 				ObjectMeta: metav1.ObjectMeta{
@@ -606,14 +959,14 @@ func TestBuildListeners(t *testing.T) {
 					Valid:             true,
 					Routes:            map[types.NamespacedName]*route{},
 					AcceptedHostnames: map[string]struct{}{},
-					SecretPath:        secretPath,
+					SecretPaths:       secretPaths,
 				},
 				"listener-443-2": {
 					Source:            listener4432,
 					Valid:             true,
 					Routes:            map[types.NamespacedName]*route{},
 					AcceptedHostnames: map[string]struct{}{},
-					SecretPath:        secretPath,
+					SecretPaths:       secretPaths,
 				},
 			},
 			msg: "multiple valid http/https listeners",
@@ -635,32 +988,66 @@ func TestBuildListeners(t *testing.T) {
 				"listener-80-1": {
 					Source:            listener801,
 					Valid:             false,
+					Conflict:          ListenerConflictReasonHostname,
 					Routes:            map[types.NamespacedName]*route{},
 					AcceptedHostnames: map[string]struct{}{},
 				},
 				"listener-80-4": {
 					Source:            listener804,
 					Valid:             false,
+					Conflict:          ListenerConflictReasonHostname,
 					Routes:            map[types.NamespacedName]*route{},
 					AcceptedHostnames: map[string]struct{}{},
 				},
 				"listener-443-1": {
 					Source:            listener4431,
 					Valid:             false,
+					Conflict:          ListenerConflictReasonHostname,
 					Routes:            map[types.NamespacedName]*route{},
 					AcceptedHostnames: map[string]struct{}{},
-					SecretPath:        secretPath,
+					SecretPaths:       secretPaths,
 				},
 				"listener-443-3": {
 					Source:            listener4433,
 					Valid:             false,
+					Conflict:          ListenerConflictReasonHostname,
 					Routes:            map[types.NamespacedName]*route{},
 					AcceptedHostnames: map[string]struct{}{},
-					SecretPath:        secretPath,
+					SecretPaths:       secretPaths,
 				},
 			},
 			msg: "collisions",
 		},
+		{
+			gateway: &v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+				},
+				Spec: v1beta1.GatewaySpec{
+					GatewayClassName: gcName,
+					Listeners: []v1beta1.Listener{
+						listener801, listener807,
+					},
+				},
+			},
+			expected: map[string]*listener{
+				"listener-80-1": {
+					Source:            listener801,
+					Valid:             false,
+					Conflict:          ListenerConflictReasonProtocol,
+					Routes:            map[types.NamespacedName]*route{},
+					AcceptedHostnames: map[string]struct{}{},
+				},
+				"listener-80-7": {
+					Source:            listener807,
+					Valid:             false,
+					Conflict:          ListenerConflictReasonProtocol,
+					Routes:            map[types.NamespacedName]*route{},
+					AcceptedHostnames: map[string]struct{}{},
+				},
+			},
+			msg: "http and https listeners on the same port",
+		},
 		{
 			gateway:  nil,
 			expected: map[string]*listener{},
@@ -722,6 +1109,27 @@ func TestBindRouteToListeners(t *testing.T) {
 		SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("listener-80-2")),
 	})
 
+	hrMissingGateway := createRoute(
+		"foo.example.com",
+		v1beta1.ParentReference{
+			Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+			Name:        "gateway",
+			SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("listener-80-1")),
+		},
+		v1beta1.ParentReference{
+			Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+			Name:        "missing-gateway",
+			SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("listener-80-1")),
+		},
+		v1beta1.ParentReference{
+			Group:       (*v1beta1.Group)(helpers.GetStringPointer("example.com")),
+			Kind:        (*v1beta1.Kind)(helpers.GetStringPointer("Mesh")),
+			Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+			Name:        "mesh",
+			SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("listener-80-1")),
+		},
+	)
+
 	hrEmptySectionName := createRoute("foo.example.com", v1beta1.ParentReference{
 		Namespace: (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
 		Name:      "gateway",
@@ -750,6 +1158,27 @@ func TestBindRouteToListeners(t *testing.T) {
 		SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("listener-80-1")),
 	})
 
+	hrFooHTTPAndHTTPS := createRoute(
+		"foo.example.com",
+		v1beta1.ParentReference{
+			Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+			Name:        "gateway",
+			SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("listener-80-1")),
+		},
+		v1beta1.ParentReference{
+			Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+			Name:        "gateway",
+			SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("listener-443-1")),
+		},
+	)
+
+	hrFooOtherNamespace := createRoute("foo.example.com", v1beta1.ParentReference{
+		Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+		Name:        "gateway",
+		SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("listener-80-1")),
+	})
+	hrFooOtherNamespace.Namespace = "other"
+
 	// we create a new listener each time because the function under test can modify it
 	createListener := func() *listener {
 		return &listener{
@@ -828,8 +1257,8 @@ func TestBindRouteToListeners(t *testing.T) {
 			expectedRoute: &route{
 				Source:               hrNonExistingSectionName,
 				ValidSectionNameRefs: map[string]struct{}{},
-				InvalidSectionNameRefs: map[string]struct{}{
-					"listener-80-2": {},
+				InvalidSectionNameRefs: map[string]ParentRefReason{
+					"listener-80-2": ParentRefReasonNoMatchingParent,
 				},
 			},
 			expectedListeners: map[string]*listener{
@@ -838,19 +1267,74 @@ func TestBindRouteToListeners(t *testing.T) {
 			msg: "HTTPRoute with non-existing section name",
 		},
 		{
-			httpRoute:  hrEmptySectionName,
+			httpRoute:  hrMissingGateway,
 			gw:         gw,
 			ignoredGws: nil,
 			listeners: map[string]*listener{
 				"listener-80-1": createListener(),
 			},
-			expectedIgnored: true,
-			expectedRoute:   nil,
-			expectedListeners: map[string]*listener{
-				"listener-80-1": createListener(),
-			},
-			msg: "HTTPRoute with empty section name",
-		},
+			expectedIgnored: false,
+			expectedRoute: &route{
+				Source: hrMissingGateway,
+				ValidSectionNameRefs: map[string]struct{}{
+					"listener-80-1": {},
+				},
+				InvalidSectionNameRefs: map[string]ParentRefReason{},
+				MissingGatewayRefs: map[types.NamespacedName][]string{
+					{Namespace: "test", Name: "missing-gateway"}: {"listener-80-1"},
+				},
+			},
+			expectedListeners: map[string]*listener{
+				"listener-80-1": createModifiedListener(func(l *listener) {
+					l.Routes = map[types.NamespacedName]*route{
+						{Namespace: "test", Name: "hr-1"}: {
+							Source: hrMissingGateway,
+							ValidSectionNameRefs: map[string]struct{}{
+								"listener-80-1": {},
+							},
+							InvalidSectionNameRefs: map[string]ParentRefReason{},
+							MissingGatewayRefs: map[types.NamespacedName][]string{
+								{Namespace: "test", Name: "missing-gateway"}: {"listener-80-1"},
+							},
+						},
+					}
+					l.AcceptedHostnames = map[string]struct{}{
+						"foo.example.com": {},
+					}
+				}),
+			},
+			msg: "HTTPRoute referencing a non-existing gateway",
+		},
+		{
+			httpRoute:       hrFoo,
+			gw:              gw,
+			ignoredGws:      nil,
+			listeners:       map[string]*listener{},
+			expectedIgnored: false,
+			expectedRoute: &route{
+				Source:               hrFoo,
+				ValidSectionNameRefs: map[string]struct{}{},
+				InvalidSectionNameRefs: map[string]ParentRefReason{
+					"listener-80-1": ParentRefReasonNoMatchingParent,
+				},
+			},
+			expectedListeners: map[string]*listener{},
+			msg:               "HTTPRoute referencing a gateway without listeners",
+		},
+		{
+			httpRoute:  hrEmptySectionName,
+			gw:         gw,
+			ignoredGws: nil,
+			listeners: map[string]*listener{
+				"listener-80-1": createListener(),
+			},
+			expectedIgnored: true,
+			expectedRoute:   nil,
+			expectedListeners: map[string]*listener{
+				"listener-80-1": createListener(),
+			},
+			msg: "HTTPRoute with empty section name",
+		},
 		{
 			httpRoute:  hrFoo,
 			gw:         gw,
@@ -864,7 +1348,7 @@ func TestBindRouteToListeners(t *testing.T) {
 				ValidSectionNameRefs: map[string]struct{}{
 					"listener-80-1": {},
 				},
-				InvalidSectionNameRefs: map[string]struct{}{},
+				InvalidSectionNameRefs: map[string]ParentRefReason{},
 			},
 			expectedListeners: map[string]*listener{
 				"listener-80-1": createModifiedListener(func(l *listener) {
@@ -874,7 +1358,7 @@ func TestBindRouteToListeners(t *testing.T) {
 							ValidSectionNameRefs: map[string]struct{}{
 								"listener-80-1": {},
 							},
-							InvalidSectionNameRefs: map[string]struct{}{},
+							InvalidSectionNameRefs: map[string]ParentRefReason{},
 						},
 					}
 					l.AcceptedHostnames = map[string]struct{}{
@@ -897,7 +1381,7 @@ func TestBindRouteToListeners(t *testing.T) {
 				ValidSectionNameRefs: map[string]struct{}{
 					"listener-80-1": {},
 				},
-				InvalidSectionNameRefs: map[string]struct{}{},
+				InvalidSectionNameRefs: map[string]ParentRefReason{},
 			},
 			expectedListeners: map[string]*listener{
 				"listener-80-1": createModifiedListener(func(l *listener) {
@@ -907,7 +1391,7 @@ func TestBindRouteToListeners(t *testing.T) {
 							ValidSectionNameRefs: map[string]struct{}{
 								"listener-80-1": {},
 							},
-							InvalidSectionNameRefs: map[string]struct{}{},
+							InvalidSectionNameRefs: map[string]ParentRefReason{},
 						},
 					}
 					l.AcceptedHostnames = map[string]struct{}{
@@ -928,8 +1412,8 @@ func TestBindRouteToListeners(t *testing.T) {
 			expectedRoute: &route{
 				Source:               hrBar,
 				ValidSectionNameRefs: map[string]struct{}{},
-				InvalidSectionNameRefs: map[string]struct{}{
-					"listener-80-1": {},
+				InvalidSectionNameRefs: map[string]ParentRefReason{
+					"listener-80-1": ParentRefReasonNoMatchingListenerHostname,
 				},
 			},
 			expectedListeners: map[string]*listener{
@@ -937,6 +1421,58 @@ func TestBindRouteToListeners(t *testing.T) {
 			},
 			msg: "HTTPRoute with zero accepted hostnames",
 		},
+		{
+			httpRoute:  hrFooOtherNamespace,
+			gw:         gw,
+			ignoredGws: nil,
+			listeners: map[string]*listener{
+				"listener-80-1": createListener(),
+			},
+			expectedIgnored: false,
+			expectedRoute: &route{
+				Source:               hrFooOtherNamespace,
+				ValidSectionNameRefs: map[string]struct{}{},
+				InvalidSectionNameRefs: map[string]ParentRefReason{
+					"listener-80-1": ParentRefReasonNotAllowedByListeners,
+				},
+			},
+			expectedListeners: map[string]*listener{
+				"listener-80-1": createListener(),
+			},
+			msg: "HTTPRoute from a namespace not allowed by the listener",
+		},
+		{
+			httpRoute:  hrFoo,
+			gw:         gw,
+			ignoredGws: nil,
+			listeners: map[string]*listener{
+				"listener-80-1": createModifiedListener(func(l *listener) {
+					l.Source.AllowedRoutes = &v1beta1.AllowedRoutes{
+						Kinds: []v1beta1.RouteGroupKind{
+							{Kind: "TCPRoute"},
+						},
+					}
+				}),
+			},
+			expectedIgnored: false,
+			expectedRoute: &route{
+				Source:               hrFoo,
+				ValidSectionNameRefs: map[string]struct{}{},
+				InvalidSectionNameRefs: map[string]ParentRefReason{
+					"listener-80-1": ParentRefReasonNotAllowedByListeners,
+				},
+			},
+			expectedListeners: map[string]*listener{
+				"listener-80-1": createModifiedListener(func(l *listener) {
+					l.Source.AllowedRoutes = &v1beta1.AllowedRoutes{
+						Kinds: []v1beta1.RouteGroupKind{
+							{Kind: "TCPRoute"},
+						},
+					}
+				}),
+			},
+			msg: "HTTPRoute of a kind not allowed by the listener",
+		},
 		{
 			httpRoute: hrIgnoredGateway,
 			gw:        gw,
@@ -950,8 +1486,8 @@ func TestBindRouteToListeners(t *testing.T) {
 			expectedRoute: &route{
 				Source:               hrIgnoredGateway,
 				ValidSectionNameRefs: map[string]struct{}{},
-				InvalidSectionNameRefs: map[string]struct{}{
-					"listener-80-1": {},
+				InvalidSectionNameRefs: map[string]ParentRefReason{
+					"listener-80-1": ParentRefReasonGatewayConflict,
 				},
 			},
 			expectedListeners: map[string]*listener{
@@ -959,6 +1495,100 @@ func TestBindRouteToListeners(t *testing.T) {
 			},
 			msg: "HTTPRoute with ignored gateway reference",
 		},
+		{
+			httpRoute:  hrFooHTTPAndHTTPS,
+			gw:         gw,
+			ignoredGws: nil,
+			listeners: map[string]*listener{
+				"listener-80-1":  createListener(),
+				"listener-443-1": createListener(),
+			},
+			expectedIgnored: false,
+			expectedRoute: &route{
+				Source: hrFooHTTPAndHTTPS,
+				ValidSectionNameRefs: map[string]struct{}{
+					"listener-80-1":  {},
+					"listener-443-1": {},
+				},
+				InvalidSectionNameRefs: map[string]ParentRefReason{},
+			},
+			expectedListeners: map[string]*listener{
+				"listener-80-1": createModifiedListener(func(l *listener) {
+					l.Routes = map[types.NamespacedName]*route{
+						{Namespace: "test", Name: "hr-1"}: {
+							Source: hrFooHTTPAndHTTPS,
+							ValidSectionNameRefs: map[string]struct{}{
+								"listener-80-1":  {},
+								"listener-443-1": {},
+							},
+							InvalidSectionNameRefs: map[string]ParentRefReason{},
+						},
+					}
+					l.AcceptedHostnames = map[string]struct{}{
+						"foo.example.com": {},
+					}
+				}),
+				"listener-443-1": createModifiedListener(func(l *listener) {
+					l.Routes = map[types.NamespacedName]*route{
+						{Namespace: "test", Name: "hr-1"}: {
+							Source: hrFooHTTPAndHTTPS,
+							ValidSectionNameRefs: map[string]struct{}{
+								"listener-80-1":  {},
+								"listener-443-1": {},
+							},
+							InvalidSectionNameRefs: map[string]ParentRefReason{},
+						},
+					}
+					l.AcceptedHostnames = map[string]struct{}{
+						"foo.example.com": {},
+					}
+				}),
+			},
+			msg: "HTTPRoute attached to two listeners of the same gateway",
+		},
+		{
+			httpRoute:  hrFooHTTPAndHTTPS,
+			gw:         gw,
+			ignoredGws: nil,
+			listeners: map[string]*listener{
+				"listener-80-1": createListener(),
+				"listener-443-1": createModifiedListener(func(l *listener) {
+					l.Source.Hostname = (*v1beta1.Hostname)(helpers.GetStringPointer("bar.example.com"))
+				}),
+			},
+			expectedIgnored: false,
+			expectedRoute: &route{
+				Source: hrFooHTTPAndHTTPS,
+				ValidSectionNameRefs: map[string]struct{}{
+					"listener-80-1": {},
+				},
+				InvalidSectionNameRefs: map[string]ParentRefReason{
+					"listener-443-1": ParentRefReasonNoMatchingListenerHostname,
+				},
+			},
+			expectedListeners: map[string]*listener{
+				"listener-80-1": createModifiedListener(func(l *listener) {
+					l.Routes = map[types.NamespacedName]*route{
+						{Namespace: "test", Name: "hr-1"}: {
+							Source: hrFooHTTPAndHTTPS,
+							ValidSectionNameRefs: map[string]struct{}{
+								"listener-80-1": {},
+							},
+							InvalidSectionNameRefs: map[string]ParentRefReason{
+								"listener-443-1": ParentRefReasonNoMatchingListenerHostname,
+							},
+						},
+					}
+					l.AcceptedHostnames = map[string]struct{}{
+						"foo.example.com": {},
+					}
+				}),
+				"listener-443-1": createModifiedListener(func(l *listener) {
+					l.Source.Hostname = (*v1beta1.Hostname)(helpers.GetStringPointer("bar.example.com"))
+				}),
+			},
+			msg: "HTTPRoute attached to one listener and rejected by another listener of the same gateway",
+		},
 		{
 			httpRoute:         hrFoo,
 			gw:                nil,
@@ -972,7 +1602,15 @@ func TestBindRouteToListeners(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		ignored, route := bindHTTPRouteToListeners(test.httpRoute, test.gw, test.ignoredGws, test.listeners)
+		gateways := make(map[types.NamespacedName]*v1beta1.Gateway)
+		if test.gw != nil {
+			gateways[getNamespacedName(test.gw)] = test.gw
+		}
+		for nsname, ignoredGw := range test.ignoredGws {
+			gateways[nsname] = ignoredGw
+		}
+
+		ignored, route := bindHTTPRouteToListeners(test.httpRoute, test.gw, test.ignoredGws, gateways, test.listeners)
 		if diff := cmp.Diff(test.expectedIgnored, ignored); diff != "" {
 			t.Errorf("bindHTTPRouteToListeners() %q  mismatch on ignored (-want +got):\n%s", test.msg, diff)
 		}
@@ -985,9 +1623,451 @@ func TestBindRouteToListeners(t *testing.T) {
 	}
 }
 
+func TestResolveBackendRefs(t *testing.T) {
+	serviceStore := NewServiceStore()
+	serviceStore.Upsert(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "service1",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: "10.0.0.1",
+			Ports: []v1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+		},
+	})
+
+	createRef := func(name string, port int32) v1beta1.HTTPBackendRef {
+		return v1beta1.HTTPBackendRef{
+			BackendRef: v1beta1.BackendRef{
+				BackendObjectReference: v1beta1.BackendObjectReference{
+					Name: v1beta1.ObjectName(name),
+					Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(port)),
+				},
+			},
+		}
+	}
+
+	createRefWithGroupKind := func(name string, group string, kind string) v1beta1.HTTPBackendRef {
+		ref := createRef(name, 80)
+		ref.Group = (*v1beta1.Group)(helpers.GetStringPointer(group))
+		ref.Kind = (*v1beta1.Kind)(helpers.GetStringPointer(kind))
+		return ref
+	}
+
+	createRoute := func(refs ...v1beta1.HTTPBackendRef) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test",
+				Name:      "hr",
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						BackendRefs: refs,
+					},
+				},
+			},
+		}
+	}
+
+	serviceImportStore := NewServiceImportStore()
+	serviceImportStore.Upsert(createServiceImport("import1", map[string]interface{}{
+		"type": "ClusterSetIP",
+		"ips":  []interface{}{"10.0.0.2"},
+		"ports": []interface{}{
+			map[string]interface{}{"port": int64(80)},
+		},
+	}))
+	backendResolvers := map[BackendKind]BackendResolver{
+		ServiceImportBackendKind: serviceImportStore,
+	}
+
+	tests := []struct {
+		hr                  *v1beta1.HTTPRoute
+		backendResolvers    map[BackendKind]BackendResolver
+		expected            []string
+		expectedInvalidKind []string
+		expectedRules       []int
+		expectedCount       int
+		msg                 string
+	}{
+		{
+			hr:            createRoute(),
+			expected:      nil,
+			expectedCount: 0,
+			msg:           "no refs",
+		},
+		{
+			hr:            createRoute(createRef("service1", 80)),
+			expected:      nil,
+			expectedCount: 1,
+			msg:           "exposed port",
+		},
+		{
+			hr:            createRoute(createRef("service1", 8080)),
+			expected:      []string{"service test/service1 doesn't expose port 8080"},
+			expectedRules: []int{0},
+			expectedCount: 1,
+			msg:           "not exposed port",
+		},
+		{
+			hr: createRoute(
+				createRef("service1", 80),
+				createRef("service2", 80),
+				createRef("service1", 8080),
+			),
+			expected: []string{
+				"service test/service2 doesn't exist",
+				"service test/service1 doesn't expose port 8080",
+			},
+			expectedCount: 3,
+			msg:           "resolvable and unresolvable refs",
+		},
+		{
+			hr: createRoute(
+				createRef("service1", 80),
+				func() v1beta1.HTTPBackendRef {
+					ref := createRef("service1", 80)
+					ref.Namespace = (*v1beta1.Namespace)(helpers.GetStringPointer("missing"))
+					return ref
+				}(),
+			),
+			expected:      []string{"service missing/service1 doesn't exist"},
+			expectedCount: 2,
+			msg:           "ref in a missing namespace",
+		},
+		{
+			hr:            createRoute(createRefWithGroupKind("service1", "", "Service")),
+			expected:      nil,
+			expectedCount: 1,
+			msg:           "core group",
+		},
+		{
+			hr: createRoute(
+				createRefWithGroupKind("service1", "networking.k8s.io", "Service"),
+				createRefWithGroupKind("service1", "", "ConfigMap"),
+				createRef("service1", 80),
+			),
+			expected: nil,
+			expectedInvalidKind: []string{
+				"backendRef service1 has unsupported group networking.k8s.io, must be the core group",
+				"backendRef service1 has unsupported kind ConfigMap",
+			},
+			expectedCount: 3,
+			msg:           "unexpected group and kind",
+		},
+		{
+			hr: createRoute(
+				createRefWithGroupKind("service1", "", "Service"),
+				createRefWithGroupKind("import1", "multicluster.x-k8s.io", "ServiceImport"),
+				createRefWithGroupKind("import2", "multicluster.x-k8s.io", "ServiceImport"),
+			),
+			backendResolvers: backendResolvers,
+			expected:         []string{"service test/import2 doesn't exist"},
+			expectedCount:    3,
+			msg:              "service and registered service import",
+		},
+		{
+			hr:       createRoute(createRefWithGroupKind("import1", "multicluster.x-k8s.io", "ServiceImport")),
+			expected: nil,
+			expectedInvalidKind: []string{
+				"backendRef import1 has unsupported kind ServiceImport of group multicluster.x-k8s.io",
+			},
+			expectedRules: []int{0},
+			expectedCount: 1,
+			msg:           "not registered service import",
+		},
+		{
+			hr: createRoute(func() v1beta1.HTTPBackendRef {
+				ref := createRefWithGroupKind("config", "", "ConfigMap")
+				ref.Port = nil
+				return ref
+			}()),
+			expected:            nil,
+			expectedInvalidKind: []string{"backendRef config has unsupported kind ConfigMap"},
+			expectedRules:       []int{0},
+			expectedCount:       1,
+			msg:                 "unsupported kind without port",
+		},
+		{
+			hr: &v1beta1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "hr",
+				},
+				Spec: v1beta1.HTTPRouteSpec{
+					Rules: []v1beta1.HTTPRouteRule{
+						{
+							BackendRefs: []v1beta1.HTTPBackendRef{createRef("service1", 80)},
+						},
+						{
+							BackendRefs: []v1beta1.HTTPBackendRef{
+								createRef("service2", 80),
+								createRefWithGroupKind("service1", "", "ConfigMap"),
+							},
+						},
+						{
+							BackendRefs: []v1beta1.HTTPBackendRef{
+								createRef("service1", 80),
+								createRef("service2", 80),
+							},
+						},
+					},
+				},
+			},
+			expected: []string{
+				"service test/service2 doesn't exist",
+				"service test/service2 doesn't exist",
+			},
+			expectedInvalidKind: []string{"backendRef service1 has unsupported kind ConfigMap"},
+			expectedRules:       []int{1},
+			expectedCount:       5,
+			msg:                 "rule without resolvable refs",
+		},
+	}
+
+	for _, test := range tests {
+		count, result, invalidKind, rules := resolveBackendRefs(
+			context.Background(),
+			test.hr,
+			make(BackendResolutions),
+			DefaultResolveTimeout,
+			serviceStore,
+			test.backendResolvers,
+		)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("resolveBackendRefs() %q mismatch (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedInvalidKind, invalidKind); diff != "" {
+			t.Errorf("resolveBackendRefs() %q mismatch on invalid kind (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedRules, rules); diff != "" {
+			t.Errorf("resolveBackendRefs() %q mismatch on unresolvable rules (-want +got):\n%s", test.msg, diff)
+		}
+		if count != test.expectedCount {
+			t.Errorf("resolveBackendRefs() %q returned count %d but expected %d", test.msg, count, test.expectedCount)
+		}
+	}
+}
+
+func TestResolveBackendRefsDoneContext(t *testing.T) {
+	serviceStore := NewServiceStore()
+	serviceStore.Upsert(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "service1",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: "10.0.0.1",
+			Ports: []v1.ServicePort{
+				{
+					Port: 80,
+				},
+			},
+		},
+	})
+
+	hr := &v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "hr",
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					BackendRefs: []v1beta1.HTTPBackendRef{
+						{
+							BackendRef: v1beta1.BackendRef{
+								BackendObjectReference: v1beta1.BackendObjectReference{
+									Name: "service1",
+									Port: (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	count, unresolved, _, rules := resolveBackendRefs(
+		ctx,
+		hr,
+		make(BackendResolutions),
+		DefaultResolveTimeout,
+		serviceStore,
+		nil,
+	)
+
+	// the backends are not resolved once the context is done
+	expectedUnresolved := []string{context.Canceled.Error()}
+	if diff := cmp.Diff(expectedUnresolved, unresolved); diff != "" {
+		t.Errorf("resolveBackendRefs() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{0}, rules); diff != "" {
+		t.Errorf("resolveBackendRefs() mismatch on unresolvable rules (-want +got):\n%s", diff)
+	}
+	if count != 1 {
+		t.Errorf("resolveBackendRefs() returned count %d but expected 1", count)
+	}
+}
+
+func TestResolveBackendRefsBlockingResolver(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	var calls int32
+	// the resolver ignores the context, so only the time limit of the resolution prevents it from blocking the build
+	var resolver backendResolverFunc = func(context.Context, types.NamespacedName, int32) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-unblock
+		return "10.0.0.1", nil
+	}
+
+	backendResolvers := map[BackendKind]BackendResolver{
+		ServiceImportBackendKind: resolver,
+	}
+
+	createRoute := func(name string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test",
+				Name:      name,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				Rules: []v1beta1.HTTPRouteRule{
+					{
+						BackendRefs: []v1beta1.HTTPBackendRef{
+							{
+								BackendRef: v1beta1.BackendRef{
+									BackendObjectReference: v1beta1.BackendObjectReference{
+										Group: (*v1beta1.Group)(helpers.GetStringPointer("multicluster.x-k8s.io")),
+										Kind:  (*v1beta1.Kind)(helpers.GetStringPointer("ServiceImport")),
+										Name:  "import1",
+										Port:  (*v1beta1.PortNumber)(helpers.GetInt32Pointer(80)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	resolutions := make(BackendResolutions)
+
+	// the context of the handler has no deadline
+	for _, hr := range []*v1beta1.HTTPRoute{createRoute("hr1"), createRoute("hr2")} {
+		count, unresolved, _, rules := resolveBackendRefs(
+			context.Background(),
+			hr,
+			resolutions,
+			10*time.Millisecond,
+			NewServiceStore(),
+			backendResolvers,
+		)
+
+		expectedUnresolved := []string{context.DeadlineExceeded.Error()}
+		if diff := cmp.Diff(expectedUnresolved, unresolved); diff != "" {
+			t.Errorf("resolveBackendRefs() mismatch for %s (-want +got):\n%s", hr.Name, diff)
+		}
+		if diff := cmp.Diff([]int{0}, rules); diff != "" {
+			t.Errorf("resolveBackendRefs() mismatch on unresolvable rules for %s (-want +got):\n%s", hr.Name, diff)
+		}
+		if count != 1 {
+			t.Errorf("resolveBackendRefs() returned count %d for %s but expected 1", count, hr.Name)
+		}
+	}
+
+	// the backend that timed out is not resolved again for the second HTTPRoute
+	if c := atomic.LoadInt32(&calls); c > 1 {
+		t.Errorf("resolveBackendRefs() called the resolver %d times but expected 1", c)
+	}
+}
+
+func TestResolveTCPRouteConflicts(t *testing.T) {
+	createTCPRoute := func(name string, creationTime metav1.Time) *tcpRoute {
+		return &tcpRoute{
+			Source: &v1alpha2.TCPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         "test",
+					Name:              name,
+					CreationTimestamp: creationTime,
+				},
+			},
+			ValidSectionNameRefs: map[string]struct{}{
+				"listener-8080": {},
+			},
+			InvalidSectionNameRefs: map[string]ParentRefReason{},
+		}
+	}
+
+	// tr2 is older than tr1, so it wins the listener
+	tr1 := createTCPRoute("tr-1", metav1.Now())
+	tr2 := createTCPRoute("tr-2", metav1.NewTime(tr1.Source.CreationTimestamp.Add(-time.Minute)))
+	tr3 := createTCPRoute("tr-3", metav1.Now())
+	tr3.ValidSectionNameRefs = map[string]struct{}{
+		"listener-8081": {},
+	}
+
+	listeners := map[string]*listener{
+		"listener-8080": {
+			TCPRoutes: map[types.NamespacedName]*tcpRoute{
+				{Namespace: "test", Name: "tr-1"}: tr1,
+				{Namespace: "test", Name: "tr-2"}: tr2,
+			},
+		},
+		"listener-8081": {
+			TCPRoutes: map[types.NamespacedName]*tcpRoute{
+				{Namespace: "test", Name: "tr-3"}: tr3,
+			},
+		},
+		"listener-80": {},
+	}
+
+	resolveTCPRouteConflicts(listeners)
+
+	expectedTCPRoutes := map[string]map[types.NamespacedName]*tcpRoute{
+		"listener-8080": {
+			{Namespace: "test", Name: "tr-2"}: tr2,
+		},
+		"listener-8081": {
+			{Namespace: "test", Name: "tr-3"}: tr3,
+		},
+		"listener-80": nil,
+	}
+
+	for name, expected := range expectedTCPRoutes {
+		if diff := cmp.Diff(expected, listeners[name].TCPRoutes); diff != "" {
+			t.Errorf("resolveTCPRouteConflicts() mismatch on the TCPRoutes of %s (-want +got):\n%s", name, diff)
+		}
+	}
+
+	expectedTR1 := &tcpRoute{
+		Source:               tr1.Source,
+		ValidSectionNameRefs: map[string]struct{}{},
+		InvalidSectionNameRefs: map[string]ParentRefReason{
+			"listener-8080": ParentRefReasonRouteConflict,
+		},
+	}
+
+	if diff := cmp.Diff(expectedTR1, tr1); diff != "" {
+		t.Errorf("resolveTCPRouteConflicts() mismatch on the detached route (-want +got):\n%s", diff)
+	}
+}
+
 func TestFindAcceptedHostnames(t *testing.T) {
 	var listenerHostnameFoo v1beta1.Hostname = "foo.example.com"
 	var listenerHostnameCafe v1beta1.Hostname = "cafe.example.com"
+	var listenerHostnameWildcard v1beta1.Hostname = "*.example.com"
 	routeHostnames := []v1beta1.Hostname{"foo.example.com", "bar.example.com"}
 
 	tests := []struct {
@@ -1014,6 +2094,42 @@ func TestFindAcceptedHostnames(t *testing.T) {
 			expected:         []string{"foo.example.com", "bar.example.com"},
 			msg:              "nil listener hostname",
 		},
+		{
+			listenerHostname: &listenerHostnameWildcard,
+			routeHostnames:   []v1beta1.Hostname{"foo.example.com", "bar.example.com", "foo.example.org"},
+			expected:         []string{"foo.example.com", "bar.example.com"},
+			msg:              "wildcard listener hostname",
+		},
+		{
+			listenerHostname: &listenerHostnameWildcard,
+			routeHostnames:   []v1beta1.Hostname{"example.com", "*.example.com", "*.foo.example.com"},
+			expected:         []string{"*.example.com", "*.foo.example.com"},
+			msg:              "wildcard listener hostname and wildcard route hostnames",
+		},
+		{
+			listenerHostname: &listenerHostnameFoo,
+			routeHostnames:   []v1beta1.Hostname{"*.example.com", "foo.example.com"},
+			expected:         []string{"foo.example.com"},
+			msg:              "wildcard route hostname",
+		},
+		{
+			listenerHostname: &listenerHostnameFoo,
+			routeHostnames:   nil,
+			expected:         []string{"foo.example.com"},
+			msg:              "route without hostnames inherits listener hostname",
+		},
+		{
+			listenerHostname: &listenerHostnameWildcard,
+			routeHostnames:   nil,
+			expected:         []string{"*.example.com"},
+			msg:              "route without hostnames inherits wildcard listener hostname",
+		},
+		{
+			listenerHostname: nil,
+			routeHostnames:   nil,
+			expected:         nil,
+			msg:              "route without hostnames and nil listener hostname",
+		},
 	}
 
 	for _, test := range tests {
@@ -1058,6 +2174,77 @@ func TestGetHostname(t *testing.T) {
 	}
 }
 
+func TestValidateGatewayAddresses(t *testing.T) {
+	ipAddressType := v1beta1.IPAddressType
+	hostnameAddressType := v1beta1.HostnameAddressType
+
+	allowedAddresses := []string{"10.0.0.1", "10.0.0.2", "2001:db8::1"}
+
+	tests := []struct {
+		addresses        []v1beta1.GatewayAddress
+		expectedValid    []string
+		expectedRejected []string
+		msg              string
+	}{
+		{
+			addresses:        nil,
+			expectedValid:    nil,
+			expectedRejected: nil,
+			msg:              "no addresses",
+		},
+		{
+			addresses: []v1beta1.GatewayAddress{
+				{
+					Value: "10.0.0.1",
+				},
+				{
+					Type:  &ipAddressType,
+					Value: "2001:db8:0::1",
+				},
+			},
+			expectedValid:    []string{"10.0.0.1", "2001:db8:0::1"},
+			expectedRejected: nil,
+			msg:              "allowed ip addresses",
+		},
+		{
+			addresses: []v1beta1.GatewayAddress{
+				{
+					Type:  &hostnameAddressType,
+					Value: "gateway.example.com",
+				},
+				{
+					Value: "not-an-ip",
+				},
+				{
+					Value: "10.0.0.3",
+				},
+				{
+					Value: "10.0.0.2",
+				},
+			},
+			expectedValid:    []string{"10.0.0.2"},
+			expectedRejected: []string{"gateway.example.com", "not-an-ip", "10.0.0.3"},
+			msg:              "hostname, invalid and not allowed addresses",
+		},
+	}
+
+	for _, test := range tests {
+		gw := &v1beta1.Gateway{
+			Spec: v1beta1.GatewaySpec{
+				Addresses: test.addresses,
+			},
+		}
+
+		valid, rejected := validateGatewayAddresses(gw, allowedAddresses)
+		if diff := cmp.Diff(test.expectedValid, valid); diff != "" {
+			t.Errorf("validateGatewayAddresses() %q mismatch on valid addresses (-want +got):\n%s", test.msg, diff)
+		}
+		if diff := cmp.Diff(test.expectedRejected, rejected); diff != "" {
+			t.Errorf("validateGatewayAddresses() %q mismatch on rejected addresses (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
 func TestValidateGatewayClass(t *testing.T) {
 	gc := &v1beta1.GatewayClass{
 		Spec: v1beta1.GatewayClassSpec{
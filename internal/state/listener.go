@@ -1,26 +1,56 @@
 package state
 
 import (
+	"crypto/x509"
+
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 // listener represents a listener of the Gateway resource.
-// FIXME(pleshakov) For now, we only support HTTP and HTTPS listeners.
+// FIXME(pleshakov) For now, we only support HTTP, HTTPS and TCP listeners.
 type listener struct {
 	// Source holds the source of the listener from the Gateway resource.
 	Source v1beta1.Listener
 	// Valid shows whether the listener is valid.
 	Valid bool
-	// SecretPath is the path to the secret on disk.
-	SecretPath string
+	// UnresolvedRefs shows whether the listener references resources that don't exist - for example, a Secret
+	// from its certificateRefs. A listener with unresolved refs is not valid.
+	UnresolvedRefs bool
+	// SecretPaths are the paths to the files of the secret on disk.
+	SecretPaths SecretPaths
+	// Certificate is the leaf certificate of the secret. It is only set for valid HTTPS listeners.
+	Certificate *x509.Certificate
 	// Routes holds the routes attached to the listener.
 	Routes map[types.NamespacedName]*route
 	// AcceptedHostnames is an intersection between the hostnames supported by the listener and the hostnames
 	// from the attached routes.
 	AcceptedHostnames map[string]struct{}
+	// TCPRoutes holds the TCPRoutes attached to the listener. It is only initialized for TCP listeners.
+	TCPRoutes map[types.NamespacedName]*tcpRoute
+	// UnsupportedSelector shows whether the allowedRoutes of the listener select the namespaces by a label selector,
+	// which is not supported, so the listener doesn't allow any routes.
+	UnsupportedSelector bool
+	// UnsupportedProtocol shows whether the protocol of the listener is not supported, for example, TLS or UDP.
+	// A listener with an unsupported protocol is not valid.
+	UnsupportedProtocol bool
+	// Conflict shows why the listener conflicts with another listener of the Gateway. It is empty if the listener
+	// doesn't conflict with any listener. A conflicted listener is not valid.
+	Conflict ListenerConflictReason
 }
 
+// ListenerConflictReason is the reason why a listener conflicts with another listener of the same Gateway.
+type ListenerConflictReason string
+
+const (
+	// ListenerConflictReasonHostname means the listener has the same hostname as another listener with the same
+	// protocol or, for TCP listeners, the same port.
+	ListenerConflictReasonHostname ListenerConflictReason = "HostnameConflict"
+	// ListenerConflictReasonProtocol means the listener binds the same port as another listener with a different
+	// protocol.
+	ListenerConflictReasonProtocol ListenerConflictReason = "ProtocolConflict"
+)
+
 type listenerConfigurator interface {
 	configure(listener v1beta1.Listener) *listener
 }
@@ -28,6 +58,7 @@ type listenerConfigurator interface {
 type listenerConfiguratorFactory struct {
 	https *httpsListenerConfigurator
 	http  *httpListenerConfigurator
+	tcp   *tcpListenerConfigurator
 }
 
 func (f *listenerConfiguratorFactory) getConfiguratorForListener(l v1beta1.Listener) listenerConfigurator {
@@ -36,6 +67,8 @@ func (f *listenerConfiguratorFactory) getConfiguratorForListener(l v1beta1.Liste
 		return f.http
 	case v1beta1.HTTPSProtocolType:
 		return f.https
+	case v1beta1.TCPProtocolType:
+		return f.tcp
 	default:
 		return newInvalidProtocolListenerConfigurator()
 	}
@@ -45,6 +78,7 @@ func newListenerConfiguratorFactory(gw *v1beta1.Gateway, secretMemoryMgr SecretD
 	return &listenerConfiguratorFactory{
 		https: newHTTPSListenerConfigurator(gw, secretMemoryMgr),
 		http:  newHTTPListenerConfigurator(),
+		tcp:   newTCPListenerConfigurator(),
 	}
 }
 
@@ -63,8 +97,10 @@ func newHTTPSListenerConfigurator(gateway *v1beta1.Gateway, secretMemoryMgr Secr
 }
 
 func (c *httpsListenerConfigurator) configure(gl v1beta1.Listener) *listener {
-	var path string
+	var paths SecretPaths
+	var cert *x509.Certificate
 	var err error
+	var unresolvedRefs bool
 
 	valid := validateHTTPSListener(gl, c.gateway.Namespace)
 
@@ -74,23 +110,33 @@ func (c *httpsListenerConfigurator) configure(gl v1beta1.Listener) *listener {
 			Name:      string(gl.TLS.CertificateRefs[0].Name),
 		}
 
-		path, err = c.secretMemoryMgr.Request(nsname)
+		paths, err = c.secretMemoryMgr.Request(nsname)
 		if err != nil {
 			valid = false
+			unresolvedRefs = true
+		} else {
+			cert = c.secretMemoryMgr.Certificate(nsname)
 		}
 	}
 
 	h := getHostname(gl.Hostname)
 
+	var conflict ListenerConflictReason
+
 	if holder, exist := c.usedHostnames[h]; exist {
 		valid = false
+		conflict = ListenerConflictReasonHostname
 		holder.Valid = false // all listeners for the same hostname become conflicted
+		holder.Conflict = ListenerConflictReasonHostname
 	}
 
 	l := &listener{
 		Source:            gl,
 		Valid:             valid,
-		SecretPath:        path,
+		UnresolvedRefs:    unresolvedRefs,
+		Conflict:          conflict,
+		SecretPaths:       paths,
+		Certificate:       cert,
 		Routes:            make(map[types.NamespacedName]*route),
 		AcceptedHostnames: make(map[string]struct{}),
 	}
@@ -115,14 +161,19 @@ func (c *httpListenerConfigurator) configure(gl v1beta1.Listener) *listener {
 
 	h := getHostname(gl.Hostname)
 
+	var conflict ListenerConflictReason
+
 	if holder, exist := c.usedHostnames[h]; exist {
 		valid = false
+		conflict = ListenerConflictReasonHostname
 		holder.Valid = false // all listeners for the same hostname become conflicted
+		holder.Conflict = ListenerConflictReasonHostname
 	}
 
 	l := &listener{
 		Source:            gl,
 		Valid:             valid,
+		Conflict:          conflict,
 		Routes:            make(map[types.NamespacedName]*route),
 		AcceptedHostnames: make(map[string]struct{}),
 	}
@@ -132,6 +183,42 @@ func (c *httpListenerConfigurator) configure(gl v1beta1.Listener) *listener {
 	return l
 }
 
+type tcpListenerConfigurator struct {
+	usedPorts map[v1beta1.PortNumber]*listener
+}
+
+func newTCPListenerConfigurator() *tcpListenerConfigurator {
+	return &tcpListenerConfigurator{
+		usedPorts: make(map[v1beta1.PortNumber]*listener),
+	}
+}
+
+func (c *tcpListenerConfigurator) configure(gl v1beta1.Listener) *listener {
+	valid := validateTCPListener(gl)
+
+	var conflict ListenerConflictReason
+
+	if holder, exist := c.usedPorts[gl.Port]; exist {
+		valid = false
+		conflict = ListenerConflictReasonHostname
+		holder.Valid = false // all listeners for the same port become conflicted
+		holder.Conflict = ListenerConflictReasonHostname
+	}
+
+	l := &listener{
+		Source:            gl,
+		Valid:             valid,
+		Conflict:          conflict,
+		Routes:            make(map[types.NamespacedName]*route),
+		AcceptedHostnames: make(map[string]struct{}),
+		TCPRoutes:         make(map[types.NamespacedName]*tcpRoute),
+	}
+
+	c.usedPorts[gl.Port] = l
+
+	return l
+}
+
 type invalidProtocolListenerConfigurator struct{}
 
 func newInvalidProtocolListenerConfigurator() *invalidProtocolListenerConfigurator {
@@ -140,10 +227,11 @@ func newInvalidProtocolListenerConfigurator() *invalidProtocolListenerConfigurat
 
 func (c *invalidProtocolListenerConfigurator) configure(gl v1beta1.Listener) *listener {
 	return &listener{
-		Source:            gl,
-		Valid:             false,
-		Routes:            make(map[types.NamespacedName]*route),
-		AcceptedHostnames: make(map[string]struct{}),
+		Source:              gl,
+		Valid:               false,
+		UnsupportedProtocol: true,
+		Routes:              make(map[types.NamespacedName]*route),
+		AcceptedHostnames:   make(map[string]struct{}),
 	}
 }
 
@@ -152,6 +240,11 @@ func validateHTTPListener(listener v1beta1.Listener) bool {
 	return listener.Port == 80
 }
 
+func validateTCPListener(listener v1beta1.Listener) bool {
+	// Ports 80 and 443 are reserved for the HTTP and HTTPS listeners.
+	return listener.Port != 80 && listener.Port != 443
+}
+
 func validateHTTPSListener(listener v1beta1.Listener, gwNsname string) bool {
 	// FIXME(kate-osborn):
 	// 1. For now we require that all HTTPS listeners bind to port 443
@@ -173,3 +266,52 @@ func validateHTTPSListener(listener v1beta1.Listener, gwNsname string) bool {
 
 	return true
 }
+
+// usesNamespaceSelector returns true if the allowedRoutes of the listener select the namespaces of the routes by
+// a label selector.
+func usesNamespaceSelector(listener v1beta1.Listener) bool {
+	return listener.AllowedRoutes != nil &&
+		listener.AllowedRoutes.Namespaces != nil &&
+		listener.AllowedRoutes.Namespaces.From != nil &&
+		*listener.AllowedRoutes.Namespaces.From == v1beta1.NamespacesFromSelector
+}
+
+// allowsRoute checks if the allowedRoutes of the listener allow a route of the kind from the namespace to attach to
+// the listener of the Gateway from gwNamespace.
+// The namespace selector is not supported, because the labels of the namespaces are not
+// available, so the listeners with the Selector namespaces don't allow any routes.
+func allowsRoute(listener v1beta1.Listener, gwNamespace, routeNamespace string, kind v1beta1.Kind) bool {
+	if listener.AllowedRoutes == nil {
+		// the default allows the routes from the namespace of the Gateway
+		return gwNamespace == routeNamespace
+	}
+
+	if len(listener.AllowedRoutes.Kinds) > 0 {
+		allowedKind := false
+
+		for _, k := range listener.AllowedRoutes.Kinds {
+			if k.Kind == kind && (k.Group == nil || *k.Group == v1beta1.GroupName) {
+				allowedKind = true
+				break
+			}
+		}
+
+		if !allowedKind {
+			return false
+		}
+	}
+
+	from := v1beta1.NamespacesFromSame
+	if listener.AllowedRoutes.Namespaces != nil && listener.AllowedRoutes.Namespaces.From != nil {
+		from = *listener.AllowedRoutes.Namespaces.From
+	}
+
+	switch from {
+	case v1beta1.NamespacesFromAll:
+		return true
+	case v1beta1.NamespacesFromSame:
+		return gwNamespace == routeNamespace
+	default:
+		return false
+	}
+}
@@ -40,6 +40,46 @@ func TestValidateHTTPListener(t *testing.T) {
 	}
 }
 
+func TestValidateTCPListener(t *testing.T) {
+	tests := []struct {
+		l        v1beta1.Listener
+		expected bool
+		msg      string
+	}{
+		{
+			l: v1beta1.Listener{
+				Port:     8080,
+				Protocol: v1beta1.TCPProtocolType,
+			},
+			expected: true,
+			msg:      "valid",
+		},
+		{
+			l: v1beta1.Listener{
+				Port:     80,
+				Protocol: v1beta1.TCPProtocolType,
+			},
+			expected: false,
+			msg:      "http port",
+		},
+		{
+			l: v1beta1.Listener{
+				Port:     443,
+				Protocol: v1beta1.TCPProtocolType,
+			},
+			expected: false,
+			msg:      "https port",
+		},
+	}
+
+	for _, test := range tests {
+		result := validateTCPListener(test.l)
+		if result != test.expected {
+			t.Errorf("validateTCPListener() returned %v but expected %v for the case of %q", result, test.expected, test.msg)
+		}
+	}
+}
+
 func TestValidateHTTPSListener(t *testing.T) {
 	gwNs := "gateway-ns"
 
@@ -154,3 +194,94 @@ func TestValidateHTTPSListener(t *testing.T) {
 		}
 	}
 }
+
+func TestAllowsRoute(t *testing.T) {
+	fromAll := v1beta1.NamespacesFromAll
+	fromSame := v1beta1.NamespacesFromSame
+	fromSelector := v1beta1.NamespacesFromSelector
+	otherGroup := v1beta1.Group("example.com")
+
+	tests := []struct {
+		allowedRoutes  *v1beta1.AllowedRoutes
+		routeNamespace string
+		kind           v1beta1.Kind
+		expected       bool
+		msg            string
+	}{
+		{
+			routeNamespace: "test",
+			kind:           "HTTPRoute",
+			expected:       true,
+			msg:            "default allowed routes, same namespace",
+		},
+		{
+			routeNamespace: "other",
+			kind:           "HTTPRoute",
+			expected:       false,
+			msg:            "default allowed routes, other namespace",
+		},
+		{
+			allowedRoutes: &v1beta1.AllowedRoutes{
+				Namespaces: &v1beta1.RouteNamespaces{From: &fromAll},
+			},
+			routeNamespace: "other",
+			kind:           "HTTPRoute",
+			expected:       true,
+			msg:            "all namespaces",
+		},
+		{
+			allowedRoutes: &v1beta1.AllowedRoutes{
+				Namespaces: &v1beta1.RouteNamespaces{From: &fromSame},
+			},
+			routeNamespace: "other",
+			kind:           "HTTPRoute",
+			expected:       false,
+			msg:            "same namespace, other namespace",
+		},
+		{
+			allowedRoutes: &v1beta1.AllowedRoutes{
+				Namespaces: &v1beta1.RouteNamespaces{From: &fromSelector},
+			},
+			routeNamespace: "test",
+			kind:           "HTTPRoute",
+			expected:       false,
+			msg:            "unsupported namespace selector",
+		},
+		{
+			allowedRoutes: &v1beta1.AllowedRoutes{
+				Kinds: []v1beta1.RouteGroupKind{{Kind: "HTTPRoute"}},
+			},
+			routeNamespace: "test",
+			kind:           "HTTPRoute",
+			expected:       true,
+			msg:            "allowed kind",
+		},
+		{
+			allowedRoutes: &v1beta1.AllowedRoutes{
+				Kinds: []v1beta1.RouteGroupKind{{Kind: "TCPRoute"}},
+			},
+			routeNamespace: "test",
+			kind:           "HTTPRoute",
+			expected:       false,
+			msg:            "not allowed kind",
+		},
+		{
+			allowedRoutes: &v1beta1.AllowedRoutes{
+				Kinds: []v1beta1.RouteGroupKind{{Group: &otherGroup, Kind: "HTTPRoute"}},
+			},
+			routeNamespace: "test",
+			kind:           "HTTPRoute",
+			expected:       false,
+			msg:            "kind of other group",
+		},
+	}
+
+	for _, test := range tests {
+		l := v1beta1.Listener{AllowedRoutes: test.allowedRoutes}
+
+		result := allowsRoute(l, "test", test.routeNamespace, test.kind)
+		if result != test.expected {
+			t.Errorf("allowsRoute() returned %v but expected %v for the case of %q", result, test.expected, test.msg)
+		}
+	}
+}
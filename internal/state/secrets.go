@@ -1,12 +1,16 @@
 package state
 
 import (
-	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io/fs"
 	"os"
 	"path"
+	"sort"
 
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -17,8 +21,14 @@ import (
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . FileManager
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 io/fs.DirEntry
 
-// tlsSecretFileMode defines the default file mode for files with TLS Secrets.
-const tlsSecretFileMode = 0o600
+const (
+	// tlsSecretFileMode defines the default file mode for files with TLS Secrets.
+	tlsSecretFileMode = 0o600
+	// certificateFileExt is the extension of the file with the certificate of a TLS Secret.
+	certificateFileExt = ".crt"
+	// keyFileExt is the extension of the file with the private key of a TLS Secret.
+	keyFileExt = ".key"
+)
 
 // SecretStore stores secrets.
 type SecretStore interface {
@@ -40,6 +50,8 @@ type Secret struct {
 	Secret *apiv1.Secret
 	// Valid is whether the Kubernetes Secret is valid.
 	Valid bool
+	// Certificate is the leaf certificate of the Kubernetes Secret. It is nil if the Secret is invalid.
+	Certificate *x509.Certificate
 }
 
 func NewSecretStore() *SecretStoreImpl {
@@ -54,8 +66,8 @@ func (s SecretStoreImpl) Upsert(secret *apiv1.Secret) {
 		Name:      secret.Name,
 	}
 
-	valid := isSecretValid(secret)
-	s.secrets[nsname] = &Secret{Secret: secret, Valid: valid}
+	cert, err := parseSecretCertificate(secret)
+	s.secrets[nsname] = &Secret{Secret: secret, Valid: err == nil, Certificate: cert}
 }
 
 func (s SecretStoreImpl) Delete(nsname types.NamespacedName) {
@@ -69,10 +81,23 @@ func (s SecretStoreImpl) Get(nsname types.NamespacedName) *Secret {
 // SecretDiskMemoryManager manages secrets that are requested by Gateway resources.
 type SecretDiskMemoryManager interface {
 	// Request marks the secret as requested so that it can be written to disk before reloading NGINX.
-	// Returns the path to the secret and an error if the secret does not exist in the secret store or the secret is invalid.
-	Request(nsname types.NamespacedName) (string, error)
+	// Returns the paths to the files of the secret and an error if the secret does not exist in the secret store or
+	// the secret is invalid.
+	Request(nsname types.NamespacedName) (SecretPaths, error)
+	// Certificate returns the leaf certificate of the requested secret or nil if the secret is not requested.
+	// It allows checking the hostnames the certificate is valid for.
+	Certificate(nsname types.NamespacedName) *x509.Certificate
 	// WriteAllRequestedSecrets writes all requested secrets to disk.
-	WriteAllRequestedSecrets() error
+	// Returns a hash of the paths and the contents of the written files, which changes when a secret is rotated.
+	WriteAllRequestedSecrets() (string, error)
+}
+
+// SecretPaths are the paths to the files of a TLS Secret on disk.
+type SecretPaths struct {
+	// Certificate is the path to the file with the certificate.
+	Certificate string
+	// Key is the path to the file with the private key.
+	Key string
 }
 
 // FileManager is an interface that exposes File I/O operations.
@@ -99,8 +124,9 @@ type SecretDiskMemoryManagerImpl struct {
 }
 
 type requestedSecret struct {
-	secret *apiv1.Secret
-	path   string
+	secret      *apiv1.Secret
+	certificate *x509.Certificate
+	paths       SecretPaths
 }
 
 // SecretDiskMemoryManagerOption is a function that modifies the configuration of the SecretDiskMemoryManager.
@@ -129,85 +155,117 @@ func NewSecretDiskMemoryManager(secretDirectory string, secretStore SecretStore,
 	return sm
 }
 
-func (s *SecretDiskMemoryManagerImpl) Request(nsname types.NamespacedName) (string, error) {
+func (s *SecretDiskMemoryManagerImpl) Request(nsname types.NamespacedName) (SecretPaths, error) {
 	secret := s.secretStore.Get(nsname)
 	if secret == nil {
-		return "", fmt.Errorf("secret %s does not exist", nsname)
+		return SecretPaths{}, fmt.Errorf("secret %s does not exist", nsname)
 	}
 
 	if !secret.Valid {
-		return "", fmt.Errorf("secret %s is not valid; must be of type %s and contain a valid X509 key pair", nsname, apiv1.SecretTypeTLS)
+		return SecretPaths{}, fmt.Errorf("secret %s is not valid; must be of type %s and contain a valid X509 key pair", nsname, apiv1.SecretTypeTLS)
 	}
 
+	filepath := path.Join(s.secretDirectory, generateFilepathForSecret(nsname))
+
 	ss := requestedSecret{
-		secret: secret.Secret,
-		path:   path.Join(s.secretDirectory, generateFilepathForSecret(nsname)),
+		secret:      secret.Secret,
+		certificate: secret.Certificate,
+		paths: SecretPaths{
+			Certificate: filepath + certificateFileExt,
+			Key:         filepath + keyFileExt,
+		},
 	}
 
 	s.requestedSecrets[nsname] = ss
 
-	return ss.path, nil
+	return ss.paths, nil
 }
 
-func (s *SecretDiskMemoryManagerImpl) WriteAllRequestedSecrets() error {
+func (s *SecretDiskMemoryManagerImpl) Certificate(nsname types.NamespacedName) *x509.Certificate {
+	return s.requestedSecrets[nsname].certificate
+}
+
+func (s *SecretDiskMemoryManagerImpl) WriteAllRequestedSecrets() (string, error) {
 	// Remove all existing secrets from secrets directory
 	dir, err := s.fileManager.ReadDir(s.secretDirectory)
 	if err != nil {
-		return fmt.Errorf("failed to remove all secrets from %s: %w", s.secretDirectory, err)
+		return "", fmt.Errorf("failed to remove all secrets from %s: %w", s.secretDirectory, err)
 	}
 
 	for _, d := range dir {
 		filepath := path.Join(s.secretDirectory, d.Name())
 		if err := s.fileManager.Remove(filepath); err != nil {
-			return fmt.Errorf("failed to remove secret %s: %w", filepath, err)
+			return "", fmt.Errorf("failed to remove secret %s: %w", filepath, err)
 		}
 	}
 
-	// Write all secrets to secrets directory
-	for nsname, ss := range s.requestedSecrets {
+	// The secrets are written in a fixed order, so that the hash is the same for the same secrets.
+	nsnames := make([]types.NamespacedName, 0, len(s.requestedSecrets))
+	for nsname := range s.requestedSecrets {
+		nsnames = append(nsnames, nsname)
+	}
+	sort.Slice(nsnames, func(i, j int) bool {
+		return nsnames[i].String() < nsnames[j].String()
+	})
 
-		file, err := s.fileManager.Create(ss.path)
-		if err != nil {
-			return fmt.Errorf("failed to create file %s for secret %s: %w", ss.path, nsname, err)
-		}
+	h := sha256.New()
 
-		if err = s.fileManager.Chmod(file, tlsSecretFileMode); err != nil {
-			return fmt.Errorf("failed to change mode of file %s for secret %s: %w", ss.path, nsname, err)
-		}
+	// Write all secrets to secrets directory
+	for _, nsname := range nsnames {
+		ss := s.requestedSecrets[nsname]
 
-		contents := generateCertAndKeyFileContent(ss.secret)
+		if err := s.writeSecretFile(ss.paths.Certificate, ss.secret.Data[apiv1.TLSCertKey], h); err != nil {
+			return "", fmt.Errorf("failed to write certificate of secret %s: %w", nsname, err)
+		}
 
-		err = s.fileManager.Write(file, contents)
-		if err != nil {
-			return fmt.Errorf("failed to write secret %s to file %s: %w", nsname, ss.path, err)
+		if err := s.writeSecretFile(ss.paths.Key, ss.secret.Data[apiv1.TLSPrivateKeyKey], h); err != nil {
+			return "", fmt.Errorf("failed to write key of secret %s: %w", nsname, err)
 		}
 	}
 
 	// reset stored secrets
 	s.requestedSecrets = make(map[types.NamespacedName]requestedSecret)
 
-	return nil
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
-func isSecretValid(secret *apiv1.Secret) bool {
-	if secret.Type != apiv1.SecretTypeTLS {
-		return false
+// writeSecretFile writes the contents to the file and adds the path and the contents of the file to the hash.
+func (s *SecretDiskMemoryManagerImpl) writeSecretFile(filepath string, contents []byte, h hash.Hash) error {
+	file, err := s.fileManager.Create(filepath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filepath, err)
 	}
 
-	// A TLS Secret is guaranteed to have these data fields.
-	_, err := tls.X509KeyPair(secret.Data[apiv1.TLSCertKey], secret.Data[apiv1.TLSPrivateKeyKey])
+	if err = s.fileManager.Chmod(file, tlsSecretFileMode); err != nil {
+		return fmt.Errorf("failed to change mode of file %s: %w", filepath, err)
+	}
+
+	if err = s.fileManager.Write(file, contents); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filepath, err)
+	}
+
+	// hash.Hash never returns an error.
+	// The lengths separate the path from the contents, so that different files cannot produce the same input.
+	_, _ = fmt.Fprintf(h, "%d:%s%d:", len(filepath), filepath, len(contents))
+	_, _ = h.Write(contents)
 
-	return err == nil
+	return nil
 }
 
-func generateCertAndKeyFileContent(secret *apiv1.Secret) []byte {
-	var res bytes.Buffer
+// parseSecretCertificate validates the secret and returns its leaf certificate.
+// A secret is valid if it is a TLS Secret with a valid X509 key pair.
+func parseSecretCertificate(secret *apiv1.Secret) (*x509.Certificate, error) {
+	if secret.Type != apiv1.SecretTypeTLS {
+		return nil, fmt.Errorf("secret must be of type %s", apiv1.SecretTypeTLS)
+	}
 
-	res.Write(secret.Data[apiv1.TLSCertKey])
-	res.WriteString("\n")
-	res.Write(secret.Data[apiv1.TLSPrivateKeyKey])
+	// A TLS Secret is guaranteed to have these data fields.
+	pair, err := tls.X509KeyPair(secret.Data[apiv1.TLSCertKey], secret.Data[apiv1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, err
+	}
 
-	return res.Bytes()
+	return x509.ParseCertificate(pair.Certificate[0])
 }
 
 func generateFilepathForSecret(nsname types.NamespacedName) string {
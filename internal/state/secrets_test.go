@@ -2,6 +2,7 @@
 package state_test
 
 import (
+	"crypto/x509"
 	"errors"
 	"io/fs"
 	"os"
@@ -155,69 +156,108 @@ var _ = Describe("SecretDiskMemoryManager", func() {
 	})
 
 	Describe("Manages secrets on disk", Ordered, func() {
-		testRequest := func(s *apiv1.Secret, expPath string, expErr bool) {
+		testRequest := func(s *apiv1.Secret, expPaths state.SecretPaths, expErr bool) {
 			nsname := types.NamespacedName{Namespace: s.Namespace, Name: s.Name}
-			actualPath, err := memMgr.Request(nsname)
+			actualPaths, err := memMgr.Request(nsname)
 
 			if expErr {
 				Expect(err).To(HaveOccurred())
-				Expect(actualPath).To(BeEmpty())
+				Expect(actualPaths).To(BeZero())
 			} else {
 				Expect(err).ToNot(HaveOccurred())
-				Expect(actualPath).To(Equal(expPath))
+				Expect(actualPaths).To(Equal(expPaths))
 			}
 		}
 
-		It("should return an error and empty path when secret does not exist", func() {
+		It("should return an error and empty paths when secret does not exist", func() {
 			fakeStore.GetReturns(nil)
 
-			testRequest(secret1, "", true)
+			testRequest(secret1, state.SecretPaths{}, true)
 		})
-		It("request should return the file path for a valid secret", func() {
+		It("request should return the file paths for a valid secret", func() {
 			fakeStore.GetReturns(&state.Secret{Secret: secret1, Valid: true})
-			expectedPath := path.Join(tmpSecretsDir, "test_secret1")
+			expectedPaths := state.SecretPaths{
+				Certificate: path.Join(tmpSecretsDir, "test_secret1.crt"),
+				Key:         path.Join(tmpSecretsDir, "test_secret1.key"),
+			}
+
+			testRequest(secret1, expectedPaths, false)
+		})
+
+		It("should return the certificate of a requested secret", func() {
+			certificate := &x509.Certificate{DNSNames: []string{"cafe.example.com"}}
+			fakeStore.GetReturns(&state.Secret{Secret: secret1, Valid: true, Certificate: certificate})
 
-			testRequest(secret1, expectedPath, false)
+			nsname := types.NamespacedName{Namespace: secret1.Namespace, Name: secret1.Name}
+
+			_, err := memMgr.Request(nsname)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(memMgr.Certificate(nsname)).To(Equal(certificate))
+			Expect(memMgr.Certificate(types.NamespacedName{Namespace: "test", Name: "not-requested"})).To(BeNil())
 		})
 
-		It("request should return the file path for another valid secret", func() {
+		It("request should return the file paths for another valid secret", func() {
 			fakeStore.GetReturns(&state.Secret{Secret: secret2, Valid: true})
-			expectedPath := path.Join(tmpSecretsDir, "test_secret2")
+			expectedPaths := state.SecretPaths{
+				Certificate: path.Join(tmpSecretsDir, "test_secret2.crt"),
+				Key:         path.Join(tmpSecretsDir, "test_secret2.key"),
+			}
 
-			testRequest(secret2, expectedPath, false)
+			testRequest(secret2, expectedPaths, false)
 		})
 
-		It("request should return an error and empty path when secret is invalid", func() {
+		It("request should return an error and empty paths when secret is invalid", func() {
 			fakeStore.GetReturns(&state.Secret{Secret: invalidSecretType, Valid: false})
 
-			testRequest(invalidSecretType, "", true)
+			testRequest(invalidSecretType, state.SecretPaths{}, true)
 		})
 
 		It("should write all requested secrets", func() {
-			err := memMgr.WriteAllRequestedSecrets()
+			_, err := memMgr.WriteAllRequestedSecrets()
 			Expect(err).ToNot(HaveOccurred())
 
-			expectedFileNames := []string{"test_secret1", "test_secret2"}
+			expectedFileNames := []string{
+				"test_secret1.crt",
+				"test_secret1.key",
+				"test_secret2.crt",
+				"test_secret2.key",
+			}
 
 			// read all files from directory
 			dir, err := os.ReadDir(tmpSecretsDir)
 			Expect(err).ToNot(HaveOccurred())
 
 			// test that the files exist that we expect
-			Expect(dir).To(HaveLen(2))
-			actualFilenames := []string{dir[0].Name(), dir[1].Name()}
+			Expect(dir).To(HaveLen(4))
+			actualFilenames := make([]string, 0, len(dir))
+			for _, d := range dir {
+				actualFilenames = append(actualFilenames, d.Name())
+			}
 			Expect(actualFilenames).To(ConsistOf(expectedFileNames))
+
+			// the certificate and the key are written to separate files
+			cert, err := os.ReadFile(path.Join(tmpSecretsDir, "test_secret1.crt"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cert).To(Equal(secret1.Data[apiv1.TLSCertKey]))
+
+			key, err := os.ReadFile(path.Join(tmpSecretsDir, "test_secret1.key"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key).To(Equal(secret1.Data[apiv1.TLSPrivateKeyKey]))
 		})
 
-		It("request should return the file path for secret after write", func() {
+		It("request should return the file paths for secret after write", func() {
 			fakeStore.GetReturns(&state.Secret{Secret: secret3, Valid: true})
-			expectedPath := path.Join(tmpSecretsDir, "test_secret3")
+			expectedPaths := state.SecretPaths{
+				Certificate: path.Join(tmpSecretsDir, "test_secret3.crt"),
+				Key:         path.Join(tmpSecretsDir, "test_secret3.key"),
+			}
 
-			testRequest(secret3, expectedPath, false)
+			testRequest(secret3, expectedPaths, false)
 		})
 
 		It("should write all requested secrets", func() {
-			err := memMgr.WriteAllRequestedSecrets()
+			_, err := memMgr.WriteAllRequestedSecrets()
 			Expect(err).ToNot(HaveOccurred())
 
 			// read all files from directory
@@ -225,12 +265,12 @@ var _ = Describe("SecretDiskMemoryManager", func() {
 			Expect(err).ToNot(HaveOccurred())
 
 			// only the secrets stored after the last write should be written to disk.
-			Expect(dir).To(HaveLen(1))
-			Expect(dir[0].Name()).To(Equal("test_secret3"))
+			Expect(dir).To(HaveLen(2))
+			Expect([]string{dir[0].Name(), dir[1].Name()}).To(ConsistOf("test_secret3.crt", "test_secret3.key"))
 		})
 		When("no secrets are requested", func() {
 			It("write all secrets should remove all existing secrets and write no additional secrets", func() {
-				err := memMgr.WriteAllRequestedSecrets()
+				_, err := memMgr.WriteAllRequestedSecrets()
 				Expect(err).ToNot(HaveOccurred())
 
 				// read all files from directory
@@ -262,11 +302,37 @@ var _ = Describe("SecretDiskMemoryManager", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("should return a hash that changes only when the contents of the secrets change", func() {
+			nsname := types.NamespacedName{Namespace: secret1.Namespace, Name: secret1.Name}
+
+			hash, err := memMgr.WriteAllRequestedSecrets()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hash).ToNot(BeEmpty())
+
+			_, err = memMgr.Request(nsname)
+			Expect(err).ToNot(HaveOccurred())
+
+			sameHash, err := memMgr.WriteAllRequestedSecrets()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sameHash).To(Equal(hash))
+
+			rotatedSecret := secret1.DeepCopy()
+			rotatedSecret.Data[apiv1.TLSCertKey] = []byte("rotated certificate")
+			fakeStore.GetReturns(&state.Secret{Secret: rotatedSecret, Valid: true})
+
+			_, err = memMgr.Request(nsname)
+			Expect(err).ToNot(HaveOccurred())
+
+			rotatedHash, err := memMgr.WriteAllRequestedSecrets()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rotatedHash).ToNot(Equal(hash))
+		})
+
 		DescribeTable("error cases", Ordered,
 			func(e error, preparer func(e error)) {
 				preparer(e)
 
-				err := memMgr.WriteAllRequestedSecrets()
+				_, err := memMgr.WriteAllRequestedSecrets()
 				Expect(err).To(MatchError(e))
 			},
 			Entry("read directory error", errors.New("read dir"),
@@ -319,8 +385,11 @@ var _ = Describe("SecretStore", func() {
 			actualSecret := store.Get(nsname)
 			if valid {
 				Expect(actualSecret.Valid).To(BeTrue())
+				Expect(actualSecret.Certificate).ToNot(BeNil())
+				Expect(actualSecret.Certificate.Subject.CommonName).To(Equal("cafe.example.com"))
 			} else {
 				Expect(actualSecret.Valid).To(BeFalse())
+				Expect(actualSecret.Certificate).To(BeNil())
 			}
 			Expect(actualSecret.Secret).To(Equal(s))
 		}
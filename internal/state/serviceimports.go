@@ -0,0 +1,133 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ServiceImportBackendKind is the kind of the ServiceImports of the Multi-Cluster Services API, which the backendRefs
+// reference to route to a Service exported by the clusters of a ClusterSet.
+var ServiceImportBackendKind = BackendKind{Group: "multicluster.x-k8s.io", Kind: "ServiceImport"}
+
+// ServiceImportGVK is the group, version and kind of the ServiceImports the Gateway watches.
+// The Multi-Cluster Services API is not a part of the Kubernetes API, so the ServiceImports are handled
+// as unstructured resources.
+var ServiceImportGVK = schema.GroupVersionKind{
+	Group:   ServiceImportBackendKind.Group,
+	Version: "v1alpha1",
+	Kind:    ServiceImportBackendKind.Kind,
+}
+
+// IsServiceImport returns true if the unstructured resource is a ServiceImport.
+func IsServiceImport(obj *unstructured.Unstructured) bool {
+	return obj.GroupVersionKind().GroupKind() == ServiceImportGVK.GroupKind()
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . ServiceImportStore
+
+// ServiceImportStore stores the ServiceImports and resolves their ClusterSet IPs.
+// It is the BackendResolver of ServiceImportBackendKind.
+// The ServiceImports are upserted by the EventHandler and resolved by the Generator concurrently, so the store is
+// safe for concurrent use.
+type ServiceImportStore interface {
+	BackendResolver
+	// Upsert upserts the ServiceImport into the store.
+	Upsert(si *unstructured.Unstructured)
+	// Delete deletes the ServiceImport from the store.
+	Delete(nsname types.NamespacedName)
+}
+
+// NewServiceImportStore creates a new ServiceImportStore.
+func NewServiceImportStore() ServiceImportStore {
+	return &serviceImportStoreImpl{
+		imports: make(map[types.NamespacedName]serviceImport),
+	}
+}
+
+// serviceImport holds the fields of a ServiceImport the store needs to resolve it.
+type serviceImport struct {
+	ips      []string
+	ports    []int64
+	headless bool
+}
+
+type serviceImportStoreImpl struct {
+	lock    sync.RWMutex
+	imports map[types.NamespacedName]serviceImport
+}
+
+func (s *serviceImportStoreImpl) Upsert(si *unstructured.Unstructured) {
+	nsname := types.NamespacedName{Namespace: si.GetNamespace(), Name: si.GetName()}
+	parsed := parseServiceImport(si)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.imports[nsname] = parsed
+}
+
+func (s *serviceImportStoreImpl) Delete(nsname types.NamespacedName) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.imports, nsname)
+}
+
+func (s *serviceImportStoreImpl) Resolve(ctx context.Context, nsname types.NamespacedName, port int32) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	s.lock.RLock()
+	si, exist := s.imports[nsname]
+	s.lock.RUnlock()
+
+	if !exist {
+		return "", fmt.Errorf("serviceimport %s doesn't exist", nsname.String())
+	}
+
+	if si.headless || len(si.ips) == 0 {
+		return "", fmt.Errorf("serviceimport %s doesn't have ClusterSet IP", nsname.String())
+	}
+
+	for _, p := range si.ports {
+		if p == int64(port) {
+			return si.ips[0], nil
+		}
+	}
+
+	return "", fmt.Errorf("serviceimport %s doesn't expose port %d", nsname.String(), port)
+}
+
+// parseServiceImport parses the type, the IPs and the ports of the spec of a ServiceImport.
+// The malformed fields are treated as missing.
+func parseServiceImport(si *unstructured.Unstructured) serviceImport {
+	var parsed serviceImport
+
+	siType, _, _ := unstructured.NestedString(si.Object, "spec", "type")
+	parsed.headless = siType == "Headless"
+
+	parsed.ips, _, _ = unstructured.NestedStringSlice(si.Object, "spec", "ips")
+
+	ports, _, _ := unstructured.NestedSlice(si.Object, "spec", "ports")
+	for _, p := range ports {
+		portObj, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		port, found, err := unstructured.NestedInt64(portObj, "port")
+		if err != nil || !found {
+			continue
+		}
+
+		parsed.ports = append(parsed.ports, port)
+	}
+
+	return parsed
+}
@@ -0,0 +1,143 @@
+package state
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "github.com/onsi/gomega"
+)
+
+func createServiceImport(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	si := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	si.SetGroupVersionKind(ServiceImportGVK)
+	si.SetNamespace("test")
+	si.SetName(name)
+
+	return si
+}
+
+var _ = Describe("ServiceImportStore", func() {
+	var store ServiceImportStore
+
+	BeforeEach(OncePerOrdered, func() {
+		store = NewServiceImportStore()
+	})
+
+	Describe("Resolve ServiceImport", Ordered, func() {
+		nsname := types.NamespacedName{Namespace: "test", Name: "import1"}
+
+		It("should add a serviceimport", func() {
+			store.Upsert(createServiceImport("import1", map[string]interface{}{
+				"type": "ClusterSetIP",
+				"ips":  []interface{}{"10.0.1.1"},
+				"ports": []interface{}{
+					map[string]interface{}{"port": int64(80)},
+				},
+			}))
+		})
+
+		It("should resolve the serviceimport", func() {
+			address, err := store.Resolve(context.Background(), nsname, 80)
+
+			Expect(address).To(Equal("10.0.1.1"))
+			Expect(err).To(BeNil())
+		})
+
+		It("should fail to resolve the serviceimport when the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := store.Resolve(ctx, nsname, 80)
+
+			Expect(err).To(MatchError(context.Canceled))
+		})
+
+		It("should fail to resolve the serviceimport with a port it doesn't expose", func() {
+			_, err := store.Resolve(context.Background(), nsname, 8080)
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should delete the serviceimport", func() {
+			store.Delete(nsname)
+		})
+
+		It("should fail to resolve the serviceimport", func() {
+			_, err := store.Resolve(context.Background(), nsname, 80)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("should be safe for concurrent use", func() {
+		// run with -race to detect the unsynchronized accesses
+		nsname := types.NamespacedName{Namespace: "test", Name: "import1"}
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				store.Upsert(createServiceImport("import1", map[string]interface{}{
+					"type": "ClusterSetIP",
+					"ips":  []interface{}{"10.0.1.1"},
+				}))
+				store.Delete(nsname)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_, _ = store.Resolve(context.Background(), nsname, 80)
+			}
+		}()
+
+		wg.Wait()
+	})
+
+	Describe("Edge cases", func() {
+		BeforeEach(func() {
+			store.Upsert(createServiceImport("no-ips", map[string]interface{}{
+				"type": "ClusterSetIP",
+				"ports": []interface{}{
+					map[string]interface{}{"port": int64(80)},
+				},
+			}))
+
+			store.Upsert(createServiceImport("headless", map[string]interface{}{
+				"type": "Headless",
+				"ips":  []interface{}{"10.0.1.1"},
+				"ports": []interface{}{
+					map[string]interface{}{"port": int64(80)},
+				},
+			}))
+
+			store.Upsert(createServiceImport("malformed-ports", map[string]interface{}{
+				"type":  "ClusterSetIP",
+				"ips":   []interface{}{"10.0.1.1"},
+				"ports": "80",
+			}))
+		})
+		DescribeTable("Resolve returns error",
+			func(nsname types.NamespacedName) {
+				_, err := store.Resolve(context.Background(), nsname, 80)
+
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("ips are empty", types.NamespacedName{Namespace: "test", Name: "no-ips"}),
+			Entry("serviceimport is headless", types.NamespacedName{Namespace: "test", Name: "headless"}),
+			Entry("ports are malformed", types.NamespacedName{Namespace: "test", Name: "malformed-ports"}),
+			Entry("serviceimport doesn't exist", types.NamespacedName{Namespace: "test", Name: "import"}),
+		)
+	})
+})
@@ -1,28 +1,98 @@
 package state
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . ServiceStore
 
-// ServiceStore stores services and can be queried for the cluster IP of a service.
+// ServiceStore stores services and can be queried for the address of a service.
+// The Generator resolves the services in goroutines that can outlive a generation bounded by a timeout, while
+// the EventHandler updates the store, so an implementation must be safe for concurrent use.
+// The implementations are created by their names with NewServiceStoreByName.
 type ServiceStore interface {
-	// Upsert upserts the service into the store.
+	// Upsert upserts the service into the store. The EventHandler calls it for every upserted Service of the cluster.
+	// An implementation that doesn't resolve the services of the cluster can ignore it.
 	Upsert(svc *v1.Service)
-	// Delete deletes the service from the store.
+	// Delete deletes the service from the store. The EventHandler calls it for every deleted Service of the cluster.
+	// An implementation that doesn't resolve the services of the cluster can ignore it.
 	Delete(nsname types.NamespacedName)
 	// Resolve returns the cluster IP  the service specified by its namespace and name.
-	// If the service doesn't have a cluster IP or it doesn't exist, resolve will return an error.
+	// For an ExternalName service, it returns the external hostname, which NGINX resolves at runtime, regardless
+	// of the port.
+	// If the service doesn't have a cluster IP, doesn't expose the port or doesn't exist,
+	// resolve will return an error. If ctx is done before the service is resolved, resolve returns the error of ctx.
 	// FIXME(pleshakov): later, we will start using the Endpoints rather than cluster IPs.
-	Resolve(nsname types.NamespacedName) (string, error)
+	Resolve(ctx context.Context, nsname types.NamespacedName, port int32) (string, error)
 }
 
-// NewServiceStore creates a new ServiceStore.
+const (
+	// ServiceStoreClusterIP is the name of the ServiceStore that resolves the services of the cluster to their
+	// cluster IPs. It is the default.
+	ServiceStoreClusterIP = "cluster-ip"
+	// ServiceStoreStatic is the name of the ServiceStore that resolves the ports of the services to fixed addresses,
+	// for example, to the addresses of the backends that run locally during the development.
+	ServiceStoreStatic = "static"
+)
+
+// ServiceStoreConfig is the configuration of the constructors of the ServiceStores.
+type ServiceStoreConfig struct {
+	// StaticAddresses are the addresses of the static ServiceStore in the form namespace/name:port=address.
+	StaticAddresses []string
+}
+
+// ServiceStoreConstructor creates a ServiceStore from the configuration.
+type ServiceStoreConstructor func(cfg ServiceStoreConfig) (ServiceStore, error)
+
+// serviceStoreConstructors are the constructors of the ServiceStores by their names.
+var serviceStoreConstructors = map[string]ServiceStoreConstructor{
+	ServiceStoreClusterIP: func(ServiceStoreConfig) (ServiceStore, error) {
+		return NewServiceStore(), nil
+	},
+	ServiceStoreStatic: func(cfg ServiceStoreConfig) (ServiceStore, error) {
+		addresses, err := ParseStaticServiceAddresses(cfg.StaticAddresses)
+		if err != nil {
+			return nil, err
+		}
+		return NewStaticServiceStore(addresses), nil
+	},
+}
+
+// ServiceStoreNames returns the sorted names of the ServiceStores that NewServiceStoreByName creates.
+func ServiceStoreNames() []string {
+	names := make([]string, 0, len(serviceStoreConstructors))
+	for name := range serviceStoreConstructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NewServiceStoreByName creates the ServiceStore with the name from the configuration.
+// It returns an error if there is no ServiceStore with the name or the configuration is invalid for it.
+func NewServiceStoreByName(name string, cfg ServiceStoreConfig) (ServiceStore, error) {
+	constructor, exist := serviceStoreConstructors[name]
+	if !exist {
+		return nil, fmt.Errorf("unknown service store %q, must be one of: %s", name,
+			strings.Join(ServiceStoreNames(), ", "))
+	}
+
+	return constructor(cfg)
+}
+
+// NewServiceStore creates a new ServiceStore that resolves the services of the cluster to their cluster IPs.
 func NewServiceStore() ServiceStore {
 	return &serviceStoreImpl{
 		services: make(map[string]*v1.Service),
@@ -30,30 +100,160 @@ func NewServiceStore() ServiceStore {
 }
 
 type serviceStoreImpl struct {
+	// lock protects services.
+	lock     sync.RWMutex
 	services map[string]*v1.Service
 }
 
 func (s *serviceStoreImpl) Upsert(svc *v1.Service) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	s.services[getResourceKey(&svc.ObjectMeta)] = svc
 }
 
 func (s *serviceStoreImpl) Delete(nsname types.NamespacedName) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	delete(s.services, nsname.String())
 }
 
-func (s *serviceStoreImpl) Resolve(nsname types.NamespacedName) (string, error) {
+func (s *serviceStoreImpl) Resolve(ctx context.Context, nsname types.NamespacedName, port int32) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	s.lock.RLock()
 	svc, exist := s.services[nsname.String()]
+	s.lock.RUnlock()
+
 	if !exist {
 		return "", fmt.Errorf("service %s doesn't exist", nsname.String())
 	}
 
+	if svc.Spec.Type == v1.ServiceTypeExternalName {
+		if svc.Spec.ExternalName == "" {
+			return "", fmt.Errorf("service %s doesn't have ExternalName", nsname.String())
+		}
+		return svc.Spec.ExternalName, nil
+	}
+
 	if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == "None" {
 		return "", fmt.Errorf("service %s doesn't have ClusterIP", nsname.String())
 	}
 
+	if !exposesPort(svc, port) {
+		return "", fmt.Errorf("service %s doesn't expose port %d", nsname.String(), port)
+	}
+
 	return svc.Spec.ClusterIP, nil
 }
 
+func exposesPort(svc *v1.Service, port int32) bool {
+	for _, p := range svc.Spec.Ports {
+		if p.Port == port {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ServicePort is a port of a service.
+type ServicePort struct {
+	NsName types.NamespacedName
+	Port   int32
+}
+
+// ParseServicePort parses a port of a service in the form namespace/name:port.
+func ParseServicePort(ref string) (ServicePort, error) {
+	nsname, portStr, found := strings.Cut(ref, ":")
+	if !found {
+		return ServicePort{}, fmt.Errorf("invalid service port %q, must be namespace/name:port", ref)
+	}
+
+	namespace, name, found := strings.Cut(nsname, "/")
+	if !found || len(validation.IsDNS1123Label(namespace)) > 0 || len(validation.IsDNS1035Label(name)) > 0 {
+		return ServicePort{}, fmt.Errorf("invalid service %q in the service port %q", nsname, ref)
+	}
+
+	port, err := strconv.ParseInt(portStr, 10, 32)
+	if err != nil || port < 1 || port > 65535 {
+		return ServicePort{}, fmt.Errorf("invalid port %q in the service port %q", portStr, ref)
+	}
+
+	return ServicePort{
+		NsName: types.NamespacedName{Namespace: namespace, Name: name},
+		Port:   int32(port),
+	}, nil
+}
+
+// ParseStaticServiceAddresses parses the addresses of the static ServiceStore in the form
+// namespace/name:port=address, where the address is an IP address or a hostname.
+func ParseStaticServiceAddresses(entries []string) (map[ServicePort]string, error) {
+	addresses := make(map[ServicePort]string, len(entries))
+
+	for _, entry := range entries {
+		ref, address, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid static service address %q, must be namespace/name:port=address", entry)
+		}
+
+		svcPort, err := ParseServicePort(ref)
+		if err != nil {
+			return nil, fmt.Errorf("invalid static service address %q: %w", entry, err)
+		}
+
+		if net.ParseIP(address) == nil && len(validation.IsDNS1123Subdomain(address)) > 0 {
+			return nil, fmt.Errorf("invalid address %q in the static service address %q", address, entry)
+		}
+
+		if _, exist := addresses[svcPort]; exist {
+			return nil, fmt.Errorf("duplicate static service address for %s", ref)
+		}
+
+		addresses[svcPort] = address
+	}
+
+	return addresses, nil
+}
+
+// NewStaticServiceStore creates a ServiceStore that resolves the ports of the services to the fixed addresses.
+// The store ignores the services of the cluster: Upsert and Delete don't change it.
+func NewStaticServiceStore(addresses map[ServicePort]string) ServiceStore {
+	s := &staticServiceStore{
+		addresses: make(map[ServicePort]string, len(addresses)),
+	}
+	for port, address := range addresses {
+		s.addresses[port] = address
+	}
+
+	return s
+}
+
+// staticServiceStore is safe for concurrent use, because its addresses don't change after it is created.
+type staticServiceStore struct {
+	addresses map[ServicePort]string
+}
+
+func (s *staticServiceStore) Upsert(*v1.Service) {}
+
+func (s *staticServiceStore) Delete(types.NamespacedName) {}
+
+func (s *staticServiceStore) Resolve(ctx context.Context, nsname types.NamespacedName, port int32) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	address, exist := s.addresses[ServicePort{NsName: nsname, Port: port}]
+	if !exist {
+		return "", fmt.Errorf("service %s doesn't have a static address for port %d", nsname.String(), port)
+	}
+
+	return address, nil
+}
+
 func getResourceKey(meta *metav1.ObjectMeta) string {
 	return fmt.Sprintf("%s/%s", meta.Namespace, meta.Name)
 }
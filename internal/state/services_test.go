@@ -1,6 +1,9 @@
 package state
 
 import (
+	"context"
+	"sync"
+
 	. "github.com/onsi/ginkgo/v2"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,6 +31,11 @@ var _ = Describe("ServiceStore", func() {
 				},
 				Spec: apiv1.ServiceSpec{
 					ClusterIP: "10.0.0.1",
+					Ports: []apiv1.ServicePort{
+						{
+							Port: 80,
+						},
+					},
 				},
 			}
 
@@ -42,18 +50,33 @@ var _ = Describe("ServiceStore", func() {
 		})
 
 		It("should resolve the service", func() {
-			address, err := store.Resolve(types.NamespacedName{Namespace: "test", Name: "service1"})
+			address, err := store.Resolve(context.Background(), types.NamespacedName{Namespace: "test", Name: "service1"}, 80)
 
 			Expect(address).To(Equal("10.0.0.1"))
 			Expect(err).To(BeNil())
 		})
 
+		It("should fail to resolve the service when the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := store.Resolve(ctx, types.NamespacedName{Namespace: "test", Name: "service1"}, 80)
+
+			Expect(err).To(MatchError(context.Canceled))
+		})
+
+		It("should fail to resolve the service with a port it doesn't expose", func() {
+			_, err := store.Resolve(context.Background(), types.NamespacedName{Namespace: "test", Name: "service1"}, 8080)
+
+			Expect(err).To(HaveOccurred())
+		})
+
 		It("should update the service", func() {
 			store.Upsert(svcUpdated)
 		})
 
 		It("should resolve the updated service", func() {
-			address, err := store.Resolve(types.NamespacedName{Namespace: "test", Name: "service1"})
+			address, err := store.Resolve(context.Background(), types.NamespacedName{Namespace: "test", Name: "service1"}, 80)
 
 			Expect(address).To(Equal("10.0.0.2"))
 			Expect(err).To(BeNil())
@@ -65,12 +88,115 @@ var _ = Describe("ServiceStore", func() {
 		})
 
 		It("should fail to resolve the service", func() {
-			_, err := store.Resolve(types.NamespacedName{Namespace: "test", Name: "service1"})
+			_, err := store.Resolve(context.Background(), types.NamespacedName{Namespace: "test", Name: "service1"}, 80)
 
 			Expect(err).To(HaveOccurred())
 		})
 	})
 
+	It("should be safe for concurrent use", func() {
+		// run with -race to detect the unsynchronized accesses
+		nsname := types.NamespacedName{Namespace: "test", Name: "service1"}
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				store.Upsert(&apiv1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "test",
+						Name:      "service1",
+					},
+					Spec: apiv1.ServiceSpec{
+						ClusterIP: "10.0.0.1",
+						Ports: []apiv1.ServicePort{
+							{
+								Port: 80,
+							},
+						},
+					},
+				})
+				store.Delete(nsname)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_, _ = store.Resolve(context.Background(), nsname, 80)
+			}
+		}()
+
+		wg.Wait()
+	})
+
+	Describe("Resolve Service with multiple ports", func() {
+		nsname := types.NamespacedName{Namespace: "test", Name: "multi-port"}
+
+		BeforeEach(func() {
+			store.Upsert(&apiv1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "multi-port",
+				},
+				Spec: apiv1.ServiceSpec{
+					ClusterIP: "10.0.0.3",
+					Ports: []apiv1.ServicePort{
+						{
+							Name: "http",
+							Port: 80,
+						},
+						{
+							Name: "metrics",
+							Port: 9113,
+						},
+					},
+				},
+			})
+		})
+
+		DescribeTable("should resolve the service by any of its ports",
+			func(port int32) {
+				address, err := store.Resolve(context.Background(), nsname, port)
+
+				Expect(address).To(Equal("10.0.0.3"))
+				Expect(err).To(BeNil())
+			},
+			Entry("first port", int32(80)),
+			Entry("second port", int32(9113)),
+		)
+
+		It("should fail to resolve the service with a port it doesn't expose", func() {
+			_, err := store.Resolve(context.Background(), nsname, 8080)
+
+			Expect(err).To(MatchError("service test/multi-port doesn't expose port 8080"))
+		})
+	})
+
+	Describe("Resolve ExternalName Service", func() {
+		BeforeEach(func() {
+			store.Upsert(&apiv1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "external",
+				},
+				Spec: apiv1.ServiceSpec{
+					Type:         apiv1.ServiceTypeExternalName,
+					ExternalName: "api.example.com",
+				},
+			})
+		})
+
+		It("should resolve the service to the external hostname for any port", func() {
+			address, err := store.Resolve(context.Background(), types.NamespacedName{Namespace: "test", Name: "external"}, 443)
+
+			Expect(address).To(Equal("api.example.com"))
+			Expect(err).To(BeNil())
+		})
+	})
+
 	Describe("Edge cases", func() {
 		BeforeEach(func() {
 			store.Upsert(&apiv1.Service{
@@ -89,16 +215,124 @@ var _ = Describe("ServiceStore", func() {
 					ClusterIP: "None",
 				},
 			})
+
+			store.Upsert(&apiv1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "empty-external-name",
+				},
+				Spec: apiv1.ServiceSpec{
+					Type: apiv1.ServiceTypeExternalName,
+				},
+			})
 		})
 		DescribeTable("Resolve returns error",
 			func(nsname types.NamespacedName) {
-				_, err := store.Resolve(nsname)
+				_, err := store.Resolve(context.Background(), nsname, 80)
 
 				Expect(err).To(HaveOccurred())
 			},
 			Entry("cluster ip is empty", types.NamespacedName{Namespace: "test", Name: "empty-ip"}),
 			Entry("cluster ip is none", types.NamespacedName{Namespace: "test", Name: "none-ip"}),
+			Entry("external name is empty", types.NamespacedName{Namespace: "test", Name: "empty-external-name"}),
 			Entry("service doesn't exist", types.NamespacedName{Namespace: "test", Name: "service"}),
 		)
 	})
 })
+
+var _ = Describe("StaticServiceStore", func() {
+	var store ServiceStore
+
+	BeforeEach(func() {
+		store = NewStaticServiceStore(map[ServicePort]string{
+			{NsName: types.NamespacedName{Namespace: "test", Name: "service1"}, Port: 80}:   "127.0.0.1",
+			{NsName: types.NamespacedName{Namespace: "test", Name: "service1"}, Port: 9113}: "127.0.0.2",
+		})
+	})
+
+	It("should resolve the ports of the service to their addresses", func() {
+		address, err := store.Resolve(context.Background(), types.NamespacedName{Namespace: "test", Name: "service1"}, 80)
+
+		Expect(address).To(Equal("127.0.0.1"))
+		Expect(err).To(BeNil())
+
+		address, err = store.Resolve(context.Background(), types.NamespacedName{Namespace: "test", Name: "service1"}, 9113)
+
+		Expect(address).To(Equal("127.0.0.2"))
+		Expect(err).To(BeNil())
+	})
+
+	It("should fail to resolve a port without an address", func() {
+		_, err := store.Resolve(context.Background(), types.NamespacedName{Namespace: "test", Name: "service1"}, 8080)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail to resolve the service when the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := store.Resolve(ctx, types.NamespacedName{Namespace: "test", Name: "service1"}, 80)
+
+		Expect(err).To(MatchError(context.Canceled))
+	})
+
+	It("should ignore the services of the cluster", func() {
+		store.Upsert(&apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test",
+				Name:      "service2",
+			},
+			Spec: apiv1.ServiceSpec{
+				ClusterIP: "10.0.0.1",
+				Ports: []apiv1.ServicePort{
+					{
+						Port: 80,
+					},
+				},
+			},
+		})
+		store.Delete(types.NamespacedName{Namespace: "test", Name: "service1"})
+
+		_, err := store.Resolve(context.Background(), types.NamespacedName{Namespace: "test", Name: "service2"}, 80)
+		Expect(err).To(HaveOccurred())
+
+		address, err := store.Resolve(context.Background(), types.NamespacedName{Namespace: "test", Name: "service1"}, 80)
+		Expect(address).To(Equal("127.0.0.1"))
+		Expect(err).To(BeNil())
+	})
+})
+
+var _ = Describe("NewServiceStoreByName", func() {
+	It("should create the cluster IP service store", func() {
+		store, err := NewServiceStoreByName(ServiceStoreClusterIP, ServiceStoreConfig{})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store).To(BeAssignableToTypeOf(&serviceStoreImpl{}))
+	})
+
+	It("should create the static service store from the addresses", func() {
+		store, err := NewServiceStoreByName(ServiceStoreStatic, ServiceStoreConfig{
+			StaticAddresses: []string{"test/service1:80=127.0.0.1"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		address, err := store.Resolve(context.Background(), types.NamespacedName{Namespace: "test", Name: "service1"}, 80)
+		Expect(address).To(Equal("127.0.0.1"))
+		Expect(err).To(BeNil())
+	})
+
+	It("should fail with invalid static addresses", func() {
+		_, err := NewServiceStoreByName(ServiceStoreStatic, ServiceStoreConfig{
+			StaticAddresses: []string{"test/service1=127.0.0.1"},
+		})
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail with an unknown name", func() {
+		_, err := NewServiceStoreByName("endpoints", ServiceStoreConfig{})
+
+		Expect(err).To(MatchError(`unknown service store "endpoints", must be one of: cluster-ip, static`))
+	})
+})
@@ -6,7 +6,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func sortMatchRules(matchRules []MatchRule) {
+// SortMatchRules sorts the match rules that share a path by their precedence, highest first.
+func SortMatchRules(matchRules []MatchRule) {
 	// stable sort is used so that the order of matches (as defined in each HTTPRoute rule) is preserved
 	// this is important, because the winning match is the first match to win.
 	sort.SliceStable(
@@ -24,6 +25,7 @@ Precedence must be given to the Rule with the largest number of (Continuing on t
 - Characters in a matching non-wildcard hostname.
 - Characters in a matching hostname.
 - Characters in a matching path.
+- Method match.
 - Header matches.
 - Query param matches.
 
@@ -33,13 +35,21 @@ If ties still exist across multiple Routes, matching precedence MUST be determin
 
 If ties still exist within the Route that has been given precedence, matching precedence MUST be granted to the first matching rule meeting the above criteria.
 
-higherPriority will determine precedence by comparing len(headers), len(query parameters), creation timestamp, and namespace name. The other criteria are handled by NGINX.
+higherPriority will determine precedence by comparing the method, len(headers), len(query parameters), creation timestamp, and namespace name. The other criteria are handled by NGINX.
+A match can only have one method, so the requests with different methods to the same path are matched by multiple
+matches. A match with a method has a higher priority than a match without one, so a match without conditions (Any)
+never shadows the method matches.
 */
 func higherPriority(rule1, rule2 MatchRule) bool {
 	// Get the matches from the rules
 	match1 := rule1.GetMatch()
 	match2 := rule2.GetMatch()
 
+	// The match with a method wins over the match without a method
+	if (match1.Method == nil) != (match2.Method == nil) {
+		return match1.Method != nil
+	}
+
 	// If both matches exists then compare the number of header matches
 	// The match with the largest number of header matches wins
 	l1 := len(match1.Headers)
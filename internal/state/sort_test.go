@@ -198,10 +198,127 @@ func TestSort(t *testing.T) {
 		},
 	}
 
-	sortMatchRules(routes)
+	SortMatchRules(routes)
 
 	if diff := cmp.Diff(sortedRoutes, routes); diff != "" {
-		t.Errorf("sortMatchRules() mismatch (-want +got):\n%s", diff)
+		t.Errorf("SortMatchRules() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSortMethodMatches(t *testing.T) {
+	earlier := metav1.Now()
+	later := metav1.NewTime(earlier.Add(1 * time.Second))
+
+	createMethodMatch := func(method v1beta1.HTTPMethod) v1beta1.HTTPRouteMatch {
+		return v1beta1.HTTPRouteMatch{
+			Path: &v1beta1.HTTPPathMatch{
+				Value: helpers.GetStringPointer("/path"),
+			},
+			Method: &method,
+		}
+	}
+
+	anyMatch := v1beta1.HTTPRouteMatch{
+		Path: &v1beta1.HTTPPathMatch{
+			Value: helpers.GetStringPointer("/path"),
+		},
+	}
+	headerMatch := v1beta1.HTTPRouteMatch{
+		Path: &v1beta1.HTTPPathMatch{
+			Value: helpers.GetStringPointer("/path"),
+		},
+		Headers: []v1beta1.HTTPHeaderMatch{
+			{
+				Name:  "header1",
+				Value: "value1",
+			},
+		},
+	}
+
+	// the older route would win the ties, so its Any match would shadow the method matches of the newer route
+	hrAny := v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "hr-any",
+			Namespace:         "test",
+			CreationTimestamp: earlier,
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{anyMatch, headerMatch},
+				},
+			},
+		},
+	}
+
+	hrMethods := v1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "hr-methods",
+			Namespace:         "test",
+			CreationTimestamp: later,
+		},
+		Spec: v1beta1.HTTPRouteSpec{
+			Rules: []v1beta1.HTTPRouteRule{
+				{
+					Matches: []v1beta1.HTTPRouteMatch{
+						createMethodMatch(v1beta1.HTTPMethodGet),
+						createMethodMatch(v1beta1.HTTPMethodPost),
+					},
+				},
+			},
+		},
+	}
+
+	rules := []MatchRule{
+		{
+			MatchIdx: 0, // Any
+			RuleIdx:  0,
+			Source:   &hrAny,
+		},
+		{
+			MatchIdx: 1, // header
+			RuleIdx:  0,
+			Source:   &hrAny,
+		},
+		{
+			MatchIdx: 0, // GET
+			RuleIdx:  0,
+			Source:   &hrMethods,
+		},
+		{
+			MatchIdx: 1, // POST
+			RuleIdx:  0,
+			Source:   &hrMethods,
+		},
+	}
+
+	expected := []MatchRule{
+		{
+			MatchIdx: 0, // GET
+			RuleIdx:  0,
+			Source:   &hrMethods,
+		},
+		{
+			MatchIdx: 1, // POST
+			RuleIdx:  0,
+			Source:   &hrMethods,
+		},
+		{
+			MatchIdx: 1, // header
+			RuleIdx:  0,
+			Source:   &hrAny,
+		},
+		{
+			MatchIdx: 0, // Any
+			RuleIdx:  0,
+			Source:   &hrAny,
+		},
+	}
+
+	SortMatchRules(rules)
+
+	if diff := cmp.Diff(expected, rules); diff != "" {
+		t.Errorf("SortMatchRules() mismatch (-want +got):\n%s", diff)
 	}
 }
 
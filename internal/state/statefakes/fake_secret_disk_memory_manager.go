@@ -2,6 +2,7 @@
 package statefakes
 
 import (
+	"crypto/x509"
 	"sync"
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
@@ -9,34 +10,108 @@ import (
 )
 
 type FakeSecretDiskMemoryManager struct {
-	RequestStub        func(types.NamespacedName) (string, error)
+	CertificateStub        func(types.NamespacedName) *x509.Certificate
+	certificateMutex       sync.RWMutex
+	certificateArgsForCall []struct {
+		arg1 types.NamespacedName
+	}
+	certificateReturns struct {
+		result1 *x509.Certificate
+	}
+	certificateReturnsOnCall map[int]struct {
+		result1 *x509.Certificate
+	}
+	RequestStub        func(types.NamespacedName) (state.SecretPaths, error)
 	requestMutex       sync.RWMutex
 	requestArgsForCall []struct {
 		arg1 types.NamespacedName
 	}
 	requestReturns struct {
-		result1 string
+		result1 state.SecretPaths
 		result2 error
 	}
 	requestReturnsOnCall map[int]struct {
-		result1 string
+		result1 state.SecretPaths
 		result2 error
 	}
-	WriteAllRequestedSecretsStub        func() error
+	WriteAllRequestedSecretsStub        func() (string, error)
 	writeAllRequestedSecretsMutex       sync.RWMutex
 	writeAllRequestedSecretsArgsForCall []struct {
 	}
 	writeAllRequestedSecretsReturns struct {
-		result1 error
+		result1 string
+		result2 error
 	}
 	writeAllRequestedSecretsReturnsOnCall map[int]struct {
-		result1 error
+		result1 string
+		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeSecretDiskMemoryManager) Request(arg1 types.NamespacedName) (string, error) {
+func (fake *FakeSecretDiskMemoryManager) Certificate(arg1 types.NamespacedName) *x509.Certificate {
+	fake.certificateMutex.Lock()
+	ret, specificReturn := fake.certificateReturnsOnCall[len(fake.certificateArgsForCall)]
+	fake.certificateArgsForCall = append(fake.certificateArgsForCall, struct {
+		arg1 types.NamespacedName
+	}{arg1})
+	stub := fake.CertificateStub
+	fakeReturns := fake.certificateReturns
+	fake.recordInvocation("Certificate", []interface{}{arg1})
+	fake.certificateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSecretDiskMemoryManager) CertificateCallCount() int {
+	fake.certificateMutex.RLock()
+	defer fake.certificateMutex.RUnlock()
+	return len(fake.certificateArgsForCall)
+}
+
+func (fake *FakeSecretDiskMemoryManager) CertificateCalls(stub func(types.NamespacedName) *x509.Certificate) {
+	fake.certificateMutex.Lock()
+	defer fake.certificateMutex.Unlock()
+	fake.CertificateStub = stub
+}
+
+func (fake *FakeSecretDiskMemoryManager) CertificateArgsForCall(i int) types.NamespacedName {
+	fake.certificateMutex.RLock()
+	defer fake.certificateMutex.RUnlock()
+	argsForCall := fake.certificateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSecretDiskMemoryManager) CertificateReturns(result1 *x509.Certificate) {
+	fake.certificateMutex.Lock()
+	defer fake.certificateMutex.Unlock()
+	fake.CertificateStub = nil
+	fake.certificateReturns = struct {
+		result1 *x509.Certificate
+	}{result1}
+}
+
+func (fake *FakeSecretDiskMemoryManager) CertificateReturnsOnCall(i int, result1 *x509.Certificate) {
+	fake.certificateMutex.Lock()
+	defer fake.certificateMutex.Unlock()
+	fake.CertificateStub = nil
+	if fake.certificateReturnsOnCall == nil {
+		fake.certificateReturnsOnCall = make(map[int]struct {
+			result1 *x509.Certificate
+		})
+	}
+	fake.certificateReturnsOnCall[i] = struct {
+		result1 *x509.Certificate
+	}{result1}
+}
+
+func (fake *FakeSecretDiskMemoryManager) Request(arg1 types.NamespacedName) (state.SecretPaths, error) {
 	fake.requestMutex.Lock()
 	ret, specificReturn := fake.requestReturnsOnCall[len(fake.requestArgsForCall)]
 	fake.requestArgsForCall = append(fake.requestArgsForCall, struct {
@@ -61,7 +136,7 @@ func (fake *FakeSecretDiskMemoryManager) RequestCallCount() int {
 	return len(fake.requestArgsForCall)
 }
 
-func (fake *FakeSecretDiskMemoryManager) RequestCalls(stub func(types.NamespacedName) (string, error)) {
+func (fake *FakeSecretDiskMemoryManager) RequestCalls(stub func(types.NamespacedName) (state.SecretPaths, error)) {
 	fake.requestMutex.Lock()
 	defer fake.requestMutex.Unlock()
 	fake.RequestStub = stub
@@ -74,33 +149,33 @@ func (fake *FakeSecretDiskMemoryManager) RequestArgsForCall(i int) types.Namespa
 	return argsForCall.arg1
 }
 
-func (fake *FakeSecretDiskMemoryManager) RequestReturns(result1 string, result2 error) {
+func (fake *FakeSecretDiskMemoryManager) RequestReturns(result1 state.SecretPaths, result2 error) {
 	fake.requestMutex.Lock()
 	defer fake.requestMutex.Unlock()
 	fake.RequestStub = nil
 	fake.requestReturns = struct {
-		result1 string
+		result1 state.SecretPaths
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeSecretDiskMemoryManager) RequestReturnsOnCall(i int, result1 string, result2 error) {
+func (fake *FakeSecretDiskMemoryManager) RequestReturnsOnCall(i int, result1 state.SecretPaths, result2 error) {
 	fake.requestMutex.Lock()
 	defer fake.requestMutex.Unlock()
 	fake.RequestStub = nil
 	if fake.requestReturnsOnCall == nil {
 		fake.requestReturnsOnCall = make(map[int]struct {
-			result1 string
+			result1 state.SecretPaths
 			result2 error
 		})
 	}
 	fake.requestReturnsOnCall[i] = struct {
-		result1 string
+		result1 state.SecretPaths
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeSecretDiskMemoryManager) WriteAllRequestedSecrets() error {
+func (fake *FakeSecretDiskMemoryManager) WriteAllRequestedSecrets() (string, error) {
 	fake.writeAllRequestedSecretsMutex.Lock()
 	ret, specificReturn := fake.writeAllRequestedSecretsReturnsOnCall[len(fake.writeAllRequestedSecretsArgsForCall)]
 	fake.writeAllRequestedSecretsArgsForCall = append(fake.writeAllRequestedSecretsArgsForCall, struct {
@@ -113,9 +188,9 @@ func (fake *FakeSecretDiskMemoryManager) WriteAllRequestedSecrets() error {
 		return stub()
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeSecretDiskMemoryManager) WriteAllRequestedSecretsCallCount() int {
@@ -124,38 +199,43 @@ func (fake *FakeSecretDiskMemoryManager) WriteAllRequestedSecretsCallCount() int
 	return len(fake.writeAllRequestedSecretsArgsForCall)
 }
 
-func (fake *FakeSecretDiskMemoryManager) WriteAllRequestedSecretsCalls(stub func() error) {
+func (fake *FakeSecretDiskMemoryManager) WriteAllRequestedSecretsCalls(stub func() (string, error)) {
 	fake.writeAllRequestedSecretsMutex.Lock()
 	defer fake.writeAllRequestedSecretsMutex.Unlock()
 	fake.WriteAllRequestedSecretsStub = stub
 }
 
-func (fake *FakeSecretDiskMemoryManager) WriteAllRequestedSecretsReturns(result1 error) {
+func (fake *FakeSecretDiskMemoryManager) WriteAllRequestedSecretsReturns(result1 string, result2 error) {
 	fake.writeAllRequestedSecretsMutex.Lock()
 	defer fake.writeAllRequestedSecretsMutex.Unlock()
 	fake.WriteAllRequestedSecretsStub = nil
 	fake.writeAllRequestedSecretsReturns = struct {
-		result1 error
-	}{result1}
+		result1 string
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeSecretDiskMemoryManager) WriteAllRequestedSecretsReturnsOnCall(i int, result1 error) {
+func (fake *FakeSecretDiskMemoryManager) WriteAllRequestedSecretsReturnsOnCall(i int, result1 string, result2 error) {
 	fake.writeAllRequestedSecretsMutex.Lock()
 	defer fake.writeAllRequestedSecretsMutex.Unlock()
 	fake.WriteAllRequestedSecretsStub = nil
 	if fake.writeAllRequestedSecretsReturnsOnCall == nil {
 		fake.writeAllRequestedSecretsReturnsOnCall = make(map[int]struct {
-			result1 error
+			result1 string
+			result2 error
 		})
 	}
 	fake.writeAllRequestedSecretsReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+		result1 string
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeSecretDiskMemoryManager) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.certificateMutex.RLock()
+	defer fake.certificateMutex.RUnlock()
 	fake.requestMutex.RLock()
 	defer fake.requestMutex.RUnlock()
 	fake.writeAllRequestedSecretsMutex.RLock()
@@ -2,6 +2,7 @@
 package statefakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
@@ -15,10 +16,12 @@ type FakeServiceStore struct {
 	deleteArgsForCall []struct {
 		arg1 types.NamespacedName
 	}
-	ResolveStub        func(types.NamespacedName) (string, error)
+	ResolveStub        func(context.Context, types.NamespacedName, int32) (string, error)
 	resolveMutex       sync.RWMutex
 	resolveArgsForCall []struct {
-		arg1 types.NamespacedName
+		arg1 context.Context
+		arg2 types.NamespacedName
+		arg3 int32
 	}
 	resolveReturns struct {
 		result1 string
@@ -69,18 +72,20 @@ func (fake *FakeServiceStore) DeleteArgsForCall(i int) types.NamespacedName {
 	return argsForCall.arg1
 }
 
-func (fake *FakeServiceStore) Resolve(arg1 types.NamespacedName) (string, error) {
+func (fake *FakeServiceStore) Resolve(arg1 context.Context, arg2 types.NamespacedName, arg3 int32) (string, error) {
 	fake.resolveMutex.Lock()
 	ret, specificReturn := fake.resolveReturnsOnCall[len(fake.resolveArgsForCall)]
 	fake.resolveArgsForCall = append(fake.resolveArgsForCall, struct {
-		arg1 types.NamespacedName
-	}{arg1})
+		arg1 context.Context
+		arg2 types.NamespacedName
+		arg3 int32
+	}{arg1, arg2, arg3})
 	stub := fake.ResolveStub
 	fakeReturns := fake.resolveReturns
-	fake.recordInvocation("Resolve", []interface{}{arg1})
+	fake.recordInvocation("Resolve", []interface{}{arg1, arg2, arg3})
 	fake.resolveMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -94,17 +99,17 @@ func (fake *FakeServiceStore) ResolveCallCount() int {
 	return len(fake.resolveArgsForCall)
 }
 
-func (fake *FakeServiceStore) ResolveCalls(stub func(types.NamespacedName) (string, error)) {
+func (fake *FakeServiceStore) ResolveCalls(stub func(context.Context, types.NamespacedName, int32) (string, error)) {
 	fake.resolveMutex.Lock()
 	defer fake.resolveMutex.Unlock()
 	fake.ResolveStub = stub
 }
 
-func (fake *FakeServiceStore) ResolveArgsForCall(i int) types.NamespacedName {
+func (fake *FakeServiceStore) ResolveArgsForCall(i int) (context.Context, types.NamespacedName, int32) {
 	fake.resolveMutex.RLock()
 	defer fake.resolveMutex.RUnlock()
 	argsForCall := fake.resolveArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeServiceStore) ResolveReturns(result1 string, result2 error) {
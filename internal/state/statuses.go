@@ -8,18 +8,27 @@ type ListenerStatuses map[string]ListenerStatus
 // HTTPRouteStatuses holds the statuses of HTTPRoutes where the key is the namespaced name of an HTTPRoute.
 type HTTPRouteStatuses map[types.NamespacedName]HTTPRouteStatus
 
+// TCPRouteStatuses holds the statuses of TCPRoutes where the key is the namespaced name of a TCPRoute.
+type TCPRouteStatuses map[types.NamespacedName]TCPRouteStatus
+
 // Statuses holds the status-related information about Gateway API resources.
 type Statuses struct {
-	GatewayClassStatus     *GatewayClassStatus
-	GatewayStatus          *GatewayStatus
-	IgnoredGatewayStatuses IgnoredGatewayStatuses
-	HTTPRouteStatuses      HTTPRouteStatuses
+	GatewayClassStatus          *GatewayClassStatus
+	IgnoredGatewayClassStatuses IgnoredGatewayClassStatuses
+	GatewayStatus               *GatewayStatus
+	IgnoredGatewayStatuses      IgnoredGatewayStatuses
+	HTTPRouteStatuses           HTTPRouteStatuses
+	TCPRouteStatuses            TCPRouteStatuses
 }
 
 // GatewayStatus holds the status of the winning Gateway resource.
 type GatewayStatus struct {
 	NsName           types.NamespacedName
 	ListenerStatuses ListenerStatuses
+	// Addresses are the IP addresses of the Gateway NGINX listens on.
+	Addresses []string
+	// RejectedAddresses are the values of the addresses of the Gateway NGINX cannot listen on.
+	RejectedAddresses []string
 }
 
 // IgnoredGatewayStatuses holds the statuses of the ignored Gateway resources.
@@ -30,12 +39,35 @@ type IgnoredGatewayStatus struct {
 	ObservedGeneration int64
 }
 
+// IgnoredGatewayClassStatuses holds the statuses of the ignored GatewayClass resources where the key is the name of
+// a GatewayClass.
+type IgnoredGatewayClassStatuses map[string]IgnoredGatewayClassStatus
+
+// IgnoredGatewayClassStatus holds the status of an ignored GatewayClass resource.
+type IgnoredGatewayClassStatus struct {
+	ObservedGeneration int64
+}
+
 // ListenerStatus holds the status-related information about a listener in the Gateway resource.
 type ListenerStatus struct {
 	// Valid shows if the listener is valid.
 	Valid bool
+	// UnresolvedRefs shows if the listener references resources that don't exist. For example, a Secret from its
+	// certificateRefs.
+	UnresolvedRefs bool
+	// UnsupportedSelector shows if the allowedRoutes of the listener select the namespaces by a label selector,
+	// which is not supported.
+	UnsupportedSelector bool
+	// UnsupportedProtocol shows if the protocol of the listener is not supported.
+	UnsupportedProtocol bool
+	// Conflict shows why the listener conflicts with another listener of the Gateway. It is empty if the listener
+	// doesn't conflict with any listener.
+	Conflict ListenerConflictReason
 	// AttachedRoutes is the number of routes attached to the listener.
 	AttachedRoutes int32
+	// Programmed shows whether NGINX has the configuration of the listener. Unlike the other fields, it is not set
+	// by the ChangeProcessor, but by the EventHandler once it has updated NGINX.
+	Programmed bool
 }
 
 // ParentStatuses holds the statuses of parents where the key is the section name in a parentRef.
@@ -43,12 +75,34 @@ type ParentStatuses map[string]ParentStatus
 
 type HTTPRouteStatus struct {
 	ParentStatuses ParentStatuses
+	// MissingGatewayRefs holds the section names of the parentRefs that reference Gateways that don't exist, where
+	// the key is the namespaced name of a Gateway.
+	MissingGatewayRefs map[types.NamespacedName][]string
+	// Warnings holds the unique warnings produced while generating NGINX configuration for the route.
+	Warnings []string
+	// UnresolvedRefs holds the messages explaining why some backendRefs of the route cannot be resolved.
+	UnresolvedRefs []string
+	// InvalidKindRefs holds the messages explaining why some backendRefs of the route reference unsupported resources.
+	InvalidKindRefs []string
+	// BackendRefCount is the number of the backendRefs of the route, including the unresolved and invalid ones.
+	BackendRefCount int
+	// UnresolvableRules holds the indexes of the rules of the route none of whose backendRefs can be resolved.
+	UnresolvableRules []int
 }
 
-// ParentStatus holds status-related information related to how the HTTPRoute binds to a specific parentRef.
+// TCPRouteStatus holds the status-related information about a TCPRoute.
+type TCPRouteStatus struct {
+	ParentStatuses ParentStatuses
+	// Warnings holds the unique warnings produced while generating NGINX configuration for the route.
+	Warnings []string
+}
+
+// ParentStatus holds status-related information related to how the route binds to a specific parentRef.
 type ParentStatus struct {
 	// Attached is true if the route attaches to the parent (listener).
 	Attached bool
+	// Reason explains why the parentRef is invalid. It is empty for the valid parentRefs.
+	Reason ParentRefReason
 }
 
 // GatewayClassStatus holds status-related infortmation about the GatewayClass resource.
@@ -64,8 +118,10 @@ type GatewayClassStatus struct {
 // buildStatuses builds statuses from a graph.
 func buildStatuses(graph *graph) Statuses {
 	statuses := Statuses{
-		HTTPRouteStatuses:      make(map[types.NamespacedName]HTTPRouteStatus),
-		IgnoredGatewayStatuses: make(map[types.NamespacedName]IgnoredGatewayStatus),
+		HTTPRouteStatuses:           make(map[types.NamespacedName]HTTPRouteStatus),
+		TCPRouteStatuses:            make(map[types.NamespacedName]TCPRouteStatus),
+		IgnoredGatewayStatuses:      make(map[types.NamespacedName]IgnoredGatewayStatus),
+		IgnoredGatewayClassStatuses: make(map[string]IgnoredGatewayClassStatus),
 	}
 
 	if graph.GatewayClass != nil {
@@ -76,21 +132,38 @@ func buildStatuses(graph *graph) Statuses {
 		}
 	}
 
+	for name, gc := range graph.IgnoredGatewayClasses {
+		statuses.IgnoredGatewayClassStatuses[name] = IgnoredGatewayClassStatus{ObservedGeneration: gc.Generation}
+	}
+
 	gcValidAndExist := graph.GatewayClass != nil && graph.GatewayClass.Valid
 
 	if graph.Gateway != nil {
 		listenerStatuses := make(map[string]ListenerStatus)
 
 		for name, l := range graph.Gateway.Listeners {
+			// The listener only holds the routes that are allowed by its allowedRoutes and whose hostnames
+			// intersect with its hostname. Those routes are only attached when the GatewayClass is valid and exists.
+			var attachedRoutes int32
+			if gcValidAndExist {
+				attachedRoutes = int32(len(l.Routes) + len(l.TCPRoutes))
+			}
+
 			listenerStatuses[name] = ListenerStatus{
-				Valid:          l.Valid && gcValidAndExist,
-				AttachedRoutes: int32(len(l.Routes)),
+				Valid:               l.Valid && gcValidAndExist,
+				UnresolvedRefs:      l.UnresolvedRefs,
+				UnsupportedSelector: l.UnsupportedSelector,
+				UnsupportedProtocol: l.UnsupportedProtocol,
+				Conflict:            l.Conflict,
+				AttachedRoutes:      attachedRoutes,
 			}
 		}
 
 		statuses.GatewayStatus = &GatewayStatus{
-			NsName:           getNamespacedName(graph.Gateway.Source),
-			ListenerStatuses: listenerStatuses,
+			NsName:            getNamespacedName(graph.Gateway.Source),
+			ListenerStatuses:  listenerStatuses,
+			Addresses:         graph.Gateway.ListenAddresses,
+			RejectedAddresses: graph.Gateway.RejectedAddresses,
 		}
 	}
 
@@ -99,23 +172,45 @@ func buildStatuses(graph *graph) Statuses {
 	}
 
 	for nsname, r := range graph.Routes {
-		parentStatuses := make(map[string]ParentStatus)
-
-		for ref := range r.ValidSectionNameRefs {
-			parentStatuses[ref] = ParentStatus{
-				Attached: gcValidAndExist, // Attached only when GatewayClass is valid and exists
-			}
-		}
-		for ref := range r.InvalidSectionNameRefs {
-			parentStatuses[ref] = ParentStatus{
-				Attached: false,
-			}
+		statuses.HTTPRouteStatuses[nsname] = HTTPRouteStatus{
+			ParentStatuses:     buildParentStatuses(r.ValidSectionNameRefs, r.InvalidSectionNameRefs, gcValidAndExist),
+			MissingGatewayRefs: r.MissingGatewayRefs,
+			UnresolvedRefs:     r.UnresolvedRefs,
+			InvalidKindRefs:    r.InvalidKindRefs,
+			BackendRefCount:    r.BackendRefCount,
+			UnresolvableRules:  r.UnresolvableRules,
 		}
+	}
 
-		statuses.HTTPRouteStatuses[nsname] = HTTPRouteStatus{
-			ParentStatuses: parentStatuses,
+	for nsname, r := range graph.TCPRoutes {
+		statuses.TCPRouteStatuses[nsname] = TCPRouteStatus{
+			ParentStatuses: buildParentStatuses(r.ValidSectionNameRefs, r.InvalidSectionNameRefs, gcValidAndExist),
 		}
 	}
 
 	return statuses
 }
+
+// buildParentStatuses builds the statuses of the parentRefs of a route. The valid parentRefs are only attached when
+// the GatewayClass is valid and exists.
+func buildParentStatuses(
+	validRefs map[string]struct{},
+	invalidRefs map[string]ParentRefReason,
+	gcValidAndExist bool,
+) ParentStatuses {
+	parentStatuses := make(map[string]ParentStatus, len(validRefs)+len(invalidRefs))
+
+	for ref := range validRefs {
+		parentStatuses[ref] = ParentStatus{
+			Attached: gcValidAndExist,
+		}
+	}
+	for ref, reason := range invalidRefs {
+		parentStatuses[ref] = ParentStatus{
+			Attached: false,
+			Reason:   reason,
+		}
+	}
+
+	return parentStatuses
+}
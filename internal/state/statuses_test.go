@@ -7,6 +7,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/helpers"
 )
 
 func TestBuildStatuses(t *testing.T) {
@@ -17,6 +19,12 @@ func TestBuildStatuses(t *testing.T) {
 				{Namespace: "test", Name: "hr-1"}: {},
 			},
 		},
+		"listener-443-1": {
+			Valid:               false,
+			UnresolvedRefs:      true,
+			UnsupportedSelector: true,
+			Routes:              map[types.NamespacedName]*route{},
+		},
 	}
 
 	routes := map[types.NamespacedName]*route{
@@ -24,17 +32,39 @@ func TestBuildStatuses(t *testing.T) {
 			ValidSectionNameRefs: map[string]struct{}{
 				"listener-80-1": {},
 			},
-			InvalidSectionNameRefs: map[string]struct{}{
-				"listener-80-2": {},
+			InvalidSectionNameRefs: map[string]ParentRefReason{
+				"listener-80-2": ParentRefReasonNoMatchingListenerHostname,
+			},
+			MissingGatewayRefs: map[types.NamespacedName][]string{
+				{Namespace: "test", Name: "missing-gateway"}: {"listener-80-1"},
 			},
 		},
 	}
 
 	routesAllRefsInvalid := map[types.NamespacedName]*route{
 		{Namespace: "test", Name: "hr-1"}: {
-			InvalidSectionNameRefs: map[string]struct{}{
-				"listener-80-2": {},
-				"listener-80-1": {},
+			InvalidSectionNameRefs: map[string]ParentRefReason{
+				"listener-80-2": ParentRefReasonGatewayConflict,
+				"listener-80-1": ParentRefReasonGatewayConflict,
+			},
+		},
+	}
+
+	tcpRoutes := map[types.NamespacedName]*tcpRoute{
+		{Namespace: "test", Name: "tr-1"}: {
+			ValidSectionNameRefs: map[string]struct{}{
+				"listener-8080-1": {},
+			},
+			InvalidSectionNameRefs: map[string]ParentRefReason{
+				"listener-8081-1": ParentRefReasonRouteConflict,
+			},
+		},
+	}
+
+	routesNoMatchingParent := map[types.NamespacedName]*route{
+		{Namespace: "test", Name: "hr-1"}: {
+			InvalidSectionNameRefs: map[string]ParentRefReason{
+				"listener-80-1": ParentRefReasonNoMatchingParent,
 			},
 		},
 	}
@@ -68,13 +98,16 @@ func TestBuildStatuses(t *testing.T) {
 					Valid: true,
 				},
 				Gateway: &gateway{
-					Source:    gw,
-					Listeners: listeners,
+					Source:            gw,
+					Listeners:         listeners,
+					ListenAddresses:   []string{"10.0.0.1"},
+					RejectedAddresses: []string{"10.0.0.2"},
 				},
 				IgnoredGateways: map[types.NamespacedName]*v1beta1.Gateway{
 					{Namespace: "test", Name: "ignored-gateway"}: ignoredGw,
 				},
-				Routes: routes,
+				Routes:    routes,
+				TCPRoutes: tcpRoutes,
 			},
 			expected: Statuses{
 				GatewayClassStatus: &GatewayClassStatus{
@@ -88,11 +121,33 @@ func TestBuildStatuses(t *testing.T) {
 							Valid:          true,
 							AttachedRoutes: 1,
 						},
+						"listener-443-1": {
+							Valid:               false,
+							UnresolvedRefs:      true,
+							UnsupportedSelector: true,
+							AttachedRoutes:      0,
+						},
 					},
+					Addresses:         []string{"10.0.0.1"},
+					RejectedAddresses: []string{"10.0.0.2"},
 				},
+				IgnoredGatewayClassStatuses: map[string]IgnoredGatewayClassStatus{},
 				IgnoredGatewayStatuses: map[types.NamespacedName]IgnoredGatewayStatus{
 					{Namespace: "test", Name: "ignored-gateway"}: {ObservedGeneration: 1},
 				},
+				TCPRouteStatuses: map[types.NamespacedName]TCPRouteStatus{
+					{Namespace: "test", Name: "tr-1"}: {
+						ParentStatuses: map[string]ParentStatus{
+							"listener-8080-1": {
+								Attached: true,
+							},
+							"listener-8081-1": {
+								Attached: false,
+								Reason:   ParentRefReasonRouteConflict,
+							},
+						},
+					},
+				},
 				HTTPRouteStatuses: map[types.NamespacedName]HTTPRouteStatus{
 					{Namespace: "test", Name: "hr-1"}: {
 						ParentStatuses: map[string]ParentStatus{
@@ -101,8 +156,12 @@ func TestBuildStatuses(t *testing.T) {
 							},
 							"listener-80-2": {
 								Attached: false,
+								Reason:   ParentRefReasonNoMatchingListenerHostname,
 							},
 						},
+						MissingGatewayRefs: map[types.NamespacedName][]string{
+							{Namespace: "test", Name: "missing-gateway"}: {"listener-80-1"},
+						},
 					},
 				},
 			},
@@ -127,13 +186,21 @@ func TestBuildStatuses(t *testing.T) {
 					ListenerStatuses: map[string]ListenerStatus{
 						"listener-80-1": {
 							Valid:          false,
-							AttachedRoutes: 1,
+							AttachedRoutes: 0,
+						},
+						"listener-443-1": {
+							Valid:               false,
+							UnresolvedRefs:      true,
+							UnsupportedSelector: true,
+							AttachedRoutes:      0,
 						},
 					},
 				},
+				IgnoredGatewayClassStatuses: map[string]IgnoredGatewayClassStatus{},
 				IgnoredGatewayStatuses: map[types.NamespacedName]IgnoredGatewayStatus{
 					{Namespace: "test", Name: "ignored-gateway"}: {ObservedGeneration: 1},
 				},
+				TCPRouteStatuses: map[types.NamespacedName]TCPRouteStatus{},
 				HTTPRouteStatuses: map[types.NamespacedName]HTTPRouteStatus{
 					{Namespace: "test", Name: "hr-1"}: {
 						ParentStatuses: map[string]ParentStatus{
@@ -142,8 +209,12 @@ func TestBuildStatuses(t *testing.T) {
 							},
 							"listener-80-2": {
 								Attached: false,
+								Reason:   ParentRefReasonNoMatchingListenerHostname,
 							},
 						},
+						MissingGatewayRefs: map[types.NamespacedName][]string{
+							{Namespace: "test", Name: "missing-gateway"}: {"listener-80-1"},
+						},
 					},
 				},
 			},
@@ -178,13 +249,21 @@ func TestBuildStatuses(t *testing.T) {
 					ListenerStatuses: map[string]ListenerStatus{
 						"listener-80-1": {
 							Valid:          false,
-							AttachedRoutes: 1,
+							AttachedRoutes: 0,
+						},
+						"listener-443-1": {
+							Valid:               false,
+							UnresolvedRefs:      true,
+							UnsupportedSelector: true,
+							AttachedRoutes:      0,
 						},
 					},
 				},
+				IgnoredGatewayClassStatuses: map[string]IgnoredGatewayClassStatus{},
 				IgnoredGatewayStatuses: map[types.NamespacedName]IgnoredGatewayStatus{
 					{Namespace: "test", Name: "ignored-gateway"}: {ObservedGeneration: 1},
 				},
+				TCPRouteStatuses: map[types.NamespacedName]TCPRouteStatus{},
 				HTTPRouteStatuses: map[types.NamespacedName]HTTPRouteStatus{
 					{Namespace: "test", Name: "hr-1"}: {
 						ParentStatuses: map[string]ParentStatus{
@@ -193,8 +272,12 @@ func TestBuildStatuses(t *testing.T) {
 							},
 							"listener-80-2": {
 								Attached: false,
+								Reason:   ParentRefReasonNoMatchingListenerHostname,
 							},
 						},
+						MissingGatewayRefs: map[types.NamespacedName][]string{
+							{Namespace: "test", Name: "missing-gateway"}: {"listener-80-1"},
+						},
 					},
 				},
 			},
@@ -217,16 +300,20 @@ func TestBuildStatuses(t *testing.T) {
 					Valid:              true,
 					ObservedGeneration: 1,
 				},
-				GatewayStatus:          nil,
-				IgnoredGatewayStatuses: map[types.NamespacedName]IgnoredGatewayStatus{},
+				GatewayStatus:               nil,
+				IgnoredGatewayClassStatuses: map[string]IgnoredGatewayClassStatus{},
+				IgnoredGatewayStatuses:      map[types.NamespacedName]IgnoredGatewayStatus{},
+				TCPRouteStatuses:            map[types.NamespacedName]TCPRouteStatus{},
 				HTTPRouteStatuses: map[types.NamespacedName]HTTPRouteStatus{
 					{Namespace: "test", Name: "hr-1"}: {
 						ParentStatuses: map[string]ParentStatus{
 							"listener-80-1": {
 								Attached: false,
+								Reason:   ParentRefReasonGatewayConflict,
 							},
 							"listener-80-2": {
 								Attached: false,
+								Reason:   ParentRefReasonGatewayConflict,
 							},
 						},
 					},
@@ -234,6 +321,46 @@ func TestBuildStatuses(t *testing.T) {
 			},
 			msg: "gateway and ignored gateways don't exist",
 		},
+		{
+			graph: &graph{
+				GatewayClass: &gatewayClass{
+					Source: &v1beta1.GatewayClass{
+						ObjectMeta: metav1.ObjectMeta{Generation: 1},
+					},
+					Valid: true,
+				},
+				Gateway: &gateway{
+					Source:    gw,
+					Listeners: map[string]*listener{},
+				},
+				IgnoredGateways: nil,
+				Routes:          routesNoMatchingParent,
+			},
+			expected: Statuses{
+				GatewayClassStatus: &GatewayClassStatus{
+					Valid:              true,
+					ObservedGeneration: 1,
+				},
+				GatewayStatus: &GatewayStatus{
+					NsName:           types.NamespacedName{Namespace: "test", Name: "gateway"},
+					ListenerStatuses: map[string]ListenerStatus{},
+				},
+				IgnoredGatewayClassStatuses: map[string]IgnoredGatewayClassStatus{},
+				IgnoredGatewayStatuses:      map[types.NamespacedName]IgnoredGatewayStatus{},
+				TCPRouteStatuses:            map[types.NamespacedName]TCPRouteStatus{},
+				HTTPRouteStatuses: map[types.NamespacedName]HTTPRouteStatus{
+					{Namespace: "test", Name: "hr-1"}: {
+						ParentStatuses: map[string]ParentStatus{
+							"listener-80-1": {
+								Attached: false,
+								Reason:   ParentRefReasonNoMatchingParent,
+							},
+						},
+					},
+				},
+			},
+			msg: "gateway without listeners",
+		},
 	}
 
 	for _, test := range tests {
@@ -243,3 +370,126 @@ func TestBuildStatuses(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildStatusesUnsupportedProtocol(t *testing.T) {
+	graph := &graph{
+		GatewayClass: &gatewayClass{
+			Source: &v1beta1.GatewayClass{},
+			Valid:  true,
+		},
+		Gateway: &gateway{
+			Source: &v1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "gateway",
+				},
+			},
+			Listeners: map[string]*listener{
+				"listener-53": {
+					Valid:               false,
+					UnsupportedProtocol: true,
+					Routes:              map[types.NamespacedName]*route{},
+				},
+			},
+		},
+	}
+
+	expected := ListenerStatuses{
+		"listener-53": {
+			Valid:               false,
+			UnsupportedProtocol: true,
+		},
+	}
+
+	result := buildStatuses(graph)
+	if diff := cmp.Diff(expected, result.GatewayStatus.ListenerStatuses); diff != "" {
+		t.Errorf("buildStatuses() mismatch on the listener statuses (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuildStatusesAttachedRoutes(t *testing.T) {
+	gw := &v1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "gateway",
+		},
+	}
+
+	createRoute := func(namespace, name, hostname string) *v1beta1.HTTPRoute {
+		return &v1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+			},
+			Spec: v1beta1.HTTPRouteSpec{
+				CommonRouteSpec: v1beta1.CommonRouteSpec{
+					ParentRefs: []v1beta1.ParentReference{
+						{
+							Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+							Name:        "gateway",
+							SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("listener-80-1")),
+						},
+					},
+				},
+				Hostnames: []v1beta1.Hostname{v1beta1.Hostname(hostname)},
+			},
+		}
+	}
+
+	hrs := []*v1beta1.HTTPRoute{
+		createRoute("test", "accepted-1", "foo.example.com"),
+		createRoute("test", "accepted-2", "foo.example.com"),
+		// rejected, because the hostname doesn't intersect with the hostname of the listener
+		createRoute("test", "other-hostname", "bar.example.com"),
+		// rejected, because the allowedRoutes of the listener don't allow the namespace
+		createRoute("other", "other-namespace", "foo.example.com"),
+	}
+
+	listeners := map[string]*listener{
+		"listener-80-1": {
+			Source: v1beta1.Listener{
+				Hostname: (*v1beta1.Hostname)(helpers.GetStringPointer("foo.example.com")),
+			},
+			Valid:             true,
+			Routes:            map[types.NamespacedName]*route{},
+			AcceptedHostnames: map[string]struct{}{},
+		},
+	}
+
+	routes := make(map[types.NamespacedName]*route)
+
+	for _, hr := range hrs {
+		ignored, r := bindHTTPRouteToListeners(hr, gw, nil, nil, listeners)
+		if ignored {
+			t.Fatalf("bindHTTPRouteToListeners() ignored the route %s", hr.Name)
+		}
+		routes[getNamespacedName(hr)] = r
+	}
+
+	graph := &graph{
+		GatewayClass: &gatewayClass{
+			Source: &v1beta1.GatewayClass{},
+			Valid:  true,
+		},
+		Gateway: &gateway{
+			Source:    gw,
+			Listeners: listeners,
+		},
+		Routes: routes,
+	}
+
+	statuses := buildStatuses(graph)
+
+	expected := int32(2)
+	if result := statuses.GatewayStatus.ListenerStatuses["listener-80-1"].AttachedRoutes; result != expected {
+		t.Errorf("buildStatuses() returned %d attached routes but expected %d", result, expected)
+	}
+
+	for nsname, s := range statuses.HTTPRouteStatuses {
+		attached := s.ParentStatuses["listener-80-1"].Attached
+		_, accepted := listeners["listener-80-1"].Routes[nsname]
+		if attached != accepted {
+			t.Errorf("buildStatuses() returned attached %v for the route %s but expected %v", attached, nsname, accepted)
+		}
+	}
+}
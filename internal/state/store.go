@@ -2,19 +2,49 @@ package state
 
 import (
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 // store contains the resources that represent the state of the Gateway.
 type store struct {
-	gc         *v1beta1.GatewayClass
-	gateways   map[types.NamespacedName]*v1beta1.Gateway
-	httpRoutes map[types.NamespacedName]*v1beta1.HTTPRoute
+	gc *v1beta1.GatewayClass
+	// ignoredGatewayClasses holds the GatewayClass resources other than gc that reference the Gateway controller.
+	ignoredGatewayClasses map[string]*v1beta1.GatewayClass
+	gateways              map[types.NamespacedName]*v1beta1.Gateway
+	httpRoutes            map[types.NamespacedName]*v1beta1.HTTPRoute
+	tcpRoutes             map[types.NamespacedName]*v1alpha2.TCPRoute
 }
 
 func newStore() *store {
 	return &store{
-		gateways:   make(map[types.NamespacedName]*v1beta1.Gateway),
-		httpRoutes: make(map[types.NamespacedName]*v1beta1.HTTPRoute),
+		ignoredGatewayClasses: make(map[string]*v1beta1.GatewayClass),
+		gateways:              make(map[types.NamespacedName]*v1beta1.Gateway),
+		httpRoutes:            make(map[types.NamespacedName]*v1beta1.HTTPRoute),
+		tcpRoutes:             make(map[types.NamespacedName]*v1alpha2.TCPRoute),
 	}
 }
+
+// referencesSecret tells if any listener of the Gateways references the Secret with the namespaced name.
+// A listener can only reference a Secret from the namespace of its Gateway.
+func (s *store) referencesSecret(nsname types.NamespacedName) bool {
+	for _, gw := range s.gateways {
+		if gw.Namespace != nsname.Namespace {
+			continue
+		}
+
+		for _, l := range gw.Spec.Listeners {
+			if l.TLS == nil {
+				continue
+			}
+
+			for _, ref := range l.TLS.CertificateRefs {
+				if string(ref.Name) == nsname.Name {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -1,7 +1,9 @@
 package status
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
@@ -17,6 +19,60 @@ const (
 
 	// GatewayMessageGatewayConflict is message that describes GetawayReasonGatewayConflict.
 	GatewayMessageGatewayConflict = "The resource is ignored due to a conflicting Gateway resource"
+
+	// GatewayMessageListenersNotReady is a message that describes v1beta1.GatewayReasonListenersNotReady.
+	// It is followed by the names of the listeners that are not ready.
+	GatewayMessageListenersNotReady = "Some listeners are not ready"
+
+	// GatewayMessageAddressNotAssigned is a message that describes v1beta1.GatewayReasonAddressNotAssigned.
+	// It is followed by the addresses NGINX cannot listen on.
+	GatewayMessageAddressNotAssigned = "Some addresses cannot be assigned"
+
+	// ListenerMessageInvalidCertificateRef is a message that describes v1beta1.ListenerReasonInvalidCertificateRef.
+	ListenerMessageInvalidCertificateRef = "The certificateRef of the listener cannot be resolved"
+
+	// ListenerConditionProgrammed indicates whether NGINX has the configuration of the listener.
+	// Unlike ListenerConditionReady, it is False for a valid listener when NGINX fails to apply the configuration.
+	// The Gateway API v0.5.0 doesn't define this condition type, so it is an NGINX Gateway extension until
+	// the Gateway API is upgraded to a version that defines it.
+	ListenerConditionProgrammed v1beta1.ListenerConditionType = "Programmed"
+
+	// ListenerReasonProgrammed is used with ListenerConditionProgrammed (true).
+	ListenerReasonProgrammed v1beta1.ListenerConditionReason = "Programmed"
+
+	// ListenerMessageNotProgrammed is a message that describes v1beta1.ListenerReasonPending, which is used with
+	// ListenerConditionProgrammed (false) for a valid listener.
+	ListenerMessageNotProgrammed = "The configuration of the listener is not applied to NGINX"
+
+	// ListenerConditionAccepted indicates whether NGINX Gateway supports the configuration of the listener.
+	// The Gateway API v0.5.0 doesn't define this condition type, so it is an NGINX Gateway extension until
+	// the Gateway API is upgraded to a version that defines it.
+	ListenerConditionAccepted v1beta1.ListenerConditionType = "Accepted"
+
+	// ListenerReasonAccepted is used with ListenerConditionAccepted (true).
+	ListenerReasonAccepted v1beta1.ListenerConditionReason = "Accepted"
+
+	// ListenerReasonUnsupportedValue is used with ListenerConditionAccepted (false) when the listener has a field
+	// value that NGINX Gateway doesn't support.
+	ListenerReasonUnsupportedValue v1beta1.ListenerConditionReason = "UnsupportedValue"
+
+	// ListenerMessageUnsupportedSelector is a message that describes ListenerReasonUnsupportedValue for a listener
+	// whose allowedRoutes select the namespaces by a label selector.
+	ListenerMessageUnsupportedSelector = "The Selector namespaces of the allowedRoutes are not supported, " +
+		"so the listener doesn't allow any routes"
+
+	// ListenerReasonUnsupportedProtocol is used with ListenerConditionAccepted (false) when the protocol of
+	// the listener is not supported.
+	ListenerReasonUnsupportedProtocol v1beta1.ListenerConditionReason = "UnsupportedProtocol"
+
+	// ListenerMessageUnsupportedProtocol is a message that describes ListenerReasonUnsupportedProtocol.
+	ListenerMessageUnsupportedProtocol = "The protocol of the listener is not supported"
+
+	// ListenerMessageHostnameConflict is a message that describes v1beta1.ListenerReasonHostnameConflict.
+	ListenerMessageHostnameConflict = "The listener has the same hostname as another listener on the same port"
+
+	// ListenerMessageProtocolConflict is a message that describes v1beta1.ListenerReasonProtocolConflict.
+	ListenerMessageProtocolConflict = "The listener has a different protocol than another listener on the same port"
 )
 
 // prepareGatewayStatus prepares the status for a Gateway resource.
@@ -33,15 +89,26 @@ func prepareGatewayStatus(gatewayStatus state.GatewayStatus, transitionTime meta
 	}
 	sort.Strings(names)
 
+	// notReady are the names of the listeners that are not ready.
+	var notReady []string
+
 	for _, name := range names {
 		s := gatewayStatus.ListenerStatuses[name]
 
+		// a listener that is not accepted is not ready, even if it is valid, like a listener with
+		// the Selector namespaces, which doesn't allow any routes
+		ready := s.Valid && isListenerAccepted(s)
+
+		if !ready || s.UnresolvedRefs {
+			notReady = append(notReady, name)
+		}
+
 		var (
 			status metav1.ConditionStatus
 			reason v1beta1.ListenerConditionReason
 		)
 
-		if s.Valid {
+		if ready {
 			status = metav1.ConditionTrue
 			reason = v1beta1.ListenerReasonReady
 		} else {
@@ -59,6 +126,14 @@ func prepareGatewayStatus(gatewayStatus state.GatewayStatus, transitionTime meta
 			Message:            "", // FIXME(pleshakov) Come up with a good message
 		}
 
+		conditions := []metav1.Condition{
+			cond,
+			prepareListenerAcceptedCondition(s, transitionTime),
+			prepareListenerProgrammedCondition(s, transitionTime),
+			prepareListenerResolvedRefsCondition(s, transitionTime),
+			prepareListenerConflictedCondition(s, transitionTime),
+		}
+
 		listenerStatuses = append(listenerStatuses, v1beta1.ListenerStatus{
 			Name: v1beta1.SectionName(name),
 			SupportedKinds: []v1beta1.RouteGroupKind{
@@ -67,14 +142,158 @@ func prepareGatewayStatus(gatewayStatus state.GatewayStatus, transitionTime meta
 				},
 			},
 			AttachedRoutes: s.AttachedRoutes,
-			Conditions:     []metav1.Condition{cond},
+			Conditions:     conditions,
+		})
+	}
+
+	var addresses []v1beta1.GatewayAddress
+	for _, a := range gatewayStatus.Addresses {
+		addressType := v1beta1.IPAddressType
+		addresses = append(addresses, v1beta1.GatewayAddress{
+			Type:  &addressType,
+			Value: a,
 		})
 	}
 
+	readyCond := prepareGatewayReadyCondition(notReady, gatewayStatus.RejectedAddresses, transitionTime)
+
 	return v1beta1.GatewayStatus{
+		Addresses:  addresses,
 		Listeners:  listenerStatuses,
-		Conditions: nil, // FIXME(pleshakov) Create conditions for the Gateway resource.
+		Conditions: []metav1.Condition{readyCond},
+	}
+}
+
+// isListenerAccepted reports whether NGINX Gateway supports the configuration of the listener.
+func isListenerAccepted(s state.ListenerStatus) bool {
+	return !s.UnsupportedProtocol && !s.UnsupportedSelector
+}
+
+// prepareListenerAcceptedCondition prepares the Accepted condition of the listener, which is False if the protocol
+// of the listener is not supported or the listener uses the Selector namespaces in its allowedRoutes.
+func prepareListenerAcceptedCondition(s state.ListenerStatus, transitionTime metav1.Time) metav1.Condition {
+	cond := metav1.Condition{
+		Type:   string(ListenerConditionAccepted),
+		Status: metav1.ConditionTrue,
+		// FIXME(pleshakov) Set the observed generation to the last processed generation of the Gateway resource.
+		ObservedGeneration: 123,
+		LastTransitionTime: transitionTime,
+		Reason:             string(ListenerReasonAccepted),
+	}
+
+	if s.UnsupportedProtocol {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(ListenerReasonUnsupportedProtocol)
+		cond.Message = ListenerMessageUnsupportedProtocol
+	} else if s.UnsupportedSelector {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(ListenerReasonUnsupportedValue)
+		cond.Message = ListenerMessageUnsupportedSelector
+	}
+
+	return cond
+}
+
+// prepareListenerProgrammedCondition prepares the Programmed condition of the listener, which is only True if
+// NGINX has the configuration of the listener.
+func prepareListenerProgrammedCondition(s state.ListenerStatus, transitionTime metav1.Time) metav1.Condition {
+	cond := metav1.Condition{
+		Type:   string(ListenerConditionProgrammed),
+		Status: metav1.ConditionTrue,
+		// FIXME(pleshakov) Set the observed generation to the last processed generation of the Gateway resource.
+		ObservedGeneration: 123,
+		LastTransitionTime: transitionTime,
+		Reason:             string(ListenerReasonProgrammed),
+	}
+
+	if !s.Valid {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(v1beta1.ListenerReasonInvalid)
+	} else if !s.Programmed {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(v1beta1.ListenerReasonPending)
+		cond.Message = ListenerMessageNotProgrammed
+	}
+
+	return cond
+}
+
+// prepareListenerResolvedRefsCondition prepares the ResolvedRefs condition of the listener, which is False if the
+// certificateRef of the listener cannot be resolved.
+func prepareListenerResolvedRefsCondition(s state.ListenerStatus, transitionTime metav1.Time) metav1.Condition {
+	cond := metav1.Condition{
+		Type:   string(v1beta1.ListenerConditionResolvedRefs),
+		Status: metav1.ConditionTrue,
+		// FIXME(pleshakov) Set the observed generation to the last processed generation of the Gateway resource.
+		ObservedGeneration: 123,
+		LastTransitionTime: transitionTime,
+		Reason:             string(v1beta1.ListenerReasonResolvedRefs),
+	}
+
+	if s.UnresolvedRefs {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(v1beta1.ListenerReasonInvalidCertificateRef)
+		cond.Message = ListenerMessageInvalidCertificateRef
+	}
+
+	return cond
+}
+
+// prepareListenerConflictedCondition prepares the Conflicted condition of the listener, which is True if the listener
+// conflicts with another listener of the Gateway on the same port.
+func prepareListenerConflictedCondition(s state.ListenerStatus, transitionTime metav1.Time) metav1.Condition {
+	cond := metav1.Condition{
+		Type:   string(v1beta1.ListenerConditionConflicted),
+		Status: metav1.ConditionFalse,
+		// FIXME(pleshakov) Set the observed generation to the last processed generation of the Gateway resource.
+		ObservedGeneration: 123,
+		LastTransitionTime: transitionTime,
+		Reason:             string(v1beta1.ListenerReasonNoConflicts),
+	}
+
+	switch s.Conflict {
+	case state.ListenerConflictReasonHostname:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = string(v1beta1.ListenerReasonHostnameConflict)
+		cond.Message = ListenerMessageHostnameConflict
+	case state.ListenerConflictReasonProtocol:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = string(v1beta1.ListenerReasonProtocolConflict)
+		cond.Message = ListenerMessageProtocolConflict
 	}
+
+	return cond
+}
+
+// prepareGatewayReadyCondition prepares the Ready condition of the Gateway, which aggregates the conditions of its
+// listeners: it is True only if all listeners are ready. Otherwise, its message lists the listeners that are not ready.
+// The condition is also False if some addresses of the Gateway cannot be assigned, which takes precedence over
+// the listeners.
+func prepareGatewayReadyCondition(
+	notReady []string,
+	rejectedAddresses []string,
+	transitionTime metav1.Time,
+) metav1.Condition {
+	cond := metav1.Condition{
+		Type:   string(v1beta1.GatewayConditionReady),
+		Status: metav1.ConditionTrue,
+		// FIXME(pleshakov) Set the observed generation to the last processed generation of the Gateway resource.
+		ObservedGeneration: 123,
+		LastTransitionTime: transitionTime,
+		Reason:             string(v1beta1.GatewayReasonReady),
+	}
+
+	if len(rejectedAddresses) > 0 {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(v1beta1.GatewayReasonAddressNotAssigned)
+		cond.Message = fmt.Sprintf("%s: %s", GatewayMessageAddressNotAssigned, strings.Join(rejectedAddresses, ", "))
+	} else if len(notReady) > 0 {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(v1beta1.GatewayReasonListenersNotReady)
+		cond.Message = fmt.Sprintf("%s: %s", GatewayMessageListenersNotReady, strings.Join(notReady, ", "))
+	}
+
+	return cond
 }
 
 // prepareIgnoredGatewayStatus prepares the status for an ignored Gateway resource.
@@ -17,11 +17,21 @@ func TestPrepareGatewayStatus(t *testing.T) {
 			"valid-listener": {
 				Valid:          true,
 				AttachedRoutes: 2,
+				Programmed:     true,
+			},
+			"unprogrammed-listener": {
+				Valid:          true,
+				AttachedRoutes: 1,
 			},
 			"invalid-listener": {
 				Valid:          false,
 				AttachedRoutes: 1,
 			},
+			"unresolved-refs-listener": {
+				Valid:          false,
+				UnresolvedRefs: true,
+				AttachedRoutes: 0,
+			},
 		},
 	}
 
@@ -45,6 +55,128 @@ func TestPrepareGatewayStatus(t *testing.T) {
 						LastTransitionTime: transitionTime,
 						Reason:             string(v1beta1.ListenerReasonInvalid),
 					},
+					{
+						Type:               string(ListenerConditionAccepted),
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(ListenerReasonAccepted),
+					},
+					{
+						Type:               string(ListenerConditionProgrammed),
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonInvalid),
+					},
+					{
+						Type:               string(v1beta1.ListenerConditionResolvedRefs),
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonResolvedRefs),
+					},
+					{
+						Type:               string(v1beta1.ListenerConditionConflicted),
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonNoConflicts),
+					},
+				},
+			},
+			{
+				Name: "unprogrammed-listener",
+				SupportedKinds: []v1beta1.RouteGroupKind{
+					{
+						Kind: "HTTPRoute",
+					},
+				},
+				AttachedRoutes: 1,
+				Conditions: []metav1.Condition{
+					{
+						Type:               string(v1beta1.ListenerConditionReady),
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonReady),
+					},
+					{
+						Type:               string(ListenerConditionAccepted),
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(ListenerReasonAccepted),
+					},
+					{
+						Type:               string(ListenerConditionProgrammed),
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonPending),
+						Message:            ListenerMessageNotProgrammed,
+					},
+					{
+						Type:               string(v1beta1.ListenerConditionResolvedRefs),
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonResolvedRefs),
+					},
+					{
+						Type:               string(v1beta1.ListenerConditionConflicted),
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonNoConflicts),
+					},
+				},
+			},
+			{
+				Name: "unresolved-refs-listener",
+				SupportedKinds: []v1beta1.RouteGroupKind{
+					{
+						Kind: "HTTPRoute",
+					},
+				},
+				AttachedRoutes: 0,
+				Conditions: []metav1.Condition{
+					{
+						Type:               string(v1beta1.ListenerConditionReady),
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonInvalid),
+					},
+					{
+						Type:               string(ListenerConditionAccepted),
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(ListenerReasonAccepted),
+					},
+					{
+						Type:               string(ListenerConditionProgrammed),
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonInvalid),
+					},
+					{
+						Type:               string(v1beta1.ListenerConditionResolvedRefs),
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonInvalidCertificateRef),
+						Message:            ListenerMessageInvalidCertificateRef,
+					},
+					{
+						Type:               string(v1beta1.ListenerConditionConflicted),
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonNoConflicts),
+					},
 				},
 			},
 			{
@@ -63,9 +195,47 @@ func TestPrepareGatewayStatus(t *testing.T) {
 						LastTransitionTime: transitionTime,
 						Reason:             string(v1beta1.ListenerReasonReady),
 					},
+					{
+						Type:               string(ListenerConditionAccepted),
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(ListenerReasonAccepted),
+					},
+					{
+						Type:               string(ListenerConditionProgrammed),
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(ListenerReasonProgrammed),
+					},
+					{
+						Type:               string(v1beta1.ListenerConditionResolvedRefs),
+						Status:             metav1.ConditionTrue,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonResolvedRefs),
+					},
+					{
+						Type:               string(v1beta1.ListenerConditionConflicted),
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 123,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1beta1.ListenerReasonNoConflicts),
+					},
 				},
 			},
 		},
+		Conditions: []metav1.Condition{
+			{
+				Type:               string(v1beta1.GatewayConditionReady),
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 123,
+				LastTransitionTime: transitionTime,
+				Reason:             string(v1beta1.GatewayReasonListenersNotReady),
+				Message:            "Some listeners are not ready: invalid-listener, unresolved-refs-listener",
+			},
+		},
 	}
 
 	result := prepareGatewayStatus(status, transitionTime)
@@ -74,6 +244,209 @@ func TestPrepareGatewayStatus(t *testing.T) {
 	}
 }
 
+func TestPrepareGatewayStatusAllListenersReady(t *testing.T) {
+	status := state.GatewayStatus{
+		ListenerStatuses: state.ListenerStatuses{
+			"listener-80": {
+				Valid:          true,
+				AttachedRoutes: 2,
+			},
+			"listener-443": {
+				Valid:          true,
+				AttachedRoutes: 1,
+			},
+		},
+	}
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	expected := []metav1.Condition{
+		{
+			Type:               string(v1beta1.GatewayConditionReady),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             string(v1beta1.GatewayReasonReady),
+		},
+	}
+
+	result := prepareGatewayStatus(status, transitionTime)
+	if diff := cmp.Diff(expected, result.Conditions); diff != "" {
+		t.Errorf("prepareGatewayStatus() mismatch on conditions (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrepareGatewayStatusUnsupportedSelector(t *testing.T) {
+	status := state.GatewayStatus{
+		ListenerStatuses: state.ListenerStatuses{
+			"listener-80": {
+				Valid:               true,
+				UnsupportedSelector: true,
+			},
+		},
+	}
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	expected := metav1.Condition{
+		Type:               string(ListenerConditionAccepted),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 123,
+		LastTransitionTime: transitionTime,
+		Reason:             string(ListenerReasonUnsupportedValue),
+		Message:            ListenerMessageUnsupportedSelector,
+	}
+
+	result := prepareGatewayStatus(status, transitionTime)
+	if diff := cmp.Diff(expected, result.Listeners[0].Conditions[1]); diff != "" {
+		t.Errorf("prepareGatewayStatus() mismatch on the accepted condition (-want +got):\n%s", diff)
+	}
+
+	// the listener is valid but not accepted, so neither the listener nor the Gateway is ready
+	expectedReady := metav1.Condition{
+		Type:               string(v1beta1.ListenerConditionReady),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 123,
+		LastTransitionTime: transitionTime,
+		Reason:             string(v1beta1.ListenerReasonInvalid),
+	}
+	if diff := cmp.Diff(expectedReady, result.Listeners[0].Conditions[0]); diff != "" {
+		t.Errorf("prepareGatewayStatus() mismatch on the ready condition (-want +got):\n%s", diff)
+	}
+
+	expectedGatewayReady := []metav1.Condition{
+		{
+			Type:               string(v1beta1.GatewayConditionReady),
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             string(v1beta1.GatewayReasonListenersNotReady),
+			Message:            GatewayMessageListenersNotReady + ": listener-80",
+		},
+	}
+	if diff := cmp.Diff(expectedGatewayReady, result.Conditions); diff != "" {
+		t.Errorf("prepareGatewayStatus() mismatch on conditions (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrepareGatewayStatusUnsupportedProtocol(t *testing.T) {
+	status := state.GatewayStatus{
+		ListenerStatuses: state.ListenerStatuses{
+			"listener-53": {
+				Valid:               false,
+				UnsupportedProtocol: true,
+			},
+		},
+	}
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	expected := metav1.Condition{
+		Type:               string(ListenerConditionAccepted),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 123,
+		LastTransitionTime: transitionTime,
+		Reason:             string(ListenerReasonUnsupportedProtocol),
+		Message:            ListenerMessageUnsupportedProtocol,
+	}
+
+	result := prepareGatewayStatus(status, transitionTime)
+	if diff := cmp.Diff(expected, result.Listeners[0].Conditions[1]); diff != "" {
+		t.Errorf("prepareGatewayStatus() mismatch on the accepted condition (-want +got):\n%s", diff)
+	}
+
+	// the listener is not programmed, because NGINX doesn't have its configuration
+	if c := result.Listeners[0].Conditions[2]; c.Status != metav1.ConditionFalse {
+		t.Errorf("prepareGatewayStatus() returned the programmed condition %v for the unsupported listener", c)
+	}
+}
+
+func TestPrepareGatewayStatusConflictedListeners(t *testing.T) {
+	status := state.GatewayStatus{
+		ListenerStatuses: state.ListenerStatuses{
+			"hostname-conflict": {
+				Valid:    false,
+				Conflict: state.ListenerConflictReasonHostname,
+			},
+			"protocol-conflict": {
+				Valid:    false,
+				Conflict: state.ListenerConflictReasonProtocol,
+			},
+		},
+	}
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	expected := []metav1.Condition{
+		{
+			Type:               string(v1beta1.ListenerConditionConflicted),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             string(v1beta1.ListenerReasonHostnameConflict),
+			Message:            ListenerMessageHostnameConflict,
+		},
+		{
+			Type:               string(v1beta1.ListenerConditionConflicted),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             string(v1beta1.ListenerReasonProtocolConflict),
+			Message:            ListenerMessageProtocolConflict,
+		},
+	}
+
+	result := prepareGatewayStatus(status, transitionTime)
+
+	conditions := []metav1.Condition{result.Listeners[0].Conditions[4], result.Listeners[1].Conditions[4]}
+	if diff := cmp.Diff(expected, conditions); diff != "" {
+		t.Errorf("prepareGatewayStatus() mismatch on the conflicted conditions (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrepareGatewayStatusAddresses(t *testing.T) {
+	status := state.GatewayStatus{
+		ListenerStatuses: state.ListenerStatuses{
+			"listener-80": {
+				Valid: true,
+			},
+			"listener-443": {
+				Valid: false,
+			},
+		},
+		Addresses:         []string{"10.0.0.1"},
+		RejectedAddresses: []string{"10.0.0.2", "gateway.example.com"},
+	}
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	ipAddressType := v1beta1.IPAddressType
+	expectedAddresses := []v1beta1.GatewayAddress{
+		{
+			Type:  &ipAddressType,
+			Value: "10.0.0.1",
+		},
+	}
+	expectedConditions := []metav1.Condition{
+		{
+			Type:               string(v1beta1.GatewayConditionReady),
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             string(v1beta1.GatewayReasonAddressNotAssigned),
+			Message:            "Some addresses cannot be assigned: 10.0.0.2, gateway.example.com",
+		},
+	}
+
+	result := prepareGatewayStatus(status, transitionTime)
+	if diff := cmp.Diff(expectedAddresses, result.Addresses); diff != "" {
+		t.Errorf("prepareGatewayStatus() mismatch on addresses (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedConditions, result.Conditions); diff != "" {
+		t.Errorf("prepareGatewayStatus() mismatch on conditions (-want +got):\n%s", diff)
+	}
+}
+
 func TestPrepareIgnoredGatewayStatus(t *testing.T) {
 	status := state.IgnoredGatewayStatus{
 		ObservedGeneration: 1,
@@ -9,6 +9,17 @@ import (
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
 )
 
+const (
+	// GatewayClassReasonGatewayClassConflict indicates there are multiple GatewayClass resources that reference
+	// the controller of NGINX Gateway, and NGINX Gateway ignored the resource in question because it only supports
+	// the GatewayClass it is configured with.
+	// NGINX Gateway will use this reason with GatewayClassConditionStatusAccepted (false).
+	GatewayClassReasonGatewayClassConflict v1beta1.GatewayClassConditionReason = "GatewayClassConflict"
+
+	// GatewayClassMessageGatewayClassConflict is a message that describes GatewayClassReasonGatewayClassConflict.
+	GatewayClassMessageGatewayClassConflict = "The resource is ignored due to a conflicting GatewayClass resource"
+)
+
 // prepareGatewayClassStatus prepares the status for the GatewayClass resource.
 func prepareGatewayClassStatus(status state.GatewayClassStatus, transitionTime metav1.Time) v1beta1.GatewayClassStatus {
 	var (
@@ -37,3 +48,22 @@ func prepareGatewayClassStatus(status state.GatewayClassStatus, transitionTime m
 		Conditions: []metav1.Condition{cond},
 	}
 }
+
+// prepareIgnoredGatewayClassStatus prepares the status for an ignored GatewayClass resource.
+func prepareIgnoredGatewayClassStatus(
+	status state.IgnoredGatewayClassStatus,
+	transitionTime metav1.Time,
+) v1beta1.GatewayClassStatus {
+	return v1beta1.GatewayClassStatus{
+		Conditions: []metav1.Condition{
+			{
+				Type:               string(v1beta1.GatewayClassConditionStatusAccepted),
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: status.ObservedGeneration,
+				LastTransitionTime: transitionTime,
+				Reason:             string(GatewayClassReasonGatewayClassConflict),
+				Message:            GatewayClassMessageGatewayClassConflict,
+			},
+		},
+	}
+}
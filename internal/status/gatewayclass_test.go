@@ -67,3 +67,27 @@ func TestPrepareGatewayClassStatus(t *testing.T) {
 		}
 	}
 }
+
+func TestPrepareIgnoredGatewayClassStatus(t *testing.T) {
+	transitionTime := metav1.NewTime(time.Now())
+
+	status := state.IgnoredGatewayClassStatus{ObservedGeneration: 1}
+
+	expected := v1beta1.GatewayClassStatus{
+		Conditions: []metav1.Condition{
+			{
+				Type:               string(v1beta1.GatewayClassConditionStatusAccepted),
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 1,
+				LastTransitionTime: transitionTime,
+				Reason:             string(GatewayClassReasonGatewayClassConflict),
+				Message:            GatewayClassMessageGatewayClassConflict,
+			},
+		},
+	}
+
+	result := prepareIgnoredGatewayClassStatus(status, transitionTime)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("prepareIgnoredGatewayClassStatus() mismatch (-want +got):\n%s", diff)
+	}
+}
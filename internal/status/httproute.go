@@ -1,7 +1,10 @@
 package status
 
 import (
+	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -10,6 +13,22 @@ import (
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
 )
 
+const (
+	// RouteConditionWarnings indicates that NGINX Gateway got warnings while generating configuration for
+	// the route. The message of the condition includes the warnings.
+	RouteConditionWarnings v1beta1.RouteConditionType = "Warnings"
+
+	// RouteReasonConfigurationWarnings is used with RouteConditionWarnings (true).
+	RouteReasonConfigurationWarnings v1beta1.RouteConditionReason = "ConfigurationWarnings"
+
+	// RouteReasonNoMatchingParent is used with the Accepted (false) condition when the Gateway doesn't have
+	// the listener the parentRef references.
+	RouteReasonNoMatchingParent v1beta1.RouteConditionReason = "NoMatchingParent"
+
+	// maxWarningsInMessage is the maximum number of warnings included in the message of RouteConditionWarnings.
+	maxWarningsInMessage = 10
+)
+
 // prepareHTTPRouteStatus prepares the status for an HTTPRoute resource.
 // FIXME(pleshakov): Be compliant with in the Gateway API.
 // Currently, we only support simple attached/not attached status per each parentRef.
@@ -22,56 +41,211 @@ func prepareHTTPRouteStatus(
 ) v1beta1.HTTPRouteStatus {
 	parents := make([]v1beta1.RouteParentStatus, 0, len(status.ParentStatuses))
 
-	// FIXME(pleshakov) Maintain the order from the HTTPRoute resource
-	names := make([]string, 0, len(status.ParentStatuses))
-	for name := range status.ParentStatuses {
-		names = append(names, name)
-	}
-	sort.Strings(names)
-
-	for _, name := range names {
+	for _, name := range getSortedSectionNames(status.ParentStatuses) {
 		ps := status.ParentStatuses[name]
 
-		var (
-			status metav1.ConditionStatus
-			reason string // FIXME(pleshakov) use RouteConditionReason once we upgrade to v1beta1
-		)
-
-		if ps.Attached {
-			status = metav1.ConditionTrue
-			reason = "Accepted" // FIXME(pleshakov): use RouteReasonAccepted once we upgrade to v1beta1
-		} else {
-			status = metav1.ConditionFalse
-			reason = "NotAttached" // FIXME(pleshakov): use a more specific message from the defined constants (available in v1beta1)
-		}
+		p := prepareRouteParentStatus(name, ps, gwNsName, gatewayCtlrName, transitionTime)
 
-		sectionName := name
+		if cond, exists := prepareResolvedRefsCondition(status, transitionTime); exists {
+			p.Conditions = append(p.Conditions, cond)
+		}
 
-		p := v1beta1.RouteParentStatus{
-			ParentRef: v1beta1.ParentReference{
-				Namespace:   (*v1beta1.Namespace)(&gwNsName.Namespace),
-				Name:        v1beta1.ObjectName(gwNsName.Name),
-				SectionName: (*v1beta1.SectionName)(&sectionName),
-			},
-			ControllerName: v1beta1.GatewayController(gatewayCtlrName),
-			Conditions: []metav1.Condition{
-				{
-					Type:   string(v1beta1.RouteConditionAccepted),
-					Status: status,
-					// FIXME(pleshakov) Set the observed generation to the last processed generation of the HTTPRoute resource.
-					ObservedGeneration: 123,
-					LastTransitionTime: transitionTime,
-					Reason:             reason,
-					Message:            "", // FIXME(pleshakov): Figure out a good message
-				},
-			},
+		if ps.Attached && len(status.Warnings) > 0 {
+			p.Conditions = append(p.Conditions, prepareWarningsCondition(status.Warnings, transitionTime))
 		}
+
 		parents = append(parents, p)
 	}
 
+	parents = append(parents, prepareMissingGatewayParentStatuses(status, gatewayCtlrName, transitionTime)...)
+
 	return v1beta1.HTTPRouteStatus{
 		RouteStatus: v1beta1.RouteStatus{
 			Parents: parents,
 		},
 	}
 }
+
+// prepareMissingGatewayParentStatuses prepares the statuses of the parentRefs of a route that reference Gateways that
+// don't exist, sorted by the Gateways. Those parentRefs are not accepted with RouteReasonNoMatchingParent.
+func prepareMissingGatewayParentStatuses(
+	status state.HTTPRouteStatus,
+	gatewayCtlrName string,
+	transitionTime metav1.Time,
+) []v1beta1.RouteParentStatus {
+	gwNsNames := make([]types.NamespacedName, 0, len(status.MissingGatewayRefs))
+	for nsname := range status.MissingGatewayRefs {
+		gwNsNames = append(gwNsNames, nsname)
+	}
+	sort.Slice(gwNsNames, func(i, j int) bool {
+		return gwNsNames[i].String() < gwNsNames[j].String()
+	})
+
+	var parents []v1beta1.RouteParentStatus
+
+	for _, gwNsName := range gwNsNames {
+		for _, name := range status.MissingGatewayRefs[gwNsName] {
+			ps := state.ParentStatus{
+				Reason: state.ParentRefReasonNoMatchingParent,
+			}
+
+			parents = append(parents, prepareRouteParentStatus(name, ps, gwNsName, gatewayCtlrName, transitionTime))
+		}
+	}
+
+	return parents
+}
+
+// getSortedSectionNames returns the section names of the parent statuses of a route in the alphabetical order.
+// FIXME(pleshakov) Maintain the order from the route resource
+func getSortedSectionNames(statuses state.ParentStatuses) []string {
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// prepareRouteParentStatus prepares the status of the parentRef of a route with the Accepted condition.
+func prepareRouteParentStatus(
+	sectionName string,
+	ps state.ParentStatus,
+	gwNsName types.NamespacedName,
+	gatewayCtlrName string,
+	transitionTime metav1.Time,
+) v1beta1.RouteParentStatus {
+	var (
+		condStatus metav1.ConditionStatus
+		reason     string // FIXME(pleshakov) use RouteConditionReason once we upgrade to v1beta1
+	)
+
+	switch {
+	case ps.Attached:
+		condStatus = metav1.ConditionTrue
+		reason = "Accepted" // FIXME(pleshakov): use RouteReasonAccepted once we upgrade to v1beta1
+	case ps.Reason != "":
+		// the reasons of the invalid parentRefs match the reasons of the Gateway API,
+		// for example, v1beta1.RouteReasonNoMatchingListenerHostname or RouteReasonNoMatchingParent
+		condStatus = metav1.ConditionFalse
+		reason = string(ps.Reason)
+	default:
+		condStatus = metav1.ConditionFalse
+		reason = "NotAttached" // FIXME(pleshakov): use a more specific message from the defined constants (available in v1beta1)
+	}
+
+	return v1beta1.RouteParentStatus{
+		ParentRef: v1beta1.ParentReference{
+			Namespace:   (*v1beta1.Namespace)(&gwNsName.Namespace),
+			Name:        v1beta1.ObjectName(gwNsName.Name),
+			SectionName: (*v1beta1.SectionName)(&sectionName),
+		},
+		ControllerName: v1beta1.GatewayController(gatewayCtlrName),
+		Conditions: []metav1.Condition{
+			{
+				Type:   string(v1beta1.RouteConditionAccepted),
+				Status: condStatus,
+				// FIXME(pleshakov) Set the observed generation to the last processed generation of the route resource.
+				ObservedGeneration: 123,
+				LastTransitionTime: transitionTime,
+				Reason:             reason,
+				Message:            "", // FIXME(pleshakov): Figure out a good message
+			},
+		},
+	}
+}
+
+// prepareWarningsCondition prepares the Warnings condition with the warnings produced while generating NGINX
+// configuration for a route.
+func prepareWarningsCondition(warnings []string, transitionTime metav1.Time) metav1.Condition {
+	return metav1.Condition{
+		Type:   string(RouteConditionWarnings),
+		Status: metav1.ConditionTrue,
+		// FIXME(pleshakov) Set the observed generation to the last processed generation of the route resource.
+		ObservedGeneration: 123,
+		LastTransitionTime: transitionTime,
+		Reason:             string(RouteReasonConfigurationWarnings),
+		Message:            consolidateWarnings(warnings),
+	}
+}
+
+// prepareResolvedRefsCondition prepares the ResolvedRefs condition, which aggregates the resolution of all backendRefs
+// of the route: it is False if any backendRef cannot be resolved. The reason is InvalidKind if any backendRef
+// references an unsupported resource, and BackendNotFound otherwise. The message points out the rules none of whose
+// backendRefs can be resolved. The condition doesn't exist if the route doesn't have any backendRefs.
+func prepareResolvedRefsCondition(status state.HTTPRouteStatus, transitionTime metav1.Time) (metav1.Condition, bool) {
+	// the messages about the invalid kinds go first, because they explain the reason of the condition
+	msgs := make([]string, 0, len(status.InvalidKindRefs)+len(status.UnresolvedRefs))
+	msgs = append(msgs, status.InvalidKindRefs...)
+	msgs = append(msgs, status.UnresolvedRefs...)
+
+	total := status.BackendRefCount
+	unresolved := len(msgs)
+
+	if total < unresolved {
+		total = unresolved
+	}
+
+	if total == 0 {
+		return metav1.Condition{}, false
+	}
+
+	cond := metav1.Condition{
+		Type: string(v1beta1.RouteConditionResolvedRefs),
+		// FIXME(pleshakov) Set the observed generation to the last processed generation of the HTTPRoute resource.
+		ObservedGeneration: 123,
+		LastTransitionTime: transitionTime,
+	}
+
+	if unresolved == 0 {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = string(v1beta1.RouteReasonResolvedRefs)
+		cond.Message = fmt.Sprintf("All %d backendRefs are resolved", total)
+
+		return cond, true
+	}
+
+	cond.Status = metav1.ConditionFalse
+	if len(status.InvalidKindRefs) > 0 {
+		cond.Reason = string(v1beta1.RouteReasonInvalidKind)
+	} else {
+		cond.Reason = string(v1beta1.RouteReasonBackendNotFound)
+	}
+	cond.Message = fmt.Sprintf("%d of %d backendRefs cannot be resolved", unresolved, total)
+	if len(status.UnresolvableRules) > 0 {
+		cond.Message += fmt.Sprintf(", including all backendRefs of %s", formatRules(status.UnresolvableRules))
+	}
+	cond.Message += ": " + strings.Join(msgs, "; ")
+
+	return cond, true
+}
+
+// formatRules formats the indexes of the rules of a route for a condition message. For example, "rule 1" or
+// "rules 0, 2".
+func formatRules(rules []int) string {
+	idxs := make([]string, 0, len(rules))
+	for _, r := range rules {
+		idxs = append(idxs, strconv.Itoa(r))
+	}
+
+	if len(idxs) == 1 {
+		return "rule " + idxs[0]
+	}
+
+	return "rules " + strings.Join(idxs, ", ")
+}
+
+// consolidateWarnings combines the warnings into a single message.
+// To keep the message concise, only the first maxWarningsInMessage warnings are included.
+func consolidateWarnings(warnings []string) string {
+	if len(warnings) <= maxWarningsInMessage {
+		return strings.Join(warnings, "; ")
+	}
+
+	return fmt.Sprintf(
+		"%s; and %d more",
+		strings.Join(warnings[:maxWarningsInMessage], "; "),
+		len(warnings)-maxWarningsInMessage,
+	)
+}
@@ -1,6 +1,7 @@
 package status
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -22,6 +23,14 @@ func TestPrepareHTTPRouteStatus(t *testing.T) {
 			"not-attached": {
 				Attached: false,
 			},
+			"no-matching-parent": {
+				Attached: false,
+				Reason:   state.ParentRefReasonNoMatchingParent,
+			},
+			"no-matching-listener-hostname": {
+				Attached: false,
+				Reason:   state.ParentRefReasonNoMatchingListenerHostname,
+			},
 		},
 	}
 
@@ -50,6 +59,40 @@ func TestPrepareHTTPRouteStatus(t *testing.T) {
 						},
 					},
 				},
+				{
+					ParentRef: v1beta1.ParentReference{
+						Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+						Name:        "gateway",
+						SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("no-matching-listener-hostname")),
+					},
+					ControllerName: v1beta1.GatewayController(gatewayCtlrName),
+					Conditions: []metav1.Condition{
+						{
+							Type:               string(v1beta1.RouteConditionAccepted),
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: 123,
+							LastTransitionTime: transitionTime,
+							Reason:             string(v1beta1.RouteReasonNoMatchingListenerHostname),
+						},
+					},
+				},
+				{
+					ParentRef: v1beta1.ParentReference{
+						Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+						Name:        "gateway",
+						SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("no-matching-parent")),
+					},
+					ControllerName: v1beta1.GatewayController(gatewayCtlrName),
+					Conditions: []metav1.Condition{
+						{
+							Type:               string(v1beta1.RouteConditionAccepted),
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: 123,
+							LastTransitionTime: transitionTime,
+							Reason:             string(RouteReasonNoMatchingParent),
+						},
+					},
+				},
 				{
 					ParentRef: v1beta1.ParentReference{
 						Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
@@ -76,3 +119,358 @@ func TestPrepareHTTPRouteStatus(t *testing.T) {
 		t.Errorf("prepareHTTPRouteStatus() mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestPrepareHTTPRouteStatusWithWarnings(t *testing.T) {
+	status := state.HTTPRouteStatus{
+		ParentStatuses: map[string]state.ParentStatus{
+			"attached": {
+				Attached: true,
+			},
+			"not-attached": {
+				Attached: false,
+			},
+		},
+		Warnings: []string{
+			"empty backend refs",
+			"service test/foo cannot be resolved",
+		},
+	}
+
+	gwNsName := types.NamespacedName{Namespace: "test", Name: "gateway"}
+	gatewayCtlrName := "test.example.com"
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	expectedAttachedConditions := []metav1.Condition{
+		{
+			Type:               string(v1beta1.RouteConditionAccepted),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             "Accepted",
+		},
+		{
+			Type:               string(RouteConditionWarnings),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             string(RouteReasonConfigurationWarnings),
+			Message:            "empty backend refs; service test/foo cannot be resolved",
+		},
+	}
+
+	result := prepareHTTPRouteStatus(status, gwNsName, gatewayCtlrName, transitionTime)
+
+	if len(result.Parents) != 2 {
+		t.Fatalf("prepareHTTPRouteStatus() returned %d parents but expected 2", len(result.Parents))
+	}
+
+	if diff := cmp.Diff(expectedAttachedConditions, result.Parents[0].Conditions); diff != "" {
+		t.Errorf("prepareHTTPRouteStatus() mismatch on attached parent conditions (-want +got):\n%s", diff)
+	}
+
+	// the warnings are only reported for the parents the route is attached to
+	if l := len(result.Parents[1].Conditions); l != 1 {
+		t.Errorf("prepareHTTPRouteStatus() returned %d conditions for not attached parent but expected 1", l)
+	}
+}
+
+func TestPrepareHTTPRouteStatusWithUnresolvedRefs(t *testing.T) {
+	status := state.HTTPRouteStatus{
+		ParentStatuses: map[string]state.ParentStatus{
+			"attached": {
+				Attached: true,
+			},
+		},
+		UnresolvedRefs: []string{
+			"service test/foo doesn't expose port 8080",
+			"service test/bar doesn't exist",
+		},
+		BackendRefCount: 3,
+	}
+
+	gwNsName := types.NamespacedName{Namespace: "test", Name: "gateway"}
+	gatewayCtlrName := "test.example.com"
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	expectedConditions := []metav1.Condition{
+		{
+			Type:               string(v1beta1.RouteConditionAccepted),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             "Accepted",
+		},
+		{
+			Type:               string(v1beta1.RouteConditionResolvedRefs),
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             string(v1beta1.RouteReasonBackendNotFound),
+			Message: "2 of 3 backendRefs cannot be resolved: " +
+				"service test/foo doesn't expose port 8080; service test/bar doesn't exist",
+		},
+	}
+
+	result := prepareHTTPRouteStatus(status, gwNsName, gatewayCtlrName, transitionTime)
+
+	if len(result.Parents) != 1 {
+		t.Fatalf("prepareHTTPRouteStatus() returned %d parents but expected 1", len(result.Parents))
+	}
+
+	if diff := cmp.Diff(expectedConditions, result.Parents[0].Conditions); diff != "" {
+		t.Errorf("prepareHTTPRouteStatus() mismatch on conditions (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrepareHTTPRouteStatusWithUnresolvableRules(t *testing.T) {
+	status := state.HTTPRouteStatus{
+		ParentStatuses: map[string]state.ParentStatus{
+			"attached": {
+				Attached: true,
+			},
+		},
+		UnresolvedRefs: []string{
+			"service test/foo doesn't exist",
+			"service test/bar doesn't exist",
+		},
+		BackendRefCount:   3,
+		UnresolvableRules: []int{1},
+	}
+
+	gwNsName := types.NamespacedName{Namespace: "test", Name: "gateway"}
+	gatewayCtlrName := "test.example.com"
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	expectedCondition := metav1.Condition{
+		Type:               string(v1beta1.RouteConditionResolvedRefs),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 123,
+		LastTransitionTime: transitionTime,
+		Reason:             string(v1beta1.RouteReasonBackendNotFound),
+		Message: "2 of 3 backendRefs cannot be resolved, including all backendRefs of rule 1: " +
+			"service test/foo doesn't exist; service test/bar doesn't exist",
+	}
+
+	result := prepareHTTPRouteStatus(status, gwNsName, gatewayCtlrName, transitionTime)
+
+	if len(result.Parents) != 1 || len(result.Parents[0].Conditions) != 2 {
+		t.Fatalf("prepareHTTPRouteStatus() returned unexpected parents %v", result.Parents)
+	}
+
+	if diff := cmp.Diff(expectedCondition, result.Parents[0].Conditions[1]); diff != "" {
+		t.Errorf("prepareHTTPRouteStatus() mismatch on ResolvedRefs condition (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormatRules(t *testing.T) {
+	tests := []struct {
+		rules    []int
+		expected string
+	}{
+		{
+			rules:    []int{2},
+			expected: "rule 2",
+		},
+		{
+			rules:    []int{0, 2},
+			expected: "rules 0, 2",
+		},
+	}
+
+	for _, test := range tests {
+		result := formatRules(test.rules)
+		if result != test.expected {
+			t.Errorf("formatRules(%v) returned %q but expected %q", test.rules, result, test.expected)
+		}
+	}
+}
+
+func TestPrepareHTTPRouteStatusWithInvalidKindRefs(t *testing.T) {
+	status := state.HTTPRouteStatus{
+		ParentStatuses: map[string]state.ParentStatus{
+			"attached": {
+				Attached: true,
+			},
+		},
+		UnresolvedRefs: []string{
+			"service test/bar doesn't exist",
+		},
+		InvalidKindRefs: []string{
+			"backendRef foo has unsupported group networking.k8s.io, must be the core group",
+		},
+		BackendRefCount: 3,
+	}
+
+	gwNsName := types.NamespacedName{Namespace: "test", Name: "gateway"}
+	gatewayCtlrName := "test.example.com"
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	expectedConditions := []metav1.Condition{
+		{
+			Type:               string(v1beta1.RouteConditionAccepted),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             "Accepted",
+		},
+		{
+			Type:               string(v1beta1.RouteConditionResolvedRefs),
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             string(v1beta1.RouteReasonInvalidKind),
+			Message: "2 of 3 backendRefs cannot be resolved: " +
+				"backendRef foo has unsupported group networking.k8s.io, must be the core group; " +
+				"service test/bar doesn't exist",
+		},
+	}
+
+	result := prepareHTTPRouteStatus(status, gwNsName, gatewayCtlrName, transitionTime)
+
+	if len(result.Parents) != 1 {
+		t.Fatalf("prepareHTTPRouteStatus() returned %d parents but expected 1", len(result.Parents))
+	}
+
+	if diff := cmp.Diff(expectedConditions, result.Parents[0].Conditions); diff != "" {
+		t.Errorf("prepareHTTPRouteStatus() mismatch on conditions (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrepareHTTPRouteStatusWithResolvedRefs(t *testing.T) {
+	status := state.HTTPRouteStatus{
+		ParentStatuses: map[string]state.ParentStatus{
+			"attached": {
+				Attached: true,
+			},
+		},
+		BackendRefCount: 2,
+	}
+
+	gwNsName := types.NamespacedName{Namespace: "test", Name: "gateway"}
+	gatewayCtlrName := "test.example.com"
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	expectedConditions := []metav1.Condition{
+		{
+			Type:               string(v1beta1.RouteConditionAccepted),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             "Accepted",
+		},
+		{
+			Type:               string(v1beta1.RouteConditionResolvedRefs),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 123,
+			LastTransitionTime: transitionTime,
+			Reason:             string(v1beta1.RouteReasonResolvedRefs),
+			Message:            "All 2 backendRefs are resolved",
+		},
+	}
+
+	result := prepareHTTPRouteStatus(status, gwNsName, gatewayCtlrName, transitionTime)
+
+	if len(result.Parents) != 1 {
+		t.Fatalf("prepareHTTPRouteStatus() returned %d parents but expected 1", len(result.Parents))
+	}
+
+	if diff := cmp.Diff(expectedConditions, result.Parents[0].Conditions); diff != "" {
+		t.Errorf("prepareHTTPRouteStatus() mismatch on conditions (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrepareHTTPRouteStatusWithMissingGatewayRefs(t *testing.T) {
+	status := state.HTTPRouteStatus{
+		ParentStatuses: map[string]state.ParentStatus{
+			"attached": {
+				Attached: true,
+			},
+		},
+		MissingGatewayRefs: map[types.NamespacedName][]string{
+			{Namespace: "test", Name: "missing-gateway-2"}: {"listener-80"},
+			{Namespace: "test", Name: "missing-gateway-1"}: {"listener-80", "listener-443"},
+		},
+	}
+
+	gwNsName := types.NamespacedName{Namespace: "test", Name: "gateway"}
+	gatewayCtlrName := "test.example.com"
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	createMissingParent := func(gwName, sectionName string) v1beta1.RouteParentStatus {
+		return v1beta1.RouteParentStatus{
+			ParentRef: v1beta1.ParentReference{
+				Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+				Name:        v1beta1.ObjectName(gwName),
+				SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer(sectionName)),
+			},
+			ControllerName: v1beta1.GatewayController(gatewayCtlrName),
+			Conditions: []metav1.Condition{
+				{
+					Type:               string(v1beta1.RouteConditionAccepted),
+					Status:             metav1.ConditionFalse,
+					ObservedGeneration: 123,
+					LastTransitionTime: transitionTime,
+					Reason:             string(RouteReasonNoMatchingParent),
+				},
+			},
+		}
+	}
+
+	expectedMissingParents := []v1beta1.RouteParentStatus{
+		createMissingParent("missing-gateway-1", "listener-80"),
+		createMissingParent("missing-gateway-1", "listener-443"),
+		createMissingParent("missing-gateway-2", "listener-80"),
+	}
+
+	result := prepareHTTPRouteStatus(status, gwNsName, gatewayCtlrName, transitionTime)
+
+	if len(result.Parents) != 4 {
+		t.Fatalf("prepareHTTPRouteStatus() returned %d parents but expected 4", len(result.Parents))
+	}
+
+	if diff := cmp.Diff(expectedMissingParents, result.Parents[1:]); diff != "" {
+		t.Errorf("prepareHTTPRouteStatus() mismatch on the parents of the missing gateways (-want +got):\n%s", diff)
+	}
+}
+
+func TestConsolidateWarnings(t *testing.T) {
+	many := make([]string, 0, maxWarningsInMessage+2)
+	for i := 0; i < maxWarningsInMessage+2; i++ {
+		many = append(many, "warning")
+	}
+
+	tests := []struct {
+		warnings []string
+		expected string
+		msg      string
+	}{
+		{
+			warnings: []string{"first"},
+			expected: "first",
+			msg:      "one warning",
+		},
+		{
+			warnings: []string{"first", "second"},
+			expected: "first; second",
+			msg:      "two warnings",
+		},
+		{
+			warnings: many,
+			expected: strings.Repeat("warning; ", maxWarningsInMessage) + "and 2 more",
+			msg:      "too many warnings",
+		},
+	}
+
+	for _, test := range tests {
+		result := consolidateWarnings(test.warnings)
+		if result != test.expected {
+			t.Errorf("consolidateWarnings() returned %q but expected %q for the case of %q", result, test.expected, test.msg)
+		}
+	}
+}
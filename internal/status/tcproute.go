@@ -0,0 +1,39 @@
+package status
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
+)
+
+// prepareTCPRouteStatus prepares the status for a TCPRoute resource.
+// Like for an HTTPRoute, the status includes the Accepted condition per each parentRef and the Warnings condition
+// for the attached parentRefs.
+func prepareTCPRouteStatus(
+	status state.TCPRouteStatus,
+	gwNsName types.NamespacedName,
+	gatewayCtlrName string,
+	transitionTime metav1.Time,
+) v1alpha2.TCPRouteStatus {
+	parents := make([]v1alpha2.RouteParentStatus, 0, len(status.ParentStatuses))
+
+	for _, name := range getSortedSectionNames(status.ParentStatuses) {
+		ps := status.ParentStatuses[name]
+
+		p := prepareRouteParentStatus(name, ps, gwNsName, gatewayCtlrName, transitionTime)
+
+		if ps.Attached && len(status.Warnings) > 0 {
+			p.Conditions = append(p.Conditions, prepareWarningsCondition(status.Warnings, transitionTime))
+		}
+
+		parents = append(parents, p)
+	}
+
+	return v1alpha2.TCPRouteStatus{
+		RouteStatus: v1alpha2.RouteStatus{
+			Parents: parents,
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/helpers"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
+)
+
+func TestPrepareTCPRouteStatus(t *testing.T) {
+	status := state.TCPRouteStatus{
+		ParentStatuses: map[string]state.ParentStatus{
+			"attached": {
+				Attached: true,
+			},
+			"route-conflict": {
+				Attached: false,
+				Reason:   state.ParentRefReasonRouteConflict,
+			},
+		},
+		Warnings: []string{
+			"only the first backendRef of the first rule is supported; backendRef 1 of rule 0 is ignored",
+		},
+	}
+
+	gwNsName := types.NamespacedName{Namespace: "test", Name: "gateway"}
+	gatewayCtlrName := "test.example.com"
+
+	transitionTime := metav1.NewTime(time.Now())
+
+	expected := v1alpha2.TCPRouteStatus{
+		RouteStatus: v1alpha2.RouteStatus{
+			Parents: []v1alpha2.RouteParentStatus{
+				{
+					ParentRef: v1beta1.ParentReference{
+						Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+						Name:        "gateway",
+						SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("attached")),
+					},
+					ControllerName: v1beta1.GatewayController(gatewayCtlrName),
+					Conditions: []metav1.Condition{
+						{
+							Type:               string(v1beta1.RouteConditionAccepted),
+							Status:             metav1.ConditionTrue,
+							ObservedGeneration: 123,
+							LastTransitionTime: transitionTime,
+							Reason:             "Accepted",
+						},
+						{
+							Type:               string(RouteConditionWarnings),
+							Status:             metav1.ConditionTrue,
+							ObservedGeneration: 123,
+							LastTransitionTime: transitionTime,
+							Reason:             string(RouteReasonConfigurationWarnings),
+							Message: "only the first backendRef of the first rule is supported; " +
+								"backendRef 1 of rule 0 is ignored",
+						},
+					},
+				},
+				{
+					ParentRef: v1beta1.ParentReference{
+						Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+						Name:        "gateway",
+						SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("route-conflict")),
+					},
+					ControllerName: v1beta1.GatewayController(gatewayCtlrName),
+					Conditions: []metav1.Condition{
+						{
+							Type:               string(v1beta1.RouteConditionAccepted),
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: 123,
+							LastTransitionTime: transitionTime,
+							Reason:             string(state.ParentRefReasonRouteConflict),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	result := prepareTCPRouteStatus(status, gwNsName, gatewayCtlrName, transitionTime)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("prepareTCPRouteStatus() mismatch (-want +got):\n%s", diff)
+	}
+}
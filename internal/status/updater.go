@@ -7,8 +7,10 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/metrics"
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
 )
 
@@ -32,6 +34,8 @@ type UpdaterConfig struct {
 	Logger logr.Logger
 	// Clock is used as a source of time for the LastTransitionTime field in Conditions in resource statuses.
 	Clock Clock
+	// Metrics holds the metrics to record the results of the status updates. Optional.
+	Metrics *metrics.ControllerMetrics
 }
 
 // updaterImpl updates statuses of the Gateway API resources.
@@ -77,6 +81,10 @@ type updaterImpl struct {
 
 // NewUpdater creates a new Updater.
 func NewUpdater(cfg UpdaterConfig) Updater {
+	if cfg.Metrics == nil {
+		cfg.Metrics = metrics.NewControllerMetrics()
+	}
+
 	return &updaterImpl{
 		cfg: cfg,
 	}
@@ -93,6 +101,19 @@ func (upd *updaterImpl) Update(ctx context.Context, statuses state.Statuses) {
 		})
 	}
 
+	for name, gcs := range statuses.IgnoredGatewayClassStatuses {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		upd.update(ctx, types.NamespacedName{Name: name}, &v1beta1.GatewayClass{}, func(object client.Object) {
+			gc := object.(*v1beta1.GatewayClass)
+			gc.Status = prepareIgnoredGatewayClassStatus(gcs, upd.cfg.Clock.Now())
+		})
+	}
+
 	if statuses.GatewayStatus != nil {
 		upd.update(ctx, statuses.GatewayStatus.NsName, &v1beta1.Gateway{}, func(object client.Object) {
 			gw := object.(*v1beta1.Gateway)
@@ -126,6 +147,20 @@ func (upd *updaterImpl) Update(ctx context.Context, statuses state.Statuses) {
 			hr.Status = prepareHTTPRouteStatus(rs, statuses.GatewayStatus.NsName, upd.cfg.GatewayCtlrName, upd.cfg.Clock.Now())
 		})
 	}
+
+	for nsname, rs := range statuses.TCPRouteStatuses {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		upd.update(ctx, nsname, &v1alpha2.TCPRoute{}, func(object client.Object) {
+			tr := object.(*v1alpha2.TCPRoute)
+			// statuses.GatewayStatus is never nil when len(statuses.TCPRouteStatuses) > 0
+			tr.Status = prepareTCPRouteStatus(rs, statuses.GatewayStatus.NsName, upd.cfg.GatewayCtlrName, upd.cfg.Clock.Now())
+		})
+	}
 }
 
 func (upd *updaterImpl) update(ctx context.Context, nsname types.NamespacedName, obj client.Object, statusSetter func(client.Object)) {
@@ -143,6 +178,7 @@ func (upd *updaterImpl) update(ctx context.Context, nsname types.NamespacedName,
 				"namespace", nsname.Namespace,
 				"name", nsname.Name,
 				"kind", obj.GetObjectKind().GroupVersionKind().Kind)
+			upd.cfg.Metrics.StatusUpdates.WithLabelValues(metrics.StatusUpdateFailure).Inc()
 		}
 		return
 	}
@@ -155,5 +191,9 @@ func (upd *updaterImpl) update(ctx context.Context, nsname types.NamespacedName,
 			"namespace", nsname.Namespace,
 			"name", nsname.Name,
 			"kind", obj.GetObjectKind().GroupVersionKind().Kind)
+		upd.cfg.Metrics.StatusUpdates.WithLabelValues(metrics.StatusUpdateFailure).Inc()
+		return
 	}
+
+	upd.cfg.Metrics.StatusUpdates.WithLabelValues(metrics.StatusUpdateSuccess).Inc()
 }
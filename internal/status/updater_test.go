@@ -6,16 +6,19 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/helpers"
+	"github.com/nginxinc/nginx-kubernetes-gateway/internal/metrics"
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/state"
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/status"
 	"github.com/nginxinc/nginx-kubernetes-gateway/internal/status/statusfakes"
@@ -25,16 +28,18 @@ var _ = Describe("Updater", func() {
 	const gcName = "my-class"
 
 	var (
-		updater         status.Updater
-		client          client.Client
-		fakeClockTime   metav1.Time
-		gatewayCtrlName string
+		updater           status.Updater
+		client            client.Client
+		fakeClockTime     metav1.Time
+		gatewayCtrlName   string
+		controllerMetrics *metrics.ControllerMetrics
 	)
 
 	BeforeEach(OncePerOrdered, func() {
 		scheme := runtime.NewScheme()
 
 		Expect(gatewayv1beta1.AddToScheme(scheme)).Should(Succeed())
+		Expect(v1alpha2.AddToScheme(scheme)).Should(Succeed())
 
 		client = fake.NewClientBuilder().
 			WithScheme(scheme).
@@ -49,20 +54,24 @@ var _ = Describe("Updater", func() {
 
 		gatewayCtrlName = "test.example.com"
 
+		controllerMetrics = metrics.NewControllerMetrics()
+
 		updater = status.NewUpdater(status.UpdaterConfig{
 			GatewayCtlrName:  gatewayCtrlName,
 			GatewayClassName: gcName,
 			Client:           client,
 			Logger:           zap.New(),
 			Clock:            fakeClock,
+			Metrics:          controllerMetrics,
 		})
 	})
 
 	Describe("Process status updates", Ordered, func() {
 		var (
-			gc            *v1beta1.GatewayClass
+			gc, ignoredGc *v1beta1.GatewayClass
 			gw, ignoredGw *v1beta1.Gateway
 			hr            *v1beta1.HTTPRoute
+			tr            *v1alpha2.TCPRoute
 
 			createStatuses = func(valid bool, generation int64) state.Statuses {
 				var gcErrorMsg string
@@ -76,12 +85,18 @@ var _ = Describe("Updater", func() {
 						ErrorMsg:           gcErrorMsg,
 						ObservedGeneration: generation,
 					},
+					IgnoredGatewayClassStatuses: map[string]state.IgnoredGatewayClassStatus{
+						"ignored-class": {
+							ObservedGeneration: generation,
+						},
+					},
 					GatewayStatus: &state.GatewayStatus{
 						NsName: types.NamespacedName{Namespace: "test", Name: "gateway"},
 						ListenerStatuses: map[string]state.ListenerStatus{
 							"http": {
 								Valid:          valid,
 								AttachedRoutes: 1,
+								Programmed:     valid,
 							},
 						},
 					},
@@ -99,6 +114,15 @@ var _ = Describe("Updater", func() {
 							},
 						},
 					},
+					TCPRouteStatuses: map[types.NamespacedName]state.TCPRouteStatus{
+						{Namespace: "test", Name: "tcp-route1"}: {
+							ParentStatuses: map[string]state.ParentStatus{
+								"tcp": {
+									Attached: valid,
+								},
+							},
+						},
+					},
 				}
 			}
 
@@ -126,7 +150,45 @@ var _ = Describe("Updater", func() {
 				}
 			}
 
-			createExpectedGw = func(status metav1.ConditionStatus, reason string) *v1beta1.Gateway {
+			createExpectedIgnoredGc = func() *v1beta1.GatewayClass {
+				return &v1beta1.GatewayClass{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "ignored-class",
+					},
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "GatewayClass",
+						APIVersion: "gateway.networking.k8s.io/v1beta1",
+					},
+					Status: v1beta1.GatewayClassStatus{
+						Conditions: []metav1.Condition{
+							{
+								Type:               string(v1beta1.GatewayClassConditionStatusAccepted),
+								Status:             metav1.ConditionFalse,
+								ObservedGeneration: 1,
+								LastTransitionTime: fakeClockTime,
+								Reason:             string(status.GatewayClassReasonGatewayClassConflict),
+								Message:            status.GatewayClassMessageGatewayClassConflict,
+							},
+						},
+					},
+				}
+			}
+
+			createExpectedGw = func(condStatus metav1.ConditionStatus, reason string) *v1beta1.Gateway {
+				gwCond := metav1.Condition{
+					Type:               string(v1beta1.GatewayConditionReady),
+					Status:             condStatus,
+					ObservedGeneration: 123,
+					LastTransitionTime: fakeClockTime,
+					Reason:             string(v1beta1.GatewayReasonReady),
+				}
+				programmedReason := string(status.ListenerReasonProgrammed)
+				if condStatus == metav1.ConditionFalse {
+					gwCond.Reason = string(v1beta1.GatewayReasonListenersNotReady)
+					gwCond.Message = "Some listeners are not ready: http"
+					programmedReason = reason
+				}
+
 				return &v1beta1.Gateway{
 					ObjectMeta: metav1.ObjectMeta{
 						Namespace: "test",
@@ -149,14 +211,43 @@ var _ = Describe("Updater", func() {
 								Conditions: []metav1.Condition{
 									{
 										Type:               string(v1beta1.ListenerConditionReady),
-										Status:             status,
+										Status:             condStatus,
 										ObservedGeneration: 123,
 										LastTransitionTime: fakeClockTime,
 										Reason:             reason,
 									},
+									{
+										Type:               string(status.ListenerConditionAccepted),
+										Status:             metav1.ConditionTrue,
+										ObservedGeneration: 123,
+										LastTransitionTime: fakeClockTime,
+										Reason:             string(status.ListenerReasonAccepted),
+									},
+									{
+										Type:               string(status.ListenerConditionProgrammed),
+										Status:             condStatus,
+										ObservedGeneration: 123,
+										LastTransitionTime: fakeClockTime,
+										Reason:             programmedReason,
+									},
+									{
+										Type:               string(v1beta1.ListenerConditionResolvedRefs),
+										Status:             metav1.ConditionTrue,
+										ObservedGeneration: 123,
+										LastTransitionTime: fakeClockTime,
+										Reason:             string(v1beta1.ListenerReasonResolvedRefs),
+									},
+									{
+										Type:               string(v1beta1.ListenerConditionConflicted),
+										Status:             metav1.ConditionFalse,
+										ObservedGeneration: 123,
+										LastTransitionTime: fakeClockTime,
+										Reason:             string(v1beta1.ListenerReasonNoConflicts),
+									},
 								},
 							},
 						},
+						Conditions: []metav1.Condition{gwCond},
 					},
 				}
 			}
@@ -221,6 +312,42 @@ var _ = Describe("Updater", func() {
 					},
 				}
 			}
+
+			createExpectedTR = func() *v1alpha2.TCPRoute {
+				return &v1alpha2.TCPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "test",
+						Name:      "tcp-route1",
+					},
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "TCPRoute",
+						APIVersion: "gateway.networking.k8s.io/v1alpha2",
+					},
+					Status: v1alpha2.TCPRouteStatus{
+						RouteStatus: v1alpha2.RouteStatus{
+							Parents: []v1alpha2.RouteParentStatus{
+								{
+									ControllerName: gatewayv1beta1.GatewayController(gatewayCtrlName),
+									ParentRef: gatewayv1beta1.ParentReference{
+										Namespace:   (*v1beta1.Namespace)(helpers.GetStringPointer("test")),
+										Name:        "gateway",
+										SectionName: (*v1beta1.SectionName)(helpers.GetStringPointer("tcp")),
+									},
+									Conditions: []metav1.Condition{
+										{
+											Type:               string(gatewayv1beta1.RouteConditionAccepted),
+											Status:             metav1.ConditionTrue,
+											ObservedGeneration: 123,
+											LastTransitionTime: fakeClockTime,
+											Reason:             "Accepted",
+										},
+									},
+								},
+							},
+						},
+					},
+				}
+			}
 		)
 
 		BeforeAll(func() {
@@ -233,6 +360,15 @@ var _ = Describe("Updater", func() {
 					APIVersion: "gateway.networking.k8s.io/v1beta1",
 				},
 			}
+			ignoredGc = &v1beta1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ignored-class",
+				},
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "GatewayClass",
+					APIVersion: "gateway.networking.k8s.io/v1beta1",
+				},
+			}
 			gw = &v1beta1.Gateway{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: "test",
@@ -263,19 +399,39 @@ var _ = Describe("Updater", func() {
 					APIVersion: "gateway.networking.k8s.io/v1beta1",
 				},
 			}
+			tr = &v1alpha2.TCPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test",
+					Name:      "tcp-route1",
+				},
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "TCPRoute",
+					APIVersion: "gateway.networking.k8s.io/v1alpha2",
+				},
+			}
 		})
 
 		It("should create resources in the API server", func() {
 			Expect(client.Create(context.Background(), gc)).Should(Succeed())
+			Expect(client.Create(context.Background(), ignoredGc)).Should(Succeed())
 			Expect(client.Create(context.Background(), gw)).Should(Succeed())
 			Expect(client.Create(context.Background(), ignoredGw)).Should(Succeed())
 			Expect(client.Create(context.Background(), hr)).Should(Succeed())
+			Expect(client.Create(context.Background(), tr)).Should(Succeed())
 		})
 
 		It("should update statuses", func() {
 			updater.Update(context.Background(), createStatuses(true, 1))
 		})
 
+		It("should record the successful status updates", func() {
+			successes := testutil.ToFloat64(controllerMetrics.StatusUpdates.WithLabelValues(metrics.StatusUpdateSuccess))
+			Expect(successes).To(Equal(6.0))
+
+			failures := testutil.ToFloat64(controllerMetrics.StatusUpdates.WithLabelValues(metrics.StatusUpdateFailure))
+			Expect(failures).To(BeZero())
+		})
+
 		It("should have the updated status of GatewayClass in the API server", func() {
 			latestGc := &v1beta1.GatewayClass{}
 			expectedGc := createExpectedGc(metav1.ConditionTrue, 1, string(v1beta1.GatewayClassConditionStatusAccepted), "GatewayClass has been accepted")
@@ -288,6 +444,18 @@ var _ = Describe("Updater", func() {
 			Expect(helpers.Diff(expectedGc, latestGc)).To(BeEmpty())
 		})
 
+		It("should have the updated status of ignored GatewayClass in the API server", func() {
+			latestGc := &v1beta1.GatewayClass{}
+			expectedGc := createExpectedIgnoredGc()
+
+			err := client.Get(context.Background(), types.NamespacedName{Name: "ignored-class"}, latestGc)
+			Expect(err).Should(Not(HaveOccurred()))
+
+			expectedGc.ResourceVersion = latestGc.ResourceVersion
+
+			Expect(helpers.Diff(expectedGc, latestGc)).To(BeEmpty())
+		})
+
 		It("should have the updated status of Gateway in the API server", func() {
 			latestGw := &v1beta1.Gateway{}
 			expectedGw := createExpectedGw(metav1.ConditionTrue, string(v1beta1.ListenerReasonReady))
@@ -324,6 +492,18 @@ var _ = Describe("Updater", func() {
 			Expect(helpers.Diff(expectedHR, latestHR)).To(BeEmpty())
 		})
 
+		It("should have the updated status of TCPRoute in the API server", func() {
+			latestTR := &v1alpha2.TCPRoute{}
+			expectedTR := createExpectedTR()
+
+			err := client.Get(context.Background(), types.NamespacedName{Namespace: "test", Name: "tcp-route1"}, latestTR)
+			Expect(err).Should(Not(HaveOccurred()))
+
+			expectedTR.ResourceVersion = latestTR.ResourceVersion
+
+			Expect(helpers.Diff(expectedTR, latestTR)).To(BeEmpty())
+		})
+
 		It("should update statuses with canceled context - function normally returns", func() {
 			ctx, cancel := context.WithCancel(context.Background())
 			cancel()
@@ -343,6 +523,19 @@ var _ = Describe("Updater", func() {
 				Expect(helpers.Diff(expectedGc, latestGc)).To(BeEmpty())
 			})
 
+			It("should not have the updated status of ignored GatewayClass in the API server", func() {
+				latestGc := &v1beta1.GatewayClass{}
+				expectedGc := createExpectedIgnoredGc()
+
+				err := client.Get(context.Background(), types.NamespacedName{Name: "ignored-class"}, latestGc)
+				Expect(err).Should(Not(HaveOccurred()))
+
+				expectedGc.ResourceVersion = latestGc.ResourceVersion
+
+				// if the status was updated, we would see a different ObservedGeneration
+				Expect(helpers.Diff(expectedGc, latestGc)).To(BeEmpty())
+			})
+
 			It("should have the updated status of Gateway in the API server", func() {
 				latestGw := &v1beta1.Gateway{}
 				expectedGw := createExpectedGw(metav1.ConditionFalse, string(v1beta1.ListenerReasonInvalid))
@@ -380,6 +573,19 @@ var _ = Describe("Updater", func() {
 				// if the status was updated, we would see the route rejected (Accepted = false)
 				Expect(helpers.Diff(expectedHR, latestHR)).To(BeEmpty())
 			})
+
+			It("should not have the updated status of TCPRoute in the API server", func() {
+				latestTR := &v1alpha2.TCPRoute{}
+				expectedTR := createExpectedTR()
+
+				err := client.Get(context.Background(), types.NamespacedName{Namespace: "test", Name: "tcp-route1"}, latestTR)
+				Expect(err).Should(Not(HaveOccurred()))
+
+				expectedTR.ResourceVersion = latestTR.ResourceVersion
+
+				// if the status was updated, we would see the route rejected (Accepted = false)
+				Expect(helpers.Diff(expectedTR, latestTR)).To(BeEmpty())
+			})
 		})
 	})
 })
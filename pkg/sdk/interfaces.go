@@ -2,7 +2,9 @@ package sdk
 
 import (
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	nginxgwv1alpha1 "github.com/nginxinc/nginx-kubernetes-gateway/pkg/apis/gateway/v1alpha1"
@@ -29,11 +31,21 @@ type HTTPRouteImpl interface {
 	Remove(types.NamespacedName)
 }
 
+type TCPRouteImpl interface {
+	Upsert(route *v1alpha2.TCPRoute)
+	Remove(types.NamespacedName)
+}
+
 type ServiceImpl interface {
 	Upsert(svc *apiv1.Service)
 	Remove(nsname types.NamespacedName)
 }
 
+type ServiceImportImpl interface {
+	Upsert(si *unstructured.Unstructured)
+	Remove(nsname types.NamespacedName)
+}
+
 type SecretImpl interface {
 	Upsert(secret *apiv1.Secret)
 	Remove(name types.NamespacedName)
@@ -0,0 +1,71 @@
+package sdk
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctlr "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type serviceImportReconciler struct {
+	client.Client
+	scheme *runtime.Scheme
+	gvk    schema.GroupVersionKind
+	impl   ServiceImportImpl
+}
+
+// RegisterServiceImportController registers the ServiceImportController in the manager.
+// The ServiceImports of the Multi-Cluster Services API are watched as unstructured resources of the gvk, because
+// the API is not a part of the Kubernetes API.
+func RegisterServiceImportController(mgr manager.Manager, gvk schema.GroupVersionKind, impl ServiceImportImpl) error {
+	r := &serviceImportReconciler{
+		Client: mgr.GetClient(),
+		scheme: mgr.GetScheme(),
+		gvk:    gvk,
+		impl:   impl,
+	}
+
+	si := &unstructured.Unstructured{}
+	si.SetGroupVersionKind(gvk)
+
+	return ctlr.NewControllerManagedBy(mgr).
+		For(si).
+		Complete(r)
+}
+
+func (r *serviceImportReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := log.FromContext(ctx).WithValues("serviceimport", req.NamespacedName)
+
+	log.V(3).Info("Reconciling ServiceImport")
+
+	found := true
+	si := &unstructured.Unstructured{}
+	si.SetGroupVersionKind(r.gvk)
+	err := r.Get(ctx, req.NamespacedName, si)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "Failed to get ServiceImport")
+			return reconcile.Result{}, err
+		}
+		found = false
+	}
+
+	if !found {
+		log.V(3).Info("Removing ServiceImport")
+
+		r.impl.Remove(req.NamespacedName)
+		return reconcile.Result{}, nil
+	}
+
+	log.V(3).Info("Upserting ServiceImport")
+
+	r.impl.Upsert(si)
+	return reconcile.Result{}, nil
+}